@@ -5,12 +5,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
+	"syscall"
 	"time"
 
 	"github.com/kamir/memory-connector/internal/logger"
+	"github.com/kamir/memory-connector/pkg/alerting"
+	"github.com/kamir/memory-connector/pkg/aliases"
+	"github.com/kamir/memory-connector/pkg/anonymizer"
+	"github.com/kamir/memory-connector/pkg/api"
+	"github.com/kamir/memory-connector/pkg/audit"
 	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/clock"
 	"github.com/kamir/memory-connector/pkg/config"
+	"github.com/kamir/memory-connector/pkg/contentfilter"
+	"github.com/kamir/memory-connector/pkg/events"
+	"github.com/kamir/memory-connector/pkg/export"
+	"github.com/kamir/memory-connector/pkg/features"
+	"github.com/kamir/memory-connector/pkg/heartbeat"
+	"github.com/kamir/memory-connector/pkg/models"
 	"github.com/kamir/memory-connector/pkg/orchestrator"
+	"github.com/kamir/memory-connector/pkg/reporting"
+	"github.com/kamir/memory-connector/pkg/scheduler"
+	"github.com/kamir/memory-connector/pkg/simulation"
 	"github.com/kamir/memory-connector/pkg/state"
 	"github.com/kamir/memory-connector/pkg/transformer"
 	"github.com/spf13/cobra"
@@ -21,8 +39,12 @@ var (
 	cfgFile    string
 	jsonOutput bool
 	log        *zap.Logger
+	logLevels  *logger.Levels // subsystem loggers of the service, nil for one-shot commands
 )
 
+// secretVerifyTimeout bounds checking rotated credentials against LightRAG and the Memory API
+const secretVerifyTimeout = 30 * time.Second
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "memory-connector",
@@ -49,6 +71,13 @@ into LightRAG's knowledge graph for entity and relation extraction.`,
 	rootCmd.AddCommand(serveCmd())
 	rootCmd.AddCommand(listCmd())
 	rootCmd.AddCommand(statusCmd())
+	rootCmd.AddCommand(validateConfigCmd())
+	rootCmd.AddCommand(schemaCmd())
+	rootCmd.AddCommand(clusterPlacesCmd())
+	rootCmd.AddCommand(snapshotCmd())
+	rootCmd.AddCommand(restoreCmd())
+	rootCmd.AddCommand(importCmd())
+	rootCmd.AddCommand(benchCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -81,18 +110,19 @@ func versionCmd() *cobra.Command {
 
 // syncCmd returns the sync command
 func syncCmd() *cobra.Command {
-	var connectorID string
+	var connectorID, fixedTime string
 
 	cmd := &cobra.Command{
 		Use:   "sync",
 		Short: "Trigger a manual sync for a connector",
 		Long:  "Manually trigger a sync operation for the specified connector",
 		Run: func(cmd *cobra.Command, args []string) {
-			runSync(connectorID)
+			runSync(connectorID, fixedTime)
 		},
 	}
 
 	cmd.Flags().StringVarP(&connectorID, "connector", "c", "", "connector ID to sync (required)")
+	cmd.Flags().StringVar(&fixedTime, "fixed-time", "", "run the sync at a fixed RFC 3339 time, so repeated runs produce identical documents, exports, and state")
 	cmd.MarkFlagRequired("connector")
 
 	return cmd
@@ -123,7 +153,7 @@ func listCmd() *cobra.Command {
 }
 
 // statusCmd returns the status command
-func statusCmd() *cobra.Command{
+func statusCmd() *cobra.Command {
 	var connectorID string
 
 	cmd := &cobra.Command{
@@ -141,10 +171,144 @@ func statusCmd() *cobra.Command{
 	return cmd
 }
 
+// validateConfigCmd returns the validate-config command
+func validateConfigCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate-config",
+		Short: "Validate the configuration file",
+		Long:  "Check the configuration file against the JSON Schema and validation rules and print every violation with its YAML path",
+		Run: func(cmd *cobra.Command, args []string) {
+			runValidateConfig()
+		},
+	}
+}
+
+// schemaCmd returns the schema command
+func schemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the configuration file",
+		Long:  "Print the JSON Schema of the configuration file for IDE autocompletion and CI validation",
+		Run: func(cmd *cobra.Command, args []string) {
+			data, _ := json.MarshalIndent(config.JSONSchema(), "", "  ")
+			fmt.Println(string(data))
+		},
+	}
+}
+
+// clusterPlacesCmd returns the cluster-places command
+func clusterPlacesCmd() *cobra.Command {
+	var connectorID string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "cluster-places",
+		Short: "Cluster the locations of a connector's memories into frequent places",
+		Long:  "Cluster the recorded and recent locations of a connector's memories (DBSCAN) into frequent places, which its syncs use to label new memories",
+		Run: func(cmd *cobra.Command, args []string) {
+			runClusterPlaces(connectorID, limit)
+		},
+	}
+
+	cmd.Flags().StringVarP(&connectorID, "connector", "c", "", "connector ID (required)")
+	cmd.Flags().IntVar(&limit, "limit", 1000, "maximum number of recent memories fetched from the Memory API")
+	cmd.MarkFlagRequired("connector")
+
+	return cmd
+}
+
+// snapshotCmd returns the snapshot command
+func snapshotCmd() *cobra.Command {
+	var contextID, output string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Write the LightRAG documents and sync state of a context to an archive",
+		Long:  "Write the documents ingested for a context and the sync state of its connectors to a gzip-compressed tar, which the restore command inserts into another LightRAG instance",
+		Run: func(cmd *cobra.Command, args []string) {
+			runSnapshot(contextID, output, limit)
+		},
+	}
+
+	cmd.Flags().StringVar(&contextID, "context", "", "context ID (required)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "archive path (required)")
+	cmd.Flags().IntVar(&limit, "limit", 1000, "maximum number of recent memories fetched from the Memory API per connector")
+	cmd.MarkFlagRequired("context")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// restoreCmd returns the restore command
+func restoreCmd() *cobra.Command {
+	var input string
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a context snapshot into the configured LightRAG instance",
+		Long:  "Insert the documents of a snapshot archive into the configured LightRAG instance and restore the sync state of its connectors, which must have none yet",
+		Run: func(cmd *cobra.Command, args []string) {
+			runRestore(input)
+		},
+	}
+
+	cmd.Flags().StringVarP(&input, "input", "i", "", "archive path (required)")
+	cmd.MarkFlagRequired("input")
+
+	return cmd
+}
+
+// importCmd returns the import command
+func importCmd() *cobra.Command {
+	var input, connectorID string
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import a JSON Lines document export into the configured LightRAG instance",
+		Long:  "Insert the documents of a JSON Lines export into the configured LightRAG instance without fetching or transforming memories, skipping documents whose memories the connector already processed",
+		Run: func(cmd *cobra.Command, args []string) {
+			runImport(input, connectorID)
+		},
+	}
+
+	cmd.Flags().StringVarP(&input, "input", "i", "", "export file path (required)")
+	cmd.Flags().StringVarP(&connectorID, "connector", "c", "", "connector ID recording the imported memories, defaults to the exporting connector")
+	cmd.MarkFlagRequired("input")
+
+	return cmd
+}
+
+// benchCmd returns the bench command
+func benchCmd() *cobra.Command {
+	var connectorID, contextID string
+	var documents int
+	var levels []int
+	var wait, cleanup bool
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark ingestion throughput against the configured LightRAG instance",
+		Long:  "Push synthetic documents through the full pipeline into the configured LightRAG instance at each concurrency level, and report documents per second, P50/P95 latencies, and error rates to size deployments",
+		Run: func(cmd *cobra.Command, args []string) {
+			runBench(connectorID, contextID, documents, levels, wait, cleanup)
+		},
+	}
+
+	cmd.Flags().IntVarP(&documents, "documents", "n", 100, "documents per concurrency level")
+	cmd.Flags().IntSliceVar(&levels, "concurrency", []int{1, 4, 16}, "concurrency levels, each benchmarked with its own documents")
+	cmd.Flags().StringVarP(&connectorID, "connector", "c", "", "connector whose transform and simulation settings build the documents, defaults to the rich strategy")
+	cmd.Flags().StringVar(&contextID, "context", "bench", "context ID of the benchmark documents")
+	cmd.Flags().BoolVar(&wait, "wait", false, "wait for LightRAG to process each level's documents and report the processing rate")
+	cmd.Flags().BoolVar(&cleanup, "cleanup", false, "delete the benchmark documents afterwards")
+
+	return cmd
+}
+
 // runSync executes a manual sync
-func runSync(connectorID string) {
+func runSync(connectorID, fixedTime string) {
 	// Load configuration
-	cfg, err := config.LoadConfig(cfgFile, log)
+	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatal("Failed to load config", zap.Error(err))
 	}
@@ -154,6 +318,7 @@ func runSync(connectorID string) {
 		Level:      cfg.Logging.Level,
 		Format:     cfg.Logging.Format,
 		OutputPath: cfg.Logging.OutputPath,
+		Redaction:  logRedaction(cfg.Logging.Redaction),
 	})
 	if err != nil {
 		log.Fatal("Failed to initialize logger", zap.Error(err))
@@ -166,37 +331,29 @@ func runSync(connectorID string) {
 	}
 
 	// Initialize components
-	memoryClient := client.NewMemoryClient(client.MemoryClientConfig{
-		APIURL:     cfg.MemoryAPI.URL,
-		APIKey:     cfg.MemoryAPI.APIKey,
-		Timeout:    time.Duration(cfg.MemoryAPI.Timeout) * time.Second,
-		MaxRetries: cfg.MemoryAPI.MaxRetries,
-		RetryDelay: time.Duration(cfg.MemoryAPI.RetryDelay) * time.Second,
-	}, log)
-
-	lightragClient := client.NewLightRAGClient(client.LightRAGClientConfig{
-		APIURL:     cfg.LightRAG.URL,
-		APIKey:     cfg.LightRAG.APIKey,
-		Timeout:    time.Duration(cfg.LightRAG.Timeout) * time.Second,
-		MaxRetries: cfg.LightRAG.MaxRetries,
-		RetryDelay: time.Duration(cfg.LightRAG.RetryDelay) * time.Second,
-	}, log)
-
 	trans, err := transformer.NewTransformer(connectorCfg.Transform.Strategy, log)
 	if err != nil {
 		log.Fatal("Failed to create transformer", zap.Error(err))
 	}
 
-	stateManager, err := state.NewStateManager(state.Config{
-		Type: cfg.Storage.Type,
-		Path: cfg.Storage.Path,
-	}, log)
+	stateManager, err := newStateManager(cfg)
 	if err != nil {
 		log.Fatal("Failed to create state manager", zap.Error(err))
 	}
 	defer stateManager.Close()
 
-	orch := orchestrator.NewOrchestrator(memoryClient, lightragClient, trans, stateManager, log)
+	orch := newOrchestrator(cfg, newLightRAGClient(cfg), trans, stateManager, newAnonymizer(cfg))
+	if registry := newAliasRegistry(cfg); registry != nil {
+		orch.SetAliasResolver(registry)
+	}
+	orch.SetFeatureOverrides(newFeatureOverrides(cfg))
+	if fixedTime != "" {
+		at, err := time.Parse(time.RFC3339, fixedTime)
+		if err != nil {
+			log.Fatal("Invalid --fixed-time, expected an RFC 3339 time", zap.String("fixed_time", fixedTime))
+		}
+		orch.SetClock(clock.Fixed(at))
+	}
 
 	// Execute sync
 	log.Info("Starting manual sync", zap.String("connector_id", connectorID))
@@ -219,12 +376,35 @@ func runSync(connectorID string) {
 		fmt.Printf("Processed: %d\n", report.TotalProcessed)
 		fmt.Printf("Skipped: %d\n", report.TotalSkipped)
 		fmt.Printf("Failed: %d\n", report.TotalFailed)
+		if report.TotalAwaitingDigest > 0 {
+			fmt.Printf("Awaiting Digest: %d\n", report.TotalAwaitingDigest)
+		}
+		if report.TotalDuplicates > 0 {
+			fmt.Printf("Duplicates: %d\n", report.TotalDuplicates)
+		}
+		if report.TotalBlocked > 0 {
+			fmt.Printf("Blocked: %d\n", report.TotalBlocked)
+		}
+		if report.TotalDeferred > 0 {
+			fmt.Printf("Deferred: %d\n", report.TotalDeferred)
+		}
 		fmt.Printf("Success Rate: %.2f%%\n", report.CalculateSuccessRate())
+		if report.Aborted {
+			fmt.Printf("Aborted: %s\n", report.ErrorMessage)
+		}
+		if catchUp := report.CatchUp; catchUp != nil {
+			fmt.Printf("Catch-up: queried range %q (limit %d) to cover %s since the last sync",
+				catchUp.QueryRange, catchUp.QueryLimit, catchUp.Gap.Round(time.Minute))
+			if catchUp.Truncated {
+				fmt.Printf(", older memories may be missed")
+			}
+			fmt.Println()
+		}
 
 		if len(report.MemoriesFailed) > 0 {
 			fmt.Printf("\nFailed Items:\n")
 			for _, failed := range report.MemoriesFailed {
-				fmt.Printf("  - %s: %s\n", failed.MemoryID, failed.ErrorMessage)
+				fmt.Printf("  - %s [%s]: %s\n", failed.MemoryID, failed.Category, failed.ErrorMessage)
 			}
 		}
 	}
@@ -234,37 +414,532 @@ func runSync(connectorID string) {
 	}
 }
 
+// loadConfig loads the configuration and tunes the HTTP transport the API clients share
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig(cfgFile, log)
+	if err != nil {
+		return nil, err
+	}
+
+	client.ConfigureTransport(client.TransportConfig{
+		MaxIdleConns:        cfg.HTTPClient.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.HTTPClient.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     cfg.HTTPClient.MaxConnsPerHost,
+		IdleConnTimeout:     time.Duration(cfg.HTTPClient.IdleConnTimeout) * time.Second,
+		KeepAlive:           time.Duration(cfg.HTTPClient.KeepAlive) * time.Second,
+	})
+	return cfg, nil
+}
+
+// newMemoryClient creates the Memory API client from configuration
+func newMemoryClient(apiCfg config.MemoryAPIConfig) *client.MemoryClient {
+	return client.NewMemoryClient(client.MemoryClientConfig{
+		APIURL:     apiCfg.URL,
+		APIKey:     apiCfg.APIKey,
+		OAuth2:     oauth2ClientConfig(apiCfg.OAuth2),
+		Timeout:    time.Duration(apiCfg.Timeout) * time.Second,
+		MaxRetries: apiCfg.MaxRetries,
+		RetryDelay: time.Duration(apiCfg.RetryDelay) * time.Second,
+	}, subsystemLogger("client"))
+}
+
+// newOrchestrator creates the orchestrator with per-connector Memory API clients enabled
+func newOrchestrator(
+	cfg *config.Config,
+	lightragClient client.LightRAGAPI,
+	trans *transformer.Transformer,
+	stateManager state.StateManager,
+	anon *anonymizer.Anonymizer,
+) *orchestrator.Orchestrator {
+	orch := orchestrator.NewOrchestrator(newMemoryClient(cfg.MemoryAPI), lightragClient, trans, stateManager, log)
+	orch.SetMemorySourceFactory(memorySourceFactory(cfg))
+	orch.SetDeadLetterPolicy(cfg.DeadLetters.Policy())
+	if backpressure := cfg.LightRAG.Backpressure; backpressure.Enabled {
+		orch.SetBackpressure(orchestrator.BackpressureConfig{
+			MaxPending:   backpressure.MaxPending,
+			PollInterval: time.Duration(backpressure.PollInterval) * time.Second,
+			MaxWait:      time.Duration(backpressure.MaxWait) * time.Second,
+		})
+	}
+	if anon != nil {
+		orch.SetPseudonymizer(anon)
+	}
+	if cfg.Translation.URL != "" {
+		orch.SetTranslator(newTranslationClient(cfg.Translation))
+	}
+	if len(cfg.ContentFilter.Policies) > 0 {
+		orch.SetContentFilter(newContentFilter(cfg.ContentFilter))
+	}
+	if cfg.Geocoding.URL != "" {
+		orch.SetGeocoder(client.NewGeocodingClient(client.GeocodingClientConfig{
+			APIURL:   cfg.Geocoding.URL,
+			Language: cfg.Geocoding.Language,
+			Timeout:  time.Duration(cfg.Geocoding.Timeout) * time.Second,
+		}, subsystemLogger("client")))
+	}
+	if cfg.TimeZones.URL != "" {
+		orch.SetTimeZoneResolver(client.NewTimeZoneClient(client.TimeZoneClientConfig{
+			APIURL:   cfg.TimeZones.URL,
+			Username: cfg.TimeZones.Username,
+			Timeout:  time.Duration(cfg.TimeZones.Timeout) * time.Second,
+		}, subsystemLogger("client")))
+	}
+
+	return orch
+}
+
+// memorySourceFactory creates the Memory API clients of connectors with memory_api settings
+func memorySourceFactory(cfg *config.Config) orchestrator.MemorySourceFactory {
+	return func(connector *models.ConnectorConfig) client.MemorySource {
+		return newMemoryClient(cfg.MemoryAPIFor(connector))
+	}
+}
+
+// verifySecrets checks rotated credentials against LightRAG and the Memory API before they're
+// applied, so credentials that don't work (yet) never replace ones that do
+func verifySecrets(cfg *config.Config, diff config.ConfigDiff) error {
+	ctx, cancel := context.WithTimeout(context.Background(), secretVerifyTimeout)
+	defer cancel()
+
+	if diff.Rotated(config.SecretLightRAGAPIKey) {
+		if err := newLightRAGClient(cfg).HealthCheck(ctx); err != nil {
+			return fmt.Errorf("rotated LightRAG credentials don't work: %w", err)
+		}
+	}
+
+	if diff.Rotated(config.SecretMemoryAPIKey, config.SecretMemoryAPIOAuth2) {
+		// Fetched from the context of a connector that uses the global memory_api credentials;
+		// without one, nothing uses them
+		for i := range cfg.Connectors {
+			connector := &cfg.Connectors[i]
+			if !connector.Enabled || connector.Simulation.Enabled ||
+				connector.MemoryAPI != nil && (connector.MemoryAPI.URL != "" || connector.MemoryAPI.HasCredentials()) {
+				continue
+			}
+			if _, err := newMemoryClient(cfg.MemoryAPI).GetMemories(ctx, connector.ContextID, 1, "day"); err != nil {
+				return fmt.Errorf("rotated Memory API credentials don't work: %w", err)
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// rotateSecrets switches the clients to rotated credentials, while syncs keep running
+func rotateSecrets(cfg *config.Config, diff config.ConfigDiff, lightragClient *client.LightRAGClient, orch *orchestrator.Orchestrator) {
+	if diff.Rotated(config.SecretLightRAGAPIKey) {
+		lightragClient.SetAPIKey(cfg.LightRAG.APIKey)
+	}
+	if diff.Rotated(config.SecretMemoryAPIKey, config.SecretMemoryAPIOAuth2) {
+		orch.RotateMemoryAPICredentials(cfg.MemoryAPI.APIKey, oauth2ClientConfig(cfg.MemoryAPI.OAuth2), memorySourceFactory(cfg))
+	}
+	if len(diff.RotatedSecrets) > 0 {
+		log.Info("Rotated secrets", zap.Strings("secrets", diff.RotatedSecrets))
+	}
+}
+
+// newTranslationClient creates the translation API client from configuration
+func newTranslationClient(translation config.TranslationConfig) *client.TranslationClient {
+	return client.NewTranslationClient(client.TranslationClientConfig{
+		Provider: translation.Provider,
+		APIURL:   translation.URL,
+		APIKey:   translation.APIKey,
+		Timeout:  time.Duration(translation.Timeout) * time.Second,
+	}, subsystemLogger("client"))
+}
+
+// newContentFilter creates the content filter of connectors with transform.content_filter
+func newContentFilter(contentFilter config.ContentFilterConfig) *contentfilter.WordlistFilter {
+	policies := make([]contentfilter.Policy, len(contentFilter.Policies))
+	for i, policy := range contentFilter.Policies {
+		policies[i] = contentfilter.Policy{
+			Name:     policy.Name,
+			Action:   policy.Action,
+			Words:    policy.Words,
+			Patterns: policy.Patterns,
+		}
+	}
+
+	filter, err := contentfilter.New(policies)
+	if err != nil {
+		log.Fatal("Failed to create content filter", zap.Error(err))
+	}
+	return filter
+}
+
+// newAnonymizer creates the anonymizer of connectors with transform.anonymize, nil without a key
+func newAnonymizer(cfg *config.Config) *anonymizer.Anonymizer {
+	if cfg.Anonymization.Key == "" {
+		return nil
+	}
+
+	anon, err := anonymizer.New(anonymizer.Config{
+		Key:   cfg.Anonymization.Key,
+		Path:  cfg.Anonymization.Path,
+		Names: cfg.Anonymization.Names,
+	}, log)
+	if err != nil {
+		log.Fatal("Failed to create anonymizer", zap.Error(err))
+	}
+	return anon
+}
+
+// newAliasRegistry creates the registry of entity aliases, nil if it's not enabled
+func newAliasRegistry(cfg *config.Config) *aliases.Registry {
+	if !cfg.Aliases.Enabled {
+		return nil
+	}
+
+	registry, err := aliases.New(cfg.Aliases.Path, log)
+	if err != nil {
+		log.Fatal("Failed to create alias registry", zap.Error(err))
+	}
+	return registry
+}
+
+// newFeatureOverrides loads the runtime overrides of connectors' feature flags
+func newFeatureOverrides(cfg *config.Config) *features.Overrides {
+	overrides, err := features.New(cfg.FeatureFlags.Path, log)
+	if err != nil {
+		log.Fatal("Failed to load feature flag overrides", zap.Error(err))
+	}
+	return overrides
+}
+
+// newLightRAGClient creates the LightRAG API client from configuration
+func newLightRAGClient(cfg *config.Config) *client.LightRAGClient {
+	compressMinBytes := 0
+	if cfg.LightRAG.Compression.Enabled {
+		compressMinBytes = max(cfg.LightRAG.Compression.MinBytes, 1)
+	}
+
+	return client.NewLightRAGClient(client.LightRAGClientConfig{
+		APIURL:           cfg.LightRAG.URL,
+		APIKey:           cfg.LightRAG.APIKey,
+		Timeout:          time.Duration(cfg.LightRAG.Timeout) * time.Second,
+		MaxRetries:       cfg.LightRAG.MaxRetries,
+		RetryDelay:       time.Duration(cfg.LightRAG.RetryDelay) * time.Second,
+		CompressMinBytes: compressMinBytes,
+		APIVersion:       cfg.LightRAG.APIVersion,
+	}, subsystemLogger("client"))
+}
+
+// logRedaction converts redaction settings to logger configuration
+func logRedaction(redaction config.RedactionConfig) logger.RedactionConfig {
+	if !redaction.Enabled {
+		return logger.RedactionConfig{}
+	}
+	return logger.RedactionConfig{
+		Keys:      redaction.Keys,
+		MaxLength: redaction.MaxLength,
+	}
+}
+
+// subsystemLogger returns the logger of a subsystem whose level can be changed at runtime,
+// or the global logger outside the service
+func subsystemLogger(subsystem string) *zap.Logger {
+	if logLevels == nil {
+		return log
+	}
+	return logLevels.Logger(subsystem)
+}
+
+// newStateManager creates the state manager from configuration
+func newStateManager(cfg *config.Config) (state.StateManager, error) {
+	var stateCipher *state.Cipher
+	if cfg.Storage.Encryption.Enabled {
+		keys, err := cfg.Storage.Encryption.Keys()
+		if err != nil {
+			return nil, err
+		}
+		if stateCipher, err = state.NewCipher(keys[0], keys[1:]...); err != nil {
+			return nil, err
+		}
+	}
+
+	return state.NewStateManager(state.Config{
+		Type:   cfg.Storage.Type,
+		Path:   cfg.Storage.Path,
+		Cipher: stateCipher,
+	}, log)
+}
+
+// oauth2ClientConfig converts OAuth2 settings to client configuration (nil if not configured)
+func oauth2ClientConfig(oauth config.OAuth2Config) *client.OAuth2Config {
+	if !oauth.Enabled() {
+		return nil
+	}
+	return &client.OAuth2Config{
+		TokenURL:     oauth.TokenURL,
+		ClientID:     oauth.ClientID,
+		ClientSecret: oauth.ClientSecret,
+		RefreshToken: oauth.RefreshToken,
+		Scopes:       oauth.Scopes,
+	}
+}
+
 // runServe starts the service in daemon mode
 func runServe() {
 	// Load configuration
-	cfg, err := config.LoadConfig(cfgFile, log)
+	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatal("Failed to load config", zap.Error(err))
 	}
 
 	// Update logger
-	log, err = logger.NewLogger(logger.LogConfig{
+	// Loggers of the subsystems whose level can be changed at runtime
+	logLevels, err = logger.NewLevels(logger.LogConfig{
 		Level:      cfg.Logging.Level,
 		Format:     cfg.Logging.Format,
 		OutputPath: cfg.Logging.OutputPath,
-	})
+		Redaction:  logRedaction(cfg.Logging.Redaction),
+	}, "client", "scheduler", "api")
 	if err != nil {
 		log.Fatal("Failed to initialize logger", zap.Error(err))
 	}
+	log = logLevels.Root()
 
 	log.Info("Starting Memory Connector service",
 		zap.String("version", "0.1.0"),
 		zap.Int("connectors", len(cfg.Connectors)),
 	)
 
-	// Initialize components (similar to sync but for all connectors)
-	// TODO: Implement full service mode with HTTP API and scheduler
-	log.Fatal("Service mode not yet implemented - use 'sync' command for manual syncs")
+	// Initialize components (transformers are resolved per connector strategy)
+	stateManager, err := newStateManager(cfg)
+	if err != nil {
+		log.Fatal("Failed to create state manager", zap.Error(err))
+	}
+	defer stateManager.Close()
+	if filter := cfg.Storage.DedupFilter; filter.Enabled {
+		if stateManager, err = state.NewFilteredStore(stateManager, filter.FalsePositiveRate, log); err != nil {
+			log.Fatal("Failed to create state manager", zap.Error(err))
+		}
+	}
+
+	lightragClient := newLightRAGClient(cfg)
+	anon := newAnonymizer(cfg)
+	orch := newOrchestrator(cfg, lightragClient, nil, stateManager, anon)
+	aliasRegistry := newAliasRegistry(cfg)
+	if aliasRegistry != nil {
+		orch.SetAliasResolver(aliasRegistry)
+	}
+	featureOverrides := newFeatureOverrides(cfg)
+	orch.SetFeatureOverrides(featureOverrides)
+
+	var auditLog audit.Recorder = audit.NopRecorder{}
+	var auditFile *audit.FileLog
+	if cfg.Audit.Enabled {
+		fileLog, err := audit.NewFileLog(cfg.Audit.Path, log)
+		if err != nil {
+			log.Fatal("Failed to create audit log", zap.Error(err))
+		}
+		auditLog = fileLog
+		auditFile = fileLog
+	}
+
+	// Connector, sync, and ingestion events for the live feed of the API
+	eventBus := events.NewBus()
+	defer eventBus.Close()
+	orch.SetEventPublisher(eventBus)
+
+	// Record the events for forensic queries, continuing the event IDs of earlier runs
+	var eventLog *events.Store
+	if cfg.EventLog.Enabled {
+		eventLog, err = events.NewStore(cfg.EventLog.Path, log)
+		if err != nil {
+			log.Fatal("Failed to open event log", zap.Error(err))
+		}
+		lastID, err := eventLog.LastID()
+		if err != nil {
+			log.Fatal("Failed to read event log", zap.Error(err))
+		}
+		eventBus.SetSequence(lastID)
+		eventLog.Record(eventBus)
+	}
+
+	// Alert on connectors that keep failing
+	var schedulerEvents events.Publisher = eventBus
+	var alerter *alerting.Alerter
+	if cfg.Alerting.Enabled {
+		alerter = newAlerter(cfg.Alerting)
+		schedulerEvents = events.Publishers{eventBus, alerter}
+	}
+
+	// Report panics of syncs and handlers
+	var reporter reporting.Reporter = reporting.Nop{}
+	var sentryReporter *reporting.SentryReporter
+	if cfg.ErrorReporting.DSN != "" {
+		sentryReporter, err = reporting.NewSentryReporter(reporting.Config{
+			DSN:         cfg.ErrorReporting.DSN,
+			Environment: cfg.ErrorReporting.Environment,
+			Release:     "memory-connector@0.1.0",
+		}, log)
+		if err != nil {
+			log.Fatal("Failed to create error reporter", zap.Error(err))
+		}
+		reporter = sentryReporter
+	}
+
+	// Schedule connectors, pinging their heartbeat URLs after successful scheduled syncs
+	sched := scheduler.NewScheduler(orch, subsystemLogger("scheduler"))
+	beats := heartbeat.NewHeartbeat(func(connectorID string) string {
+		connector, _ := sched.ConnectorConfig(connectorID)
+		return connector.Heartbeat.URL
+	}, log)
+	sched.SetEventPublisher(events.Publishers{schedulerEvents, beats})
+	sched.SetErrorReporter(reporter)
+	sched.SetJitter(time.Duration(cfg.Scheduler.Jitter) * time.Second)
+	sched.SetStagger(cfg.Scheduler.Stagger)
+	sched.SetMaxConcurrentSyncs(cfg.Scheduler.MaxConcurrentSyncs)
+	if preflight := cfg.Scheduler.Preflight; preflight.Enabled {
+		sched.SetPreflight(scheduler.PreflightConfig{
+			Timeout:       time.Duration(preflight.Timeout) * time.Second,
+			RetryAfter:    time.Duration(preflight.RetryAfter) * time.Second,
+			MaxRetryAfter: time.Duration(preflight.MaxRetryAfter) * time.Second,
+		})
+	}
+	if dlq := cfg.DeadLetters; dlq.Retry {
+		sched.SetDeadLetterRetry(scheduler.DeadLetterRetryConfig{
+			Interval:    time.Duration(dlq.PollInterval) * time.Second,
+			MaxMemories: dlq.MaxMemories,
+		})
+	}
+	if err := sched.ReconcileConnectors(cfg.Connectors); err != nil {
+		log.Fatal("Failed to schedule connectors", zap.Error(err))
+	}
+	sched.Start()
+
+	// Apply config file changes without restarting
+	currentConfig := func() *config.Config { return cfg }
+	watcher, err := config.NewWatcher(cfgFile, cfg, auditLog, log)
+	if err != nil {
+		log.Warn("Configuration hot reload disabled", zap.Error(err))
+	} else {
+		currentConfig = watcher.Current
+		watcher.Start(
+			func(oldCfg, newCfg *config.Config, diff config.ConfigDiff) error {
+				if err := verifySecrets(newCfg, diff); err != nil {
+					return err
+				}
+				if err := sched.ReconcileConnectors(newCfg.Connectors); err != nil {
+					return err
+				}
+				rotateSecrets(newCfg, diff, lightragClient, orch)
+				return nil
+			},
+			func(newCfg *config.Config) error {
+				return sched.ValidateConnectors(newCfg.Connectors)
+			},
+		)
+	}
+
+	// Start the management API
+	server := api.NewServer(cfg.Server, currentConfig, sched, stateManager, lightragClient, eventBus, subsystemLogger("api"))
+	server.SetTransformerMetrics(orch.TransformerMetrics)
+	server.SetMemoryFetcher(orch)
+	server.SetPlaceNamer(orch)
+	server.SetMemoryAPIChecker(orch)
+	server.SetEventLog(eventLog)
+	server.SetLogLevels(logLevels)
+	server.SetErrorReporter(reporter)
+	if auditFile != nil {
+		server.SetAuditLog(auditFile)
+	}
+	if watcher != nil {
+		server.SetConfigReloader(watcher)
+	}
+	if anon != nil {
+		server.SetPseudonymResolver(anon)
+	}
+	if aliasRegistry != nil {
+		server.SetAliasRegistry(aliasRegistry)
+	}
+	server.SetFeatureOverrides(featureOverrides)
+	if cfg.Server.ChatProxy.Enabled {
+		server.SetChatProxy(lightragClient)
+	}
+	if err := server.Start(); err != nil {
+		log.Fatal("Failed to start API server", zap.Error(err))
+	}
+
+	// Wait for shutdown signal, reloading the configuration and its secrets on SIGHUP
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	sig := <-signals
+	for sig == syscall.SIGHUP {
+		if watcher == nil {
+			log.Warn("Ignoring SIGHUP, configuration hot reload is disabled")
+		} else {
+			go func() {
+				if diff, err := watcher.Reload(config.PrincipalSIGHUP); err == nil && diff.IsEmpty() {
+					log.Info("Reloaded configuration on SIGHUP, nothing changed")
+				}
+			}()
+		}
+		sig = <-signals
+	}
+
+	log.Info("Shutting down", zap.String("signal", sig.String()))
+
+	// Drain requests, stop scheduling, and let running syncs checkpoint
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Warn("Graceful shutdown incomplete", zap.Error(err))
+	}
+	if alerter != nil {
+		if err := alerter.Wait(ctx); err != nil {
+			log.Warn("Alerts still in delivery at shutdown", zap.Error(err))
+		}
+	}
+	if err := beats.Wait(ctx); err != nil {
+		log.Warn("Heartbeats still in progress at shutdown", zap.Error(err))
+	}
+	if sentryReporter != nil {
+		if err := sentryReporter.Wait(ctx); err != nil {
+			log.Warn("Panic reports still in delivery at shutdown", zap.Error(err))
+		}
+	}
+	if eventLog != nil {
+		// Events published until here are recorded before the log is closed
+		eventBus.Close()
+		if err := eventLog.Close(); err != nil {
+			log.Warn("Failed to close event log", zap.Error(err))
+		}
+	}
+}
+
+// newAlerter creates the alerter with the configured notifiers
+func newAlerter(alertCfg config.AlertingConfig) *alerting.Alerter {
+	var notifiers []alerting.Notifier
+	if alertCfg.Webhook.URL != "" {
+		notifiers = append(notifiers, alerting.NewWebhookNotifier(alertCfg.Webhook.URL, alertCfg.Webhook.Headers))
+	}
+	if alertCfg.Slack.WebhookURL != "" {
+		notifiers = append(notifiers, alerting.NewSlackNotifier(alertCfg.Slack.WebhookURL, alertCfg.Slack.Channel))
+	}
+	if alertCfg.Email.SMTPHost != "" {
+		notifiers = append(notifiers, alerting.NewEmailNotifier(alerting.EmailConfig{
+			Host:     alertCfg.Email.SMTPHost,
+			Port:     alertCfg.Email.SMTPPort,
+			Username: alertCfg.Email.Username,
+			Password: alertCfg.Email.Password,
+			From:     alertCfg.Email.From,
+			To:       alertCfg.Email.To,
+		}))
+	}
+
+	return alerting.NewAlerter(alerting.Config{
+		Threshold: alertCfg.Threshold,
+		Cooldown:  time.Duration(alertCfg.Cooldown) * time.Minute,
+		Resolve:   alertCfg.Resolve,
+	}, notifiers, log)
 }
 
 // runList lists all connectors
 func runList() {
-	cfg, err := config.LoadConfig(cfgFile, log)
+	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatal("Failed to load config", zap.Error(err))
 	}
@@ -278,6 +953,9 @@ func runList() {
 			fmt.Printf("ID: %s\n", conn.ID)
 			fmt.Printf("  Enabled: %v\n", conn.Enabled)
 			fmt.Printf("  Context: %s\n", conn.ContextID)
+			if conn.Template != "" {
+				fmt.Printf("  Template: %s\n", conn.Template)
+			}
 			fmt.Printf("  Schedule: %s\n", conn.GetScheduleDescription())
 			fmt.Printf("  Transform: %s\n", conn.Transform.Strategy)
 			fmt.Println()
@@ -287,15 +965,12 @@ func runList() {
 
 // runStatus shows connector status
 func runStatus(connectorID string) {
-	cfg, err := config.LoadConfig(cfgFile, log)
+	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatal("Failed to load config", zap.Error(err))
 	}
 
-	stateManager, err := state.NewStateManager(state.Config{
-		Type: cfg.Storage.Type,
-		Path: cfg.Storage.Path,
-	}, log)
+	stateManager, err := newStateManager(cfg)
 	if err != nil {
 		log.Fatal("Failed to create state manager", zap.Error(err))
 	}
@@ -326,3 +1001,321 @@ func runStatus(connectorID string) {
 		}
 	}
 }
+
+// runClusterPlaces runs the frequent-place clustering job of a connector
+func runClusterPlaces(connectorID string, limit int) {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal("Failed to load config", zap.Error(err))
+	}
+
+	connectorCfg, err := cfg.GetConnectorByID(connectorID)
+	if err != nil {
+		log.Fatal("Connector not found", zap.String("connector_id", connectorID))
+	}
+
+	stateManager, err := newStateManager(cfg)
+	if err != nil {
+		log.Fatal("Failed to create state manager", zap.Error(err))
+	}
+	defer stateManager.Close()
+
+	orch := newOrchestrator(cfg, newLightRAGClient(cfg), nil, stateManager, nil)
+
+	placeState, err := orch.ClusterPlaces(context.Background(), connectorCfg, limit)
+	if err != nil {
+		log.Fatal("Clustering failed", zap.Error(err))
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(placeState.Clusters, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("\n=== Frequent Places ===\n")
+		fmt.Printf("Connector ID: %s\n", connectorCfg.ID)
+		fmt.Printf("Locations: %d\n", len(placeState.History))
+		fmt.Printf("Places: %d\n", len(placeState.Clusters))
+		for _, place := range placeState.Clusters {
+			fmt.Printf("  %s  %-18s %.5f,%.5f  radius %.0f m  %d memories\n",
+				place.ID, place.Label, place.Lat, place.Lon, place.RadiusM, place.Memories)
+		}
+	}
+}
+
+// runSnapshot writes a context snapshot to an archive
+func runSnapshot(contextID, output string, limit int) {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal("Failed to load config", zap.Error(err))
+	}
+
+	stateManager, err := newStateManager(cfg)
+	if err != nil {
+		log.Fatal("Failed to create state manager", zap.Error(err))
+	}
+	defer stateManager.Close()
+
+	orch := newOrchestrator(cfg, newLightRAGClient(cfg), nil, stateManager, newAnonymizer(cfg))
+	if registry := newAliasRegistry(cfg); registry != nil {
+		orch.SetAliasResolver(registry)
+	}
+	orch.SetFeatureOverrides(newFeatureOverrides(cfg))
+
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Fatal("Failed to create snapshot archive", zap.Error(err))
+	}
+
+	manifest, err := orch.Snapshot(context.Background(), contextID, cfg.Connectors, limit, file)
+	if err == nil {
+		err = file.Close()
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(output)
+		log.Fatal("Snapshot failed", zap.Error(err))
+	}
+
+	if len(manifest.Missing) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d memories are missing from the snapshot and dropped from its sync state, the restored connectors ingest them again on their next sync. Raise --limit if they're older than the latest %d memories.\n",
+			len(manifest.Missing), limit)
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(manifest, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("\n=== Snapshot ===\n")
+		fmt.Printf("Context ID: %s\n", manifest.ContextID)
+		fmt.Printf("Archive: %s\n", output)
+		fmt.Printf("Connectors: %v\n", manifest.ConnectorIDs)
+		fmt.Printf("Documents: %d\n", manifest.Documents)
+		if len(manifest.Missing) > 0 {
+			fmt.Printf("Missing: %d (no longer served by the Memory API, beyond --limit, or blocked)\n", len(manifest.Missing))
+		}
+		if len(manifest.DigestConnectors) > 0 {
+			fmt.Printf("Digest connectors (documents not included): %v\n", manifest.DigestConnectors)
+		}
+	}
+}
+
+// runRestore restores a context snapshot from an archive
+func runRestore(input string) {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal("Failed to load config", zap.Error(err))
+	}
+
+	file, err := os.Open(input)
+	if err != nil {
+		log.Fatal("Failed to open snapshot archive", zap.Error(err))
+	}
+	snapshot, err := orchestrator.ReadSnapshot(file)
+	file.Close()
+	if err != nil {
+		log.Fatal("Failed to read snapshot archive", zap.Error(err))
+	}
+
+	stateManager, err := newStateManager(cfg)
+	if err != nil {
+		log.Fatal("Failed to create state manager", zap.Error(err))
+	}
+	defer stateManager.Close()
+
+	orch := newOrchestrator(cfg, newLightRAGClient(cfg), nil, stateManager, nil)
+
+	result, err := orch.RestoreSnapshot(context.Background(), snapshot, cfg.Connectors)
+	if err != nil {
+		log.Fatal("Restore failed", zap.Error(err))
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("\n=== Restore ===\n")
+		fmt.Printf("Context ID: %s\n", result.ContextID)
+		fmt.Printf("Snapshot Taken: %s\n", snapshot.Manifest.CreatedAt.Format(time.RFC3339))
+		fmt.Printf("Restored Documents: %d\n", result.RestoredDocuments)
+		fmt.Printf("Restored States: %v\n", result.RestoredStates)
+	}
+}
+
+// runImport inserts the documents of a JSON Lines export into LightRAG
+func runImport(input, connectorID string) {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal("Failed to load config", zap.Error(err))
+	}
+
+	file, err := os.Open(input)
+	if err != nil {
+		log.Fatal("Failed to open export file", zap.Error(err))
+	}
+	documents, err := export.ReadDocuments(file)
+	file.Close()
+	if err != nil {
+		log.Fatal("Failed to read export file", zap.Error(err))
+	}
+
+	stateManager, err := newStateManager(cfg)
+	if err != nil {
+		log.Fatal("Failed to create state manager", zap.Error(err))
+	}
+	defer stateManager.Close()
+
+	orch := newOrchestrator(cfg, newLightRAGClient(cfg), nil, stateManager, nil)
+
+	result, err := orch.ImportDocuments(context.Background(), documents, cfg.Connectors, connectorID)
+	if err != nil {
+		log.Fatal("Import failed", zap.Error(err))
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("\n=== Import ===\n")
+		fmt.Printf("Connectors: %v\n", result.Connectors)
+		fmt.Printf("Documents: %d\n", result.Documents)
+		fmt.Printf("Imported: %d\n", result.Imported)
+		fmt.Printf("Duplicates: %d\n", result.Duplicates)
+	}
+}
+
+// runBench benchmarks ingestion throughput at each concurrency level
+func runBench(connectorID, contextID string, documents int, levels []int, wait, cleanup bool) {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal("Failed to load config", zap.Error(err))
+	}
+	if documents < 1 {
+		log.Fatal("--documents must be at least 1")
+	}
+	for _, level := range levels {
+		if level < 1 || level > 1000 {
+			log.Fatal("--concurrency levels must be between 1 and 1000", zap.Int("concurrency", level))
+		}
+	}
+
+	connector := models.ConnectorConfig{
+		ID:        "bench",
+		Transform: models.TransformConfig{Strategy: "rich", IncludeMetadata: true},
+	}
+	if connectorID != "" {
+		configured, err := cfg.GetConnectorByID(connectorID)
+		if err != nil {
+			log.Fatal("Connector not found", zap.String("connector_id", connectorID))
+		}
+		if configured.Transform.Mode == models.TransformModeDailyDigest {
+			log.Fatal("Daily digest connectors can't be benchmarked, their documents cover whole days", zap.String("connector_id", connectorID))
+		}
+		connector = *configured
+	}
+	// Keep the documents out of the connector's context, and make them differ from earlier runs,
+	// since LightRAG skips documents it already holds
+	connector.ContextID = contextID
+	connector.Simulation.Enabled = true
+	connector.Simulation.Seed = time.Now().UnixNano()
+	connector.ApplyDefaults()
+
+	// Each level inserts its own documents
+	memories := simulation.New(connector.Simulation, clock.System).Generate(contextID, documents*len(levels), time.Now())
+
+	orch := newOrchestrator(cfg, newLightRAGClient(cfg), nil, nil, newAnonymizer(cfg))
+	if registry := newAliasRegistry(cfg); registry != nil {
+		orch.SetAliasResolver(registry)
+	}
+	orch.SetFeatureOverrides(newFeatureOverrides(cfg))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	result := &models.BenchmarkResult{ConnectorID: connector.ID, Strategy: connector.Transform.Strategy}
+	var inserted []string
+	var benchErr error
+	for i, level := range levels {
+		log.Info("Benchmarking", zap.Int("concurrency", level), zap.Int("documents", documents))
+		run, docIDs, err := orch.Benchmark(ctx, &connector, memories[i*documents:(i+1)*documents], level, wait)
+		inserted = append(inserted, docIDs...)
+		if err != nil {
+			benchErr = err
+			break
+		}
+		result.Runs = append(result.Runs, *run)
+	}
+
+	if cleanup && len(inserted) > 0 {
+		deleted, err := orch.DeleteBenchmarkDocuments(context.Background(), inserted)
+		result.DeletedDocuments = deleted
+		if err != nil {
+			log.Error("Failed to delete benchmark documents", zap.Error(err))
+		}
+	}
+	if benchErr != nil {
+		log.Fatal("Benchmark failed", zap.Error(benchErr), zap.Int("inserted_documents", len(inserted)))
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("\n=== Benchmark ===\n")
+	fmt.Printf("Connector: %s (%s)\n", result.ConnectorID, result.Strategy)
+	fmt.Printf("Documents per Level: %d\n\n", documents)
+	fmt.Printf("%-12s %10s %10s %10s %10s %8s", "Concurrency", "Docs/s", "P50 ms", "P95 ms", "Max ms", "Errors")
+	if wait {
+		fmt.Printf(" %12s", "Processed/s")
+	}
+	fmt.Println()
+	for _, run := range result.Runs {
+		fmt.Printf("%-12d %10.1f %10d %10d %10d %7.1f%%",
+			run.Concurrency, run.DocsPerSecond, run.P50LatencyMs, run.P95LatencyMs, run.MaxLatencyMs, run.ErrorRate*100)
+		if wait {
+			fmt.Printf(" %12.2f", run.ProcessedPerSecond)
+		}
+		fmt.Println()
+		categories := make([]string, 0, len(run.Errors))
+		for category := range run.Errors {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			fmt.Printf("  %s: %d\n", category, run.Errors[category])
+		}
+	}
+	if cleanup {
+		fmt.Printf("\nDeleted Documents: %d\n", result.DeletedDocuments)
+	}
+}
+
+// runValidateConfig validates the configuration file and exits non-zero on violations
+func runValidateConfig() {
+	violations, err := config.ValidateFile(cfgFile, log)
+	if err != nil {
+		log.Fatal("Failed to validate config", zap.Error(err))
+	}
+
+	if jsonOutput {
+		result := map[string]interface{}{
+			"file":       cfgFile,
+			"valid":      len(violations) == 0,
+			"violations": violations,
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else if len(violations) == 0 {
+		fmt.Printf("%s: configuration is valid\n", cfgFile)
+	} else {
+		fmt.Printf("%s: %d violation(s)\n", cfgFile, len(violations))
+		for _, violation := range violations {
+			fmt.Printf("  %s\n", violation.Error())
+		}
+	}
+
+	if len(violations) > 0 {
+		os.Exit(1)
+	}
+}
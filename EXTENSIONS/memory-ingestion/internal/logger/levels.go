@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Levels creates the loggers of the service's subsystems, each with a log level that can be changed
+// at runtime. Entries of other code are logged at the configured level.
+type Levels struct {
+	core   zapcore.Core // accepts every level, filtered per subsystem
+	root   *zap.Logger
+	levels map[string]zap.AtomicLevel
+}
+
+// NewLevels creates the root logger and one logger per subsystem, all starting at the configured level
+func NewLevels(config LogConfig, subsystems ...string) (*Levels, error) {
+	level, err := parseLevel(config.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	core, err := newCore(config, zapcore.DebugLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Levels{
+		core:   core,
+		levels: make(map[string]zap.AtomicLevel, len(subsystems)),
+	}
+	l.root = zap.New(&levelCore{Core: core, level: zap.NewAtomicLevelAt(level)}, loggerOptions()...)
+	for _, subsystem := range subsystems {
+		l.levels[subsystem] = zap.NewAtomicLevelAt(level)
+	}
+
+	return l, nil
+}
+
+// Root returns the logger of code outside the subsystems
+func (l *Levels) Root() *zap.Logger {
+	return l.root
+}
+
+// Logger returns the logger of a subsystem (the root logger for unknown subsystems)
+func (l *Levels) Logger(subsystem string) *zap.Logger {
+	level, ok := l.levels[subsystem]
+	if !ok {
+		return l.root
+	}
+	return zap.New(&levelCore{Core: l.core, level: level}, loggerOptions()...).Named(subsystem)
+}
+
+// SetLevel changes the log level of a subsystem, or of all subsystems if subsystem is empty
+func (l *Levels) SetLevel(subsystem, level string) error {
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q (must be debug, info, warn, or error)", level)
+	}
+
+	if subsystem == "" {
+		for _, atomic := range l.levels {
+			atomic.SetLevel(parsed)
+		}
+		return nil
+	}
+
+	atomic, ok := l.levels[subsystem]
+	if !ok {
+		return fmt.Errorf("unknown subsystem %q (must be one of %v)", subsystem, l.Subsystems())
+	}
+	atomic.SetLevel(parsed)
+	return nil
+}
+
+// Levels returns the current log level of each subsystem
+func (l *Levels) Levels() map[string]string {
+	levels := make(map[string]string, len(l.levels))
+	for subsystem, atomic := range l.levels {
+		levels[subsystem] = atomic.Level().String()
+	}
+	return levels
+}
+
+// Subsystems returns the names of the subsystems, sorted
+func (l *Levels) Subsystems() []string {
+	subsystems := make([]string, 0, len(l.levels))
+	for subsystem := range l.levels {
+		subsystems = append(subsystems, subsystem)
+	}
+	sort.Strings(subsystems)
+	return subsystems
+}
+
+// levelCore filters a core by a level that can change at runtime
+type levelCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+// Enabled implements zapcore.LevelEnabler
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+// With implements zapcore.Core
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+// Check implements zapcore.Core
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
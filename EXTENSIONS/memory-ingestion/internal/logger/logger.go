@@ -13,6 +13,7 @@ type LogConfig struct {
 	Level      string // debug, info, warn, error
 	Format     string // json or console (as per user's answer: both, configurable)
 	OutputPath string // file path or stdout
+	Redaction  RedactionConfig
 }
 
 // NewLogger creates a new zap logger based on configuration
@@ -23,6 +24,24 @@ func NewLogger(config LogConfig) (*zap.Logger, error) {
 		return nil, err
 	}
 
+	core, err := newCore(config, level)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create logger
+	logger := zap.New(core, loggerOptions()...)
+
+	return logger, nil
+}
+
+// loggerOptions are the options of every logger
+func loggerOptions() []zap.Option {
+	return []zap.Option{zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)}
+}
+
+// newCore creates the core writing entries at or above level in the configured format and output
+func newCore(config LogConfig, level zapcore.LevelEnabler) (zapcore.Core, error) {
 	// Create encoder config
 	encoderConfig := zapcore.EncoderConfig{
 		TimeKey:        "timestamp",
@@ -63,13 +82,12 @@ func NewLogger(config LogConfig) (*zap.Logger, error) {
 		writeSyncer = zapcore.AddSync(file)
 	}
 
-	// Create core
+	// Create core, masking sensitive values if configured
 	core := zapcore.NewCore(encoder, writeSyncer, level)
-
-	// Create logger
-	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
-
-	return logger, nil
+	if redactor := newRedactor(config.Redaction); redactor != nil {
+		return &redactCore{Core: core, redactor: redactor}, nil
+	}
+	return core, nil
 }
 
 // parseLevel converts string level to zapcore.Level
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue replaces the values of redacted keys
+const redactedValue = "[REDACTED]"
+
+// RedactionConfig masks sensitive values before they are logged
+type RedactionConfig struct {
+	Keys      []string // log fields and metadata map keys whose values are masked (case-insensitive)
+	MaxLength int      // longer strings and error messages are truncated, 0 keeps them whole
+}
+
+// redactor rewrites the fields of log entries
+type redactor struct {
+	keys      map[string]bool
+	maxLength int
+}
+
+// newRedactor creates a redactor, nil if config redacts nothing
+func newRedactor(config RedactionConfig) *redactor {
+	if len(config.Keys) == 0 && config.MaxLength <= 0 {
+		return nil
+	}
+
+	r := &redactor{keys: make(map[string]bool, len(config.Keys)), maxLength: config.MaxLength}
+	for _, key := range config.Keys {
+		r.keys[strings.ToLower(key)] = true
+	}
+	return r
+}
+
+// fields returns fields with sensitive values masked and long values truncated
+func (r *redactor) fields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = r.field(field)
+	}
+	return redacted
+}
+
+// field masks or truncates a single field
+func (r *redactor) field(field zapcore.Field) zapcore.Field {
+	if r.keys[strings.ToLower(field.Key)] {
+		return zap.String(field.Key, redactedValue)
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		return zap.String(field.Key, r.truncate(field.String))
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok && r.maxLength > 0 && len(err.Error()) > r.maxLength {
+			return zap.String(field.Key, r.truncate(err.Error()))
+		}
+	case zapcore.ReflectType:
+		switch value := field.Interface.(type) {
+		case map[string]string:
+			masked := make(map[string]string, len(value))
+			for key, v := range value {
+				masked[key] = r.value(key, v)
+			}
+			return zap.Any(field.Key, masked)
+		case map[string]interface{}:
+			masked := make(map[string]interface{}, len(value))
+			for key, v := range value {
+				if s, ok := v.(string); ok {
+					masked[key] = r.value(key, s)
+				} else if r.keys[strings.ToLower(key)] {
+					masked[key] = redactedValue
+				} else {
+					masked[key] = v
+				}
+			}
+			return zap.Any(field.Key, masked)
+		}
+	}
+
+	return field
+}
+
+// value masks or truncates the value of a map entry
+func (r *redactor) value(key, value string) string {
+	if r.keys[strings.ToLower(key)] {
+		return redactedValue
+	}
+	return r.truncate(value)
+}
+
+// truncate shortens value to the maximum length, noting its original length
+func (r *redactor) truncate(value string) string {
+	if r.maxLength <= 0 || len(value) <= r.maxLength {
+		return value
+	}
+
+	cut := r.maxLength
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... (truncated, %d bytes)", value[:cut], len(value))
+}
+
+// redactCore redacts the fields of every entry before passing it to the wrapped core
+type redactCore struct {
+	zapcore.Core
+	redactor *redactor
+}
+
+// With implements zapcore.Core
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactor.fields(fields)), redactor: c.redactor}
+}
+
+// Check implements zapcore.Core
+func (c *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write implements zapcore.Core
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, c.redactor.fields(fields))
+}
@@ -0,0 +1,237 @@
+// Package alerting notifies operators when connectors keep failing: after a number of consecutive
+// failed syncs an alert fires, repeats while the connector keeps failing (at most once per cooldown),
+// and resolves with the next successful sync. A sync aborted because too many of its memories failed
+// fires the alert right away.
+package alerting
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/events"
+	"go.uber.org/zap"
+)
+
+// notifyTimeout bounds the delivery of one alert to one notifier
+const notifyTimeout = 30 * time.Second
+
+// Alert states
+const (
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// Alert is a notification about a failing (or recovered) connector
+type Alert struct {
+	ConnectorID         string     `json:"connector_id"`
+	State               string     `json:"state"`                // firing or resolved
+	ConsecutiveFailures int        `json:"consecutive_failures"` // for resolved alerts: failures before the recovery
+	LastError           string     `json:"last_error,omitempty"`
+	FiredAt             time.Time  `json:"fired_at"`
+	ResolvedAt          *time.Time `json:"resolved_at,omitempty"`
+	Repeat              bool       `json:"repeat,omitempty"` // true if the connector was already alerted for this failure streak
+}
+
+// Notifier defines the interface for alert delivery channels
+type Notifier interface {
+	// Name identifies the notifier in logs
+	Name() string
+
+	// Notify delivers an alert
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Config holds alerting configuration
+type Config struct {
+	Threshold int           // consecutive failed syncs before an alert fires
+	Cooldown  time.Duration // minimum time between alerts for a connector that keeps failing
+	Resolve   bool          // notify when a connector recovers
+}
+
+// connectorState tracks the failure streak of a connector
+type connectorState struct {
+	failures     int
+	lastError    string
+	firedAt      time.Time // zero while no alert is firing
+	lastNotified time.Time
+}
+
+// Alerter tracks consecutive sync failures per connector and notifies its notifiers.
+// It consumes sync and connector events, so it's wired in as an events.Publisher.
+type Alerter struct {
+	config    Config
+	notifiers []Notifier
+	logger    *zap.Logger
+	now       func() time.Time
+
+	mu         sync.Mutex
+	connectors map[string]*connectorState
+	pending    sync.WaitGroup
+}
+
+// NewAlerter creates an alerter delivering alerts to notifiers
+func NewAlerter(config Config, notifiers []Notifier, logger *zap.Logger) *Alerter {
+	if config.Threshold < 1 {
+		config.Threshold = 1
+	}
+
+	return &Alerter{
+		config:     config,
+		notifiers:  notifiers,
+		logger:     logger,
+		now:        time.Now,
+		connectors: make(map[string]*connectorState),
+	}
+}
+
+// Publish updates the failure streak of the event's connector. Alerts are delivered in the background.
+func (a *Alerter) Publish(event events.Event) {
+	switch event.Type {
+	case events.TypeSyncFailed:
+		a.recordFailure(event.ConnectorID, eventError(event), eventAborted(event))
+	case events.TypeSyncCompleted:
+		if eventStatus(event) != "interrupted" {
+			a.recordSuccess(event.ConnectorID)
+		}
+	case events.TypeConnectorRemoved:
+		a.mu.Lock()
+		delete(a.connectors, event.ConnectorID)
+		a.mu.Unlock()
+	}
+}
+
+// recordFailure extends a connector's failure streak and fires (or repeats) its alert. Aborted syncs
+// fire it regardless of the threshold.
+func (a *Alerter) recordFailure(connectorID, errorMessage string, aborted bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, ok := a.connectors[connectorID]
+	if !ok {
+		state = &connectorState{}
+		a.connectors[connectorID] = state
+	}
+	state.failures++
+	state.lastError = errorMessage
+
+	if state.failures < a.config.Threshold && !aborted {
+		return
+	}
+
+	now := a.now()
+	repeat := !state.firedAt.IsZero()
+	if repeat && now.Sub(state.lastNotified) < a.config.Cooldown {
+		return
+	}
+	if !repeat {
+		state.firedAt = now
+	}
+	state.lastNotified = now
+
+	a.dispatch(Alert{
+		ConnectorID:         connectorID,
+		State:               StateFiring,
+		ConsecutiveFailures: state.failures,
+		LastError:           state.lastError,
+		FiredAt:             state.firedAt,
+		Repeat:              repeat,
+	})
+}
+
+// recordSuccess ends a connector's failure streak and resolves its alert
+func (a *Alerter) recordSuccess(connectorID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, ok := a.connectors[connectorID]
+	if !ok {
+		return
+	}
+	delete(a.connectors, connectorID)
+
+	if state.firedAt.IsZero() || !a.config.Resolve {
+		return
+	}
+
+	resolvedAt := a.now()
+	a.dispatch(Alert{
+		ConnectorID:         connectorID,
+		State:               StateResolved,
+		ConsecutiveFailures: state.failures,
+		LastError:           state.lastError,
+		FiredAt:             state.firedAt,
+		ResolvedAt:          &resolvedAt,
+	})
+}
+
+// dispatch delivers an alert to all notifiers in the background
+func (a *Alerter) dispatch(alert Alert) {
+	log := a.logger.Warn
+	if alert.State == StateResolved {
+		log = a.logger.Info
+	}
+	log("Connector alert",
+		zap.String("connector_id", alert.ConnectorID),
+		zap.String("state", alert.State),
+		zap.Int("consecutive_failures", alert.ConsecutiveFailures),
+		zap.String("last_error", alert.LastError),
+	)
+
+	for _, notifier := range a.notifiers {
+		a.pending.Add(1)
+		go func(notifier Notifier) {
+			defer a.pending.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			defer cancel()
+
+			if err := notifier.Notify(ctx, alert); err != nil {
+				a.logger.Error("Failed to deliver alert",
+					zap.String("notifier", notifier.Name()),
+					zap.String("connector_id", alert.ConnectorID),
+					zap.String("state", alert.State),
+					zap.Error(err),
+				)
+			}
+		}(notifier)
+	}
+}
+
+// Wait blocks until alerts in delivery are delivered or ctx is done
+func (a *Alerter) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// eventError returns the error message of a sync.failed event
+func eventError(event events.Event) string {
+	data, _ := event.Data.(map[string]interface{})
+	message, _ := data["error"].(string)
+	return message
+}
+
+// eventAborted returns true if a sync.failed event is of a sync aborted because too many of its
+// memories failed
+func eventAborted(event events.Event) bool {
+	data, _ := event.Data.(map[string]interface{})
+	aborted, _ := data["aborted"].(bool)
+	return aborted
+}
+
+// eventStatus returns the report status of a sync.completed event
+func eventStatus(event events.Event) string {
+	data, _ := event.Data.(map[string]interface{})
+	status, _ := data["status"].(string)
+	return status
+}
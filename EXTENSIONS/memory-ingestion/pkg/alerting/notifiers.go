@@ -0,0 +1,200 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookNotifier posts alerts as JSON to a URL
+type WebhookNotifier struct {
+	url        string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+// NewWebhookNotifier creates a notifier posting alerts to url with the given extra headers
+func NewWebhookNotifier(url string, headers map[string]string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:        url,
+		headers:    headers,
+		httpClient: &http.Client{Timeout: notifyTimeout},
+	}
+}
+
+// Name identifies the notifier in logs
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify posts the alert
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, n.httpClient, n.url, n.headers, alert)
+}
+
+// SlackNotifier posts alerts to a Slack incoming webhook
+type SlackNotifier struct {
+	webhookURL string
+	channel    string
+	httpClient *http.Client
+}
+
+// NewSlackNotifier creates a notifier posting to a Slack incoming webhook. channel overrides
+// the webhook's default channel if not empty.
+func NewSlackNotifier(webhookURL, channel string) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		channel:    channel,
+		httpClient: &http.Client{Timeout: notifyTimeout},
+	}
+}
+
+// Name identifies the notifier in logs
+func (n *SlackNotifier) Name() string {
+	return "slack"
+}
+
+// Notify posts the alert as a Slack message
+func (n *SlackNotifier) Notify(ctx context.Context, alert Alert) error {
+	message := map[string]string{"text": slackText(alert)}
+	if n.channel != "" {
+		message["channel"] = n.channel
+	}
+	return postJSON(ctx, n.httpClient, n.webhookURL, nil, message)
+}
+
+// slackText formats an alert as Slack mrkdwn
+func slackText(alert Alert) string {
+	if alert.State == StateResolved {
+		return fmt.Sprintf(":white_check_mark: Connector `%s` recovered after %d failed syncs (failing since %s)",
+			alert.ConnectorID, alert.ConsecutiveFailures, alert.FiredAt.UTC().Format(time.RFC3339))
+	}
+
+	text := fmt.Sprintf(":rotating_light: Connector `%s` failed %d consecutive syncs", alert.ConnectorID, alert.ConsecutiveFailures)
+	if alert.Repeat {
+		text += fmt.Sprintf(" (still failing, first alerted %s)", alert.FiredAt.UTC().Format(time.RFC3339))
+	}
+	if alert.LastError != "" {
+		text += "\n>" + alert.LastError
+	}
+	return text
+}
+
+// EmailConfig holds the SMTP settings of the email notifier
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string // optional, enables SMTP AUTH PLAIN
+	Password string
+	From     string
+	To       []string
+}
+
+// EmailNotifier sends alerts by email via SMTP. STARTTLS is used if the server supports it.
+type EmailNotifier struct {
+	config EmailConfig
+}
+
+// NewEmailNotifier creates an SMTP email notifier
+func NewEmailNotifier(config EmailConfig) *EmailNotifier {
+	return &EmailNotifier{config: config}
+}
+
+// Name identifies the notifier in logs
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+// Notify sends the alert. net/smtp doesn't take a context, so ctx only bounds the wait.
+func (n *EmailNotifier) Notify(ctx context.Context, alert Alert) error {
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, n.config.From, n.config.To, n.message(alert))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send email: %w", ctx.Err())
+	}
+}
+
+// message formats an alert as an RFC 5322 plain text email
+func (n *EmailNotifier) message(alert Alert) []byte {
+	subject := fmt.Sprintf("[memory-connector] FIRING: connector %s is failing", alert.ConnectorID)
+	if alert.State == StateResolved {
+		subject = fmt.Sprintf("[memory-connector] RESOLVED: connector %s recovered", alert.ConnectorID)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Connector: %s\r\n", alert.ConnectorID)
+	fmt.Fprintf(&body, "State: %s\r\n", alert.State)
+	fmt.Fprintf(&body, "Consecutive failures: %d\r\n", alert.ConsecutiveFailures)
+	fmt.Fprintf(&body, "Failing since: %s\r\n", alert.FiredAt.UTC().Format(time.RFC3339))
+	if alert.ResolvedAt != nil {
+		fmt.Fprintf(&body, "Resolved at: %s\r\n", alert.ResolvedAt.UTC().Format(time.RFC3339))
+	}
+	if alert.LastError != "" {
+		fmt.Fprintf(&body, "Last error: %s\r\n", alert.LastError)
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(n.config.To, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	message.WriteString(body.String())
+
+	return message.Bytes()
+}
+
+// postJSON posts v as JSON and fails on non-2xx responses
+func postJSON(ctx context.Context, httpClient *http.Client, url string, headers map[string]string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+// Package aliases keeps the entity aliases of each context, e.g. "Mike" for "Michael Schmidt".
+// Documents name the canonical entities of the aliases they use and lookups expand aliases, so
+// LightRAG doesn't split one person or place into several entities.
+package aliases
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalidAlias is wrapped by the errors of Set for aliases that can't be registered
+var ErrInvalidAlias = errors.New("invalid alias")
+
+// Registry holds the aliases of each context, stored in a JSON file. Aliases match regardless of
+// case.
+type Registry struct {
+	path string
+
+	mu       sync.RWMutex
+	contexts map[string]map[string]string // context ID -> alias -> canonical name
+}
+
+// New creates a registry stored at path, loading the aliases saved there
+func New(path string, logger *zap.Logger) (*Registry, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create alias directory: %w", err)
+	}
+
+	r := &Registry{path: path, contexts: make(map[string]map[string]string)}
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read aliases: %w", err)
+	default:
+		if err := json.Unmarshal(data, &r.contexts); err != nil {
+			return nil, fmt.Errorf("failed to decode aliases: %w", err)
+		}
+	}
+
+	logger.Info("Initialized alias registry", zap.String("path", path), zap.Int("contexts", len(r.contexts)))
+	return r, nil
+}
+
+// Aliases returns a copy of the aliases of a context, alias -> canonical name
+func (r *Registry) Aliases(contextID string) map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	aliases := make(map[string]string, len(r.contexts[contextID]))
+	for alias, canonical := range r.contexts[contextID] {
+		aliases[alias] = canonical
+	}
+	return aliases
+}
+
+// Set registers alias as another name of canonical in a context, replacing the alias if it's
+// registered already (in any case). Canonical names can't be aliases themselves, so aliases never
+// chain.
+func (r *Registry) Set(contextID, alias, canonical string) error {
+	alias, canonical = strings.TrimSpace(alias), strings.TrimSpace(canonical)
+	switch {
+	case alias == "" || canonical == "":
+		return fmt.Errorf("%w: alias and canonical name are required", ErrInvalidAlias)
+	case strings.EqualFold(alias, canonical):
+		return fmt.Errorf("%w: %q is its own canonical name", ErrInvalidAlias, alias)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	aliases := r.contexts[contextID]
+	if existing, ok := lookup(aliases, canonical); ok {
+		return fmt.Errorf("%w: canonical name %q is an alias of %q", ErrInvalidAlias, canonical, existing)
+	}
+	for other, otherCanonical := range aliases {
+		if strings.EqualFold(otherCanonical, alias) {
+			return fmt.Errorf("%w: %q is the canonical name of %q", ErrInvalidAlias, alias, other)
+		}
+	}
+
+	updated := make(map[string]string, len(aliases)+1)
+	for other, otherCanonical := range aliases {
+		if !strings.EqualFold(other, alias) {
+			updated[other] = otherCanonical
+		}
+	}
+	updated[alias] = canonical
+	return r.save(contextID, updated)
+}
+
+// Delete removes an alias of a context. It returns false if the alias isn't registered.
+func (r *Registry) Delete(contextID, alias string) (bool, error) {
+	alias = strings.TrimSpace(alias)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	aliases := r.contexts[contextID]
+	updated := make(map[string]string, len(aliases))
+	for other, canonical := range aliases {
+		if !strings.EqualFold(other, alias) {
+			updated[other] = canonical
+		}
+	}
+	if len(updated) == len(aliases) {
+		return false, nil
+	}
+	return true, r.save(contextID, updated)
+}
+
+// Expand returns the canonical name of name if it's an alias in the given contexts (all contexts
+// if none are given) that all agree on, and name itself otherwise
+func (r *Registry) Expand(name string, contextIDs ...string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(contextIDs) == 0 {
+		for contextID := range r.contexts {
+			contextIDs = append(contextIDs, contextID)
+		}
+	}
+
+	expanded := ""
+	for _, contextID := range contextIDs {
+		canonical, ok := lookup(r.contexts[contextID], strings.TrimSpace(name))
+		if !ok {
+			continue
+		}
+		if expanded != "" && expanded != canonical {
+			return name // ambiguous across contexts
+		}
+		expanded = canonical
+	}
+	if expanded == "" {
+		return name
+	}
+	return expanded
+}
+
+// CanonicalNames returns the aliases of a context that text uses as whole words, with their
+// canonical names
+func (r *Registry) CanonicalNames(contextID, text string) map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	used := make(map[string]string)
+	if len(r.contexts[contextID]) == 0 {
+		return used
+	}
+
+	lowered := strings.ToLower(text)
+	for alias, canonical := range r.contexts[contextID] {
+		if containsWord(lowered, strings.ToLower(alias)) {
+			used[alias] = canonical
+		}
+	}
+	return used
+}
+
+// lookup finds an alias in a context's aliases, ignoring case
+func lookup(aliases map[string]string, alias string) (string, bool) {
+	if canonical, ok := aliases[alias]; ok {
+		return canonical, true
+	}
+	for other, canonical := range aliases {
+		if strings.EqualFold(other, alias) {
+			return canonical, true
+		}
+	}
+	return "", false
+}
+
+// containsWord returns true if text contains word not surrounded by letters or digits
+func containsWord(text, word string) bool {
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(word)
+
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		offset = start + size
+	}
+}
+
+// isWordRune returns true for letters and digits
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// save replaces the aliases of a context and writes the registry, replacing the previous file
+// atomically. Called with r.mu held.
+func (r *Registry) save(contextID string, aliases map[string]string) error {
+	contexts := make(map[string]map[string]string, len(r.contexts)+1)
+	for other, otherAliases := range r.contexts {
+		contexts[other] = otherAliases
+	}
+	if len(aliases) > 0 {
+		contexts[contextID] = aliases
+	} else {
+		delete(contexts, contextID)
+	}
+
+	data, err := json.MarshalIndent(contexts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode aliases: %w", err)
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write aliases: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("failed to write aliases: %w", err)
+	}
+
+	r.contexts = contexts
+	return nil
+}
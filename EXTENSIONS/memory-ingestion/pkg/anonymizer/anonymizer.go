@@ -0,0 +1,227 @@
+// Package anonymizer replaces person names in memory transcripts with stable pseudonyms before they
+// are ingested, so the LightRAG graph can be shared or hosted externally. The mapping of each context
+// is stored encrypted on local disk, so pseudonyms can be resolved back to real names.
+package anonymizer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/kamir/memory-connector/pkg/seal"
+	"go.uber.org/zap"
+)
+
+// pseudonymPrefix starts every pseudonym, so LightRAG still extracts them as person entities
+const pseudonymPrefix = "Person "
+
+// pseudonymPattern matches pseudonyms in text
+var pseudonymPattern = regexp.MustCompile(`\bPerson [A-Z2-7]{6}\b`)
+
+// pseudonymEncoding encodes pseudonym codes (upper case letters and digits, no padding)
+var pseudonymEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Config holds anonymization settings
+type Config struct {
+	Key   string   // secret that encrypts the mappings and derives the pseudonyms
+	Path  string   // directory of the encrypted mappings
+	Names []string // names always replaced, e.g. single first names, which aren't detected
+}
+
+// Anonymizer pseudonymizes person names per context
+type Anonymizer struct {
+	encryptionKey *seal.Key
+	pseudonymKey  []byte
+	path          string
+	names         []string
+	logger        *zap.Logger
+
+	mu       sync.Mutex
+	mappings map[string]*mapping // context ID -> loaded mapping
+}
+
+// mapping holds the pseudonyms of a context
+type mapping struct {
+	ContextID  string            `json:"context_id"`
+	Pseudonyms map[string]string `json:"pseudonyms"` // real name -> pseudonym
+	realNames  map[string]string // pseudonym -> real name
+}
+
+// New creates an anonymizer storing its mappings under config.Path
+func New(config Config, logger *zap.Logger) (*Anonymizer, error) {
+	if config.Key == "" {
+		return nil, errors.New("anonymization key is required")
+	}
+	if err := os.MkdirAll(config.Path, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create pseudonym directory: %w", err)
+	}
+
+	encryptionKey, err := seal.NewKey(deriveKey(config.Key, "encryption"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encryption key: %w", err)
+	}
+
+	logger.Info("Initialized anonymizer", zap.String("path", config.Path))
+
+	return &Anonymizer{
+		encryptionKey: encryptionKey,
+		pseudonymKey:  deriveKey(config.Key, "pseudonyms"),
+		path:          config.Path,
+		names:         config.Names,
+		logger:        logger,
+		mappings:      make(map[string]*mapping),
+	}, nil
+}
+
+// deriveKey derives a 256-bit key for one purpose from the configured secret
+func deriveKey(secret, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// Pseudonymize replaces the person names in text with the context's pseudonyms. New names get
+// a pseudonym derived from the key, and the mapping is saved before the text is returned.
+func (a *Anonymizer) Pseudonymize(contextID, text string) (string, error) {
+	spans := findNames(text, a.names)
+	if len(spans) == 0 {
+		return text, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, err := a.load(contextID)
+	if err != nil {
+		return "", err
+	}
+
+	added := false
+	var builder strings.Builder
+	last := 0
+	for _, span := range spans {
+		name := text[span.start:span.end]
+		pseudonym, ok := m.Pseudonyms[name]
+		if !ok {
+			pseudonym = a.newPseudonym(m, name)
+			added = true
+		}
+		builder.WriteString(text[last:span.start])
+		builder.WriteString(pseudonym)
+		last = span.end
+	}
+	builder.WriteString(text[last:])
+
+	if added {
+		if err := a.save(m); err != nil {
+			return "", err
+		}
+	}
+	return builder.String(), nil
+}
+
+// newPseudonym assigns a pseudonym to a name of the mapping. It's derived from the name, so it's
+// stable even if the mapping is lost; collisions get the next derivation.
+func (a *Anonymizer) newPseudonym(m *mapping, name string) string {
+	for attempt := 0; ; attempt++ {
+		mac := hmac.New(sha256.New, a.pseudonymKey)
+		fmt.Fprintf(mac, "%s\x00%s\x00%d", m.ContextID, name, attempt)
+		pseudonym := pseudonymPrefix + pseudonymEncoding.EncodeToString(mac.Sum(nil))[:6]
+		if _, taken := m.realNames[pseudonym]; !taken {
+			m.Pseudonyms[name] = pseudonym
+			m.realNames[pseudonym] = name
+			return pseudonym
+		}
+	}
+}
+
+// Resolve replaces the context's pseudonyms in text with the real names. It also returns the
+// pseudonyms it resolved; unknown pseudonyms (e.g. of another context) are left as they are.
+func (a *Anonymizer) Resolve(contextID, text string) (string, map[string]string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, err := a.load(contextID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	resolved := make(map[string]string)
+	text = pseudonymPattern.ReplaceAllStringFunc(text, func(pseudonym string) string {
+		name, ok := m.realNames[pseudonym]
+		if !ok {
+			return pseudonym
+		}
+		resolved[pseudonym] = name
+		return name
+	})
+	return text, resolved, nil
+}
+
+// load returns the mapping of a context, reading it on first use. Called with a.mu held.
+func (a *Anonymizer) load(contextID string) (*mapping, error) {
+	if m, ok := a.mappings[contextID]; ok {
+		return m, nil
+	}
+
+	m := &mapping{ContextID: contextID, Pseudonyms: make(map[string]string)}
+	sealed, err := os.ReadFile(a.mappingPath(contextID))
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read pseudonyms of context %s: %w", contextID, err)
+	default:
+		data, err := a.encryptionKey.Open(sealed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt pseudonyms of context %s (wrong key?): %w", contextID, err)
+		}
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, fmt.Errorf("failed to decode pseudonyms of context %s: %w", contextID, err)
+		}
+	}
+
+	m.realNames = make(map[string]string, len(m.Pseudonyms))
+	for name, pseudonym := range m.Pseudonyms {
+		m.realNames[pseudonym] = name
+	}
+	a.mappings[contextID] = m
+	return m, nil
+}
+
+// save encrypts and writes a mapping, replacing the previous file atomically. Called with a.mu held.
+func (a *Anonymizer) save(m *mapping) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode pseudonyms: %w", err)
+	}
+	sealed, err := a.encryptionKey.Seal(data)
+	if err != nil {
+		return err
+	}
+
+	path := a.mappingPath(m.ContextID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, sealed, 0600); err != nil {
+		return fmt.Errorf("failed to write pseudonyms: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write pseudonyms: %w", err)
+	}
+	return nil
+}
+
+// mappingPath returns the file of a context's mapping. The name is hashed, so it reveals nothing
+// and is safe for any context ID.
+func (a *Anonymizer) mappingPath(contextID string) string {
+	sum := sha256.Sum256([]byte(contextID))
+	return filepath.Join(a.path, hex.EncodeToString(sum[:16])+".enc")
+}
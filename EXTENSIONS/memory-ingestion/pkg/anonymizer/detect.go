@@ -0,0 +1,135 @@
+package anonymizer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wordPattern matches words, including inner apostrophes and hyphens ("O'Brien", "Meyer-Lang")
+var wordPattern = regexp.MustCompile(`\p{L}+(?:['’-]\p{L}+)*`)
+
+// titles introduce a name, so a single capitalized word after them is a name ("Dr. Meyer")
+var titles = setOf("Mr", "Mrs", "Ms", "Miss", "Dr", "Prof", "Herr", "Frau", "Uncle", "Aunt")
+
+// stopWords are capitalized words that aren't part of names: sentence starters, days and months
+var stopWords = setOf(
+	"I", "A", "An", "The", "This", "That", "These", "Those", "My", "Our", "Your", "His", "Her",
+	"Their", "We", "You", "He", "She", "They", "It", "And", "But", "Or", "So", "Then", "When",
+	"After", "Before", "Today", "Yesterday", "Tomorrow", "Tonight", "Morning", "Afternoon",
+	"Evening", "Night", "Met", "Call", "Meeting", "Lunch", "Dinner", "With", "From", "To", "At", "In", "On",
+	"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday",
+	"January", "February", "March", "April", "May", "June", "July", "August", "September",
+	"October", "November", "December",
+)
+
+// span is the byte range of a name in a text
+type span struct {
+	start, end int
+}
+
+// findNames returns the names in text, ordered and without overlaps. Detected names are runs of
+// two or more capitalized words ("Anna Schmidt"), or capitalized words after a title; the
+// configured names are always found.
+func findNames(text string, names []string) []span {
+	spans := findConfigured(text, names)
+
+	words := wordPattern.FindAllStringIndex(text, -1)
+	for i := 0; i < len(words); {
+		j := i
+		for j < len(words) && isNameWord(text[words[j][0]:words[j][1]]) &&
+			(j == i || text[words[j-1][1]:words[j][0]] == " ") {
+			j++
+		}
+		if j == i {
+			i++
+			continue
+		}
+
+		titled := i > 0 && titles[text[words[i-1][0]:words[i-1][1]]] && followsTitle(text[words[i-1][1]:words[i][0]])
+		if j-i >= 2 || titled {
+			spans = append(spans, span{start: words[i][0], end: words[j-1][1]})
+		}
+		i = j
+	}
+
+	return normalize(spans)
+}
+
+// isNameWord reports whether a word can be part of a name: capitalized, not all upper case
+// (acronyms) and not a stop word or title
+func isNameWord(word string) bool {
+	first, size := utf8.DecodeRuneInString(word)
+	if !unicode.IsUpper(first) || stopWords[word] || titles[word] {
+		return false
+	}
+	return strings.IndexFunc(word[size:], unicode.IsLower) >= 0
+}
+
+// followsTitle reports whether the separator between a title and the next word keeps them together
+func followsTitle(separator string) bool {
+	return separator == " " || separator == ". "
+}
+
+// findConfigured returns the occurrences of the configured names as whole words
+func findConfigured(text string, names []string) []span {
+	var spans []span
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		for offset := 0; ; {
+			index := strings.Index(text[offset:], name)
+			if index < 0 {
+				break
+			}
+			start := offset + index
+			end := start + len(name)
+			if isBoundary(text, start, end) {
+				spans = append(spans, span{start: start, end: end})
+			}
+			offset = end
+		}
+	}
+	return spans
+}
+
+// isBoundary reports whether text[start:end] isn't part of a longer word
+func isBoundary(text string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	after, _ := utf8.DecodeRuneInString(text[end:])
+	return !isWordRune(before) && !isWordRune(after)
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// normalize sorts spans and drops those overlapping an earlier (or, at the same start, longer) span
+func normalize(spans []span) []span {
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	result := spans[:0]
+	for _, s := range spans {
+		if len(result) > 0 && s.start < result[len(result)-1].end {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func setOf(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
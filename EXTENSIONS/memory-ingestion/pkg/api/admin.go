@@ -0,0 +1,71 @@
+package api
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// publishVars registers the service's expvar variables once per process (expvar names are global)
+var publishVars sync.Once
+
+// adminHandler serves pprof under /debug/pprof/ and expvar under /debug/vars
+func (s *Server) adminHandler() http.Handler {
+	publishVars.Do(func() {
+		expvar.Publish("goroutines", expvar.Func(func() interface{} {
+			return runtime.NumGoroutine()
+		}))
+		expvar.Publish("running_syncs", expvar.Func(func() interface{} {
+			return s.scheduler.RunningConnectors()
+		}))
+		expvar.Publish("transformers", expvar.Func(func() interface{} {
+			if s.transformers == nil {
+				return nil
+			}
+			return s.transformers()
+		}))
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+// startAdminServer serves the diagnostics endpoints on their own listener, so they never share
+// the (possibly public) API port
+func (s *Server) startAdminServer(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s.adminServer = &http.Server{
+		Handler:           s.adminHandler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		// No write timeout: CPU profiles and traces stream for as long as the client asks
+		IdleTimeout: idleTimeout,
+	}
+
+	s.logger.Info("Admin diagnostics listening", zap.String("addr", listener.Addr().String()))
+
+	go func() {
+		if err := s.adminServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Admin diagnostics listener failed", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kamir/memory-connector/pkg/aliases"
+	"github.com/kamir/memory-connector/pkg/audit"
+	"go.uber.org/zap"
+)
+
+// aliasMaxBodyBytes limits the size of alias changes
+const aliasMaxBodyBytes = 4 << 10
+
+// AliasRegistry maintains the entity aliases of each context
+type AliasRegistry interface {
+	// Aliases returns the aliases of a context, alias -> canonical name
+	Aliases(contextID string) map[string]string
+	// Set registers alias as another name of canonical, failing with aliases.ErrInvalidAlias
+	Set(contextID, alias, canonical string) error
+	// Delete removes an alias, returning false if it isn't registered
+	Delete(contextID, alias string) (bool, error)
+	// Expand returns the canonical name of name if it's an alias in the contexts (all if none)
+	Expand(name string, contextIDs ...string) string
+}
+
+// ContextAliases lists the aliases of a context
+type ContextAliases struct {
+	ContextID string            `json:"context_id"`
+	Aliases   map[string]string `json:"aliases"` // alias -> canonical name
+}
+
+// AliasRequest registers an alias
+type AliasRequest struct {
+	Canonical string `json:"canonical"`
+}
+
+// handleAliases returns the aliases of a context (GET /api/v1/aliases/{context_id}), and registers
+// (PUT) or removes (DELETE) one of them (/api/v1/aliases/{context_id}/{alias})
+func (s *Server) handleAliases(w http.ResponseWriter, r *http.Request) {
+	if s.aliases == nil {
+		s.handleNotFound(w, r)
+		return
+	}
+
+	// The escaped path keeps slashes within the context ID or alias apart from the separators
+	escapedContextID, escapedAlias, hasAlias := strings.Cut(strings.TrimPrefix(r.URL.EscapedPath(), "/api/v1/aliases/"), "/")
+	contextID, err := url.PathUnescape(escapedContextID)
+	if err != nil || contextID == "" {
+		s.handleNotFound(w, r)
+		return
+	}
+	alias, err := url.PathUnescape(escapedAlias)
+	if err != nil || (hasAlias && alias == "") {
+		s.handleNotFound(w, r)
+		return
+	}
+	if err := authorizeContexts(r.Context(), contextID); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+
+	if !hasAlias {
+		if allowMethod(w, r, http.MethodGet) {
+			writeJSON(w, http.StatusOK, ContextAliases{ContextID: contextID, Aliases: s.aliases.Aliases(contextID)})
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req AliasRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, aliasMaxBodyBytes)).Decode(&req); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid alias request: %v", err))
+			return
+		}
+		before, registered := s.aliases.Aliases(contextID)[alias]
+		err := s.aliases.Set(contextID, alias, req.Canonical)
+		if !errors.Is(err, aliases.ErrInvalidAlias) {
+			entry := audit.Entry{
+				Action:  audit.ActionAliasSet,
+				Target:  contextID,
+				After:   map[string]string{alias: req.Canonical},
+				Details: map[string]interface{}{"alias": alias},
+			}
+			if registered {
+				entry.Before = map[string]string{alias: before}
+			}
+			s.recordAudit(r.Context(), entry, err)
+		}
+		switch {
+		case errors.Is(err, aliases.ErrInvalidAlias):
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		case err != nil:
+			s.writeInternalError(w, r, err)
+			return
+		}
+		s.logger.Info("Registered alias",
+			zap.String("context_id", contextID),
+			zap.String("alias", alias),
+			zap.String("canonical", req.Canonical),
+		)
+		writeJSON(w, http.StatusOK, ContextAliases{ContextID: contextID, Aliases: s.aliases.Aliases(contextID)})
+	case http.MethodDelete:
+		before := s.aliases.Aliases(contextID)[alias]
+		deleted, err := s.aliases.Delete(contextID, alias)
+		if deleted || err != nil {
+			s.recordAudit(r.Context(), audit.Entry{
+				Action:  audit.ActionAliasDelete,
+				Target:  contextID,
+				Before:  map[string]string{alias: before},
+				Details: map[string]interface{}{"alias": alias},
+			}, err)
+		}
+		if err != nil {
+			s.writeInternalError(w, r, err)
+			return
+		}
+		if !deleted {
+			writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound,
+				fmt.Sprintf("context %q has no alias %q", contextID, alias))
+			return
+		}
+		s.logger.Info("Removed alias", zap.String("context_id", contextID), zap.String("alias", alias))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "PUT, DELETE")
+		writeProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed,
+			fmt.Sprintf("method %s is not allowed, use PUT or DELETE", r.Method))
+	}
+}
+
+// expandAlias returns the canonical name of an entity label if it's an alias in the contexts (all
+// if none), the label itself otherwise or without an alias registry
+func (s *Server) expandAlias(label string, contextIDs ...string) string {
+	if s.aliases == nil {
+		return label
+	}
+	return s.aliases.Expand(label, contextIDs...)
+}
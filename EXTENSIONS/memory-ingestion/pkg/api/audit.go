@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kamir/memory-connector/pkg/audit"
+	"go.uber.org/zap"
+)
+
+// Audit query limits
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// anonymousPrincipal records mutations while authorization is disabled
+const anonymousPrincipal = "anonymous"
+
+// AuditLog records the mutations made through the API and serves them
+type AuditLog interface {
+	audit.Recorder
+	// Query returns a page of the recorded entries, newest first
+	Query(query audit.Query) (*audit.Page, error)
+}
+
+// recordAudit records a mutation made by the client of a request, with its principal, role, and
+// correlation ID. The outcome is success unless err is set.
+func (s *Server) recordAudit(ctx context.Context, entry audit.Entry, err error) {
+	if s.auditLog == nil {
+		return
+	}
+
+	entry.Principal = anonymousPrincipal
+	if principal, ok := PrincipalFrom(ctx); ok {
+		entry.Principal = principal.Name
+		if entry.Details == nil {
+			entry.Details = make(map[string]interface{})
+		}
+		entry.Details["role"] = principal.Role
+	}
+	if id := CorrelationID(ctx); id != "" {
+		if entry.Details == nil {
+			entry.Details = make(map[string]interface{})
+		}
+		entry.Details["correlation_id"] = id
+	}
+	entry.Outcome = audit.OutcomeSuccess
+	if err != nil {
+		entry.Outcome = audit.OutcomeFailed
+		entry.Error = err.Error()
+	}
+
+	if err := s.auditLog.Record(entry); err != nil {
+		s.logger.Warn("Failed to record audit entry",
+			zap.String("action", entry.Action),
+			zap.String("correlation_id", CorrelationID(ctx)),
+			zap.Error(err),
+		)
+	}
+}
+
+// handleAudit returns the audit log, newest first (GET /api/v1/audit). Query parameters: action,
+// principal, target, since and until (RFC 3339 or YYYY-MM-DD), limit, offset.
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	if s.auditLog == nil {
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, "the audit log is not enabled")
+		return
+	}
+
+	values := r.URL.Query()
+	query := audit.Query{
+		Action:    values.Get("action"),
+		Principal: values.Get("principal"),
+		Target:    values.Get("target"),
+		Limit:     defaultAuditLimit,
+	}
+	var err error
+	if query.Since, err = parseReportTime(values.Get("since")); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid since: %v", err))
+		return
+	}
+	if query.Until, err = parseReportTime(values.Get("until")); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid until: %v", err))
+		return
+	}
+	if limit := values.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 1 || query.Limit > maxAuditLimit {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("limit must be between 1 and %d", maxAuditLimit))
+			return
+		}
+	}
+	if offset := values.Get("offset"); offset != "" {
+		if query.Offset, err = strconv.Atoi(offset); err != nil || query.Offset < 0 {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "offset must be a non-negative integer")
+			return
+		}
+	}
+
+	page, err := s.auditLog.Query(query)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,424 @@
+package api
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/config"
+	"go.uber.org/zap"
+)
+
+// APIKeyHeader carries an API key, as an alternative to a bearer token
+const APIKeyHeader = "X-API-Key"
+
+// jwtLeeway tolerates clock skew between the server and the issuer of JWTs
+const jwtLeeway = time.Minute
+
+const principalKey contextKey = "principal"
+
+// roleRanks orders the roles: each is allowed what lower ones are
+var roleRanks = map[string]int{
+	config.RoleReader:   1,
+	config.RoleOperator: 2,
+	config.RoleAdmin:    3,
+}
+
+// adminRoutes are the routes that change or export the service's data as a whole, admin only
+// whatever the method
+var adminRoutes = []string{
+	"/api/v1/admin/",
+	"/api/v1/audit",
+	"/api/v1/purges",
+	"/api/v1/snapshots",
+	"/api/v1/restores",
+	"/api/v1/imports",
+}
+
+// readOnlyPostRoutes are the routes that take POST requests but only read
+var readOnlyPostRoutes = map[string]bool{
+	"/api/v1/lookup/memories": true,
+	"/api/v1/query":           true,
+	"/graphql":                true,
+}
+
+// errNoCredentials is returned for requests without an API key or JWT
+var errNoCredentials = errors.New("credentials are required: send an API key or a JWT as bearer token")
+
+// allContexts in a principal's contexts grants it all contexts
+const allContexts = "*"
+
+// errContextForbidden is returned for lookups in contexts outside the client's scope
+var errContextForbidden = errors.New("credentials are not bound to context")
+
+// Principal is the authenticated client of a request
+type Principal struct {
+	Name     string   `json:"name"` // the API key's name or the JWT's subject
+	Role     string   `json:"role"`
+	Contexts []string `json:"contexts,omitempty"` // the contexts whose memories it may look up, nil for all
+}
+
+// PrincipalFrom returns the authenticated client of a request context, false if authorization is disabled
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	return principal, ok
+}
+
+// principalName returns the name of the authenticated client of a request context, empty if
+// authorization is disabled
+func principalName(ctx context.Context) string {
+	principal, _ := PrincipalFrom(ctx)
+	return principal.Name
+}
+
+// apiKey is a configured API key, kept as its hash so keys are compared in constant time
+type apiKey struct {
+	hash     [sha256.Size]byte
+	name     string
+	role     string
+	contexts []string
+}
+
+// authenticator maps the credentials of requests to principals
+type authenticator struct {
+	apiKeys   []apiKey
+	jwt       config.JWTConfig
+	secret    []byte         // verifies HS256 tokens
+	publicKey *rsa.PublicKey // verifies RS256 tokens
+}
+
+// newAuthenticator creates the authenticator of the auth settings, reading the JWT public key
+func newAuthenticator(authConfig config.AuthConfig) (*authenticator, error) {
+	a := &authenticator{jwt: authConfig.JWT}
+	for i, key := range authConfig.APIKeys {
+		name := key.Name
+		if name == "" {
+			name = fmt.Sprintf("api_keys[%d]", i)
+		}
+		a.apiKeys = append(a.apiKeys, apiKey{
+			hash:     sha256.Sum256([]byte(key.Key)),
+			name:     name,
+			role:     key.Role,
+			contexts: contextGrant(key.Contexts),
+		})
+	}
+
+	if authConfig.JWT.Secret != "" {
+		a.secret = []byte(authConfig.JWT.Secret)
+	}
+	if authConfig.JWT.PublicKeyFile != "" {
+		publicKey, err := readRSAPublicKey(authConfig.JWT.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+		a.publicKey = publicKey
+	}
+	return a, nil
+}
+
+// readRSAPublicKey reads a PEM-encoded RSA public key (PKIX or PKCS #1)
+func readRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s holds no PEM block", path)
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s holds no RSA public key", path)
+	}
+	return publicKey, nil
+}
+
+// authenticate returns the principal of an API key or JWT. Credentials that are no API key are
+// verified as JWT.
+func (a *authenticator) authenticate(credential string) (Principal, error) {
+	if credential == "" {
+		return Principal{}, errNoCredentials
+	}
+
+	hash := sha256.Sum256([]byte(credential))
+	var match *apiKey
+	for i := range a.apiKeys {
+		// All keys are compared, so the time taken doesn't tell which one matched
+		if subtle.ConstantTimeCompare(hash[:], a.apiKeys[i].hash[:]) == 1 {
+			match = &a.apiKeys[i]
+		}
+	}
+	if match != nil {
+		return Principal{Name: match.name, Role: match.role, Contexts: match.contexts}, nil
+	}
+
+	if !a.jwt.Enabled() || strings.Count(credential, ".") != 2 {
+		return Principal{}, errors.New("invalid API key")
+	}
+	return a.verifyJWT(credential)
+}
+
+// verifyJWT checks the signature and claims of a JWT and returns its principal: its subject with
+// the highest role of its role claim
+func (a *authenticator) verifyJWT(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return Principal{}, errors.New("invalid JWT header")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return Principal{}, errors.New("invalid JWT header")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, errors.New("invalid JWT signature")
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch {
+	case header.Alg == "HS256" && a.secret != nil:
+		mac := hmac.New(sha256.New, a.secret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return Principal{}, errors.New("invalid JWT signature")
+		}
+	case header.Alg == "RS256" && a.publicKey != nil:
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(a.publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return Principal{}, errors.New("invalid JWT signature")
+		}
+	default:
+		return Principal{}, fmt.Errorf("JWT signing algorithm %q is not accepted", header.Alg)
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Principal{}, errors.New("invalid JWT claims")
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return Principal{}, errors.New("invalid JWT claims")
+	}
+	if err := a.checkClaims(claims, time.Now()); err != nil {
+		return Principal{}, err
+	}
+
+	principal := Principal{Name: "jwt"}
+	if subject, ok := claims["sub"].(string); ok && subject != "" {
+		principal.Name = subject
+	}
+	for _, role := range claimStrings(claims, a.jwt.RoleClaim) {
+		if roleRanks[role] > roleRanks[principal.Role] {
+			principal.Role = role
+		}
+	}
+	if principal.Role == "" {
+		return Principal{}, fmt.Errorf("JWT claim %s grants no role", a.jwt.RoleClaim)
+	}
+	if a.jwt.ContextClaim != "" {
+		contexts := claimStrings(claims, a.jwt.ContextClaim)
+		if len(contexts) == 0 {
+			return Principal{}, fmt.Errorf("JWT claim %s grants no context", a.jwt.ContextClaim)
+		}
+		principal.Contexts = contextGrant(contexts)
+	}
+	return principal, nil
+}
+
+// checkClaims checks the expiry, start, issuer, and audience of a JWT. Tokens must expire.
+func (a *authenticator) checkClaims(claims map[string]interface{}, now time.Time) error {
+	expiry, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("JWT has no expiry")
+	}
+	if now.Add(-jwtLeeway).After(time.Unix(int64(expiry), 0)) {
+		return errors.New("JWT expired")
+	}
+	if notBefore, ok := claims["nbf"].(float64); ok && now.Add(jwtLeeway).Before(time.Unix(int64(notBefore), 0)) {
+		return errors.New("JWT is not valid yet")
+	}
+	if a.jwt.Issuer != "" && claims["iss"] != a.jwt.Issuer {
+		return errors.New("JWT issuer is not accepted")
+	}
+	if a.jwt.Audience != "" {
+		audience := claimStrings(claims, "aud")
+		accepted := false
+		for _, aud := range audience {
+			accepted = accepted || aud == a.jwt.Audience
+		}
+		if !accepted {
+			return errors.New("JWT audience is not accepted")
+		}
+	}
+	return nil
+}
+
+// claimStrings returns the string or strings of a claim, dotted for nested claims
+func claimStrings(claims map[string]interface{}, name string) []string {
+	var value interface{} = claims
+	for _, key := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// requiredRole returns the role a request needs, empty for the public health check. Reads need
+// the reader role, and so do POST routes that only read and chats; syncs need the operator role;
+// the admin routes and all other writes need the admin role.
+func requiredRole(method, path string) string {
+	if path == "/api/v1/health" {
+		return ""
+	}
+	for _, route := range adminRoutes {
+		if strings.HasPrefix(path, route) {
+			return config.RoleAdmin
+		}
+	}
+
+	switch {
+	case method == http.MethodGet || method == http.MethodHead:
+		return config.RoleReader
+	case readOnlyPostRoutes[path] || strings.HasPrefix(path, "/ollama/"):
+		return config.RoleReader
+	case method == http.MethodPost && strings.HasPrefix(path, "/api/v1/connectors/"):
+		// trigger, pause, resume, and reingest
+		return config.RoleOperator
+	default:
+		return config.RoleAdmin
+	}
+}
+
+// contextGrant returns the contexts a principal is granted, nil for all if none are listed or
+// allContexts is among them
+func contextGrant(contexts []string) []string {
+	for _, contextID := range contexts {
+		if contextID == allContexts {
+			return nil
+		}
+	}
+	if len(contexts) == 0 {
+		return nil
+	}
+	return contexts
+}
+
+// contextScope returns the contexts the client of a request may look up memories of, nil if it
+// may look up all
+func contextScope(ctx context.Context) map[string]bool {
+	principal, ok := PrincipalFrom(ctx)
+	if !ok || principal.Contexts == nil {
+		return nil
+	}
+	scope := make(map[string]bool, len(principal.Contexts))
+	for _, contextID := range principal.Contexts {
+		scope[contextID] = true
+	}
+	return scope
+}
+
+// authorizeContexts returns an error naming the first of contextIDs the client of a request may
+// not look up memories of
+func authorizeContexts(ctx context.Context, contextIDs ...string) error {
+	scope := contextScope(ctx)
+	if scope == nil {
+		return nil
+	}
+	for _, contextID := range contextIDs {
+		if !scope[contextID] {
+			return fmt.Errorf("%w: %s", errContextForbidden, contextID)
+		}
+	}
+	return nil
+}
+
+// allowed returns true if a principal has the role or a higher one
+func (p Principal) allowed(role string) bool {
+	return roleRanks[p.Role] >= roleRanks[role]
+}
+
+// requestCredential returns the bearer token or API key of a request
+func requestCredential(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return r.Header.Get(APIKeyHeader)
+}
+
+// withAuth authenticates requests and rejects those whose principal lacks the role of the route,
+// if authorization is enabled
+func (s *Server) withAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := requiredRole(r.Method, r.URL.Path)
+		if s.auth == nil || role == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := s.auth.authenticate(requestCredential(r))
+		if err != nil {
+			s.logger.Warn("Rejected unauthenticated request",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("correlation_id", CorrelationID(r.Context())),
+				zap.Error(err),
+			)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="memory-connector"`)
+			writeProblem(w, r, http.StatusUnauthorized, CodeUnauthorized, err.Error())
+			return
+		}
+		if !principal.allowed(role) {
+			s.logger.Warn("Rejected unauthorized request",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("principal", principal.Name),
+				zap.String("role", principal.Role),
+				zap.String("correlation_id", CorrelationID(r.Context())),
+			)
+			writeProblem(w, r, http.StatusForbidden, CodeForbidden,
+				fmt.Sprintf("role %s may not %s %s, it requires role %s", principal.Role, r.Method, r.URL.Path, role))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey, principal)))
+	})
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// compressibleTypes are the media types worth compressing
+var compressibleTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/x-ndjson",
+	"text/",
+}
+
+// gzipWriters reuses gzip writers across responses
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// withCompression gzips compressible responses for clients that accept gzip
+func withCompression(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipWriter{ResponseWriter: w}
+		defer gw.close()
+
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip returns true if the Accept-Encoding header allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// gzipWriter compresses the response body once the headers show it is worth it
+type gzipWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+// WriteHeader decides whether to compress based on the status and content headers
+func (g *gzipWriter) WriteHeader(status int) {
+	if g.wroteHeader {
+		return
+	}
+	g.wroteHeader = true
+
+	header := g.Header()
+	if status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified &&
+		header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		// The compressed representation is only semantically equivalent to the identity one
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		g.gz = gzipWriters.Get().(*gzip.Writer)
+		g.gz.Reset(g.ResponseWriter)
+	}
+
+	g.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes the (compressed) body
+func (g *gzipWriter) Write(p []byte) (int, error) {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.gz != nil {
+		return g.gz.Write(p)
+	}
+	return g.ResponseWriter.Write(p)
+}
+
+// Flush sends what has been compressed so far, so streamed responses reach the client as they are written
+func (g *gzipWriter) Flush() {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.gz != nil {
+		g.gz.Flush()
+	}
+	http.NewResponseController(g.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the connection
+func (g *gzipWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+// close flushes the compressed body
+func (g *gzipWriter) close() {
+	if g.gz == nil {
+		return
+	}
+	g.gz.Close()
+	gzipWriters.Put(g.gz)
+	g.gz = nil
+}
+
+// compressible returns true if the content type is worth compressing
+func compressible(contentType string) bool {
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// withETag adds an ETag to successful GET responses and answers If-None-Match with
+// 304 Not Modified, so clients polling for unchanged results skip the body
+func withETag(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next(w, r)
+			return
+		}
+
+		buffer := &etagWriter{header: make(http.Header)}
+		next(buffer, r)
+
+		// Append, so headers set by outer middleware (e.g. Vary) are kept
+		for key, values := range buffer.header {
+			w.Header()[key] = append(w.Header()[key], values...)
+		}
+		if buffer.status == 0 {
+			buffer.status = http.StatusOK
+		}
+		if buffer.status != http.StatusOK {
+			w.WriteHeader(buffer.status)
+			w.Write(buffer.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(buffer.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "no-cache")
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(buffer.body.Bytes())
+	}
+}
+
+// etagMatches implements the weak comparison of If-None-Match (RFC 9110, section 13.1.2)
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter buffers a response to compute its ETag
+type etagWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+// Header returns the buffered response headers
+func (e *etagWriter) Header() http.Header {
+	return e.header
+}
+
+// Write buffers the response body
+func (e *etagWriter) Write(p []byte) (int, error) {
+	if e.status == 0 {
+		e.status = http.StatusOK
+	}
+	return e.body.Write(p)
+}
+
+// WriteHeader records the response status
+func (e *etagWriter) WriteHeader(status int) {
+	if e.status == 0 {
+		e.status = status
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// DeadLetterQueue lists the failed memories of a connector awaiting a retry, and those the queue gave up on
+type DeadLetterQueue struct {
+	ConnectorID string              `json:"connector_id"`
+	AutoRetry   bool                `json:"auto_retry"` // due memories are retried independent of the schedule
+	Pending     int                 `json:"pending"`
+	GaveUp      int                 `json:"gave_up"`
+	NextRetryAt *time.Time          `json:"next_retry_at,omitempty"` // the earliest next attempt of a pending memory
+	Items       []models.FailedItem `json:"items"`                   // pending memories by next attempt, then those given up on
+}
+
+// handleDeadLetterQueue returns the Dead Letter Queue of a connector, with each memory's next attempt
+func (s *Server) handleDeadLetterQueue(w http.ResponseWriter, r *http.Request, connector *models.ConnectorConfig) {
+	syncState, err := s.stateManager.GetState(r.Context(), connector.ID)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	queue := DeadLetterQueue{
+		ConnectorID: connector.ID,
+		AutoRetry:   s.configs().DeadLetters.Retry && connector.Transform.Mode != models.TransformModeDailyDigest,
+		Items:       append([]models.FailedItem{}, syncState.FailedItems...),
+	}
+	sort.SliceStable(queue.Items, func(i, j int) bool {
+		a, b := queue.Items[i], queue.Items[j]
+		if a.Pending() != b.Pending() {
+			return a.Pending()
+		}
+		if a.NextRetryAt == nil || b.NextRetryAt == nil {
+			// Memories queued before retries were scheduled are due right away
+			return a.NextRetryAt == nil && b.NextRetryAt != nil
+		}
+		return a.NextRetryAt.Before(*b.NextRetryAt)
+	})
+
+	for _, item := range queue.Items {
+		if !item.Pending() {
+			queue.GaveUp++
+			continue
+		}
+		queue.Pending++
+		if item.NextRetryAt != nil && (queue.NextRetryAt == nil || item.NextRetryAt.Before(*queue.NextRetryAt)) {
+			queue.NextRetryAt = item.NextRetryAt
+		}
+	}
+
+	writeJSON(w, http.StatusOK, queue)
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// ProblemContentType is the media type of error responses (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBase prefixes the error code to form the problem type URI
+const problemTypeBase = "urn:memory-connector:problem:"
+
+// Machine-readable error codes
+const (
+	CodeInvalidRequest      = "invalid_request"
+	CodeInvalidURI          = "invalid_uri"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeEntityNotFound      = "entity_not_found"
+	CodeMethodNotAllowed    = "method_not_allowed"
+	CodeConflict            = "conflict"
+	CodeUpstreamUnavailable = "upstream_unavailable"
+	CodeTimeout             = "timeout"
+	CodeQuotaExhausted      = "quota_exhausted"
+	CodeConnectorPaused     = "connector_paused"
+	CodeInternal            = "internal_error"
+)
+
+// Problem is an RFC 7807 problem details payload
+type Problem struct {
+	Type          string `json:"type"`
+	Title         string `json:"title"`
+	Status        int    `json:"status"`
+	Detail        string `json:"detail,omitempty"`
+	Instance      string `json:"instance,omitempty"`
+	Code          string `json:"code"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// writeProblem writes an RFC 7807 error response. All handlers and middleware report errors through it.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	problem := Problem{
+		Type:          problemTypeBase + code,
+		Title:         http.StatusText(status),
+		Status:        status,
+		Detail:        detail,
+		Instance:      r.URL.Path,
+		Code:          code,
+		CorrelationID: CorrelationID(r.Context()),
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeInternalError logs err with the correlation ID and writes a generic 500 problem
+func (s *Server) writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	s.logger.Error("Request failed",
+		zap.String("path", r.URL.Path),
+		zap.String("correlation_id", CorrelationID(r.Context())),
+		zap.Error(err),
+	)
+	writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "internal server error")
+}
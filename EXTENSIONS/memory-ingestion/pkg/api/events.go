@@ -0,0 +1,271 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kamir/memory-connector/pkg/events"
+	"go.uber.org/zap"
+)
+
+// WebSocket event feed settings
+const (
+	eventBuffer      = 256
+	eventWriteWait   = 10 * time.Second
+	eventPongWait    = 60 * time.Second
+	eventPingPeriod  = eventPongWait * 9 / 10
+	eventMaxReadSize = 4096
+)
+
+// typeEventsDropped tells a subscriber that events were lost because it didn't keep up
+const typeEventsDropped = "events.dropped"
+
+// upgrader upgrades event feed requests. The default origin check only admits same-origin browsers.
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// handleEvents streams events as JSON messages over a WebSocket. The connector_id and type query
+// parameters (repeatable or comma-separated) filter the feed; clients can replace the filter at
+// any time by sending {"connector_ids": [...], "types": [...]}. Clients bound to contexts only
+// receive the events of their connectors.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	if s.events == nil {
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, "the event feed is not enabled")
+		return
+	}
+	if !websocket.IsWebSocketUpgrade(r) {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "this endpoint requires a WebSocket upgrade")
+		return
+	}
+
+	filter := events.Filter{
+		ConnectorIDs: queryList(r, "connector_id"),
+		Types:        queryList(r, "type"),
+	}
+	if err := authorizeContexts(r.Context(), s.connectorContexts(filter.ConnectorIDs)...); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already written an error response
+		return
+	}
+	defer conn.Close()
+
+	subscription := s.events.Subscribe(s.scopeEventFilter(r.Context(), filter), eventBuffer)
+	defer subscription.Close()
+
+	correlationID := CorrelationID(r.Context())
+	s.logger.Info("Event subscriber connected",
+		zap.Strings("connector_ids", filter.ConnectorIDs),
+		zap.Strings("types", filter.Types),
+		zap.String("correlation_id", correlationID),
+	)
+
+	closed := make(chan struct{})
+	go s.readEventFilters(r.Context(), conn, subscription, closed)
+
+	ticker := time.NewTicker(eventPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-subscription.C:
+			conn.SetWriteDeadline(time.Now().Add(eventWriteWait))
+			if !ok {
+				// The bus was closed, the service is shutting down
+				conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+				return
+			}
+
+			if !s.eventVisible(r.Context(), event) {
+				// The filter of a client whose contexts no connector ingests is empty, matching all events
+				continue
+			}
+			if dropped := subscription.Dropped(); dropped > 0 {
+				notice := events.Event{Type: typeEventsDropped, Timestamp: time.Now().UTC(), Data: map[string]uint64{"count": dropped}}
+				if err := conn.WriteJSON(notice); err != nil {
+					return
+				}
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(eventWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+
+		case <-closed:
+			s.logger.Info("Event subscriber disconnected", zap.String("correlation_id", correlationID))
+			return
+		}
+	}
+}
+
+// readEventFilters applies filter updates sent by the client, narrowed to its contexts, and handles
+// pongs. It closes closed when the connection fails or the client closes it.
+func (s *Server) readEventFilters(ctx context.Context, conn *websocket.Conn, subscription *events.Subscription, closed chan<- struct{}) {
+	defer close(closed)
+
+	conn.SetReadLimit(eventMaxReadSize)
+	conn.SetReadDeadline(time.Now().Add(eventPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(eventPongWait))
+	})
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var filter events.Filter
+		if err := json.Unmarshal(message, &filter); err != nil {
+			// Malformed filter messages are ignored, the current filter stays
+			continue
+		}
+		subscription.SetFilter(s.scopeEventFilter(ctx, filter))
+	}
+}
+
+// scopeEventFilter narrows the connectors of an event filter to those of the client's contexts. An
+// empty filter of a client bound to contexts becomes the list of its connectors, which is empty
+// too if none ingests them; eventVisible drops the events such a filter lets through.
+func (s *Server) scopeEventFilter(ctx context.Context, filter events.Filter) events.Filter {
+	if contextScope(ctx) == nil {
+		return filter
+	}
+
+	requested := make(map[string]bool, len(filter.ConnectorIDs))
+	for _, id := range filter.ConnectorIDs {
+		requested[id] = true
+	}
+	var connectorIDs []string
+	for _, connector := range s.scopedConnectors(ctx) {
+		if len(requested) == 0 || requested[connector.ID] {
+			connectorIDs = append(connectorIDs, connector.ID)
+		}
+	}
+	filter.ConnectorIDs = connectorIDs
+	return filter
+}
+
+// eventVisible returns true if the client of a request may see an event: one of a connector of its
+// contexts, or any event if it isn't bound to contexts
+func (s *Server) eventVisible(ctx context.Context, event events.Event) bool {
+	scope := contextScope(ctx)
+	if scope == nil {
+		return true
+	}
+	connector, err := s.configs().GetConnectorByID(event.ConnectorID)
+	return err == nil && scope[connector.ContextID]
+}
+
+// queryList returns the values of a repeatable, comma-separated query parameter
+func queryList(r *http.Request, key string) []string {
+	var values []string
+	for _, value := range r.URL.Query()[key] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+// Event log paging
+const (
+	defaultEventLimit = 100
+	maxEventLimit     = 1000
+)
+
+// handleEventQuery returns a page of the recorded events, newest first. Query parameters: since and
+// until (RFC 3339 or YYYY-MM-DD), type and connector_id (repeatable or comma-separated), limit, offset.
+// Clients bound to contexts only get the events of their connectors.
+func (s *Server) handleEventQuery(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	if s.eventLog == nil {
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, "the event log is not enabled")
+		return
+	}
+
+	query, err := parseEventQuery(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+	if err := authorizeContexts(r.Context(), s.connectorContexts(query.ConnectorIDs)...); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+	query.Filter = s.scopeEventFilter(r.Context(), query.Filter)
+	if contextScope(r.Context()) != nil && len(query.ConnectorIDs) == 0 {
+		// No connector ingests the client's contexts
+		writeJSON(w, http.StatusOK, events.Page{Events: []events.Event{}, Limit: query.Limit, Offset: query.Offset})
+		return
+	}
+
+	page, err := s.eventLog.Query(query)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// parseEventQuery reads the filters and paging of an event log request
+func parseEventQuery(r *http.Request) (events.Query, error) {
+	values := r.URL.Query()
+	query := events.Query{
+		Filter: events.Filter{
+			ConnectorIDs: queryList(r, "connector_id"),
+			Types:        queryList(r, "type"),
+		},
+		Limit: defaultEventLimit,
+	}
+
+	var err error
+	if query.Since, err = parseReportTime(values.Get("since")); err != nil {
+		return query, fmt.Errorf("invalid since: %w", err)
+	}
+	if query.Until, err = parseReportTime(values.Get("until")); err != nil {
+		return query, fmt.Errorf("invalid until: %w", err)
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && !query.Since.Before(query.Until) {
+		return query, fmt.Errorf("since must be before until")
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 1 || query.Limit > maxEventLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", maxEventLimit)
+		}
+	}
+	if offset := values.Get("offset"); offset != "" {
+		if query.Offset, err = strconv.Atoi(offset); err != nil || query.Offset < 0 {
+			return query, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+
+	return query, nil
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// Report export formats, negotiated via the Accept header
+const (
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+
+	csvContentType    = "text/csv"
+	ndjsonContentType = "application/x-ndjson"
+)
+
+// Export record types: one sync row per report, followed by one memory row per memory it touched
+const (
+	recordSync   = "sync"
+	recordMemory = "memory"
+)
+
+// Memory outcomes of export memory rows
+const (
+	outcomeIngested = "ingested"
+	outcomeSkipped  = "skipped"
+	outcomeFailed   = "failed"
+)
+
+// exportColumns is the CSV header, in the order of exportRow.csv
+var exportColumns = []string{
+	"record", "connector_id", "context_id", "start_time", "end_time", "duration_ms", "status",
+	"total_fetched", "total_processed", "total_skipped", "total_failed", "total_deferred",
+	"memory_id", "outcome", "error_message", "category", "failed_at", "retryable", "retry_count",
+}
+
+// exportRow is a flat row of a report export. Sync rows carry the report totals, memory rows
+// the outcome of a single memory; both carry the report's identity so rows can be joined.
+type exportRow struct {
+	Record         string     `json:"record"`
+	ConnectorID    string     `json:"connector_id"`
+	ContextID      string     `json:"context_id"`
+	StartTime      time.Time  `json:"start_time"`
+	EndTime        *time.Time `json:"end_time,omitempty"`
+	DurationMs     *int64     `json:"duration_ms,omitempty"`
+	Status         string     `json:"status"`
+	TotalFetched   *int       `json:"total_fetched,omitempty"`
+	TotalProcessed *int       `json:"total_processed,omitempty"`
+	TotalSkipped   *int       `json:"total_skipped,omitempty"`
+	TotalFailed    *int       `json:"total_failed,omitempty"`
+	TotalDeferred  *int       `json:"total_deferred,omitempty"`
+	MemoryID       string     `json:"memory_id,omitempty"`
+	Outcome        string     `json:"outcome,omitempty"`
+	ErrorMessage   string     `json:"error_message,omitempty"`
+	Category       string     `json:"category,omitempty"`
+	FailedAt       *time.Time `json:"failed_at,omitempty"`
+	Retryable      *bool      `json:"retryable,omitempty"`
+	RetryCount     *int       `json:"retry_count,omitempty"`
+}
+
+// exportRows flattens reports into a sync row and the memory rows of each report
+func exportRows(reports []models.SyncReport) []exportRow {
+	var rows []exportRow
+	for i := range reports {
+		report := &reports[i]
+		sync := exportRow{
+			Record:      recordSync,
+			ConnectorID: report.ConnectorID,
+			ContextID:   report.ContextID,
+			StartTime:   report.StartTime,
+			Status:      report.Status,
+		}
+		memory := sync
+		memory.Record = recordMemory
+
+		endTime := report.EndTime
+		durationMs := report.Duration.Milliseconds()
+		sync.EndTime = &endTime
+		sync.DurationMs = &durationMs
+		sync.TotalFetched = &report.TotalFetched
+		sync.TotalProcessed = &report.TotalProcessed
+		sync.TotalSkipped = &report.TotalSkipped
+		sync.TotalFailed = &report.TotalFailed
+		sync.TotalDeferred = &report.TotalDeferred
+		sync.ErrorMessage = report.ErrorMessage
+		rows = append(rows, sync)
+
+		for _, memoryID := range report.MemoriesIngested {
+			row := memory
+			row.MemoryID, row.Outcome = memoryID, outcomeIngested
+			rows = append(rows, row)
+		}
+		for _, memoryID := range report.MemoriesSkipped {
+			row := memory
+			row.MemoryID, row.Outcome = memoryID, outcomeSkipped
+			rows = append(rows, row)
+		}
+		for j := range report.MemoriesFailed {
+			item := &report.MemoriesFailed[j]
+			row := memory
+			row.MemoryID, row.Outcome, row.ErrorMessage = item.MemoryID, outcomeFailed, item.ErrorMessage
+			row.Category = item.Category
+			row.FailedAt, row.Retryable, row.RetryCount = &item.FailedAt, &item.Retryable, &item.RetryCount
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// csv returns the row's fields in the order of exportColumns; unset fields are empty
+func (row *exportRow) csv() []string {
+	return []string{
+		row.Record, row.ConnectorID, row.ContextID, formatTime(&row.StartTime), formatTime(row.EndTime),
+		formatInt64(row.DurationMs), row.Status,
+		formatInt(row.TotalFetched), formatInt(row.TotalProcessed), formatInt(row.TotalSkipped),
+		formatInt(row.TotalFailed), formatInt(row.TotalDeferred),
+		row.MemoryID, row.Outcome, row.ErrorMessage, row.Category, formatTime(row.FailedAt), formatBool(row.Retryable),
+		formatInt(row.RetryCount),
+	}
+}
+
+// negotiateReportFormat picks the report format from the Accept header: the first listed of
+// text/csv, application/x-ndjson, and application/json. Anything else gets JSON.
+func negotiateReportFormat(r *http.Request) string {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		switch mediaType {
+		case csvContentType:
+			return formatCSV
+		case ndjsonContentType:
+			return formatNDJSON
+		case "application/json":
+			return formatJSON
+		}
+	}
+	return formatJSON
+}
+
+// writeReportExport writes reports as CSV or JSON Lines export rows
+func writeReportExport(w http.ResponseWriter, format, filename string, reports []models.SyncReport) error {
+	rows := exportRows(reports)
+
+	switch format {
+	case formatCSV:
+		w.Header().Set("Content-Type", csvContentType+"; charset=utf-8; header=present")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".csv"))
+		w.WriteHeader(http.StatusOK)
+
+		writer := csv.NewWriter(w)
+		writer.Write(exportColumns)
+		for i := range rows {
+			writer.Write(rows[i].csv())
+		}
+		writer.Flush()
+		return writer.Error()
+
+	default:
+		w.Header().Set("Content-Type", ndjsonContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".jsonl"))
+		w.WriteHeader(http.StatusOK)
+
+		encoder := json.NewEncoder(w)
+		for i := range rows {
+			if err := encoder.Encode(&rows[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// formatTime formats an optional time as RFC 3339 (UTC)
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// formatInt formats an optional int
+func formatInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+// formatInt64 formats an optional int64
+func formatInt64(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+// formatBool formats an optional bool
+func formatBool(v *bool) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatBool(*v)
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kamir/memory-connector/pkg/audit"
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// featureMaxBodyBytes limits the size of feature flag changes
+const featureMaxBodyBytes = 1 << 10
+
+// FeatureOverrides maintains the runtime overrides of connectors' feature flags
+type FeatureOverrides interface {
+	// For returns the overrides of a connector, flag -> enabled
+	For(connectorID string) map[string]bool
+	// Set overrides a flag of a connector, or removes its override if enabled is nil
+	Set(connectorID, flag string, enabled *bool) error
+}
+
+// ConnectorFeatures lists the feature flags of a connector
+type ConnectorFeatures struct {
+	ConnectorID string         `json:"connector_id"`
+	Flags       []FeatureState `json:"flags"`
+}
+
+// FeatureState is a feature flag of a connector and what sets it
+type FeatureState struct {
+	models.FeatureFlag
+	Enabled    bool  `json:"enabled"`              // for the connector's next sync
+	Configured *bool `json:"configured,omitempty"` // by the connector's features setting
+	Override   *bool `json:"override,omitempty"`   // through the API, takes precedence
+}
+
+// FeatureRequest overrides a feature flag
+type FeatureRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// connectorFeatures returns the state of a connector's feature flags
+func (s *Server) connectorFeatures(connector *models.ConnectorConfig) ConnectorFeatures {
+	overrides := s.features.For(connector.ID)
+	result := ConnectorFeatures{ConnectorID: connector.ID, Flags: make([]FeatureState, 0, len(models.FeatureFlags))}
+	for _, flag := range models.FeatureFlags {
+		state := FeatureState{FeatureFlag: flag, Enabled: connector.FeatureEnabled(flag.Name, overrides)}
+		if enabled, ok := connector.Features[flag.Name]; ok {
+			state.Configured = &enabled
+		}
+		if enabled, ok := overrides[flag.Name]; ok {
+			state.Override = &enabled
+		}
+		result.Flags = append(result.Flags, state)
+	}
+	return result
+}
+
+// handleFeatures returns the feature flags of a connector (GET /api/v1/connectors/{id}/features),
+// and overrides (PUT) or stops overriding (DELETE) one of them (/api/v1/connectors/{id}/features/{flag})
+func (s *Server) handleFeatures(w http.ResponseWriter, r *http.Request, connector *models.ConnectorConfig, flag string) {
+	if s.features == nil {
+		s.handleNotFound(w, r)
+		return
+	}
+
+	if flag == "" {
+		if allowMethod(w, r, http.MethodGet) {
+			writeJSON(w, http.StatusOK, s.connectorFeatures(connector))
+		}
+		return
+	}
+	if _, ok := models.LookupFeatureFlag(flag); !ok {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound, fmt.Sprintf("feature flag %q not found", flag))
+		return
+	}
+
+	var enabled *bool
+	switch r.Method {
+	case http.MethodPut:
+		var req FeatureRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, featureMaxBodyBytes)).Decode(&req); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid feature flag request: %v", err))
+			return
+		}
+		if req.Enabled == nil {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "enabled is required")
+			return
+		}
+		if err := connector.CheckFeature(flag, *req.Enabled); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("connector %q: %v", connector.ID, err))
+			return
+		}
+		enabled = req.Enabled
+	case http.MethodDelete:
+	default:
+		w.Header().Set("Allow", "PUT, DELETE")
+		writeProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed,
+			fmt.Sprintf("method %s is not allowed, use PUT or DELETE", r.Method))
+		return
+	}
+
+	before := connector.FeatureEnabled(flag, s.features.For(connector.ID))
+	err := s.features.Set(connector.ID, flag, enabled)
+	s.recordAudit(r.Context(), audit.Entry{
+		Action:  audit.ActionFeatureFlag,
+		Target:  connector.ID,
+		Before:  map[string]bool{flag: before},
+		After:   map[string]bool{flag: connector.FeatureEnabled(flag, s.features.For(connector.ID))},
+		Details: map[string]interface{}{"flag": flag, "override": enabled},
+	}, err)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	s.logger.Info("Changed feature flag override",
+		zap.String("connector_id", connector.ID),
+		zap.String("flag", flag),
+		zap.Any("override", enabled),
+	)
+	writeJSON(w, http.StatusOK, s.connectorFeatures(connector))
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+)
+
+// Freshness statuses of a memory ingested by a connector
+const (
+	FreshnessFresh   = "fresh"   // the Memory API serves the version that was ingested
+	FreshnessStale   = "stale"   // the memory changed since it was ingested
+	FreshnessMissing = "missing" // the Memory API doesn't serve the memory anymore (or beyond max_memories)
+	FreshnessUnknown = "unknown" // no version was recorded: ingested by an older release, or blocked or a duplicate
+)
+
+// Reasons of a stale memory
+const (
+	StaleContentChanged   = "content_changed"
+	StaleUpdatedAtChanged = "updated_at_changed"
+)
+
+// MemoryFetcher fetches the current version of memories from the Memory API of a connector
+type MemoryFetcher interface {
+	// FetchMemory returns a memory among the latest limit memories of the connector, nil if it isn't one
+	FetchMemory(ctx context.Context, config *models.ConnectorConfig, memoryID string, limit int) (*models.Memory, error)
+	// FetchMemories returns those of the memories among the latest limit memories of the connector, by ID
+	FetchMemories(ctx context.Context, config *models.ConnectorConfig, memoryIDs []string, limit int) (map[string]*models.Memory, error)
+}
+
+// MemoryFreshness reports whether the knowledge graph holds the current version of a memory
+type MemoryFreshness struct {
+	URI        string                     `json:"uri"`
+	MemoryID   string                     `json:"memory_id"`
+	Stale      bool                       `json:"stale"` // a connector ingested a version that is stale or missing
+	Connectors []ConnectorMemoryFreshness `json:"connectors"`
+}
+
+// ConnectorMemoryFreshness compares the version of a memory a connector ingested with the current one
+type ConnectorMemoryFreshness struct {
+	ConnectorID string                `json:"connector_id"`
+	ContextID   string                `json:"context_id"`
+	Status      string                `json:"status"`
+	Reasons     []string              `json:"reasons,omitempty"` // why a memory is stale
+	Ingested    *models.MemoryVersion `json:"ingested,omitempty"`
+	Current     *CurrentMemoryVersion `json:"current,omitempty"` // missing if the Memory API doesn't serve the memory
+}
+
+// CurrentMemoryVersion identifies the version of a memory the Memory API serves
+type CurrentMemoryVersion struct {
+	ContentHash string  `json:"content_hash"`
+	UpdatedAt   *string `json:"updated_at,omitempty"`
+}
+
+// handleLookupFreshness compares the version of a memory each connector ingested with the version
+// the Memory API serves now, by content hash and updated_at. Query parameters: memory_id,
+// context_id (repeatable or comma-separated, all contexts if missing), max_memories.
+func (s *Server) handleLookupFreshness(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	if s.memories == nil {
+		s.handleNotFound(w, r)
+		return
+	}
+
+	values := r.URL.Query()
+	memoryID := values.Get("memory_id")
+	if memoryID == "" {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "query parameter memory_id is required")
+		return
+	}
+	uri := utils.MemoryURI(memoryID)
+
+	limit := defaultMemoryScanLimit
+	if value := values.Get("max_memories"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > maxMemoryScanLimit {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest,
+				fmt.Sprintf("max_memories must be between 1 and %d", maxMemoryScanLimit))
+			return
+		}
+	}
+
+	contextIDs := queryList(r, "context_id")
+	if err := authorizeContexts(r.Context(), contextIDs...); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+	lookup, err := s.lookupMemory(r.Context(), uri, memoryID, contextIDs)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+	if len(lookup.IngestedBy) == 0 {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound,
+			fmt.Sprintf("memory %q has not been ingested by any connector", memoryID))
+		return
+	}
+
+	freshness := MemoryFreshness{URI: uri, MemoryID: memoryID, Connectors: []ConnectorMemoryFreshness{}}
+	for _, entry := range lookup.IngestedBy {
+		connector, err := s.configs().GetConnectorByID(entry.ConnectorID)
+		if err != nil {
+			s.writeInternalError(w, r, err)
+			return
+		}
+		syncState, err := s.stateManager.GetState(r.Context(), connector.ID)
+		if err != nil {
+			s.writeInternalError(w, r, err)
+			return
+		}
+		current, err := s.memories.FetchMemory(r.Context(), connector, memoryID, limit)
+		if err != nil {
+			writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable,
+				fmt.Sprintf("connector %s: %v", connector.ID, err))
+			return
+		}
+
+		result := compareMemoryVersions(syncState.Versions, memoryID, current)
+		result.ConnectorID, result.ContextID = connector.ID, connector.ContextID
+		freshness.Stale = freshness.Stale || result.Status == FreshnessStale || result.Status == FreshnessMissing
+		freshness.Connectors = append(freshness.Connectors, result)
+	}
+
+	writeJSON(w, http.StatusOK, freshness)
+}
+
+// compareMemoryVersions compares the ingested version of a memory with its current one; current is
+// nil if the Memory API doesn't serve the memory anymore
+func compareMemoryVersions(versions map[string]models.MemoryVersion, memoryID string, current *models.Memory) ConnectorMemoryFreshness {
+	var result ConnectorMemoryFreshness
+	if current != nil {
+		result.Current = &CurrentMemoryVersion{ContentHash: current.ContentHash(), UpdatedAt: current.UpdatedAt}
+	}
+	if ingested, ok := versions[memoryID]; ok {
+		result.Ingested = &ingested
+	}
+
+	switch {
+	case result.Current == nil:
+		result.Status = FreshnessMissing
+	case result.Ingested == nil:
+		result.Status = FreshnessUnknown
+	default:
+		if result.Ingested.ContentHash != result.Current.ContentHash {
+			result.Reasons = append(result.Reasons, StaleContentChanged)
+		}
+		if !sameTimestamp(result.Ingested.UpdatedAt, result.Current.UpdatedAt) {
+			result.Reasons = append(result.Reasons, StaleUpdatedAtChanged)
+		}
+		result.Status = FreshnessFresh
+		if len(result.Reasons) > 0 {
+			result.Status = FreshnessStale
+		}
+	}
+	return result
+}
+
+// sameTimestamp returns true if two optional timestamps are both missing or name the same time
+func sameTimestamp(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if *a == *b {
+		return true
+	}
+	timeA, errA := time.Parse(time.RFC3339, *a)
+	timeB, errB := time.Parse(time.RFC3339, *b)
+	return errA == nil && errB == nil && timeA.Equal(timeB)
+}
@@ -0,0 +1,548 @@
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+)
+
+//go:embed schema.graphql
+var graphQLSchema string
+
+// GraphQL limits
+const (
+	graphQLMaxDepth     = 10
+	graphQLMaxBodyBytes = 1 << 20
+	graphQLMaxNodes     = 1000
+)
+
+// graphQLRequest is a GraphQL request as sent by GraphQL clients
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// newGraphQLSchema parses the GraphQL schema and binds it to the server's resolvers
+func (s *Server) newGraphQLSchema() *graphql.Schema {
+	return graphql.MustParseSchema(graphQLSchema, &graphQLResolver{s: s},
+		graphql.UseFieldResolvers(),
+		graphql.MaxDepth(graphQLMaxDepth),
+	)
+}
+
+// handleGraphQL executes GraphQL queries sent as POST (JSON body) or GET (query parameters).
+// Malformed requests get a problem response, query errors are reported in the GraphQL errors list.
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid variables: %v", err))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, graphQLMaxBodyBytes)).Decode(&req); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid GraphQL request: %v", err))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed,
+			fmt.Sprintf("method %s is not allowed, use GET or POST", r.Method))
+		return
+	}
+
+	if req.Query == "" {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "query is required")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.graphqlSchema.Exec(r.Context(), req.Query, req.OperationName, req.Variables))
+}
+
+// graphQLResolver resolves the Query type
+type graphQLResolver struct {
+	s *Server
+}
+
+// Connectors resolves Query.connectors, the connectors of the client's contexts
+func (q *graphQLResolver) Connectors(ctx context.Context) []*connectorResolver {
+	jobs := q.s.scheduler.GetScheduledJobs()
+	connectors := q.s.scopedConnectors(ctx)
+
+	result := make([]*connectorResolver, 0, len(connectors))
+	for i := range connectors {
+		result = append(result, &connectorResolver{s: q.s, info: connectorInfo(&connectors[i], jobs)})
+	}
+	return result
+}
+
+// Connector resolves Query.connector, null for connectors outside the client's contexts
+func (q *graphQLResolver) Connector(ctx context.Context, args struct{ ID graphql.ID }) *connectorResolver {
+	connector := q.s.connectorResolver(string(args.ID))
+	if connector == nil || authorizeContexts(ctx, connector.info.ContextID) != nil {
+		return nil
+	}
+	return connector
+}
+
+// Memory resolves Query.memory
+func (q *graphQLResolver) Memory(ctx context.Context, args struct {
+	URI        string
+	ContextID  *string
+	ContextIDs *[]string
+}) (*memoryResolver, error) {
+	uri, memoryID, err := q.s.resolveMemoryReference(ctx, args.URI)
+	if errors.Is(err, errUnknownCitation) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var contextIDs []string
+	if args.ContextID != nil {
+		contextIDs = append(contextIDs, *args.ContextID)
+	}
+	contextIDs = append(contextIDs, optionalList(args.ContextIDs)...)
+	if err := authorizeContexts(ctx, contextIDs...); err != nil {
+		return nil, err
+	}
+
+	lookup, err := q.s.lookupMemory(ctx, uri, memoryID, contextIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(lookup.IngestedBy) == 0 {
+		return nil, nil
+	}
+
+	return &memoryResolver{s: q.s, ID: graphql.ID(memoryID), URI: uri, lookup: &lookup}, nil
+}
+
+// Graph resolves Query.graph
+func (q *graphQLResolver) Graph(ctx context.Context, args struct {
+	Label      string
+	MaxDepth   int32
+	MaxNodes   int32
+	ContextIDs *[]string
+}) (*graphNode, error) {
+	if args.MaxDepth < 1 || args.MaxNodes < 1 || args.MaxNodes > graphQLMaxNodes {
+		return nil, fmt.Errorf("maxDepth must be positive and maxNodes between 1 and %d", graphQLMaxNodes)
+	}
+
+	label := q.s.expandAlias(args.Label, optionalList(args.ContextIDs)...)
+	graph, err := q.s.lightragClient.GetKnowledgeGraph(ctx, label, int(args.MaxDepth), int(args.MaxNodes))
+	if err != nil {
+		return nil, err
+	}
+
+	node := newGraphNode(graph)
+	node.Label = label
+	return node, nil
+}
+
+// SearchLabels resolves Query.searchLabels
+func (q *graphQLResolver) SearchLabels(ctx context.Context, args struct {
+	Query      string
+	Limit      int32
+	ContextIDs *[]string
+}) ([]string, error) {
+	if args.Limit < 1 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	canonical := q.s.expandAlias(args.Query, optionalList(args.ContextIDs)...)
+	if canonical == args.Query {
+		return q.s.lightragClient.SearchLabels(ctx, args.Query, int(args.Limit))
+	}
+
+	// Labels of the canonical name first, then those of the alias (e.g. split off before it was registered)
+	labels := []string{}
+	seen := make(map[string]bool)
+	for _, query := range []string{canonical, args.Query} {
+		found, err := q.s.lightragClient.SearchLabels(ctx, query, int(args.Limit))
+		if err != nil {
+			return nil, err
+		}
+		for _, label := range found {
+			if !seen[label] && len(labels) < int(args.Limit) {
+				seen[label] = true
+				labels = append(labels, label)
+			}
+		}
+	}
+	return labels, nil
+}
+
+// optionalList returns the values of an optional list argument
+func optionalList(values *[]string) []string {
+	if values == nil {
+		return nil
+	}
+	return *values
+}
+
+// connectorResolver returns the resolver of a configured connector, nil if it doesn't exist
+func (s *Server) connectorResolver(id string) *connectorResolver {
+	connector, err := s.configs().GetConnectorByID(id)
+	if err != nil {
+		return nil
+	}
+	return &connectorResolver{s: s, info: connectorInfo(connector, s.scheduler.GetScheduledJobs())}
+}
+
+// connectorResolver resolves the Connector type
+type connectorResolver struct {
+	s    *Server
+	info ConnectorInfo
+}
+
+// ID resolves Connector.id
+func (c *connectorResolver) ID() graphql.ID {
+	return graphql.ID(c.info.ID)
+}
+
+// Enabled resolves Connector.enabled
+func (c *connectorResolver) Enabled() bool {
+	return c.info.Enabled
+}
+
+// ContextID resolves Connector.contextId
+func (c *connectorResolver) ContextID() string {
+	return c.info.ContextID
+}
+
+// ScheduleType resolves Connector.scheduleType
+func (c *connectorResolver) ScheduleType() string {
+	return c.info.Schedule.Type
+}
+
+// ScheduleDescription resolves Connector.scheduleDescription
+func (c *connectorResolver) ScheduleDescription() string {
+	return c.info.ScheduleDescription
+}
+
+// NextRun resolves Connector.nextRun
+func (c *connectorResolver) NextRun() *string {
+	return formatOptionalTime(c.info.NextRun)
+}
+
+// TransformStrategy resolves Connector.transformStrategy
+func (c *connectorResolver) TransformStrategy() string {
+	return c.info.Transform.Strategy
+}
+
+// Status resolves Connector.status
+func (c *connectorResolver) Status(ctx context.Context) (*connectorStatusNode, error) {
+	status, err := c.s.connectorStatus(ctx, &c.info.ConnectorConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &connectorStatusNode{
+		State:          status.State,
+		LastSyncTime:   formatOptionalTime(status.LastSyncTime),
+		NextSyncTime:   formatOptionalTime(status.NextSyncTime),
+		LastSyncReport: newSyncReportNode(status.LastSyncReport),
+	}
+	if status.ErrorMessage != "" {
+		node.ErrorMessage = &status.ErrorMessage
+	}
+	if status.QueuePosition > 0 {
+		position := int32(status.QueuePosition)
+		node.QueuePosition = &position
+	}
+	return node, nil
+}
+
+// History resolves Connector.history
+func (c *connectorResolver) History(ctx context.Context) ([]*syncReportNode, error) {
+	history, err := c.s.connectorHistory(ctx, &c.info.ConnectorConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*syncReportNode, 0, len(history.Reports))
+	for i := range history.Reports {
+		reports = append(reports, newSyncReportNode(&history.Reports[i]))
+	}
+	return reports, nil
+}
+
+// Memories resolves Connector.memories
+func (c *connectorResolver) Memories(ctx context.Context, args struct{ First int32 }) ([]*memoryResolver, error) {
+	syncState, err := c.s.stateManager.GetState(ctx, c.info.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(syncState.ProcessedIDs))
+	for id := range syncState.ProcessedIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if args.First >= 0 && int(args.First) < len(ids) {
+		ids = ids[:args.First]
+	}
+
+	memories := make([]*memoryResolver, 0, len(ids))
+	for _, id := range ids {
+		memories = append(memories, &memoryResolver{s: c.s, ID: graphql.ID(id), URI: utils.MemoryURI(id)})
+	}
+	return memories, nil
+}
+
+// memoryResolver resolves the Memory type
+type memoryResolver struct {
+	s      *Server
+	ID     graphql.ID
+	URI    string
+	lookup *MemoryLookup // resolved lazily if nil
+}
+
+// resolveLookup looks up the connectors that ingested the memory unless that's done already
+func (m *memoryResolver) resolveLookup(ctx context.Context) error {
+	if m.lookup != nil {
+		return nil
+	}
+	lookup, err := m.s.lookupMemory(ctx, m.URI, string(m.ID), nil)
+	if err != nil {
+		return err
+	}
+	m.lookup = &lookup
+	return nil
+}
+
+// IngestedBy resolves Memory.ingestedBy
+func (m *memoryResolver) IngestedBy(ctx context.Context) ([]*connectorResolver, error) {
+	if err := m.resolveLookup(ctx); err != nil {
+		return nil, err
+	}
+
+	connectors := make([]*connectorResolver, 0, len(m.lookup.IngestedBy))
+	for _, entry := range m.lookup.IngestedBy {
+		if connector := m.s.connectorResolver(entry.ConnectorID); connector != nil {
+			connectors = append(connectors, connector)
+		}
+	}
+	return connectors, nil
+}
+
+// ContextIDs resolves Memory.contextIds
+func (m *memoryResolver) ContextIDs(ctx context.Context) ([]string, error) {
+	if err := m.resolveLookup(ctx); err != nil {
+		return nil, err
+	}
+	return m.lookup.ContextIDs, nil
+}
+
+// DeepLinks resolves Memory.deepLinks
+func (m *memoryResolver) DeepLinks(ctx context.Context) ([]string, error) {
+	if err := m.resolveLookup(ctx); err != nil {
+		return nil, err
+	}
+
+	links := []string{}
+	seen := make(map[string]bool)
+	for _, entry := range m.lookup.IngestedBy {
+		if entry.DeepLink != "" && !seen[entry.DeepLink] {
+			seen[entry.DeepLink] = true
+			links = append(links, entry.DeepLink)
+		}
+	}
+	return links, nil
+}
+
+// connectorStatusNode is the ConnectorStatus type
+type connectorStatusNode struct {
+	State          string
+	LastSyncTime   *string
+	NextSyncTime   *string
+	LastSyncReport *syncReportNode
+	ErrorMessage   *string
+	QueuePosition  *int32
+}
+
+// syncReportNode is the SyncReport type
+type syncReportNode struct {
+	ConnectorID    string
+	ContextID      string
+	StartTime      string
+	EndTime        string
+	DurationMs     float64
+	Status         string
+	TotalFetched   int32
+	TotalProcessed int32
+	TotalSkipped   int32
+	TotalFailed    int32
+	TotalDeferred  int32
+	ErrorMessage   *string
+}
+
+// newSyncReportNode converts a sync report (nil stays nil)
+func newSyncReportNode(report *models.SyncReport) *syncReportNode {
+	if report == nil {
+		return nil
+	}
+
+	node := &syncReportNode{
+		ConnectorID:    report.ConnectorID,
+		ContextID:      report.ContextID,
+		StartTime:      report.StartTime.Format(time.RFC3339),
+		EndTime:        report.EndTime.Format(time.RFC3339),
+		DurationMs:     float64(report.Duration) / float64(time.Millisecond),
+		Status:         report.Status,
+		TotalFetched:   int32(report.TotalFetched),
+		TotalProcessed: int32(report.TotalProcessed),
+		TotalSkipped:   int32(report.TotalSkipped),
+		TotalFailed:    int32(report.TotalFailed),
+		TotalDeferred:  int32(report.TotalDeferred),
+	}
+	if report.ErrorMessage != "" {
+		node.ErrorMessage = &report.ErrorMessage
+	}
+	return node
+}
+
+// graphNode is the Graph type
+type graphNode struct {
+	Label         string
+	Entities      []*entityNode
+	Relationships []*relationshipNode
+	Truncated     bool
+}
+
+// entityNode is the Entity type
+type entityNode struct {
+	ID          graphql.ID
+	Labels      []string
+	EntityType  *string
+	Description *string
+	Properties  []*propertyNode
+}
+
+// relationshipNode is the Relationship type
+type relationshipNode struct {
+	ID          graphql.ID
+	Type        *string
+	Source      *entityNode
+	Target      *entityNode
+	Description *string
+	Keywords    *string
+	Weight      *float64
+	Properties  []*propertyNode
+}
+
+// propertyNode is the Property type
+type propertyNode struct {
+	Key   string
+	Value string
+}
+
+// newGraphNode converts a LightRAG knowledge graph. Relationships to entities outside a
+// truncated graph point to entities that only have an ID.
+func newGraphNode(graph *client.KnowledgeGraph) *graphNode {
+	node := &graphNode{
+		Entities:      make([]*entityNode, 0, len(graph.Nodes)),
+		Relationships: make([]*relationshipNode, 0, len(graph.Edges)),
+		Truncated:     graph.IsTruncated,
+	}
+
+	entities := make(map[string]*entityNode, len(graph.Nodes))
+	for _, graphNode := range graph.Nodes {
+		entity := &entityNode{
+			ID:          graphql.ID(graphNode.ID),
+			Labels:      graphNode.Labels,
+			EntityType:  stringProperty(graphNode.Properties, "entity_type"),
+			Description: stringProperty(graphNode.Properties, "description"),
+			Properties:  newPropertyNodes(graphNode.Properties),
+		}
+		if entity.Labels == nil {
+			entity.Labels = []string{}
+		}
+		entities[graphNode.ID] = entity
+		node.Entities = append(node.Entities, entity)
+	}
+
+	entity := func(id string) *entityNode {
+		if entity, ok := entities[id]; ok {
+			return entity
+		}
+		return &entityNode{ID: graphql.ID(id), Labels: []string{}, Properties: []*propertyNode{}}
+	}
+
+	for _, edge := range graph.Edges {
+		relationship := &relationshipNode{
+			ID:          graphql.ID(edge.ID),
+			Source:      entity(edge.Source),
+			Target:      entity(edge.Target),
+			Description: stringProperty(edge.Properties, "description"),
+			Keywords:    stringProperty(edge.Properties, "keywords"),
+			Properties:  newPropertyNodes(edge.Properties),
+		}
+		if edge.Type != "" {
+			relationship.Type = &edge.Type
+		}
+		if weight, ok := edge.Properties["weight"].(float64); ok {
+			relationship.Weight = &weight
+		}
+		node.Relationships = append(node.Relationships, relationship)
+	}
+
+	return node
+}
+
+// newPropertyNodes converts raw LightRAG properties, sorted by key
+func newPropertyNodes(properties map[string]interface{}) []*propertyNode {
+	nodes := make([]*propertyNode, 0, len(properties))
+	for key, value := range properties {
+		text, ok := value.(string)
+		if !ok {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				continue
+			}
+			text = string(encoded)
+		}
+		nodes = append(nodes, &propertyNode{Key: key, Value: text})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Key < nodes[j].Key })
+	return nodes
+}
+
+// stringProperty returns a non-empty string property, nil otherwise
+func stringProperty(properties map[string]interface{}, key string) *string {
+	value, ok := properties[key].(string)
+	if !ok || strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return &value
+}
+
+// formatOptionalTime formats an optional time as RFC 3339 (nil stays nil)
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
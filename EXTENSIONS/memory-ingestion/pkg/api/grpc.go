@@ -0,0 +1,492 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/api/pb"
+	"github.com/kamir/memory-connector/pkg/audit"
+	"github.com/kamir/memory-connector/pkg/config"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/reporting"
+	"github.com/kamir/memory-connector/pkg/scheduler"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// correlationIDMetadata carries the correlation ID in gRPC metadata (the lowercase form of CorrelationIDHeader)
+const correlationIDMetadata = "x-correlation-id"
+
+// progressBuffer is the number of progress updates buffered per TriggerSync stream.
+// Updates beyond it are dropped; progress counts are cumulative, so the next update catches up.
+const progressBuffer = 64
+
+// grpcMethodRoles maps the gRPC methods that don't need the reader role to the role they need,
+// empty for public ones
+var grpcMethodRoles = map[string]string{
+	pb.MemoryConnector_Health_FullMethodName:      "",
+	pb.MemoryConnector_TriggerSync_FullMethodName: config.RoleOperator,
+}
+
+// grpcService implements the MemoryConnector gRPC service on top of the API server
+type grpcService struct {
+	pb.UnimplementedMemoryConnectorServer
+	s *Server
+}
+
+// newGRPCServer creates the gRPC server, using tlsConfig for transport security if non-nil
+func (s *Server) newGRPCServer(tlsConfig *tls.Config) *grpc.Server {
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig.Clone())))
+	}
+
+	grpcServer := grpc.NewServer(options...)
+	pb.RegisterMemoryConnectorServer(grpcServer, &grpcService{s: s})
+	reflection.Register(grpcServer)
+
+	return grpcServer
+}
+
+// startGRPC listens on the gRPC port and serves in the background
+func (s *Server) startGRPC(tlsConfig *tls.Config) error {
+	addr := net.JoinHostPort(s.serverConfig.Host, strconv.Itoa(s.serverConfig.GRPC.Port))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s.grpcServer = s.newGRPCServer(tlsConfig)
+
+	s.logger.Info("gRPC server listening",
+		zap.String("addr", listener.Addr().String()),
+		zap.Bool("tls", tlsConfig != nil),
+	)
+
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.logger.Error("gRPC server failed", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+// unaryInterceptor assigns correlation IDs, bounds calls by the request timeout, recovers panics, and logs calls
+func (s *Server) unaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp interface{}, err error) {
+	start := time.Now()
+	ctx = grpcCorrelationID(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.serverConfig.RequestTimeout)*time.Second)
+	defer cancel()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.reporter.CapturePanic(recovered, reporting.Callers(), map[string]string{
+				"component":      "grpc",
+				"method":         info.FullMethod,
+				"correlation_id": CorrelationID(ctx),
+			})
+			err = s.grpcInternalError(ctx, fmt.Errorf("panic: %v", recovered))
+		}
+		s.logGRPC(ctx, info.FullMethod, start, err)
+	}()
+
+	if ctx, err = s.grpcAuthorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor assigns correlation IDs, recovers panics, and logs streaming calls. Streams are
+// not bounded by the request timeout, they last as long as the operation they report on.
+func (s *Server) streamInterceptor(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	start := time.Now()
+	ctx := grpcCorrelationID(stream.Context())
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.reporter.CapturePanic(recovered, reporting.Callers(), map[string]string{
+				"component":      "grpc",
+				"method":         info.FullMethod,
+				"correlation_id": CorrelationID(ctx),
+			})
+			err = s.grpcInternalError(ctx, fmt.Errorf("panic: %v", recovered))
+		}
+		s.logGRPC(ctx, info.FullMethod, start, err)
+	}()
+
+	if ctx, err = s.grpcAuthorize(ctx, info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, &correlatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// correlatedStream is a server stream whose context carries the correlation ID
+type correlatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the stream context with the correlation ID
+func (c *correlatedStream) Context() context.Context {
+	return c.ctx
+}
+
+// grpcCorrelationID adds the client's correlation ID (or a new one) to the context and the response header
+func grpcCorrelationID(ctx context.Context) context.Context {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(correlationIDMetadata); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if id == "" || len(id) > 128 {
+		id = newCorrelationID()
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs(correlationIDMetadata, id))
+	return context.WithValue(ctx, correlationIDKey, id)
+}
+
+// grpcAuthorize authenticates a call by the bearer token or API key of its metadata and rejects it
+// if its principal lacks the role of the method, if authorization is enabled. The returned context
+// carries the principal.
+func (s *Server) grpcAuthorize(ctx context.Context, method string) (context.Context, error) {
+	role, ok := grpcMethodRoles[method]
+	if !ok {
+		role = config.RoleReader
+	}
+	if s.auth == nil || role == "" {
+		return ctx, nil
+	}
+
+	credential := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			credential, _ = strings.CutPrefix(values[0], "Bearer ")
+		} else if values := md.Get(strings.ToLower(APIKeyHeader)); len(values) > 0 {
+			credential = values[0]
+		}
+	}
+	principal, err := s.auth.authenticate(strings.TrimSpace(credential))
+	if err != nil {
+		return ctx, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if !principal.allowed(role) {
+		return ctx, status.Errorf(codes.PermissionDenied, "role %s may not call %s, it requires role %s", principal.Role, method, role)
+	}
+	return context.WithValue(ctx, principalKey, principal), nil
+}
+
+// logGRPC logs a gRPC call with its status code, duration, and correlation ID
+func (s *Server) logGRPC(ctx context.Context, method string, start time.Time, err error) {
+	s.logger.Info("gRPC call",
+		zap.String("method", method),
+		zap.String("code", status.Code(err).String()),
+		zap.Duration("duration", time.Since(start)),
+		zap.String("correlation_id", CorrelationID(ctx)),
+	)
+}
+
+// grpcInternalError logs err with the correlation ID and returns a generic internal error status
+func (s *Server) grpcInternalError(ctx context.Context, err error) error {
+	s.logger.Error("gRPC call failed",
+		zap.String("correlation_id", CorrelationID(ctx)),
+		zap.Error(err),
+	)
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// Health reports service health including LightRAG reachability
+func (g *grpcService) Health(ctx context.Context, _ *pb.HealthRequest) (*pb.HealthResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	if err := g.s.lightragClient.HealthCheck(ctx); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "LightRAG health check failed: %v", err)
+	}
+
+	return &pb.HealthResponse{Status: "ok"}, nil
+}
+
+// ListConnectors lists the configured connectors of the client's contexts
+func (g *grpcService) ListConnectors(ctx context.Context, _ *pb.ListConnectorsRequest) (*pb.ListConnectorsResponse, error) {
+	jobs := g.s.scheduler.GetScheduledJobs()
+	connectors := g.s.scopedConnectors(ctx)
+
+	response := &pb.ListConnectorsResponse{Connectors: make([]*pb.Connector, 0, len(connectors))}
+	for i := range connectors {
+		response.Connectors = append(response.Connectors, connectorToProto(connectorInfo(&connectors[i], jobs)))
+	}
+
+	return response, nil
+}
+
+// GetConnector returns a single connector
+func (g *grpcService) GetConnector(ctx context.Context, req *pb.GetConnectorRequest) (*pb.Connector, error) {
+	connector, err := g.connector(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return connectorToProto(connectorInfo(connector, g.s.scheduler.GetScheduledJobs())), nil
+}
+
+// GetConnectorStatus returns the current state and last sync report of a connector
+func (g *grpcService) GetConnectorStatus(ctx context.Context, req *pb.GetConnectorStatusRequest) (*pb.ConnectorStatus, error) {
+	connector, err := g.connector(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	connectorStatus, err := g.s.connectorStatus(ctx, connector)
+	if err != nil {
+		return nil, g.s.grpcInternalError(ctx, err)
+	}
+
+	return &pb.ConnectorStatus{
+		ConnectorId:    connectorStatus.ConnectorID,
+		State:          connectorStatus.State,
+		LastSyncTime:   timestampToProto(connectorStatus.LastSyncTime),
+		NextSyncTime:   timestampToProto(connectorStatus.NextSyncTime),
+		LastSyncReport: reportToProto(connectorStatus.LastSyncReport),
+		ErrorMessage:   connectorStatus.ErrorMessage,
+		QueuePosition:  int32(connectorStatus.QueuePosition),
+	}, nil
+}
+
+// GetConnectorHistory returns the recorded sync reports of a connector
+func (g *grpcService) GetConnectorHistory(ctx context.Context, req *pb.GetConnectorHistoryRequest) (*pb.SyncHistory, error) {
+	connector, err := g.connector(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := g.s.connectorHistory(ctx, connector)
+	if err != nil {
+		return nil, g.s.grpcInternalError(ctx, err)
+	}
+
+	response := &pb.SyncHistory{Reports: make([]*pb.SyncReport, 0, len(history.Reports))}
+	for i := range history.Reports {
+		response.Reports = append(response.Reports, reportToProto(&history.Reports[i]))
+	}
+
+	return response, nil
+}
+
+// TriggerSync runs a sync and streams its progress. The sync keeps running if the client goes away.
+func (g *grpcService) TriggerSync(req *pb.TriggerSyncRequest, stream pb.MemoryConnector_TriggerSyncServer) error {
+	connector, err := g.connector(stream.Context(), req.GetId())
+	if err != nil {
+		return err
+	}
+	if g.s.scheduler.IsRunning(connector.ID) {
+		return status.Errorf(codes.FailedPrecondition, "a sync for connector %q is already running", connector.ID)
+	}
+	g.s.recordAudit(stream.Context(), audit.Entry{Action: audit.ActionSyncTrigger, Target: connector.ID}, nil)
+
+	type result struct {
+		report *models.SyncReport
+		err    error
+	}
+
+	updates := make(chan models.SyncProgress, progressBuffer)
+	done := make(chan result, 1)
+
+	config := *connector
+	go func() {
+		report, err := g.s.scheduler.TriggerSyncWithProgress(&config, func(progress models.SyncProgress) {
+			select {
+			case updates <- progress:
+			default:
+			}
+		})
+		done <- result{report: report, err: err}
+	}()
+
+	for {
+		select {
+		case progress := <-updates:
+			if err := stream.Send(progressToProto(progress)); err != nil {
+				return err
+			}
+
+		case res := <-done:
+			if res.report == nil {
+				return syncError(connector.ID, res.err)
+			}
+			// A failed fetch still produces a report, its status and error message tell the client what went wrong
+			final := progressToProto(models.SyncProgress{
+				ConnectorID:  res.report.ConnectorID,
+				TotalFetched: res.report.TotalFetched,
+				TotalNew:     res.report.TotalFetched - res.report.TotalSkipped,
+				Processed:    res.report.TotalProcessed,
+				Skipped:      res.report.TotalSkipped,
+				Failed:       res.report.TotalFailed,
+			})
+			final.Report = reportToProto(res.report)
+			return stream.Send(final)
+
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
+
+// LookupMemory resolves a memory URI (as cited by LightRAG) to the connectors that ingested it
+func (g *grpcService) LookupMemory(ctx context.Context, req *pb.LookupMemoryRequest) (*pb.MemoryLookup, error) {
+	if req.GetUri() == "" {
+		return nil, status.Error(codes.InvalidArgument, "uri is required")
+	}
+
+	uri, memoryID, err := g.s.resolveMemoryReference(ctx, req.GetUri())
+	switch {
+	case errors.Is(err, utils.ErrInvalidMemoryURI):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, errUnknownCitation):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case err != nil:
+		return nil, g.s.grpcInternalError(ctx, err)
+	}
+
+	contextIDs := req.GetContextIds()
+	if req.GetContextId() != "" {
+		contextIDs = append([]string{req.GetContextId()}, contextIDs...)
+	}
+	if err := authorizeContexts(ctx, contextIDs...); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	lookup, err := g.s.lookupMemory(ctx, uri, memoryID, contextIDs)
+	if err != nil {
+		return nil, g.s.grpcInternalError(ctx, err)
+	}
+	if len(lookup.IngestedBy) == 0 {
+		return nil, status.Errorf(codes.NotFound, "memory %q has not been ingested by any connector", memoryID)
+	}
+
+	response := &pb.MemoryLookup{Uri: lookup.URI, MemoryId: lookup.MemoryID, ContextIds: lookup.ContextIDs}
+	for _, entry := range lookup.IngestedBy {
+		response.IngestedBy = append(response.IngestedBy, &pb.MemoryLookupEntry{
+			ConnectorId: entry.ConnectorID,
+			ContextId:   entry.ContextID,
+			DeepLink:    entry.DeepLink,
+		})
+	}
+
+	return response, nil
+}
+
+// connector returns the configured connector with the given ID, or a NotFound status, or
+// PermissionDenied if it's outside the client's contexts
+func (g *grpcService) connector(ctx context.Context, id string) (*models.ConnectorConfig, error) {
+	connector, err := g.s.configs().GetConnectorByID(id)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "connector %q not found", id)
+	}
+	if err := authorizeContexts(ctx, connector.ContextID); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	return connector, nil
+}
+
+// syncError maps a scheduler error to a gRPC status
+func syncError(connectorID string, err error) error {
+	switch {
+	case errors.Is(err, scheduler.ErrSyncRunning):
+		return status.Errorf(codes.FailedPrecondition, "a sync for connector %q is already running", connectorID)
+	case errors.Is(err, scheduler.ErrShuttingDown):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, scheduler.ErrPaused):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, scheduler.ErrQuotaExhausted):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "sync failed: %v", err)
+	}
+}
+
+// connectorToProto converts a connector to its protobuf message
+func connectorToProto(info ConnectorInfo) *pb.Connector {
+	return &pb.Connector{
+		Id:                  info.ID,
+		Enabled:             info.Enabled,
+		ContextId:           info.ContextID,
+		ScheduleType:        info.Schedule.Type,
+		ScheduleDescription: info.ScheduleDescription,
+		NextRun:             timestampToProto(info.NextRun),
+		TransformStrategy:   info.Transform.Strategy,
+		Metadata:            info.Metadata,
+	}
+}
+
+// reportToProto converts a sync report to its protobuf message (nil stays nil)
+func reportToProto(report *models.SyncReport) *pb.SyncReport {
+	if report == nil {
+		return nil
+	}
+
+	return &pb.SyncReport{
+		ConnectorId:    report.ConnectorID,
+		ContextId:      report.ContextID,
+		StartTime:      timestamppb.New(report.StartTime),
+		EndTime:        timestamppb.New(report.EndTime),
+		Duration:       durationpb.New(report.Duration),
+		Status:         report.Status,
+		TotalFetched:   int32(report.TotalFetched),
+		TotalProcessed: int32(report.TotalProcessed),
+		TotalSkipped:   int32(report.TotalSkipped),
+		TotalFailed:    int32(report.TotalFailed),
+		TotalDeferred:  int32(report.TotalDeferred),
+		ErrorMessage:   report.ErrorMessage,
+	}
+}
+
+// progressToProto converts a progress snapshot to its protobuf message
+func progressToProto(progress models.SyncProgress) *pb.SyncProgress {
+	return &pb.SyncProgress{
+		ConnectorId:  progress.ConnectorID,
+		TotalFetched: int32(progress.TotalFetched),
+		TotalNew:     int32(progress.TotalNew),
+		Processed:    int32(progress.Processed),
+		Skipped:      int32(progress.Skipped),
+		Failed:       int32(progress.Failed),
+		MemoryId:     progress.MemoryID,
+	}
+}
+
+// timestampToProto converts an optional time (nil stays nil)
+func timestampToProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,542 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/audit"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/scheduler"
+	"github.com/kamir/memory-connector/pkg/state"
+	"github.com/kamir/memory-connector/pkg/transformer"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// healthCheckTimeout bounds the upstream check of the health endpoint
+const healthCheckTimeout = 5 * time.Second
+
+// Limits of the latest memories fetched from the Memory API by endpoints taking max_memories
+// (freshness, relationships, queries, re-ingestion, snapshots)
+const (
+	defaultMemoryScanLimit = 1000
+	maxMemoryScanLimit     = 10000
+)
+
+// ConnectorInfo is a connector as returned by the connector endpoints
+type ConnectorInfo struct {
+	models.ConnectorConfig
+	ScheduleDescription string     `json:"schedule_description"`
+	NextRun             *time.Time `json:"next_run,omitempty"`
+}
+
+// MemoryLookup is the result of resolving a memory URI
+type MemoryLookup struct {
+	URI        string              `json:"uri"`
+	MemoryID   string              `json:"memory_id"`
+	IngestedBy []MemoryLookupEntry `json:"ingested_by"`
+	ContextIDs []string            `json:"context_ids"` // distinct contexts of IngestedBy
+
+	// ResolvedText is the text parameter with the pseudonyms of anonymized contexts replaced by real names
+	ResolvedText *string           `json:"resolved_text,omitempty"`
+	Pseudonyms   map[string]string `json:"pseudonyms,omitempty"` // pseudonym -> real name, of those resolved
+}
+
+// MemoryLookupEntry names a connector that ingested a memory
+type MemoryLookupEntry struct {
+	ConnectorID string `json:"connector_id"`
+	ContextID   string `json:"context_id"`
+	Anonymized  bool   `json:"anonymized,omitempty"` // person names were ingested as pseudonyms
+	DeepLink    string `json:"deep_link,omitempty"`  // the memory in the source system's app, if the connector has a deep_link template
+}
+
+// handleHealth reports service health including LightRAG reachability
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	if err := s.lightragClient.HealthCheck(ctx); err != nil {
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable,
+			fmt.Sprintf("LightRAG health check failed: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleTransformerMetrics returns the metrics of every transformation strategy used so far
+func (s *Server) handleTransformerMetrics(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	metrics := []transformer.StrategyMetrics{}
+	if s.transformers != nil {
+		metrics = s.transformers()
+	}
+
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+// handleListConnectors lists the configured connectors of the client's contexts
+func (s *Server) handleListConnectors(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	jobs := s.scheduler.GetScheduledJobs()
+	connectors := s.scopedConnectors(r.Context())
+
+	result := make([]ConnectorInfo, 0, len(connectors))
+	for i := range connectors {
+		result = append(result, connectorInfo(&connectors[i], jobs))
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// scopedConnectors returns the configured connectors of the contexts the client of a request may
+// look up, all of them if its credentials aren't bound to contexts
+func (s *Server) scopedConnectors(ctx context.Context) []models.ConnectorConfig {
+	connectors := s.configs().Connectors
+	scope := contextScope(ctx)
+	if scope == nil {
+		return connectors
+	}
+
+	scoped := make([]models.ConnectorConfig, 0, len(connectors))
+	for _, connector := range connectors {
+		if scope[connector.ContextID] {
+			scoped = append(scoped, connector)
+		}
+	}
+	return scoped
+}
+
+// connectorContexts returns the contexts of the configured connectors among connectorIDs
+func (s *Server) connectorContexts(connectorIDs []string) []string {
+	var contextIDs []string
+	for _, id := range connectorIDs {
+		if connector, err := s.configs().GetConnectorByID(id); err == nil {
+			contextIDs = append(contextIDs, connector.ContextID)
+		}
+	}
+	return contextIDs
+}
+
+// handleConnector routes /api/v1/connectors/{id}[/status|/history|/reports[/latest]|/trigger|/pause|/resume|/reingest|/dlq|/features[/{flag}]]
+func (s *Server) handleConnector(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/connectors/"), "/")
+
+	connector, err := s.configs().GetConnectorByID(id)
+	if err != nil {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound, fmt.Sprintf("connector %q not found", id))
+		return
+	}
+	// Reports, the Dead Letter Queue, and features name the memories of the connector's context
+	if err := authorizeContexts(r.Context(), connector.ContextID); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+
+	switch action {
+	case "":
+		if allowMethod(w, r, http.MethodGet) {
+			writeJSON(w, http.StatusOK, connectorInfo(connector, s.scheduler.GetScheduledJobs()))
+		}
+	case "status":
+		if allowMethod(w, r, http.MethodGet) {
+			s.handleConnectorStatus(w, r, connector)
+		}
+	case "history":
+		if allowMethod(w, r, http.MethodGet) {
+			s.handleConnectorHistory(w, r, connector)
+		}
+	case "reports":
+		if allowMethod(w, r, http.MethodGet) {
+			s.handleConnectorReports(w, r, connector)
+		}
+	case "reports/latest":
+		if allowMethod(w, r, http.MethodGet) {
+			s.handleLatestReport(w, r, connector)
+		}
+	case "trigger":
+		if allowMethod(w, r, http.MethodPost) {
+			s.handleTrigger(w, r, connector)
+		}
+	case "pause":
+		if allowMethod(w, r, http.MethodPost) {
+			s.handlePause(w, r, connector)
+		}
+	case "resume":
+		if allowMethod(w, r, http.MethodPost) {
+			s.handleResume(w, r, connector)
+		}
+	case "reingest":
+		if allowMethod(w, r, http.MethodPost) {
+			s.handleReingest(w, r, connector)
+		}
+	case "dlq":
+		if allowMethod(w, r, http.MethodGet) {
+			s.handleDeadLetterQueue(w, r, connector)
+		}
+	case "features":
+		s.handleFeatures(w, r, connector, "")
+	default:
+		if flag, ok := strings.CutPrefix(action, "features/"); ok && flag != "" {
+			s.handleFeatures(w, r, connector, flag)
+			return
+		}
+		s.handleNotFound(w, r)
+	}
+}
+
+// handleConnectorStatus returns the current state of a connector
+func (s *Server) handleConnectorStatus(w http.ResponseWriter, r *http.Request, connector *models.ConnectorConfig) {
+	status, err := s.connectorStatus(r.Context(), connector)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// connectorStatus combines a connector's sync state, schedule, and running sync
+func (s *Server) connectorStatus(ctx context.Context, connector *models.ConnectorConfig) (models.ConnectorStatus, error) {
+	syncState, err := s.stateManager.GetState(ctx, connector.ID)
+	if err != nil {
+		return models.ConnectorStatus{}, err
+	}
+
+	status := models.ConnectorStatus{
+		ConnectorID:    connector.ID,
+		State:          "idle",
+		LastSyncReport: syncState.LastSyncReport,
+	}
+	if !syncState.LastSyncTime.IsZero() {
+		lastSync := syncState.LastSyncTime
+		status.LastSyncTime = &lastSync
+	}
+	if job, ok := s.scheduler.GetScheduledJobs()[connector.ID]; ok && !job.NextRun.IsZero() {
+		nextRun := job.NextRun
+		status.NextSyncTime = &nextRun
+	}
+	if report := syncState.LastSyncReport; report != nil && (report.IsFailed() || report.IsUpstreamUnavailable()) {
+		status.State = "error"
+		status.ErrorMessage = report.ErrorMessage
+	}
+	if connector.Quota.Enabled() {
+		status.Quota = syncState.QuotaStatus(connector.Quota, time.Now())
+		if status.Quota.Exhausted {
+			status.State = "paused"
+			status.ErrorMessage = "daily quota exhausted: " + status.Quota.Usage()
+		}
+	}
+	if pausedAt := s.scheduler.PausedAt(connector.ID); pausedAt != nil {
+		status.State = "paused"
+		status.PausedAt = pausedAt
+		status.ErrorMessage = "paused by an operator since " + pausedAt.Format(time.RFC3339)
+	}
+	if s.scheduler.IsRunning(connector.ID) {
+		status.State = "running"
+		if position := s.scheduler.QueuePosition(connector.ID); position > 0 {
+			status.State = "queued"
+			status.QueuePosition = position
+		}
+	}
+
+	return status, nil
+}
+
+// handleConnectorHistory returns the most recent sync reports of a connector
+func (s *Server) handleConnectorHistory(w http.ResponseWriter, r *http.Request, connector *models.ConnectorConfig) {
+	history, err := s.connectorHistory(r.Context(), connector)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
+
+// handleTrigger starts a sync for a connector in the background
+func (s *Server) handleTrigger(w http.ResponseWriter, r *http.Request, connector *models.ConnectorConfig) {
+	if s.scheduler.IsRunning(connector.ID) {
+		writeProblem(w, r, http.StatusConflict, CodeConflict,
+			fmt.Sprintf("a sync for connector %q is already running", connector.ID))
+		return
+	}
+
+	if s.scheduler.PausedAt(connector.ID) != nil {
+		writeProblem(w, r, http.StatusConflict, CodeConnectorPaused,
+			fmt.Sprintf("connector %q is paused, resume it first", connector.ID))
+		return
+	}
+
+	if err := s.scheduler.CheckQuota(connector); errors.Is(err, scheduler.ErrQuotaExhausted) {
+		writeProblem(w, r, http.StatusTooManyRequests, CodeQuotaExhausted,
+			fmt.Sprintf("connector %q is paused: %v", connector.ID, err))
+		return
+	} else if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	s.recordAudit(r.Context(), audit.Entry{Action: audit.ActionSyncTrigger, Target: connector.ID}, nil)
+
+	config := *connector
+	go func() {
+		if _, err := s.scheduler.TriggerSync(&config); err != nil {
+			s.logger.Error("Triggered sync failed",
+				zap.String("connector_id", config.ID),
+				zap.Error(err),
+			)
+		}
+	}()
+
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"connector_id":   connector.ID,
+		"status":         "accepted",
+		"correlation_id": CorrelationID(r.Context()),
+	})
+}
+
+// handlePause pauses a connector and returns its status. A running sync finishes its in-flight
+// memories and checkpoints before it stops, so the response is 202 Accepted until it has.
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request, connector *models.ConnectorConfig) {
+	wasPaused := s.scheduler.PausedAt(connector.ID) != nil
+	code := http.StatusOK
+	if s.scheduler.Pause(connector.ID) {
+		code = http.StatusAccepted
+	}
+	s.recordAudit(r.Context(), audit.Entry{
+		Action: audit.ActionConnectorPause,
+		Target: connector.ID,
+		Before: map[string]bool{"paused": wasPaused},
+		After:  map[string]bool{"paused": true},
+	}, nil)
+
+	status, err := s.connectorStatus(r.Context(), connector)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, code, status)
+}
+
+// handleResume lifts the pause of a connector and returns its status
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request, connector *models.ConnectorConfig) {
+	wasPaused := s.scheduler.PausedAt(connector.ID) != nil
+	s.scheduler.Resume(connector.ID)
+	s.recordAudit(r.Context(), audit.Entry{
+		Action: audit.ActionConnectorResume,
+		Target: connector.ID,
+		Before: map[string]bool{"paused": wasPaused},
+		After:  map[string]bool{"paused": false},
+	}, nil)
+
+	status, err := s.connectorStatus(r.Context(), connector)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleLookupMemory resolves a memory URI (as cited by LightRAG) to the connectors that ingested it,
+// optionally only those of the contexts named by context_id (repeatable or comma-separated)
+func (s *Server) handleLookupMemory(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	uri := r.URL.Query().Get("uri")
+	if uri == "" {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "query parameter uri is required")
+		return
+	}
+
+	uri, memoryID, err := s.resolveMemoryReference(r.Context(), uri)
+	switch {
+	case errors.Is(err, utils.ErrInvalidMemoryURI):
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidURI, err.Error())
+		return
+	case errors.Is(err, errUnknownCitation):
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound, err.Error())
+		return
+	case err != nil:
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	contextIDs := queryList(r, "context_id")
+	if err := authorizeContexts(r.Context(), contextIDs...); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+	lookup, err := s.lookupMemory(r.Context(), uri, memoryID, contextIDs)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	if len(lookup.IngestedBy) == 0 {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound,
+			fmt.Sprintf("memory %q has not been ingested by any connector", memoryID))
+		return
+	}
+
+	if r.URL.Query().Has("text") {
+		if err := s.resolvePseudonyms(&lookup, r.URL.Query().Get("text")); err != nil {
+			s.writeInternalError(w, r, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, lookup)
+}
+
+// resolvePseudonyms resolves text (e.g. a LightRAG answer about the memory) with the pseudonyms
+// of each anonymized context that ingested the memory
+func (s *Server) resolvePseudonyms(lookup *MemoryLookup, text string) error {
+	resolved := make(map[string]string)
+	contexts := make(map[string]bool)
+	for _, entry := range lookup.IngestedBy {
+		if !entry.Anonymized || s.pseudonyms == nil || contexts[entry.ContextID] {
+			continue
+		}
+		contexts[entry.ContextID] = true
+
+		var names map[string]string
+		var err error
+		text, names, err = s.pseudonyms.Resolve(entry.ContextID, text)
+		if err != nil {
+			return err
+		}
+		for pseudonym, name := range names {
+			resolved[pseudonym] = name
+		}
+	}
+
+	lookup.ResolvedText = &text
+	lookup.Pseudonyms = resolved
+	return nil
+}
+
+// errUnknownCitation is returned for short citations no connector assigned
+var errUnknownCitation = errors.New("unknown citation")
+
+// resolveMemoryReference returns the URI and ID of the memory a reference cited by LightRAG stands
+// for: a memory URI, or the short citation of a connector with transform.short_citations
+func (s *Server) resolveMemoryReference(ctx context.Context, reference string) (string, string, error) {
+	reference = strings.TrimSpace(reference)
+	if !utils.IsShortCitation(reference) {
+		memoryID, err := utils.ParseMemoryURI(reference)
+		return reference, memoryID, err
+	}
+
+	for _, connector := range s.configs().Connectors {
+		syncState, err := s.stateManager.GetState(ctx, connector.ID)
+		if err != nil {
+			return "", "", err
+		}
+		if memoryID, ok := syncState.Citations[reference]; ok {
+			return utils.MemoryURI(memoryID), memoryID, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w %q: no connector cites a memory with it", errUnknownCitation, reference)
+}
+
+// lookupMemory finds the connectors that ingested a memory, optionally only those of the given
+// contexts (all if contextIDs is empty), and only those of contexts the client may look up
+func (s *Server) lookupMemory(ctx context.Context, uri, memoryID string, contextIDs []string) (MemoryLookup, error) {
+	lookup := MemoryLookup{URI: uri, MemoryID: memoryID, IngestedBy: []MemoryLookupEntry{}, ContextIDs: []string{}}
+
+	var wanted map[string]bool
+	if len(contextIDs) > 0 {
+		wanted = make(map[string]bool, len(contextIDs))
+		for _, contextID := range contextIDs {
+			wanted[contextID] = true
+		}
+	}
+
+	scope := contextScope(ctx)
+
+	filter, _ := s.stateManager.(state.ProcessedFilter)
+	seenContexts := make(map[string]bool)
+	for _, connector := range s.configs().Connectors {
+		if wanted != nil && !wanted[connector.ContextID] || scope != nil && !scope[connector.ContextID] {
+			continue
+		}
+		if filter != nil && !filter.MayBeProcessed(connector.ID, memoryID) {
+			continue
+		}
+
+		syncState, err := s.stateManager.GetState(ctx, connector.ID)
+		if err != nil {
+			return MemoryLookup{}, err
+		}
+		if !syncState.IsProcessed(memoryID) {
+			continue
+		}
+
+		entry := MemoryLookupEntry{
+			ConnectorID: connector.ID,
+			ContextID:   connector.ContextID,
+			Anonymized:  connector.Transform.Anonymize,
+		}
+		if connector.DeepLink != "" {
+			if entry.DeepLink, err = utils.ResolveDeepLink(connector.DeepLink, uri, connector.ContextID); err != nil {
+				return MemoryLookup{}, err
+			}
+		}
+		lookup.IngestedBy = append(lookup.IngestedBy, entry)
+		if !seenContexts[connector.ContextID] {
+			seenContexts[connector.ContextID] = true
+			lookup.ContextIDs = append(lookup.ContextIDs, connector.ContextID)
+		}
+	}
+
+	return lookup, nil
+}
+
+// handleNotFound reports unknown routes
+func (s *Server) handleNotFound(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound, fmt.Sprintf("no route for %s", r.URL.Path))
+}
+
+// allowMethod writes a 405 problem and returns false if the request method is not allowed
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+
+	w.Header().Set("Allow", method)
+	writeProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed,
+		fmt.Sprintf("method %s is not allowed, use %s", r.Method, method))
+	return false
+}
+
+// connectorInfo combines a connector's config with its schedule
+func connectorInfo(connector *models.ConnectorConfig, jobs map[string]scheduler.JobInfo) ConnectorInfo {
+	info := ConnectorInfo{
+		ConnectorConfig:     *connector,
+		ScheduleDescription: connector.GetScheduleDescription(),
+	}
+	if job, ok := jobs[connector.ID]; ok && !job.NextRun.IsZero() {
+		nextRun := job.NextRun
+		info.NextRun = &nextRun
+	}
+	return info
+}
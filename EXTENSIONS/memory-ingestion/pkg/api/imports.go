@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kamir/memory-connector/pkg/audit"
+	"github.com/kamir/memory-connector/pkg/export"
+	"github.com/kamir/memory-connector/pkg/orchestrator"
+	"github.com/kamir/memory-connector/pkg/scheduler"
+	"go.uber.org/zap"
+)
+
+// importMaxBodyBytes limits the size of imported exports
+const importMaxBodyBytes = 1 << 30
+
+// handleImport inserts the documents of a JSON Lines export sent as the request body into LightRAG
+// (POST /api/v1/imports?connector_id=)
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	documents, err := export.ReadDocuments(http.MaxBytesReader(w, r.Body, importMaxBodyBytes))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+
+	connectorID := r.URL.Query().Get("connector_id")
+	contextIDs := s.connectorContexts(orchestrator.ImportConnectorIDs(documents, connectorID))
+	if err := authorizeContexts(r.Context(), contextIDs...); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+	result, err := s.scheduler.ImportDocuments(documents, s.configs().Connectors, connectorID)
+	if !errors.Is(err, orchestrator.ErrConnectorNotConfigured) {
+		entry := audit.Entry{Action: audit.ActionImport, Target: connectorID, Details: map[string]interface{}{"documents": len(documents)}}
+		if err == nil {
+			entry.After = result
+		}
+		s.recordAudit(r.Context(), entry, err)
+	}
+	switch {
+	case errors.Is(err, orchestrator.ErrConnectorNotConfigured):
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+	case errors.Is(err, scheduler.ErrSyncRunning):
+		writeProblem(w, r, http.StatusConflict, CodeConflict,
+			fmt.Sprintf("%v, import the documents when it's done", err))
+	case errors.Is(err, orchestrator.ErrUpstreamFailed):
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, err.Error())
+	case err != nil:
+		s.writeInternalError(w, r, err)
+	default:
+		s.logger.Info("Documents imported",
+			zap.Strings("connectors", result.Connectors),
+			zap.Int("imported", result.Imported),
+			zap.Int("duplicates", result.Duplicates),
+			zap.String("correlation_id", CorrelationID(r.Context())),
+		)
+		writeJSON(w, http.StatusOK, result)
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kamir/memory-connector/pkg/audit"
+	"go.uber.org/zap"
+)
+
+// logLevelMaxBodyBytes limits the size of log level changes
+const logLevelMaxBodyBytes = 4 << 10
+
+// LogLevelRequest changes the log level of a subsystem (client, scheduler, api), or of all
+// subsystems if Subsystem is empty
+type LogLevelRequest struct {
+	Subsystem string `json:"subsystem,omitempty"`
+	Level     string `json:"level"`
+}
+
+// handleLogLevel returns (GET) or changes (PUT) the log levels of the subsystems
+func (s *Server) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	if s.logLevels == nil {
+		s.handleNotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req LogLevelRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, logLevelMaxBodyBytes)).Decode(&req); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid log level request: %v", err))
+			return
+		}
+		before := s.logLevels.Levels()
+		if err := s.logLevels.SetLevel(req.Subsystem, req.Level); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+			return
+		}
+		s.recordAudit(r.Context(), audit.Entry{
+			Action: audit.ActionLogLevel,
+			Target: req.Subsystem,
+			Before: before,
+			After:  s.logLevels.Levels(),
+		}, nil)
+		s.logger.Info("Changed log level",
+			zap.String("subsystem", req.Subsystem),
+			zap.String("level", req.Level),
+		)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed,
+			fmt.Sprintf("method %s is not allowed, use GET or PUT", r.Method))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.logLevels.Levels())
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kamir/memory-connector/pkg/utils"
+	"golang.org/x/sync/errgroup"
+)
+
+// Batch lookup limits
+const (
+	batchLookupMaxBodyBytes  = 256 << 10
+	batchLookupMaxReferences = 200 // references resolved by a request
+	batchLookupConcurrency   = 8   // references resolved at once
+)
+
+// BatchLookupRequest lists the references (memory URIs or short citations) an answer cites
+type BatchLookupRequest struct {
+	References []string `json:"references"`
+	ContextIDs []string `json:"context_ids,omitempty"` // only connectors of these contexts, all if empty
+}
+
+// BatchLookupResult resolves the references of a batch lookup
+type BatchLookupResult struct {
+	Memories []MemoryLookup `json:"memories"`  // memories ingested by some connector, in the order of their first reference
+	NotFound []string       `json:"not_found"` // references to unknown citations or memories no connector ingested
+}
+
+// handleLookupMemories resolves the memory references an answer cites in one request
+// (POST /api/v1/lookup/memories). References are resolved concurrently, so a response citing dozens
+// of memories takes about as long as the slowest of them.
+func (s *Server) handleLookupMemories(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req BatchLookupRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, batchLookupMaxBodyBytes)).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid lookup request: %v", err))
+		return
+	}
+	if len(req.References) == 0 {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "references is required")
+		return
+	}
+	if len(req.References) > batchLookupMaxReferences {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest,
+			fmt.Sprintf("at most %d references can be looked up at once, got %d", batchLookupMaxReferences, len(req.References)))
+		return
+	}
+
+	if err := authorizeContexts(r.Context(), req.ContextIDs...); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+
+	// Repeated references are resolved once
+	references := make([]string, 0, len(req.References))
+	seen := make(map[string]bool, len(req.References))
+	for _, reference := range req.References {
+		if !seen[reference] {
+			seen[reference] = true
+			references = append(references, reference)
+		}
+	}
+
+	lookups, err := s.lookupReferences(r.Context(), references, req.ContextIDs)
+	switch {
+	case errors.Is(err, utils.ErrInvalidMemoryURI):
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidURI, err.Error())
+		return
+	case err != nil:
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	result := BatchLookupResult{Memories: []MemoryLookup{}, NotFound: []string{}}
+	resolved := make(map[string]bool, len(lookups))
+	for i, lookup := range lookups {
+		switch {
+		case lookup == nil:
+			result.NotFound = append(result.NotFound, references[i])
+		case !resolved[lookup.MemoryID]:
+			// A memory cited by its URI and its short citation is listed once
+			resolved[lookup.MemoryID] = true
+			result.Memories = append(result.Memories, *lookup)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// lookupReferences resolves references concurrently to the memories they cite, nil for unknown
+// citations and memories no connector (of contextIDs, if given) ingested
+func (s *Server) lookupReferences(ctx context.Context, references, contextIDs []string) ([]*MemoryLookup, error) {
+	lookups := make([]*MemoryLookup, len(references))
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(batchLookupConcurrency)
+	for i, reference := range references {
+		i, reference := i, reference
+		group.Go(func() error {
+			uri, memoryID, err := s.resolveMemoryReference(ctx, reference)
+			if errors.Is(err, errUnknownCitation) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			lookup, err := s.lookupMemory(ctx, uri, memoryID, contextIDs)
+			if err != nil {
+				return err
+			}
+			if len(lookup.IngestedBy) > 0 {
+				lookups[i] = &lookup
+			}
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return lookups, nil
+}
+
+// isMemoryReference returns true if a file path cited by LightRAG may stand for a memory: a memory
+// URI or a short citation
+func isMemoryReference(filePath string) bool {
+	_, err := utils.ParseMemoryURI(filePath)
+	return err == nil || utils.IsShortCitation(filePath)
+}
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kamir/memory-connector/pkg/audit"
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/entitymerge"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// Entity merge analysis limits
+const (
+	defaultMergeScanNodes = 1000
+	maxMergeScanNodes     = 10000
+
+	// entityMergeMaxBodyBytes limits the size of merges to apply
+	entityMergeMaxBodyBytes = 64 << 10
+
+	// graphFieldSeparator joins the file paths of entities extracted from several documents
+	graphFieldSeparator = "<SEP>"
+)
+
+// errGraphUnavailable is returned if the knowledge graph can't be read from LightRAG
+var errGraphUnavailable = errors.New("failed to read the knowledge graph")
+
+// EntityMergeAnalysis lists probable duplicate entities extracted from memories
+type EntityMergeAnalysis struct {
+	Suggestions          []entitymerge.Suggestion `json:"suggestions"`
+	EntitiesScanned      int                      `json:"entities_scanned"`       // entities of the graph
+	EntitiesFromMemories int                      `json:"entities_from_memories"` // of those, extracted from memories of the connectors
+	Truncated            bool                     `json:"truncated"`              // the graph has more than max_nodes entities
+}
+
+// EntityMergeRequest applies merges. Without merges, all suggestions of the analysis selected by
+// the query parameters are applied.
+type EntityMergeRequest struct {
+	Merges []EntityMerge `json:"merges,omitempty"`
+}
+
+// EntityMerge merges entities of the knowledge graph into a target entity
+type EntityMerge struct {
+	Target  string   `json:"target"`
+	Sources []string `json:"sources"`
+}
+
+// EntityMergeResult is the outcome of an applied merge
+type EntityMergeResult struct {
+	EntityMerge
+	Merged bool   `json:"merged"`
+	Error  string `json:"error,omitempty"`
+}
+
+// EntityMergeResults lists the outcomes of applied merges
+type EntityMergeResults struct {
+	Results []EntityMergeResult `json:"results"`
+	Merged  int                 `json:"merged"`
+	Failed  int                 `json:"failed"`
+}
+
+// entityMergeQuery selects the entities of an analysis
+type entityMergeQuery struct {
+	connectorIDs []string // all connectors if empty
+	maxNodes     int
+	options      entitymerge.Options
+}
+
+// handleEntityMerges suggests merges of duplicate entities extracted from memories (GET) and
+// applies merges through LightRAG's entity merge API (POST). Query parameters: connector_id
+// (repeatable or comma-separated, the client's connectors if missing and it's bound to contexts),
+// min_similarity, min_shared_memories, max_nodes.
+func (s *Server) handleEntityMerges(w http.ResponseWriter, r *http.Request) {
+	query, err := parseEntityMergeQuery(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+	for _, id := range query.connectorIDs {
+		connector, err := s.configs().GetConnectorByID(id)
+		if err != nil {
+			writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound, fmt.Sprintf("connector %q not found", id))
+			return
+		}
+		if err := authorizeContexts(r.Context(), connector.ContextID); err != nil {
+			writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+			return
+		}
+	}
+	if len(query.connectorIDs) == 0 && contextScope(r.Context()) != nil {
+		// Clients bound to contexts analyze the memories of their own connectors
+		for _, connector := range s.scopedConnectors(r.Context()) {
+			query.connectorIDs = append(query.connectorIDs, connector.ID)
+		}
+		if len(query.connectorIDs) == 0 {
+			writeProblem(w, r, http.StatusForbidden, CodeForbidden, "no connector ingests the contexts the credentials are bound to")
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		analysis, err := s.analyzeEntityMerges(r.Context(), query)
+		if err != nil {
+			s.writeEntityMergeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, analysis)
+	case http.MethodPost:
+		var req EntityMergeRequest
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, entityMergeMaxBodyBytes)).Decode(&req)
+		if err != nil && !errors.Is(err, io.EOF) {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid merge request: %v", err))
+			return
+		}
+		for _, merge := range req.Merges {
+			if merge.Target == "" || len(merge.Sources) == 0 {
+				writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "every merge needs a target and sources")
+				return
+			}
+		}
+
+		merges := req.Merges
+		if len(merges) == 0 {
+			analysis, err := s.analyzeEntityMerges(r.Context(), query)
+			if err != nil {
+				s.writeEntityMergeError(w, r, err)
+				return
+			}
+			for _, suggestion := range analysis.Suggestions {
+				merge := EntityMerge{Target: suggestion.Target}
+				for _, source := range suggestion.Sources {
+					merge.Sources = append(merge.Sources, source.Name)
+				}
+				merges = append(merges, merge)
+			}
+		}
+		results := s.applyEntityMerges(r.Context(), merges)
+		s.recordAudit(r.Context(), audit.Entry{
+			Action:  audit.ActionEntityMerge,
+			Target:  strings.Join(query.connectorIDs, ","),
+			After:   results,
+			Details: map[string]interface{}{"suggested": len(req.Merges) == 0},
+		}, nil)
+		writeJSON(w, http.StatusOK, results)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed,
+			fmt.Sprintf("method %s is not allowed, use GET or POST", r.Method))
+	}
+}
+
+// parseEntityMergeQuery reads the query parameters of an entity merge request
+func parseEntityMergeQuery(r *http.Request) (entityMergeQuery, error) {
+	values := r.URL.Query()
+	query := entityMergeQuery{connectorIDs: queryList(r, "connector_id"), maxNodes: defaultMergeScanNodes}
+
+	var err error
+	if value := values.Get("min_similarity"); value != "" {
+		similarity, err := strconv.ParseFloat(value, 64)
+		if err != nil || similarity <= 0 || similarity > 1 {
+			return query, fmt.Errorf("min_similarity must be greater than 0 and at most 1")
+		}
+		query.options.MinSimilarity = similarity
+	}
+	if value := values.Get("min_shared_memories"); value != "" {
+		if query.options.MinSharedCount, err = strconv.Atoi(value); err != nil || query.options.MinSharedCount < 1 {
+			return query, fmt.Errorf("min_shared_memories must be a positive integer")
+		}
+	}
+	if value := values.Get("max_nodes"); value != "" {
+		if query.maxNodes, err = strconv.Atoi(value); err != nil || query.maxNodes < 1 || query.maxNodes > maxMergeScanNodes {
+			return query, fmt.Errorf("max_nodes must be between 1 and %d", maxMergeScanNodes)
+		}
+	}
+	return query, nil
+}
+
+// analyzeEntityMerges scans the knowledge graph for duplicate entities extracted from memories of
+// the selected connectors. Memories are referenced by URI, short citations are expanded.
+func (s *Server) analyzeEntityMerges(ctx context.Context, query entityMergeQuery) (EntityMergeAnalysis, error) {
+	graph, err := s.lightragClient.GetKnowledgeGraph(ctx, "*", 1, query.maxNodes)
+	if err != nil {
+		return EntityMergeAnalysis{}, fmt.Errorf("%w: %w", errGraphUnavailable, err)
+	}
+
+	citations, ingested, err := s.memoryIndex(ctx, query.connectorIDs)
+	if err != nil {
+		return EntityMergeAnalysis{}, err
+	}
+
+	analysis := EntityMergeAnalysis{EntitiesScanned: len(graph.Nodes), Truncated: graph.IsTruncated}
+	var entities []entitymerge.Entity
+	for _, node := range graph.Nodes {
+		entity := entitymerge.Entity{Name: node.ID}
+		if entityType := stringProperty(node.Properties, "entity_type"); entityType != nil {
+			entity.Type = *entityType
+		}
+		for _, memoryID := range memoryIDsOf(node, citations) {
+			if ingested == nil || ingested(memoryID) {
+				entity.Memories = append(entity.Memories, utils.MemoryURI(memoryID))
+			}
+		}
+		if len(entity.Memories) > 0 {
+			entities = append(entities, entity)
+		}
+	}
+	analysis.EntitiesFromMemories = len(entities)
+	analysis.Suggestions = entitymerge.Suggest(entities, query.options)
+	return analysis, nil
+}
+
+// writeEntityMergeError writes the problem of a failed analysis: 503 if LightRAG failed
+func (s *Server) writeEntityMergeError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errGraphUnavailable) {
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, err.Error())
+		return
+	}
+	s.writeInternalError(w, r, err)
+}
+
+// memoryIndex returns the short citations assigned by the connectors (citation -> memory ID) and,
+// if connectorIDs isn't empty, a function reporting whether one of them ingested a memory
+func (s *Server) memoryIndex(ctx context.Context, connectorIDs []string) (map[string]string, func(string) bool, error) {
+	selected := make(map[string]bool, len(connectorIDs))
+	for _, id := range connectorIDs {
+		selected[id] = true
+	}
+
+	citations := make(map[string]string)
+	var processed []func(string) bool
+	for _, connector := range s.configs().Connectors {
+		syncState, err := s.stateManager.GetState(ctx, connector.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for citation, memoryID := range syncState.Citations {
+			citations[citation] = memoryID
+		}
+		if selected[connector.ID] {
+			processed = append(processed, syncState.IsProcessed)
+		}
+	}
+
+	if len(connectorIDs) == 0 {
+		return citations, nil, nil
+	}
+	return citations, func(memoryID string) bool {
+		for _, isProcessed := range processed {
+			if isProcessed(memoryID) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// memoryIDsOf returns the IDs of the memories an entity was extracted from, read from the file
+// paths of its source documents (memory URIs or short citations)
+func memoryIDsOf(node client.GraphNode, citations map[string]string) []string {
+	filePath := stringProperty(node.Properties, "file_path")
+	if filePath == nil {
+		return nil
+	}
+
+	var memoryIDs []string
+	for _, reference := range strings.Split(*filePath, graphFieldSeparator) {
+		reference = strings.TrimSpace(reference)
+		if memoryID, ok := citations[reference]; ok {
+			memoryIDs = append(memoryIDs, memoryID)
+		} else if memoryID, err := utils.ParseMemoryURI(reference); err == nil {
+			memoryIDs = append(memoryIDs, memoryID)
+		}
+	}
+	return memoryIDs
+}
+
+// applyEntityMerges merges entities through LightRAG, one merge after the other. A failed merge
+// doesn't stop the others.
+func (s *Server) applyEntityMerges(ctx context.Context, merges []EntityMerge) EntityMergeResults {
+	results := EntityMergeResults{Results: []EntityMergeResult{}}
+	for _, merge := range merges {
+		result := EntityMergeResult{EntityMerge: merge}
+		if err := s.lightragClient.MergeEntities(ctx, merge.Sources, merge.Target); err != nil {
+			result.Error = err.Error()
+			results.Failed++
+			s.logger.Warn("Entity merge failed",
+				zap.String("target", merge.Target),
+				zap.Strings("sources", merge.Sources),
+				zap.Error(err),
+			)
+		} else {
+			result.Merged = true
+			results.Merged++
+			s.logger.Info("Merged entities",
+				zap.String("target", merge.Target),
+				zap.Strings("sources", merge.Sources),
+			)
+		}
+		results.Results = append(results.Results, result)
+	}
+	return results
+}
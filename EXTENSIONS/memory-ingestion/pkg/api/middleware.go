@@ -0,0 +1,246 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/reporting"
+	"go.uber.org/zap"
+)
+
+// CorrelationIDHeader carries the correlation ID of a request (accepted from clients, echoed in responses)
+const CorrelationIDHeader = "X-Correlation-ID"
+
+type contextKey string
+
+const correlationIDKey contextKey = "correlation_id"
+
+// CorrelationID returns the correlation ID of a request context
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey).(string)
+	return id
+}
+
+// withCorrelationID assigns every request a correlation ID, reusing the client's if it sent one
+func withCorrelationID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(CorrelationIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newCorrelationID()
+		}
+
+		w.Header().Set(CorrelationIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), correlationIDKey, id)))
+	})
+}
+
+// newCorrelationID returns a random 128-bit hex ID
+func newCorrelationID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// handlerPanic carries a panic of a handler goroutine, with its stack, to withRecovery
+type handlerPanic struct {
+	value interface{}
+	stack []uintptr
+}
+
+// withRecovery turns handler panics into 500 problem responses and reports them
+func (s *Server) withRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			stack := reporting.Callers()
+			if p, ok := recovered.(handlerPanic); ok {
+				recovered, stack = p.value, p.stack
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			s.reportPanic(recovered, stack, r)
+			s.writeInternalError(w, r, fmt.Errorf("panic: %v", recovered))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// reportPanic reports a handler panic, tagged with the request and its connector
+func (s *Server) reportPanic(recovered interface{}, stack []uintptr, r *http.Request) {
+	tags := map[string]string{
+		"component":      "api",
+		"method":         r.Method,
+		"path":           r.URL.Path,
+		"correlation_id": CorrelationID(r.Context()),
+	}
+	if rest, ok := strings.CutPrefix(r.URL.Path, "/api/v1/connectors/"); ok && rest != "" {
+		tags["connector_id"], _, _ = strings.Cut(rest, "/")
+	}
+	s.reporter.CapturePanic(recovered, stack, tags)
+}
+
+// statusRecorder captures the response status for logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack lets WebSocket handlers take over the connection
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to flush streamed responses
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// withLogging logs every request with its status, duration, and correlation ID
+func (s *Server) withLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		s.logger.Info("HTTP request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", recorder.status),
+			zap.Duration("duration", time.Since(start)),
+			zap.String("correlation_id", CorrelationID(r.Context())),
+		)
+	})
+}
+
+// withTimeout bounds the run time of a handler. If it hasn't finished when the timeout expires,
+// the client gets a 504 problem response instead of a connection cut by the server's WriteTimeout.
+// The handler's request context is cancelled, so upstream calls made with it are aborted.
+func (s *Server) withTimeout(timeout time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+
+		tw := &timeoutWriter{header: make(http.Header)}
+		done := make(chan struct{})
+		panicked := make(chan interface{}, 1)
+
+		go func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					panicked <- handlerPanic{value: recovered, stack: reporting.Callers()}
+				}
+			}()
+			next(tw, r)
+			close(done)
+		}()
+
+		select {
+		case recovered := <-panicked:
+			// Re-raised on the serving goroutine for withRecovery
+			panic(recovered)
+
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+
+			for key, values := range tw.header {
+				w.Header()[key] = append(w.Header()[key], values...)
+			}
+			if tw.status == 0 {
+				tw.status = http.StatusOK
+			}
+			w.WriteHeader(tw.status)
+			w.Write(tw.body.Bytes())
+
+		case <-ctx.Done():
+			tw.mu.Lock()
+			tw.timedOut = true
+			tw.mu.Unlock()
+
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				// The client went away, nobody to respond to
+				return
+			}
+
+			s.logger.Warn("Request exceeded route timeout",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Duration("timeout", timeout),
+				zap.String("correlation_id", CorrelationID(r.Context())),
+			)
+			writeProblem(w, r, http.StatusGatewayTimeout, CodeTimeout,
+				fmt.Sprintf("request did not complete within %s", timeout))
+		}
+	}
+}
+
+// timeoutWriter buffers a handler's response so it can be discarded when the handler times out
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+// Header returns the buffered response headers
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// Write buffers the response body
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	return tw.body.Write(p)
+}
+
+// WriteHeader records the response status
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+	tw.status = status
+}
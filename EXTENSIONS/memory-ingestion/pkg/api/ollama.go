@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Chat proxy limits
+const (
+	chatProxyMaxBodyBytes = 4 << 20  // a chat's history, with images
+	chatProxyChunkBytes   = 32 << 10 // relayed and flushed at once
+)
+
+// ollamaEndpoints maps the endpoints of LightRAG's Ollama API the chat proxy forwards to their
+// method. /api/generate isn't forwarded: LightRAG passes it to its LLM without the knowledge graph.
+var ollamaEndpoints = map[string]string{
+	"/api/chat":    http.MethodPost,
+	"/api/tags":    http.MethodGet,
+	"/api/version": http.MethodGet,
+	"/api/ps":      http.MethodGet,
+}
+
+// chatScopeInstruction is the system message the chat proxy puts first in every chat
+const chatScopeInstruction = "Answer from the memories of %s only. The knowledge base holds the memories " +
+	"of other contexts too; leave out what you retrieve from them and say so if nothing is left."
+
+// OllamaForwarder forwards requests to LightRAG's Ollama-compatible API
+type OllamaForwarder interface {
+	// ForwardOllama sends a request and returns its response unread; the caller closes the body
+	ForwardOllama(ctx context.Context, method, path string, body []byte) (*http.Response, error)
+}
+
+// handleOllama serves the chat proxy (/ollama/{context_ids}/api/...): chat UIs configured with it as
+// their Ollama server chat with the memories of the contexts, comma-separated in the path. Chats
+// are forwarded to LightRAG's Ollama API with a system message scoping the answer to the contexts,
+// and the answer is streamed back as LightRAG produces it.
+//
+// LightRAG can't filter its knowledge graph by context, so the scope is an instruction to the LLM,
+// not access control: the proxy isn't a way to keep the memories of contexts from each other.
+func (s *Server) handleOllama(w http.ResponseWriter, r *http.Request) {
+	if s.chatProxy == nil {
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, "the chat proxy is not enabled")
+		return
+	}
+
+	contexts, endpoint, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/ollama/"), "/")
+	path := "/" + endpoint
+	method, ok := ollamaEndpoints[path]
+	if !ok {
+		s.handleNotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r, method) {
+		return
+	}
+	contextIDs, err := s.chatContexts(contexts)
+	if err != nil {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound, err.Error())
+		return
+	}
+	if err := authorizeContexts(r.Context(), contextIDs...); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+
+	var body []byte
+	if method == http.MethodPost {
+		body, err = scopeChat(http.MaxBytesReader(w, r.Body, chatProxyMaxBodyBytes), contextIDs)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid chat request: %v", err))
+			return
+		}
+	}
+
+	// Answers stream for longer than any route timeout and the server's write timeout allow
+	timeout := time.Duration(s.serverConfig.ChatProxy.Timeout) * time.Second
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Now().Add(timeout + writeTimeoutMargin)); err != nil {
+		s.logger.Debug("Failed to extend the write deadline of a chat", zap.Error(err))
+	}
+
+	resp, err := s.chatProxy.ForwardOllama(ctx, method, path, body)
+	if err != nil {
+		s.logger.Warn("Failed to forward a chat request to LightRAG",
+			zap.String("path", path),
+			zap.String("correlation_id", CorrelationID(r.Context())),
+			zap.Error(err),
+		)
+		writeProblem(w, r, http.StatusBadGateway, CodeUpstreamUnavailable, "LightRAG's Ollama API is unavailable")
+		return
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	// Relay each chunk as it arrives; an answer cut off upstream ends the response
+	chunk := make([]byte, chatProxyChunkBytes)
+	for {
+		n, readErr := resp.Body.Read(chunk)
+		if n > 0 {
+			if _, err := w.Write(chunk[:n]); err != nil {
+				return
+			}
+			controller.Flush()
+		}
+		if readErr != nil {
+			return
+		}
+	}
+}
+
+// chatContexts parses the comma-separated context IDs of a chat proxy path, each of which must be
+// the context of a configured connector
+func (s *Server) chatContexts(contexts string) ([]string, error) {
+	known := make(map[string]bool)
+	for _, connector := range s.configs().Connectors {
+		known[connector.ContextID] = true
+	}
+
+	var contextIDs []string
+	for _, contextID := range strings.Split(contexts, ",") {
+		contextID = strings.TrimSpace(contextID)
+		if contextID == "" {
+			continue
+		}
+		if !known[contextID] {
+			return nil, fmt.Errorf("no connector ingests context %s", contextID)
+		}
+		contextIDs = append(contextIDs, contextID)
+	}
+	if len(contextIDs) == 0 {
+		return nil, fmt.Errorf("no context in %s", contexts)
+	}
+	return contextIDs, nil
+}
+
+// scopeChat reads an Ollama chat request and puts the scope instruction of the contexts before its
+// messages. Other fields are forwarded as they are.
+func scopeChat(body io.Reader, contextIDs []string) ([]byte, error) {
+	var chat map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&chat); err != nil {
+		return nil, err
+	}
+	var messages []json.RawMessage
+	if err := json.Unmarshal(chat["messages"], &messages); err != nil || len(messages) == 0 {
+		return nil, fmt.Errorf("messages is required")
+	}
+
+	instruction, err := json.Marshal(map[string]string{
+		"role":    "system",
+		"content": fmt.Sprintf(chatScopeInstruction, strings.Join(contextIDs, ", ")),
+	})
+	if err != nil {
+		return nil, err
+	}
+	chat["messages"], err = json.Marshal(append([]json.RawMessage{instruction}, messages...))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(chat)
+}
@@ -0,0 +1,1206 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: memoryconnector/v1/memory_connector.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{0}
+}
+
+type HealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HealthResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListConnectorsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConnectorsRequest) Reset() {
+	*x = ListConnectorsRequest{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConnectorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConnectorsRequest) ProtoMessage() {}
+
+func (x *ListConnectorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConnectorsRequest.ProtoReflect.Descriptor instead.
+func (*ListConnectorsRequest) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{2}
+}
+
+type ListConnectorsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Connectors    []*Connector           `protobuf:"bytes,1,rep,name=connectors,proto3" json:"connectors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConnectorsResponse) Reset() {
+	*x = ListConnectorsResponse{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConnectorsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConnectorsResponse) ProtoMessage() {}
+
+func (x *ListConnectorsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConnectorsResponse.ProtoReflect.Descriptor instead.
+func (*ListConnectorsResponse) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListConnectorsResponse) GetConnectors() []*Connector {
+	if x != nil {
+		return x.Connectors
+	}
+	return nil
+}
+
+type GetConnectorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConnectorRequest) Reset() {
+	*x = GetConnectorRequest{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConnectorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConnectorRequest) ProtoMessage() {}
+
+func (x *GetConnectorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConnectorRequest.ProtoReflect.Descriptor instead.
+func (*GetConnectorRequest) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetConnectorRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetConnectorStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConnectorStatusRequest) Reset() {
+	*x = GetConnectorStatusRequest{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConnectorStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConnectorStatusRequest) ProtoMessage() {}
+
+func (x *GetConnectorStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConnectorStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetConnectorStatusRequest) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetConnectorStatusRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetConnectorHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConnectorHistoryRequest) Reset() {
+	*x = GetConnectorHistoryRequest{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConnectorHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConnectorHistoryRequest) ProtoMessage() {}
+
+func (x *GetConnectorHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConnectorHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetConnectorHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetConnectorHistoryRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type TriggerSyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerSyncRequest) Reset() {
+	*x = TriggerSyncRequest{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerSyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerSyncRequest) ProtoMessage() {}
+
+func (x *TriggerSyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerSyncRequest.ProtoReflect.Descriptor instead.
+func (*TriggerSyncRequest) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TriggerSyncRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type LookupMemoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uri           string                 `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
+	ContextId     string                 `protobuf:"bytes,2,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	ContextIds    []string               `protobuf:"bytes,3,rep,name=context_ids,json=contextIds,proto3" json:"context_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupMemoryRequest) Reset() {
+	*x = LookupMemoryRequest{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupMemoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupMemoryRequest) ProtoMessage() {}
+
+func (x *LookupMemoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupMemoryRequest.ProtoReflect.Descriptor instead.
+func (*LookupMemoryRequest) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LookupMemoryRequest) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *LookupMemoryRequest) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *LookupMemoryRequest) GetContextIds() []string {
+	if x != nil {
+		return x.ContextIds
+	}
+	return nil
+}
+
+type Connector struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Id                  string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Enabled             bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	ContextId           string                 `protobuf:"bytes,3,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	ScheduleType        string                 `protobuf:"bytes,4,opt,name=schedule_type,json=scheduleType,proto3" json:"schedule_type,omitempty"`
+	ScheduleDescription string                 `protobuf:"bytes,5,opt,name=schedule_description,json=scheduleDescription,proto3" json:"schedule_description,omitempty"`
+	NextRun             *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=next_run,json=nextRun,proto3" json:"next_run,omitempty"`
+	TransformStrategy   string                 `protobuf:"bytes,7,opt,name=transform_strategy,json=transformStrategy,proto3" json:"transform_strategy,omitempty"`
+	Metadata            map[string]string      `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Connector) Reset() {
+	*x = Connector{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Connector) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Connector) ProtoMessage() {}
+
+func (x *Connector) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Connector.ProtoReflect.Descriptor instead.
+func (*Connector) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Connector) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Connector) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Connector) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *Connector) GetScheduleType() string {
+	if x != nil {
+		return x.ScheduleType
+	}
+	return ""
+}
+
+func (x *Connector) GetScheduleDescription() string {
+	if x != nil {
+		return x.ScheduleDescription
+	}
+	return ""
+}
+
+func (x *Connector) GetNextRun() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRun
+	}
+	return nil
+}
+
+func (x *Connector) GetTransformStrategy() string {
+	if x != nil {
+		return x.TransformStrategy
+	}
+	return ""
+}
+
+func (x *Connector) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ConnectorStatus struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConnectorId    string                 `protobuf:"bytes,1,opt,name=connector_id,json=connectorId,proto3" json:"connector_id,omitempty"`
+	State          string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	LastSyncTime   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_sync_time,json=lastSyncTime,proto3" json:"last_sync_time,omitempty"`
+	NextSyncTime   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=next_sync_time,json=nextSyncTime,proto3" json:"next_sync_time,omitempty"`
+	LastSyncReport *SyncReport            `protobuf:"bytes,5,opt,name=last_sync_report,json=lastSyncReport,proto3" json:"last_sync_report,omitempty"`
+	ErrorMessage   string                 `protobuf:"bytes,6,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	QueuePosition  int32                  `protobuf:"varint,7,opt,name=queue_position,json=queuePosition,proto3" json:"queue_position,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ConnectorStatus) Reset() {
+	*x = ConnectorStatus{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectorStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectorStatus) ProtoMessage() {}
+
+func (x *ConnectorStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectorStatus.ProtoReflect.Descriptor instead.
+func (*ConnectorStatus) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ConnectorStatus) GetConnectorId() string {
+	if x != nil {
+		return x.ConnectorId
+	}
+	return ""
+}
+
+func (x *ConnectorStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ConnectorStatus) GetLastSyncTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSyncTime
+	}
+	return nil
+}
+
+func (x *ConnectorStatus) GetNextSyncTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextSyncTime
+	}
+	return nil
+}
+
+func (x *ConnectorStatus) GetLastSyncReport() *SyncReport {
+	if x != nil {
+		return x.LastSyncReport
+	}
+	return nil
+}
+
+func (x *ConnectorStatus) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *ConnectorStatus) GetQueuePosition() int32 {
+	if x != nil {
+		return x.QueuePosition
+	}
+	return 0
+}
+
+type SyncReport struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConnectorId    string                 `protobuf:"bytes,1,opt,name=connector_id,json=connectorId,proto3" json:"connector_id,omitempty"`
+	ContextId      string                 `protobuf:"bytes,2,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	StartTime      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime        *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Duration       *durationpb.Duration   `protobuf:"bytes,5,opt,name=duration,proto3" json:"duration,omitempty"`
+	Status         string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	TotalFetched   int32                  `protobuf:"varint,7,opt,name=total_fetched,json=totalFetched,proto3" json:"total_fetched,omitempty"`
+	TotalProcessed int32                  `protobuf:"varint,8,opt,name=total_processed,json=totalProcessed,proto3" json:"total_processed,omitempty"`
+	TotalSkipped   int32                  `protobuf:"varint,9,opt,name=total_skipped,json=totalSkipped,proto3" json:"total_skipped,omitempty"`
+	TotalFailed    int32                  `protobuf:"varint,10,opt,name=total_failed,json=totalFailed,proto3" json:"total_failed,omitempty"`
+	TotalDeferred  int32                  `protobuf:"varint,11,opt,name=total_deferred,json=totalDeferred,proto3" json:"total_deferred,omitempty"`
+	ErrorMessage   string                 `protobuf:"bytes,12,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SyncReport) Reset() {
+	*x = SyncReport{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncReport) ProtoMessage() {}
+
+func (x *SyncReport) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncReport.ProtoReflect.Descriptor instead.
+func (*SyncReport) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SyncReport) GetConnectorId() string {
+	if x != nil {
+		return x.ConnectorId
+	}
+	return ""
+}
+
+func (x *SyncReport) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *SyncReport) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *SyncReport) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *SyncReport) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *SyncReport) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SyncReport) GetTotalFetched() int32 {
+	if x != nil {
+		return x.TotalFetched
+	}
+	return 0
+}
+
+func (x *SyncReport) GetTotalProcessed() int32 {
+	if x != nil {
+		return x.TotalProcessed
+	}
+	return 0
+}
+
+func (x *SyncReport) GetTotalSkipped() int32 {
+	if x != nil {
+		return x.TotalSkipped
+	}
+	return 0
+}
+
+func (x *SyncReport) GetTotalFailed() int32 {
+	if x != nil {
+		return x.TotalFailed
+	}
+	return 0
+}
+
+func (x *SyncReport) GetTotalDeferred() int32 {
+	if x != nil {
+		return x.TotalDeferred
+	}
+	return 0
+}
+
+func (x *SyncReport) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+type SyncHistory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reports       []*SyncReport          `protobuf:"bytes,1,rep,name=reports,proto3" json:"reports,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncHistory) Reset() {
+	*x = SyncHistory{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncHistory) ProtoMessage() {}
+
+func (x *SyncHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncHistory.ProtoReflect.Descriptor instead.
+func (*SyncHistory) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SyncHistory) GetReports() []*SyncReport {
+	if x != nil {
+		return x.Reports
+	}
+	return nil
+}
+
+type SyncProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConnectorId   string                 `protobuf:"bytes,1,opt,name=connector_id,json=connectorId,proto3" json:"connector_id,omitempty"`
+	TotalFetched  int32                  `protobuf:"varint,2,opt,name=total_fetched,json=totalFetched,proto3" json:"total_fetched,omitempty"`
+	TotalNew      int32                  `protobuf:"varint,3,opt,name=total_new,json=totalNew,proto3" json:"total_new,omitempty"`
+	Processed     int32                  `protobuf:"varint,4,opt,name=processed,proto3" json:"processed,omitempty"`
+	Skipped       int32                  `protobuf:"varint,5,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Failed        int32                  `protobuf:"varint,6,opt,name=failed,proto3" json:"failed,omitempty"`
+	MemoryId      string                 `protobuf:"bytes,7,opt,name=memory_id,json=memoryId,proto3" json:"memory_id,omitempty"`
+	Report        *SyncReport            `protobuf:"bytes,8,opt,name=report,proto3" json:"report,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncProgress) Reset() {
+	*x = SyncProgress{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncProgress) ProtoMessage() {}
+
+func (x *SyncProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncProgress.ProtoReflect.Descriptor instead.
+func (*SyncProgress) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SyncProgress) GetConnectorId() string {
+	if x != nil {
+		return x.ConnectorId
+	}
+	return ""
+}
+
+func (x *SyncProgress) GetTotalFetched() int32 {
+	if x != nil {
+		return x.TotalFetched
+	}
+	return 0
+}
+
+func (x *SyncProgress) GetTotalNew() int32 {
+	if x != nil {
+		return x.TotalNew
+	}
+	return 0
+}
+
+func (x *SyncProgress) GetProcessed() int32 {
+	if x != nil {
+		return x.Processed
+	}
+	return 0
+}
+
+func (x *SyncProgress) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *SyncProgress) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *SyncProgress) GetMemoryId() string {
+	if x != nil {
+		return x.MemoryId
+	}
+	return ""
+}
+
+func (x *SyncProgress) GetReport() *SyncReport {
+	if x != nil {
+		return x.Report
+	}
+	return nil
+}
+
+type MemoryLookup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uri           string                 `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
+	MemoryId      string                 `protobuf:"bytes,2,opt,name=memory_id,json=memoryId,proto3" json:"memory_id,omitempty"`
+	IngestedBy    []*MemoryLookupEntry   `protobuf:"bytes,3,rep,name=ingested_by,json=ingestedBy,proto3" json:"ingested_by,omitempty"`
+	ContextIds    []string               `protobuf:"bytes,4,rep,name=context_ids,json=contextIds,proto3" json:"context_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemoryLookup) Reset() {
+	*x = MemoryLookup{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemoryLookup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemoryLookup) ProtoMessage() {}
+
+func (x *MemoryLookup) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemoryLookup.ProtoReflect.Descriptor instead.
+func (*MemoryLookup) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *MemoryLookup) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *MemoryLookup) GetMemoryId() string {
+	if x != nil {
+		return x.MemoryId
+	}
+	return ""
+}
+
+func (x *MemoryLookup) GetIngestedBy() []*MemoryLookupEntry {
+	if x != nil {
+		return x.IngestedBy
+	}
+	return nil
+}
+
+func (x *MemoryLookup) GetContextIds() []string {
+	if x != nil {
+		return x.ContextIds
+	}
+	return nil
+}
+
+type MemoryLookupEntry struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ConnectorId string                 `protobuf:"bytes,1,opt,name=connector_id,json=connectorId,proto3" json:"connector_id,omitempty"`
+	ContextId   string                 `protobuf:"bytes,2,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	// The memory in the source system's app, if the connector has a deep_link template
+	DeepLink      string `protobuf:"bytes,3,opt,name=deep_link,json=deepLink,proto3" json:"deep_link,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemoryLookupEntry) Reset() {
+	*x = MemoryLookupEntry{}
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemoryLookupEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemoryLookupEntry) ProtoMessage() {}
+
+func (x *MemoryLookupEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_memoryconnector_v1_memory_connector_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemoryLookupEntry.ProtoReflect.Descriptor instead.
+func (*MemoryLookupEntry) Descriptor() ([]byte, []int) {
+	return file_memoryconnector_v1_memory_connector_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *MemoryLookupEntry) GetConnectorId() string {
+	if x != nil {
+		return x.ConnectorId
+	}
+	return ""
+}
+
+func (x *MemoryLookupEntry) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *MemoryLookupEntry) GetDeepLink() string {
+	if x != nil {
+		return x.DeepLink
+	}
+	return ""
+}
+
+var File_memoryconnector_v1_memory_connector_proto protoreflect.FileDescriptor
+
+const file_memoryconnector_v1_memory_connector_proto_rawDesc = "" +
+	"\n" +
+	")memoryconnector/v1/memory_connector.proto\x12\x12memoryconnector.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x0f\n" +
+	"\rHealthRequest\"(\n" +
+	"\x0eHealthResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\x17\n" +
+	"\x15ListConnectorsRequest\"W\n" +
+	"\x16ListConnectorsResponse\x12=\n" +
+	"\n" +
+	"connectors\x18\x01 \x03(\v2\x1d.memoryconnector.v1.ConnectorR\n" +
+	"connectors\"%\n" +
+	"\x13GetConnectorRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"+\n" +
+	"\x19GetConnectorStatusRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\",\n" +
+	"\x1aGetConnectorHistoryRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"$\n" +
+	"\x12TriggerSyncRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"g\n" +
+	"\x13LookupMemoryRequest\x12\x10\n" +
+	"\x03uri\x18\x01 \x01(\tR\x03uri\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x02 \x01(\tR\tcontextId\x12\x1f\n" +
+	"\vcontext_ids\x18\x03 \x03(\tR\n" +
+	"contextIds\"\x98\x03\n" +
+	"\tConnector\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x03 \x01(\tR\tcontextId\x12#\n" +
+	"\rschedule_type\x18\x04 \x01(\tR\fscheduleType\x121\n" +
+	"\x14schedule_description\x18\x05 \x01(\tR\x13scheduleDescription\x125\n" +
+	"\bnext_run\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\anextRun\x12-\n" +
+	"\x12transform_strategy\x18\a \x01(\tR\x11transformStrategy\x12G\n" +
+	"\bmetadata\x18\b \x03(\v2+.memoryconnector.v1.Connector.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe4\x02\n" +
+	"\x0fConnectorStatus\x12!\n" +
+	"\fconnector_id\x18\x01 \x01(\tR\vconnectorId\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12@\n" +
+	"\x0elast_sync_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\flastSyncTime\x12@\n" +
+	"\x0enext_sync_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\fnextSyncTime\x12H\n" +
+	"\x10last_sync_report\x18\x05 \x01(\v2\x1e.memoryconnector.v1.SyncReportR\x0elastSyncReport\x12#\n" +
+	"\rerror_message\x18\x06 \x01(\tR\ferrorMessage\x12%\n" +
+	"\x0equeue_position\x18\a \x01(\x05R\rqueuePosition\"\xf1\x03\n" +
+	"\n" +
+	"SyncReport\x12!\n" +
+	"\fconnector_id\x18\x01 \x01(\tR\vconnectorId\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x02 \x01(\tR\tcontextId\x129\n" +
+	"\n" +
+	"start_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x125\n" +
+	"\bduration\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12#\n" +
+	"\rtotal_fetched\x18\a \x01(\x05R\ftotalFetched\x12'\n" +
+	"\x0ftotal_processed\x18\b \x01(\x05R\x0etotalProcessed\x12#\n" +
+	"\rtotal_skipped\x18\t \x01(\x05R\ftotalSkipped\x12!\n" +
+	"\ftotal_failed\x18\n" +
+	" \x01(\x05R\vtotalFailed\x12%\n" +
+	"\x0etotal_deferred\x18\v \x01(\x05R\rtotalDeferred\x12#\n" +
+	"\rerror_message\x18\f \x01(\tR\ferrorMessage\"G\n" +
+	"\vSyncHistory\x128\n" +
+	"\areports\x18\x01 \x03(\v2\x1e.memoryconnector.v1.SyncReportR\areports\"\x98\x02\n" +
+	"\fSyncProgress\x12!\n" +
+	"\fconnector_id\x18\x01 \x01(\tR\vconnectorId\x12#\n" +
+	"\rtotal_fetched\x18\x02 \x01(\x05R\ftotalFetched\x12\x1b\n" +
+	"\ttotal_new\x18\x03 \x01(\x05R\btotalNew\x12\x1c\n" +
+	"\tprocessed\x18\x04 \x01(\x05R\tprocessed\x12\x18\n" +
+	"\askipped\x18\x05 \x01(\x05R\askipped\x12\x16\n" +
+	"\x06failed\x18\x06 \x01(\x05R\x06failed\x12\x1b\n" +
+	"\tmemory_id\x18\a \x01(\tR\bmemoryId\x126\n" +
+	"\x06report\x18\b \x01(\v2\x1e.memoryconnector.v1.SyncReportR\x06report\"\xa6\x01\n" +
+	"\fMemoryLookup\x12\x10\n" +
+	"\x03uri\x18\x01 \x01(\tR\x03uri\x12\x1b\n" +
+	"\tmemory_id\x18\x02 \x01(\tR\bmemoryId\x12F\n" +
+	"\vingested_by\x18\x03 \x03(\v2%.memoryconnector.v1.MemoryLookupEntryR\n" +
+	"ingestedBy\x12\x1f\n" +
+	"\vcontext_ids\x18\x04 \x03(\tR\n" +
+	"contextIds\"r\n" +
+	"\x11MemoryLookupEntry\x12!\n" +
+	"\fconnector_id\x18\x01 \x01(\tR\vconnectorId\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x02 \x01(\tR\tcontextId\x12\x1b\n" +
+	"\tdeep_link\x18\x03 \x01(\tR\bdeepLink2\xab\x05\n" +
+	"\x0fMemoryConnector\x12O\n" +
+	"\x06Health\x12!.memoryconnector.v1.HealthRequest\x1a\".memoryconnector.v1.HealthResponse\x12g\n" +
+	"\x0eListConnectors\x12).memoryconnector.v1.ListConnectorsRequest\x1a*.memoryconnector.v1.ListConnectorsResponse\x12V\n" +
+	"\fGetConnector\x12'.memoryconnector.v1.GetConnectorRequest\x1a\x1d.memoryconnector.v1.Connector\x12h\n" +
+	"\x12GetConnectorStatus\x12-.memoryconnector.v1.GetConnectorStatusRequest\x1a#.memoryconnector.v1.ConnectorStatus\x12f\n" +
+	"\x13GetConnectorHistory\x12..memoryconnector.v1.GetConnectorHistoryRequest\x1a\x1f.memoryconnector.v1.SyncHistory\x12Y\n" +
+	"\vTriggerSync\x12&.memoryconnector.v1.TriggerSyncRequest\x1a .memoryconnector.v1.SyncProgress0\x01\x12Y\n" +
+	"\fLookupMemory\x12'.memoryconnector.v1.LookupMemoryRequest\x1a .memoryconnector.v1.MemoryLookupB1Z/github.com/kamir/memory-connector/pkg/api/pb;pbb\x06proto3"
+
+var (
+	file_memoryconnector_v1_memory_connector_proto_rawDescOnce sync.Once
+	file_memoryconnector_v1_memory_connector_proto_rawDescData []byte
+)
+
+func file_memoryconnector_v1_memory_connector_proto_rawDescGZIP() []byte {
+	file_memoryconnector_v1_memory_connector_proto_rawDescOnce.Do(func() {
+		file_memoryconnector_v1_memory_connector_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_memoryconnector_v1_memory_connector_proto_rawDesc), len(file_memoryconnector_v1_memory_connector_proto_rawDesc)))
+	})
+	return file_memoryconnector_v1_memory_connector_proto_rawDescData
+}
+
+var file_memoryconnector_v1_memory_connector_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_memoryconnector_v1_memory_connector_proto_goTypes = []any{
+	(*HealthRequest)(nil),              // 0: memoryconnector.v1.HealthRequest
+	(*HealthResponse)(nil),             // 1: memoryconnector.v1.HealthResponse
+	(*ListConnectorsRequest)(nil),      // 2: memoryconnector.v1.ListConnectorsRequest
+	(*ListConnectorsResponse)(nil),     // 3: memoryconnector.v1.ListConnectorsResponse
+	(*GetConnectorRequest)(nil),        // 4: memoryconnector.v1.GetConnectorRequest
+	(*GetConnectorStatusRequest)(nil),  // 5: memoryconnector.v1.GetConnectorStatusRequest
+	(*GetConnectorHistoryRequest)(nil), // 6: memoryconnector.v1.GetConnectorHistoryRequest
+	(*TriggerSyncRequest)(nil),         // 7: memoryconnector.v1.TriggerSyncRequest
+	(*LookupMemoryRequest)(nil),        // 8: memoryconnector.v1.LookupMemoryRequest
+	(*Connector)(nil),                  // 9: memoryconnector.v1.Connector
+	(*ConnectorStatus)(nil),            // 10: memoryconnector.v1.ConnectorStatus
+	(*SyncReport)(nil),                 // 11: memoryconnector.v1.SyncReport
+	(*SyncHistory)(nil),                // 12: memoryconnector.v1.SyncHistory
+	(*SyncProgress)(nil),               // 13: memoryconnector.v1.SyncProgress
+	(*MemoryLookup)(nil),               // 14: memoryconnector.v1.MemoryLookup
+	(*MemoryLookupEntry)(nil),          // 15: memoryconnector.v1.MemoryLookupEntry
+	nil,                                // 16: memoryconnector.v1.Connector.MetadataEntry
+	(*timestamppb.Timestamp)(nil),      // 17: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),        // 18: google.protobuf.Duration
+}
+var file_memoryconnector_v1_memory_connector_proto_depIdxs = []int32{
+	9,  // 0: memoryconnector.v1.ListConnectorsResponse.connectors:type_name -> memoryconnector.v1.Connector
+	17, // 1: memoryconnector.v1.Connector.next_run:type_name -> google.protobuf.Timestamp
+	16, // 2: memoryconnector.v1.Connector.metadata:type_name -> memoryconnector.v1.Connector.MetadataEntry
+	17, // 3: memoryconnector.v1.ConnectorStatus.last_sync_time:type_name -> google.protobuf.Timestamp
+	17, // 4: memoryconnector.v1.ConnectorStatus.next_sync_time:type_name -> google.protobuf.Timestamp
+	11, // 5: memoryconnector.v1.ConnectorStatus.last_sync_report:type_name -> memoryconnector.v1.SyncReport
+	17, // 6: memoryconnector.v1.SyncReport.start_time:type_name -> google.protobuf.Timestamp
+	17, // 7: memoryconnector.v1.SyncReport.end_time:type_name -> google.protobuf.Timestamp
+	18, // 8: memoryconnector.v1.SyncReport.duration:type_name -> google.protobuf.Duration
+	11, // 9: memoryconnector.v1.SyncHistory.reports:type_name -> memoryconnector.v1.SyncReport
+	11, // 10: memoryconnector.v1.SyncProgress.report:type_name -> memoryconnector.v1.SyncReport
+	15, // 11: memoryconnector.v1.MemoryLookup.ingested_by:type_name -> memoryconnector.v1.MemoryLookupEntry
+	0,  // 12: memoryconnector.v1.MemoryConnector.Health:input_type -> memoryconnector.v1.HealthRequest
+	2,  // 13: memoryconnector.v1.MemoryConnector.ListConnectors:input_type -> memoryconnector.v1.ListConnectorsRequest
+	4,  // 14: memoryconnector.v1.MemoryConnector.GetConnector:input_type -> memoryconnector.v1.GetConnectorRequest
+	5,  // 15: memoryconnector.v1.MemoryConnector.GetConnectorStatus:input_type -> memoryconnector.v1.GetConnectorStatusRequest
+	6,  // 16: memoryconnector.v1.MemoryConnector.GetConnectorHistory:input_type -> memoryconnector.v1.GetConnectorHistoryRequest
+	7,  // 17: memoryconnector.v1.MemoryConnector.TriggerSync:input_type -> memoryconnector.v1.TriggerSyncRequest
+	8,  // 18: memoryconnector.v1.MemoryConnector.LookupMemory:input_type -> memoryconnector.v1.LookupMemoryRequest
+	1,  // 19: memoryconnector.v1.MemoryConnector.Health:output_type -> memoryconnector.v1.HealthResponse
+	3,  // 20: memoryconnector.v1.MemoryConnector.ListConnectors:output_type -> memoryconnector.v1.ListConnectorsResponse
+	9,  // 21: memoryconnector.v1.MemoryConnector.GetConnector:output_type -> memoryconnector.v1.Connector
+	10, // 22: memoryconnector.v1.MemoryConnector.GetConnectorStatus:output_type -> memoryconnector.v1.ConnectorStatus
+	12, // 23: memoryconnector.v1.MemoryConnector.GetConnectorHistory:output_type -> memoryconnector.v1.SyncHistory
+	13, // 24: memoryconnector.v1.MemoryConnector.TriggerSync:output_type -> memoryconnector.v1.SyncProgress
+	14, // 25: memoryconnector.v1.MemoryConnector.LookupMemory:output_type -> memoryconnector.v1.MemoryLookup
+	19, // [19:26] is the sub-list for method output_type
+	12, // [12:19] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_memoryconnector_v1_memory_connector_proto_init() }
+func file_memoryconnector_v1_memory_connector_proto_init() {
+	if File_memoryconnector_v1_memory_connector_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_memoryconnector_v1_memory_connector_proto_rawDesc), len(file_memoryconnector_v1_memory_connector_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_memoryconnector_v1_memory_connector_proto_goTypes,
+		DependencyIndexes: file_memoryconnector_v1_memory_connector_proto_depIdxs,
+		MessageInfos:      file_memoryconnector_v1_memory_connector_proto_msgTypes,
+	}.Build()
+	File_memoryconnector_v1_memory_connector_proto = out.File
+	file_memoryconnector_v1_memory_connector_proto_goTypes = nil
+	file_memoryconnector_v1_memory_connector_proto_depIdxs = nil
+}
@@ -0,0 +1,353 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: memoryconnector/v1/memory_connector.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MemoryConnector_Health_FullMethodName              = "/memoryconnector.v1.MemoryConnector/Health"
+	MemoryConnector_ListConnectors_FullMethodName      = "/memoryconnector.v1.MemoryConnector/ListConnectors"
+	MemoryConnector_GetConnector_FullMethodName        = "/memoryconnector.v1.MemoryConnector/GetConnector"
+	MemoryConnector_GetConnectorStatus_FullMethodName  = "/memoryconnector.v1.MemoryConnector/GetConnectorStatus"
+	MemoryConnector_GetConnectorHistory_FullMethodName = "/memoryconnector.v1.MemoryConnector/GetConnectorHistory"
+	MemoryConnector_TriggerSync_FullMethodName         = "/memoryconnector.v1.MemoryConnector/TriggerSync"
+	MemoryConnector_LookupMemory_FullMethodName        = "/memoryconnector.v1.MemoryConnector/LookupMemory"
+)
+
+// MemoryConnectorClient is the client API for MemoryConnector service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MemoryConnectorClient interface {
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+	ListConnectors(ctx context.Context, in *ListConnectorsRequest, opts ...grpc.CallOption) (*ListConnectorsResponse, error)
+	GetConnector(ctx context.Context, in *GetConnectorRequest, opts ...grpc.CallOption) (*Connector, error)
+	GetConnectorStatus(ctx context.Context, in *GetConnectorStatusRequest, opts ...grpc.CallOption) (*ConnectorStatus, error)
+	GetConnectorHistory(ctx context.Context, in *GetConnectorHistoryRequest, opts ...grpc.CallOption) (*SyncHistory, error)
+	TriggerSync(ctx context.Context, in *TriggerSyncRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SyncProgress], error)
+	LookupMemory(ctx context.Context, in *LookupMemoryRequest, opts ...grpc.CallOption) (*MemoryLookup, error)
+}
+
+type memoryConnectorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMemoryConnectorClient(cc grpc.ClientConnInterface) MemoryConnectorClient {
+	return &memoryConnectorClient{cc}
+}
+
+func (c *memoryConnectorClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, MemoryConnector_Health_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memoryConnectorClient) ListConnectors(ctx context.Context, in *ListConnectorsRequest, opts ...grpc.CallOption) (*ListConnectorsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListConnectorsResponse)
+	err := c.cc.Invoke(ctx, MemoryConnector_ListConnectors_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memoryConnectorClient) GetConnector(ctx context.Context, in *GetConnectorRequest, opts ...grpc.CallOption) (*Connector, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Connector)
+	err := c.cc.Invoke(ctx, MemoryConnector_GetConnector_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memoryConnectorClient) GetConnectorStatus(ctx context.Context, in *GetConnectorStatusRequest, opts ...grpc.CallOption) (*ConnectorStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConnectorStatus)
+	err := c.cc.Invoke(ctx, MemoryConnector_GetConnectorStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memoryConnectorClient) GetConnectorHistory(ctx context.Context, in *GetConnectorHistoryRequest, opts ...grpc.CallOption) (*SyncHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncHistory)
+	err := c.cc.Invoke(ctx, MemoryConnector_GetConnectorHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memoryConnectorClient) TriggerSync(ctx context.Context, in *TriggerSyncRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SyncProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MemoryConnector_ServiceDesc.Streams[0], MemoryConnector_TriggerSync_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TriggerSyncRequest, SyncProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MemoryConnector_TriggerSyncClient = grpc.ServerStreamingClient[SyncProgress]
+
+func (c *memoryConnectorClient) LookupMemory(ctx context.Context, in *LookupMemoryRequest, opts ...grpc.CallOption) (*MemoryLookup, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MemoryLookup)
+	err := c.cc.Invoke(ctx, MemoryConnector_LookupMemory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MemoryConnectorServer is the server API for MemoryConnector service.
+// All implementations must embed UnimplementedMemoryConnectorServer
+// for forward compatibility.
+type MemoryConnectorServer interface {
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	ListConnectors(context.Context, *ListConnectorsRequest) (*ListConnectorsResponse, error)
+	GetConnector(context.Context, *GetConnectorRequest) (*Connector, error)
+	GetConnectorStatus(context.Context, *GetConnectorStatusRequest) (*ConnectorStatus, error)
+	GetConnectorHistory(context.Context, *GetConnectorHistoryRequest) (*SyncHistory, error)
+	TriggerSync(*TriggerSyncRequest, grpc.ServerStreamingServer[SyncProgress]) error
+	LookupMemory(context.Context, *LookupMemoryRequest) (*MemoryLookup, error)
+	mustEmbedUnimplementedMemoryConnectorServer()
+}
+
+// UnimplementedMemoryConnectorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMemoryConnectorServer struct{}
+
+func (UnimplementedMemoryConnectorServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedMemoryConnectorServer) ListConnectors(context.Context, *ListConnectorsRequest) (*ListConnectorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConnectors not implemented")
+}
+func (UnimplementedMemoryConnectorServer) GetConnector(context.Context, *GetConnectorRequest) (*Connector, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConnector not implemented")
+}
+func (UnimplementedMemoryConnectorServer) GetConnectorStatus(context.Context, *GetConnectorStatusRequest) (*ConnectorStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConnectorStatus not implemented")
+}
+func (UnimplementedMemoryConnectorServer) GetConnectorHistory(context.Context, *GetConnectorHistoryRequest) (*SyncHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConnectorHistory not implemented")
+}
+func (UnimplementedMemoryConnectorServer) TriggerSync(*TriggerSyncRequest, grpc.ServerStreamingServer[SyncProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method TriggerSync not implemented")
+}
+func (UnimplementedMemoryConnectorServer) LookupMemory(context.Context, *LookupMemoryRequest) (*MemoryLookup, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupMemory not implemented")
+}
+func (UnimplementedMemoryConnectorServer) mustEmbedUnimplementedMemoryConnectorServer() {}
+func (UnimplementedMemoryConnectorServer) testEmbeddedByValue()                         {}
+
+// UnsafeMemoryConnectorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MemoryConnectorServer will
+// result in compilation errors.
+type UnsafeMemoryConnectorServer interface {
+	mustEmbedUnimplementedMemoryConnectorServer()
+}
+
+func RegisterMemoryConnectorServer(s grpc.ServiceRegistrar, srv MemoryConnectorServer) {
+	// If the following call pancis, it indicates UnimplementedMemoryConnectorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MemoryConnector_ServiceDesc, srv)
+}
+
+func _MemoryConnector_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemoryConnectorServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemoryConnector_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemoryConnectorServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MemoryConnector_ListConnectors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConnectorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemoryConnectorServer).ListConnectors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemoryConnector_ListConnectors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemoryConnectorServer).ListConnectors(ctx, req.(*ListConnectorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MemoryConnector_GetConnector_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConnectorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemoryConnectorServer).GetConnector(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemoryConnector_GetConnector_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemoryConnectorServer).GetConnector(ctx, req.(*GetConnectorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MemoryConnector_GetConnectorStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConnectorStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemoryConnectorServer).GetConnectorStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemoryConnector_GetConnectorStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemoryConnectorServer).GetConnectorStatus(ctx, req.(*GetConnectorStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MemoryConnector_GetConnectorHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConnectorHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemoryConnectorServer).GetConnectorHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemoryConnector_GetConnectorHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemoryConnectorServer).GetConnectorHistory(ctx, req.(*GetConnectorHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MemoryConnector_TriggerSync_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TriggerSyncRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MemoryConnectorServer).TriggerSync(m, &grpc.GenericServerStream[TriggerSyncRequest, SyncProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MemoryConnector_TriggerSyncServer = grpc.ServerStreamingServer[SyncProgress]
+
+func _MemoryConnector_LookupMemory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemoryConnectorServer).LookupMemory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MemoryConnector_LookupMemory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemoryConnectorServer).LookupMemory(ctx, req.(*LookupMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MemoryConnector_ServiceDesc is the grpc.ServiceDesc for MemoryConnector service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MemoryConnector_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "memoryconnector.v1.MemoryConnector",
+	HandlerType: (*MemoryConnectorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Health",
+			Handler:    _MemoryConnector_Health_Handler,
+		},
+		{
+			MethodName: "ListConnectors",
+			Handler:    _MemoryConnector_ListConnectors_Handler,
+		},
+		{
+			MethodName: "GetConnector",
+			Handler:    _MemoryConnector_GetConnector_Handler,
+		},
+		{
+			MethodName: "GetConnectorStatus",
+			Handler:    _MemoryConnector_GetConnectorStatus_Handler,
+		},
+		{
+			MethodName: "GetConnectorHistory",
+			Handler:    _MemoryConnector_GetConnectorHistory_Handler,
+		},
+		{
+			MethodName: "LookupMemory",
+			Handler:    _MemoryConnector_LookupMemory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TriggerSync",
+			Handler:       _MemoryConnector_TriggerSync_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "memoryconnector/v1/memory_connector.proto",
+}
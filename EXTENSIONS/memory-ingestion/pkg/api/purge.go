@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/audit"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/orchestrator"
+	"github.com/kamir/memory-connector/pkg/scheduler"
+	"go.uber.org/zap"
+)
+
+// Purge confirmation limits
+const (
+	purgeTokenTTL       = 5 * time.Minute
+	purgeMaxBodyBytes   = 4 << 10
+	purgeMaxPendingSize = 100 // pending purges kept at once, the oldest is dropped beyond
+)
+
+// PurgeRequest requests the purge of a context
+type PurgeRequest struct {
+	ContextID string `json:"context_id"`
+}
+
+// PurgeConfirmation is the plan of a requested purge, executed by POST /api/v1/purges/{confirmation_token}
+type PurgeConfirmation struct {
+	models.PurgePlan
+	ConfirmationToken string    `json:"confirmation_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// pendingPurge is a requested purge awaiting confirmation by the client that requested it
+type pendingPurge struct {
+	plan      *models.PurgePlan
+	principal string // the requesting client's name, empty if authorization is disabled
+	expiresAt time.Time
+}
+
+// handlePurges requests the purge of a context (POST /api/v1/purges), returning what it removes
+// and a confirmation token, and executes it (POST /api/v1/purges/{confirmation_token}). Tokens
+// expire after five minutes, are used once, and only by the client that requested the purge.
+func (s *Server) handlePurges(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	token := strings.TrimPrefix(r.URL.Path, "/api/v1/purges")
+	if token == "" || token == "/" {
+		s.requestPurge(w, r)
+		return
+	}
+	s.executePurge(w, r, strings.TrimPrefix(token, "/"))
+}
+
+// requestPurge plans the purge of a context and hands out its confirmation token
+func (s *Server) requestPurge(w http.ResponseWriter, r *http.Request) {
+	var req PurgeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, purgeMaxBodyBytes)).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid purge request: %v", err))
+		return
+	}
+	if req.ContextID == "" {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "context_id is required")
+		return
+	}
+	if err := authorizeContexts(r.Context(), req.ContextID); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+
+	connectors := s.configs().Connectors
+	if !hasContext(connectors, req.ContextID) {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound,
+			fmt.Sprintf("no connector ingests context %q", req.ContextID))
+		return
+	}
+
+	plan, err := s.scheduler.PlanPurge(r.Context(), req.ContextID, connectors)
+	if err != nil {
+		s.writePurgeError(w, r, err)
+		return
+	}
+
+	confirmation := PurgeConfirmation{
+		PurgePlan:         *plan,
+		ConfirmationToken: newCorrelationID(),
+		ExpiresAt:         time.Now().Add(purgeTokenTTL).UTC(),
+	}
+	s.purgeMu.Lock()
+	s.prunePurges(time.Now())
+	s.purges[confirmation.ConfirmationToken] = pendingPurge{
+		plan:      plan,
+		principal: principalName(r.Context()),
+		expiresAt: confirmation.ExpiresAt,
+	}
+	s.purgeMu.Unlock()
+
+	s.logger.Info("Purge requested",
+		zap.String("context_id", req.ContextID),
+		zap.Strings("connectors", plan.ConnectorIDs),
+		zap.Int("documents", len(plan.Documents)),
+		zap.String("correlation_id", CorrelationID(r.Context())),
+	)
+	writeJSON(w, http.StatusOK, confirmation)
+}
+
+// executePurge executes the planned purge of a confirmation token, which is used up even if the
+// purge fails. Tokens of other clients are unknown.
+func (s *Server) executePurge(w http.ResponseWriter, r *http.Request, token string) {
+	s.purgeMu.Lock()
+	s.prunePurges(time.Now())
+	purge, ok := s.purges[token]
+	if ok && purge.principal == principalName(r.Context()) {
+		delete(s.purges, token)
+	} else {
+		ok = false
+	}
+	s.purgeMu.Unlock()
+	if !ok {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound,
+			"unknown or expired confirmation token, request the purge again")
+		return
+	}
+
+	result, err := s.scheduler.PurgeContext(purge.plan, s.configs().Connectors)
+	entry := audit.Entry{Action: audit.ActionPurge, Target: purge.plan.ContextID}
+	if err == nil {
+		entry.After = result
+	}
+	s.recordAudit(r.Context(), entry, err)
+	if err != nil {
+		s.writePurgeError(w, r, err)
+		return
+	}
+
+	s.logger.Warn("Purge executed",
+		zap.String("context_id", purge.plan.ContextID),
+		zap.Int("deleted_documents", result.DeletedDocuments),
+		zap.String("correlation_id", CorrelationID(r.Context())),
+	)
+	writeJSON(w, http.StatusOK, result)
+}
+
+// writePurgeError writes the problem of a failed purge: 409 while a sync runs or if the plan is
+// outdated, 503 if LightRAG failed
+func (s *Server) writePurgeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, scheduler.ErrSyncRunning):
+		writeProblem(w, r, http.StatusConflict, CodeConflict,
+			fmt.Sprintf("%v, request the purge again when it's done", err))
+	case errors.Is(err, orchestrator.ErrPurgePlanChanged):
+		writeProblem(w, r, http.StatusConflict, CodeConflict,
+			fmt.Sprintf("%v, request the purge again to review the new plan", err))
+	case errors.Is(err, orchestrator.ErrUpstreamFailed):
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, err.Error())
+	default:
+		s.writeInternalError(w, r, err)
+	}
+}
+
+// prunePurges drops expired purge requests, and the oldest beyond purgeMaxPendingSize. Called with
+// s.purgeMu held.
+func (s *Server) prunePurges(now time.Time) {
+	oldest := ""
+	for token, purge := range s.purges {
+		if now.After(purge.expiresAt) {
+			delete(s.purges, token)
+		} else if oldest == "" || purge.expiresAt.Before(s.purges[oldest].expiresAt) {
+			oldest = token
+		}
+	}
+	if len(s.purges) >= purgeMaxPendingSize {
+		delete(s.purges, oldest)
+	}
+}
+
+// hasContext returns true if one of the connectors ingests the context
+func hasContext(connectors []models.ConnectorConfig, contextID string) bool {
+	for _, connector := range connectors {
+		if connector.ContextID == contextID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,349 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/trips"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// Query limits
+const (
+	queryMaxBodyBytes = 64 << 10
+	queryMaxTopK      = 200
+	querySnippetRunes = 200 // of a source memory's transcript
+)
+
+// queryModes are the retrieval modes of LightRAG a query may use. bypass isn't one of them: it
+// answers without the knowledge graph, so there are no memories to cite.
+var queryModes = map[string]bool{"local": true, "global": true, "hybrid": true, "naive": true, "mix": true}
+
+// QueryRequest asks LightRAG a question on behalf of a connector
+type QueryRequest struct {
+	Question    string `json:"question"`
+	ConnectorID string `json:"connector_id"`   // the answer's citations are scoped to this connector's context
+	Mode        string `json:"mode,omitempty"` // LightRAG's retrieval mode, its default (mix) if empty
+	TopK        int    `json:"top_k,omitempty"`
+	MaxMemories int    `json:"max_memories,omitempty"` // latest memories searched for the cited ones, 1000 if 0
+}
+
+// QueryAnswer is LightRAG's answer with its sources, scoped to the context of the requesting
+// connector
+type QueryAnswer struct {
+	Answer    string `json:"answer"`
+	ContextID string `json:"context_id"`
+
+	// Entities are the entities of the knowledge graph the answer names that were extracted from its
+	// cited memories
+	Entities []CitedEntity `json:"entities"`
+	// Memories are the cited memories of the context, in the order they were cited, with what the
+	// Memory API serves of them. Only the context's connectors are listed as having ingested them.
+	Memories []SourceMemory `json:"memories"`
+	// OutOfContext counts the cited memories that only connectors of other contexts ingested. They
+	// are withheld, but the answer may draw on them.
+	OutOfContext int `json:"out_of_context"`
+	// Unresolved are the cited documents that aren't memories a connector ingested, e.g. documents
+	// inserted into LightRAG by other means
+	Unresolved []string `json:"unresolved"`
+}
+
+// CitedEntity is an entity of the knowledge graph an answer names
+type CitedEntity struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type,omitempty"`
+	MemoryIDs []string `json:"memory_ids"` // the cited memories it was extracted from
+}
+
+// SourceMemory is a memory an answer cites, hydrated from the Memory API
+type SourceMemory struct {
+	MemoryLookup
+	Date     string `json:"date,omitempty"`      // when the memory was recorded
+	Place    string `json:"place,omitempty"`     // where it was recorded, geocoded if geocoding is configured
+	Snippet  string `json:"snippet,omitempty"`   // the start of its transcript
+	DeepLink string `json:"deep_link,omitempty"` // the memory in the source system's app
+	Missing  bool   `json:"missing,omitempty"`   // the Memory API doesn't serve it (anymore, or beyond max_memories)
+}
+
+// PlaceNamer names the places memories were recorded at
+type PlaceNamer interface {
+	// PlaceName names the place at coordinates, falling back to the coordinates
+	PlaceName(ctx context.Context, lat, lon float64) string
+}
+
+// handleQuery answers a question from the knowledge graph for a connector (POST /api/v1/query) with
+// its sources: the memories the answer cites, hydrated from the Memory API, and the entities it
+// names. LightRAG retrieves from all documents it holds, so the citations are filtered afterwards:
+// memories of the connector's context are returned, those of other contexts are only counted, so
+// a client can tell that the answer reaches beyond its memories.
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req QueryRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, queryMaxBodyBytes)).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid query: %v", err))
+		return
+	}
+	switch {
+	case strings.TrimSpace(req.Question) == "":
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "question is required")
+		return
+	case req.ConnectorID == "":
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "connector_id is required")
+		return
+	case req.Mode != "" && !queryModes[req.Mode]:
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest,
+			fmt.Sprintf("mode must be 'local', 'global', 'hybrid', 'naive', or 'mix', got '%s'", req.Mode))
+		return
+	case req.TopK < 0 || req.TopK > queryMaxTopK:
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("top_k must be between 1 and %d", queryMaxTopK))
+		return
+	case req.MaxMemories < 0 || req.MaxMemories > maxMemoryScanLimit:
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest,
+			fmt.Sprintf("max_memories must be between 1 and %d", maxMemoryScanLimit))
+		return
+	}
+	if req.MaxMemories == 0 {
+		req.MaxMemories = defaultMemoryScanLimit
+	}
+
+	connector, err := s.configs().GetConnectorByID(req.ConnectorID)
+	if err != nil {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound, fmt.Sprintf("connector %q not found", req.ConnectorID))
+		return
+	}
+	if err := authorizeContexts(r.Context(), connector.ContextID); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+
+	// The entities are retrieved again next to the answer, which doesn't carry them
+	query := client.QueryRequest{Query: req.Question, Mode: req.Mode, TopK: req.TopK, IncludeReferences: true}
+	var response *client.QueryResponse
+	var data *client.QueryData
+	group, ctx := errgroup.WithContext(r.Context())
+	group.Go(func() error {
+		var err error
+		response, err = s.lightragClient.Query(ctx, query)
+		return err
+	})
+	group.Go(func() error {
+		var err error
+		if data, err = s.lightragClient.QueryData(ctx, query); err != nil {
+			// Releases before 1.4.9 don't serve /query/data: answer without entities
+			s.logger.Debug("Failed to retrieve the entities of a query", zap.Error(err))
+			data = &client.QueryData{}
+		}
+		return nil
+	})
+	if err := group.Wait(); err != nil {
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, err.Error())
+		return
+	}
+
+	answer := QueryAnswer{
+		Answer:     response.Response,
+		ContextID:  connector.ContextID,
+		Unresolved: []string{},
+	}
+
+	// Memory URIs and short citations are looked up, other file paths can't be memories
+	var references []string
+	for _, reference := range citedReferences(response) {
+		if isMemoryReference(reference) {
+			references = append(references, reference)
+		} else {
+			answer.Unresolved = append(answer.Unresolved, reference)
+		}
+	}
+
+	lookups, err := s.lookupReferences(r.Context(), references, nil)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+	var memories []MemoryLookup
+	resolved := make(map[string]bool, len(lookups))
+	for i, lookup := range lookups {
+		if lookup == nil {
+			answer.Unresolved = append(answer.Unresolved, references[i])
+			continue
+		}
+		if resolved[lookup.MemoryID] {
+			continue
+		}
+		resolved[lookup.MemoryID] = true
+
+		scoped := *lookup
+		scoped.IngestedBy = []MemoryLookupEntry{}
+		for _, entry := range lookup.IngestedBy {
+			if entry.ContextID == connector.ContextID {
+				scoped.IngestedBy = append(scoped.IngestedBy, entry)
+			}
+		}
+		if len(scoped.IngestedBy) == 0 {
+			answer.OutOfContext++
+			continue
+		}
+		scoped.ContextIDs = []string{connector.ContextID}
+		memories = append(memories, scoped)
+	}
+
+	answer.Memories = s.hydrateSources(r.Context(), memories, req.MaxMemories)
+	answer.Entities = citedEntities(data.Entities, response.Response, memories)
+
+	writeJSON(w, http.StatusOK, answer)
+}
+
+// hydrateSources adds the date, place, and transcript snippet the Memory API serves to the cited
+// memories, fetched once per connector that ingested them. Memories of a connector whose Memory API
+// fails are returned without them.
+func (s *Server) hydrateSources(ctx context.Context, memories []MemoryLookup, limit int) []SourceMemory {
+	sources := make([]SourceMemory, len(memories))
+	byConnector := make(map[string][]int) // connector ID -> indexes of the memories it hydrates
+	var connectorIDs []string
+	for i, memory := range memories {
+		sources[i].MemoryLookup = memory
+		for _, entry := range memory.IngestedBy {
+			if entry.DeepLink != "" {
+				sources[i].DeepLink = entry.DeepLink
+				break
+			}
+		}
+
+		connectorID := memory.IngestedBy[0].ConnectorID
+		if _, ok := byConnector[connectorID]; !ok {
+			connectorIDs = append(connectorIDs, connectorID)
+		}
+		byConnector[connectorID] = append(byConnector[connectorID], i)
+	}
+	if s.memories == nil {
+		return sources
+	}
+
+	for _, connectorID := range connectorIDs {
+		connector, err := s.configs().GetConnectorByID(connectorID)
+		if err != nil {
+			continue
+		}
+		indexes := byConnector[connectorID]
+		memoryIDs := make([]string, len(indexes))
+		for j, i := range indexes {
+			memoryIDs[j] = sources[i].MemoryID
+		}
+
+		fetched, err := s.memories.FetchMemories(ctx, connector, memoryIDs, limit)
+		if err != nil {
+			s.logger.Warn("Failed to hydrate the sources of a query",
+				zap.String("connector_id", connectorID),
+				zap.Error(err),
+			)
+			continue
+		}
+		for _, i := range indexes {
+			memory, ok := fetched[sources[i].MemoryID]
+			if !ok {
+				sources[i].Missing = true
+				continue
+			}
+			sources[i].Date = memory.CreatedAt
+			sources[i].Place = s.memoryPlace(ctx, memory)
+			sources[i].Snippet = snippet(memory.Transcript, querySnippetRunes)
+		}
+	}
+	return sources
+}
+
+// memoryPlace names the place a memory was recorded at, empty if it has no location
+func (s *Server) memoryPlace(ctx context.Context, memory *models.Memory) string {
+	if memory.LocationLat == nil || memory.LocationLon == nil {
+		return ""
+	}
+	if s.places == nil {
+		return trips.CoordinatesPlace(*memory.LocationLat, *memory.LocationLon)
+	}
+	return s.places.PlaceName(ctx, *memory.LocationLat, *memory.LocationLon)
+}
+
+// snippet returns the start of text, cut at a word boundary after at most maxRunes
+func snippet(text string, maxRunes int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	cut := string(runes[:maxRunes])
+	if space := strings.LastIndex(cut, " "); space > 0 {
+		cut = cut[:space]
+	}
+	return cut + "…"
+}
+
+// citedEntities returns the retrieved entities the answer names that were extracted from its cited
+// memories, with those memories. Entities drawn only from other documents are left out, since
+// they may stem from the memories of other contexts.
+func citedEntities(entities []client.QueryEntity, answer string, memories []MemoryLookup) []CitedEntity {
+	// Documents of the cited memories by their file path: memory URI or short citation
+	memoryIDs := make(map[string]string, 2*len(memories))
+	for _, memory := range memories {
+		memoryIDs[memory.URI] = memory.MemoryID
+		memoryIDs[utils.ShortCitation(memory.MemoryID)] = memory.MemoryID
+	}
+
+	cited := []CitedEntity{}
+	lowerAnswer := strings.ToLower(answer)
+	seen := make(map[string]bool)
+	for _, entity := range entities {
+		if entity.EntityName == "" || seen[entity.EntityName] || !strings.Contains(lowerAnswer, strings.ToLower(entity.EntityName)) {
+			continue
+		}
+
+		citedEntity := CitedEntity{Name: entity.EntityName, Type: entity.EntityType, MemoryIDs: []string{}}
+		extractedFrom := make(map[string]bool)
+		for _, filePath := range strings.Split(entity.FilePath, graphFieldSeparator) {
+			memoryID, ok := memoryIDs[strings.TrimSpace(filePath)]
+			if ok && !extractedFrom[memoryID] {
+				extractedFrom[memoryID] = true
+				citedEntity.MemoryIDs = append(citedEntity.MemoryIDs, memoryID)
+			}
+		}
+		if len(citedEntity.MemoryIDs) > 0 {
+			seen[entity.EntityName] = true
+			cited = append(cited, citedEntity)
+		}
+	}
+	return cited
+}
+
+// citedReferences returns the file paths of the documents an answer cites, each once: those of
+// its references, then memory URIs in its text (LightRAG releases that don't return references
+// list them at the end of the answer)
+func citedReferences(response *client.QueryResponse) []string {
+	var references []string
+	seen := make(map[string]bool)
+	add := func(reference string) {
+		if reference != "" && !seen[reference] {
+			seen[reference] = true
+			references = append(references, reference)
+		}
+	}
+
+	for _, reference := range response.References {
+		add(strings.TrimSpace(reference.FilePath))
+	}
+	for _, field := range strings.Fields(response.Response) {
+		if start := strings.Index(field, utils.MemoryURIPrefix); start >= 0 {
+			add(strings.TrimRight(field[start:], ".,;:!?)]}>\"'`*"))
+		}
+	}
+	return references
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+)
+
+// MemoryReceipts holds the receipts of a memory's latest ingestion by each connector that ingested it
+type MemoryReceipts struct {
+	URI      string                    `json:"uri"`
+	MemoryID string                    `json:"memory_id"`
+	Receipts []models.IngestionReceipt `json:"receipts"`
+}
+
+// handleLookupReceipt returns what each connector sent LightRAG for a memory: the document's ID and
+// request hash, LightRAG's answer, and when. Query parameters: uri (or a short citation),
+// context_id (repeatable or comma-separated, all contexts if missing).
+func (s *Server) handleLookupReceipt(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	uri := r.URL.Query().Get("uri")
+	if uri == "" {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "query parameter uri is required")
+		return
+	}
+
+	uri, memoryID, err := s.resolveMemoryReference(r.Context(), uri)
+	switch {
+	case errors.Is(err, utils.ErrInvalidMemoryURI):
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidURI, err.Error())
+		return
+	case errors.Is(err, errUnknownCitation):
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound, err.Error())
+		return
+	case err != nil:
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	contextIDs := queryList(r, "context_id")
+	if err := authorizeContexts(r.Context(), contextIDs...); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+	lookup, err := s.lookupMemory(r.Context(), uri, memoryID, contextIDs)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	receipts := MemoryReceipts{URI: uri, MemoryID: memoryID, Receipts: []models.IngestionReceipt{}}
+	for _, entry := range lookup.IngestedBy {
+		syncState, err := s.stateManager.GetState(r.Context(), entry.ConnectorID)
+		if err != nil {
+			s.writeInternalError(w, r, err)
+			return
+		}
+		// Memories blocked by a content policy, or ingested before receipts were recorded, have none
+		if receipt := syncState.Receipt(memoryID); receipt != nil {
+			receipt.ContextID = entry.ContextID
+			receipts.Receipts = append(receipts.Receipts, *receipt)
+		}
+	}
+	if len(receipts.Receipts) == 0 {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound,
+			fmt.Sprintf("no connector recorded a receipt for memory %q", memoryID))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, receipts)
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kamir/memory-connector/pkg/audit"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/orchestrator"
+	"github.com/kamir/memory-connector/pkg/scheduler"
+)
+
+// reingestMaxBodyBytes limits the size of re-ingestion requests
+const reingestMaxBodyBytes = 4 << 10
+
+// ReingestRequest re-ingests a memory of a connector
+type ReingestRequest struct {
+	MemoryID    string `json:"memory_id"`
+	MaxMemories int    `json:"max_memories,omitempty"` // latest memories searched for it, 1000 if 0
+}
+
+// handleReingest replaces the documents of a memory in LightRAG with its latest version from the
+// Memory API, transformed with the connector's current settings
+func (s *Server) handleReingest(w http.ResponseWriter, r *http.Request, connector *models.ConnectorConfig) {
+	var req ReingestRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, reingestMaxBodyBytes)).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid re-ingestion request: %v", err))
+		return
+	}
+	if req.MemoryID == "" {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "memory_id is required")
+		return
+	}
+	if req.MaxMemories == 0 {
+		req.MaxMemories = defaultMemoryScanLimit
+	}
+	if req.MaxMemories < 1 || req.MaxMemories > maxMemoryScanLimit {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest,
+			fmt.Sprintf("max_memories must be between 1 and %d", maxMemoryScanLimit))
+		return
+	}
+	if connector.Transform.Mode == models.TransformModeDailyDigest {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest,
+			fmt.Sprintf("connector %q ingests daily digests, which can't be re-ingested by memory", connector.ID))
+		return
+	}
+
+	result, err := s.scheduler.ReingestMemory(connector, req.MemoryID, req.MaxMemories)
+	entry := audit.Entry{Action: audit.ActionReingest, Target: connector.ID, Details: map[string]interface{}{"memory_id": req.MemoryID}}
+	if err == nil {
+		entry.After = result
+	}
+	s.recordAudit(r.Context(), entry, err)
+	switch {
+	case errors.Is(err, scheduler.ErrSyncRunning):
+		writeProblem(w, r, http.StatusConflict, CodeConflict,
+			fmt.Sprintf("a sync for connector %q is running, try again when it's done", connector.ID))
+	case errors.Is(err, scheduler.ErrPaused):
+		writeProblem(w, r, http.StatusConflict, CodeConnectorPaused,
+			fmt.Sprintf("connector %q is paused, resume it first", connector.ID))
+	case errors.Is(err, scheduler.ErrQuotaExhausted):
+		writeProblem(w, r, http.StatusTooManyRequests, CodeQuotaExhausted,
+			fmt.Sprintf("connector %q: %v", connector.ID, err))
+	case errors.Is(err, orchestrator.ErrMemoryNotFound):
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound, err.Error())
+	case errors.Is(err, orchestrator.ErrUpstreamFailed):
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, err.Error())
+	case err != nil:
+		s.writeInternalError(w, r, err)
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
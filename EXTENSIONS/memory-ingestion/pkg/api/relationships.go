@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kamir/memory-connector/pkg/client"
+)
+
+// Relationship lookup limits
+const (
+	defaultRelationshipScanNodes = 1000
+	maxRelationshipScanNodes     = 10000
+)
+
+// RelationshipLookup lists the relationships between two entities of the knowledge graph
+type RelationshipLookup struct {
+	Source        string         `json:"src"` // after alias expansion
+	Target        string         `json:"dst"`
+	Relationships []Relationship `json:"relationships"`
+}
+
+// Relationship is a relationship of the knowledge graph with the memories that evidence it
+type Relationship struct {
+	Source      string   `json:"src"` // the direction it was extracted in
+	Target      string   `json:"dst"`
+	Description string   `json:"description,omitempty"`
+	Keywords    string   `json:"keywords,omitempty"`
+	Weight      *float64 `json:"weight,omitempty"`
+
+	// Memories are the memories the relationship was extracted from, oldest first
+	Memories []SourceMemory `json:"memories"`
+}
+
+// handleLookupRelationship returns the relationships between two entities and the memories each
+// was extracted from, hydrated like the sources of a query, so "when did I meet X with Y" can be
+// answered with dates and places (GET /api/v1/lookup/relationship). Query parameters: src, dst,
+// context_id (repeatable or comma-separated; only memories of these contexts, and their aliases),
+// max_nodes, max_memories.
+func (s *Server) handleLookupRelationship(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	values := r.URL.Query()
+	contextIDs := queryList(r, "context_id")
+	if err := authorizeContexts(r.Context(), contextIDs...); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+	src := s.expandAlias(strings.TrimSpace(values.Get("src")), contextIDs...)
+	dst := s.expandAlias(strings.TrimSpace(values.Get("dst")), contextIDs...)
+	if src == "" || dst == "" {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "query parameters src and dst are required")
+		return
+	}
+
+	maxNodes := defaultRelationshipScanNodes
+	if value := values.Get("max_nodes"); value != "" {
+		var err error
+		if maxNodes, err = strconv.Atoi(value); err != nil || maxNodes < 1 || maxNodes > maxRelationshipScanNodes {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest,
+				fmt.Sprintf("max_nodes must be between 1 and %d", maxRelationshipScanNodes))
+			return
+		}
+	}
+	maxMemories := defaultMemoryScanLimit
+	if value := values.Get("max_memories"); value != "" {
+		var err error
+		if maxMemories, err = strconv.Atoi(value); err != nil || maxMemories < 1 || maxMemories > maxMemoryScanLimit {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest,
+				fmt.Sprintf("max_memories must be between 1 and %d", maxMemoryScanLimit))
+			return
+		}
+	}
+
+	edges, found, err := s.relationshipEdges(r.Context(), src, dst, maxNodes)
+	if err != nil {
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, err.Error())
+		return
+	}
+	if !found {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound, fmt.Sprintf("entity %q not found in the knowledge graph", src))
+		return
+	}
+
+	lookup := RelationshipLookup{Source: src, Target: dst, Relationships: []Relationship{}}
+	for _, edge := range edges {
+		relationship := Relationship{Source: edge.Source, Target: edge.Target, Memories: []SourceMemory{}}
+		if description := stringProperty(edge.Properties, "description"); description != nil {
+			relationship.Description = *description
+		}
+		if keywords := stringProperty(edge.Properties, "keywords"); keywords != nil {
+			relationship.Keywords = *keywords
+		}
+		if weight, ok := edge.Properties["weight"].(float64); ok {
+			relationship.Weight = &weight
+		}
+
+		memories, err := s.evidenceOf(r.Context(), edge, contextIDs)
+		if err != nil {
+			s.writeInternalError(w, r, err)
+			return
+		}
+		relationship.Memories = s.hydrateSources(r.Context(), memories, maxMemories)
+		sort.SliceStable(relationship.Memories, func(i, j int) bool {
+			a, b := relationship.Memories[i].Date, relationship.Memories[j].Date
+			return a != "" && (b == "" || a < b)
+		})
+		lookup.Relationships = append(lookup.Relationships, relationship)
+	}
+
+	writeJSON(w, http.StatusOK, lookup)
+}
+
+// relationshipEdges returns the edges between two entities, in either direction, read from the
+// graph around src, and from the graph around dst if the first was truncated before reaching it.
+// found is false if src isn't an entity of the graph.
+func (s *Server) relationshipEdges(ctx context.Context, src, dst string, maxNodes int) ([]client.GraphEdge, bool, error) {
+	var edges []client.GraphEdge
+	found := false
+	for _, label := range []string{src, dst} {
+		graph, err := s.lightragClient.GetKnowledgeGraph(ctx, label, 1, maxNodes)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: %w", errGraphUnavailable, err)
+		}
+		for _, node := range graph.Nodes {
+			found = found || node.ID == src
+		}
+		for _, edge := range graph.Edges {
+			if edge.Source == src && edge.Target == dst || edge.Source == dst && edge.Target == src {
+				edges = append(edges, edge)
+			}
+		}
+		if len(edges) > 0 || !graph.IsTruncated {
+			break
+		}
+	}
+	return edges, found, nil
+}
+
+// evidenceOf returns the memories a relationship was extracted from, read from the file paths of
+// its source documents, only those of contextIDs if given
+func (s *Server) evidenceOf(ctx context.Context, edge client.GraphEdge, contextIDs []string) ([]MemoryLookup, error) {
+	filePath := stringProperty(edge.Properties, "file_path")
+	if filePath == nil {
+		return nil, nil
+	}
+
+	var references []string
+	seen := make(map[string]bool)
+	for _, reference := range strings.Split(*filePath, graphFieldSeparator) {
+		if reference = strings.TrimSpace(reference); isMemoryReference(reference) && !seen[reference] {
+			seen[reference] = true
+			references = append(references, reference)
+		}
+	}
+
+	lookups, err := s.lookupReferences(ctx, references, contextIDs)
+	if err != nil {
+		return nil, err
+	}
+	var memories []MemoryLookup
+	resolved := make(map[string]bool, len(lookups))
+	for _, lookup := range lookups {
+		if lookup != nil && !resolved[lookup.MemoryID] {
+			resolved[lookup.MemoryID] = true
+			memories = append(memories, *lookup)
+		}
+	}
+	return memories, nil
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// Report history paging
+const (
+	defaultReportLimit = 50
+	maxReportLimit     = 500
+	historyLimit       = 20 // reports returned by /history and the GraphQL/gRPC history
+)
+
+// reportStatuses are the valid values of the status filter
+var reportStatuses = map[string]bool{"success": true, "partial": true, "failed": true, "interrupted": true, "quota_exhausted": true, "upstream_unavailable": true}
+
+// handleConnectorReports returns a page of a connector's recorded sync reports, newest first.
+// Query parameters: since and until (RFC 3339 or YYYY-MM-DD, by start time), status, limit, offset.
+// With Accept: text/csv or application/x-ndjson the reports are exported as rows, by default all of them.
+func (s *Server) handleConnectorReports(w http.ResponseWriter, r *http.Request, connector *models.ConnectorConfig) {
+	w.Header().Add("Vary", "Accept")
+	format := negotiateReportFormat(r)
+	defaultLimit := defaultReportLimit
+	if format != formatJSON {
+		defaultLimit = 0
+	}
+
+	query, err := parseReportQuery(r, defaultLimit)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+
+	page, err := s.stateManager.ListReports(r.Context(), connector.ID, query)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	if format == formatJSON {
+		writeJSON(w, http.StatusOK, page)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	s.writeExport(w, r, format, connector.ID+"-reports", page.Reports)
+}
+
+// handleLatestReport returns a connector's most recent sync report
+func (s *Server) handleLatestReport(w http.ResponseWriter, r *http.Request, connector *models.ConnectorConfig) {
+	w.Header().Add("Vary", "Accept")
+	report, err := s.latestReport(r.Context(), connector)
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+	if report == nil {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound,
+			fmt.Sprintf("connector %q has no recorded sync reports", connector.ID))
+		return
+	}
+
+	if format := negotiateReportFormat(r); format != formatJSON {
+		s.writeExport(w, r, format, connector.ID+"-report-latest", []models.SyncReport{*report})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// writeExport writes reports in an export format. Errors after the headers are sent can only be logged.
+func (s *Server) writeExport(w http.ResponseWriter, r *http.Request, format, filename string, reports []models.SyncReport) {
+	if err := writeReportExport(w, format, filename, reports); err != nil {
+		s.logger.Warn("Failed to write report export",
+			zap.String("path", r.URL.Path),
+			zap.String("correlation_id", CorrelationID(r.Context())),
+			zap.Error(err),
+		)
+	}
+}
+
+// latestReport returns a connector's most recent sync report, nil if it never synced
+func (s *Server) latestReport(ctx context.Context, connector *models.ConnectorConfig) (*models.SyncReport, error) {
+	history, err := s.connectorHistory(ctx, connector)
+	if err != nil {
+		return nil, err
+	}
+	if len(history.Reports) == 0 {
+		return nil, nil
+	}
+	return &history.Reports[0], nil
+}
+
+// connectorHistory returns the most recent sync reports of a connector, newest first
+func (s *Server) connectorHistory(ctx context.Context, connector *models.ConnectorConfig) (models.SyncHistory, error) {
+	page, err := s.stateManager.ListReports(ctx, connector.ID, models.ReportQuery{Limit: historyLimit})
+	if err != nil {
+		return models.SyncHistory{}, err
+	}
+	if len(page.Reports) > 0 {
+		return models.SyncHistory{Reports: page.Reports}, nil
+	}
+
+	// State written before reports were recorded only has the last report
+	syncState, err := s.stateManager.GetState(ctx, connector.ID)
+	if err != nil {
+		return models.SyncHistory{}, err
+	}
+
+	history := models.SyncHistory{Reports: []models.SyncReport{}}
+	if syncState.LastSyncReport != nil {
+		history.Reports = append(history.Reports, *syncState.LastSyncReport)
+	}
+
+	return history, nil
+}
+
+// parseReportQuery reads the filters and paging of a report request
+func parseReportQuery(r *http.Request, defaultLimit int) (models.ReportQuery, error) {
+	values := r.URL.Query()
+	query := models.ReportQuery{Limit: defaultLimit, Status: values.Get("status")}
+
+	if query.Status != "" && !reportStatuses[query.Status] {
+		return query, fmt.Errorf("status must be one of success, partial, failed, interrupted, quota_exhausted, upstream_unavailable, got %q", query.Status)
+	}
+
+	var err error
+	if query.Since, err = parseReportTime(values.Get("since")); err != nil {
+		return query, fmt.Errorf("invalid since: %w", err)
+	}
+	if query.Until, err = parseReportTime(values.Get("until")); err != nil {
+		return query, fmt.Errorf("invalid until: %w", err)
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && !query.Since.Before(query.Until) {
+		return query, fmt.Errorf("since must be before until")
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 1 || query.Limit > maxReportLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", maxReportLimit)
+		}
+	}
+	if offset := values.Get("offset"); offset != "" {
+		if query.Offset, err = strconv.Atoi(offset); err != nil || query.Offset < 0 {
+			return query, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+
+	return query, nil
+}
+
+// parseReportTime parses an RFC 3339 timestamp or a date (midnight UTC); empty means no bound
+func parseReportTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 timestamp nor a YYYY-MM-DD date", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/kamir/memory-connector/pkg/config"
+)
+
+// handleRotateSecrets re-reads the config file and resolves its secret references again, so
+// rotated LightRAG and Memory API credentials are verified and applied without a restart, like
+// SIGHUP (POST /api/v1/admin/rotate-secrets). It returns what changed.
+func (s *Server) handleRotateSecrets(w http.ResponseWriter, r *http.Request) {
+	if s.reloader == nil {
+		s.handleNotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	principal := anonymousPrincipal
+	if p, ok := PrincipalFrom(r.Context()); ok {
+		principal = p.Name
+	}
+
+	diff, err := s.reloader.Reload(principal)
+	switch {
+	case errors.Is(err, config.ErrConfigRejected):
+		writeProblem(w, r, http.StatusUnprocessableEntity, CodeInvalidRequest, err.Error())
+	case err != nil:
+		writeProblem(w, r, http.StatusBadGateway, CodeUpstreamUnavailable,
+			"the previous configuration stays active: "+err.Error())
+	default:
+		writeJSON(w, http.StatusOK, diff)
+	}
+}
@@ -0,0 +1,382 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/config"
+	"github.com/kamir/memory-connector/pkg/events"
+	"github.com/kamir/memory-connector/pkg/reporting"
+	"github.com/kamir/memory-connector/pkg/scheduler"
+	"github.com/kamir/memory-connector/pkg/state"
+	"github.com/kamir/memory-connector/pkg/transformer"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// Server timeouts. The write timeout is derived from the route timeouts (see writeTimeout).
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	idleTimeout       = 120 * time.Second
+
+	// writeTimeoutMargin leaves time to send the 504 response of a timed out handler
+	writeTimeoutMargin = 5 * time.Second
+)
+
+// ConfigSource returns the currently active configuration (changes with hot reloads)
+type ConfigSource func() *config.Config
+
+// TransformerMetricsSource returns the metrics of the transformation strategies in use
+type TransformerMetricsSource func() []transformer.StrategyMetrics
+
+// LogLevels changes the log levels of the service's subsystems at runtime
+type LogLevels interface {
+	// SetLevel changes the level of a subsystem, or of all subsystems if subsystem is empty
+	SetLevel(subsystem, level string) error
+	// Levels returns the current level of each subsystem
+	Levels() map[string]string
+}
+
+// ConfigReloader re-reads the configuration with its secret references and applies it
+type ConfigReloader interface {
+	// Reload applies the config file as it is now, recording principal in the audit log, and
+	// returns what changed. Errors mean the previous configuration stays active.
+	Reload(principal string) (config.ConfigDiff, error)
+}
+
+// PseudonymResolver resolves the pseudonyms of anonymized contexts back to real names
+type PseudonymResolver interface {
+	// Resolve replaces the context's pseudonyms in text, returning the pseudonyms it resolved
+	Resolve(contextID, text string) (string, map[string]string, error)
+}
+
+// Server is the management and lookup API, served over HTTP and optionally gRPC
+type Server struct {
+	httpServer      *http.Server
+	grpcServer      *grpc.Server // only if server.grpc is enabled
+	challengeServer *http.Server // ACME HTTP-01 listener, only with autocert
+	adminServer     *http.Server // pprof and expvar listener, only if server.admin is enabled
+	serverConfig    config.ServerConfig
+	configs         ConfigSource
+	scheduler       *scheduler.Scheduler
+	stateManager    state.StateManager
+	lightragClient  client.LightRAGAPI
+	graphqlSchema   *graphql.Schema
+	events          *events.Bus   // feeds /api/v1/events, may be nil
+	eventLog        *events.Store // serves /api/v1/events/query, may be nil
+	transformers    TransformerMetricsSource
+	logLevels       LogLevels          // serves /api/v1/admin/log-level, may be nil
+	reloader        ConfigReloader     // serves /api/v1/admin/rotate-secrets, may be nil
+	reporter        reporting.Reporter // receives handler panics
+	pseudonyms      PseudonymResolver  // resolves text in lookups of anonymized memories, may be nil
+	aliases         AliasRegistry      // serves /api/v1/aliases/ and expands aliases in graph lookups, may be nil
+	features        FeatureOverrides   // serves /api/v1/connectors/{id}/features, may be nil
+	memories        MemoryFetcher      // serves /api/v1/lookup/freshness, may be nil
+	places          PlaceNamer         // names the places of query sources, may be nil
+	chatProxy       OllamaForwarder    // serves /ollama/, may be nil
+	memoryAPIs      MemoryAPIChecker   // checks the Memory APIs for /api/v1/summary, may be nil
+	auth            *authenticator     // set by Start if server.auth is enabled
+	auditLog        AuditLog           // records mutations and serves /api/v1/audit, may be nil
+	purgeMu         sync.Mutex
+	purges          map[string]pendingPurge // confirmation token -> requested purge
+	logger          *zap.Logger
+}
+
+// NewServer creates the API server listening on the configured host and port
+func NewServer(
+	serverConfig config.ServerConfig,
+	configs ConfigSource,
+	sched *scheduler.Scheduler,
+	stateManager state.StateManager,
+	lightragClient client.LightRAGAPI,
+	eventBus *events.Bus,
+	logger *zap.Logger,
+) *Server {
+	s := &Server{
+		serverConfig:   serverConfig,
+		configs:        configs,
+		scheduler:      sched,
+		stateManager:   stateManager,
+		lightragClient: lightragClient,
+		events:         eventBus,
+		reporter:       reporting.Nop{},
+		purges:         make(map[string]pendingPurge),
+		logger:         logger,
+	}
+	s.graphqlSchema = s.newGraphQLSchema()
+
+	s.httpServer = &http.Server{
+		Addr:              net.JoinHostPort(serverConfig.Host, strconv.Itoa(serverConfig.Port)),
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      s.writeTimeout(),
+		IdleTimeout:       idleTimeout,
+	}
+
+	return s
+}
+
+// SetTransformerMetrics makes /api/v1/transformers serve the metrics of source. Must be called before Start.
+func (s *Server) SetTransformerMetrics(source TransformerMetricsSource) {
+	s.transformers = source
+}
+
+// SetEventLog makes /api/v1/events/query serve the events recorded in store. Must be called before Start.
+func (s *Server) SetEventLog(store *events.Store) {
+	s.eventLog = store
+}
+
+// SetLogLevels makes /api/v1/admin/log-level change the log levels of levels. Must be called before Start.
+func (s *Server) SetLogLevels(levels LogLevels) {
+	s.logLevels = levels
+}
+
+// SetConfigReloader makes /api/v1/admin/rotate-secrets reload the configuration with reloader.
+// Must be called before Start.
+func (s *Server) SetConfigReloader(reloader ConfigReloader) {
+	s.reloader = reloader
+}
+
+// SetErrorReporter makes the server report panics of HTTP handlers and gRPC calls to reporter.
+// Must be called before Start.
+func (s *Server) SetErrorReporter(reporter reporting.Reporter) {
+	s.reporter = reporter
+}
+
+// SetPseudonymResolver makes memory lookups resolve the pseudonyms of anonymized contexts in
+// their text parameter. Must be called before Start.
+func (s *Server) SetPseudonymResolver(resolver PseudonymResolver) {
+	s.pseudonyms = resolver
+}
+
+// SetAliasRegistry makes /api/v1/aliases/ maintain the aliases of registry, and graph lookups
+// expand them. Must be called before Start.
+func (s *Server) SetAliasRegistry(registry AliasRegistry) {
+	s.aliases = registry
+}
+
+// SetFeatureOverrides makes /api/v1/connectors/{id}/features maintain the runtime overrides of
+// connectors' feature flags. Must be called before Start.
+func (s *Server) SetFeatureOverrides(overrides FeatureOverrides) {
+	s.features = overrides
+}
+
+// SetMemoryFetcher makes /api/v1/lookup/freshness compare ingested memories with their current
+// version fetched by fetcher. Must be called before Start.
+func (s *Server) SetMemoryFetcher(fetcher MemoryFetcher) {
+	s.memories = fetcher
+}
+
+// SetPlaceNamer makes /api/v1/query name the places of the memories an answer cites with namer.
+// Must be called before Start.
+func (s *Server) SetPlaceNamer(namer PlaceNamer) {
+	s.places = namer
+}
+
+// SetChatProxy makes /ollama/ forward chats to LightRAG's Ollama API through forwarder.
+// Must be called before Start.
+func (s *Server) SetChatProxy(forwarder OllamaForwarder) {
+	s.chatProxy = forwarder
+}
+
+// SetMemoryAPIChecker makes /api/v1/summary report the health of the connectors' Memory APIs,
+// checked by checker. Must be called before Start.
+func (s *Server) SetMemoryAPIChecker(checker MemoryAPIChecker) {
+	s.memoryAPIs = checker
+}
+
+// SetAuditLog makes the server record the mutations made through the API (syncs, pauses, purges,
+// ...) in log and serve it at /api/v1/audit. Must be called before Start.
+func (s *Server) SetAuditLog(log AuditLog) {
+	s.auditLog = log
+}
+
+// Handler returns the HTTP handler with all routes and middleware
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	s.route(mux, "/api/v1/health", s.handleHealth)
+	s.route(mux, "/api/v1/summary", s.handleSummary)
+	s.route(mux, "/api/v1/audit", s.handleAudit)
+	s.route(mux, "/api/v1/connectors", withETag(s.handleListConnectors))
+	s.route(mux, "/api/v1/connectors/", withETag(s.handleConnector))
+	s.route(mux, "/api/v1/lookup/memory", withETag(s.handleLookupMemory))
+	s.route(mux, "/api/v1/lookup/memories", s.handleLookupMemories)
+	s.route(mux, "/api/v1/lookup/freshness", s.handleLookupFreshness)
+	s.route(mux, "/api/v1/lookup/receipt", withETag(s.handleLookupReceipt))
+	s.route(mux, "/api/v1/lookup/relationship", withETag(s.handleLookupRelationship))
+	s.route(mux, "/api/v1/query", s.handleQuery)
+	s.route(mux, "/api/v1/transformers", s.handleTransformerMetrics)
+	s.route(mux, "/api/v1/admin/log-level", s.handleLogLevel)
+	s.route(mux, "/api/v1/admin/rotate-secrets", s.handleRotateSecrets)
+	s.route(mux, "/api/v1/aliases/", s.handleAliases)
+	s.route(mux, "/api/v1/analysis/entity-merges", s.handleEntityMerges)
+	s.route(mux, "/api/v1/purges", s.handlePurges)
+	s.route(mux, "/api/v1/purges/", s.handlePurges)
+	s.route(mux, "/api/v1/snapshots", s.handleSnapshot)
+	s.route(mux, "/api/v1/restores", s.handleRestore)
+	s.route(mux, "/api/v1/imports", s.handleImport)
+	s.route(mux, "/graphql", s.handleGraphQL)
+	// Long-lived WebSocket, not bounded by a route timeout
+	mux.HandleFunc("/api/v1/events", s.handleEvents)
+	s.route(mux, "/api/v1/events/query", s.handleEventQuery)
+	// Streamed chat answers, bounded by server.chat_proxy.timeout instead of a route timeout
+	mux.HandleFunc("/ollama/", s.handleOllama)
+	mux.HandleFunc("/", s.handleNotFound)
+
+	var handler http.Handler = mux
+	if s.serverConfig.Compression {
+		handler = withCompression(handler)
+	}
+	return withCorrelationID(s.withLogging(s.withRecovery(s.withAuth(handler))))
+}
+
+// route registers a handler with its route timeout
+func (s *Server) route(mux *http.ServeMux, pattern string, handler http.HandlerFunc) {
+	mux.HandleFunc(pattern, s.withTimeout(s.routeTimeout(pattern), handler))
+}
+
+// routeTimeout returns the configured timeout of a route
+func (s *Server) routeTimeout(pattern string) time.Duration {
+	if seconds, ok := s.serverConfig.RouteTimeouts[pattern]; ok && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Duration(s.serverConfig.RequestTimeout) * time.Second
+}
+
+// writeTimeout returns a write timeout longer than every route timeout
+func (s *Server) writeTimeout() time.Duration {
+	longest := time.Duration(s.serverConfig.RequestTimeout) * time.Second
+	for _, seconds := range s.serverConfig.RouteTimeouts {
+		if timeout := time.Duration(seconds) * time.Second; timeout > longest {
+			longest = timeout
+		}
+	}
+	return longest + writeTimeoutMargin
+}
+
+// Start starts serving in the background, over HTTPS if TLS is configured
+func (s *Server) Start() error {
+	tlsEnabled := s.serverConfig.TLS.Enabled()
+
+	if s.serverConfig.Auth.Enabled {
+		auth, err := newAuthenticator(s.serverConfig.Auth)
+		if err != nil {
+			return err
+		}
+		s.auth = auth
+	}
+
+	var challengeHandler http.Handler
+	if tlsEnabled {
+		var err error
+		challengeHandler, err = configureTLS(s.httpServer, s.serverConfig.TLS, s.serverConfig.HTTP2)
+		if err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.httpServer.Addr, err)
+	}
+
+	if s.serverConfig.GRPC.Enabled {
+		if err := s.startGRPC(s.httpServer.TLSConfig); err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	if s.serverConfig.Admin.Enabled {
+		if err := s.startAdminServer(s.serverConfig.Admin.Addr); err != nil {
+			listener.Close()
+			if s.grpcServer != nil {
+				s.grpcServer.Stop()
+			}
+			return err
+		}
+	}
+
+	if challengeHandler != nil {
+		s.startChallengeServer(s.serverConfig.TLS.Autocert.HTTPAddr, challengeHandler)
+	}
+
+	s.logger.Info("API server listening",
+		zap.String("addr", listener.Addr().String()),
+		zap.Bool("tls", tlsEnabled),
+		zap.Bool("http2", tlsEnabled && s.serverConfig.HTTP2),
+	)
+
+	go func() {
+		var err error
+		if tlsEnabled {
+			// Certificates come from TLSConfig
+			err = s.httpServer.ServeTLS(listener, "", "")
+		} else {
+			err = s.httpServer.Serve(listener)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("API server failed", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown gracefully stops the service: it stops accepting requests and drains in-flight
+// requests, then stops the scheduler and waits for running syncs to checkpoint, all until ctx expires
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.logger.Info("Stopping API server...")
+
+	if s.challengeServer != nil {
+		if err := s.challengeServer.Shutdown(ctx); err != nil {
+			s.logger.Warn("ACME challenge listener shutdown incomplete", zap.Error(err))
+		}
+	}
+
+	var errs []error
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("draining HTTP requests: %w", err))
+	}
+
+	// gRPC streams report on running syncs, so they end once the scheduler has stopped them
+	grpcStopped := make(chan struct{})
+	if s.grpcServer != nil {
+		go func() {
+			s.grpcServer.GracefulStop()
+			close(grpcStopped)
+		}()
+	}
+
+	if err := s.scheduler.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if s.grpcServer != nil {
+		select {
+		case <-grpcStopped:
+		case <-ctx.Done():
+			s.grpcServer.Stop()
+			errs = append(errs, fmt.Errorf("draining gRPC calls: %w", ctx.Err()))
+		}
+	}
+
+	// Diagnostics stay available until the syncs have stopped
+	if s.adminServer != nil {
+		if err := s.adminServer.Shutdown(ctx); err != nil {
+			s.logger.Warn("Admin diagnostics listener shutdown incomplete", zap.Error(err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
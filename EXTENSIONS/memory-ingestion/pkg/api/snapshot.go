@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/kamir/memory-connector/pkg/audit"
+	"github.com/kamir/memory-connector/pkg/orchestrator"
+	"github.com/kamir/memory-connector/pkg/scheduler"
+	"go.uber.org/zap"
+)
+
+// Snapshot archives are gzip-compressed tars
+const snapshotContentType = "application/gzip"
+
+// SnapshotMissingHeader counts the memories a snapshot archive misses (see models.SnapshotManifest.Missing)
+const SnapshotMissingHeader = "X-Snapshot-Missing"
+
+// restoreMaxBodyBytes limits the size of restored snapshot archives
+const restoreMaxBodyBytes = 1 << 30
+
+// handleSnapshot writes the documents and sync state of a context as a snapshot archive
+// (GET /api/v1/snapshots?context_id=), to be restored by POST /api/v1/restores
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	contextID := r.URL.Query().Get("context_id")
+	if contextID == "" {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "context_id is required")
+		return
+	}
+	if err := authorizeContexts(r.Context(), contextID); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+	limit := defaultMemoryScanLimit
+	if raw := r.URL.Query().Get("max_memories"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxMemoryScanLimit {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest,
+				fmt.Sprintf("max_memories must be between 1 and %d", maxMemoryScanLimit))
+			return
+		}
+		limit = n
+	}
+
+	connectors := s.configs().Connectors
+	if !hasContext(connectors, contextID) {
+		writeProblem(w, r, http.StatusNotFound, CodeEntityNotFound,
+			fmt.Sprintf("no connector ingests context %q", contextID))
+		return
+	}
+
+	// Buffered, so a failed snapshot is answered with a problem rather than a truncated archive
+	var archive bytes.Buffer
+	manifest, err := s.scheduler.Snapshot(r.Context(), contextID, connectors, limit, &archive)
+	switch {
+	case errors.Is(err, orchestrator.ErrUpstreamFailed):
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, err.Error())
+		return
+	case err != nil:
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("snapshot-%s-%s.tar.gz", contextID, manifest.CreatedAt.Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", snapshotContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Length", strconv.Itoa(archive.Len()))
+	if len(manifest.Missing) > 0 {
+		w.Header().Set(SnapshotMissingHeader, strconv.Itoa(len(manifest.Missing)))
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := archive.WriteTo(w); err != nil {
+		s.logger.Warn("Failed to write snapshot", zap.Error(err))
+	}
+}
+
+// handleRestore restores a snapshot archive sent as the request body (POST /api/v1/restores)
+func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	snapshot, err := orchestrator.ReadSnapshot(http.MaxBytesReader(w, r.Body, restoreMaxBodyBytes))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+	if err := authorizeContexts(r.Context(), snapshot.Manifest.ContextID); err != nil {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+		return
+	}
+
+	result, err := s.scheduler.RestoreSnapshot(snapshot, s.configs().Connectors)
+	if !errors.Is(err, orchestrator.ErrInvalidSnapshot) {
+		entry := audit.Entry{Action: audit.ActionRestore}
+		if err == nil {
+			entry.Target = result.ContextID
+			entry.After = result
+		}
+		s.recordAudit(r.Context(), entry, err)
+	}
+	switch {
+	case errors.Is(err, orchestrator.ErrInvalidSnapshot):
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+	case errors.Is(err, orchestrator.ErrStateExists):
+		writeProblem(w, r, http.StatusConflict, CodeConflict,
+			fmt.Sprintf("%v, purge the context before restoring it", err))
+	case errors.Is(err, scheduler.ErrSyncRunning):
+		writeProblem(w, r, http.StatusConflict, CodeConflict,
+			fmt.Sprintf("%v, restore the snapshot when it's done", err))
+	case errors.Is(err, orchestrator.ErrUpstreamFailed):
+		writeProblem(w, r, http.StatusServiceUnavailable, CodeUpstreamUnavailable, err.Error())
+	case err != nil:
+		s.writeInternalError(w, r, err)
+	default:
+		s.logger.Info("Snapshot restored",
+			zap.String("context_id", result.ContextID),
+			zap.Int("restored_documents", result.RestoredDocuments),
+			zap.String("correlation_id", CorrelationID(r.Context())),
+		)
+		writeJSON(w, http.StatusOK, result)
+	}
+}
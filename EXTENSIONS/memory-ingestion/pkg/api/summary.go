@@ -0,0 +1,242 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// Upstream states of the summary
+const (
+	upstreamOK          = "ok"
+	upstreamUnavailable = "unavailable"
+)
+
+// MemoryAPIChecker checks the Memory API of a connector
+type MemoryAPIChecker interface {
+	// CheckMemoryAPI returns an error if the connector's Memory API doesn't answer
+	CheckMemoryAPI(ctx context.Context, config *models.ConnectorConfig) error
+}
+
+// Summary is the state of all connectors and their upstreams, for status dashboards
+type Summary struct {
+	Status      string    `json:"status"` // ok, or degraded if a connector fails or an upstream is unavailable
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Connectors        ConnectorCounts    `json:"connectors"`
+	FailingConnectors []FailingConnector `json:"failing_connectors"`
+	Ingested          IngestedCounts     `json:"ingested"`
+	DeadLetterQueue   int                `json:"dead_letter_queue"` // failed memories awaiting a retry, across connectors
+
+	LightRAG   LightRAGSummary   `json:"lightrag"`
+	MemoryAPIs []MemoryAPIHealth `json:"memory_apis"`
+}
+
+// ConnectorCounts counts the configured connectors
+type ConnectorCounts struct {
+	Total   int            `json:"total"`
+	Enabled int            `json:"enabled"`
+	States  map[string]int `json:"states"` // connectors per state of their status
+}
+
+// FailingConnector is a connector whose last sync failed
+type FailingConnector struct {
+	ConnectorID  string     `json:"connector_id"`
+	ContextID    string     `json:"context_id"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	LastSyncTime *time.Time `json:"last_sync_time,omitempty"`
+}
+
+// IngestedCounts counts the memories ingested by syncs started since midnight and since Monday,
+// in the server's time zone
+type IngestedCounts struct {
+	Today    int `json:"today"`
+	ThisWeek int `json:"this_week"`
+}
+
+// LightRAGSummary is the state of LightRAG and its processing pipeline
+type LightRAGSummary struct {
+	Status          string         `json:"status"`
+	PipelineBacklog int            `json:"pipeline_backlog"`    // documents queued or being processed
+	Documents       map[string]int `json:"documents,omitempty"` // documents per processing status
+	Error           string         `json:"error,omitempty"`
+}
+
+// MemoryAPIHealth is the state of a Memory API and the connectors that fetch from it
+type MemoryAPIHealth struct {
+	URL        string   `json:"url,omitempty"` // a connector's memory_api override, empty for the global memory_api
+	Connectors []string `json:"connectors"`
+	Status     string   `json:"status"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// handleSummary aggregates the state of all connectors, LightRAG, and the Memory APIs in a single
+// call for status dashboards (GET /api/v1/summary). Unavailable upstreams are reported, not failed
+// on; each is checked within healthCheckTimeout. Clients bound to contexts get the summary of their
+// connectors; LightRAG's document counts span all contexts.
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekStart := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+
+	summary := Summary{
+		Status:            "ok",
+		GeneratedAt:       now,
+		Connectors:        ConnectorCounts{States: make(map[string]int)},
+		FailingConnectors: []FailingConnector{},
+	}
+	connectors := s.scopedConnectors(r.Context())
+
+	// Upstreams are checked while the state is read
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		summary.LightRAG = s.lightragSummary(r.Context())
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		summary.MemoryAPIs = s.memoryAPIHealth(r.Context(), connectors)
+	}()
+
+	var err error
+	for i := range connectors {
+		if err = s.summarizeConnector(r.Context(), &connectors[i], &summary, today, weekStart); err != nil {
+			break
+		}
+	}
+	wg.Wait()
+	if err != nil {
+		s.writeInternalError(w, r, err)
+		return
+	}
+
+	if len(summary.FailingConnectors) > 0 || summary.LightRAG.Status != upstreamOK {
+		summary.Status = "degraded"
+	}
+	for _, memoryAPI := range summary.MemoryAPIs {
+		if memoryAPI.Status != upstreamOK {
+			summary.Status = "degraded"
+		}
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// summarizeConnector adds a connector's status, dead letter queue, and the memories its syncs
+// ingested since weekStart to the summary
+func (s *Server) summarizeConnector(ctx context.Context, connector *models.ConnectorConfig, summary *Summary, today, weekStart time.Time) error {
+	status, err := s.connectorStatus(ctx, connector)
+	if err != nil {
+		return err
+	}
+	summary.Connectors.Total++
+	if connector.Enabled {
+		summary.Connectors.Enabled++
+	}
+	summary.Connectors.States[status.State]++
+	if status.State == "error" {
+		summary.FailingConnectors = append(summary.FailingConnectors, FailingConnector{
+			ConnectorID:  connector.ID,
+			ContextID:    connector.ContextID,
+			ErrorMessage: status.ErrorMessage,
+			LastSyncTime: status.LastSyncTime,
+		})
+	}
+
+	syncState, err := s.stateManager.GetState(ctx, connector.ID)
+	if err != nil {
+		return err
+	}
+	for _, item := range syncState.FailedItems {
+		if item.Pending() {
+			summary.DeadLetterQueue++
+		}
+	}
+
+	reports, err := s.stateManager.ListReports(ctx, connector.ID, models.ReportQuery{Since: weekStart})
+	if err != nil {
+		return err
+	}
+	for _, report := range reports.Reports {
+		summary.Ingested.ThisWeek += report.TotalProcessed
+		if !report.StartTime.Before(today) {
+			summary.Ingested.Today += report.TotalProcessed
+		}
+	}
+	return nil
+}
+
+// lightragSummary checks LightRAG and counts its documents per processing status
+func (s *Server) lightragSummary(ctx context.Context) LightRAGSummary {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	counts, err := s.lightragClient.GetDocumentStatusCounts(ctx)
+	if err != nil {
+		return LightRAGSummary{Status: upstreamUnavailable, Error: err.Error()}
+	}
+	summary := LightRAGSummary{Status: upstreamOK, Documents: counts}
+	for _, status := range client.QueuedDocumentStatuses {
+		summary.PipelineBacklog += counts[status]
+	}
+	return summary
+}
+
+// memoryAPIHealth checks the Memory APIs of the enabled connectors concurrently, once per distinct
+// memory_api setting. Simulated connectors have none.
+func (s *Server) memoryAPIHealth(ctx context.Context, connectors []models.ConnectorConfig) []MemoryAPIHealth {
+	health := []MemoryAPIHealth{}
+	if s.memoryAPIs == nil {
+		return health
+	}
+
+	// The connector of each entry is the one checked for all of its connectors
+	var checked []*models.ConnectorConfig
+	for i := range connectors {
+		connector := &connectors[i]
+		if !connector.Enabled || connector.Simulation.Enabled {
+			continue
+		}
+		j := 0
+		for j < len(checked) && !reflect.DeepEqual(checked[j].MemoryAPI, connector.MemoryAPI) {
+			j++
+		}
+		if j == len(checked) {
+			checked = append(checked, connector)
+			entry := MemoryAPIHealth{Connectors: []string{}}
+			if connector.MemoryAPI != nil {
+				entry.URL = connector.MemoryAPI.URL
+			}
+			health = append(health, entry)
+		}
+		health[j].Connectors = append(health[j].Connectors, connector.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i, connector := range checked {
+		wg.Add(1)
+		go func(entry *MemoryAPIHealth, connector *models.ConnectorConfig) {
+			defer wg.Done()
+			entry.Status = upstreamOK
+			if err := s.memoryAPIs.CheckMemoryAPI(ctx, connector); err != nil {
+				entry.Status = upstreamUnavailable
+				entry.Error = err.Error()
+			}
+		}(&health[i], connector)
+	}
+	wg.Wait()
+	return health
+}
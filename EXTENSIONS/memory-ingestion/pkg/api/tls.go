@@ -0,0 +1,84 @@
+package api
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kamir/memory-connector/pkg/config"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// tlsVersions maps config values to TLS versions
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// configureTLS prepares the HTTPS settings of the server. It returns a non-nil handler
+// when autocert needs an additional plain HTTP listener for HTTP-01 challenges.
+func configureTLS(httpServer *http.Server, tlsConfig config.TLSConfig, http2 bool) (http.Handler, error) {
+	minVersion, ok := tlsVersions[tlsConfig.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TLS min_version: %s", tlsConfig.MinVersion)
+	}
+
+	var challengeHandler http.Handler
+
+	if tlsConfig.Autocert.Enabled {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(tlsConfig.Autocert.Domains...),
+			Cache:      autocert.DirCache(tlsConfig.Autocert.CacheDir),
+			Email:      tlsConfig.Autocert.Email,
+		}
+		// Includes the acme-tls/1 protocol for TLS-ALPN-01 challenges on the HTTPS port
+		httpServer.TLSConfig = manager.TLSConfig()
+		if tlsConfig.Autocert.HTTPAddr != "" {
+			challengeHandler = manager.HTTPHandler(nil)
+		}
+	} else {
+		certificate, err := tls.LoadX509KeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		httpServer.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{certificate},
+		}
+	}
+
+	httpServer.TLSConfig.MinVersion = minVersion
+
+	if http2 {
+		httpServer.TLSConfig.NextProtos = append([]string{"h2", "http/1.1"}, httpServer.TLSConfig.NextProtos...)
+	} else {
+		// A non-nil, empty map disables the automatic HTTP/2 upgrade
+		httpServer.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+		httpServer.TLSConfig.NextProtos = append([]string{"http/1.1"}, httpServer.TLSConfig.NextProtos...)
+	}
+
+	return challengeHandler, nil
+}
+
+// startChallengeServer serves ACME HTTP-01 challenges and redirects all other requests to HTTPS
+func (s *Server) startChallengeServer(addr string, handler http.Handler) {
+	s.challengeServer = &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      readTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+
+	s.logger.Info("ACME challenge listener started", zap.String("addr", addr))
+
+	go func() {
+		if err := s.challengeServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("ACME challenge listener failed", zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,190 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Audit actions
+const (
+	ActionConfigReload    = "config_reload"
+	ActionSyncTrigger     = "sync_trigger"
+	ActionConnectorPause  = "connector_pause"
+	ActionConnectorResume = "connector_resume"
+	ActionReingest        = "reingest"
+	ActionPurge           = "purge"
+	ActionRestore         = "restore"
+	ActionImport          = "import"
+	ActionAliasSet        = "alias_set"
+	ActionAliasDelete     = "alias_delete"
+	ActionEntityMerge     = "entity_merge"
+	ActionLogLevel        = "log_level"
+	ActionFeatureFlag     = "feature_flag"
+)
+
+// maxEntryBytes bounds the size of an entry read back from the log
+const maxEntryBytes = 4 << 20
+
+// Audit outcomes
+const (
+	OutcomeSuccess  = "success"
+	OutcomeRejected = "rejected" // validation failed, nothing was applied
+	OutcomeFailed   = "failed"   // apply failed
+)
+
+// Entry represents a single audit record
+type Entry struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Action    string                 `json:"action"`
+	Principal string                 `json:"principal,omitempty"`
+	Target    string                 `json:"target,omitempty"` // what was acted on, e.g. a connector or context ID
+	Outcome   string                 `json:"outcome"`
+	Before    interface{}            `json:"before,omitempty"` // the changed state before and after the action
+	After     interface{}            `json:"after,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// Query selects audit entries. Zero fields don't restrict.
+type Query struct {
+	Since     time.Time // entries at or after Since
+	Until     time.Time // entries before Until
+	Action    string
+	Principal string
+	Target    string
+	Limit     int // maximum number of entries, 0 for all
+	Offset    int // number of matching entries to skip
+}
+
+// matches returns true if an entry is selected by the query
+func (q Query) matches(entry Entry) bool {
+	return (q.Since.IsZero() || !entry.Timestamp.Before(q.Since)) &&
+		(q.Until.IsZero() || entry.Timestamp.Before(q.Until)) &&
+		(q.Action == "" || entry.Action == q.Action) &&
+		(q.Principal == "" || entry.Principal == q.Principal) &&
+		(q.Target == "" || entry.Target == q.Target)
+}
+
+// Page is a page of audit entries, newest first
+type Page struct {
+	Entries []Entry `json:"entries"`
+	Total   int     `json:"total"` // matching entries across all pages
+}
+
+// Recorder defines the interface for writing audit entries
+type Recorder interface {
+	// Record appends an entry to the audit log
+	Record(entry Entry) error
+}
+
+// FileLog is an append-only audit log stored as JSON Lines
+type FileLog struct {
+	path   string
+	logger *zap.Logger
+	mu     sync.Mutex
+}
+
+// NewFileLog creates a new JSON Lines audit log at path
+func NewFileLog(path string, logger *zap.Logger) (*FileLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	logger.Info("Initialized audit log", zap.String("path", path))
+
+	return &FileLog{
+		path:   path,
+		logger: logger,
+	}, nil
+}
+
+// Record appends an entry to the audit log
+func (l *FileLog) Record(entry Entry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+
+	l.logger.Debug("Recorded audit entry",
+		zap.String("action", entry.Action),
+		zap.String("outcome", entry.Outcome),
+	)
+
+	return nil
+}
+
+// Query returns a page of the recorded entries selected by q, newest first. The log is read in
+// full, it's expected to hold mutations only.
+func (l *FileLog) Query(q Query) (*Page, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	page := &Page{Entries: []Entry{}}
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return page, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var matching []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), maxEntryBytes)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A line cut off by a crash is skipped, the entries after it are intact
+			l.logger.Warn("Skipping unreadable audit entry", zap.Error(err))
+			continue
+		}
+		if q.matches(entry) {
+			matching = append(matching, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	page.Total = len(matching)
+	for i := len(matching) - 1 - q.Offset; i >= 0; i-- {
+		if q.Limit > 0 && len(page.Entries) == q.Limit {
+			break
+		}
+		page.Entries = append(page.Entries, matching[i])
+	}
+	return page, nil
+}
+
+// NopRecorder discards all entries (used when auditing is disabled)
+type NopRecorder struct{}
+
+// Record discards the entry
+func (NopRecorder) Record(entry Entry) error {
+	return nil
+}
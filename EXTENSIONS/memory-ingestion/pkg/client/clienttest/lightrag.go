@@ -0,0 +1,528 @@
+// Package clienttest provides fakes of the upstream services for integration tests
+// of connectors built on this module.
+package clienttest
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"go.uber.org/zap"
+)
+
+// GuestToken is the access token handed out by the fake /auth-status endpoint
+const GuestToken = "clienttest-guest-token"
+
+// Document is a document received by the fake LightRAG server
+type Document struct {
+	DocID      string            `json:"doc_id"`
+	Text       string            `json:"text"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	ReceivedAt time.Time         `json:"received_at"`
+}
+
+// FakeLightRAG is an in-process LightRAG API backed by httptest.Server.
+// It implements the endpoints used by client.LightRAGClient and records
+// every inserted document.
+type FakeLightRAG struct {
+	server    *httptest.Server
+	apiKey    string
+	mu        sync.Mutex
+	documents []Document
+	failures  []int // status codes returned by the next insert requests
+	requests  int
+	graph     client.KnowledgeGraph
+	answer    *client.QueryResponse // served by /query, nil to cite every document
+	pending   int                   // documents reported as PENDING by /documents/status_counts
+	gzip      bool
+}
+
+// FakeLightRAGOption configures a FakeLightRAG
+type FakeLightRAGOption func(*FakeLightRAG)
+
+// WithAPIKey makes the fake server require the given X-API-Key header
+func WithAPIKey(apiKey string) FakeLightRAGOption {
+	return func(f *FakeLightRAG) {
+		f.apiKey = apiKey
+	}
+}
+
+// WithGzipRequests makes the fake server decode gzip-compressed insert bodies, like a proxy in
+// front of LightRAG would; without it they are rejected like LightRAG does
+func WithGzipRequests() FakeLightRAGOption {
+	return func(f *FakeLightRAG) {
+		f.gzip = true
+	}
+}
+
+// NewFakeLightRAG starts a fake LightRAG server. Call Close when done.
+func NewFakeLightRAG(opts ...FakeLightRAGOption) *FakeLightRAG {
+	f := &FakeLightRAG{}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", f.handleHealth)
+	mux.HandleFunc("/auth-status", f.handleAuthStatus)
+	mux.HandleFunc("/documents/text", f.handleInsertText)
+	mux.HandleFunc("/documents/status_counts", f.handleStatusCounts)
+	mux.HandleFunc("/documents/paginated", f.handleListDocuments)
+	mux.HandleFunc("/documents/delete_document", f.handleDeleteDocuments)
+	mux.HandleFunc("/graphs", f.handleGraphs)
+	mux.HandleFunc("/graph/label/search", f.handleSearchLabels)
+	mux.HandleFunc("/graph/entities/merge", f.handleMergeEntities)
+	mux.HandleFunc("/query", f.handleQuery)
+	mux.HandleFunc("/query/data", f.handleQueryData)
+
+	f.server = httptest.NewServer(mux)
+	return f
+}
+
+// URL returns the base URL of the fake server
+func (f *FakeLightRAG) URL() string {
+	return f.server.URL
+}
+
+// Close shuts down the fake server
+func (f *FakeLightRAG) Close() {
+	f.server.Close()
+}
+
+// Client returns a LightRAGClient configured for the fake server with
+// a single, near-instant retry
+func (f *FakeLightRAG) Client(logger *zap.Logger) *client.LightRAGClient {
+	return client.NewLightRAGClient(client.LightRAGClientConfig{
+		APIURL:     f.URL(),
+		APIKey:     f.apiKey,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+	}, logger)
+}
+
+// FailNext makes the next len(statusCodes) insert requests fail with the given status codes
+func (f *FakeLightRAG) FailNext(statusCodes ...int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures = append(f.failures, statusCodes...)
+}
+
+// Documents returns a copy of all documents inserted so far
+func (f *FakeLightRAG) Documents() []Document {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	docs := make([]Document, len(f.documents))
+	copy(docs, f.documents)
+	return docs
+}
+
+// InsertRequests returns the number of insert requests received, including failed ones
+func (f *FakeLightRAG) InsertRequests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.requests
+}
+
+// SetGraph sets the knowledge graph served by /graphs, /graph/label/search, and /query/data
+func (f *FakeLightRAG) SetGraph(graph client.KnowledgeGraph) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.graph = graph
+}
+
+// SetAnswer sets the answer /query gives to every question, citing the documents with the given
+// file paths. Until it's set, answers cite every document inserted so far.
+func (f *FakeLightRAG) SetAnswer(response string, filePaths ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.answer = &client.QueryResponse{Response: response, References: queryReferences(filePaths)}
+}
+
+// SetPending sets the number of documents /documents/status_counts reports as waiting in the pipeline
+func (f *FakeLightRAG) SetPending(pending int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.pending = pending
+}
+
+// Reset clears recorded documents, request counters, pending failures, and the simulated pipeline queue
+func (f *FakeLightRAG) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.documents = nil
+	f.failures = nil
+	f.requests = 0
+	f.pending = 0
+}
+
+// handleHealth serves GET /health
+func (f *FakeLightRAG) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if !f.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "invalid API key"})
+		return
+	}
+	writeJSON(w, http.StatusOK, client.HealthResponse{Status: "healthy", CoreVersion: "1.4.6", APIVersion: "0204"})
+}
+
+// handleAuthStatus serves GET /auth-status
+func (f *FakeLightRAG) handleAuthStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, client.AuthStatusResponse{
+		AuthConfigured: f.apiKey != "",
+		AccessToken:    GuestToken,
+		TokenType:      "bearer",
+		AuthMode:       "disabled",
+		Message:        "clienttest fake server",
+	})
+}
+
+// handleInsertText serves POST /documents/text
+func (f *FakeLightRAG) handleInsertText(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"detail": "method not allowed"})
+		return
+	}
+	if !f.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "invalid API key"})
+		return
+	}
+
+	var body io.Reader = r.Body
+	if f.gzip && r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"detail": err.Error()})
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	var docReq client.DocumentRequest
+	if err := json.NewDecoder(body).Decode(&docReq); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"detail": err.Error()})
+		return
+	}
+
+	f.mu.Lock()
+	f.requests++
+	if len(f.failures) > 0 {
+		status := f.failures[0]
+		f.failures = f.failures[1:]
+		f.mu.Unlock()
+		writeJSON(w, status, map[string]string{"detail": "injected failure"})
+		return
+	}
+
+	doc := Document{
+		DocID:      fmt.Sprintf("doc-%d", f.requests),
+		Text:       docReq.Text,
+		Metadata:   docReq.Metadata,
+		ReceivedAt: time.Now(),
+	}
+	f.documents = append(f.documents, doc)
+	f.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, client.DocumentResponse{
+		Status:  client.InsertStatusSuccess,
+		Message: "Document inserted",
+		DocID:   doc.DocID,
+		TrackID: fmt.Sprintf("insert_%d", f.requests),
+	})
+}
+
+// handleListDocuments serves POST /documents/paginated with the inserted documents, oldest first.
+// Their file_path is the metadata's file_path.
+func (f *FakeLightRAG) handleListDocuments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"detail": "method not allowed"})
+		return
+	}
+	if !f.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "invalid API key"})
+		return
+	}
+
+	var listReq client.DocumentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&listReq); err != nil || listReq.Page < 1 || listReq.PageSize < 1 {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"detail": "invalid page"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var page client.DocumentPage
+	page.Documents = []client.DocumentStatus{}
+	for i := (listReq.Page - 1) * listReq.PageSize; i < len(f.documents) && len(page.Documents) < listReq.PageSize; i++ {
+		doc := f.documents[i]
+		receivedAt := doc.ReceivedAt.Format(time.RFC3339)
+		page.Documents = append(page.Documents, client.DocumentStatus{
+			ID:        doc.DocID,
+			Status:    "processed",
+			FilePath:  doc.Metadata["file_path"],
+			CreatedAt: receivedAt,
+			UpdatedAt: receivedAt,
+		})
+	}
+	page.Pagination.Page = listReq.Page
+	page.Pagination.TotalCount = len(f.documents)
+	page.Pagination.TotalPages = (len(f.documents) + listReq.PageSize - 1) / listReq.PageSize
+	page.Pagination.HasNext = listReq.Page < page.Pagination.TotalPages
+	writeJSON(w, http.StatusOK, page)
+}
+
+// handleDeleteDocuments serves DELETE /documents/delete_document, removing the documents at once
+func (f *FakeLightRAG) handleDeleteDocuments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"detail": "method not allowed"})
+		return
+	}
+	if !f.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "invalid API key"})
+		return
+	}
+
+	var deleteReq client.DeleteDocumentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&deleteReq); err != nil || len(deleteReq.DocIDs) == 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"detail": "document IDs list cannot be empty"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	deleted := make(map[string]bool, len(deleteReq.DocIDs))
+	for _, docID := range deleteReq.DocIDs {
+		deleted[docID] = true
+	}
+	documents := f.documents[:0]
+	for _, doc := range f.documents {
+		if !deleted[doc.DocID] {
+			documents = append(documents, doc)
+		}
+	}
+	f.documents = documents
+
+	writeJSON(w, http.StatusOK, client.DeleteDocumentsResponse{
+		Status:  "deletion_started",
+		Message: fmt.Sprintf("Document deletion for '%s' has been initiated", strings.Join(deleteReq.DocIDs, ", ")),
+	})
+}
+
+// handleStatusCounts serves GET /documents/status_counts. Inserted documents count as processed.
+func (f *FakeLightRAG) handleStatusCounts(w http.ResponseWriter, r *http.Request) {
+	if !f.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "invalid API key"})
+		return
+	}
+
+	f.mu.Lock()
+	counts := map[string]int{
+		"PENDING":   f.pending,
+		"PROCESSED": len(f.documents),
+	}
+	f.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, client.StatusCountsResponse{StatusCounts: counts})
+}
+
+// handleGraphs serves GET /graphs. It returns the whole graph set with SetGraph if the label
+// names one of its entities (or is "*"), and an empty graph otherwise.
+func (f *FakeLightRAG) handleGraphs(w http.ResponseWriter, r *http.Request) {
+	if !f.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "invalid API key"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	label := r.URL.Query().Get("label")
+	for _, node := range f.graph.Nodes {
+		if label == "*" || node.ID == label {
+			writeJSON(w, http.StatusOK, f.graph)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, client.KnowledgeGraph{Nodes: []client.GraphNode{}, Edges: []client.GraphEdge{}})
+}
+
+// handleSearchLabels serves GET /graph/label/search with case-insensitive substring matching
+func (f *FakeLightRAG) handleSearchLabels(w http.ResponseWriter, r *http.Request) {
+	if !f.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "invalid API key"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	query := strings.ToLower(r.URL.Query().Get("q"))
+	labels := []string{}
+	for _, node := range f.graph.Nodes {
+		if strings.Contains(strings.ToLower(node.ID), query) {
+			labels = append(labels, node.ID)
+		}
+	}
+	writeJSON(w, http.StatusOK, labels)
+}
+
+// handleMergeEntities serves POST /graph/entities/merge. The source entities are removed from the
+// graph set with SetGraph and their relationships moved to the target, which must exist.
+func (f *FakeLightRAG) handleMergeEntities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"detail": "method not allowed"})
+		return
+	}
+	if !f.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "invalid API key"})
+		return
+	}
+
+	var mergeReq client.EntityMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&mergeReq); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"detail": err.Error()})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sources := make(map[string]bool, len(mergeReq.EntitiesToChange))
+	for _, source := range mergeReq.EntitiesToChange {
+		sources[source] = true
+	}
+	targetExists := false
+	nodes := []client.GraphNode{}
+	for _, node := range f.graph.Nodes {
+		targetExists = targetExists || node.ID == mergeReq.EntityToChangeInto
+		if !sources[node.ID] {
+			nodes = append(nodes, node)
+		}
+	}
+	if !targetExists {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": fmt.Sprintf("entity %q does not exist", mergeReq.EntityToChangeInto)})
+		return
+	}
+
+	edges := []client.GraphEdge{}
+	for _, edge := range f.graph.Edges {
+		if sources[edge.Source] {
+			edge.Source = mergeReq.EntityToChangeInto
+		}
+		if sources[edge.Target] {
+			edge.Target = mergeReq.EntityToChangeInto
+		}
+		if edge.Source != edge.Target {
+			edges = append(edges, edge)
+		}
+	}
+	f.graph.Nodes, f.graph.Edges = nodes, edges
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": fmt.Sprintf("Successfully merged %d entities into '%s'", len(mergeReq.EntitiesToChange), mergeReq.EntityToChangeInto),
+	})
+}
+
+// handleQuery serves POST /query with the answer set with SetAnswer
+func (f *FakeLightRAG) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"detail": "method not allowed"})
+		return
+	}
+	if !f.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "invalid API key"})
+		return
+	}
+
+	var queryReq client.QueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&queryReq); err != nil || queryReq.Query == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"detail": "query is required"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	answer := f.answer
+	if answer == nil {
+		var filePaths []string
+		for _, doc := range f.documents {
+			filePaths = append(filePaths, doc.Metadata["file_path"])
+		}
+		answer = &client.QueryResponse{Response: fmt.Sprintf("The answer draws on %d documents.", len(filePaths)), References: queryReferences(filePaths)}
+	}
+	if !queryReq.IncludeReferences {
+		answer = &client.QueryResponse{Response: answer.Response}
+	}
+	writeJSON(w, http.StatusOK, answer)
+}
+
+// handleQueryData serves POST /query/data with every entity of the graph set with SetGraph
+func (f *FakeLightRAG) handleQueryData(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"detail": "method not allowed"})
+		return
+	}
+	if !f.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "invalid API key"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data := client.QueryData{Entities: []client.QueryEntity{}}
+	for _, node := range f.graph.Nodes {
+		entityType, _ := node.Properties["entity_type"].(string)
+		description, _ := node.Properties["description"].(string)
+		filePath, _ := node.Properties["file_path"].(string)
+		data.Entities = append(data.Entities, client.QueryEntity{
+			EntityName:  node.ID,
+			EntityType:  entityType,
+			Description: description,
+			FilePath:    filePath,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "success", "data": data})
+}
+
+// queryReferences numbers the cited documents of an answer
+func queryReferences(filePaths []string) []client.QueryReference {
+	references := []client.QueryReference{}
+	for i, filePath := range filePaths {
+		references = append(references, client.QueryReference{ReferenceID: fmt.Sprint(i + 1), FilePath: filePath})
+	}
+	return references
+}
+
+// authorized checks the X-API-Key header if the fake requires an API key
+func (f *FakeLightRAG) authorized(r *http.Request) bool {
+	if f.apiKey == "" {
+		return true
+	}
+	return r.Header.Get("X-API-Key") == f.apiKey
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,111 @@
+package clienttest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// StaticMemorySource is an in-memory client.MemorySource serving fixed memories per context
+type StaticMemorySource struct {
+	mu       sync.RWMutex
+	memories map[string][]models.Memory // context ID -> memories
+	audio    map[string][]byte          // memory ID -> audio data
+	images   map[string][]byte          // memory ID -> image data
+	err      error
+}
+
+var _ client.MemorySource = (*StaticMemorySource)(nil)
+
+// NewStaticMemorySource creates an empty static memory source
+func NewStaticMemorySource() *StaticMemorySource {
+	return &StaticMemorySource{
+		memories: make(map[string][]models.Memory),
+		audio:    make(map[string][]byte),
+		images:   make(map[string][]byte),
+	}
+}
+
+// AddMemories adds memories to a context
+func (s *StaticMemorySource) AddMemories(ctxID string, memories ...models.Memory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.memories[ctxID] = append(s.memories[ctxID], memories...)
+}
+
+// SetAudio sets the audio data returned for a memory
+func (s *StaticMemorySource) SetAudio(memoryID string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.audio[memoryID] = data
+}
+
+// SetImage sets the image data returned for a memory
+func (s *StaticMemorySource) SetImage(memoryID string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.images[memoryID] = data
+}
+
+// SetError makes every subsequent call fail with err (nil to clear)
+func (s *StaticMemorySource) SetError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
+
+// GetMemories returns up to limit memories of a context. The range parameter is ignored.
+func (s *StaticMemorySource) GetMemories(ctx context.Context, ctxID string, limit int, rangeParam string) (*models.MemoryList, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	memories := s.memories[ctxID]
+	if limit > 0 && len(memories) > limit {
+		memories = memories[:limit]
+	}
+
+	result := make([]models.Memory, len(memories))
+	copy(result, memories)
+
+	return &models.MemoryList{
+		Memories: result,
+		Count:    len(result),
+	}, nil
+}
+
+// GetMemoryAudio returns the audio data set with SetAudio
+func (s *StaticMemorySource) GetMemoryAudio(ctx context.Context, ctxID, memoryID string) ([]byte, error) {
+	return s.media(s.audio, "audio", memoryID)
+}
+
+// GetMemoryImage returns the image data set with SetImage
+func (s *StaticMemorySource) GetMemoryImage(ctx context.Context, ctxID, memoryID string) ([]byte, error) {
+	return s.media(s.images, "image", memoryID)
+}
+
+// media looks up media data for a memory
+func (s *StaticMemorySource) media(store map[string][]byte, kind, memoryID string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	data, ok := store[memoryID]
+	if !ok {
+		return nil, fmt.Errorf("no %s for memory %s", kind, memoryID)
+	}
+	return data, nil
+}
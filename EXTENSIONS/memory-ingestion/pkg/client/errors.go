@@ -0,0 +1,48 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Classes of API failures. A *StatusError wraps the class of its status code, so callers can
+// branch with errors.Is(err, ErrRateLimited) and still reach the status and body with errors.As.
+var (
+	ErrUnauthorized  = errors.New("unauthorized")         // 401 and 403: missing, expired, or insufficient credentials
+	ErrRateLimited   = errors.New("rate limited")         // 429
+	ErrUnprocessable = errors.New("request rejected")     // other 4xx: the request fails again until it changes
+	ErrUnavailable   = errors.New("upstream unavailable") // 5xx
+)
+
+// StatusError is returned when an API responds with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// Unwrap returns the class of the status code, nil for statuses outside 4xx and 5xx
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 400 && e.StatusCode < 500:
+		return ErrUnprocessable
+	case e.StatusCode >= 500:
+		return ErrUnavailable
+	default:
+		return nil
+	}
+}
+
+// IsOverloaded returns true if err reports an overloaded API (429 or 5xx)
+func IsOverloaded(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnavailable)
+}
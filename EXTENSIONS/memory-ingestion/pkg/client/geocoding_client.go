@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// geocodingUserAgent identifies the connector, as Nominatim's usage policy requires
+const geocodingUserAgent = "memory-connector (https://github.com/kamir/LightRAG)"
+
+// GeocodingClient names places with a Nominatim-compatible reverse geocoding API
+type GeocodingClient struct {
+	apiURL     string
+	language   string
+	zoom       int
+	httpClient *http.Client
+	logger     *zap.Logger
+}
+
+// GeocodingClientConfig holds configuration for the reverse geocoding API client
+type GeocodingClientConfig struct {
+	APIURL   string // e.g. https://nominatim.openstreetmap.org
+	Language string // preferred language of place names, e.g. en
+	Zoom     int    // detail of place names: 10 (default) names the town, 14 and above also the neighborhood
+	Timeout  time.Duration
+}
+
+// nominatimResponse is the response of Nominatim's /reverse
+type nominatimResponse struct {
+	Name    string            `json:"name"`
+	Address map[string]string `json:"address"`
+	Error   string            `json:"error"`
+}
+
+// NewGeocodingClient creates a new reverse geocoding API client
+func NewGeocodingClient(config GeocodingClientConfig, logger *zap.Logger) *GeocodingClient {
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Zoom == 0 {
+		config.Zoom = 10
+	}
+
+	return &GeocodingClient{
+		apiURL:   strings.TrimSuffix(config.APIURL, "/"),
+		language: config.Language,
+		zoom:     config.Zoom,
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: SharedTransport(),
+		},
+		logger: logger,
+	}
+}
+
+// GeocodedPlace is the place at coordinates
+type GeocodedPlace struct {
+	Name        string // e.g. "Alfama, Lisbon, Portugal"
+	CountryCode string // ISO 3166-1 alpha-2, upper case; empty if unknown
+}
+
+// ReverseGeocode returns the name of the town or city at coordinates and its country, e.g.
+// "Lisbon, Portugal", preceded by the neighborhood at zoom 14 and above, e.g. "Alfama, Lisbon,
+// Portugal"; empty if the API knows no place there
+func (c *GeocodingClient) ReverseGeocode(ctx context.Context, lat, lon float64) (string, error) {
+	place, err := c.ReversePlace(ctx, lat, lon)
+	return place.Name, err
+}
+
+// ReversePlace returns the place at coordinates, named as by ReverseGeocode, with its country
+func (c *GeocodingClient) ReversePlace(ctx context.Context, lat, lon float64) (GeocodedPlace, error) {
+	query := url.Values{}
+	query.Set("format", "jsonv2")
+	query.Set("lat", strconv.FormatFloat(lat, 'f', 4, 64))
+	query.Set("lon", strconv.FormatFloat(lon, 'f', 4, 64))
+	query.Set("zoom", strconv.Itoa(c.zoom))
+	if c.language != "" {
+		query.Set("accept-language", c.language)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"/reverse?"+query.Encode(), nil)
+	if err != nil {
+		return GeocodedPlace{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", geocodingUserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return GeocodedPlace{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return GeocodedPlace{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return GeocodedPlace{}, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var response nominatimResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return GeocodedPlace{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if response.Error != "" {
+		return GeocodedPlace{}, nil // e.g. "Unable to geocode" in the middle of the ocean
+	}
+	countryCode := strings.ToUpper(response.Address["country_code"])
+
+	place := response.Name
+	for _, field := range []string{"city", "town", "village", "municipality", "county", "state"} {
+		if value := response.Address[field]; value != "" {
+			place = value
+			break
+		}
+	}
+	if c.zoom >= 14 {
+		for _, field := range []string{"neighbourhood", "suburb", "quarter", "hamlet"} {
+			if value := response.Address[field]; value != "" && value != place {
+				if place == "" {
+					place = value
+				} else {
+					place = value + ", " + place
+				}
+				break
+			}
+		}
+	}
+	if country := response.Address["country"]; country != "" && country != place {
+		if place == "" {
+			return GeocodedPlace{Name: country, CountryCode: countryCode}, nil
+		}
+		place += ", " + country
+	}
+
+	c.logger.Debug("Reverse geocoded location",
+		zap.Float64("lat", lat),
+		zap.Float64("lon", lon),
+		zap.String("place", place),
+		zap.String("country_code", countryCode),
+	)
+	return GeocodedPlace{Name: place, CountryCode: countryCode}, nil
+}
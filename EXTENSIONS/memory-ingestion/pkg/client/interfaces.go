@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// LightRAGAPI defines the LightRAG operations used by the connector
+type LightRAGAPI interface {
+	// InsertDocument inserts a document into LightRAG
+	InsertDocument(ctx context.Context, text string, metadata map[string]string) (*DocumentResponse, error)
+
+	// HealthCheck checks if the LightRAG API is available
+	HealthCheck(ctx context.Context) error
+
+	// GetKnowledgeGraph returns the subgraph around an entity
+	GetKnowledgeGraph(ctx context.Context, label string, maxDepth, maxNodes int) (*KnowledgeGraph, error)
+
+	// SearchLabels returns entity labels matching a query
+	SearchLabels(ctx context.Context, query string, limit int) ([]string, error)
+
+	// MergeEntities merges entities of the knowledge graph into a target entity
+	MergeEntities(ctx context.Context, sources []string, target string) error
+
+	// Query answers a question from the knowledge graph
+	Query(ctx context.Context, query QueryRequest) (*QueryResponse, error)
+
+	// QueryData returns what the knowledge graph retrieves for a question, without an answer
+	QueryData(ctx context.Context, query QueryRequest) (*QueryData, error)
+
+	// ListDocuments returns a page of documents, oldest first
+	ListDocuments(ctx context.Context, page, pageSize int) (*DocumentPage, error)
+
+	// DeleteDocuments deletes documents and everything extracted from them
+	DeleteDocuments(ctx context.Context, docIDs []string) error
+
+	// GetDocumentStatusCounts returns the number of documents per processing status
+	GetDocumentStatusCounts(ctx context.Context) (map[string]int, error)
+}
+
+// MemorySource defines the Memory API operations used by the connector
+type MemorySource interface {
+	// GetMemories fetches memories for a context
+	GetMemories(ctx context.Context, ctxID string, limit int, rangeParam string) (*models.MemoryList, error)
+
+	// GetMemoryAudio fetches audio data for a specific memory
+	GetMemoryAudio(ctx context.Context, ctxID, memoryID string) ([]byte, error)
+
+	// GetMemoryImage fetches image data for a specific memory
+	GetMemoryImage(ctx context.Context, ctxID, memoryID string) ([]byte, error)
+}
+
+// Translator translates text between languages, identified by ISO 639-1 codes
+type Translator interface {
+	// Translate translates text to the target language; an empty source is detected by the API
+	Translate(ctx context.Context, text, source, target string) (string, error)
+}
+
+// Geocoder names the place at coordinates
+type Geocoder interface {
+	// ReverseGeocode returns a human-readable place name, empty if there's none
+	ReverseGeocode(ctx context.Context, lat, lon float64) (string, error)
+}
+
+// PlaceGeocoder names the place at coordinates and tells its country
+type PlaceGeocoder interface {
+	// ReversePlace returns the place, empty if there's none
+	ReversePlace(ctx context.Context, lat, lon float64) (GeocodedPlace, error)
+}
+
+// TimeZoneResolver looks up the time zone at coordinates
+type TimeZoneResolver interface {
+	// TimeZone returns the IANA name of the time zone, empty if there's none
+	TimeZone(ctx context.Context, lat, lon float64) (string, error)
+}
+
+// Compile-time checks that the HTTP clients satisfy the interfaces
+var (
+	_ LightRAGAPI      = (*LightRAGClient)(nil)
+	_ MemorySource     = (*MemoryClient)(nil)
+	_ Translator       = (*TranslationClient)(nil)
+	_ Geocoder         = (*GeocodingClient)(nil)
+	_ PlaceGeocoder    = (*GeocodingClient)(nil)
+	_ TimeZoneResolver = (*TimeZoneClient)(nil)
+)
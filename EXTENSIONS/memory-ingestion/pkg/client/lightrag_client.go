@@ -3,25 +3,45 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/kamir/memory-connector/pkg/export"
 	"go.uber.org/zap"
 )
 
 // LightRAGClient is a client for the LightRAG API
 type LightRAGClient struct {
-	apiURL          string
-	apiKey          string
-	accessToken     string
-	authConfigured  bool
-	httpClient      *http.Client
-	logger          *zap.Logger
-	maxRetries      int
-	retryDelay      time.Duration
+	apiURL         string
+	accessToken    string
+	authConfigured bool
+	httpClient     *http.Client
+	logger         *zap.Logger
+	maxRetries     int
+	retryDelay     time.Duration
+
+	credentialMu         sync.RWMutex
+	apiKey               string
+	credentialGeneration uint64 // incremented whenever the API key is rotated
+
+	compressMinBytes    int         // gzip insert payloads of at least this size, 0 disables compression
+	compressionRejected atomic.Bool // set once LightRAG failed to decode a compressed insert
+
+	versionMu       sync.Mutex
+	apiVersion      string    // configured API generation, auto to detect it
+	detectedVersion string    // API generation detected from the server's version, empty until detected
+	probedAt        time.Time // last probe of the server's version
 }
 
 // LightRAGClientConfig holds configuration for the LightRAG API client
@@ -31,6 +51,16 @@ type LightRAGClientConfig struct {
 	Timeout    time.Duration
 	MaxRetries int
 	RetryDelay time.Duration
+
+	// CompressMinBytes gzips insert payloads of at least this many bytes (Content-Encoding: gzip),
+	// 0 disables compression. LightRAG itself doesn't decode compressed bodies, a proxy in front of
+	// it has to; if an insert is rejected compressed but accepted uncompressed, compression is
+	// turned off.
+	CompressMinBytes int
+
+	// APIVersion is the API generation of the server (one of the APIVersion constants). auto, the
+	// default, probes the server's version at startup.
+	APIVersion string
 }
 
 // DocumentRequest represents a document submission to LightRAG
@@ -39,11 +69,141 @@ type DocumentRequest struct {
 	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
+// DocumentRequestHash returns the SHA-256 of the JSON body InsertDocument sends for a document, hex
+// encoded and before compression, for receipts of what was ingested
+func DocumentRequestHash(text string, metadata map[string]string) string {
+	data, _ := json.Marshal(DocumentRequest{Text: text, Metadata: metadata}) // a DocumentRequest always encodes
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // DocumentResponse represents the response from LightRAG
 type DocumentResponse struct {
-	Status  string `json:"status"`
+	Status  string `json:"status"` // one of the InsertStatus constants
 	Message string `json:"message,omitempty"`
-	DocID   string `json:"doc_id,omitempty"`
+	DocID   string `json:"doc_id,omitempty"`   // returned by older LightRAG versions only
+	TrackID string `json:"track_id,omitempty"` // follows the document through LightRAG's pipeline
+}
+
+// Statuses of a document insert
+const (
+	InsertStatusSuccess        = "success"
+	InsertStatusDuplicated     = "duplicated" // LightRAG already holds a document of the same text
+	InsertStatusPartialSuccess = "partial_success"
+	InsertStatusFailure        = "failure"
+)
+
+// IdempotencyKeyHeader carries the document ID of an insert, so that retries of an insert that
+// reached the server are deduplicated by servers that support idempotency keys
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// KnowledgeGraph is a subgraph of LightRAG's entity/relationship graph (response of /graphs)
+type KnowledgeGraph struct {
+	Nodes       []GraphNode `json:"nodes"`
+	Edges       []GraphEdge `json:"edges"`
+	IsTruncated bool        `json:"is_truncated"`
+}
+
+// GraphNode is an entity of the knowledge graph. Properties include entity_type, description, and source_id.
+type GraphNode struct {
+	ID         string                 `json:"id"`
+	Labels     []string               `json:"labels"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GraphEdge is a relationship between two entities. Properties include description, keywords, and weight.
+type GraphEdge struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type,omitempty"`
+	Source     string                 `json:"source"`
+	Target     string                 `json:"target"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// EntityMergeRequest merges entities of the knowledge graph into one (POST /graph/entities/merge)
+type EntityMergeRequest struct {
+	EntitiesToChange   []string `json:"entities_to_change"`    // merged into the target and deleted
+	EntityToChangeInto string   `json:"entity_to_change_into"` // must exist, keeps its name
+}
+
+// QueryRequest asks LightRAG a question (POST /query)
+type QueryRequest struct {
+	Query             string `json:"query"`
+	Mode              string `json:"mode,omitempty"` // local, global, hybrid, naive, mix (LightRAG's default), or bypass
+	TopK              int    `json:"top_k,omitempty"`
+	IncludeReferences bool   `json:"include_references"` // ignored by releases before references were returned
+}
+
+// QueryResponse is LightRAG's answer to a question
+type QueryResponse struct {
+	Response   string           `json:"response"`
+	References []QueryReference `json:"references,omitempty"` // missing in releases before 1.4.9
+}
+
+// QueryReference is a document an answer draws on
+type QueryReference struct {
+	ReferenceID string `json:"reference_id"` // as cited in the answer text, e.g. [1]
+	FilePath    string `json:"file_path"`    // the memory URI or short citation of the connector's documents
+}
+
+// QueryData is what LightRAG retrieves to answer a question, without the answer (POST /query/data,
+// LightRAG 1.4.9 and later)
+type QueryData struct {
+	Entities []QueryEntity `json:"entities"`
+}
+
+// QueryEntity is an entity of the knowledge graph retrieved for a question
+type QueryEntity struct {
+	EntityName  string `json:"entity_name"`
+	EntityType  string `json:"entity_type"`
+	Description string `json:"description"`
+	FilePath    string `json:"file_path"` // of the documents it was extracted from, joined by <SEP>
+}
+
+// DocumentsRequest requests a page of LightRAG's documents (POST /documents/paginated)
+type DocumentsRequest struct {
+	Page          int    `json:"page"`      // 1-based
+	PageSize      int    `json:"page_size"` // 10 to 200
+	SortField     string `json:"sort_field,omitempty"`
+	SortDirection string `json:"sort_direction,omitempty"`
+}
+
+// DocumentStatus is a document of LightRAG with its processing status
+type DocumentStatus struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	FilePath  string `json:"file_path"` // the memory URI or short citation of the connector's documents
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// DocumentPage is a page of LightRAG's documents
+type DocumentPage struct {
+	Documents  []DocumentStatus `json:"documents"`
+	Pagination struct {
+		Page       int  `json:"page"`
+		TotalCount int  `json:"total_count"`
+		TotalPages int  `json:"total_pages"`
+		HasNext    bool `json:"has_next"`
+	} `json:"pagination"`
+}
+
+// DeleteDocumentsRequest deletes documents with their chunks, entities, and relationships
+// (DELETE /documents/delete_document)
+type DeleteDocumentsRequest struct {
+	DocIDs         []string `json:"doc_ids"`
+	DeleteLLMCache bool     `json:"delete_llm_cache"` // so re-inserted text is extracted again
+}
+
+// DeleteDocumentsResponse represents the response from /documents/delete_document
+type DeleteDocumentsResponse struct {
+	Status  string `json:"status"` // deletion_started, busy, or not_allowed
+	Message string `json:"message"`
+}
+
+// StatusCountsResponse represents the response from /documents/status_counts
+type StatusCountsResponse struct {
+	StatusCounts map[string]int `json:"status_counts"` // document status (PENDING, PROCESSING, ...) -> count
 }
 
 // AuthStatusResponse represents the response from /auth-status endpoint
@@ -66,16 +226,22 @@ func NewLightRAGClient(config LightRAGClientConfig, logger *zap.Logger) *LightRA
 	if config.RetryDelay == 0 {
 		config.RetryDelay = 2 * time.Second
 	}
+	if config.APIVersion == "" {
+		config.APIVersion = APIVersionAuto
+	}
 
 	client := &LightRAGClient{
 		apiURL: config.APIURL,
 		apiKey: config.APIKey,
 		httpClient: &http.Client{
-			Timeout: config.Timeout,
+			Timeout:   config.Timeout,
+			Transport: SharedTransport(),
 		},
-		logger:     logger,
-		maxRetries: config.MaxRetries,
-		retryDelay: config.RetryDelay,
+		logger:           logger,
+		maxRetries:       config.MaxRetries,
+		retryDelay:       config.RetryDelay,
+		compressMinBytes: config.CompressMinBytes,
+		apiVersion:       config.APIVersion,
 	}
 
 	// If no API key is configured, fetch guest access token from auth-status
@@ -90,12 +256,20 @@ func NewLightRAGClient(config LightRAGClientConfig, logger *zap.Logger) *LightRA
 		}
 	}
 
+	// Detect the server's API generation, with the access token fetched above
+	if config.APIVersion == APIVersionAuto {
+		client.api(context.Background())
+	}
+
 	return client
 }
 
-// InsertDocument inserts a document into LightRAG
+// InsertDocument inserts a document into LightRAG. The request carries the document's ID as its
+// Idempotency-Key, so a server (or proxy) that supports idempotency keys ingests it once even if
+// an attempt that timed out reached it, and the insert is retried.
 func (c *LightRAGClient) InsertDocument(ctx context.Context, text string, metadata map[string]string) (*DocumentResponse, error) {
 	url := fmt.Sprintf("%s/documents/text", c.apiURL)
+	idempotencyKey := export.DocumentID(text)
 
 	docReq := DocumentRequest{
 		Text:     text,
@@ -105,23 +279,153 @@ func (c *LightRAGClient) InsertDocument(ctx context.Context, text string, metada
 	c.logger.Debug("Inserting document",
 		zap.String("url", url),
 		zap.Int("text_length", len(text)),
+		zap.String("idempotency_key", idempotencyKey),
 		zap.Any("metadata", metadata),
 	)
 
 	var docResp DocumentResponse
-	err := c.doRequestWithRetry(ctx, "POST", url, docReq, &docResp)
+	err := c.doRequest(ctx, "POST", url, docReq, &docResp, c.compressMinBytes > 0, idempotencyKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert document: %w", err)
 	}
+	// LightRAG answers 200 for documents it didn't take too
+	if docResp.Status == InsertStatusFailure {
+		return nil, fmt.Errorf("failed to insert document: %w: %s", ErrUnprocessable, docResp.Message)
+	}
 
 	c.logger.Info("Successfully inserted document",
 		zap.String("status", docResp.Status),
 		zap.String("doc_id", docResp.DocID),
+		zap.String("track_id", docResp.TrackID),
 	)
 
 	return &docResp, nil
 }
 
+// GetKnowledgeGraph returns the subgraph around the entity with the given label, up to maxDepth hops and maxNodes entities
+func (c *LightRAGClient) GetKnowledgeGraph(ctx context.Context, label string, maxDepth, maxNodes int) (*KnowledgeGraph, error) {
+	query := url.Values{}
+	query.Set("label", label)
+	query.Set("max_depth", strconv.Itoa(maxDepth))
+	query.Set("max_nodes", strconv.Itoa(maxNodes))
+	graphURL := fmt.Sprintf("%s/graphs?%s", c.apiURL, query.Encode())
+
+	var graph KnowledgeGraph
+	if err := c.doRequestWithRetry(ctx, "GET", graphURL, nil, &graph); err != nil {
+		return nil, fmt.Errorf("failed to get knowledge graph: %w", err)
+	}
+
+	return &graph, nil
+}
+
+// SearchLabels returns entity labels matching query (fuzzy, most relevant first)
+func (c *LightRAGClient) SearchLabels(ctx context.Context, query string, limit int) ([]string, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("limit", strconv.Itoa(limit))
+	searchURL := fmt.Sprintf("%s/graph/label/search?%s", c.apiURL, params.Encode())
+
+	labels := []string{}
+	if err := c.doRequestWithRetry(ctx, "GET", searchURL, nil, &labels); err != nil {
+		return nil, fmt.Errorf("failed to search labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+// MergeEntities merges the source entities into the target entity, transferring their
+// relationships. The sources are deleted; this can't be undone.
+func (c *LightRAGClient) MergeEntities(ctx context.Context, sources []string, target string) error {
+	mergeURL := fmt.Sprintf("%s/graph/entities/merge", c.apiURL)
+
+	mergeReq := EntityMergeRequest{EntitiesToChange: sources, EntityToChangeInto: target}
+	if err := c.doRequestWithRetry(ctx, "POST", mergeURL, mergeReq, nil); err != nil {
+		return fmt.Errorf("failed to merge entities into %q: %w", target, err)
+	}
+
+	c.logger.Info("Merged entities",
+		zap.Strings("sources", sources),
+		zap.String("target", target),
+	)
+
+	return nil
+}
+
+// Query asks LightRAG a question about the knowledge graph and returns its answer
+func (c *LightRAGClient) Query(ctx context.Context, query QueryRequest) (*QueryResponse, error) {
+	var answer QueryResponse
+	if err := c.doRequestWithRetry(ctx, "POST", c.apiURL+"/query", query, &answer); err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+
+	return &answer, nil
+}
+
+// QueryData returns the entities LightRAG retrieves to answer a question, without generating the answer
+func (c *LightRAGClient) QueryData(ctx context.Context, query QueryRequest) (*QueryData, error) {
+	var dataResp struct {
+		Status  string    `json:"status"`
+		Message string    `json:"message"`
+		Data    QueryData `json:"data"`
+	}
+	if err := c.doRequestWithRetry(ctx, "POST", c.apiURL+"/query/data", query, &dataResp); err != nil {
+		return nil, fmt.Errorf("failed to query data: %w", err)
+	}
+	if dataResp.Status == "failure" {
+		return nil, fmt.Errorf("failed to query data: %w: %s", ErrUnprocessable, dataResp.Message)
+	}
+
+	return &dataResp.Data, nil
+}
+
+// ListDocuments returns a page of LightRAG's documents, oldest first
+func (c *LightRAGClient) ListDocuments(ctx context.Context, page, pageSize int) (*DocumentPage, error) {
+	documents, err := c.api(ctx).listDocuments(ctx, c, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	return documents, nil
+}
+
+// DeleteDocuments deletes documents and everything extracted from them only, including their cached
+// LLM results. LightRAG deletes them in the background; it refuses while its pipeline is busy.
+func (c *LightRAGClient) DeleteDocuments(ctx context.Context, docIDs []string) error {
+	deleteURL := fmt.Sprintf("%s/documents/delete_document", c.apiURL)
+
+	deleteReq := DeleteDocumentsRequest{DocIDs: docIDs, DeleteLLMCache: true}
+	var deleteResp DeleteDocumentsResponse
+	if err := c.doRequestWithRetry(ctx, "DELETE", deleteURL, deleteReq, &deleteResp); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+	if deleteResp.Status != "deletion_started" {
+		return fmt.Errorf("failed to delete documents: %s: %s", deleteResp.Status, deleteResp.Message)
+	}
+
+	c.logger.Info("Deleting documents", zap.Strings("doc_ids", docIDs))
+
+	return nil
+}
+
+// QueuedDocumentStatuses are the document statuses that make up LightRAG's processing queue
+var QueuedDocumentStatuses = []string{"PENDING", "PROCESSING", "PREPROCESSED"}
+
+// GetDocumentStatusCounts returns the number of documents per processing status (PENDING, PROCESSING,
+// PREPROCESSED, PROCESSED, FAILED). Status names are upper-case as in LightRAG's API docs.
+func (c *LightRAGClient) GetDocumentStatusCounts(ctx context.Context) (map[string]int, error) {
+	statusCounts, err := c.api(ctx).documentStatusCounts(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document status counts: %w", err)
+	}
+
+	counts := make(map[string]int, len(statusCounts))
+	for status, count := range statusCounts {
+		counts[strings.ToUpper(status)] += count
+	}
+
+	return counts, nil
+}
+
 // fetchAuthStatus fetches the authentication status and access token
 func (c *LightRAGClient) fetchAuthStatus(ctx context.Context) error {
 	url := fmt.Sprintf("%s/auth-status", c.apiURL)
@@ -143,7 +447,7 @@ func (c *LightRAGClient) fetchAuthStatus(ctx context.Context) error {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("auth-status returned status %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("auth-status request failed: %w", &StatusError{StatusCode: resp.StatusCode, Body: string(body)})
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -195,7 +499,7 @@ func (c *LightRAGClient) HealthCheck(ctx context.Context) error {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("health check returned status %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("health check failed: %w", &StatusError{StatusCode: resp.StatusCode, Body: string(body)})
 	}
 
 	c.logger.Info("LightRAG API is healthy")
@@ -204,6 +508,34 @@ func (c *LightRAGClient) HealthCheck(ctx context.Context) error {
 
 // doRequestWithRetry performs an HTTP request with retry logic
 func (c *LightRAGClient) doRequestWithRetry(ctx context.Context, method, url string, requestBody interface{}, result interface{}) error {
+	return c.doRequest(ctx, method, url, requestBody, result, false, "")
+}
+
+// doRequest performs an HTTP request with retry logic, gzipping a large enough request body if
+// compress is set and LightRAG hasn't rejected compressed bodies. Once ctx is done, its error is
+// returned instead of the failed attempt's, and no more attempts are made.
+func (c *LightRAGClient) doRequest(ctx context.Context, method, url string, requestBody interface{}, result interface{}, compress bool, idempotencyKey string) error {
+	// Marshal request body
+	var bodyBytes []byte
+	if requestBody != nil {
+		var err error
+		bodyBytes, err = json.Marshal(requestBody)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+
+	payload, contentEncoding := bodyBytes, ""
+	if compress && len(bodyBytes) >= c.compressMinBytes && !c.compressionRejected.Load() {
+		compressed, err := gzipBytes(bodyBytes)
+		if err != nil {
+			return fmt.Errorf("failed to compress request body: %w", err)
+		}
+		payload, contentEncoding = compressed, "gzip"
+	}
+	uncompressedRetry := false
+	rotatedRetry := false
+
 	var lastErr error
 
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
@@ -213,17 +545,14 @@ func (c *LightRAGClient) doRequestWithRetry(ctx context.Context, method, url str
 				zap.Int("attempt", attempt),
 				zap.Int("max_retries", c.maxRetries),
 			)
-			time.Sleep(c.retryDelay * time.Duration(attempt))
+			if err := waitRetry(ctx, c.retryDelay*time.Duration(attempt)); err != nil {
+				return err
+			}
 		}
 
-		// Marshal request body
 		var bodyReader io.Reader
 		if requestBody != nil {
-			bodyBytes, err := json.Marshal(requestBody)
-			if err != nil {
-				return fmt.Errorf("failed to marshal request body: %w", err)
-			}
-			bodyReader = bytes.NewReader(bodyBytes)
+			bodyReader = bytes.NewReader(payload)
 		}
 
 		req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
@@ -233,13 +562,39 @@ func (c *LightRAGClient) doRequestWithRetry(ctx context.Context, method, url str
 
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Accept", "application/json")
+		if contentEncoding != "" {
+			req.Header.Set("Content-Encoding", contentEncoding)
+		}
+		if idempotencyKey != "" {
+			// The same key on every attempt, so retries are recognized as such
+			req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+		}
 
 		// Add authentication header
-		c.setAuthHeader(req)
+		generation := c.setAuthHeader(req)
+
+		body, err := send(c.httpClient, req)
+		if err == nil {
+			if uncompressedRetry && !c.compressionRejected.Swap(true) {
+				c.logger.Warn("LightRAG rejected a gzip-compressed request body, sending uncompressed bodies from now on",
+					zap.String("url", url),
+				)
+			}
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			lastErr = err
+			if result != nil {
+				if err := json.Unmarshal(body, result); err != nil {
+					return fmt.Errorf("failed to unmarshal response: %w", err)
+				}
+			}
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		lastErr = err
+
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) {
 			c.logger.Warn("Request failed",
 				zap.String("url", url),
 				zap.Error(err),
@@ -247,50 +602,73 @@ func (c *LightRAGClient) doRequestWithRetry(ctx context.Context, method, url str
 			continue
 		}
 
-		defer resp.Body.Close()
-
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			body, _ := io.ReadAll(resp.Body)
-			lastErr = fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-
-			// Don't retry on 4xx errors (client errors)
-			if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-				return lastErr
-			}
-
-			c.logger.Warn("Non-success status code",
-				zap.Int("status_code", resp.StatusCode),
-				zap.String("body", string(body)),
-			)
+		// A server that can't decode the compressed body answers 415, or 422 if it parsed
+		// it as JSON: repeat the attempt uncompressed, without counting it as a retry
+		if contentEncoding != "" &&
+			(statusErr.StatusCode == http.StatusUnsupportedMediaType || statusErr.StatusCode == http.StatusUnprocessableEntity) {
+			payload, contentEncoding = bodyBytes, ""
+			uncompressedRetry = true
+			attempt--
 			continue
 		}
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to read response body: %w", err)
+		// The API key was rotated while the request was in flight: repeat it once with the new key,
+		// without counting it as a retry
+		if errors.Is(err, ErrUnauthorized) && !rotatedRetry && c.credentialGenerationNow() != generation {
+			rotatedRetry = true
+			attempt--
 			continue
 		}
 
-		if result != nil {
-			err = json.Unmarshal(body, result)
-			if err != nil {
-				return fmt.Errorf("failed to unmarshal response: %w", err)
-			}
+		// With an idempotency key, 409 means an earlier attempt is still being processed: wait
+		// for it like for a 5xx
+		conflict := idempotencyKey != "" && statusErr.StatusCode == http.StatusConflict
+
+		// Only retry 5xx errors, the others fail again
+		if !errors.Is(err, ErrUnavailable) && !conflict {
+			return err
 		}
 
-		return nil
+		c.logger.Warn("Non-success status code",
+			zap.Int("status_code", statusErr.StatusCode),
+			zap.String("body", statusErr.Body),
+		)
 	}
 
 	return fmt.Errorf("request failed after %d retries: %w", c.maxRetries, lastErr)
 }
 
-// setAuthHeader sets the appropriate authentication header on the request
-func (c *LightRAGClient) setAuthHeader(req *http.Request) {
-	if c.apiKey != "" {
+// setAuthHeader sets the appropriate authentication header on the request and returns the
+// generation of the API key it used
+func (c *LightRAGClient) setAuthHeader(req *http.Request) uint64 {
+	c.credentialMu.RLock()
+	apiKey, generation := c.apiKey, c.credentialGeneration
+	c.credentialMu.RUnlock()
+
+	if apiKey != "" {
 		// Use X-API-Key if explicitly configured
-		req.Header.Set("X-API-Key", c.apiKey)
+		req.Header.Set("X-API-Key", apiKey)
 	} else if c.accessToken != "" {
 		// Use Bearer token from auth-status (guest access or authenticated)
 		req.Header.Set("Authorization", "Bearer "+c.accessToken)
 	}
+	return generation
+}
+
+// SetAPIKey replaces the API key at runtime, when it was rotated. Requests sent from now on use
+// it; requests in flight that LightRAG rejects with the old key are repeated with it.
+func (c *LightRAGClient) SetAPIKey(apiKey string) {
+	c.credentialMu.Lock()
+	defer c.credentialMu.Unlock()
+
+	c.apiKey = apiKey
+	c.credentialGeneration++
+}
+
+// credentialGenerationNow returns the generation of the current API key
+func (c *LightRAGClient) credentialGenerationNow() uint64 {
+	c.credentialMu.RLock()
+	defer c.credentialMu.RUnlock()
+
+	return c.credentialGeneration
 }
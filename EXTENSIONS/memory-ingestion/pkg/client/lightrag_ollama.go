@@ -0,0 +1,40 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ForwardOllama sends a request to LightRAG's Ollama-compatible API (path below /api/, e.g.
+// /api/chat) and returns its response unread, whatever its status, so streamed answers can be
+// relayed as they arrive. The request is sent once and bounded by ctx only, not the client timeout,
+// since answers may stream for minutes. The caller closes the response body.
+func (c *LightRAGClient) ForwardOllama(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	if !strings.HasPrefix(path, "/api/") {
+		return nil, fmt.Errorf("not an Ollama API path: %s", path)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Ollama request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.setAuthHeader(req)
+
+	streamingClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamingClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to the Ollama API failed: %w", err)
+	}
+	return resp, nil
+}
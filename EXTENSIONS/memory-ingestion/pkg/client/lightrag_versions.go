@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// API generations of LightRAG servers, and auto to detect the server's at startup
+const (
+	APIVersionAuto    = "auto"
+	APIVersionCurrent = "current" // LightRAG 1.4 and later
+	APIVersionLegacy  = "legacy"  // before 1.4: no paginated document list or status counts
+)
+
+// reprobeInterval is how long the client waits to probe the server's version again after a probe failed
+const reprobeInterval = time.Minute
+
+// HealthResponse is the part of LightRAG's /health response the client reads
+type HealthResponse struct {
+	Status      string `json:"status"`
+	CoreVersion string `json:"core_version,omitempty"` // LightRAG's release, e.g. 1.4.6; missing in early releases
+	APIVersion  string `json:"api_version,omitempty"`
+}
+
+// lightragAPI maps the client's requests to the endpoints of a LightRAG API generation
+type lightragAPI interface {
+	listDocuments(ctx context.Context, c *LightRAGClient, page, pageSize int) (*DocumentPage, error)
+	documentStatusCounts(ctx context.Context, c *LightRAGClient) (map[string]int, error)
+}
+
+// apiFor returns the adapter of an API generation
+func apiFor(version string) lightragAPI {
+	if version == APIVersionLegacy {
+		return legacyAPI{}
+	}
+	return currentAPI{}
+}
+
+// api returns the adapter of the server's API generation. Unless the generation is configured, the
+// server's version is probed on first use; if the probe fails, the current generation is assumed
+// and the probe is repeated after reprobeInterval.
+func (c *LightRAGClient) api(ctx context.Context) lightragAPI {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+
+	if c.apiVersion != APIVersionAuto {
+		return apiFor(c.apiVersion)
+	}
+	if c.detectedVersion != "" {
+		return apiFor(c.detectedVersion)
+	}
+	if time.Since(c.probedAt) < reprobeInterval {
+		return currentAPI{}
+	}
+
+	c.probedAt = time.Now()
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	health, err := c.fetchHealth(ctx)
+	if err != nil {
+		c.logger.Warn("Failed to probe the LightRAG version, assuming the current API",
+			zap.Error(err),
+		)
+		return currentAPI{}
+	}
+
+	c.detectedVersion = apiVersionOf(health.CoreVersion)
+	c.logger.Info("Detected LightRAG version",
+		zap.String("core_version", health.CoreVersion),
+		zap.String("api_version", health.APIVersion),
+		zap.String("api", c.detectedVersion),
+	)
+	return apiFor(c.detectedVersion)
+}
+
+// APIVersion returns the API generation the client uses: the configured one, or the one detected
+// from the server's version, empty while undetected
+func (c *LightRAGClient) APIVersion() string {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+
+	if c.apiVersion != APIVersionAuto {
+		return c.apiVersion
+	}
+	return c.detectedVersion
+}
+
+// apiVersionOf returns the API generation of a LightRAG release. Releases that don't report their
+// version predate 1.4.
+func apiVersionOf(coreVersion string) string {
+	parts := strings.SplitN(strings.TrimPrefix(coreVersion, "v"), ".", 3)
+	if len(parts) < 2 {
+		return APIVersionLegacy
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return APIVersionLegacy
+	}
+	minor, _ := strconv.Atoi(leadingDigits(parts[1]))
+	if major > 1 || major == 1 && minor >= 4 {
+		return APIVersionCurrent
+	}
+	return APIVersionLegacy
+}
+
+// leadingDigits returns the digits s starts with, e.g. "4" of "4rc1"
+func leadingDigits(s string) string {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	return s[:end]
+}
+
+// fetchHealth requests /health once, without retries
+func (c *LightRAGClient) fetchHealth(ctx context.Context) (*HealthResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.apiURL+"/health", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create health request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	c.setAuthHeader(req)
+
+	body, err := send(c.httpClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("health request failed: %w", err)
+	}
+
+	var health HealthResponse
+	if err := json.Unmarshal(body, &health); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal health response: %w", err)
+	}
+	return &health, nil
+}
+
+// currentAPI serves the paginated document list and status counts of LightRAG 1.4 and later
+type currentAPI struct{}
+
+func (currentAPI) listDocuments(ctx context.Context, c *LightRAGClient, page, pageSize int) (*DocumentPage, error) {
+	listReq := DocumentsRequest{Page: page, PageSize: pageSize, SortField: "created_at", SortDirection: "asc"}
+	var documents DocumentPage
+	if err := c.doRequestWithRetry(ctx, "POST", c.apiURL+"/documents/paginated", listReq, &documents); err != nil {
+		return nil, err
+	}
+	return &documents, nil
+}
+
+func (currentAPI) documentStatusCounts(ctx context.Context, c *LightRAGClient) (map[string]int, error) {
+	var countsResp StatusCountsResponse
+	if err := c.doRequestWithRetry(ctx, "GET", c.apiURL+"/documents/status_counts", nil, &countsResp); err != nil {
+		return nil, err
+	}
+	return countsResp.StatusCounts, nil
+}
+
+// legacyAPI serves both from the complete document list of earlier releases (GET /documents), so
+// each page and count fetches all documents
+type legacyAPI struct{}
+
+// legacyDocumentsResponse is the response of GET /documents before LightRAG 1.4
+type legacyDocumentsResponse struct {
+	Statuses map[string][]DocumentStatus `json:"statuses"` // document status -> documents
+}
+
+func (legacyAPI) fetchDocuments(ctx context.Context, c *LightRAGClient) (*legacyDocumentsResponse, error) {
+	var documents legacyDocumentsResponse
+	if err := c.doRequestWithRetry(ctx, "GET", c.apiURL+"/documents", nil, &documents); err != nil {
+		return nil, err
+	}
+	return &documents, nil
+}
+
+func (api legacyAPI) listDocuments(ctx context.Context, c *LightRAGClient, page, pageSize int) (*DocumentPage, error) {
+	documents, err := api.fetchDocuments(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []DocumentStatus
+	for status, group := range documents.Statuses {
+		for _, document := range group {
+			if document.Status == "" {
+				document.Status = status
+			}
+			all = append(all, document)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].CreatedAt != all[j].CreatedAt {
+			return all[i].CreatedAt < all[j].CreatedAt
+		}
+		return all[i].ID < all[j].ID
+	})
+
+	result := &DocumentPage{Documents: []DocumentStatus{}}
+	start := min((page-1)*pageSize, len(all))
+	end := min(start+pageSize, len(all))
+	result.Documents = append(result.Documents, all[start:end]...)
+	result.Pagination.Page = page
+	result.Pagination.TotalCount = len(all)
+	result.Pagination.TotalPages = (len(all) + pageSize - 1) / pageSize
+	result.Pagination.HasNext = end < len(all)
+	return result, nil
+}
+
+func (api legacyAPI) documentStatusCounts(ctx context.Context, c *LightRAGClient) (map[string]int, error) {
+	documents, err := api.fetchDocuments(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(documents.Statuses))
+	for status, group := range documents.Statuses {
+		counts[status] += len(group)
+	}
+	return counts, nil
+}
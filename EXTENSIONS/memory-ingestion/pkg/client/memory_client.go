@@ -3,10 +3,11 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/kamir/memory-connector/pkg/models"
@@ -24,17 +25,22 @@ func min(a, b int) int {
 // MemoryClient is a client for the Memory API
 type MemoryClient struct {
 	apiURL     string
-	apiKey     string
 	httpClient *http.Client
 	logger     *zap.Logger
 	maxRetries int
 	retryDelay time.Duration
+
+	credentialMu         sync.RWMutex
+	apiKey               string
+	tokens               *tokenSource // nil unless OAuth2 is configured
+	credentialGeneration uint64       // incremented whenever the credentials are rotated
 }
 
 // MemoryClientConfig holds configuration for the Memory API client
 type MemoryClientConfig struct {
 	APIURL     string
 	APIKey     string
+	OAuth2     *OAuth2Config // Optional, takes precedence over APIKey
 	Timeout    time.Duration
 	MaxRetries int
 	RetryDelay time.Duration
@@ -52,16 +58,53 @@ func NewMemoryClient(config MemoryClientConfig, logger *zap.Logger) *MemoryClien
 		config.RetryDelay = 2 * time.Second
 	}
 
-	return &MemoryClient{
-		apiURL: config.APIURL,
-		apiKey: config.APIKey,
-		httpClient: &http.Client{
-			Timeout: config.Timeout,
-		},
+	httpClient := &http.Client{
+		Timeout:   config.Timeout,
+		Transport: SharedTransport(),
+	}
+
+	c := &MemoryClient{
+		apiURL:     config.APIURL,
+		apiKey:     config.APIKey,
+		httpClient: httpClient,
 		logger:     logger,
 		maxRetries: config.MaxRetries,
 		retryDelay: config.RetryDelay,
 	}
+
+	if config.OAuth2 != nil && config.OAuth2.TokenURL != "" {
+		c.tokens = newTokenSource(*config.OAuth2, httpClient, logger)
+		logger.Info("Memory API client uses OAuth2 authentication",
+			zap.String("token_url", config.OAuth2.TokenURL),
+		)
+	}
+
+	return c
+}
+
+// SetCredentials replaces the API key and OAuth2 settings at runtime, when they were rotated.
+// OAuth2 clients authenticate again with the new settings. Requests sent from now on use them;
+// requests in flight that the Memory API rejects with the old ones are repeated with them.
+func (c *MemoryClient) SetCredentials(apiKey string, oauth2 *OAuth2Config) {
+	var tokens *tokenSource
+	if oauth2 != nil && oauth2.TokenURL != "" {
+		tokens = newTokenSource(*oauth2, c.httpClient, c.logger)
+	}
+
+	c.credentialMu.Lock()
+	defer c.credentialMu.Unlock()
+
+	c.apiKey = apiKey
+	c.tokens = tokens
+	c.credentialGeneration++
+}
+
+// credentials returns the current API key, token source, and their generation
+func (c *MemoryClient) credentials() (string, *tokenSource, uint64) {
+	c.credentialMu.RLock()
+	defer c.credentialMu.RUnlock()
+
+	return c.apiKey, c.tokens, c.credentialGeneration
 }
 
 // GetMemories fetches memories from the Memory API
@@ -104,7 +147,7 @@ func (c *MemoryClient) GetMemoryAudio(ctx context.Context, ctxID, memoryID strin
 		zap.String("memory_id", memoryID),
 	)
 
-	return c.doRawRequestWithRetry(ctx, "GET", url)
+	return c.doRawRequestWithRetry(ctx, "GET", url, "")
 }
 
 // GetMemoryImage fetches image data for a specific memory
@@ -116,12 +159,39 @@ func (c *MemoryClient) GetMemoryImage(ctx context.Context, ctxID, memoryID strin
 		zap.String("memory_id", memoryID),
 	)
 
-	return c.doRawRequestWithRetry(ctx, "GET", url)
+	return c.doRawRequestWithRetry(ctx, "GET", url, "")
 }
 
 // doRequestWithRetry performs an HTTP request with retry logic and JSON unmarshaling
 func (c *MemoryClient) doRequestWithRetry(ctx context.Context, method, url string, result interface{}) error {
+	body, err := c.doRawRequestWithRetry(ctx, method, url, "application/json")
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("Received HTTP response",
+		zap.Int("body_length", len(body)),
+		zap.String("body_preview", string(body[:min(200, len(body))])),
+	)
+
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		c.logger.Error("Failed to unmarshal JSON response",
+			zap.Error(err),
+			zap.String("body", string(body)),
+		)
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+// doRawRequestWithRetry performs an HTTP request with retry logic and returns raw bytes. Once ctx
+// is done, its error is returned instead of the failed attempt's, and no more attempts are made.
+func (c *MemoryClient) doRawRequestWithRetry(ctx context.Context, method, url, accept string) ([]byte, error) {
 	var lastErr error
+	reauthenticated := false
+	rotatedRetry := false
 
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
 		if attempt > 0 {
@@ -130,26 +200,41 @@ func (c *MemoryClient) doRequestWithRetry(ctx context.Context, method, url strin
 				zap.Int("attempt", attempt),
 				zap.Int("max_retries", c.maxRetries),
 			)
-			time.Sleep(c.retryDelay * time.Duration(attempt))
+			if err := waitRetry(ctx, c.retryDelay*time.Duration(attempt)); err != nil {
+				return nil, err
+			}
 		}
 
 		req, err := http.NewRequestWithContext(ctx, method, url, nil)
 		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
+			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
 
-		req.Header.Set("X-API-KEY", c.apiKey)
-		req.Header.Set("Accept", "application/json")
+		apiKey, tokens, generation := c.credentials()
+		if err := setMemoryAuthHeader(ctx, req, apiKey, tokens); err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
 
 		c.logger.Info("Sending HTTP request",
 			zap.String("method", method),
 			zap.String("url", url),
-			zap.String("api_key_prefix", c.apiKey[:min(8, len(c.apiKey))]+"..."),
+			zap.String("api_key_prefix", apiKey[:min(8, len(apiKey))]+"..."),
 		)
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			lastErr = err
+		body, err := send(c.httpClient, req)
+		if err == nil {
+			return body, nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		lastErr = err
+
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) {
 			c.logger.Warn("Request failed",
 				zap.String("url", url),
 				zap.Error(err),
@@ -157,98 +242,49 @@ func (c *MemoryClient) doRequestWithRetry(ctx context.Context, method, url strin
 			continue
 		}
 
-		defer resp.Body.Close()
-
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			body, _ := io.ReadAll(resp.Body)
-			lastErr = fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-
-			// Don't retry on 4xx errors (client errors)
-			if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-				return lastErr
+		// The credentials were rotated while the request was in flight: repeat it once with the new
+		// ones, without counting it as a retry
+		if errors.Is(err, ErrUnauthorized) && !rotatedRetry {
+			if _, _, current := c.credentials(); current != generation {
+				rotatedRetry = true
+				attempt--
+				continue
 			}
-
-			c.logger.Warn("Non-success status code",
-				zap.Int("status_code", resp.StatusCode),
-				zap.String("body", string(body)),
-			)
-			continue
 		}
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to read response body: %w", err)
+		// The access token may have expired mid-run; refresh it once and retry
+		if errors.Is(err, ErrUnauthorized) && tokens != nil && !reauthenticated {
+			reauthenticated = true
+			tokens.Invalidate()
+			c.logger.Warn("Access token rejected, refreshing", zap.String("url", url))
 			continue
 		}
 
-		c.logger.Info("Received HTTP response",
-			zap.Int("status_code", resp.StatusCode),
-			zap.Int("body_length", len(body)),
-			zap.String("body_preview", string(body[:min(200, len(body))])),
-		)
-
-		err = json.Unmarshal(body, result)
-		if err != nil {
-			c.logger.Error("Failed to unmarshal JSON response",
-				zap.Error(err),
-				zap.String("body", string(body)),
-			)
-			return fmt.Errorf("failed to unmarshal response: %w", err)
+		// Only retry 5xx errors, the others fail again
+		if !errors.Is(err, ErrUnavailable) {
+			return nil, err
 		}
 
-		return nil
+		c.logger.Warn("Non-success status code",
+			zap.Int("status_code", statusErr.StatusCode),
+			zap.String("body", statusErr.Body),
+		)
 	}
 
-	return fmt.Errorf("request failed after %d retries: %w", c.maxRetries, lastErr)
+	return nil, fmt.Errorf("request failed after %d retries: %w", c.maxRetries, lastErr)
 }
 
-// doRawRequestWithRetry performs an HTTP request with retry logic and returns raw bytes
-func (c *MemoryClient) doRawRequestWithRetry(ctx context.Context, method, url string) ([]byte, error) {
-	var lastErr error
-
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		if attempt > 0 {
-			c.logger.Warn("Retrying request",
-				zap.String("url", url),
-				zap.Int("attempt", attempt),
-			)
-			time.Sleep(c.retryDelay * time.Duration(attempt))
-		}
-
-		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+// setMemoryAuthHeader sets the OAuth2 bearer token of tokens if configured, otherwise the API key
+func setMemoryAuthHeader(ctx context.Context, req *http.Request, apiKey string, tokens *tokenSource) error {
+	if tokens != nil {
+		token, err := tokens.Token(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-
-		req.Header.Set("X-API-KEY", c.apiKey)
-
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			lastErr = err
-			continue
+			return fmt.Errorf("failed to obtain access token: %w", err)
 		}
-
-		defer resp.Body.Close()
-
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			body, _ := io.ReadAll(resp.Body)
-			lastErr = fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-
-			// Don't retry on 4xx errors
-			if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-				return nil, lastErr
-			}
-			continue
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to read response body: %w", err)
-			continue
-		}
-
-		return body, nil
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
 	}
 
-	return nil, fmt.Errorf("request failed after %d retries: %w", c.maxRetries, lastErr)
+	req.Header.Set("X-API-KEY", apiKey)
+	return nil
 }
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// tokenExpirySkew refreshes tokens slightly before they expire so requests
+// issued right before expiry don't race the upstream clock
+const tokenExpirySkew = 30 * time.Second
+
+// OAuth2Config holds OAuth2 settings for bearer-token authentication.
+// If RefreshToken is set the refresh-token grant is used, otherwise the
+// client-credentials grant.
+type OAuth2Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+	Scopes       []string
+}
+
+// tokenResponse represents a token endpoint response (RFC 6749 section 5.1)
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// tokenSource fetches and caches OAuth2 access tokens, refreshing them
+// when they are about to expire or were rejected by the API
+type tokenSource struct {
+	config       OAuth2Config
+	httpClient   *http.Client
+	logger       *zap.Logger
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+	expiry       time.Time // zero means the server did not report a lifetime
+}
+
+// newTokenSource creates a token source for the given OAuth2 configuration
+func newTokenSource(config OAuth2Config, httpClient *http.Client, logger *zap.Logger) *tokenSource {
+	return &tokenSource{
+		config:       config,
+		httpClient:   httpClient,
+		logger:       logger,
+		refreshToken: config.RefreshToken,
+	}
+}
+
+// Token returns a valid access token, fetching a new one if necessary
+func (t *tokenSource) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.accessToken != "" && (t.expiry.IsZero() || time.Now().Add(tokenExpirySkew).Before(t.expiry)) {
+		return t.accessToken, nil
+	}
+
+	if err := t.fetchToken(ctx); err != nil {
+		return "", err
+	}
+
+	return t.accessToken, nil
+}
+
+// Invalidate drops the cached access token so the next call to Token fetches a new one.
+// The refresh token (if any) is kept.
+func (t *tokenSource) Invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.accessToken = ""
+	t.expiry = time.Time{}
+}
+
+// fetchToken requests a new access token from the token endpoint (caller holds mu)
+func (t *tokenSource) fetchToken(ctx context.Context) error {
+	if t.refreshToken != "" {
+		err := t.requestToken(ctx, "refresh_token")
+		if err == nil {
+			return nil
+		}
+
+		// A revoked or expired refresh token can still be recovered from if
+		// client credentials are configured
+		if t.config.ClientSecret == "" {
+			return err
+		}
+
+		t.logger.Warn("Refresh token grant failed, falling back to client credentials",
+			zap.Error(err),
+		)
+		t.refreshToken = ""
+	}
+
+	return t.requestToken(ctx, "client_credentials")
+}
+
+// requestToken performs a single token request for the given grant type
+func (t *tokenSource) requestToken(ctx context.Context, grantType string) error {
+	form := url.Values{}
+	form.Set("grant_type", grantType)
+	if grantType == "refresh_token" {
+		form.Set("refresh_token", t.refreshToken)
+	}
+	if t.config.ClientID != "" {
+		form.Set("client_id", t.config.ClientID)
+	}
+	if t.config.ClientSecret != "" {
+		form.Set("client_secret", t.config.ClientSecret)
+	}
+	if len(t.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(t.config.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create token request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	t.logger.Debug("Requesting OAuth2 access token",
+		zap.String("token_url", t.config.TokenURL),
+		zap.String("grant_type", grantType),
+	)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token request failed: %w", &StatusError{StatusCode: resp.StatusCode, Body: string(body)})
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return fmt.Errorf("failed to unmarshal token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return fmt.Errorf("token endpoint returned no access_token")
+	}
+
+	t.accessToken = token.AccessToken
+	if token.ExpiresIn > 0 {
+		t.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	} else {
+		t.expiry = time.Time{}
+	}
+
+	// Servers may rotate refresh tokens on every use
+	if token.RefreshToken != "" {
+		t.refreshToken = token.RefreshToken
+	}
+
+	t.logger.Info("Obtained OAuth2 access token",
+		zap.String("grant_type", grantType),
+		zap.Int64("expires_in", token.ExpiresIn),
+	)
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TimeZoneClient looks up the time zone at coordinates with a GeoNames-compatible time zone API
+type TimeZoneClient struct {
+	apiURL     string
+	username   string
+	httpClient *http.Client
+	logger     *zap.Logger
+}
+
+// TimeZoneClientConfig holds configuration for the time zone API client
+type TimeZoneClientConfig struct {
+	APIURL   string // e.g. https://secure.geonames.org
+	Username string // GeoNames account
+	Timeout  time.Duration
+}
+
+// geonamesTimeZoneResponse is the response of GeoNames' /timezoneJSON
+type geonamesTimeZoneResponse struct {
+	TimezoneID string `json:"timezoneId"`
+	Status     *struct {
+		Message string `json:"message"`
+		Value   int    `json:"value"`
+	} `json:"status"`
+}
+
+// NewTimeZoneClient creates a new time zone API client
+func NewTimeZoneClient(config TimeZoneClientConfig, logger *zap.Logger) *TimeZoneClient {
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &TimeZoneClient{
+		apiURL:   strings.TrimSuffix(config.APIURL, "/"),
+		username: config.Username,
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: SharedTransport(),
+		},
+		logger: logger,
+	}
+}
+
+// TimeZone returns the IANA name of the time zone at coordinates, e.g. Europe/Lisbon; empty if
+// the API knows none (e.g. at sea)
+func (c *TimeZoneClient) TimeZone(ctx context.Context, lat, lon float64) (string, error) {
+	query := url.Values{}
+	query.Set("lat", strconv.FormatFloat(lat, 'f', 4, 64))
+	query.Set("lng", strconv.FormatFloat(lon, 'f', 4, 64))
+	if c.username != "" {
+		query.Set("username", c.username)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"/timezoneJSON?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var response geonamesTimeZoneResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if response.Status != nil {
+		// GeoNames reports errors (e.g. an unknown user or an exceeded limit) with status 200
+		return "", fmt.Errorf("time zone API error %d: %s", response.Status.Value, response.Status.Message)
+	}
+
+	c.logger.Debug("Looked up time zone",
+		zap.Float64("lat", lat),
+		zap.Float64("lon", lon),
+		zap.String("timezone", response.TimezoneID),
+	)
+	return response.TimezoneID, nil
+}
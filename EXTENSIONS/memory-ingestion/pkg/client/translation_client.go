@@ -0,0 +1,225 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Translation providers supported by TranslationClient
+const (
+	ProviderLibreTranslate = "libretranslate" // LibreTranslate and compatible APIs (POST /translate)
+	ProviderDeepL          = "deepl"          // DeepL API v2 (POST /v2/translate)
+)
+
+// TranslationClient translates text with a LibreTranslate-compatible or DeepL API
+type TranslationClient struct {
+	provider   string
+	apiURL     string
+	apiKey     string
+	httpClient *http.Client
+	logger     *zap.Logger
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// TranslationClientConfig holds configuration for the translation API client
+type TranslationClientConfig struct {
+	Provider   string // libretranslate (default) or deepl
+	APIURL     string // e.g. https://libretranslate.example.com or https://api-free.deepl.com
+	APIKey     string
+	Timeout    time.Duration
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+// libreTranslateRequest is the request body of LibreTranslate's /translate
+type libreTranslateRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// libreTranslateResponse is the response of LibreTranslate's /translate
+type libreTranslateResponse struct {
+	TranslatedText string `json:"translatedText"`
+}
+
+// deepLRequest is the request body of DeepL's /v2/translate
+type deepLRequest struct {
+	Text       []string `json:"text"`
+	SourceLang string   `json:"source_lang,omitempty"`
+	TargetLang string   `json:"target_lang"`
+}
+
+// deepLResponse is the response of DeepL's /v2/translate
+type deepLResponse struct {
+	Translations []struct {
+		Text string `json:"text"`
+	} `json:"translations"`
+}
+
+// NewTranslationClient creates a new translation API client
+func NewTranslationClient(config TranslationClientConfig, logger *zap.Logger) *TranslationClient {
+	if config.Provider == "" {
+		config.Provider = ProviderLibreTranslate
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.RetryDelay == 0 {
+		config.RetryDelay = 2 * time.Second
+	}
+
+	return &TranslationClient{
+		provider: config.Provider,
+		apiURL:   strings.TrimSuffix(config.APIURL, "/"),
+		apiKey:   config.APIKey,
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: SharedTransport(),
+		},
+		logger:     logger,
+		maxRetries: config.MaxRetries,
+		retryDelay: config.RetryDelay,
+	}
+}
+
+// Translate translates text from the source to the target language (ISO 639-1 codes).
+// An empty source lets the API detect the language.
+func (c *TranslationClient) Translate(ctx context.Context, text, source, target string) (string, error) {
+	c.logger.Debug("Translating text",
+		zap.String("provider", c.provider),
+		zap.String("source", source),
+		zap.String("target", target),
+		zap.Int("text_length", len(text)),
+	)
+
+	var translated string
+	var err error
+	switch c.provider {
+	case ProviderDeepL:
+		translated, err = c.translateDeepL(ctx, text, source, target)
+	default:
+		translated, err = c.translateLibre(ctx, text, source, target)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to translate text: %w", err)
+	}
+	return translated, nil
+}
+
+// translateLibre translates with a LibreTranslate-compatible API
+func (c *TranslationClient) translateLibre(ctx context.Context, text, source, target string) (string, error) {
+	if source == "" {
+		source = "auto"
+	}
+	request := libreTranslateRequest{Q: text, Source: source, Target: target, Format: "text", APIKey: c.apiKey}
+
+	var response libreTranslateResponse
+	if err := c.doRequestWithRetry(ctx, c.apiURL+"/translate", request, nil, &response); err != nil {
+		return "", err
+	}
+	return response.TranslatedText, nil
+}
+
+// translateDeepL translates with the DeepL API, which uses upper-case language codes
+func (c *TranslationClient) translateDeepL(ctx context.Context, text, source, target string) (string, error) {
+	request := deepLRequest{
+		Text:       []string{text},
+		SourceLang: strings.ToUpper(source),
+		TargetLang: strings.ToUpper(target),
+	}
+	headers := map[string]string{"Authorization": "DeepL-Auth-Key " + c.apiKey}
+
+	var response deepLResponse
+	if err := c.doRequestWithRetry(ctx, c.apiURL+"/v2/translate", request, headers, &response); err != nil {
+		return "", err
+	}
+	if len(response.Translations) == 0 {
+		return "", errors.New("response contains no translation")
+	}
+	return response.Translations[0].Text, nil
+}
+
+// doRequestWithRetry posts a JSON request, retrying network errors, 429, and 5xx responses.
+// The URL isn't logged, since some providers expect keys in it.
+func (c *TranslationClient) doRequestWithRetry(ctx context.Context, url string, requestBody interface{}, headers map[string]string, result interface{}) error {
+	bodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			c.logger.Warn("Retrying translation request",
+				zap.Int("attempt", attempt),
+				zap.Int("max_retries", c.maxRetries),
+				zap.Error(lastErr),
+			)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.retryDelay * time.Duration(attempt)):
+			}
+		}
+
+		lastErr = c.doRequest(ctx, url, bodyBytes, headers, result)
+		if lastErr == nil {
+			return nil
+		}
+
+		var statusErr *StatusError
+		if errors.As(lastErr, &statusErr) && !IsOverloaded(lastErr) {
+			return lastErr
+		}
+	}
+
+	return fmt.Errorf("max retries exceeded: %w", lastErr)
+}
+
+// doRequest posts one JSON request and decodes the response into result
+func (c *TranslationClient) doRequest(ctx context.Context, url string, body []byte, headers map[string]string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// TransportConfig tunes the connection pool of the HTTP transport the API clients share.
+// Zero values use the defaults.
+type TransportConfig struct {
+	MaxIdleConns        int           // idle connections kept across all hosts, defaults to 100
+	MaxIdleConnsPerHost int           // idle connections kept per host, defaults to 32
+	MaxConnsPerHost     int           // connections per host including active ones, 0 means unlimited
+	IdleConnTimeout     time.Duration // how long idle connections are kept, defaults to 90s
+	KeepAlive           time.Duration // TCP keep-alive interval, defaults to 30s
+}
+
+// sharedTransport is the transport of the clients created after the last ConfigureTransport.
+// net/http's default keeps only 2 idle connections per host, so concurrent syncs against one
+// API would close and redial connections, leaving sockets in TIME_WAIT until ephemeral ports run out.
+var sharedTransport atomic.Pointer[http.Transport]
+
+func init() {
+	sharedTransport.Store(NewTransport(TransportConfig{}))
+}
+
+// NewTransport creates an HTTP transport with a tuned connection pool; proxy, TLS handshake, and
+// HTTP/2 settings are those of net/http's default transport
+func NewTransport(config TransportConfig) *http.Transport {
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = 100
+	}
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = 32
+	}
+	if config.IdleConnTimeout == 0 {
+		config.IdleConnTimeout = 90 * time.Second
+	}
+	if config.KeepAlive == 0 {
+		config.KeepAlive = 30 * time.Second
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: config.KeepAlive,
+	}).DialContext
+	transport.MaxIdleConns = config.MaxIdleConns
+	transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = config.MaxConnsPerHost
+	transport.IdleConnTimeout = config.IdleConnTimeout
+	return transport
+}
+
+// ConfigureTransport replaces the transport shared by the clients created afterwards; clients
+// created before keep theirs
+func ConfigureTransport(config TransportConfig) {
+	if previous := sharedTransport.Swap(NewTransport(config)); previous != nil {
+		previous.CloseIdleConnections()
+	}
+}
+
+// SharedTransport returns the transport the clients share, for other HTTP clients of the process
+// to reuse its connections
+func SharedTransport() *http.Transport {
+	return sharedTransport.Load()
+}
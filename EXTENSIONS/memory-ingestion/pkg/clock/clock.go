@@ -0,0 +1,29 @@
+// Package clock abstracts the current time, so syncs can run at a fixed time and produce the same
+// documents, exports, and sync state on every run, e.g. in golden-file tests and replays
+package clock
+
+import "time"
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the clock of the operating system
+var System Clock = systemClock{}
+
+// systemClock reads time.Now
+type systemClock struct{}
+
+// Now implements Clock
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Fixed is a clock that always tells the same time
+type Fixed time.Time
+
+// Now implements Clock
+func (f Fixed) Now() time.Time {
+	return time.Time(f)
+}
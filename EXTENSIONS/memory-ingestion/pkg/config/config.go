@@ -1,63 +1,459 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/kamir/memory-connector/pkg/enrichment"
 	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/reporting"
+	"github.com/kamir/memory-connector/pkg/transformer"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Server     ServerConfig              `yaml:"server" mapstructure:"server"`
-	MemoryAPI  MemoryAPIConfig           `yaml:"memory_api" mapstructure:"memory_api"`
-	LightRAG   LightRAGConfig            `yaml:"lightrag" mapstructure:"lightrag"`
-	Logging    LoggingConfig             `yaml:"logging" mapstructure:"logging"`
-	Storage    StorageConfig             `yaml:"storage" mapstructure:"storage"`
-	Connectors []models.ConnectorConfig  `yaml:"connectors" mapstructure:"connectors"`
+	Server         ServerConfig             `yaml:"server" mapstructure:"server"`
+	MemoryAPI      MemoryAPIConfig          `yaml:"memory_api" mapstructure:"memory_api"`
+	LightRAG       LightRAGConfig           `yaml:"lightrag" mapstructure:"lightrag"`
+	HTTPClient     HTTPClientConfig         `yaml:"http_client" mapstructure:"http_client"`
+	Logging        LoggingConfig            `yaml:"logging" mapstructure:"logging"`
+	Storage        StorageConfig            `yaml:"storage" mapstructure:"storage"`
+	Audit          AuditConfig              `yaml:"audit" mapstructure:"audit"`
+	EventLog       EventLogConfig           `yaml:"event_log" mapstructure:"event_log"`
+	Alerting       AlertingConfig           `yaml:"alerting" mapstructure:"alerting"`
+	Scheduler      SchedulerConfig          `yaml:"scheduler" mapstructure:"scheduler"`
+	DeadLetters    DeadLetterQueueConfig    `yaml:"dead_letter_queue" mapstructure:"dead_letter_queue"`
+	ErrorReporting ErrorReportingConfig     `yaml:"error_reporting" mapstructure:"error_reporting"`
+	Anonymization  AnonymizationConfig      `yaml:"anonymization" mapstructure:"anonymization"`
+	Aliases        AliasesConfig            `yaml:"aliases" mapstructure:"aliases"`
+	FeatureFlags   FeatureFlagsConfig       `yaml:"feature_flags" mapstructure:"feature_flags"`
+	Translation    TranslationConfig        `yaml:"translation" mapstructure:"translation"`
+	ContentFilter  ContentFilterConfig      `yaml:"content_filter" mapstructure:"content_filter"`
+	Geocoding      GeocodingConfig          `yaml:"geocoding" mapstructure:"geocoding"`
+	TimeZones      TimeZonesConfig          `yaml:"timezones" mapstructure:"timezones"`
+	Connectors     []models.ConnectorConfig `yaml:"connectors" mapstructure:"connectors"`
+
+	// ConnectorTemplates are shared base definitions connectors can inherit via `template: <name>`
+	ConnectorTemplates map[string]models.ConnectorConfig `yaml:"connector_templates" mapstructure:"connector_templates"`
 }
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
-	Host string `yaml:"host" mapstructure:"host"`
-	Port int    `yaml:"port" mapstructure:"port"`
+	Host  string      `yaml:"host" mapstructure:"host"`
+	Port  int         `yaml:"port" mapstructure:"port" validate:"min=1,max=65535"`
+	TLS   TLSConfig   `yaml:"tls" mapstructure:"tls"`
+	HTTP2 bool        `yaml:"http2" mapstructure:"http2"` // negotiated via ALPN, requires TLS
+	GRPC  GRPCConfig  `yaml:"grpc" mapstructure:"grpc"`
+	Admin AdminConfig `yaml:"admin" mapstructure:"admin"`
+
+	ChatProxy ChatProxyConfig `yaml:"chat_proxy" mapstructure:"chat_proxy"`
+	Auth      AuthConfig      `yaml:"auth" mapstructure:"auth"`
+
+	Compression bool `yaml:"compression" mapstructure:"compression"` // gzip JSON responses for clients that accept it
+
+	ShutdownTimeout int            `yaml:"shutdown_timeout" mapstructure:"shutdown_timeout" validate:"min=1"` // seconds to drain requests and checkpoint running syncs
+	RequestTimeout  int            `yaml:"request_timeout" mapstructure:"request_timeout" validate:"min=1"`   // seconds a handler may run before the client gets a 504
+	RouteTimeouts   map[string]int `yaml:"route_timeouts" mapstructure:"route_timeouts"`                      // per-route overrides keyed by route, e.g. "/api/v1/health": 5
+}
+
+// GRPCConfig holds settings of the gRPC API, served on server.host next to the HTTP API (with the same TLS settings)
+type GRPCConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	Port    int  `yaml:"port" mapstructure:"port" validate:"min=1,max=65535"`
+}
+
+// AdminConfig holds the diagnostics listener (pprof and expvar). It's separate from the API and
+// unauthenticated, so it should only be reachable by operators (by default it listens on localhost).
+type AdminConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Addr    string `yaml:"addr" mapstructure:"addr"` // host:port, e.g. "127.0.0.1:6060"
+}
+
+// ChatProxyConfig holds the Ollama-compatible chat proxy (/ollama/{context_ids}/api/chat), which
+// forwards chats to LightRAG's Ollama API scoped to the memories of contexts
+type ChatProxyConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	Timeout int  `yaml:"timeout" mapstructure:"timeout" validate:"min=1"` // seconds a chat may stream, instead of server.request_timeout
+}
+
+// API roles, each allowed what the ones before it are
+const (
+	RoleReader   = "reader"   // lookups, queries, and connector status
+	RoleOperator = "operator" // also trigger, pause, resume, and re-ingest syncs
+	RoleAdmin    = "admin"    // also manage aliases, merges, purges, snapshots, imports, and log levels
+)
+
+// AuthConfig holds the authorization of the HTTP and gRPC APIs: clients authenticate with an API
+// key or a JWT, which grant them a role
+type AuthConfig struct {
+	Enabled bool           `yaml:"enabled" mapstructure:"enabled"`
+	APIKeys []APIKeyConfig `yaml:"api_keys" mapstructure:"api_keys"`
+	JWT     JWTConfig      `yaml:"jwt" mapstructure:"jwt"`
+}
+
+// APIKeyConfig maps an API key to the role it grants
+type APIKeyConfig struct {
+	Name string `yaml:"name" mapstructure:"name"` // identifies the client in logs
+	Key  string `yaml:"key" mapstructure:"key"`   // supports ${ENV_VAR} and secret references
+	Role string `yaml:"role" mapstructure:"role" validate:"oneof=reader operator admin"`
+
+	// Contexts binds the key to the contexts whose memories it may look up, all if empty or "*"
+	Contexts []string `yaml:"contexts" mapstructure:"contexts"`
+}
+
+// JWTConfig holds the verification of JWTs issued by an identity provider. Tokens are signed
+// either with a shared secret (HS256) or with the private key of public_key_file (RS256).
+type JWTConfig struct {
+	Secret        string `yaml:"secret" mapstructure:"secret"`                   // supports ${ENV_VAR} and secret references
+	PublicKeyFile string `yaml:"public_key_file" mapstructure:"public_key_file"` // PEM-encoded RSA public key
+	Issuer        string `yaml:"issuer" mapstructure:"issuer"`                   // required iss claim, if set
+	Audience      string `yaml:"audience" mapstructure:"audience"`               // required in the aud claim, if set
+	RoleClaim     string `yaml:"role_claim" mapstructure:"role_claim"`           // claim holding the role or roles, dotted for nested claims, e.g. realm_access.roles
+	ContextClaim  string `yaml:"context_claim" mapstructure:"context_claim"`     // claim holding the contexts a token may look up ("*" for all), required in tokens if set
+}
+
+// Enabled returns true if JWTs are accepted
+func (j JWTConfig) Enabled() bool {
+	return j.Secret != "" || j.PublicKeyFile != ""
+}
+
+// TLSConfig holds HTTPS settings: either a certificate/key pair or ACME autocert
+type TLSConfig struct {
+	CertFile   string         `yaml:"cert_file" mapstructure:"cert_file"`
+	KeyFile    string         `yaml:"key_file" mapstructure:"key_file"`
+	MinVersion string         `yaml:"min_version" mapstructure:"min_version" validate:"oneof=1.2 1.3"`
+	Autocert   AutocertConfig `yaml:"autocert" mapstructure:"autocert"`
+}
+
+// Enabled returns true if the server should serve HTTPS
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || t.Autocert.Enabled
+}
+
+// AutocertConfig holds ACME (e.g. Let's Encrypt) certificate settings
+type AutocertConfig struct {
+	Enabled  bool     `yaml:"enabled" mapstructure:"enabled"`
+	Domains  []string `yaml:"domains" mapstructure:"domains"`     // host names certificates may be issued for
+	Email    string   `yaml:"email" mapstructure:"email"`         // optional contact for the ACME account
+	CacheDir string   `yaml:"cache_dir" mapstructure:"cache_dir"` // where issued certificates are stored
+	HTTPAddr string   `yaml:"http_addr" mapstructure:"http_addr"` // optional listener for HTTP-01 challenges and HTTPS redirects, e.g. ":80"
 }
 
 // MemoryAPIConfig holds Memory API client configuration
 type MemoryAPIConfig struct {
-	URL        string `yaml:"url" mapstructure:"url" validate:"required,url"`
-	APIKey     string `yaml:"api_key" mapstructure:"api_key" validate:"required"`
-	Timeout    int    `yaml:"timeout" mapstructure:"timeout"`       // seconds
-	MaxRetries int    `yaml:"max_retries" mapstructure:"max_retries"`
-	RetryDelay int    `yaml:"retry_delay" mapstructure:"retry_delay"` // seconds
+	URL        string       `yaml:"url" mapstructure:"url" validate:"required,url"`
+	APIKey     string       `yaml:"api_key" mapstructure:"api_key"`
+	OAuth2     OAuth2Config `yaml:"oauth2" mapstructure:"oauth2"`   // alternative to api_key for short-lived tokens
+	Timeout    int          `yaml:"timeout" mapstructure:"timeout"` // seconds
+	MaxRetries int          `yaml:"max_retries" mapstructure:"max_retries"`
+	RetryDelay int          `yaml:"retry_delay" mapstructure:"retry_delay"` // seconds
 }
 
+// OAuth2Config holds OAuth2 client-credentials / refresh-token settings
+type OAuth2Config = models.OAuth2Config
+
 // LightRAGConfig holds LightRAG API configuration
 type LightRAGConfig struct {
 	URL        string `yaml:"url" mapstructure:"url" validate:"required,url"`
 	APIKey     string `yaml:"api_key" mapstructure:"api_key"`
-	Timeout    int    `yaml:"timeout" mapstructure:"timeout"`       // seconds
+	Timeout    int    `yaml:"timeout" mapstructure:"timeout"` // seconds
 	MaxRetries int    `yaml:"max_retries" mapstructure:"max_retries"`
-	RetryDelay int    `yaml:"retry_delay" mapstructure:"retry_delay"` // seconds
+	RetryDelay int    `yaml:"retry_delay" mapstructure:"retry_delay"`                                      // seconds
+	APIVersion string `yaml:"api_version" mapstructure:"api_version" validate:"oneof=auto current legacy"` // API generation of the server, auto probes its version
+
+	Backpressure BackpressureConfig       `yaml:"backpressure" mapstructure:"backpressure"`
+	Compression  RequestCompressionConfig `yaml:"compression" mapstructure:"compression"`
+}
+
+// RequestCompressionConfig gzips document inserts (Content-Encoding: gzip) to save bandwidth to a
+// remote LightRAG. LightRAG doesn't decode compressed bodies itself, a proxy in front of it has to;
+// if it rejects them, inserts continue uncompressed.
+type RequestCompressionConfig struct {
+	Enabled  bool `yaml:"enabled" mapstructure:"enabled"`
+	MinBytes int  `yaml:"min_bytes" mapstructure:"min_bytes" validate:"min=0"` // smaller payloads are sent uncompressed
+}
+
+// BackpressureConfig pauses inserts while LightRAG's processing queue (pending and processing documents) is full
+type BackpressureConfig struct {
+	Enabled      bool `yaml:"enabled" mapstructure:"enabled"`
+	MaxPending   int  `yaml:"max_pending" mapstructure:"max_pending" validate:"min=1"`     // queued documents at which inserts pause
+	PollInterval int  `yaml:"poll_interval" mapstructure:"poll_interval" validate:"min=1"` // seconds between queue checks
+	MaxWait      int  `yaml:"max_wait" mapstructure:"max_wait" validate:"min=1"`           // seconds a sync waits before deferring its remaining memories
+}
+
+// HTTPClientConfig tunes the connection pool shared by the Memory API, LightRAG, translation,
+// geocoding, time zone, and enrichment clients. Large concurrent syncs need enough idle connections per host,
+// otherwise connections are closed and redialed and can exhaust ephemeral ports.
+type HTTPClientConfig struct {
+	MaxIdleConns        int `yaml:"max_idle_conns" mapstructure:"max_idle_conns" validate:"min=0"`                   // idle connections across all hosts
+	MaxIdleConnsPerHost int `yaml:"max_idle_conns_per_host" mapstructure:"max_idle_conns_per_host" validate:"min=0"` // idle connections per host
+	MaxConnsPerHost     int `yaml:"max_conns_per_host" mapstructure:"max_conns_per_host" validate:"min=0"`           // connections per host, 0 means unlimited
+	IdleConnTimeout     int `yaml:"idle_conn_timeout" mapstructure:"idle_conn_timeout" validate:"min=0"`             // seconds an idle connection is kept
+	KeepAlive           int `yaml:"keep_alive" mapstructure:"keep_alive" validate:"min=0"`                           // seconds between TCP keep-alive probes
 }
 
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
-	Level      string `yaml:"level" mapstructure:"level"`             // debug, info, warn, error
-	Format     string `yaml:"format" mapstructure:"format"`           // json or console (as per user's answer: both, configurable)
-	OutputPath string `yaml:"output_path" mapstructure:"output_path"` // file path or stdout
+	Level      string          `yaml:"level" mapstructure:"level" validate:"oneof=debug info warn error"`
+	Format     string          `yaml:"format" mapstructure:"format" validate:"oneof=json console"` // as per user's answer: both, configurable
+	OutputPath string          `yaml:"output_path" mapstructure:"output_path"`                     // file path or stdout
+	Redaction  RedactionConfig `yaml:"redaction" mapstructure:"redaction"`
+}
+
+// RedactionConfig masks sensitive values (transcripts, coordinates, ...) in all log output
+type RedactionConfig struct {
+	Enabled   bool     `yaml:"enabled" mapstructure:"enabled"`
+	Keys      []string `yaml:"keys" mapstructure:"keys"`             // log fields and metadata keys whose values are masked (case-insensitive)
+	MaxLength int      `yaml:"max_length" mapstructure:"max_length"` // longer strings (bodies, errors, ...) are truncated, 0 keeps them whole
 }
 
 // StorageConfig holds state storage configuration
 type StorageConfig struct {
-	Type string `yaml:"type" mapstructure:"type"` // json or sqlite (as per user's answer: both in parallel)
-	Path string `yaml:"path" mapstructure:"path"` // directory for json files or sqlite db path
+	Type string `yaml:"type" mapstructure:"type" validate:"oneof=json sqlite"` // as per user's answer: both in parallel
+	Path string `yaml:"path" mapstructure:"path"`                              // directory for json files or sqlite db path
+
+	DedupFilter DedupFilterConfig       `yaml:"dedup_filter" mapstructure:"dedup_filter"`
+	Encryption  StorageEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+}
+
+// StorageEncryptionConfig encrypts the sync states (with their failed items) and sync reports at
+// rest with AES-256-GCM, since they hold memory IDs, error snippets, and visited places
+type StorageEncryptionConfig struct {
+	Enabled      bool     `yaml:"enabled" mapstructure:"enabled"`
+	Key          string   `yaml:"key" mapstructure:"key"`                     // base64-encoded 32 bytes; supports ${ENV_VAR} and secret references
+	PreviousKeys []string `yaml:"previous_keys" mapstructure:"previous_keys"` // still decrypt values written before a key rotation
+}
+
+// Keys decodes the key and the previous keys, the key first
+func (e StorageEncryptionConfig) Keys() ([][]byte, error) {
+	keys := make([][]byte, 0, 1+len(e.PreviousKeys))
+	for _, encoded := range append([]string{e.Key}, e.PreviousKeys...) {
+		key, err := decodeEncryptionKey(encoded)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// violations checks that the keys are AES-256 keys
+func (e StorageEncryptionConfig) violations() []Violation {
+	if !e.Enabled {
+		return nil
+	}
+
+	var violations []Violation
+	if e.Key == "" {
+		violations = append(violations, Violation{Path: "storage.encryption.key", Message: "is required"})
+	} else if _, err := decodeEncryptionKey(e.Key); err != nil {
+		violations = append(violations, Violation{Path: "storage.encryption.key", Message: err.Error()})
+	}
+	for i, key := range e.PreviousKeys {
+		if _, err := decodeEncryptionKey(key); err != nil {
+			violations = append(violations, Violation{Path: fmt.Sprintf("storage.encryption.previous_keys[%d]", i), Message: err.Error()})
+		}
+	}
+	return violations
+}
+
+// decodeEncryptionKey decodes a base64-encoded AES-256 key
+func decodeEncryptionKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("must be base64-encoded: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("must decode to 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// DedupFilterConfig keeps a Bloom filter of each connector's processed memory IDs in memory (serve
+// only), so memory lookups skip loading the states of connectors that certainly didn't ingest them
+type DedupFilterConfig struct {
+	Enabled           bool    `yaml:"enabled" mapstructure:"enabled"`
+	FalsePositiveRate float64 `yaml:"false_positive_rate" mapstructure:"false_positive_rate" validate:"gt=0,lt=1"` // share of lookups that load a state needlessly
+}
+
+// AuditConfig holds audit log configuration
+type AuditConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Path    string `yaml:"path" mapstructure:"path"` // JSON Lines file
+}
+
+// EventLogConfig holds the persistent log of connector, sync, and ingestion events (service mode only)
+type EventLogConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Path    string `yaml:"path" mapstructure:"path"` // JSON Lines file, grows until rotated by the operator
+}
+
+// SchedulerConfig holds settings that spread scheduled syncs over time
+type SchedulerConfig struct {
+	Jitter  int  `yaml:"jitter" mapstructure:"jitter" validate:"min=0"` // maximum random delay in seconds before each scheduled sync
+	Stagger bool `yaml:"stagger" mapstructure:"stagger"`                // start interval connectors at a fixed per-connector offset instead of on the hour
+
+	// MaxConcurrentSyncs limits how many syncs run at once across all connectors (0 = unlimited).
+	// Further syncs wait in a queue ordered by connector priority.
+	MaxConcurrentSyncs int `yaml:"max_concurrent_syncs" mapstructure:"max_concurrent_syncs" validate:"min=0"`
+
+	Preflight PreflightConfig `yaml:"preflight" mapstructure:"preflight"`
+}
+
+// PreflightConfig holds the checks of a connector's Memory API and LightRAG before each sync. Syncs
+// failing them are skipped with status upstream_unavailable; scheduled ones are retried early.
+type PreflightConfig struct {
+	Enabled       bool `yaml:"enabled" mapstructure:"enabled"`
+	Timeout       int  `yaml:"timeout" mapstructure:"timeout" validate:"min=0"`                 // seconds the checks may take
+	RetryAfter    int  `yaml:"retry_after" mapstructure:"retry_after" validate:"min=0"`         // seconds before the early retry of a skipped scheduled sync (0 = none)
+	MaxRetryAfter int  `yaml:"max_retry_after" mapstructure:"max_retry_after" validate:"min=0"` // seconds the retry delay doubles up to with each consecutive skip
+}
+
+// DeadLetterQueueConfig holds the retries of the failed memories in connectors' Dead Letter Queues.
+// Retries are scheduled per memory; service mode runs them independent of the connectors' schedules.
+type DeadLetterQueueConfig struct {
+	Retry        bool  `yaml:"retry" mapstructure:"retry"`                                  // retry due memories automatically (service mode only)
+	Backoff      []int `yaml:"backoff" mapstructure:"backoff"`                              // minutes before each retry of a memory, the last one repeating
+	MaxAttempts  int   `yaml:"max_attempts" mapstructure:"max_attempts" validate:"min=1"`   // retries of a memory before giving up on it
+	PollInterval int   `yaml:"poll_interval" mapstructure:"poll_interval" validate:"min=1"` // seconds between checks for memories whose retry is due
+	MaxMemories  int   `yaml:"max_memories" mapstructure:"max_memories" validate:"min=1"`   // latest memories of a connector searched for its due ones
+}
+
+// Policy returns the retry schedule of failed memories
+func (d DeadLetterQueueConfig) Policy() models.DeadLetterPolicy {
+	backoff := make([]time.Duration, 0, len(d.Backoff))
+	for _, minutes := range d.Backoff {
+		backoff = append(backoff, time.Duration(minutes)*time.Minute)
+	}
+	return models.DeadLetterPolicy{Backoff: backoff, MaxAttempts: d.MaxAttempts}
+}
+
+// AlertingConfig holds alerting on consecutive sync failures. Notifiers without a URL or host are disabled.
+type AlertingConfig struct {
+	Enabled   bool               `yaml:"enabled" mapstructure:"enabled"`
+	Threshold int                `yaml:"threshold" mapstructure:"threshold" validate:"min=1"` // consecutive failed syncs before an alert fires
+	Cooldown  int                `yaml:"cooldown" mapstructure:"cooldown" validate:"min=1"`   // minutes before a connector that keeps failing is alerted again
+	Resolve   bool               `yaml:"resolve" mapstructure:"resolve"`                      // notify when a connector recovers
+	Webhook   WebhookAlertConfig `yaml:"webhook" mapstructure:"webhook"`
+	Slack     SlackAlertConfig   `yaml:"slack" mapstructure:"slack"`
+	Email     EmailAlertConfig   `yaml:"email" mapstructure:"email"`
+}
+
+// ErrorReportingConfig holds reporting of panics to Sentry or a Sentry-compatible service
+type ErrorReportingConfig struct {
+	DSN         string `yaml:"dsn" mapstructure:"dsn"`                 // empty disables error reporting
+	Environment string `yaml:"environment" mapstructure:"environment"` // e.g. production, staging
+}
+
+// AnonymizationConfig holds the pseudonyms of connectors with transform.anonymize. Mappings are
+// stored per context, encrypted with a key derived from Key; losing the key loses the real names.
+type AnonymizationConfig struct {
+	Key   string   `yaml:"key" mapstructure:"key"`     // supports ${ENV_VAR} and secret references
+	Path  string   `yaml:"path" mapstructure:"path"`   // directory of the encrypted mappings
+	Names []string `yaml:"names" mapstructure:"names"` // names always replaced, e.g. single first names
+}
+
+// AliasesConfig holds the registry of entity aliases per context (e.g. "Mike" for "Michael
+// Schmidt"), maintained through the API. Documents name the canonical entities of the aliases they
+// use, and lookups expand aliases.
+type AliasesConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Path    string `yaml:"path" mapstructure:"path"` // JSON file
+}
+
+// FeatureFlagsConfig holds the runtime overrides of connectors' feature flags, set through the
+// API. The flags themselves are set in each connector's features.
+type FeatureFlagsConfig struct {
+	Path string `yaml:"path" mapstructure:"path"` // JSON file
+}
+
+// TranslationConfig holds the translation API of connectors with transform.target_language
+type TranslationConfig struct {
+	Provider string `yaml:"provider" mapstructure:"provider" validate:"oneof=libretranslate deepl"`
+	URL      string `yaml:"url" mapstructure:"url"`         // e.g. https://libretranslate.example.com or https://api-free.deepl.com
+	APIKey   string `yaml:"api_key" mapstructure:"api_key"` // optional for self-hosted LibreTranslate
+	Timeout  int    `yaml:"timeout" mapstructure:"timeout"` // seconds
+}
+
+// GeocodingConfig holds the reverse geocoding API naming the places of trips (transform.trips)
+type GeocodingConfig struct {
+	URL      string `yaml:"url" mapstructure:"url"`           // Nominatim-compatible, e.g. https://nominatim.openstreetmap.org
+	Language string `yaml:"language" mapstructure:"language"` // preferred language of place names, e.g. en
+	Timeout  int    `yaml:"timeout" mapstructure:"timeout"`   // seconds
+}
+
+// TimeZonesConfig holds the time zone API dating the memories of connectors with
+// transform.local_time in the time zone of their location
+type TimeZonesConfig struct {
+	URL      string `yaml:"url" mapstructure:"url"`           // GeoNames-compatible, e.g. https://secure.geonames.org
+	Username string `yaml:"username" mapstructure:"username"` // GeoNames account
+	Timeout  int    `yaml:"timeout" mapstructure:"timeout"`   // seconds
+}
+
+// ContentFilterConfig holds the content policies of connectors with transform.content_filter
+type ContentFilterConfig struct {
+	Policies []ContentPolicyConfig `yaml:"policies" mapstructure:"policies"`
+}
+
+// ContentPolicyConfig is a named set of words and patterns memories must not contain
+type ContentPolicyConfig struct {
+	Name     string   `yaml:"name" mapstructure:"name"`
+	Action   string   `yaml:"action" mapstructure:"action" validate:"oneof=block redact tag"`
+	Words    []string `yaml:"words" mapstructure:"words"`       // matched as whole words, ignoring case
+	Patterns []string `yaml:"patterns" mapstructure:"patterns"` // regular expressions (RE2 syntax)
+}
+
+// WebhookAlertConfig holds the webhook notifier settings; alerts are posted as JSON
+type WebhookAlertConfig struct {
+	URL     string            `yaml:"url" mapstructure:"url"`
+	Headers map[string]string `yaml:"headers" mapstructure:"headers"` // e.g. Authorization
+}
+
+// SlackAlertConfig holds the Slack notifier settings
+type SlackAlertConfig struct {
+	WebhookURL string `yaml:"webhook_url" mapstructure:"webhook_url"` // incoming webhook
+	Channel    string `yaml:"channel" mapstructure:"channel"`         // overrides the webhook's channel
+}
+
+// EmailAlertConfig holds the SMTP settings of the email notifier
+type EmailAlertConfig struct {
+	SMTPHost string   `yaml:"smtp_host" mapstructure:"smtp_host"`
+	SMTPPort int      `yaml:"smtp_port" mapstructure:"smtp_port" validate:"min=1,max=65535"`
+	Username string   `yaml:"username" mapstructure:"username"`
+	Password string   `yaml:"password" mapstructure:"password"` // supports ${ENV_VAR} and secret references
+	From     string   `yaml:"from" mapstructure:"from"`
+	To       []string `yaml:"to" mapstructure:"to"`
 }
 
 // LoadConfig loads configuration from file and environment variables
 func LoadConfig(configPath string, logger *zap.Logger) (*Config, error) {
+	v := newViper(configPath)
+
+	// Read config file
+	if err := v.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+			logger.Warn("Config file not found, using defaults and environment variables")
+		} else {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+	} else {
+		logger.Info("Loaded configuration", zap.String("file", v.ConfigFileUsed()))
+	}
+
+	return buildConfig(v, logger)
+}
+
+// newViper creates a viper instance with defaults, config file location, and environment binding
+func newViper(configPath string) *viper.Viper {
 	v := viper.New()
 
 	// Set defaults
@@ -77,40 +473,69 @@ func LoadConfig(configPath string, logger *zap.Logger) (*Config, error) {
 	v.SetEnvPrefix("MEMCON")
 	v.AutomaticEnv()
 
-	// Read config file
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			logger.Warn("Config file not found, using defaults and environment variables")
-		} else {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
-		}
-	} else {
-		logger.Info("Loaded configuration", zap.String("file", v.ConfigFileUsed()))
-	}
+	return v
+}
 
+// buildConfig unmarshals, applies environment overrides, and validates the configuration read by v
+func buildConfig(v *viper.Viper, logger *zap.Logger) (*Config, error) {
 	// Unmarshal config
 	var config Config
 	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// Expand connectors that inherit from a template
+	if err := applyConnectorTemplates(v, &config); err != nil {
+		return nil, fmt.Errorf("failed to apply connector templates: %w", err)
+	}
+
+	// Interpolate ${ENV_VAR} placeholders and resolve secret references (gcp-sm://, vault://)
+	if err := resolveReferences(&config, logger); err != nil {
+		return nil, fmt.Errorf("failed to resolve config references: %w", err)
+	}
+
 	// Override sensitive values from environment if present
+	applyEnvOverrides(&config, logger)
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return &config, nil
+}
+
+// applyEnvOverrides overrides credentials with dedicated environment variables
+func applyEnvOverrides(config *Config, logger *zap.Logger) {
 	if apiKey := os.Getenv("MEMCON_MEMORY_API_API_KEY"); apiKey != "" {
 		config.MemoryAPI.APIKey = apiKey
 		logger.Info("Using Memory API key from environment")
 	}
 
+	if secret := os.Getenv("MEMCON_MEMORY_API_OAUTH2_CLIENT_SECRET"); secret != "" {
+		config.MemoryAPI.OAuth2.ClientSecret = secret
+		logger.Info("Using Memory API OAuth2 client secret from environment")
+	}
+
+	if token := os.Getenv("MEMCON_MEMORY_API_OAUTH2_REFRESH_TOKEN"); token != "" {
+		config.MemoryAPI.OAuth2.RefreshToken = token
+		logger.Info("Using Memory API OAuth2 refresh token from environment")
+	}
+
 	if apiKey := os.Getenv("MEMCON_LIGHTRAG_API_KEY"); apiKey != "" {
 		config.LightRAG.APIKey = apiKey
 		logger.Info("Using LightRAG API key from environment")
 	}
 
-	// Validate configuration
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+	if apiKey := os.Getenv("MEMCON_TRANSLATION_API_KEY"); apiKey != "" {
+		config.Translation.APIKey = apiKey
+		logger.Info("Using translation API key from environment")
 	}
 
-	return &config, nil
+	if key := os.Getenv("MEMCON_ANONYMIZATION_KEY"); key != "" {
+		config.Anonymization.Key = key
+		logger.Info("Using anonymization key from environment")
+	}
 }
 
 // setDefaults sets default configuration values
@@ -118,6 +543,16 @@ func setDefaults(v *viper.Viper) {
 	// Server defaults
 	v.SetDefault("server.host", "0.0.0.0")
 	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.http2", true)
+	v.SetDefault("server.compression", true)
+	v.SetDefault("server.shutdown_timeout", 30)
+	v.SetDefault("server.request_timeout", 30)
+	v.SetDefault("server.grpc.port", 9090)
+	v.SetDefault("server.admin.addr", "127.0.0.1:6060")
+	v.SetDefault("server.chat_proxy.timeout", 300)
+	v.SetDefault("server.auth.jwt.role_claim", "role")
+	v.SetDefault("server.tls.min_version", "1.2")
+	v.SetDefault("server.tls.autocert.cache_dir", "./data/autocert")
 
 	// Memory API defaults
 	v.SetDefault("memory_api.timeout", 30)
@@ -128,47 +563,526 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("lightrag.timeout", 60)
 	v.SetDefault("lightrag.max_retries", 3)
 	v.SetDefault("lightrag.retry_delay", 2)
+	v.SetDefault("lightrag.api_version", "auto")
+	v.SetDefault("lightrag.backpressure.enabled", true)
+	v.SetDefault("lightrag.backpressure.max_pending", 100)
+	v.SetDefault("lightrag.backpressure.poll_interval", 10)
+	v.SetDefault("lightrag.backpressure.max_wait", 600)
+	v.SetDefault("lightrag.compression.enabled", false)
+	v.SetDefault("lightrag.compression.min_bytes", 1024)
+
+	// HTTP client defaults
+	v.SetDefault("http_client.max_idle_conns", 100)
+	v.SetDefault("http_client.max_idle_conns_per_host", 32)
+	v.SetDefault("http_client.max_conns_per_host", 0)
+	v.SetDefault("http_client.idle_conn_timeout", 90)
+	v.SetDefault("http_client.keep_alive", 30)
 
 	// Logging defaults (as per user's answer: both formats, configurable)
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "console")
 	v.SetDefault("logging.output_path", "stdout")
+	v.SetDefault("logging.redaction.enabled", true)
+	v.SetDefault("logging.redaction.keys", []string{"text", "transcript", "content", "location_lat", "location_lon", "latitude", "longitude"})
+	v.SetDefault("logging.redaction.max_length", 256)
 
 	// Storage defaults (as per user's answer: both JSON and SQLite)
 	v.SetDefault("storage.type", "json")
 	v.SetDefault("storage.path", "./data")
+	v.SetDefault("storage.dedup_filter.enabled", true)
+	v.SetDefault("storage.dedup_filter.false_positive_rate", 0.01)
+
+	// Audit log defaults
+	v.SetDefault("audit.enabled", true)
+	v.SetDefault("audit.path", "./data/audit.jsonl")
+
+	// Event log defaults
+	v.SetDefault("event_log.enabled", false)
+	v.SetDefault("event_log.path", "./data/events.jsonl")
+
+	// Anonymization defaults
+	v.SetDefault("anonymization.path", "./data/pseudonyms")
+
+	// Alias registry defaults
+	v.SetDefault("aliases.enabled", false)
+	v.SetDefault("aliases.path", "./data/aliases.json")
+
+	// Feature flag override defaults
+	v.SetDefault("feature_flags.path", "./data/feature_flags.json")
+
+	// Translation defaults
+	v.SetDefault("translation.provider", "libretranslate")
+	v.SetDefault("translation.timeout", 30)
+
+	// Geocoding defaults
+	v.SetDefault("geocoding.timeout", 10)
+
+	// Time zone API defaults
+	v.SetDefault("timezones.timeout", 10)
+
+	// Scheduler defaults
+	v.SetDefault("scheduler.jitter", 0)
+	v.SetDefault("scheduler.stagger", true)
+	v.SetDefault("scheduler.max_concurrent_syncs", 0)
+	v.SetDefault("scheduler.preflight.enabled", true)
+	v.SetDefault("scheduler.preflight.timeout", 5)
+	v.SetDefault("scheduler.preflight.retry_after", 60)
+	v.SetDefault("scheduler.preflight.max_retry_after", 900)
+
+	// Dead Letter Queue defaults
+	v.SetDefault("dead_letter_queue.retry", true)
+	v.SetDefault("dead_letter_queue.backoff", []int{5, 60, 360})
+	v.SetDefault("dead_letter_queue.max_attempts", 5)
+	v.SetDefault("dead_letter_queue.poll_interval", 60)
+	v.SetDefault("dead_letter_queue.max_memories", 1000)
+
+	// Alerting defaults
+	v.SetDefault("alerting.threshold", 3)
+	v.SetDefault("alerting.cooldown", 60)
+	v.SetDefault("alerting.resolve", true)
+	v.SetDefault("alerting.email.smtp_port", 587)
 }
 
-// Validate checks if the configuration is valid
+// Validate applies connector defaults and checks if the configuration is valid
 func (c *Config) Validate() error {
-	if c.MemoryAPI.URL == "" {
-		return fmt.Errorf("memory_api.url is required")
+	for i := range c.Connectors {
+		c.Connectors[i].ApplyDefaults()
+	}
+
+	if violations := c.Violations(); len(violations) > 0 {
+		return violations[0]
 	}
-	if c.MemoryAPI.APIKey == "" {
-		return fmt.Errorf("memory_api.api_key is required")
+
+	return nil
+}
+
+// usesMemoryAPI returns true unless every connector generates simulated memories
+func (c *Config) usesMemoryAPI() bool {
+	for i := range c.Connectors {
+		if !c.Connectors[i].Simulation.Enabled {
+			return true
+		}
+	}
+	return len(c.Connectors) == 0
+}
+
+// Violations returns every validation problem of the configuration with its YAML path
+func (c *Config) Violations() []Violation {
+	var violations []Violation
+
+	if c.usesMemoryAPI() {
+		if c.MemoryAPI.URL == "" {
+			violations = append(violations, Violation{Path: "memory_api.url", Message: "is required"})
+		}
+		if c.MemoryAPI.OAuth2.Enabled() {
+			if c.MemoryAPI.OAuth2.ClientID == "" && c.MemoryAPI.OAuth2.RefreshToken == "" {
+				violations = append(violations, Violation{Path: "memory_api.oauth2", Message: "requires client_id or refresh_token"})
+			}
+		} else if c.MemoryAPI.APIKey == "" {
+			violations = append(violations, Violation{Path: "memory_api.api_key", Message: "is required (or configure memory_api.oauth2)"})
+		}
 	}
 	if c.LightRAG.URL == "" {
-		return fmt.Errorf("lightrag.url is required")
+		violations = append(violations, Violation{Path: "lightrag.url", Message: "is required"})
+	}
+	switch c.LightRAG.APIVersion {
+	case "auto", "current", "legacy":
+	default:
+		violations = append(violations, Violation{
+			Path:    "lightrag.api_version",
+			Message: fmt.Sprintf("must be 'auto', 'current', or 'legacy', got '%s'", c.LightRAG.APIVersion),
+		})
+	}
+	if bp := c.LightRAG.Backpressure; bp.Enabled {
+		if bp.MaxPending <= 0 {
+			violations = append(violations, Violation{Path: "lightrag.backpressure.max_pending", Message: "must be positive"})
+		}
+		if bp.PollInterval <= 0 {
+			violations = append(violations, Violation{Path: "lightrag.backpressure.poll_interval", Message: "must be positive"})
+		}
+		if bp.MaxWait <= 0 {
+			violations = append(violations, Violation{Path: "lightrag.backpressure.max_wait", Message: "must be positive"})
+		}
+	}
+
+	for _, setting := range []struct {
+		path  string
+		value int
+	}{
+		{"http_client.max_idle_conns", c.HTTPClient.MaxIdleConns},
+		{"http_client.max_idle_conns_per_host", c.HTTPClient.MaxIdleConnsPerHost},
+		{"http_client.max_conns_per_host", c.HTTPClient.MaxConnsPerHost},
+		{"http_client.idle_conn_timeout", c.HTTPClient.IdleConnTimeout},
+		{"http_client.keep_alive", c.HTTPClient.KeepAlive},
+	} {
+		if setting.value < 0 {
+			violations = append(violations, Violation{Path: setting.path, Message: "must not be negative"})
+		}
+	}
+
+	if c.LightRAG.Compression.MinBytes < 0 {
+		violations = append(violations, Violation{Path: "lightrag.compression.min_bytes", Message: "must not be negative"})
+	}
+
+	if filter := c.Storage.DedupFilter; filter.Enabled && (filter.FalsePositiveRate <= 0 || filter.FalsePositiveRate >= 1) {
+		violations = append(violations, Violation{Path: "storage.dedup_filter.false_positive_rate", Message: "must be between 0 and 1 (exclusive)"})
+	}
+
+	violations = append(violations, c.Storage.Encryption.violations()...)
+	violations = append(violations, c.Server.TLS.violations()...)
+	violations = append(violations, c.Server.Auth.violations()...)
+
+	if c.Server.ShutdownTimeout <= 0 {
+		violations = append(violations, Violation{Path: "server.shutdown_timeout", Message: "must be positive"})
+	}
+	if c.Server.RequestTimeout <= 0 {
+		violations = append(violations, Violation{Path: "server.request_timeout", Message: "must be positive"})
+	}
+	for route, timeout := range c.Server.RouteTimeouts {
+		if timeout <= 0 {
+			violations = append(violations, Violation{Path: "server.route_timeouts." + route, Message: "must be positive"})
+		}
+	}
+	if c.Server.GRPC.Enabled && c.Server.GRPC.Port == c.Server.Port {
+		violations = append(violations, Violation{Path: "server.grpc.port", Message: "must differ from server.port"})
+	}
+	if c.Server.Admin.Enabled {
+		if _, port, err := net.SplitHostPort(c.Server.Admin.Addr); err != nil {
+			violations = append(violations, Violation{Path: "server.admin.addr", Message: "must be host:port"})
+		} else if port == strconv.Itoa(c.Server.Port) {
+			violations = append(violations, Violation{Path: "server.admin.addr", Message: "port must differ from server.port"})
+		}
+	}
+	if c.Server.ChatProxy.Enabled && c.Server.ChatProxy.Timeout <= 0 {
+		violations = append(violations, Violation{Path: "server.chat_proxy.timeout", Message: "must be positive"})
 	}
 
 	// Validate logging format (as per user's answer: json or console)
 	if c.Logging.Format != "json" && c.Logging.Format != "console" {
-		return fmt.Errorf("logging.format must be 'json' or 'console', got '%s'", c.Logging.Format)
+		violations = append(violations, Violation{
+			Path:    "logging.format",
+			Message: fmt.Sprintf("must be 'json' or 'console', got '%s'", c.Logging.Format),
+		})
+	}
+
+	if c.Logging.Redaction.MaxLength < 0 {
+		violations = append(violations, Violation{Path: "logging.redaction.max_length", Message: "must not be negative"})
 	}
 
 	// Validate storage type (as per user's answer: both in parallel)
 	if c.Storage.Type != "json" && c.Storage.Type != "sqlite" {
-		return fmt.Errorf("storage.type must be 'json' or 'sqlite', got '%s'", c.Storage.Type)
+		violations = append(violations, Violation{
+			Path:    "storage.type",
+			Message: fmt.Sprintf("must be 'json' or 'sqlite', got '%s'", c.Storage.Type),
+		})
+	}
+
+	if c.Scheduler.Jitter < 0 {
+		violations = append(violations, Violation{Path: "scheduler.jitter", Message: "must not be negative"})
+	}
+	if c.Scheduler.MaxConcurrentSyncs < 0 {
+		violations = append(violations, Violation{Path: "scheduler.max_concurrent_syncs", Message: "must not be negative"})
+	}
+	violations = append(violations, c.Scheduler.Preflight.violations()...)
+	violations = append(violations, c.DeadLetters.violations()...)
+	violations = append(violations, c.Alerting.violations()...)
+	if c.Translation.Provider != "libretranslate" && c.Translation.Provider != "deepl" {
+		violations = append(violations, Violation{
+			Path:    "translation.provider",
+			Message: fmt.Sprintf("must be 'libretranslate' or 'deepl', got '%s'", c.Translation.Provider),
+		})
+	}
+	if c.Translation.Provider == "deepl" && c.Translation.URL != "" && c.Translation.APIKey == "" {
+		violations = append(violations, Violation{Path: "translation.api_key", Message: "is required for deepl"})
+	}
+	violations = append(violations, c.ContentFilter.violations()...)
+	if c.Geocoding.URL != "" {
+		if parsed, err := url.Parse(c.Geocoding.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			violations = append(violations, Violation{Path: "geocoding.url", Message: "must be an http or https URL"})
+		}
+	}
+	if c.TimeZones.URL != "" {
+		if parsed, err := url.Parse(c.TimeZones.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			violations = append(violations, Violation{Path: "timezones.url", Message: "must be an http or https URL"})
+		}
+	}
+	if c.ErrorReporting.DSN != "" {
+		if _, err := reporting.ParseDSN(c.ErrorReporting.DSN); err != nil {
+			violations = append(violations, Violation{Path: "error_reporting.dsn", Message: err.Error()})
+		}
 	}
 
 	// Validate each connector
-	for i, connector := range c.Connectors {
-		if err := connector.Validate(); err != nil {
-			return fmt.Errorf("connector %d validation failed: %w", i, err)
+	seen := make(map[string]bool, len(c.Connectors))
+	for i := range c.Connectors {
+		for _, fieldErr := range c.Connectors[i].FieldErrors() {
+			violations = append(violations, Violation{
+				Path:    fmt.Sprintf("connectors[%d].%s", i, fieldErr.Field),
+				Message: fieldErr.Message,
+			})
+		}
+		if override := c.Connectors[i].MemoryAPI; override != nil &&
+			override.URL != "" && override.URL != c.MemoryAPI.URL && !override.HasCredentials() {
+			// Don't send the global credentials to a different upstream
+			violations = append(violations, Violation{
+				Path:    fmt.Sprintf("connectors[%d].memory_api", i),
+				Message: "api_key or oauth2 is required when overriding url",
+			})
+		}
+		if c.Connectors[i].Transform.Anonymize && c.Anonymization.Key == "" {
+			violations = append(violations, Violation{
+				Path:    fmt.Sprintf("connectors[%d].transform.anonymize", i),
+				Message: "requires anonymization.key",
+			})
+		}
+		if strategy := c.Connectors[i].Transform.Strategy; strategy != "" && !transformer.Registered(strategy) {
+			violations = append(violations, Violation{
+				Path:    fmt.Sprintf("connectors[%d].transform.strategy", i),
+				Message: fmt.Sprintf("must be one of %s, got '%s'", strings.Join(transformer.Strategies(), ", "), strategy),
+			})
+		}
+		if strategy := c.Connectors[i].Transform.ExperimentalStrategy; strategy != "" && !transformer.Registered(strategy) {
+			violations = append(violations, Violation{
+				Path:    fmt.Sprintf("connectors[%d].transform.experimental_strategy", i),
+				Message: fmt.Sprintf("must be one of %s, got '%s'", strings.Join(transformer.Strategies(), ", "), strategy),
+			})
+		}
+		for j, enricher := range c.Connectors[i].Transform.Enrichers {
+			if enricher.Type == "" {
+				continue
+			}
+			if _, err := enrichment.New(enricher.Type, enricher.Options); err != nil {
+				violations = append(violations, Violation{
+					Path:    fmt.Sprintf("connectors[%d].transform.enrichers[%d]", i, j),
+					Message: err.Error(),
+				})
+			}
+		}
+		if title := c.Connectors[i].Transform.Title; title != "" {
+			if _, err := transformer.ParseTitleTemplate(title); err != nil {
+				violations = append(violations, Violation{
+					Path:    fmt.Sprintf("connectors[%d].transform.title", i),
+					Message: err.Error(),
+				})
+			}
+		}
+		if c.Connectors[i].Transform.ContentFilter && len(c.ContentFilter.Policies) == 0 {
+			violations = append(violations, Violation{
+				Path:    fmt.Sprintf("connectors[%d].transform.content_filter", i),
+				Message: "requires content_filter.policies",
+			})
+		}
+		if c.Connectors[i].Transform.TargetLanguage != "" && c.Translation.URL == "" {
+			violations = append(violations, Violation{
+				Path:    fmt.Sprintf("connectors[%d].transform.target_language", i),
+				Message: "requires translation.url",
+			})
+		}
+		if c.Connectors[i].Transform.LocalTime && c.TimeZones.URL == "" {
+			violations = append(violations, Violation{
+				Path:    fmt.Sprintf("connectors[%d].transform.local_time", i),
+				Message: "requires timezones.url",
+			})
+		}
+		if id := c.Connectors[i].ID; id != "" {
+			if seen[id] {
+				violations = append(violations, Violation{
+					Path:    fmt.Sprintf("connectors[%d].id", i),
+					Message: fmt.Sprintf("duplicate connector ID: %s", id),
+				})
+			}
+			seen[id] = true
 		}
 	}
 
-	return nil
+	return violations
+}
+
+// violations checks the content policies
+func (f ContentFilterConfig) violations() []Violation {
+	var violations []Violation
+	seen := make(map[string]bool, len(f.Policies))
+	for i, policy := range f.Policies {
+		path := fmt.Sprintf("content_filter.policies[%d]", i)
+		switch {
+		case policy.Name == "":
+			violations = append(violations, Violation{Path: path + ".name", Message: "is required"})
+		case seen[policy.Name]:
+			violations = append(violations, Violation{Path: path + ".name", Message: fmt.Sprintf("duplicate policy name: %s", policy.Name)})
+		}
+		seen[policy.Name] = true
+
+		if policy.Action != "block" && policy.Action != "redact" && policy.Action != "tag" {
+			violations = append(violations, Violation{
+				Path:    path + ".action",
+				Message: fmt.Sprintf("must be 'block', 'redact', or 'tag', got '%s'", policy.Action),
+			})
+		}
+		if len(policy.Words) == 0 && len(policy.Patterns) == 0 {
+			violations = append(violations, Violation{Path: path, Message: "requires words or patterns"})
+		}
+		for j, pattern := range policy.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				violations = append(violations, Violation{Path: fmt.Sprintf("%s.patterns[%d]", path, j), Message: err.Error()})
+			}
+		}
+	}
+	return violations
+}
+
+// violations checks the preflight settings
+func (p PreflightConfig) violations() []Violation {
+	if !p.Enabled {
+		return nil
+	}
+
+	var violations []Violation
+
+	if p.Timeout < 1 {
+		violations = append(violations, Violation{Path: "scheduler.preflight.timeout", Message: "must be positive"})
+	}
+	if p.RetryAfter < 0 {
+		violations = append(violations, Violation{Path: "scheduler.preflight.retry_after", Message: "must not be negative"})
+	}
+	if p.RetryAfter > 0 && p.MaxRetryAfter < p.RetryAfter {
+		violations = append(violations, Violation{Path: "scheduler.preflight.max_retry_after", Message: "must be at least retry_after"})
+	}
+
+	return violations
+}
+
+// violations checks the Dead Letter Queue settings
+func (d DeadLetterQueueConfig) violations() []Violation {
+	var violations []Violation
+
+	if len(d.Backoff) == 0 {
+		violations = append(violations, Violation{Path: "dead_letter_queue.backoff", Message: "requires at least one delay"})
+	}
+	for i, minutes := range d.Backoff {
+		if minutes < 1 {
+			violations = append(violations, Violation{Path: fmt.Sprintf("dead_letter_queue.backoff[%d]", i), Message: "must be positive"})
+		}
+	}
+	if d.MaxAttempts < 1 {
+		violations = append(violations, Violation{Path: "dead_letter_queue.max_attempts", Message: "must be at least 1"})
+	}
+	if d.Retry && d.PollInterval < 1 {
+		violations = append(violations, Violation{Path: "dead_letter_queue.poll_interval", Message: "must be positive"})
+	}
+	if d.Retry && (d.MaxMemories < 1 || d.MaxMemories > 10000) {
+		violations = append(violations, Violation{Path: "dead_letter_queue.max_memories", Message: "must be between 1 and 10000"})
+	}
+
+	return violations
+}
+
+// violations checks the alerting settings
+func (a AlertingConfig) violations() []Violation {
+	if !a.Enabled {
+		return nil
+	}
+
+	var violations []Violation
+
+	if a.Threshold < 1 {
+		violations = append(violations, Violation{Path: "alerting.threshold", Message: "must be at least 1"})
+	}
+	if a.Cooldown < 1 {
+		violations = append(violations, Violation{Path: "alerting.cooldown", Message: "must be positive"})
+	}
+	if a.Webhook.URL == "" && a.Slack.WebhookURL == "" && a.Email.SMTPHost == "" {
+		violations = append(violations, Violation{Path: "alerting", Message: "requires at least one notifier (webhook.url, slack.webhook_url, or email.smtp_host)"})
+	}
+	if a.Email.SMTPHost != "" {
+		if a.Email.From == "" {
+			violations = append(violations, Violation{Path: "alerting.email.from", Message: "is required"})
+		}
+		if len(a.Email.To) == 0 {
+			violations = append(violations, Violation{Path: "alerting.email.to", Message: "requires at least one recipient"})
+		}
+	}
+
+	return violations
+}
+
+// violations checks the TLS settings
+func (t TLSConfig) violations() []Violation {
+	var violations []Violation
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		violations = append(violations, Violation{Path: "server.tls", Message: "cert_file and key_file must be set together"})
+	}
+	if t.CertFile != "" && t.Autocert.Enabled {
+		violations = append(violations, Violation{Path: "server.tls.autocert.enabled", Message: "cannot be combined with cert_file"})
+	}
+	if t.Autocert.Enabled && len(t.Autocert.Domains) == 0 {
+		violations = append(violations, Violation{Path: "server.tls.autocert.domains", Message: "at least one domain is required"})
+	}
+	if t.MinVersion != "" && t.MinVersion != "1.2" && t.MinVersion != "1.3" {
+		violations = append(violations, Violation{
+			Path:    "server.tls.min_version",
+			Message: fmt.Sprintf("must be '1.2' or '1.3', got '%s'", t.MinVersion),
+		})
+	}
+
+	return violations
+}
+
+// violations checks the authorization settings
+func (a AuthConfig) violations() []Violation {
+	if !a.Enabled {
+		return nil
+	}
+	var violations []Violation
+
+	if len(a.APIKeys) == 0 && !a.JWT.Enabled() {
+		violations = append(violations, Violation{Path: "server.auth", Message: "requires api_keys or jwt when enabled"})
+	}
+	seen := make(map[string]bool, len(a.APIKeys))
+	for i, apiKey := range a.APIKeys {
+		path := fmt.Sprintf("server.auth.api_keys[%d]", i)
+		switch {
+		case apiKey.Key == "":
+			violations = append(violations, Violation{Path: path + ".key", Message: "is required"})
+		case seen[apiKey.Key]:
+			violations = append(violations, Violation{Path: path + ".key", Message: "is used by another API key"})
+		}
+		seen[apiKey.Key] = true
+		if apiKey.Role != RoleReader && apiKey.Role != RoleOperator && apiKey.Role != RoleAdmin {
+			violations = append(violations, Violation{
+				Path:    path + ".role",
+				Message: fmt.Sprintf("must be 'reader', 'operator', or 'admin', got '%s'", apiKey.Role),
+			})
+		}
+	}
+	if a.JWT.Secret != "" && a.JWT.PublicKeyFile != "" {
+		violations = append(violations, Violation{Path: "server.auth.jwt.secret", Message: "cannot be combined with public_key_file"})
+	}
+	if a.JWT.Enabled() && a.JWT.RoleClaim == "" {
+		violations = append(violations, Violation{Path: "server.auth.jwt.role_claim", Message: "is required"})
+	}
+
+	return violations
+}
+
+// MemoryAPIFor returns the effective Memory API settings for a connector: the global memory_api
+// section with the connector's url and credentials applied
+func (c *Config) MemoryAPIFor(connector *models.ConnectorConfig) MemoryAPIConfig {
+	apiConfig := c.MemoryAPI
+	override := connector.MemoryAPI
+	if override == nil {
+		return apiConfig
+	}
+
+	if override.URL != "" {
+		apiConfig.URL = override.URL
+	}
+	if override.HasCredentials() {
+		apiConfig.APIKey = override.APIKey
+		apiConfig.OAuth2 = override.OAuth2
+	}
+
+	return apiConfig
 }
 
 // GetConnectorByID returns a connector by its ID
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SchemaURI is the JSON Schema dialect of the generated schema
+const SchemaURI = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema generates a JSON Schema for the configuration file from the Config structure.
+// Property names follow the yaml tags and constraints follow the validate tags (oneof, min, max, url, required).
+// Nothing is marked as required at the document level: values may come from defaults, environment
+// variables, or connector templates, which is checked by validate-config instead.
+func JSONSchema() map[string]interface{} {
+	schema := schemaFor(reflect.TypeOf(Config{}))
+	schema["$schema"] = SchemaURI
+	schema["title"] = "Memory Connector configuration"
+
+	return schema
+}
+
+// schemaFor returns the schema of a Go type
+func schemaFor(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaFor(t.Elem())
+
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			property := schemaFor(field.Type)
+			applyValidateTag(property, field.Tag.Get("validate"))
+			properties[fieldName(field)] = property
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaFor(t.Elem()),
+		}
+
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaFor(t.Elem()),
+		}
+
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+// applyValidateTag translates validate tag rules into schema keywords
+func applyValidateTag(property map[string]interface{}, tag string) {
+	if tag == "" {
+		return
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			if property["type"] == "string" {
+				property["minLength"] = 1
+			}
+		case "oneof":
+			values := strings.Fields(arg)
+			enum := make([]interface{}, len(values))
+			for i, value := range values {
+				enum[i] = value
+			}
+			property["enum"] = enum
+		case "min", "max":
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				continue
+			}
+			if property["type"] == "string" {
+				property[name+"Length"] = n
+			} else if name == "min" {
+				property["minimum"] = n
+			} else {
+				property["maximum"] = n
+			}
+		case "url":
+			property["format"] = "uri"
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// secretResolveTimeout bounds the time spent resolving all secret references of a config
+const secretResolveTimeout = 30 * time.Second
+
+// envVarPattern matches ${VAR} and ${VAR:-default}
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// SecretResolver resolves secret references of one URI scheme (e.g. vault://path#key)
+type SecretResolver interface {
+	// Resolve returns the secret value for ref (the full reference including the scheme)
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = map[string]SecretResolver{
+		"gcp-sm": &gcpSecretManagerResolver{httpClient: &http.Client{Timeout: 10 * time.Second}},
+		"vault":  &vaultResolver{httpClient: &http.Client{Timeout: 10 * time.Second}},
+	}
+)
+
+// RegisterSecretResolver registers (or replaces) the resolver for a URI scheme
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+
+	secretResolvers[scheme] = resolver
+}
+
+// resolveReferences interpolates ${ENV_VAR} placeholders and resolves secret references
+// in every string value of the configuration
+func resolveReferences(config *Config, logger *zap.Logger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+	defer cancel()
+
+	return resolveValue(ctx, reflect.ValueOf(config).Elem(), "", logger)
+}
+
+// resolveValue walks a config value, resolving strings in place. path is the YAML path used in errors.
+func resolveValue(ctx context.Context, v reflect.Value, path string, logger *zap.Logger) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return resolveValue(ctx, v.Elem(), path, logger)
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if err := resolveValue(ctx, v.Field(i), joinPath(path, fieldName(field)), logger); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveValue(ctx, v.Index(i), fmt.Sprintf("%s[%d]", path, i), logger); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		for _, key := range v.MapKeys() {
+			resolved, err := resolveString(ctx, v.MapIndex(key).String(), joinPath(path, fmt.Sprint(key.Interface())), logger)
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(key, reflect.ValueOf(resolved).Convert(v.Type().Elem()))
+		}
+
+	case reflect.String:
+		resolved, err := resolveString(ctx, v.String(), path, logger)
+		if err != nil {
+			return err
+		}
+		if v.CanSet() {
+			v.SetString(resolved)
+		}
+	}
+
+	return nil
+}
+
+// resolveString expands environment variables, then resolves the value if it is a secret reference
+func resolveString(ctx context.Context, value, path string, logger *zap.Logger) (string, error) {
+	if !strings.Contains(value, "${") && !strings.Contains(value, "://") {
+		return value, nil
+	}
+
+	expanded, err := expandEnv(value)
+	if err != nil {
+		return "", Violation{Path: path, Message: err.Error()}
+	}
+
+	scheme, _, found := strings.Cut(expanded, "://")
+	if !found {
+		return expanded, nil
+	}
+
+	secretResolversMu.RLock()
+	resolver, ok := secretResolvers[scheme]
+	secretResolversMu.RUnlock()
+	if !ok {
+		// Plain URLs (http://, https://, api://) are not secret references
+		return expanded, nil
+	}
+
+	secret, err := resolver.Resolve(ctx, expanded)
+	if err != nil {
+		return "", Violation{Path: path, Message: fmt.Sprintf("failed to resolve %s secret: %v", scheme, err)}
+	}
+
+	logger.Info("Resolved secret reference", zap.String("path", path), zap.String("scheme", scheme))
+
+	return secret, nil
+}
+
+// expandEnv replaces ${VAR} and ${VAR:-default} placeholders. Undefined variables without default are an error.
+func expandEnv(value string) (string, error) {
+	var missing []string
+
+	expanded := envVarPattern.ReplaceAllStringFunc(value, func(match string) string {
+		groups := envVarPattern.FindStringSubmatch(match)
+		if envValue, ok := os.LookupEnv(groups[1]); ok {
+			return envValue
+		}
+		if groups[2] != "" {
+			return groups[3]
+		}
+		missing = append(missing, groups[1])
+		return ""
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined environment variable(s): %s", strings.Join(missing, ", "))
+	}
+
+	return expanded, nil
+}
+
+// fieldName returns the YAML key of a struct field
+func fieldName(field reflect.StructField) string {
+	if tag := field.Tag.Get("yaml"); tag != "" {
+		if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// joinPath appends a key to a YAML path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// gcpSecretManagerResolver resolves gcp-sm:// references via the Secret Manager REST API.
+// Accepted forms: gcp-sm://projects/P/secrets/S[/versions/V] and gcp-sm://P/S[/V].
+// The access token is read from GCP_ACCESS_TOKEN or the GCE/GKE metadata server.
+type gcpSecretManagerResolver struct {
+	httpClient *http.Client
+}
+
+// Resolve fetches the secret payload
+func (r *gcpSecretManagerResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	name, err := gcpSecretName(strings.TrimPrefix(ref, "gcp-sm://"))
+	if err != nil {
+		return "", err
+	}
+
+	token, err := r.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("https://secretmanager.googleapis.com/v1/%s:access", name)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var result struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := doSecretRequest(r.httpClient, req, &result); err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(result.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret payload: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// accessToken returns a Google OAuth2 access token
+func (r *gcpSecretManagerResolver) accessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GCP_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		"http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create metadata request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doSecretRequest(r.httpClient, req, &token); err != nil {
+		return "", fmt.Errorf("no GCP_ACCESS_TOKEN set and metadata server unavailable: %w", err)
+	}
+
+	return token.AccessToken, nil
+}
+
+// gcpSecretName normalizes a reference to projects/P/secrets/S/versions/V
+func gcpSecretName(ref string) (string, error) {
+	parts := strings.Split(strings.Trim(ref, "/"), "/")
+
+	if parts[0] == "projects" {
+		switch len(parts) {
+		case 4:
+			return strings.Join(parts, "/") + "/versions/latest", nil
+		case 6:
+			return strings.Join(parts, "/"), nil
+		}
+	} else {
+		switch len(parts) {
+		case 2:
+			return fmt.Sprintf("projects/%s/secrets/%s/versions/latest", parts[0], parts[1]), nil
+		case 3:
+			return fmt.Sprintf("projects/%s/secrets/%s/versions/%s", parts[0], parts[1], parts[2]), nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid gcp-sm reference %q (expected gcp-sm://projects/P/secrets/S[/versions/V])", ref)
+}
+
+// vaultResolver resolves vault://<path>#<key> references against VAULT_ADDR using VAULT_TOKEN.
+// Both KV v2 (path includes /data/) and KV v1 responses are supported.
+type vaultResolver struct {
+	httpClient *http.Client
+}
+
+// Resolve reads the secret and returns the requested key
+func (r *vaultResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	path, key, found := strings.Cut(strings.TrimPrefix(ref, "vault://"), "#")
+	if !found || path == "" || key == "" {
+		return "", fmt.Errorf("invalid vault reference %q (expected vault://<path>#<key>)", ref)
+	}
+
+	addr := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(addr, "/"), strings.TrimPrefix(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	var result struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := doSecretRequest(r.httpClient, req, &result); err != nil {
+		return "", err
+	}
+
+	// KV v2 nests the secret under data.data
+	data := result.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found at %s", key, path)
+	}
+
+	return fmt.Sprint(value), nil
+}
+
+// doSecretRequest performs a request and unmarshals the JSON response
+func doSecretRequest(httpClient *http.Client, req *http.Request, result interface{}) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// Don't echo the body, it may contain parts of the secret request
+		return fmt.Errorf("secret store returned status %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// applyConnectorTemplates expands connectors that reference a template from connector_templates.
+// Templates may themselves extend another template via their own "template" key; values set closer
+// to the connector win, nested sections (schedule, ingestion, transform, metadata) are merged key by key.
+func applyConnectorTemplates(v *viper.Viper, config *Config) error {
+	rawTemplates := v.GetStringMap("connector_templates")
+	rawConnectors, _ := v.Get("connectors").([]interface{})
+
+	for i := range config.Connectors {
+		if config.Connectors[i].Template == "" || i >= len(rawConnectors) {
+			continue
+		}
+
+		raw, ok := toStringMap(rawConnectors[i])
+		if !ok {
+			return Violation{Path: fmt.Sprintf("connectors[%d]", i), Message: "unexpected connector definition"}
+		}
+
+		base, err := resolveTemplate(rawTemplates, config.Connectors[i].Template, nil)
+		if err != nil {
+			return Violation{Path: fmt.Sprintf("connectors[%d].template", i), Message: err.Error()}
+		}
+
+		merged := mergeMaps(base, raw)
+
+		// Decode through a scratch viper so the same decode hooks apply as for the main config
+		tv := viper.New()
+		tv.Set("connector", merged)
+
+		var connector models.ConnectorConfig
+		if err := tv.UnmarshalKey("connector", &connector); err != nil {
+			return Violation{Path: fmt.Sprintf("connectors[%d]", i), Message: fmt.Sprintf("failed to decode templated connector: %v", err)}
+		}
+		config.Connectors[i] = connector
+	}
+
+	return nil
+}
+
+// resolveTemplate returns the fully inherited definition of a template
+func resolveTemplate(templates map[string]interface{}, name string, chain []string) (map[string]interface{}, error) {
+	for _, seen := range chain {
+		if seen == name {
+			return nil, fmt.Errorf("connector template cycle: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+	}
+	chain = append(chain, name)
+
+	rawTemplate, exists := templates[strings.ToLower(name)]
+	if !exists {
+		return nil, fmt.Errorf("unknown connector template: %s", name)
+	}
+
+	template, ok := toStringMap(rawTemplate)
+	if !ok {
+		return nil, fmt.Errorf("connector template %s must be a mapping", name)
+	}
+
+	parent, _ := template["template"].(string)
+	if parent == "" {
+		return template, nil
+	}
+
+	base, err := resolveTemplate(templates, parent, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeMaps(base, template), nil
+}
+
+// mergeMaps deep-merges override into a copy of base. The "template" key itself is not inherited.
+func mergeMaps(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for key, value := range base {
+		if key != "template" {
+			merged[key] = value
+		}
+	}
+
+	for key, value := range override {
+		baseMap, baseIsMap := toStringMap(merged[key])
+		overrideMap, overrideIsMap := toStringMap(value)
+		if baseIsMap && overrideIsMap {
+			merged[key] = mergeMaps(baseMap, overrideMap)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+// toStringMap converts the map types produced by YAML decoding to map[string]interface{}
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(m))
+		for key, v := range m {
+			converted[fmt.Sprint(key)] = v
+		}
+		return converted, true
+	default:
+		return nil, false
+	}
+}
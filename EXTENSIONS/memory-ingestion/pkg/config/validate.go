@@ -0,0 +1,224 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Violation is a single configuration problem at a YAML path (e.g. connectors[2].schedule.type)
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (v Violation) Error() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// ValidateFile checks a config file against the JSON Schema and the validation rules and returns
+// every violation found. The error is only set if the file cannot be read at all.
+func ValidateFile(configPath string, logger *zap.Logger) ([]Violation, error) {
+	v := newViper(configPath)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	violations := validateAgainstSchema(v.AllSettings(), JSONSchema(), "")
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Path < violations[j].Path
+	})
+
+	var config Config
+	if err := v.Unmarshal(&config); err != nil {
+		// Type mismatches are already reported by the schema check with their paths
+		if len(violations) > 0 {
+			return violations, nil
+		}
+		return []Violation{{Message: fmt.Sprintf("failed to decode config: %v", err)}}, nil
+	}
+
+	if err := applyConnectorTemplates(v, &config); err != nil {
+		return append(violations, asViolation(err)), nil
+	}
+
+	if err := resolveReferences(&config, logger); err != nil {
+		violations = append(violations, asViolation(err))
+	}
+
+	applyEnvOverrides(&config, logger)
+	for i := range config.Connectors {
+		config.Connectors[i].ApplyDefaults()
+	}
+
+	// Skip rule violations for paths the schema check already reported
+	reported := make(map[string]bool, len(violations))
+	for _, violation := range violations {
+		reported[violation.Path] = true
+	}
+	for _, violation := range config.Violations() {
+		if !reported[violation.Path] {
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations, nil
+}
+
+// asViolation converts an error to a violation, keeping its path if it has one
+func asViolation(err error) Violation {
+	var violation Violation
+	if errors.As(err, &violation) {
+		return violation
+	}
+	return Violation{Message: err.Error()}
+}
+
+// validateAgainstSchema checks a raw config value against the subset of JSON Schema produced by JSONSchema
+func validateAgainstSchema(value interface{}, schema map[string]interface{}, path string) []Violation {
+	if value == nil {
+		return nil
+	}
+
+	var violations []Violation
+
+	switch schema["type"] {
+	case "object":
+		object, ok := toStringMap(value)
+		if !ok {
+			return []Violation{{Path: path, Message: "must be a mapping"}}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for key, item := range object {
+			if property, ok := properties[strings.ToLower(key)].(map[string]interface{}); ok {
+				violations = append(violations, validateAgainstSchema(item, property, joinPath(path, key))...)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				violations = append(violations, validateAgainstSchema(item, additional, joinPath(path, key))...)
+			case bool:
+				if !additional {
+					violations = append(violations, Violation{Path: joinPath(path, key), Message: "unknown key"})
+				}
+			}
+		}
+
+	case "array":
+		items, ok := toSlice(value)
+		if !ok {
+			return []Violation{{Path: path, Message: "must be a list"}}
+		}
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		for i, item := range items {
+			violations = append(violations, validateAgainstSchema(item, itemSchema, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			// Scalars are decoded weakly, so numbers and booleans are accepted as strings
+			s = fmt.Sprint(value)
+		}
+		if minLength, ok := schema["minLength"].(int); ok && len(s) < minLength {
+			violations = append(violations, Violation{Path: path, Message: "must not be empty"})
+		}
+		if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, s) && !strings.Contains(s, "${") {
+			violations = append(violations, Violation{Path: path, Message: fmt.Sprintf("must be one of %s, got '%s'", formatEnum(enum), s)})
+		}
+
+	case "integer":
+		n, ok := toInteger(value)
+		if !ok {
+			if s, isString := value.(string); isString && strings.Contains(s, "${") {
+				return nil
+			}
+			return []Violation{{Path: path, Message: fmt.Sprintf("must be an integer, got '%v'", value)}}
+		}
+		if minimum, ok := schema["minimum"].(int); ok && n < int64(minimum) {
+			violations = append(violations, Violation{Path: path, Message: fmt.Sprintf("must be at least %d, got %d", minimum, n)})
+		}
+		if maximum, ok := schema["maximum"].(int); ok && n > int64(maximum) {
+			violations = append(violations, Violation{Path: path, Message: fmt.Sprintf("must be at most %d, got %d", maximum, n)})
+		}
+
+	case "boolean":
+		switch b := value.(type) {
+		case bool:
+		case string:
+			if _, err := strconv.ParseBool(b); err != nil && !strings.Contains(b, "${") {
+				violations = append(violations, Violation{Path: path, Message: fmt.Sprintf("must be true or false, got '%s'", b)})
+			}
+		default:
+			violations = append(violations, Violation{Path: path, Message: fmt.Sprintf("must be true or false, got '%v'", value)})
+		}
+	}
+
+	return violations
+}
+
+// toSlice converts a list value to []interface{}; defaults set in code are typed (e.g. []string)
+func toSlice(value interface{}) ([]interface{}, bool) {
+	switch items := value.(type) {
+	case []interface{}:
+		return items, true
+	case []string:
+		converted := make([]interface{}, len(items))
+		for i, item := range items {
+			converted[i] = item
+		}
+		return converted, true
+	default:
+		return nil, false
+	}
+}
+
+// toInteger converts the numeric types produced by YAML decoding (and numeric strings) to int64
+func toInteger(value interface{}) (int64, bool) {
+	switch n := value.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), true
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, false
+		}
+		return int64(n), true
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(n), 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// containsValue returns true if enum contains s
+func containsValue(enum []interface{}, s string) bool {
+	for _, value := range enum {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+// formatEnum formats enum values for messages
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		values[i] = fmt.Sprintf("'%v'", value)
+	}
+	return strings.Join(values, ", ")
+}
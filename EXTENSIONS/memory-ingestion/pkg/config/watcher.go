@@ -0,0 +1,309 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/kamir/memory-connector/pkg/audit"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// ApplyFunc applies a reloaded configuration. Returning an error keeps the previous configuration active.
+type ApplyFunc func(oldConfig, newConfig *Config, diff ConfigDiff) error
+
+// ValidateFunc performs additional validation of a reloaded configuration before it is applied
+type ValidateFunc func(newConfig *Config) error
+
+// Principals of the reloads the watcher triggers itself
+const (
+	PrincipalConfigWatcher = "system:config-watcher" // the config file changed
+	PrincipalSIGHUP        = "system:sighup"         // the process received SIGHUP
+)
+
+// Rotatable credentials, applied at runtime unlike the rest of their sections
+const (
+	SecretLightRAGAPIKey  = "lightrag.api_key"
+	SecretMemoryAPIKey    = "memory_api.api_key"
+	SecretMemoryAPIOAuth2 = "memory_api.oauth2"
+)
+
+// ErrConfigRejected is returned by Reload if the config file is invalid
+var ErrConfigRejected = errors.New("configuration rejected")
+
+// ConfigDiff describes what changed between two configurations
+type ConfigDiff struct {
+	AddedConnectors   []string `json:"added_connectors,omitempty"`
+	UpdatedConnectors []string `json:"updated_connectors,omitempty"`
+	RemovedConnectors []string `json:"removed_connectors,omitempty"`
+	RotatedSecrets    []string `json:"rotated_secrets,omitempty"`  // credentials that changed, by path
+	RestartRequired   []string `json:"restart_required,omitempty"` // sections that only take effect after a restart
+}
+
+// IsEmpty returns true if nothing changed
+func (d ConfigDiff) IsEmpty() bool {
+	return len(d.AddedConnectors) == 0 && len(d.UpdatedConnectors) == 0 &&
+		len(d.RemovedConnectors) == 0 && len(d.RotatedSecrets) == 0 && len(d.RestartRequired) == 0
+}
+
+// Rotated returns true if one of the secrets was rotated
+func (d ConfigDiff) Rotated(secrets ...string) bool {
+	for _, rotated := range d.RotatedSecrets {
+		for _, secret := range secrets {
+			if rotated == secret {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Watcher watches the config file and applies validated changes at runtime
+type Watcher struct {
+	v          *viper.Viper
+	auditLog   audit.Recorder
+	logger     *zap.Logger
+	mu         sync.Mutex
+	current    *Config
+	apply      ApplyFunc
+	validators []ValidateFunc
+}
+
+// NewWatcher creates a watcher for the config file, starting from the already loaded configuration
+func NewWatcher(configPath string, current *Config, auditLog audit.Recorder, logger *zap.Logger) (*Watcher, error) {
+	v := newViper(configPath)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if auditLog == nil {
+		auditLog = audit.NopRecorder{}
+	}
+
+	return &Watcher{
+		v:        v,
+		auditLog: auditLog,
+		logger:   logger,
+		current:  current,
+	}, nil
+}
+
+// Start begins watching the config file. apply is called for every change that passes
+// Config.Validate and all validators.
+func (w *Watcher) Start(apply ApplyFunc, validators ...ValidateFunc) {
+	w.apply = apply
+	w.validators = validators
+	w.v.OnConfigChange(func(e fsnotify.Event) {
+		w.reload(w.v, PrincipalConfigWatcher)
+	})
+	w.v.WatchConfig()
+
+	w.logger.Info("Watching configuration for changes", zap.String("file", w.v.ConfigFileUsed()))
+}
+
+// Current returns the currently active configuration
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.current
+}
+
+// Reload re-reads the config file and resolves its secret references again, so secrets rotated in
+// their sources are picked up without a change to the file, and applies it like a change of the
+// file. principal is recorded in the audit log. It returns what changed; errors mean the previous
+// configuration stays active.
+func (w *Watcher) Reload(principal string) (ConfigDiff, error) {
+	// A viper of its own, so the file watch isn't raced
+	v := newViper(w.v.ConfigFileUsed())
+	if err := v.ReadInConfig(); err != nil {
+		return ConfigDiff{}, fmt.Errorf("%w: failed to read config file: %v", ErrConfigRejected, err)
+	}
+	return w.reload(v, principal)
+}
+
+// reload validates the config file read by v and applies it
+func (w *Watcher) reload(v *viper.Viper, principal string) (ConfigDiff, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	file := v.ConfigFileUsed()
+
+	newConfig, err := buildConfig(v, w.logger)
+	if err == nil {
+		for _, validate := range w.validators {
+			if err = validate(newConfig); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		w.logger.Error("Rejected configuration reload, keeping previous configuration",
+			zap.String("file", file),
+			zap.Error(err),
+		)
+		w.record(principal, audit.OutcomeRejected, file, ConfigDiff{}, w.current, nil, err)
+		return ConfigDiff{}, fmt.Errorf("%w: %v", ErrConfigRejected, err)
+	}
+
+	diff := DiffConfigs(w.current, newConfig)
+	if diff.IsEmpty() {
+		w.logger.Debug("Configuration file changed but settings are unchanged", zap.String("file", file))
+		return diff, nil
+	}
+
+	if len(diff.RestartRequired) > 0 {
+		w.logger.Warn("Some configuration changes require a restart to take effect",
+			zap.Strings("sections", diff.RestartRequired),
+		)
+	}
+
+	if w.apply != nil {
+		if err := w.apply(w.current, newConfig, diff); err != nil {
+			w.logger.Error("Failed to apply configuration reload, keeping previous configuration",
+				zap.String("file", file),
+				zap.Error(err),
+			)
+			w.record(principal, audit.OutcomeFailed, file, diff, w.current, newConfig, err)
+			return diff, err
+		}
+	}
+
+	previous := w.current
+	w.current = newConfig
+
+	w.logger.Info("Configuration reloaded",
+		zap.String("file", file),
+		zap.Strings("added", diff.AddedConnectors),
+		zap.Strings("updated", diff.UpdatedConnectors),
+		zap.Strings("removed", diff.RemovedConnectors),
+		zap.Strings("rotated_secrets", diff.RotatedSecrets),
+	)
+	w.record(principal, audit.OutcomeSuccess, file, diff, previous, newConfig, nil)
+	return diff, nil
+}
+
+// record writes a reload audit entry of newConfig (nil if it was rejected) replacing previous.
+// Before and after hold the connectors the reload added, updated, or removed; rotated secrets and
+// restart-required sections are only named, since they hold secrets.
+func (w *Watcher) record(principal, outcome, file string, diff ConfigDiff, previous, newConfig *Config, err error) {
+	entry := audit.Entry{
+		Action:    audit.ActionConfigReload,
+		Principal: principal,
+		Target:    file,
+		Outcome:   outcome,
+		Details: map[string]interface{}{
+			"file": file,
+			"diff": diff,
+		},
+	}
+	if newConfig != nil {
+		if before := changedConnectors(previous, diff.UpdatedConnectors, diff.RemovedConnectors); before != nil {
+			entry.Before = before
+		}
+		if after := changedConnectors(newConfig, diff.AddedConnectors, diff.UpdatedConnectors); after != nil {
+			entry.After = after
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if err := w.auditLog.Record(entry); err != nil {
+		w.logger.Warn("Failed to record audit entry", zap.Error(err))
+	}
+}
+
+// changedConnectors returns the connectors of a configuration with the given IDs, by ID, nil if
+// there are none
+func changedConnectors(config *Config, ids ...[]string) map[string]models.ConnectorConfig {
+	var connectors map[string]models.ConnectorConfig
+	for _, group := range ids {
+		for _, id := range group {
+			if connector, err := config.GetConnectorByID(id); err == nil {
+				if connectors == nil {
+					connectors = make(map[string]models.ConnectorConfig)
+				}
+				connectors[id] = *connector
+			}
+		}
+	}
+	return connectors
+}
+
+// DiffConfigs compares two configurations
+func DiffConfigs(oldConfig, newConfig *Config) ConfigDiff {
+	var diff ConfigDiff
+
+	oldConnectors := make(map[string]models.ConnectorConfig, len(oldConfig.Connectors))
+	for _, c := range oldConfig.Connectors {
+		oldConnectors[c.ID] = c
+	}
+
+	for _, c := range newConfig.Connectors {
+		previous, exists := oldConnectors[c.ID]
+		switch {
+		case !exists:
+			diff.AddedConnectors = append(diff.AddedConnectors, c.ID)
+		case !reflect.DeepEqual(previous, c):
+			diff.UpdatedConnectors = append(diff.UpdatedConnectors, c.ID)
+		}
+		delete(oldConnectors, c.ID)
+	}
+
+	for _, c := range oldConfig.Connectors {
+		if _, removed := oldConnectors[c.ID]; removed {
+			diff.RemovedConnectors = append(diff.RemovedConnectors, c.ID)
+		}
+	}
+
+	// Credentials are rotated at runtime; the rest of their sections is compared without them
+	oldMemoryAPI, oldLightRAG := oldConfig.MemoryAPI, oldConfig.LightRAG
+	if oldLightRAG.APIKey != newConfig.LightRAG.APIKey {
+		diff.RotatedSecrets = append(diff.RotatedSecrets, SecretLightRAGAPIKey)
+		oldLightRAG.APIKey = newConfig.LightRAG.APIKey
+	}
+	if oldMemoryAPI.APIKey != newConfig.MemoryAPI.APIKey {
+		diff.RotatedSecrets = append(diff.RotatedSecrets, SecretMemoryAPIKey)
+		oldMemoryAPI.APIKey = newConfig.MemoryAPI.APIKey
+	}
+	if !reflect.DeepEqual(oldMemoryAPI.OAuth2, newConfig.MemoryAPI.OAuth2) {
+		diff.RotatedSecrets = append(diff.RotatedSecrets, SecretMemoryAPIOAuth2)
+		oldMemoryAPI.OAuth2 = newConfig.MemoryAPI.OAuth2
+	}
+
+	// Client, server, and storage settings are wired at startup
+	sections := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"server", oldConfig.Server, newConfig.Server},
+		{"memory_api", oldMemoryAPI, newConfig.MemoryAPI},
+		{"lightrag", oldLightRAG, newConfig.LightRAG},
+		{"http_client", oldConfig.HTTPClient, newConfig.HTTPClient},
+		{"logging", oldConfig.Logging, newConfig.Logging},
+		{"storage", oldConfig.Storage, newConfig.Storage},
+		{"audit", oldConfig.Audit, newConfig.Audit},
+		{"event_log", oldConfig.EventLog, newConfig.EventLog},
+		{"alerting", oldConfig.Alerting, newConfig.Alerting},
+		{"scheduler", oldConfig.Scheduler, newConfig.Scheduler},
+		{"error_reporting", oldConfig.ErrorReporting, newConfig.ErrorReporting},
+		{"anonymization", oldConfig.Anonymization, newConfig.Anonymization},
+		{"aliases", oldConfig.Aliases, newConfig.Aliases},
+		{"translation", oldConfig.Translation, newConfig.Translation},
+		{"content_filter", oldConfig.ContentFilter, newConfig.ContentFilter},
+		{"geocoding", oldConfig.Geocoding, newConfig.Geocoding},
+		{"timezones", oldConfig.TimeZones, newConfig.TimeZones},
+	}
+	for _, section := range sections {
+		if !reflect.DeepEqual(section.old, section.new) {
+			diff.RestartRequired = append(diff.RestartRequired, section.name)
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,171 @@
+// Package contentfilter checks memories against content policies (e.g. profanity, confidential
+// project names) before they're ingested
+package contentfilter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Actions on text that violates a policy
+const (
+	ActionBlock  = "block"  // the memory isn't ingested
+	ActionRedact = "redact" // the violating text is replaced with RedactedText
+	ActionTag    = "tag"    // the memory is ingested with the policy in its metadata
+)
+
+// RedactedText replaces redacted text
+const RedactedText = "[redacted]"
+
+// Violation is a span of text that violates a policy
+type Violation struct {
+	Policy string
+	Action string
+	Start  int // byte offsets in the scanned text
+	End    int
+}
+
+// Filter finds the policy violations in text. Implementations must be safe for concurrent use.
+type Filter interface {
+	Scan(text string) []Violation
+}
+
+// Policy is a named set of words and regular expressions text must not contain
+type Policy struct {
+	Name     string
+	Action   string   // block, redact, or tag
+	Words    []string // matched as whole words, ignoring case
+	Patterns []string // regular expressions (RE2 syntax)
+}
+
+// WordlistFilter is the default filter, matching the words and patterns of policies
+type WordlistFilter struct {
+	policies []compiledPolicy
+}
+
+// compiledPolicy is a policy with its expressions compiled
+type compiledPolicy struct {
+	name     string
+	action   string
+	words    *regexp.Regexp // nil without words
+	patterns []*regexp.Regexp
+}
+
+// New creates a filter of policies
+func New(policies []Policy) (*WordlistFilter, error) {
+	filter := &WordlistFilter{policies: make([]compiledPolicy, 0, len(policies))}
+	for _, policy := range policies {
+		compiled := compiledPolicy{name: policy.Name, action: policy.Action}
+
+		words := make([]string, 0, len(policy.Words))
+		for _, word := range policy.Words {
+			if word = strings.TrimSpace(word); word != "" {
+				words = append(words, regexp.QuoteMeta(word))
+			}
+		}
+		if len(words) > 0 {
+			// Longer words first, so a word isn't cut short by one of its prefixes
+			sort.SliceStable(words, func(i, j int) bool { return len(words[i]) > len(words[j]) })
+			compiled.words = regexp.MustCompile(`(?i)(?:` + strings.Join(words, "|") + `)`)
+		}
+
+		for _, pattern := range policy.Patterns {
+			expr, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("policy %s: invalid pattern %q: %w", policy.Name, pattern, err)
+			}
+			compiled.patterns = append(compiled.patterns, expr)
+		}
+
+		filter.policies = append(filter.policies, compiled)
+	}
+	return filter, nil
+}
+
+// Scan implements Filter
+func (f *WordlistFilter) Scan(text string) []Violation {
+	var violations []Violation
+	for _, policy := range f.policies {
+		if policy.words != nil {
+			for _, span := range policy.words.FindAllStringIndex(text, -1) {
+				if isWordBoundary(text, span[0], span[1]) {
+					violations = append(violations, Violation{Policy: policy.name, Action: policy.action, Start: span[0], End: span[1]})
+				}
+			}
+		}
+		for _, pattern := range policy.patterns {
+			for _, span := range pattern.FindAllStringIndex(text, -1) {
+				if span[0] < span[1] {
+					violations = append(violations, Violation{Policy: policy.name, Action: policy.action, Start: span[0], End: span[1]})
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// isWordBoundary returns true if text[start:end] isn't part of a longer word
+func isWordBoundary(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(after) {
+		return false
+	}
+	return true
+}
+
+// isWordRune returns true for letters and digits
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Redact replaces the text of violations with the redact action by RedactedText. Overlapping
+// violations are redacted once.
+func Redact(text string, violations []Violation) string {
+	spans := make([][2]int, 0, len(violations))
+	for _, violation := range violations {
+		if violation.Action == ActionRedact {
+			spans = append(spans, [2]int{violation.Start, violation.End})
+		}
+	}
+	if len(spans) == 0 {
+		return text
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var redacted strings.Builder
+	position := 0
+	for i, span := range spans {
+		if i > 0 && span[0] <= position {
+			// Overlaps or adjoins the previous span
+			if span[1] > position {
+				position = span[1]
+			}
+			continue
+		}
+		redacted.WriteString(text[position:span[0]])
+		redacted.WriteString(RedactedText)
+		position = span[1]
+	}
+	redacted.WriteString(text[position:])
+	return redacted.String()
+}
+
+// Policies returns the sorted names of the policies of violations with action
+func Policies(violations []Violation, action string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, violation := range violations {
+		if violation.Action == action && !seen[violation.Policy] {
+			seen[violation.Policy] = true
+			names = append(names, violation.Policy)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,79 @@
+package dedup
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// Duplicate is a memory whose transcript nearly equals that of an earlier memory
+type Duplicate struct {
+	MemoryID    string
+	DuplicateOf string  // the earliest memory with the transcript
+	Similarity  float64 // 0 to 1
+}
+
+// Find returns the memories of candidates that duplicate an earlier memory of fetched (which
+// includes the candidates) with at least the threshold similarity, keyed by memory ID. The
+// earliest memory of each group of duplicates is kept; memories without a transcript are ignored.
+func Find(fetched, candidates []models.Memory, threshold float64) map[string]Duplicate {
+	type fingerprinted struct {
+		id          string
+		createdAt   string
+		fingerprint uint64
+	}
+
+	originals := make([]fingerprinted, 0, len(fetched))
+	for i := range fetched {
+		if strings.TrimSpace(fetched[i].Transcript) == "" {
+			continue
+		}
+		originals = append(originals, fingerprinted{
+			id:          fetched[i].ID,
+			createdAt:   createdAtKey(&fetched[i]),
+			fingerprint: Fingerprint(fetched[i].Transcript),
+		})
+	}
+	sort.SliceStable(originals, func(i, j int) bool {
+		if originals[i].createdAt != originals[j].createdAt {
+			return originals[i].createdAt < originals[j].createdAt
+		}
+		return originals[i].id < originals[j].id
+	})
+
+	isCandidate := make(map[string]bool, len(candidates))
+	for i := range candidates {
+		isCandidate[candidates[i].ID] = true
+	}
+
+	// Compare each memory with the earlier memories that aren't duplicates themselves
+	duplicates := make(map[string]Duplicate)
+	kept := make([]fingerprinted, 0, len(originals))
+	for _, memory := range originals {
+		var best Duplicate
+		for _, original := range kept {
+			if similarity := Similarity(memory.fingerprint, original.fingerprint); similarity >= threshold && similarity > best.Similarity {
+				best = Duplicate{MemoryID: memory.id, DuplicateOf: original.id, Similarity: similarity}
+			}
+		}
+		if best.DuplicateOf == "" {
+			kept = append(kept, memory)
+			continue
+		}
+		if isCandidate[memory.id] {
+			duplicates[memory.id] = best
+		}
+	}
+
+	return duplicates
+}
+
+// createdAtKey returns a sortable creation time of a memory, its raw value if it can't be parsed
+func createdAtKey(memory *models.Memory) string {
+	createdAt, err := memory.ParseCreatedAt()
+	if err != nil {
+		return memory.CreatedAt
+	}
+	return createdAt.UTC().Format("2006-01-02T15:04:05.000000000")
+}
@@ -0,0 +1,55 @@
+// Package dedup detects near-duplicate transcripts with SimHash fingerprints
+package dedup
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// shingleSize is the number of consecutive words hashed as one feature
+const shingleSize = 3
+
+// Fingerprint returns the 64-bit SimHash of text's word shingles. Texts differing in a few words
+// have fingerprints differing in a few bits; case and punctuation are ignored.
+func Fingerprint(text string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+
+	size := shingleSize
+	if len(words) < size {
+		size = len(words)
+	}
+
+	var weights [64]int
+	for i := 0; i+size <= len(words); i++ {
+		hash := fnv.New64a()
+		hash.Write([]byte(strings.Join(words[i:i+size], " ")))
+		feature := hash.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if feature&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// Similarity returns the share of equal bits of two fingerprints, from 0 to 1
+func Similarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
@@ -0,0 +1,292 @@
+package enrichment
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// defaultCalendarRefresh is how long a fetched calendar is used before it's fetched again
+const defaultCalendarRefresh = 15 * time.Minute
+
+// calendarEnricher correlates memories with the events of an iCalendar (ICS) feed, adding the
+// events a memory was recorded during
+type calendarEnricher struct {
+	url           string
+	authorization string         // Authorization header, optional
+	location      *time.Location // of times without a time zone
+	margin        time.Duration  // memories this close to an event also belong to it
+	refresh       time.Duration
+	httpClient    *http.Client
+
+	mu        sync.Mutex
+	events    []calendarEvent
+	fetchedAt time.Time
+}
+
+// calendarEvent is a VEVENT of a calendar
+type calendarEvent struct {
+	start    time.Time
+	end      time.Time
+	summary  string
+	location string
+}
+
+// newCalendarEnricher creates a calendar enricher. Options: url (required), authorization,
+// timezone (of floating times, defaults to UTC), margin_minutes, refresh_minutes (default 15), timeout.
+func newCalendarEnricher(options map[string]string) (Enricher, error) {
+	parsed, err := url.Parse(options["url"])
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("url must be an http or https URL")
+	}
+	timeout, err := timeoutOption(options)
+	if err != nil {
+		return nil, err
+	}
+
+	location := time.UTC
+	if name := options["timezone"]; name != "" {
+		if location, err = time.LoadLocation(name); err != nil {
+			return nil, fmt.Errorf("invalid timezone '%s': %w", name, err)
+		}
+	}
+	margin, err := minutesOption(options, "margin_minutes", 0)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := minutesOption(options, "refresh_minutes", defaultCalendarRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	return &calendarEnricher{
+		url:           options["url"],
+		authorization: options["authorization"],
+		location:      location,
+		margin:        margin,
+		refresh:       refresh,
+		httpClient:    &http.Client{Timeout: timeout, Transport: client.SharedTransport()},
+	}, nil
+}
+
+// minutesOption returns a duration option given in minutes, fallback if it's not set
+func minutesOption(options map[string]string, name string, fallback time.Duration) (time.Duration, error) {
+	value, ok := options[name]
+	if !ok {
+		return fallback, nil
+	}
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes < 0 {
+		return 0, fmt.Errorf("%s must be a number of minutes, got '%s'", name, value)
+	}
+	return time.Duration(minutes) * time.Minute, nil
+}
+
+// Enrich implements Enricher
+func (e *calendarEnricher) Enrich(ctx context.Context, memory *models.Memory, metadata map[string]string) error {
+	createdAt, err := memory.ParseCreatedAt()
+	if err != nil {
+		return nil
+	}
+	events, err := e.calendar(ctx)
+	if err != nil {
+		return err
+	}
+
+	var summaries, locations []string
+	seenLocations := make(map[string]bool)
+	for _, event := range events {
+		if event.start.After(createdAt.Add(e.margin)) || event.end.Before(createdAt.Add(-e.margin)) {
+			continue
+		}
+		if event.summary != "" {
+			summaries = append(summaries, event.summary)
+		}
+		if event.location != "" && !seenLocations[event.location] {
+			seenLocations[event.location] = true
+			locations = append(locations, event.location)
+		}
+	}
+
+	if len(summaries) > 0 {
+		metadata["calendar_event"] = strings.Join(summaries, "; ")
+	}
+	if len(locations) > 0 {
+		metadata["calendar_location"] = strings.Join(locations, "; ")
+	}
+	return nil
+}
+
+// calendar returns the events of the feed, fetched again once they're older than the refresh
+// interval. If fetching fails, the previous events are used until the next attempt.
+func (e *calendarEnricher) calendar(ctx context.Context) ([]calendarEvent, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.fetchedAt.IsZero() && time.Since(e.fetchedAt) < e.refresh {
+		return e.events, nil
+	}
+
+	events, err := e.fetch(ctx)
+	if err != nil {
+		if e.fetchedAt.IsZero() {
+			return nil, err
+		}
+		e.fetchedAt = time.Now()
+		return e.events, nil
+	}
+	e.events = events
+	e.fetchedAt = time.Now()
+	return e.events, nil
+}
+
+// fetch downloads and parses the feed
+func (e *calendarEnricher) fetch(ctx context.Context) ([]calendarEvent, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if e.authorization != "" {
+		req.Header.Set("Authorization", e.authorization)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("%s returned status %d: %s", req.URL.Host, resp.StatusCode, string(body))
+	}
+	return parseCalendar(io.LimitReader(resp.Body, 50<<20), e.location)
+}
+
+// parseCalendar returns the events of an iCalendar document sorted by start. Cancelled events
+// are left out; recurring events only contribute their first occurrence.
+func parseCalendar(r io.Reader, location *time.Location) ([]calendarEvent, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+
+	var events []calendarEvent
+	var event *calendarEvent
+	var cancelled, allDay bool
+	for _, line := range lines {
+		name, params, value := splitContentLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event, cancelled, allDay = &calendarEvent{}, false, false
+		case event == nil:
+			continue
+		case name == "END" && value == "VEVENT":
+			if !cancelled && !event.start.IsZero() {
+				if event.end.Before(event.start) {
+					event.end = event.start
+					if allDay {
+						event.end = event.start.AddDate(0, 0, 1)
+					}
+				}
+				events = append(events, *event)
+			}
+			event = nil
+		case name == "DTSTART":
+			event.start, allDay = parseCalendarTime(value, params, location)
+		case name == "DTEND":
+			event.end, _ = parseCalendarTime(value, params, location)
+		case name == "SUMMARY":
+			event.summary = unescapeCalendarText(value)
+		case name == "LOCATION":
+			event.location = unescapeCalendarText(value)
+		case name == "STATUS":
+			cancelled = strings.EqualFold(value, "CANCELLED")
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].start.Before(events[j].start) })
+	return events, nil
+}
+
+// unfoldLines reads the content lines of an iCalendar document, joining folded lines
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitContentLine splits "NAME;PARAM=VALUE:value" into its upper-case name, parameters, and value
+func splitContentLine(line string) (string, map[string]string, string) {
+	// The value starts at the first colon outside of quoted parameter values
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, ""
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:]
+}
+
+// parseCalendarTime parses a DATE or DATE-TIME value, and reports whether it's a date
+func parseCalendarTime(value string, params map[string]string, location *time.Location) (time.Time, bool) {
+	if tzid := params["TZID"]; tzid != "" {
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			location = tz
+		}
+	}
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, location)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t, true
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, _ := time.Parse("20060102T150405Z", value)
+		return t, false
+	}
+	t, _ := time.ParseInLocation("20060102T150405", value, location)
+	return t, false
+}
+
+// unescapeCalendarText resolves the escapes of iCalendar text values, newlines become spaces
+func unescapeCalendarText(value string) string {
+	replacer := strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return strings.TrimSpace(replacer.Replace(value))
+}
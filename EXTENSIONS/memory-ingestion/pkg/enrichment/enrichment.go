@@ -0,0 +1,108 @@
+// Package enrichment attaches domain metadata (weather, calendar events, CRM records, ...) to
+// memory documents through pluggable enrichers
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// defaultTimeout bounds the lookups of the built-in enrichers
+const defaultTimeout = 10 * time.Second
+
+// Enricher adds metadata to the document of a memory. Enrich may add or overwrite entries of
+// metadata; it must be safe for concurrent use.
+type Enricher interface {
+	Enrich(ctx context.Context, memory *models.Memory, metadata map[string]string) error
+}
+
+// Mentioner is implemented by enrichers whose metadata is also worth a line in the content of
+// rich documents. Mention returns the line for the metadata of a document, empty if there's none.
+type Mentioner interface {
+	Mention(metadata map[string]string) string
+}
+
+// BatchEnricher is implemented by enrichers that look up many memories at once more cheaply than
+// one by one. Syncs call Prefetch with their memories before enriching each of them, which is then
+// served from what Prefetch looked up. Memories Prefetch failed for are looked up by Enrich.
+type BatchEnricher interface {
+	Enricher
+	Prefetch(ctx context.Context, memories []models.Memory) error
+}
+
+// CacheStats counts the lookups of an enricher's cache since the enricher was created
+type CacheStats struct {
+	Hits    int64 // lookups served from the cache
+	Misses  int64 // lookups sent to the service
+	Entries int   // entries cached now
+}
+
+// CacheReporter is implemented by enrichers that cache their lookups
+type CacheReporter interface {
+	CacheStats() CacheStats
+}
+
+// Factory creates an enricher from the options of a connector's enricher configuration
+type Factory func(options map[string]string) (Enricher, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{
+		"http":     newHTTPEnricher,
+		"weather":  newWeatherEnricher,
+		"calendar": newCalendarEnricher,
+		"location": newLocationEnricher,
+		"holiday":  newHolidayEnricher,
+	}
+)
+
+// Register registers (or replaces) the factory of an enricher type
+func Register(enricherType string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	factories[enricherType] = factory
+}
+
+// Types returns the registered enricher types, sorted
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	types := make([]string, 0, len(factories))
+	for enricherType := range factories {
+		types = append(types, enricherType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// New creates an enricher of a registered type
+func New(enricherType string, options map[string]string) (Enricher, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[enricherType]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown enricher type: %s", enricherType)
+	}
+	return factory(options)
+}
+
+// timeoutOption returns the timeout option (seconds), defaultTimeout if it's not set
+func timeoutOption(options map[string]string) (time.Duration, error) {
+	value, ok := options["timeout"]
+	if !ok {
+		return defaultTimeout, nil
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("timeout must be a positive number of seconds, got '%s'", value)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
@@ -0,0 +1,106 @@
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/holidays"
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// holidayEnricher tags memories with whether they were recorded on a weekend or a public holiday,
+// from the embedded holiday calendars. The country is the one a preceding location enricher
+// resolved, the configured one otherwise.
+type holidayEnricher struct {
+	country  string         // ISO 3166-1 alpha-2 code, empty to only tag weekends without a resolved one
+	location *time.Location // of the day, for memories without a local time zone
+	weekend  map[time.Weekday]bool
+}
+
+// newHolidayEnricher creates a holiday enricher. Options: country (default country code),
+// timezone (of the day of memories without a local time zone, defaults to UTC), weekend (comma-
+// separated weekdays, defaults to saturday,sunday).
+func newHolidayEnricher(options map[string]string) (Enricher, error) {
+	country := strings.ToUpper(options["country"])
+	if country != "" && !holidays.Supported(country) {
+		return nil, fmt.Errorf("country must be one of %s, got '%s'", strings.Join(holidays.Countries(), ", "), options["country"])
+	}
+
+	location := time.UTC
+	if name := options["timezone"]; name != "" {
+		var err error
+		if location, err = time.LoadLocation(name); err != nil {
+			return nil, fmt.Errorf("invalid timezone '%s': %w", name, err)
+		}
+	}
+
+	weekend := map[time.Weekday]bool{time.Saturday: true, time.Sunday: true}
+	if value, ok := options["weekend"]; ok {
+		weekend = make(map[time.Weekday]bool)
+		for _, name := range strings.Split(value, ",") {
+			weekday, ok := parseWeekday(strings.TrimSpace(name))
+			if !ok {
+				return nil, fmt.Errorf("weekend must list weekdays, e.g. saturday,sunday, got '%s'", value)
+			}
+			weekend[weekday] = true
+		}
+	}
+
+	return &holidayEnricher{country: country, location: location, weekend: weekend}, nil
+}
+
+// parseWeekday parses the English name of a weekday, ignoring case
+func parseWeekday(name string) (time.Weekday, bool) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(name, weekday.String()) {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// Enrich implements Enricher. The day is the local day where the memory was recorded if its time
+// zone is known (transform.local_time).
+func (e *holidayEnricher) Enrich(ctx context.Context, memory *models.Memory, metadata map[string]string) error {
+	day, ok := memory.LocalCreatedAt()
+	if !ok {
+		createdAt, err := memory.ParseCreatedAt()
+		if err != nil {
+			return nil
+		}
+		day = createdAt.In(e.location)
+	}
+
+	metadata["is_weekend"] = strconv.FormatBool(e.weekend[day.Weekday()])
+
+	country := e.country
+	if resolved := metadata["location_country_code"]; holidays.Supported(resolved) {
+		country = resolved
+	}
+	if country == "" {
+		return nil
+	}
+	metadata["holiday_country"] = country
+	if names := holidays.On(country, day); len(names) > 0 {
+		metadata["public_holiday"] = strings.Join(names, "; ")
+	}
+	return nil
+}
+
+// Mention implements Mentioner, e.g. "Public holiday: Christmas Day", "Weekend", or "Public
+// holiday: Christmas Day, on a weekend"
+func (e *holidayEnricher) Mention(metadata map[string]string) string {
+	weekend := metadata["is_weekend"] == "true"
+	switch holiday := metadata["public_holiday"]; {
+	case holiday != "" && weekend:
+		return "Public holiday: " + holiday + ", on a weekend"
+	case holiday != "":
+		return "Public holiday: " + holiday
+	case weekend:
+		return "Weekend"
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// httpEnricher posts each memory to a service (e.g. a CRM lookup) and adds the fields of the
+// JSON object it answers with to the metadata
+type httpEnricher struct {
+	url           string
+	authorization string // Authorization header, optional
+	prefix        string // prepended to the returned field names
+	httpClient    *http.Client
+}
+
+// httpEnrichRequest is the request body sent to the service
+type httpEnrichRequest struct {
+	Memory   *models.Memory    `json:"memory"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// newHTTPEnricher creates an http enricher. Options: url (required), authorization, prefix, timeout.
+func newHTTPEnricher(options map[string]string) (Enricher, error) {
+	parsed, err := url.Parse(options["url"])
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("url must be an http or https URL")
+	}
+	timeout, err := timeoutOption(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpEnricher{
+		url:           options["url"],
+		authorization: options["authorization"],
+		prefix:        options["prefix"],
+		httpClient:    &http.Client{Timeout: timeout, Transport: client.SharedTransport()},
+	}, nil
+}
+
+// Enrich implements Enricher
+func (e *httpEnricher) Enrich(ctx context.Context, memory *models.Memory, metadata map[string]string) error {
+	body, err := json.Marshal(httpEnrichRequest{Memory: memory, Metadata: metadata})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.authorization != "" {
+		req.Header.Set("Authorization", e.authorization)
+	}
+
+	var fields map[string]interface{}
+	if err := doJSON(e.httpClient, req, &fields); err != nil {
+		return err
+	}
+
+	for name, value := range fields {
+		if text, ok := value.(string); ok {
+			metadata[e.prefix+name] = text
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode field %s: %w", name, err)
+		}
+		metadata[e.prefix+name] = string(encoded)
+	}
+	return nil
+}
+
+// doJSON sends a request and decodes the JSON response into result
+func doJSON(httpClient *http.Client, req *http.Request, result interface{}) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d: %s", req.URL.Host, resp.StatusCode, truncate(string(body), 200))
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// truncate shortens text to at most n bytes
+func truncate(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	return text[:n] + "..."
+}
@@ -0,0 +1,277 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxLocationCacheEntries bounds the cached place names, the cache is cleared when it's full
+	maxLocationCacheEntries = 4096
+
+	// defaultLocationZoom names the neighborhood as well as the town
+	defaultLocationZoom = 14
+
+	// defaultLocationConcurrency bounds the lookups of a batch running at once
+	defaultLocationConcurrency = 4
+
+	// defaultLocationRate is the maximum of Nominatim's usage policy, one request per second
+	defaultLocationRate = 1.0
+)
+
+// Coordinate is a location rounded to ~10 m, the key of place name lookups
+type Coordinate struct {
+	Lat float64
+	Lon float64
+}
+
+// CoordinateOf returns the coordinate of a location, rounded to 4 decimals
+func CoordinateOf(lat, lon float64) Coordinate {
+	return Coordinate{Lat: math.Round(lat*1e4) / 1e4, Lon: math.Round(lon*1e4) / 1e4}
+}
+
+// String returns the coordinate as "lat,lon"
+func (c Coordinate) String() string {
+	return strconv.FormatFloat(c.Lat, 'f', 4, 64) + "," + strconv.FormatFloat(c.Lon, 'f', 4, 64)
+}
+
+// LocationEnricher adds the name and country of the place at a memory's location, looked up with
+// a Nominatim-compatible reverse geocoding API. Lookups are rate limited and cached per coordinate;
+// EnrichBatch looks up the places of many memories at once. Memories without a location are left
+// as they are.
+type LocationEnricher struct {
+	geocoder    client.PlaceGeocoder
+	concurrency int
+	interval    time.Duration // between two requests
+
+	limitMu sync.Mutex
+	next    time.Time // earliest start of the next request
+
+	mu     sync.Mutex
+	cache  map[Coordinate]client.GeocodedPlace // coordinate -> place, empty if the API knows none
+	hits   int64
+	misses int64
+}
+
+// newLocationEnricher creates a location enricher. Options: url (required), language, zoom
+// (default 14), concurrency (default 4), rate_per_second (default 1), timeout.
+func newLocationEnricher(options map[string]string) (Enricher, error) {
+	if options["url"] == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	apiURL, err := urlOption(options, "url", "")
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := timeoutOption(options)
+	if err != nil {
+		return nil, err
+	}
+	zoom, err := positiveIntOption(options, "zoom", defaultLocationZoom)
+	if err != nil {
+		return nil, err
+	}
+	if zoom > 18 {
+		return nil, fmt.Errorf("zoom must be at most 18, got %d", zoom)
+	}
+	concurrency, err := positiveIntOption(options, "concurrency", defaultLocationConcurrency)
+	if err != nil {
+		return nil, err
+	}
+	rate := defaultLocationRate
+	if value, ok := options["rate_per_second"]; ok {
+		if rate, err = strconv.ParseFloat(value, 64); err != nil || rate <= 0 {
+			return nil, fmt.Errorf("rate_per_second must be a positive number, got '%s'", value)
+		}
+	}
+
+	geocoder := client.NewGeocodingClient(client.GeocodingClientConfig{
+		APIURL:   apiURL,
+		Language: options["language"],
+		Zoom:     zoom,
+		Timeout:  timeout,
+	}, zap.NewNop())
+	return &LocationEnricher{
+		geocoder:    geocoder,
+		concurrency: concurrency,
+		interval:    time.Duration(float64(time.Second) / rate),
+		cache:       make(map[Coordinate]client.GeocodedPlace),
+	}, nil
+}
+
+// positiveIntOption returns a positive integer option, fallback if it's not set
+func positiveIntOption(options map[string]string, name string, fallback int) (int, error) {
+	value, ok := options[name]
+	if !ok {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number, got '%s'", name, value)
+	}
+	return n, nil
+}
+
+// Enrich implements Enricher, with the place cached by EnrichBatch if there's one
+func (e *LocationEnricher) Enrich(ctx context.Context, memory *models.Memory, metadata map[string]string) error {
+	if !memory.HasLocation() {
+		return nil
+	}
+
+	coordinate := CoordinateOf(*memory.LocationLat, *memory.LocationLon)
+	place, ok := e.cached(coordinate)
+	if !ok {
+		var err error
+		if place, err = e.lookup(ctx, coordinate); err != nil {
+			return err
+		}
+	}
+	if place.Name != "" {
+		metadata["location_name"] = place.Name
+	}
+	if place.CountryCode != "" {
+		metadata["location_country_code"] = place.CountryCode
+	}
+	return nil
+}
+
+// Prefetch implements BatchEnricher
+func (e *LocationEnricher) Prefetch(ctx context.Context, memories []models.Memory) error {
+	_, err := e.EnrichBatch(ctx, memories)
+	return err
+}
+
+// EnrichBatch looks up the places of the memories' locations and returns them keyed by coordinate.
+// Each coordinate is looked up once, cached ones not at all; up to the enricher's concurrency
+// lookups run at once, within its rate limit. Coordinates whose lookup failed are missing from the
+// result and their errors are returned joined.
+func (e *LocationEnricher) EnrichBatch(ctx context.Context, memories []models.Memory) (map[Coordinate]client.GeocodedPlace, error) {
+	places := make(map[Coordinate]client.GeocodedPlace)
+	var pending []Coordinate
+	seen := make(map[Coordinate]bool)
+	for i := range memories {
+		if !memories[i].HasLocation() {
+			continue
+		}
+		coordinate := CoordinateOf(*memories[i].LocationLat, *memories[i].LocationLon)
+		if seen[coordinate] {
+			continue
+		}
+		seen[coordinate] = true
+		if place, ok := e.cached(coordinate); ok {
+			places[coordinate] = place
+			continue
+		}
+		pending = append(pending, coordinate)
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, e.concurrency)
+	for _, coordinate := range pending {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(coordinate Coordinate) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			place, err := e.lookup(ctx, coordinate)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", coordinate, err))
+				return
+			}
+			places[coordinate] = place
+		}(coordinate)
+	}
+	wg.Wait()
+
+	return places, errors.Join(errs...)
+}
+
+// Mention implements Mentioner, e.g. "Place: Alfama, Lisbon, Portugal"
+func (e *LocationEnricher) Mention(metadata map[string]string) string {
+	if place := metadata["location_name"]; place != "" {
+		return "Place: " + place
+	}
+	return ""
+}
+
+// CacheStats implements CacheReporter
+func (e *LocationEnricher) CacheStats() CacheStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return CacheStats{Hits: e.hits, Misses: e.misses, Entries: len(e.cache)}
+}
+
+// cached returns the cached place of a coordinate
+func (e *LocationEnricher) cached(coordinate Coordinate) (client.GeocodedPlace, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	place, ok := e.cache[coordinate]
+	if ok {
+		e.hits++
+	} else {
+		e.misses++
+	}
+	return place, ok
+}
+
+// lookup looks up the place of a coordinate within the rate limit and caches it
+func (e *LocationEnricher) lookup(ctx context.Context, coordinate Coordinate) (client.GeocodedPlace, error) {
+	if err := e.wait(ctx); err != nil {
+		return client.GeocodedPlace{}, err
+	}
+	place, err := e.geocoder.ReversePlace(ctx, coordinate.Lat, coordinate.Lon)
+	if err != nil {
+		return client.GeocodedPlace{}, err
+	}
+
+	e.mu.Lock()
+	if len(e.cache) >= maxLocationCacheEntries {
+		e.cache = make(map[Coordinate]client.GeocodedPlace)
+	}
+	e.cache[coordinate] = place
+	e.mu.Unlock()
+	return place, nil
+}
+
+// wait blocks until the rate limit allows the next request, or ctx is done
+func (e *LocationEnricher) wait(ctx context.Context) error {
+	e.limitMu.Lock()
+	now := time.Now()
+	start := e.next
+	if start.Before(now) {
+		start = now
+	}
+	e.next = start.Add(e.interval)
+	e.limitMu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
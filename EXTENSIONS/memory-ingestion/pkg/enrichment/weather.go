@@ -0,0 +1,297 @@
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// Weather providers
+const (
+	WeatherProviderOpenMeteo      = "open-meteo"     // no API key, https://open-meteo.com
+	WeatherProviderOpenWeatherMap = "openweathermap" // One Call API 3.0 time machine, requires an API key
+)
+
+const (
+	// defaultOpenMeteoURL is Open-Meteo's forecast API, which also serves the past three months
+	defaultOpenMeteoURL = "https://api.open-meteo.com/v1/forecast"
+
+	// defaultOpenMeteoArchiveURL is Open-Meteo's historical weather API, which lags a few days behind
+	defaultOpenMeteoArchiveURL = "https://archive-api.open-meteo.com/v1/archive"
+
+	// openMeteoArchiveAge is the age from which memories are looked up in the archive
+	openMeteoArchiveAge = 60 * 24 * time.Hour
+
+	// defaultOpenWeatherMapURL is OpenWeatherMap's historical weather endpoint
+	defaultOpenWeatherMapURL = "https://api.openweathermap.org/data/3.0/onecall/timemachine"
+)
+
+// maxWeatherCacheEntries bounds the cached weather lookups, the cache is cleared when it's full
+const maxWeatherCacheEntries = 1024
+
+// weatherConditions describes WMO weather interpretation codes
+var weatherConditions = map[int]string{
+	0: "clear sky", 1: "mainly clear", 2: "partly cloudy", 3: "overcast",
+	45: "fog", 48: "depositing rime fog",
+	51: "light drizzle", 53: "drizzle", 55: "dense drizzle", 56: "freezing drizzle", 57: "dense freezing drizzle",
+	61: "light rain", 63: "rain", 65: "heavy rain", 66: "freezing rain", 67: "heavy freezing rain",
+	71: "light snow", 73: "snow", 75: "heavy snow", 77: "snow grains",
+	80: "light rain showers", 81: "rain showers", 82: "violent rain showers",
+	85: "snow showers", 86: "heavy snow showers",
+	95: "thunderstorm", 96: "thunderstorm with hail", 99: "thunderstorm with heavy hail",
+}
+
+// weatherEnricher adds the historical weather at a memory's location and time. Memories without
+// a location are left as they are.
+type weatherEnricher struct {
+	provider weatherProvider
+
+	mu    sync.Mutex
+	cache map[string]*weatherObservation // provider-specific key -> observation
+}
+
+// weatherObservation is the weather at a place and hour. Unknown values are empty.
+type weatherObservation struct {
+	TemperatureC *float64
+	Condition    string
+	Code         string // provider-specific condition code
+}
+
+// weatherProvider looks up the weather of a provider
+type weatherProvider interface {
+	// key returns the cache key of a lookup, lookups with the same key have the same result
+	key(lat, lon float64, at time.Time) string
+	lookup(ctx context.Context, lat, lon float64, at time.Time) (*weatherObservation, error)
+}
+
+// newWeatherEnricher creates a weather enricher. Options: provider (open-meteo, the default, or
+// openweathermap), url, archive_url (open-meteo, for memories older than 60 days), api_key
+// (openweathermap), timeout.
+func newWeatherEnricher(options map[string]string) (Enricher, error) {
+	timeout, err := timeoutOption(options)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Timeout: timeout, Transport: client.SharedTransport()}
+
+	var provider weatherProvider
+	switch options["provider"] {
+	case "", WeatherProviderOpenMeteo:
+		forecastURL, err := urlOption(options, "url", defaultOpenMeteoURL)
+		if err != nil {
+			return nil, err
+		}
+		archiveURL, err := urlOption(options, "archive_url", defaultOpenMeteoArchiveURL)
+		if err != nil {
+			return nil, err
+		}
+		provider = &openMeteo{forecastURL: forecastURL, archiveURL: archiveURL, httpClient: httpClient}
+	case WeatherProviderOpenWeatherMap:
+		apiURL, err := urlOption(options, "url", defaultOpenWeatherMapURL)
+		if err != nil {
+			return nil, err
+		}
+		if options["api_key"] == "" {
+			return nil, fmt.Errorf("api_key is required for provider %s", WeatherProviderOpenWeatherMap)
+		}
+		provider = &openWeatherMap{url: apiURL, apiKey: options["api_key"], httpClient: httpClient}
+	default:
+		return nil, fmt.Errorf("provider must be %s or %s, got '%s'",
+			WeatherProviderOpenMeteo, WeatherProviderOpenWeatherMap, options["provider"])
+	}
+
+	return &weatherEnricher{provider: provider, cache: make(map[string]*weatherObservation)}, nil
+}
+
+// urlOption returns an http or https URL option, fallback if it's not set
+func urlOption(options map[string]string, name, fallback string) (string, error) {
+	value := options[name]
+	if value == "" {
+		return fallback, nil
+	}
+	if parsed, err := url.Parse(value); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("%s must be an http or https URL", name)
+	}
+	return value, nil
+}
+
+// Enrich implements Enricher
+func (e *weatherEnricher) Enrich(ctx context.Context, memory *models.Memory, metadata map[string]string) error {
+	if !memory.HasLocation() {
+		return nil
+	}
+	createdAt, err := memory.ParseCreatedAt()
+	if err != nil {
+		return nil
+	}
+
+	lat, lon := *memory.LocationLat, *memory.LocationLon
+	key := e.provider.key(lat, lon, createdAt)
+	e.mu.Lock()
+	observation, ok := e.cache[key]
+	e.mu.Unlock()
+	if !ok {
+		if observation, err = e.provider.lookup(ctx, lat, lon, createdAt); err != nil {
+			return err
+		}
+		e.mu.Lock()
+		if len(e.cache) >= maxWeatherCacheEntries {
+			e.cache = make(map[string]*weatherObservation)
+		}
+		e.cache[key] = observation
+		e.mu.Unlock()
+	}
+
+	if observation.TemperatureC != nil {
+		metadata["weather_temperature_c"] = strconv.FormatFloat(*observation.TemperatureC, 'f', 1, 64)
+	}
+	if observation.Condition != "" {
+		metadata["weather_condition"] = observation.Condition
+	}
+	if observation.Code != "" {
+		metadata["weather_code"] = observation.Code
+	}
+	return nil
+}
+
+// Mention implements Mentioner, e.g. "Weather: light rain, 14.5 °C"
+func (e *weatherEnricher) Mention(metadata map[string]string) string {
+	condition, temperature := metadata["weather_condition"], metadata["weather_temperature_c"]
+	switch {
+	case condition != "" && temperature != "":
+		return fmt.Sprintf("Weather: %s, %s °C", condition, temperature)
+	case condition != "":
+		return "Weather: " + condition
+	case temperature != "":
+		return fmt.Sprintf("Weather: %s °C", temperature)
+	}
+	return ""
+}
+
+// openMeteo looks up the hourly weather of Open-Meteo compatible APIs, recent memories in the
+// forecast API and older ones in the archive
+type openMeteo struct {
+	forecastURL string
+	archiveURL  string
+	httpClient  *http.Client
+}
+
+// hourlyWeather is the hourly weather of a day as returned by Open-Meteo
+type hourlyWeather struct {
+	Time        []string   `json:"time"`
+	Temperature []*float64 `json:"temperature_2m"`
+	WeatherCode []*int     `json:"weather_code"`
+}
+
+// openMeteoHour returns the UTC hour nearest to at
+func openMeteoHour(at time.Time) time.Time {
+	return at.UTC().Add(30 * time.Minute).Truncate(time.Hour)
+}
+
+// key implements weatherProvider, lookups are rounded to ~1 km and the hour
+func (p *openMeteo) key(lat, lon float64, at time.Time) string {
+	return fmt.Sprintf("%.2f,%.2f,%s", lat, lon, openMeteoHour(at).Format(time.RFC3339))
+}
+
+// lookup implements weatherProvider
+func (p *openMeteo) lookup(ctx context.Context, lat, lon float64, at time.Time) (*weatherObservation, error) {
+	hour := openMeteoHour(at)
+	day := hour.Format("2006-01-02")
+
+	query := url.Values{}
+	query.Set("latitude", strconv.FormatFloat(lat, 'f', 2, 64))
+	query.Set("longitude", strconv.FormatFloat(lon, 'f', 2, 64))
+	query.Set("hourly", "temperature_2m,weather_code")
+	query.Set("start_date", day)
+	query.Set("end_date", day)
+	query.Set("timezone", "GMT")
+
+	apiURL := p.forecastURL
+	if time.Since(hour) > openMeteoArchiveAge {
+		apiURL = p.archiveURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	var response struct {
+		Hourly hourlyWeather `json:"hourly"`
+	}
+	if err := doJSON(p.httpClient, req, &response); err != nil {
+		return nil, err
+	}
+
+	observation := &weatherObservation{}
+	timestamp := hour.Format("2006-01-02T15:04")
+	for i, t := range response.Hourly.Time {
+		if t != timestamp {
+			continue
+		}
+		if i < len(response.Hourly.Temperature) {
+			observation.TemperatureC = response.Hourly.Temperature[i]
+		}
+		if i < len(response.Hourly.WeatherCode) && response.Hourly.WeatherCode[i] != nil {
+			code := *response.Hourly.WeatherCode[i]
+			observation.Code = strconv.Itoa(code)
+			observation.Condition = weatherConditions[code]
+		}
+		break
+	}
+	return observation, nil
+}
+
+// openWeatherMap looks up the weather of OpenWeatherMap's One Call API time machine
+type openWeatherMap struct {
+	url        string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// key implements weatherProvider, lookups are rounded to ~1 km and the minute
+func (p *openWeatherMap) key(lat, lon float64, at time.Time) string {
+	return fmt.Sprintf("%.2f,%.2f,%d", lat, lon, at.Unix()/60)
+}
+
+// lookup implements weatherProvider
+func (p *openWeatherMap) lookup(ctx context.Context, lat, lon float64, at time.Time) (*weatherObservation, error) {
+	query := url.Values{}
+	query.Set("lat", strconv.FormatFloat(lat, 'f', 4, 64))
+	query.Set("lon", strconv.FormatFloat(lon, 'f', 4, 64))
+	query.Set("dt", strconv.FormatInt(at.Unix(), 10))
+	query.Set("units", "metric")
+	query.Set("appid", p.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	var response struct {
+		Data []struct {
+			Temp    *float64 `json:"temp"`
+			Weather []struct {
+				ID          int    `json:"id"`
+				Description string `json:"description"`
+			} `json:"weather"`
+		} `json:"data"`
+	}
+	if err := doJSON(p.httpClient, req, &response); err != nil {
+		return nil, err
+	}
+
+	observation := &weatherObservation{}
+	if len(response.Data) > 0 {
+		observation.TemperatureC = response.Data[0].Temp
+		if weather := response.Data[0].Weather; len(weather) > 0 {
+			observation.Condition = weather[0].Description
+			observation.Code = strconv.Itoa(weather[0].ID)
+		}
+	}
+	return observation, nil
+}
@@ -0,0 +1,257 @@
+// Package entitymerge finds probable duplicates among the entities LightRAG extracted from
+// memories: entities with similar names (e.g. "Anna" and "Anna Schmidt", or "Falcon Project" and
+// "Project Falcon") that were extracted from the same memories, and suggests merging them.
+package entitymerge
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Defaults of Options
+const (
+	DefaultMinSimilarity  = 0.85
+	DefaultMinSharedCount = 1
+
+	// containedSimilarity is the similarity of names whose words all appear in the other name
+	containedSimilarity = 0.9
+)
+
+// Entity is an entity of the knowledge graph with the memories it was extracted from
+type Entity struct {
+	Name     string
+	Type     string   // entity_type, empty or "unknown" if LightRAG didn't determine one
+	Memories []string // references (e.g. URIs) of the memories
+}
+
+// Options tunes which entities are suggested for merging
+type Options struct {
+	MinSimilarity  float64 // name similarity between 0 and 1, DefaultMinSimilarity if 0
+	MinSharedCount int     // memories both entities were extracted from, DefaultMinSharedCount if 0
+}
+
+// Suggestion suggests merging duplicate entities into a target entity
+type Suggestion struct {
+	Target  string   `json:"target"`  // the entity to keep, the one extracted from most memories
+	Sources []Source `json:"sources"` // the entities to merge into it, most similar first
+}
+
+// Source is an entity suggested for merging into a suggestion's target
+type Source struct {
+	Name           string   `json:"name"`
+	Similarity     float64  `json:"similarity"`      // of its name to the target's name
+	SharedMemories []string `json:"shared_memories"` // memories it shares with the target
+}
+
+// Suggest returns merge suggestions for entities that share enough memories and have similar
+// names and compatible types. Entities linked through a chain of such pairs are suggested as one
+// merge. The strongest suggestions come first.
+func Suggest(entities []Entity, options Options) []Suggestion {
+	if options.MinSimilarity <= 0 {
+		options.MinSimilarity = DefaultMinSimilarity
+	}
+	if options.MinSharedCount <= 0 {
+		options.MinSharedCount = DefaultMinSharedCount
+	}
+
+	unique := make([]Entity, len(entities))
+	for i, entity := range entities {
+		entity.Memories = dedupe(entity.Memories)
+		unique[i] = entity
+	}
+	entities = unique
+
+	// Only entities sharing a memory are compared
+	byMemory := make(map[string][]int)
+	for i, entity := range entities {
+		for _, memory := range entity.Memories {
+			byMemory[memory] = append(byMemory[memory], i)
+		}
+	}
+	shared := make(map[[2]int]int)
+	for _, members := range byMemory {
+		for a := 0; a < len(members); a++ {
+			for b := a + 1; b < len(members); b++ {
+				shared[[2]int{members[a], members[b]}]++
+			}
+		}
+	}
+
+	parent := make([]int, len(entities))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for pair, count := range shared {
+		a, b := entities[pair[0]], entities[pair[1]]
+		if count >= options.MinSharedCount && compatibleTypes(a.Type, b.Type) &&
+			Similarity(a.Name, b.Name) >= options.MinSimilarity {
+			parent[find(pair[0])] = find(pair[1])
+		}
+	}
+
+	clusters := make(map[int][]int)
+	for i := range entities {
+		root := find(i)
+		clusters[root] = append(clusters[root], i)
+	}
+
+	suggestions := []Suggestion{}
+	for _, members := range clusters {
+		if len(members) < 2 {
+			continue
+		}
+		suggestions = append(suggestions, newSuggestion(entities, members))
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i].Sources[0].Similarity, suggestions[j].Sources[0].Similarity
+		if a != b {
+			return a > b
+		}
+		return suggestions[i].Target < suggestions[j].Target
+	})
+	return suggestions
+}
+
+// newSuggestion suggests merging a cluster of duplicates into the entity extracted from most
+// memories, the longer name if they tie
+func newSuggestion(entities []Entity, members []int) Suggestion {
+	sort.Slice(members, func(i, j int) bool {
+		a, b := entities[members[i]], entities[members[j]]
+		if len(a.Memories) != len(b.Memories) {
+			return len(a.Memories) > len(b.Memories)
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) > len(b.Name)
+		}
+		return a.Name < b.Name
+	})
+
+	target := entities[members[0]]
+	targetMemories := make(map[string]bool, len(target.Memories))
+	for _, memory := range target.Memories {
+		targetMemories[memory] = true
+	}
+
+	suggestion := Suggestion{Target: target.Name}
+	for _, member := range members[1:] {
+		source := Source{
+			Name:           entities[member].Name,
+			Similarity:     Similarity(entities[member].Name, target.Name),
+			SharedMemories: []string{},
+		}
+		for _, memory := range entities[member].Memories {
+			if targetMemories[memory] {
+				source.SharedMemories = append(source.SharedMemories, memory)
+			}
+		}
+		sort.Strings(source.SharedMemories)
+		suggestion.Sources = append(suggestion.Sources, source)
+	}
+	sort.SliceStable(suggestion.Sources, func(i, j int) bool {
+		return suggestion.Sources[i].Similarity > suggestion.Sources[j].Similarity
+	})
+	return suggestion
+}
+
+// Similarity returns the similarity of two entity names between 0 and 1, ignoring case and
+// punctuation: 1 for the same words, 0.9 if the words of one name all appear in the other (e.g.
+// "Anna" and "Anna Schmidt"), and the edit distance relative to the longer name otherwise
+func Similarity(a, b string) float64 {
+	wordsA, wordsB := words(a), words(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	if sameWords(wordsA, wordsB) {
+		return 1 // also e.g. "Falcon Project" and "Project Falcon"
+	}
+
+	normalizedA, normalizedB := strings.Join(wordsA, " "), strings.Join(wordsB, " ")
+
+	similarity := 1 - float64(editDistance([]rune(normalizedA), []rune(normalizedB)))/
+		float64(max(len([]rune(normalizedA)), len([]rune(normalizedB))))
+	if contains(wordsA, wordsB) || contains(wordsB, wordsA) {
+		similarity = max(similarity, containedSimilarity)
+	}
+	return similarity
+}
+
+// compatibleTypes returns false for entities of different known types, e.g. a person and a location
+func compatibleTypes(a, b string) bool {
+	a, b = strings.ToLower(strings.TrimSpace(a)), strings.ToLower(strings.TrimSpace(b))
+	return a == "" || b == "" || a == "unknown" || b == "unknown" || a == b
+}
+
+// words returns the lower-case words of a name, without punctuation
+func words(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// sameWords returns true if a and b have the same words in any order
+func sameWords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA, sortedB := append([]string(nil), a...), append([]string(nil), b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	return strings.Join(sortedA, " ") == strings.Join(sortedB, " ")
+}
+
+// contains returns true if every word of part appears in whole
+func contains(whole, part []string) bool {
+	set := make(map[string]bool, len(whole))
+	for _, word := range whole {
+		set[word] = true
+	}
+	for _, word := range part {
+		if !set[word] {
+			return false
+		}
+	}
+	return true
+}
+
+// editDistance returns the Levenshtein distance of a and b
+func editDistance(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// dedupe returns values without duplicates, in their order
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,162 @@
+// Package episodes detects episodes: sequences of memories close in time that share entities
+package episodes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// Config holds the episode detection settings
+type Config struct {
+	Window            time.Duration  // maximum gap between consecutive memories of an episode
+	MinSharedEntities int            // entities a memory must share with the episode to join it
+	Location          *time.Location // time zone of the members' creation times, UTC if nil
+}
+
+// Episode is a sequence of at least two related memories, oldest first
+type Episode struct {
+	ID      string   `json:"id"`
+	Members []Member `json:"members"`
+}
+
+// Member is a memory of an episode
+type Member struct {
+	MemoryID  string    `json:"memory_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Position returns the 1-based position of a memory in the episode, 0 if it isn't a member
+func (e *Episode) Position(memoryID string) int {
+	for i, member := range e.Members {
+		if member.MemoryID == memoryID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// stopWords are capitalized words that don't name entities: sentence starters, days, and months
+var stopWords = map[string]bool{
+	"i": true, "a": true, "an": true, "the": true, "this": true, "that": true, "these": true,
+	"those": true, "my": true, "our": true, "your": true, "his": true, "her": true, "their": true,
+	"we": true, "you": true, "he": true, "she": true, "they": true, "it": true, "and": true,
+	"but": true, "or": true, "so": true, "then": true, "when": true, "after": true, "before": true,
+	"today": true, "yesterday": true, "tomorrow": true, "also": true, "just": true, "ok": true,
+	"monday": true, "tuesday": true, "wednesday": true, "thursday": true, "friday": true,
+	"saturday": true, "sunday": true, "january": true, "february": true, "march": true,
+	"april": true, "may": true, "june": true, "july": true, "august": true, "september": true,
+	"october": true, "november": true, "december": true,
+}
+
+// Detect groups the memories of a context into episodes and returns the episode of each memory
+// that belongs to one. Memories join the current episode if they follow its last memory within
+// the window and share enough entities with it. Memories without a valid created_at are ignored.
+// Episode IDs derive from the context and the first memory, so they're stable across runs as long
+// as the first memory is fetched.
+func Detect(contextID string, memories []models.Memory, config Config) map[string]*Episode {
+	type dated struct {
+		id        string
+		createdAt time.Time
+		entities  map[string]bool
+	}
+
+	location := config.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	sorted := make([]dated, 0, len(memories))
+	for i := range memories {
+		createdAt, err := memories[i].ParseCreatedAt()
+		if err != nil {
+			continue
+		}
+		sorted = append(sorted, dated{
+			id:        memories[i].ID,
+			createdAt: createdAt.In(location),
+			entities:  Entities(memories[i].Transcript),
+		})
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].createdAt.Before(sorted[j].createdAt)
+	})
+
+	result := make(map[string]*Episode)
+	var current *Episode
+	var currentEntities map[string]bool
+	var last time.Time
+	for _, memory := range sorted {
+		if current != nil && memory.createdAt.Sub(last) <= config.Window &&
+			shared(memory.entities, currentEntities) >= config.MinSharedEntities {
+			current.Members = append(current.Members, Member{MemoryID: memory.id, CreatedAt: memory.createdAt})
+			for entity := range memory.entities {
+				currentEntities[entity] = true
+			}
+		} else {
+			finish(current, result)
+			current = &Episode{
+				ID:      episodeID(contextID, memory.id),
+				Members: []Member{{MemoryID: memory.id, CreatedAt: memory.createdAt}},
+			}
+			currentEntities = memory.entities
+		}
+		last = memory.createdAt
+	}
+	finish(current, result)
+
+	return result
+}
+
+// finish adds an episode of at least two memories to the result
+func finish(episode *Episode, result map[string]*Episode) {
+	if episode == nil || len(episode.Members) < 2 {
+		return
+	}
+	for _, member := range episode.Members {
+		result[member.MemoryID] = episode
+	}
+}
+
+// episodeID derives the ID of an episode from its context and first memory
+func episodeID(contextID, firstMemoryID string) string {
+	sum := sha256.Sum256([]byte(contextID + "\x00" + firstMemoryID))
+	return "ep-" + hex.EncodeToString(sum[:6])
+}
+
+// shared counts the entities of a that are also in b
+func shared(a, b map[string]bool) int {
+	count := 0
+	for entity := range a {
+		if b[entity] {
+			count++
+		}
+	}
+	return count
+}
+
+// Entities returns the lower-cased capitalized words of text (names, places, projects, ...),
+// without common sentence starters
+func Entities(text string) map[string]bool {
+	entities := make(map[string]bool)
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	for _, word := range words {
+		word = strings.Trim(word, "-")
+		first := []rune(word)
+		if len(first) < 2 || !unicode.IsUpper(first[0]) {
+			continue
+		}
+		lower := strings.ToLower(word)
+		if !stopWords[lower] {
+			entities[lower] = true
+		}
+	}
+	return entities
+}
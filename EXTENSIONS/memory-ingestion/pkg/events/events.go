@@ -0,0 +1,208 @@
+// Package events distributes connector lifecycle, sync, and ingestion events to live subscribers
+// (e.g. the WebSocket feed of the management API) and records them in an optional event log.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	TypeConnectorAdded   = "connector.added"
+	TypeConnectorUpdated = "connector.updated"
+	TypeConnectorRemoved = "connector.removed"
+	TypeConnectorPaused  = "connector.paused"
+	TypeConnectorResumed = "connector.resumed"
+	TypeSyncStarted      = "sync.started"
+	TypeSyncCompleted    = "sync.completed"
+	TypeSyncFailed       = "sync.failed"
+	TypeSyncSkipped      = "sync.skipped"
+	TypeSyncQueued       = "sync.queued"
+	TypeBatchCompleted   = "batch.completed"
+	TypeMemoryIngested   = "memory.ingested"
+	TypeMemoryFailed     = "memory.failed"
+	TypeMemoryBlocked    = "memory.blocked"
+	TypeMemoryReingested = "memory.reingested"
+)
+
+// Event is a single event. Data depends on the type.
+type Event struct {
+	ID          uint64      `json:"id"` // sequence number, increasing per bus
+	Type        string      `json:"type"`
+	ConnectorID string      `json:"connector_id,omitempty"`
+	Timestamp   time.Time   `json:"timestamp"`
+	Data        interface{} `json:"data,omitempty"`
+}
+
+// Publisher defines the interface for publishing events
+type Publisher interface {
+	// Publish delivers an event to all matching subscribers without blocking
+	Publish(event Event)
+}
+
+// Publishers fans events out to several publishers
+type Publishers []Publisher
+
+// Publish delivers an event to every publisher
+func (p Publishers) Publish(event Event) {
+	for _, publisher := range p {
+		publisher.Publish(event)
+	}
+}
+
+// Filter selects events. Empty fields match everything.
+type Filter struct {
+	ConnectorIDs []string `json:"connector_ids,omitempty"`
+	Types        []string `json:"types,omitempty"`
+}
+
+// Matches returns true if the event passes the filter
+func (f Filter) Matches(event Event) bool {
+	return matches(f.ConnectorIDs, event.ConnectorID) && matches(f.Types, event.Type)
+}
+
+// matches returns true if values is empty or contains value
+func matches(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Bus is an in-process event bus. Slow subscribers lose events instead of blocking publishers.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+	sequence    uint64
+	closed      bool
+}
+
+// NewBus creates an event bus
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish delivers an event to all subscribers whose filter matches
+func (b *Bus) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.sequence++
+	event.ID = b.sequence
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	for subscription := range b.subscribers {
+		subscription.deliver(event)
+	}
+}
+
+// SetSequence continues the event IDs after sequence, e.g. the last ID of a persisted event log.
+// Must be called before events are published.
+func (b *Bus) SetSequence(sequence uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sequence = sequence
+}
+
+// Subscribe registers a subscriber that buffers up to buffer events. Call Close when done.
+func (b *Bus) Subscribe(filter Filter, buffer int) *Subscription {
+	events := make(chan Event, buffer)
+	subscription := &Subscription{C: events, events: events, filter: filter, bus: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(events)
+		return subscription
+	}
+	b.subscribers[subscription] = struct{}{}
+	return subscription
+}
+
+// Close ends all subscriptions; their channels are closed. Later events are discarded.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for subscription := range b.subscribers {
+		delete(b.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// Subscription receives the events of a bus that match its filter
+type Subscription struct {
+	// C delivers the events. It is closed when the subscription or the bus is closed.
+	C <-chan Event
+
+	events  chan Event
+	filter  Filter
+	dropped uint64
+	bus     *Bus
+	mu      sync.Mutex
+}
+
+// SetFilter replaces the filter of the subscription
+func (s *Subscription) SetFilter(filter Filter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.filter = filter
+}
+
+// Dropped returns and resets the number of events lost because the buffer was full
+func (s *Subscription) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dropped := s.dropped
+	s.dropped = 0
+	return dropped
+}
+
+// Close unsubscribes and closes C
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if _, ok := s.bus.subscribers[s]; ok {
+		delete(s.bus.subscribers, s)
+		close(s.events)
+	}
+}
+
+// deliver queues an event if it matches, dropping it if the buffer is full. Called with the bus locked.
+func (s *Subscription) deliver(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.filter.Matches(event) {
+		return
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		s.dropped++
+	}
+}
@@ -0,0 +1,194 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// storeBuffer is the number of events the store buffers while writing
+const storeBuffer = 4096
+
+// Query selects recorded events, newest first
+type Query struct {
+	Filter
+	Since  time.Time // events at or after Since, zero for no lower bound
+	Until  time.Time // events before Until, zero for no upper bound
+	Limit  int       // maximum number of events, 0 for all
+	Offset int       // number of matching events to skip
+}
+
+// Matches returns true if the event passes the query's filters
+func (q Query) Matches(event Event) bool {
+	if !q.Since.IsZero() && event.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !event.Timestamp.Before(q.Until) {
+		return false
+	}
+	return q.Filter.Matches(event)
+}
+
+// Page is a page of recorded events
+type Page struct {
+	Events []Event `json:"events"`
+	Total  int     `json:"total"` // matching events across all pages
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
+// Store is an append-only event log stored as JSON Lines. It records the events of a bus for
+// forensic queries after the fact; unlike the live feed, it survives restarts.
+type Store struct {
+	path   string
+	logger *zap.Logger
+	mu     sync.RWMutex // guards the file against queries reading a partially written event
+	file   *os.File
+	done   chan struct{}
+}
+
+// NewStore opens (or creates) the event log at path
+func NewStore(path string, logger *zap.Logger) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create event log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+
+	logger.Info("Initialized event log", zap.String("path", path))
+
+	return &Store{path: path, logger: logger, file: file}, nil
+}
+
+// LastID returns the ID of the last recorded event, 0 if none was recorded
+func (s *Store) LastID() (uint64, error) {
+	var last uint64
+	err := s.scan(func(event Event) {
+		if event.ID > last {
+			last = event.ID
+		}
+	})
+	return last, err
+}
+
+// Record appends the events of bus in the background until the bus is closed. Call Close
+// afterwards to wait for the remaining events to be written.
+func (s *Store) Record(bus *Bus) {
+	subscription := bus.Subscribe(Filter{}, storeBuffer)
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		for event := range subscription.C {
+			if dropped := subscription.Dropped(); dropped > 0 {
+				s.logger.Warn("Event log fell behind, events were not recorded", zap.Uint64("count", dropped))
+			}
+			if err := s.append(event); err != nil {
+				s.logger.Error("Failed to record event",
+					zap.String("type", event.Type),
+					zap.Error(err),
+				)
+			}
+		}
+	}()
+}
+
+// append writes an event as one line
+func (s *Store) append(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
+
+// Query returns a page of the recorded events matching the query, newest first
+func (s *Store) Query(query Query) (*Page, error) {
+	// Only the newest offset+limit matches are needed for the page
+	keep := 0
+	if query.Limit > 0 {
+		keep = query.Offset + query.Limit
+	}
+
+	total := 0
+	var matched []Event
+	err := s.scan(func(event Event) {
+		if !query.Matches(event) {
+			return
+		}
+		total++
+		matched = append(matched, event)
+		if keep > 0 && len(matched) > 2*keep {
+			matched = append(matched[:0], matched[len(matched)-keep:]...)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+
+	page := &Page{Events: []Event{}, Total: total, Limit: query.Limit, Offset: query.Offset}
+	if query.Offset < len(matched) {
+		matched = matched[query.Offset:]
+		if query.Limit > 0 && len(matched) > query.Limit {
+			matched = matched[:query.Limit]
+		}
+		page.Events = matched
+	}
+	return page, nil
+}
+
+// scan decodes the recorded events in order
+func (s *Store) scan(fn func(Event)) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for {
+		var event Event
+		if err := decoder.Decode(&event); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			// A torn last line (e.g. after a crash) loses that event only
+			s.logger.Warn("Failed to decode recorded event", zap.Error(err))
+			return nil
+		}
+		fn(event)
+	}
+}
+
+// Close waits until the recorded events are written (the bus must be closed first) and closes the log
+func (s *Store) Close() error {
+	if s.done != nil {
+		<-s.done
+	}
+	return s.file.Close()
+}
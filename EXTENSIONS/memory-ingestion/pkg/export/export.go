@@ -0,0 +1,214 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// ContentType is the media type of exported documents
+const ContentType = "application/x-ndjson"
+
+// maxLineBytes bounds a line of an export read by ReadDocuments, a document's text is on one line
+const maxLineBytes = 64 << 20
+
+// ErrInvalidDocument is returned by ReadDocuments for lines that aren't exported documents
+var ErrInvalidDocument = errors.New("invalid export document")
+
+// defaultTimeout bounds a PUT of an HTTP sink without export.timeout
+const defaultTimeout = 30 * time.Second
+
+// Document is a transformed document, one line of an export
+type Document struct {
+	ID          string            `json:"id"` // "doc-" and the MD5 of the trimmed text, as LightRAG derives it for plain text
+	ConnectorID string            `json:"connector_id"`
+	ContextID   string            `json:"context_id"`
+	Text        string            `json:"text"`
+	Metadata    map[string]string `json:"metadata"`
+	ExportedAt  time.Time         `json:"exported_at"`
+}
+
+// NewDocument creates the export document of a connector's transformed text, exported at a time
+func NewDocument(config *models.ConnectorConfig, text string, metadata map[string]string, exportedAt time.Time) *Document {
+	return &Document{
+		ID:          DocumentID(text),
+		ConnectorID: config.ID,
+		ContextID:   config.ContextID,
+		Text:        text,
+		Metadata:    metadata,
+		ExportedAt:  exportedAt.UTC(),
+	}
+}
+
+// DocumentID returns the ID LightRAG derives for a plain text document: "doc-" and the MD5 of
+// the trimmed text
+func DocumentID(text string) string {
+	sum := md5.Sum([]byte(strings.TrimSpace(text)))
+	return "doc-" + hex.EncodeToString(sum[:])
+}
+
+// MemoryIDs returns the IDs of the memories a document was built from: its memory_id, or the
+// memory_ids of a daily digest. Documents exported without include_metadata have none.
+func (d *Document) MemoryIDs() []string {
+	if id := d.Metadata["memory_id"]; id != "" {
+		return []string{id}
+	}
+	if ids := d.Metadata["memory_ids"]; ids != "" {
+		return strings.Split(ids, ",")
+	}
+	return nil
+}
+
+// ReadDocuments reads the documents of a JSON Lines export, skipping blank lines
+func ReadDocuments(r io.Reader) ([]Document, error) {
+	var documents []Document
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineBytes)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var document Document
+		if err := json.Unmarshal(scanner.Bytes(), &document); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidDocument, line, err)
+		}
+		if strings.TrimSpace(document.Text) == "" {
+			return nil, fmt.Errorf("%w: line %d: no text", ErrInvalidDocument, line)
+		}
+		documents = append(documents, document)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+	return documents, nil
+}
+
+// Sink receives the exported documents of a connector
+type Sink interface {
+	// Write exports a document
+	Write(ctx context.Context, document *Document) error
+}
+
+// New creates the sink of a connector's export settings, nil if it doesn't export
+func New(config models.ExportConfig) Sink {
+	switch {
+	case config.Path != "":
+		return &FileSink{path: config.Path}
+	case config.URL != "":
+		timeout := defaultTimeout
+		if config.Timeout > 0 {
+			timeout = time.Duration(config.Timeout) * time.Second
+		}
+		return &HTTPSink{
+			url:        config.URL,
+			headers:    config.Headers,
+			httpClient: &http.Client{Timeout: timeout},
+		}
+	default:
+		return nil
+	}
+}
+
+// FileSink appends documents to a JSON Lines file
+type FileSink struct {
+	path string
+	mu   sync.Mutex // serializes the connector's concurrent writes
+}
+
+// Write appends a document as one line, creating the file and its directory if needed
+func (s *FileSink) Write(ctx context.Context, document *Document) error {
+	line, err := encodeLine(document)
+	if err != nil {
+		return err
+	}
+	path := expand(s.path, document, func(value string) string {
+		return strings.NewReplacer("/", "_", string(filepath.Separator), "_").Replace(value)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open export file: %w", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	return file.Close()
+}
+
+// HTTPSink PUTs each document to an object store as a one-line JSON Lines object
+type HTTPSink struct {
+	url        string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+// Write PUTs a document to its object URL. Documents of the same text share the URL, so a retried
+// export overwrites rather than duplicates.
+func (s *HTTPSink) Write(ctx context.Context, document *Document) error {
+	line, err := encodeLine(document)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, expand(s.url, document, url.PathEscape), bytes.NewReader(line))
+	if err != nil {
+		return fmt.Errorf("failed to create export request: %w", err)
+	}
+	req.Header.Set("Content-Type", ContentType)
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("export request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("export request failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// encodeLine encodes a document as a JSON line
+func encodeLine(document *Document) ([]byte, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal export document: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// expand replaces the placeholders of a path or URL template with the document's values, escaped
+func expand(template string, document *Document, escape func(string) string) string {
+	return strings.NewReplacer(
+		"{document_id}", escape(document.ID),
+		"{connector_id}", escape(document.ConnectorID),
+		"{context_id}", escape(document.ContextID),
+		"{date}", document.ExportedAt.Format("2006-01-02"),
+	).Replace(template)
+}
@@ -0,0 +1,112 @@
+// Package features keeps the runtime overrides of connectors' feature flags, so an operator can
+// roll an experimental behavior out to one connector at a time, or turn it off again, without
+// editing the configuration. Overrides take precedence over the connectors' features settings.
+package features
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// ErrUnknownFlag is wrapped by the errors of Set for flags that don't exist
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Overrides holds the feature flag overrides of each connector, stored in a JSON file
+type Overrides struct {
+	path string
+
+	mu         sync.RWMutex
+	connectors map[string]map[string]bool // connector ID -> flag -> enabled
+}
+
+// New creates overrides stored at path, loading the overrides saved there
+func New(path string, logger *zap.Logger) (*Overrides, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create feature flag directory: %w", err)
+	}
+
+	o := &Overrides{path: path, connectors: make(map[string]map[string]bool)}
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read feature flag overrides: %w", err)
+	default:
+		if err := json.Unmarshal(data, &o.connectors); err != nil {
+			return nil, fmt.Errorf("failed to decode feature flag overrides: %w", err)
+		}
+	}
+
+	logger.Info("Initialized feature flag overrides", zap.String("path", path), zap.Int("connectors", len(o.connectors)))
+	return o, nil
+}
+
+// For returns a copy of the overrides of a connector, flag -> enabled
+func (o *Overrides) For(connectorID string) map[string]bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	overrides := make(map[string]bool, len(o.connectors[connectorID]))
+	for flag, enabled := range o.connectors[connectorID] {
+		overrides[flag] = enabled
+	}
+	return overrides
+}
+
+// Set overrides a feature flag of a connector, or removes its override if enabled is nil. It fails
+// with ErrUnknownFlag for flags that don't exist.
+func (o *Overrides) Set(connectorID, flag string, enabled *bool) error {
+	if _, ok := models.LookupFeatureFlag(flag); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, flag)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	overrides := make(map[string]bool, len(o.connectors[connectorID])+1)
+	for other, otherEnabled := range o.connectors[connectorID] {
+		if other != flag {
+			overrides[other] = otherEnabled
+		}
+	}
+	if enabled != nil {
+		overrides[flag] = *enabled
+	}
+	return o.save(connectorID, overrides)
+}
+
+// save replaces the overrides of a connector and writes them, replacing the previous file
+// atomically. Called with o.mu held.
+func (o *Overrides) save(connectorID string, overrides map[string]bool) error {
+	connectors := make(map[string]map[string]bool, len(o.connectors)+1)
+	for other, otherOverrides := range o.connectors {
+		connectors[other] = otherOverrides
+	}
+	if len(overrides) > 0 {
+		connectors[connectorID] = overrides
+	} else {
+		delete(connectors, connectorID)
+	}
+
+	data, err := json.MarshalIndent(connectors, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode feature flag overrides: %w", err)
+	}
+	tmp := o.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write feature flag overrides: %w", err)
+	}
+	if err := os.Rename(tmp, o.path); err != nil {
+		return fmt.Errorf("failed to write feature flag overrides: %w", err)
+	}
+
+	o.connectors = connectors
+	return nil
+}
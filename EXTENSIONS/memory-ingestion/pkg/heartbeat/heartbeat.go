@@ -0,0 +1,137 @@
+// Package heartbeat pings external monitors (healthchecks.io, Cronitor, Uptime Kuma push monitors, ...)
+// after successful scheduled syncs. The monitor alerts when pings stop arriving, which also catches a
+// service that is alive but no longer syncing (stuck, misconfigured, or paused for too long).
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/events"
+	"go.uber.org/zap"
+)
+
+// Ping settings
+const (
+	pingTimeout  = 10 * time.Second
+	pingAttempts = 3
+	pingBackoff  = 2 * time.Second
+)
+
+// URLSource returns the heartbeat URL of a connector, empty if it has none
+type URLSource func(connectorID string) string
+
+// Heartbeat pings the heartbeat URL of a connector after each of its successful scheduled syncs.
+// It consumes sync events, so it's wired in as an events.Publisher.
+type Heartbeat struct {
+	urls       URLSource
+	httpClient *http.Client
+	logger     *zap.Logger
+	pending    sync.WaitGroup
+}
+
+// NewHeartbeat creates a heartbeat pinging the URLs returned by urls
+func NewHeartbeat(urls URLSource, logger *zap.Logger) *Heartbeat {
+	return &Heartbeat{
+		urls:       urls,
+		httpClient: &http.Client{Timeout: pingTimeout},
+		logger:     logger,
+	}
+}
+
+// Publish pings the connector's heartbeat URL in the background if the event is a successful
+// (completed or partial) scheduled sync, or early retry of one. Manual and triggered syncs don't
+// count, since the monitor checks that the schedule keeps running.
+func (h *Heartbeat) Publish(event events.Event) {
+	if event.Type != events.TypeSyncCompleted {
+		return
+	}
+	data, _ := event.Data.(map[string]interface{})
+	if trigger, _ := data["trigger"].(string); trigger != "scheduled" && trigger != "retry" {
+		return
+	}
+	if status, _ := data["status"].(string); status == "interrupted" {
+		return
+	}
+
+	pingURL := h.urls(event.ConnectorID)
+	if pingURL == "" {
+		return
+	}
+
+	h.pending.Add(1)
+	go func() {
+		defer h.pending.Done()
+		h.ping(event.ConnectorID, pingURL)
+	}()
+}
+
+// ping requests the URL, retrying failed attempts. The URL isn't logged since it contains the check's secret.
+func (h *Heartbeat) ping(connectorID, pingURL string) {
+	var err error
+	for attempt := 1; attempt <= pingAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(pingBackoff * time.Duration(attempt-1))
+		}
+
+		if err = h.get(pingURL); err == nil {
+			h.logger.Debug("Sent heartbeat", zap.String("connector_id", connectorID))
+			return
+		}
+	}
+
+	h.logger.Warn("Failed to send heartbeat",
+		zap.String("connector_id", connectorID),
+		zap.Int("attempts", pingAttempts),
+		zap.Error(err),
+	)
+}
+
+// get requests pingURL and fails on non-2xx responses. Errors don't contain the URL.
+func (h *Heartbeat) get(pingURL string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pingURL, nil)
+	if err != nil {
+		return errors.New("failed to create request")
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Wait blocks until pings in progress are done or ctx is done
+func (h *Heartbeat) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
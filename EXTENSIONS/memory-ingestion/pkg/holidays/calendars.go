@@ -0,0 +1,233 @@
+package holidays
+
+import "time"
+
+// calendars are the nationwide public holidays of each supported country, by ISO 3166-1 alpha-2
+// code, on their calendar dates. Regional holidays and substitute days off aren't included.
+var calendars = map[string][]rule{
+	"AT": {
+		fixed("New Year's Day", time.January, 1),
+		fixed("Epiphany", time.January, 6),
+		easter("Easter Monday", 1),
+		fixed("Labour Day", time.May, 1),
+		easter("Ascension Day", 39),
+		easter("Whit Monday", 50),
+		easter("Corpus Christi", 60),
+		fixed("Assumption Day", time.August, 15),
+		fixed("National Day", time.October, 26),
+		fixed("All Saints' Day", time.November, 1),
+		fixed("Immaculate Conception", time.December, 8),
+		fixed("Christmas Day", time.December, 25),
+		fixed("St. Stephen's Day", time.December, 26),
+	},
+	"AU": {
+		fixed("New Year's Day", time.January, 1),
+		fixed("Australia Day", time.January, 26),
+		easter("Good Friday", -2),
+		easter("Easter Monday", 1),
+		fixed("Anzac Day", time.April, 25),
+		fixed("Christmas Day", time.December, 25),
+		fixed("Boxing Day", time.December, 26),
+	},
+	"BE": {
+		fixed("New Year's Day", time.January, 1),
+		easter("Easter Monday", 1),
+		fixed("Labour Day", time.May, 1),
+		easter("Ascension Day", 39),
+		easter("Whit Monday", 50),
+		fixed("National Day", time.July, 21),
+		fixed("Assumption Day", time.August, 15),
+		fixed("All Saints' Day", time.November, 1),
+		fixed("Armistice Day", time.November, 11),
+		fixed("Christmas Day", time.December, 25),
+	},
+	"CA": {
+		fixed("New Year's Day", time.January, 1),
+		easter("Good Friday", -2),
+		weekdayOnOrAfter("Victoria Day", time.May, 18, time.Monday),
+		fixed("Canada Day", time.July, 1),
+		nthWeekday("Labour Day", time.September, time.Monday, 1),
+		between(2021, 0, fixed("National Day for Truth and Reconciliation", time.September, 30)),
+		nthWeekday("Thanksgiving", time.October, time.Monday, 2),
+		fixed("Remembrance Day", time.November, 11),
+		fixed("Christmas Day", time.December, 25),
+		fixed("Boxing Day", time.December, 26),
+	},
+	"DE": {
+		fixed("New Year's Day", time.January, 1),
+		easter("Good Friday", -2),
+		easter("Easter Monday", 1),
+		fixed("Labour Day", time.May, 1),
+		easter("Ascension Day", 39),
+		easter("Whit Monday", 50),
+		fixed("German Unity Day", time.October, 3),
+		fixed("Christmas Day", time.December, 25),
+		fixed("St. Stephen's Day", time.December, 26),
+	},
+	"DK": {
+		fixed("New Year's Day", time.January, 1),
+		easter("Maundy Thursday", -3),
+		easter("Good Friday", -2),
+		easter("Easter Sunday", 0),
+		easter("Easter Monday", 1),
+		between(0, 2023, easter("Great Prayer Day", 26)),
+		easter("Ascension Day", 39),
+		easter("Whit Sunday", 49),
+		easter("Whit Monday", 50),
+		fixed("Christmas Day", time.December, 25),
+		fixed("St. Stephen's Day", time.December, 26),
+	},
+	"ES": {
+		fixed("New Year's Day", time.January, 1),
+		fixed("Epiphany", time.January, 6),
+		easter("Good Friday", -2),
+		fixed("Labour Day", time.May, 1),
+		fixed("Assumption Day", time.August, 15),
+		fixed("National Day", time.October, 12),
+		fixed("All Saints' Day", time.November, 1),
+		fixed("Constitution Day", time.December, 6),
+		fixed("Immaculate Conception", time.December, 8),
+		fixed("Christmas Day", time.December, 25),
+	},
+	"FR": {
+		fixed("New Year's Day", time.January, 1),
+		easter("Easter Monday", 1),
+		fixed("Labour Day", time.May, 1),
+		fixed("Victory in Europe Day", time.May, 8),
+		easter("Ascension Day", 39),
+		easter("Whit Monday", 50),
+		fixed("Bastille Day", time.July, 14),
+		fixed("Assumption Day", time.August, 15),
+		fixed("All Saints' Day", time.November, 1),
+		fixed("Armistice Day", time.November, 11),
+		fixed("Christmas Day", time.December, 25),
+	},
+	"GB": {
+		fixed("New Year's Day", time.January, 1),
+		easter("Good Friday", -2),
+		easter("Easter Monday", 1),
+		nthWeekday("Early May Bank Holiday", time.May, time.Monday, 1),
+		nthWeekday("Spring Bank Holiday", time.May, time.Monday, -1),
+		nthWeekday("Summer Bank Holiday", time.August, time.Monday, -1),
+		fixed("Christmas Day", time.December, 25),
+		fixed("Boxing Day", time.December, 26),
+	},
+	"IT": {
+		fixed("New Year's Day", time.January, 1),
+		fixed("Epiphany", time.January, 6),
+		easter("Easter Sunday", 0),
+		easter("Easter Monday", 1),
+		fixed("Liberation Day", time.April, 25),
+		fixed("Labour Day", time.May, 1),
+		fixed("Republic Day", time.June, 2),
+		fixed("Assumption Day", time.August, 15),
+		fixed("All Saints' Day", time.November, 1),
+		fixed("Immaculate Conception", time.December, 8),
+		fixed("Christmas Day", time.December, 25),
+		fixed("St. Stephen's Day", time.December, 26),
+	},
+	"NL": {
+		fixed("New Year's Day", time.January, 1),
+		easter("Good Friday", -2),
+		easter("Easter Sunday", 0),
+		easter("Easter Monday", 1),
+		{name: "King's Day", date: func(year int) (time.Month, int, bool) {
+			// Moved to the Saturday before if it falls on a Sunday
+			if time.Date(year, time.April, 27, 0, 0, 0, 0, time.UTC).Weekday() == time.Sunday {
+				return time.April, 26, true
+			}
+			return time.April, 27, true
+		}},
+		fixed("Liberation Day", time.May, 5),
+		easter("Ascension Day", 39),
+		easter("Whit Sunday", 49),
+		easter("Whit Monday", 50),
+		fixed("Christmas Day", time.December, 25),
+		fixed("Second Day of Christmas", time.December, 26),
+	},
+	"NO": {
+		fixed("New Year's Day", time.January, 1),
+		easter("Maundy Thursday", -3),
+		easter("Good Friday", -2),
+		easter("Easter Sunday", 0),
+		easter("Easter Monday", 1),
+		fixed("Labour Day", time.May, 1),
+		fixed("Constitution Day", time.May, 17),
+		easter("Ascension Day", 39),
+		easter("Whit Sunday", 49),
+		easter("Whit Monday", 50),
+		fixed("Christmas Day", time.December, 25),
+		fixed("St. Stephen's Day", time.December, 26),
+	},
+	"NZ": {
+		fixed("New Year's Day", time.January, 1),
+		fixed("Day after New Year's Day", time.January, 2),
+		fixed("Waitangi Day", time.February, 6),
+		easter("Good Friday", -2),
+		easter("Easter Monday", 1),
+		fixed("Anzac Day", time.April, 25),
+		nthWeekday("King's Birthday", time.June, time.Monday, 1),
+		nthWeekday("Labour Day", time.October, time.Monday, 4),
+		fixed("Christmas Day", time.December, 25),
+		fixed("Boxing Day", time.December, 26),
+	},
+	"PL": {
+		fixed("New Year's Day", time.January, 1),
+		fixed("Epiphany", time.January, 6),
+		easter("Easter Sunday", 0),
+		easter("Easter Monday", 1),
+		fixed("Labour Day", time.May, 1),
+		fixed("Constitution Day", time.May, 3),
+		easter("Whit Sunday", 49),
+		easter("Corpus Christi", 60),
+		fixed("Assumption Day", time.August, 15),
+		fixed("All Saints' Day", time.November, 1),
+		fixed("Independence Day", time.November, 11),
+		between(2025, 0, fixed("Christmas Eve", time.December, 24)),
+		fixed("Christmas Day", time.December, 25),
+		fixed("Second Day of Christmas", time.December, 26),
+	},
+	"PT": {
+		fixed("New Year's Day", time.January, 1),
+		easter("Good Friday", -2),
+		easter("Easter Sunday", 0),
+		fixed("Freedom Day", time.April, 25),
+		fixed("Labour Day", time.May, 1),
+		easter("Corpus Christi", 60),
+		fixed("Portugal Day", time.June, 10),
+		fixed("Assumption Day", time.August, 15),
+		fixed("Republic Day", time.October, 5),
+		fixed("All Saints' Day", time.November, 1),
+		fixed("Restoration of Independence", time.December, 1),
+		fixed("Immaculate Conception", time.December, 8),
+		fixed("Christmas Day", time.December, 25),
+	},
+	"SE": {
+		fixed("New Year's Day", time.January, 1),
+		fixed("Epiphany", time.January, 6),
+		easter("Good Friday", -2),
+		easter("Easter Sunday", 0),
+		easter("Easter Monday", 1),
+		fixed("May Day", time.May, 1),
+		easter("Ascension Day", 39),
+		easter("Whit Sunday", 49),
+		fixed("National Day", time.June, 6),
+		weekdayOnOrAfter("Midsummer Day", time.June, 20, time.Saturday),
+		weekdayOnOrAfter("All Saints' Day", time.October, 31, time.Saturday),
+		fixed("Christmas Day", time.December, 25),
+		fixed("Boxing Day", time.December, 26),
+	},
+	"US": {
+		fixed("New Year's Day", time.January, 1),
+		nthWeekday("Martin Luther King Jr. Day", time.January, time.Monday, 3),
+		nthWeekday("Washington's Birthday", time.February, time.Monday, 3),
+		nthWeekday("Memorial Day", time.May, time.Monday, -1),
+		between(2021, 0, fixed("Juneteenth", time.June, 19)),
+		fixed("Independence Day", time.July, 4),
+		nthWeekday("Labor Day", time.September, time.Monday, 1),
+		nthWeekday("Columbus Day", time.October, time.Monday, 2),
+		fixed("Veterans Day", time.November, 11),
+		nthWeekday("Thanksgiving Day", time.November, time.Thursday, 4),
+		fixed("Christmas Day", time.December, 25),
+	},
+}
@@ -0,0 +1,113 @@
+// Package holidays knows the national public holidays of a set of countries, computed from
+// embedded rules, so memories can be placed in their social calendar without a lookup service
+package holidays
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// rule is a public holiday that falls on a date computed for each year
+type rule struct {
+	name string
+	date func(year int) (time.Month, int, bool) // false if there's no holiday that year
+}
+
+// fixed is a holiday on the same date each year
+func fixed(name string, month time.Month, day int) rule {
+	return rule{name: name, date: func(int) (time.Month, int, bool) {
+		return month, day, true
+	}}
+}
+
+// easter is a holiday offset days from Easter Sunday
+func easter(name string, offset int) rule {
+	return rule{name: name, date: func(year int) (time.Month, int, bool) {
+		month, day := EasterSunday(year)
+		date := time.Date(year, month, day+offset, 0, 0, 0, 0, time.UTC)
+		return date.Month(), date.Day(), true
+	}}
+}
+
+// nthWeekday is a holiday on the nth weekday of a month, counted from its end if n is negative
+// (-1 is the last)
+func nthWeekday(name string, month time.Month, weekday time.Weekday, n int) rule {
+	return rule{name: name, date: func(year int) (time.Month, int, bool) {
+		if n < 0 {
+			last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+			day := last.Day() - (int(last.Weekday())-int(weekday)+7)%7
+			return month, day + 7*(n+1), true
+		}
+		first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		day := 1 + (int(weekday)-int(first.Weekday())+7)%7
+		return month, day + 7*(n-1), true
+	}}
+}
+
+// weekdayOnOrAfter is a holiday on the first weekday on or after a date
+func weekdayOnOrAfter(name string, month time.Month, day int, weekday time.Weekday) rule {
+	return rule{name: name, date: func(year int) (time.Month, int, bool) {
+		date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		date = date.AddDate(0, 0, (int(weekday)-int(date.Weekday())+7)%7)
+		return date.Month(), date.Day(), true
+	}}
+}
+
+// between limits a holiday to the years from first to last (0 for no limit)
+func between(first, last int, r rule) rule {
+	date := r.date
+	r.date = func(year int) (time.Month, int, bool) {
+		if (first != 0 && year < first) || (last != 0 && year > last) {
+			return 0, 0, false
+		}
+		return date(year)
+	}
+	return r
+}
+
+// EasterSunday returns the date of Easter Sunday in the Gregorian calendar (anonymous Gregorian
+// algorithm)
+func EasterSunday(year int) (time.Month, int) {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Month(month), day
+}
+
+// Supported returns true if the public holidays of a country (ISO 3166-1 alpha-2 code, any case)
+// are known
+func Supported(country string) bool {
+	_, ok := calendars[strings.ToUpper(country)]
+	return ok
+}
+
+// Countries returns the codes of the countries whose public holidays are known, sorted
+func Countries() []string {
+	countries := make([]string, 0, len(calendars))
+	for country := range calendars {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	return countries
+}
+
+// On returns the names of the public holidays of a country on the date of t (in t's location),
+// nil if there are none or the country isn't supported
+func On(country string, t time.Time) []string {
+	var names []string
+	for _, r := range calendars[strings.ToUpper(country)] {
+		if month, day, ok := r.date(t.Year()); ok && month == t.Month() && day == t.Day() {
+			names = append(names, r.name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,122 @@
+// Package language detects the language of memory transcripts
+package language
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minStopWords is the number of stop words a Latin-script text needs before its language is guessed
+const minStopWords = 2
+
+// scripts maps writing systems used by a single language (or a dominant one) to its ISO 639-1 code
+var scripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// stopWords are frequent words of the supported Latin-script languages. Words shared by several
+// languages count for each of them.
+var stopWords = map[string][]string{
+	"en": {"the", "and", "is", "are", "was", "were", "of", "to", "in", "that", "it", "with", "for", "this", "have", "had", "you", "we", "they", "not", "but", "on", "at", "be", "from", "my", "about", "will", "would"},
+	"de": {"der", "die", "das", "und", "ist", "sind", "war", "nicht", "ich", "wir", "sie", "es", "mit", "auf", "für", "ein", "eine", "einen", "dem", "den", "zu", "von", "auch", "haben", "hat", "noch", "aber", "wie", "morgen", "heute"},
+	"fr": {"le", "la", "les", "et", "est", "sont", "une", "des", "du", "je", "nous", "vous", "il", "elle", "pas", "avec", "pour", "dans", "que", "qui", "sur", "au", "aux", "ce", "mais", "avons", "ont", "demain"},
+	"es": {"el", "la", "los", "las", "y", "es", "son", "una", "del", "que", "yo", "nosotros", "con", "para", "por", "en", "pero", "como", "muy", "está", "hay", "mañana", "hoy", "lo", "se"},
+	"it": {"il", "la", "gli", "le", "e", "è", "sono", "una", "che", "non", "con", "per", "della", "del", "di", "io", "noi", "anche", "ma", "come", "molto", "domani", "oggi", "ho", "abbiamo"},
+	"pt": {"o", "a", "os", "as", "e", "é", "são", "uma", "um", "que", "não", "com", "para", "por", "do", "da", "eu", "nós", "mas", "como", "muito", "amanhã", "hoje", "em", "foi"},
+	"nl": {"de", "het", "een", "en", "is", "zijn", "was", "niet", "ik", "wij", "we", "met", "op", "voor", "van", "dat", "die", "ook", "maar", "hebben", "heeft", "morgen", "vandaag", "naar"},
+}
+
+// stopWordLanguages maps each stop word to the languages using it
+var stopWordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range stopWords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// Detect returns the ISO 639-1 code of the language text is written in, or "" if it can't tell.
+// Non-Latin scripts are detected by their characters; Latin-script languages (en, de, fr, es,
+// it, pt, nl) by their stop words.
+func Detect(text string) string {
+	if language := detectScript(text); language != "" {
+		return language
+	}
+	return detectStopWords(text)
+}
+
+// detectScript returns the language of the non-Latin script most letters of text belong to
+func detectScript(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, script := range scripts {
+			if unicode.Is(script.table, r) {
+				counts[script.language]++
+				break
+			}
+		}
+	}
+
+	// Japanese mixes kana with Han characters, so any kana decides for Japanese
+	if counts["ja"] > 0 && counts["ja"]+counts["zh"] > letters/2 {
+		return "ja"
+	}
+	best, bestCount := "", 0
+	for _, script := range scripts {
+		if count := counts[script.language]; count > bestCount {
+			best, bestCount = script.language, count
+		}
+	}
+	if bestCount > letters/2 {
+		return best
+	}
+	return ""
+}
+
+// detectStopWords returns the Latin-script language with the most stop words in text, if it has
+// enough of them and more than any other language
+func detectStopWords(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+
+	scores := make(map[string]int)
+	for _, word := range words {
+		for _, language := range stopWordLanguages[word] {
+			scores[language]++
+		}
+	}
+
+	best, bestScore, tie := "", 0, false
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = language, score, false
+		case score == bestScore:
+			tie = true
+		}
+	}
+	if bestScore < minStopWords || tie {
+		return ""
+	}
+	return best
+}
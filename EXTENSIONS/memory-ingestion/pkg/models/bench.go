@@ -0,0 +1,28 @@
+package models
+
+// BenchmarkResult is the outcome of a benchmark of LightRAG ingestion throughput
+type BenchmarkResult struct {
+	ConnectorID      string         `json:"connector_id"` // whose transform settings built the documents
+	Strategy         string         `json:"strategy"`
+	Runs             []BenchmarkRun `json:"runs"`                        // one per concurrency level
+	DeletedDocuments int            `json:"deleted_documents,omitempty"` // benchmark documents deleted afterwards
+}
+
+// BenchmarkRun measures the documents pushed through the pipeline at one concurrency level.
+// Latencies are per document, from its transformation to LightRAG accepting it.
+type BenchmarkRun struct {
+	Concurrency   int            `json:"concurrency"`
+	Documents     int            `json:"documents"`
+	Failed        int            `json:"failed"`
+	ErrorRate     float64        `json:"error_rate"`       // failed share of the documents, 0 to 1
+	Errors        map[string]int `json:"errors,omitempty"` // failure category -> documents
+	DurationMs    int64          `json:"duration_ms"`
+	DocsPerSecond float64        `json:"docs_per_second"` // inserted documents per second
+	P50LatencyMs  int64          `json:"p50_latency_ms"`
+	P95LatencyMs  int64          `json:"p95_latency_ms"`
+	MaxLatencyMs  int64          `json:"max_latency_ms"`
+
+	// ProcessedPerSecond is the rate LightRAG's pipeline processed the inserted documents at, from
+	// the first insert until its queue drained; only if the benchmark waits for the pipeline
+	ProcessedPerSecond float64 `json:"processed_per_second,omitempty"`
+}
@@ -2,18 +2,76 @@ package models
 
 import (
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 )
 
 // ConnectorConfig represents a single memory ingestion connector
 type ConnectorConfig struct {
-	ID         string            `json:"id" yaml:"id" mapstructure:"id" validate:"required"`
-	Enabled    bool              `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
-	ContextID  string            `json:"context_id" yaml:"context_id" mapstructure:"context_id" validate:"required"`
-	Schedule   ScheduleConfig    `json:"schedule" yaml:"schedule" mapstructure:"schedule"`
-	Ingestion  IngestionConfig   `json:"ingestion" yaml:"ingestion" mapstructure:"ingestion"`
-	Transform  TransformConfig   `json:"transform" yaml:"transform" mapstructure:"transform"`
-	Metadata   map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty" mapstructure:"metadata,omitempty"`
+	ID        string            `json:"id" yaml:"id" mapstructure:"id" validate:"required"`
+	Template  string            `json:"template,omitempty" yaml:"template,omitempty" mapstructure:"template,omitempty"` // name of a connector template to inherit from
+	Enabled   bool              `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	ContextID string            `json:"context_id" yaml:"context_id" mapstructure:"context_id" validate:"required"`
+	Schedule  ScheduleConfig    `json:"schedule" yaml:"schedule" mapstructure:"schedule"`
+	Ingestion IngestionConfig   `json:"ingestion" yaml:"ingestion" mapstructure:"ingestion"`
+	Transform TransformConfig   `json:"transform" yaml:"transform" mapstructure:"transform"`
+	Quota     QuotaConfig       `json:"quota,omitempty" yaml:"quota,omitempty" mapstructure:"quota,omitempty"`
+	Priority  int               `json:"priority,omitempty" yaml:"priority,omitempty" mapstructure:"priority,omitempty"` // higher-priority syncs leave the queue first
+	Metadata  map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty" mapstructure:"metadata,omitempty"`
+
+	// MemoryAPI overrides the global memory_api endpoint and credentials for this connector,
+	// so one deployment can serve several users/tenants with separate upstream accounts
+	MemoryAPI *MemoryAPIOverride `json:"memory_api,omitempty" yaml:"memory_api,omitempty" mapstructure:"memory_api,omitempty"`
+
+	// Heartbeat is pinged after every successful scheduled sync, so an external monitor notices missed runs
+	Heartbeat HeartbeatConfig `json:"heartbeat,omitempty" yaml:"heartbeat,omitempty" mapstructure:"heartbeat,omitempty"`
+
+	// DeepLink is the URL template of a memory in the source system's app, e.g.
+	// https://app.example.com/memories/{memory_id}, returned by memory lookups
+	DeepLink string `json:"deep_link,omitempty" yaml:"deep_link,omitempty" mapstructure:"deep_link,omitempty"`
+
+	// Export writes the transformed documents as JSON Lines, next to or instead of inserting them into LightRAG
+	Export ExportConfig `json:"export,omitempty" yaml:"export,omitempty" mapstructure:"export,omitempty"`
+
+	// Simulation generates synthetic memories instead of fetching them from the Memory API
+	Simulation SimulationConfig `json:"simulation,omitempty" yaml:"simulation,omitempty" mapstructure:"simulation,omitempty"`
+
+	// Features turn the connector's feature flags (see FeatureFlags) on or off, flag -> enabled.
+	// Runtime overrides set through the API take precedence.
+	Features map[string]bool `json:"features,omitempty" yaml:"features,omitempty" mapstructure:"features,omitempty"`
+}
+
+// HeartbeatConfig holds the ping URL of an external monitor (healthchecks.io style)
+type HeartbeatConfig struct {
+	URL string `json:"-" yaml:"url,omitempty" mapstructure:"url,omitempty"` // contains the check's secret, so never served by the API
+}
+
+// MemoryAPIOverride holds per-connector Memory API settings. Unset fields fall back to the global memory_api section.
+type MemoryAPIOverride struct {
+	URL    string       `json:"url,omitempty" yaml:"url,omitempty" mapstructure:"url,omitempty"`
+	APIKey string       `json:"-" yaml:"api_key,omitempty" mapstructure:"api_key,omitempty"`
+	OAuth2 OAuth2Config `json:"oauth2,omitempty" yaml:"oauth2,omitempty" mapstructure:"oauth2,omitempty"`
+}
+
+// HasCredentials returns true if the override sets its own credentials
+func (o *MemoryAPIOverride) HasCredentials() bool {
+	return o.APIKey != "" || o.OAuth2.Enabled()
+}
+
+// OAuth2Config holds OAuth2 client-credentials / refresh-token settings
+type OAuth2Config struct {
+	TokenURL     string   `json:"token_url,omitempty" yaml:"token_url" mapstructure:"token_url"`
+	ClientID     string   `json:"client_id,omitempty" yaml:"client_id" mapstructure:"client_id"`
+	ClientSecret string   `json:"-" yaml:"client_secret" mapstructure:"client_secret"`
+	RefreshToken string   `json:"-" yaml:"refresh_token" mapstructure:"refresh_token"` // optional, enables the refresh-token grant
+	Scopes       []string `json:"scopes,omitempty" yaml:"scopes" mapstructure:"scopes"`
+}
+
+// Enabled returns true if OAuth2 authentication is configured
+func (o OAuth2Config) Enabled() bool {
+	return o.TokenURL != ""
 }
 
 // ScheduleConfig defines when the connector should run
@@ -21,72 +79,695 @@ type ScheduleConfig struct {
 	Type          string `json:"type" yaml:"type" mapstructure:"type" validate:"required,oneof=interval cron manual"`
 	IntervalHours int    `json:"interval_hours,omitempty" yaml:"interval_hours,omitempty" mapstructure:"interval_hours,omitempty"`
 	CronExpr      string `json:"cron_expr,omitempty" yaml:"cron_expr,omitempty" mapstructure:"cron_expr,omitempty"`
+
+	// Windows restrict when scheduled syncs may start (any window matches); manual triggers always run
+	Windows  []ExecutionWindow `json:"windows,omitempty" yaml:"windows,omitempty" mapstructure:"windows,omitempty"`
+	Timezone string            `json:"timezone,omitempty" yaml:"timezone,omitempty" mapstructure:"timezone,omitempty"` // IANA name for the windows, defaults to local time
 }
 
 // IngestionConfig defines what data to pull
 type IngestionConfig struct {
 	QueryRange      string `json:"query_range" yaml:"query_range" mapstructure:"query_range" validate:"required"`
+	MaxCatchUpRange string `json:"max_catch_up_range,omitempty" yaml:"max_catch_up_range,omitempty" mapstructure:"max_catch_up_range,omitempty"` // widest range a run after downtime may query, defaults to month
 	QueryLimit      int    `json:"query_limit" yaml:"query_limit" mapstructure:"query_limit" validate:"min=1,max=1000"`
 	IncludeAudio    bool   `json:"include_audio" yaml:"include_audio" mapstructure:"include_audio"`
 	IncludeImages   bool   `json:"include_images" yaml:"include_images" mapstructure:"include_images"`
 	MaxConcurrency  int    `json:"max_concurrency" yaml:"max_concurrency" mapstructure:"max_concurrency" validate:"min=1,max=50"`
+
+	// Timezone is the IANA name of the time zone of created_at values without an offset (e.g.
+	// 2025-01-15 18:30:00), defaults to UTC. Timestamps with an offset and epoch timestamps are absolute.
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty" mapstructure:"timezone,omitempty"`
+
+	// AdaptiveConcurrency adjusts the number of parallel inserts to LightRAG's latency (AIMD),
+	// starting at MaxConcurrency and ranging from 1 to 50
+	AdaptiveConcurrency bool `json:"adaptive_concurrency,omitempty" yaml:"adaptive_concurrency,omitempty" mapstructure:"adaptive_concurrency,omitempty"`
+
+	// AutoTune adapts both QueryLimit and MaxConcurrency within bounds
+	AutoTune AutoTuneConfig `json:"auto_tune,omitempty" yaml:"auto_tune,omitempty" mapstructure:"auto_tune,omitempty"`
+
+	// Deduplication suppresses memories whose transcript nearly equals an earlier one
+	Deduplication DeduplicationConfig `json:"deduplication,omitempty" yaml:"deduplication,omitempty" mapstructure:"deduplication,omitempty"`
+
+	// Pipeline sizes the stages memories pass through before they're inserted (MaxConcurrency at once)
+	Pipeline PipelineConfig `json:"pipeline,omitempty" yaml:"pipeline,omitempty" mapstructure:"pipeline,omitempty"`
+
+	// FailureThreshold aborts a sync once too many of its memories fail
+	FailureThreshold FailureThresholdConfig `json:"failure_threshold,omitempty" yaml:"failure_threshold,omitempty" mapstructure:"failure_threshold,omitempty"`
+}
+
+// FailureThresholdConfig aborts a sync whose memories mostly fail (e.g. because LightRAG is
+// misconfigured) instead of letting it send the rest after them: once MinMemories have been
+// processed and more than Percent of them failed, the memories not yet started are deferred.
+type FailureThresholdConfig struct {
+	Percent     int `json:"percent,omitempty" yaml:"percent,omitempty" mapstructure:"percent,omitempty" validate:"min=0,max=99"`         // share of failed memories that aborts, 0 never aborts
+	MinMemories int `json:"min_memories,omitempty" yaml:"min_memories,omitempty" mapstructure:"min_memories,omitempty" validate:"min=0"` // processed before the share is judged, defaults to 20
+}
+
+// Enabled returns true if too many failed memories abort a sync
+func (f *FailureThresholdConfig) Enabled() bool {
+	return f.Percent > 0
+}
+
+// Exceeded returns true if failed of processed memories abort a sync
+func (f *FailureThresholdConfig) Exceeded(failed, processed int) bool {
+	return f.Enabled() && processed >= f.MinMemories && failed*100 > f.Percent*processed
+}
+
+// PipelineConfig sizes the stages of a sync: fetched memories are transformed (content, language,
+// and strategy), enriched (enrichers and cross-references), and inserted by separate workers.
+// Each stage hands memories to the next over a queue of QueueSize, so a slow stage stalls the ones
+// before it instead of letting work pile up.
+type PipelineConfig struct {
+	TransformWorkers int `json:"transform_workers,omitempty" yaml:"transform_workers,omitempty" mapstructure:"transform_workers,omitempty" validate:"min=0,max=50"` // defaults to max_concurrency
+	EnrichWorkers    int `json:"enrich_workers,omitempty" yaml:"enrich_workers,omitempty" mapstructure:"enrich_workers,omitempty" validate:"min=0,max=50"`          // defaults to max_concurrency
+	QueueSize        int `json:"queue_size,omitempty" yaml:"queue_size,omitempty" mapstructure:"queue_size,omitempty" validate:"min=0,max=1000"`                    // memories between two stages, defaults to max_concurrency
+}
+
+// DeduplicationConfig defines how near-duplicate transcripts (e.g. a voice memo recorded twice) are handled
+type DeduplicationConfig struct {
+	Enabled   bool    `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	Threshold float64 `json:"threshold,omitempty" yaml:"threshold,omitempty" mapstructure:"threshold"`                    // SimHash similarity from 0 to 1 at which transcripts are duplicates, defaults to 0.9
+	Action    string  `json:"action,omitempty" yaml:"action,omitempty" mapstructure:"action" validate:"oneof=skip merge"` // skip (default) or merge into the original's document
+}
+
+// Actions on near-duplicate memories: drop them, or list them in the original's document
+const (
+	DuplicateActionSkip  = "skip"
+	DuplicateActionMerge = "merge"
+)
+
+// AutoTuneConfig bounds the batch sizes a connector adapts to observed response times and error
+// rates: the query limit of Memory API fetches and the number of parallel LightRAG inserts.
+// QueryLimit and MaxConcurrency become the starting points.
+type AutoTuneConfig struct {
+	Enabled            bool `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	MinQueryLimit      int  `json:"min_query_limit,omitempty" yaml:"min_query_limit,omitempty" mapstructure:"min_query_limit,omitempty"`                // default 10
+	MaxQueryLimit      int  `json:"max_query_limit,omitempty" yaml:"max_query_limit,omitempty" mapstructure:"max_query_limit,omitempty"`                // default 1000
+	MinConcurrency     int  `json:"min_concurrency,omitempty" yaml:"min_concurrency,omitempty" mapstructure:"min_concurrency,omitempty"`                // default 1
+	MaxConcurrency     int  `json:"max_concurrency,omitempty" yaml:"max_concurrency,omitempty" mapstructure:"max_concurrency,omitempty"`                // default 50
+	TargetFetchSeconds int  `json:"target_fetch_seconds,omitempty" yaml:"target_fetch_seconds,omitempty" mapstructure:"target_fetch_seconds,omitempty"` // slower fetches shrink the query limit, default 10
+}
+
+// AdaptsConcurrency returns true if the number of parallel inserts adapts to LightRAG's latency
+func (c *IngestionConfig) AdaptsConcurrency() bool {
+	return c.AdaptiveConcurrency || c.AutoTune.Enabled
+}
+
+// ConcurrencyBounds returns the range adaptive insert concurrency moves in
+func (c *IngestionConfig) ConcurrencyBounds() (int, int) {
+	if c.AutoTune.Enabled {
+		return c.AutoTune.MinConcurrency, c.AutoTune.MaxConcurrency
+	}
+	return 1, 50
+}
+
+// Location returns the time zone of created_at values without an offset, nil (UTC) if unset or invalid
+func (c *IngestionConfig) Location() *time.Location {
+	if c.Timezone == "" {
+		return nil
+	}
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil
+	}
+	return location
 }
 
 // TransformConfig defines transformation options
 type TransformConfig struct {
-	Strategy       string `json:"strategy" yaml:"strategy" mapstructure:"strategy" validate:"required,oneof=standard rich"`
-	IncludeMetadata bool  `json:"include_metadata" yaml:"include_metadata" mapstructure:"include_metadata"`
-	EnrichLocation bool   `json:"enrich_location" yaml:"enrich_location" mapstructure:"enrich_location"`
+	Strategy             string `json:"strategy" yaml:"strategy" mapstructure:"strategy" validate:"required"`                          // a registered transformation strategy, e.g. standard or rich
+	Mode                 string `json:"mode,omitempty" yaml:"mode,omitempty" mapstructure:"mode" validate:"oneof=memory daily_digest"` // memory (default) or daily_digest
+	IncludeMetadata      bool   `json:"include_metadata" yaml:"include_metadata" mapstructure:"include_metadata"`
+	EnrichLocation       bool   `json:"enrich_location" yaml:"enrich_location" mapstructure:"enrich_location"`
+	Anonymize            bool   `json:"anonymize,omitempty" yaml:"anonymize,omitempty" mapstructure:"anonymize"`                                                                                   // replace person names with pseudonyms
+	DetectLanguage       bool   `json:"detect_language,omitempty" yaml:"detect_language,omitempty" mapstructure:"detect_language"`                                                                 // add original_language metadata
+	TargetLanguage       string `json:"target_language,omitempty" yaml:"target_language,omitempty" mapstructure:"target_language"`                                                                 // ISO 639-1 code to translate transcripts to, implies detect_language
+	Timestamps           bool   `json:"timestamps,omitempty" yaml:"timestamps,omitempty" mapstructure:"timestamps"`                                                                                // annotate timed transcripts with [t=mm:ss] markers
+	ContentFilter        bool   `json:"content_filter,omitempty" yaml:"content_filter,omitempty" mapstructure:"content_filter"`                                                                    // block, redact, or tag memories violating content policies
+	LocationPrecision    string `json:"location_precision,omitempty" yaml:"location_precision,omitempty" mapstructure:"location_precision" validate:"oneof=exact street neighborhood city region"` // coarsen coordinates before they enter documents, exact (default) keeps them
+	S2Level              int    `json:"s2_level,omitempty" yaml:"s2_level,omitempty" mapstructure:"s2_level" validate:"min=0,max=30"`                                                              // add location_s2_cell metadata at this S2 level (1 to 30) next to the coordinates
+	ShortCitations       bool   `json:"short_citations,omitempty" yaml:"short_citations,omitempty" mapstructure:"short_citations"`                                                                 // store an 8-character hash as file_path instead of the memory URI
+	LocalTime            bool   `json:"local_time,omitempty" yaml:"local_time,omitempty" mapstructure:"local_time"`                                                                                // date located memories in the time zone of their location (local_created_* metadata, rich headers)
+	Title                string `json:"title,omitempty" yaml:"title,omitempty" mapstructure:"title"`                                                                                               // template of the title on the first line of documents, e.g. "{date} {place}: {words:6}"
+	MetadataProfile      string `json:"metadata_profile,omitempty" yaml:"metadata_profile,omitempty" mapstructure:"metadata_profile"`                                                              // full (default) or minimal
+	ExperimentalStrategy string `json:"experimental_strategy,omitempty" yaml:"experimental_strategy,omitempty" mapstructure:"experimental_strategy"`                                               // registered strategy used instead of strategy while the experimental_strategy feature flag is on
+
+	// Episodes link memories close in time that share entities
+	Episodes EpisodeConfig `json:"episodes,omitempty" yaml:"episodes,omitempty" mapstructure:"episodes"`
+
+	// Trips tag memories recorded away from the user's frequent locations with their trip
+	Trips TripConfig `json:"trips,omitempty" yaml:"trips,omitempty" mapstructure:"trips"`
+
+	// Places label memories recorded at frequent places, clustered by the cluster-places job
+	Places PlaceConfig `json:"places,omitempty" yaml:"places,omitempty" mapstructure:"places"`
+
+	// Enrichers add domain metadata (weather, calendar events, CRM records, ...) to each document, in order
+	Enrichers []EnricherConfig `json:"enrichers,omitempty" yaml:"enrichers,omitempty" mapstructure:"enrichers"`
+
+	// RawFields map JSON fields of memories the Memory model doesn't know yet to metadata keys
+	RawFields map[string]string `json:"raw_fields,omitempty" yaml:"raw_fields,omitempty" mapstructure:"raw_fields"`
+
+	// MetadataSchema types the metadata of documents, checked before they're inserted
+	MetadataSchema MetadataSchemaConfig `json:"metadata_schema,omitempty" yaml:"metadata_schema,omitempty" mapstructure:"metadata_schema"`
+}
+
+// EnricherConfig selects an enricher of a connector's chain
+type EnricherConfig struct {
+	Type     string            `json:"type" yaml:"type" mapstructure:"type"`                                 // http, weather, calendar, location, holiday, or a registered type
+	Required bool              `json:"required,omitempty" yaml:"required,omitempty" mapstructure:"required"` // fail the memory if the enricher fails, instead of ingesting it without its metadata
+	Options  map[string]string `json:"options,omitempty" yaml:"options,omitempty" mapstructure:"options"`    // type-specific, e.g. url
+}
+
+// EpisodeConfig defines how memories are grouped into episodes
+type EpisodeConfig struct {
+	Enabled           bool `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	WindowMinutes     int  `json:"window_minutes,omitempty" yaml:"window_minutes,omitempty" mapstructure:"window_minutes" validate:"min=0"`                // maximum gap between consecutive memories, defaults to 60
+	MinSharedEntities int  `json:"min_shared_entities,omitempty" yaml:"min_shared_entities,omitempty" mapstructure:"min_shared_entities" validate:"min=0"` // entities a memory must share with the episode, defaults to 1
+}
+
+// Location precisions: how far coordinates are truncated before they enter documents
+const (
+	LocationPrecisionExact        = "exact"
+	LocationPrecisionStreet       = "street"       // 3 decimals, ~100 m
+	LocationPrecisionNeighborhood = "neighborhood" // 2 decimals, ~1 km
+	LocationPrecisionCity         = "city"         // 1 decimal, ~10 km
+	LocationPrecisionRegion       = "region"       // 0 decimals, ~100 km
+)
+
+// locationPrecisions are the decimals coordinates keep and the finest S2 level of similar size
+var locationPrecisions = map[string]struct{ decimals, s2Level int }{
+	LocationPrecisionStreet:       {3, 16},
+	LocationPrecisionNeighborhood: {2, 13},
+	LocationPrecisionCity:         {1, 10},
+	LocationPrecisionRegion:       {0, 7},
+}
+
+// LocationPrecisionLimits returns the decimals coordinates keep at a precision and the finest S2
+// level of similar size; false for exact (or empty) precision, which keeps coordinates as they are
+func LocationPrecisionLimits(precision string) (decimals, s2Level int, ok bool) {
+	limits, ok := locationPrecisions[precision]
+	return limits.decimals, limits.s2Level, ok
+}
+
+// Transformation modes: one document per memory, or one per context and calendar day
+const (
+	TransformModeMemory      = "memory"
+	TransformModeDailyDigest = "daily_digest"
+)
+
+// Metadata profiles: all the metadata of documents, or only what identifies and dates them
+const (
+	MetadataProfileFull    = "full"
+	MetadataProfileMinimal = "minimal"
+)
+
+// MinimalMetadataKeys are the metadata keys documents of the minimal profile keep
+var MinimalMetadataKeys = []string{"memory_id", "context_id", "file_path", "created_date"}
+
+// IsMinimalMetadataKey returns true if documents of the minimal profile keep a metadata key
+func IsMinimalMetadataKey(key string) bool {
+	for _, minimal := range MinimalMetadataKeys {
+		if key == minimal {
+			return true
+		}
+	}
+	return false
 }
 
 // ConnectorStatus represents the current state of a connector
 type ConnectorStatus struct {
-	ConnectorID    string         `json:"connector_id"`
-	State          string         `json:"state"` // idle, running, paused, error
-	LastSyncTime   *time.Time     `json:"last_sync_time,omitempty"`
-	NextSyncTime   *time.Time     `json:"next_sync_time,omitempty"`
-	LastSyncReport *SyncReport    `json:"last_sync_report,omitempty"`
-	ErrorMessage   string         `json:"error_message,omitempty"`
+	ConnectorID    string       `json:"connector_id"`
+	State          string       `json:"state"` // idle, queued, running, paused, error
+	LastSyncTime   *time.Time   `json:"last_sync_time,omitempty"`
+	NextSyncTime   *time.Time   `json:"next_sync_time,omitempty"`
+	LastSyncReport *SyncReport  `json:"last_sync_report,omitempty"`
+	ErrorMessage   string       `json:"error_message,omitempty"`
+	Quota          *QuotaStatus `json:"quota,omitempty"`          // only for connectors with a quota
+	QueuePosition  int          `json:"queue_position,omitempty"` // 1-based, only while queued
+	PausedAt       *time.Time   `json:"paused_at,omitempty"`      // only while paused by an operator
 }
 
-// Validate checks if the connector configuration is valid
+// FieldError describes an invalid connector field. Field is the YAML path relative to the connector.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// Validate applies defaults and checks if the connector configuration is valid
 func (c *ConnectorConfig) Validate() error {
+	c.ApplyDefaults()
+
+	if errs := c.FieldErrors(); len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// FieldErrors returns every validation problem of the connector configuration
+func (c *ConnectorConfig) FieldErrors() []*FieldError {
+	var errs []*FieldError
+
 	if c.ID == "" {
-		return fmt.Errorf("connector ID is required")
+		errs = append(errs, &FieldError{Field: "id", Message: "is required"})
 	}
 	if c.ContextID == "" {
-		return fmt.Errorf("context_id is required")
+		errs = append(errs, &FieldError{Field: "context_id", Message: "is required"})
+	}
+
+	if c.MemoryAPI != nil && c.MemoryAPI.OAuth2.Enabled() &&
+		c.MemoryAPI.OAuth2.ClientID == "" && c.MemoryAPI.OAuth2.RefreshToken == "" {
+		errs = append(errs, &FieldError{Field: "memory_api.oauth2", Message: "requires client_id or refresh_token"})
+	}
+
+	if c.Ingestion.MaxCatchUpRange != "" {
+		if _, ok := QueryRangeDuration(c.Ingestion.MaxCatchUpRange); !ok {
+			errs = append(errs, &FieldError{
+				Field:   "ingestion.max_catch_up_range",
+				Message: fmt.Sprintf("must be day, week, or month, got '%s'", c.Ingestion.MaxCatchUpRange),
+			})
+		}
+	}
+
+	if c.Ingestion.Timezone != "" {
+		if _, err := time.LoadLocation(c.Ingestion.Timezone); err != nil {
+			errs = append(errs, &FieldError{
+				Field:   "ingestion.timezone",
+				Message: fmt.Sprintf("must be an IANA time zone name, got '%s'", c.Ingestion.Timezone),
+			})
+		}
+	}
+
+	errs = append(errs, c.Ingestion.AutoTune.fieldErrors()...)
+	errs = append(errs, c.Ingestion.Pipeline.fieldErrors()...)
+	if threshold := c.Ingestion.FailureThreshold; threshold.Percent < 0 || threshold.Percent > 99 {
+		errs = append(errs, &FieldError{Field: "ingestion.failure_threshold.percent", Message: "must be between 1 and 99, or 0 to never abort"})
+	}
+	if c.Ingestion.FailureThreshold.MinMemories < 0 {
+		errs = append(errs, &FieldError{Field: "ingestion.failure_threshold.min_memories", Message: "must not be negative"})
+	}
+
+	if dedup := c.Ingestion.Deduplication; dedup.Enabled {
+		if dedup.Threshold <= 0 || dedup.Threshold > 1 {
+			errs = append(errs, &FieldError{Field: "ingestion.deduplication.threshold", Message: "must be greater than 0 and at most 1"})
+		}
+		switch dedup.Action {
+		case DuplicateActionSkip:
+		case DuplicateActionMerge:
+			if c.Transform.Mode == TransformModeDailyDigest {
+				errs = append(errs, &FieldError{Field: "ingestion.deduplication.action", Message: "merge is not supported in daily_digest mode"})
+			}
+		default:
+			errs = append(errs, &FieldError{
+				Field:   "ingestion.deduplication.action",
+				Message: fmt.Sprintf("must be skip or merge, got '%s'", dedup.Action),
+			})
+		}
+	}
+
+	switch c.Transform.Mode {
+	case "", TransformModeMemory:
+	case TransformModeDailyDigest:
+		if c.Ingestion.QueryRange == "day" {
+			// Memories of the current day wait for it to end and must still be in the next window
+			errs = append(errs, &FieldError{Field: "ingestion.query_range", Message: "must be week or month for daily_digest mode"})
+		}
+	default:
+		errs = append(errs, &FieldError{
+			Field:   "transform.mode",
+			Message: fmt.Sprintf("must be memory or daily_digest, got '%s'", c.Transform.Mode),
+		})
+	}
+	if c.Transform.Episodes.Enabled {
+		if c.Transform.Mode == TransformModeDailyDigest {
+			errs = append(errs, &FieldError{Field: "transform.episodes", Message: "is not supported in daily_digest mode"})
+		}
+		if c.Transform.Episodes.WindowMinutes < 0 {
+			errs = append(errs, &FieldError{Field: "transform.episodes.window_minutes", Message: "must not be negative"})
+		}
+		if c.Transform.Episodes.MinSharedEntities < 0 {
+			errs = append(errs, &FieldError{Field: "transform.episodes.min_shared_entities", Message: "must not be negative"})
+		}
+	}
+	if _, _, ok := LocationPrecisionLimits(c.Transform.LocationPrecision); !ok &&
+		c.Transform.LocationPrecision != "" && c.Transform.LocationPrecision != LocationPrecisionExact {
+		errs = append(errs, &FieldError{
+			Field:   "transform.location_precision",
+			Message: fmt.Sprintf("must be exact, street, neighborhood, city, or region, got '%s'", c.Transform.LocationPrecision),
+		})
+	}
+	if c.Transform.S2Level < 0 || c.Transform.S2Level > 30 {
+		errs = append(errs, &FieldError{
+			Field:   "transform.s2_level",
+			Message: fmt.Sprintf("must be between 0 and 30, got %d", c.Transform.S2Level),
+		})
+	}
+	if c.Transform.Trips.Enabled {
+		if c.Transform.Mode == TransformModeDailyDigest {
+			errs = append(errs, &FieldError{Field: "transform.trips", Message: "is not supported in daily_digest mode"})
+		}
+		if c.Transform.Trips.MinDistanceKm < 0 {
+			errs = append(errs, &FieldError{Field: "transform.trips.min_distance_km", Message: "must not be negative"})
+		}
+		if c.Transform.Trips.MaxGapHours < 0 {
+			errs = append(errs, &FieldError{Field: "transform.trips.max_gap_hours", Message: "must not be negative"})
+		}
+		if c.Transform.Trips.MinVisits < 0 {
+			errs = append(errs, &FieldError{Field: "transform.trips.min_visits", Message: "must not be negative"})
+		}
+	}
+	if c.Transform.Places.Enabled {
+		if c.Transform.Mode == TransformModeDailyDigest {
+			errs = append(errs, &FieldError{Field: "transform.places", Message: "is not supported in daily_digest mode"})
+		}
+		if c.Transform.Places.RadiusM < 0 {
+			errs = append(errs, &FieldError{Field: "transform.places.radius_m", Message: "must not be negative"})
+		}
+		if c.Transform.Places.MinMemories < 0 {
+			errs = append(errs, &FieldError{Field: "transform.places.min_memories", Message: "must not be negative"})
+		}
+	}
+	if c.Transform.ShortCitations {
+		if !c.Transform.IncludeMetadata {
+			errs = append(errs, &FieldError{Field: "transform.short_citations", Message: "requires include_metadata, which adds the file_path"})
+		}
+		if c.Transform.Mode == TransformModeDailyDigest {
+			errs = append(errs, &FieldError{Field: "transform.short_citations", Message: "is not supported in daily_digest mode"})
+		}
+	}
+	for i, enricher := range c.Transform.Enrichers {
+		if enricher.Type == "" {
+			errs = append(errs, &FieldError{Field: fmt.Sprintf("transform.enrichers[%d].type", i), Message: "is required"})
+		}
+	}
+	if c.Transform.Title != "" && c.Transform.Mode == TransformModeDailyDigest {
+		errs = append(errs, &FieldError{Field: "transform.title", Message: "is not supported in daily_digest mode"})
+	}
+	switch c.Transform.MetadataProfile {
+	case "", MetadataProfileFull:
+	case MetadataProfileMinimal:
+		if !c.Transform.IncludeMetadata {
+			errs = append(errs, &FieldError{Field: "transform.metadata_profile", Message: "minimal requires include_metadata"})
+		}
+		if c.Transform.Mode == TransformModeDailyDigest {
+			errs = append(errs, &FieldError{Field: "transform.metadata_profile", Message: "minimal is not supported in daily_digest mode"})
+		}
+		for i, field := range c.Transform.MetadataSchema.Fields {
+			if !IsMinimalMetadataKey(field.Key) {
+				errs = append(errs, &FieldError{
+					Field:   fmt.Sprintf("transform.metadata_schema.fields[%d].key", i),
+					Message: fmt.Sprintf("%s isn't kept by the minimal metadata profile", field.Key),
+				})
+			}
+		}
+	default:
+		errs = append(errs, &FieldError{
+			Field:   "transform.metadata_profile",
+			Message: fmt.Sprintf("must be full or minimal, got '%s'", c.Transform.MetadataProfile),
+		})
+	}
+	if len(c.Transform.Enrichers) > 0 && c.Transform.Mode == TransformModeDailyDigest {
+		errs = append(errs, &FieldError{Field: "transform.enrichers", Message: "is not supported in daily_digest mode"})
+	}
+	if len(c.Transform.RawFields) > 0 {
+		if !c.Transform.IncludeMetadata {
+			errs = append(errs, &FieldError{Field: "transform.raw_fields", Message: "requires include_metadata"})
+		}
+		if c.Transform.Mode == TransformModeDailyDigest {
+			errs = append(errs, &FieldError{Field: "transform.raw_fields", Message: "is not supported in daily_digest mode"})
+		}
+	}
+	rawFields := make([]string, 0, len(c.Transform.RawFields))
+	for field := range c.Transform.RawFields {
+		rawFields = append(rawFields, field)
+	}
+	sort.Strings(rawFields) // in a stable order
+	for _, field := range rawFields {
+		switch {
+		case IsMemoryField(field):
+			errs = append(errs, &FieldError{Field: "transform.raw_fields." + field, Message: "is a field of the Memory model, not a raw field"})
+		case c.Transform.RawFields[field] == "":
+			errs = append(errs, &FieldError{Field: "transform.raw_fields." + field, Message: "must name a metadata key"})
+		}
+	}
+	errs = append(errs, c.Transform.MetadataSchema.fieldErrors()...)
+	if target := c.Transform.TargetLanguage; target != "" && !isLanguageCode(target) {
+		errs = append(errs, &FieldError{Field: "transform.target_language", Message: fmt.Sprintf("must be a lower-case ISO 639-1 code (e.g. en), got '%s'", target)})
+	}
+	if c.Heartbeat.URL != "" {
+		if parsed, err := url.Parse(c.Heartbeat.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs = append(errs, &FieldError{Field: "heartbeat.url", Message: "must be an http or https URL"})
+		}
+	}
+	if c.DeepLink != "" {
+		if !strings.Contains(c.DeepLink, "{memory_id}") {
+			errs = append(errs, &FieldError{Field: "deep_link", Message: "must contain {memory_id}"})
+		} else if parsed, err := url.Parse(strings.NewReplacer("{memory_id}", "x", "{context_id}", "x").Replace(c.DeepLink)); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs = append(errs, &FieldError{Field: "deep_link", Message: "must be an http or https URL template"})
+		}
+	}
+
+	errs = append(errs, c.Export.fieldErrors()...)
+	if c.Simulation.Enabled {
+		errs = append(errs, c.Simulation.fieldErrors()...)
+		if c.MemoryAPI != nil {
+			errs = append(errs, &FieldError{Field: "memory_api", Message: "is not used by simulated connectors"})
+		}
+	}
+
+	if c.Quota.MaxDocumentsPerDay < 0 {
+		errs = append(errs, &FieldError{Field: "quota.max_documents_per_day", Message: "must not be negative"})
+	}
+	if c.Quota.MaxTokensPerDay < 0 {
+		errs = append(errs, &FieldError{Field: "quota.max_tokens_per_day", Message: "must not be negative"})
 	}
 
 	// Validate schedule
 	switch c.Schedule.Type {
 	case "interval":
 		if c.Schedule.IntervalHours <= 0 {
-			return fmt.Errorf("interval_hours must be positive")
+			errs = append(errs, &FieldError{Field: "schedule.interval_hours", Message: "must be positive"})
 		}
 	case "cron":
 		if c.Schedule.CronExpr == "" {
-			return fmt.Errorf("cron_expr is required for cron schedule type")
+			errs = append(errs, &FieldError{Field: "schedule.cron_expr", Message: "is required for cron schedule type"})
 		}
 	case "manual":
 		// No additional validation needed
 	default:
-		return fmt.Errorf("invalid schedule type: %s (must be interval, cron, or manual)", c.Schedule.Type)
+		errs = append(errs, &FieldError{
+			Field:   "schedule.type",
+			Message: fmt.Sprintf("must be interval, cron, or manual, got '%s'", c.Schedule.Type),
+		})
 	}
+	errs = append(errs, c.Schedule.windowErrors()...)
+	errs = append(errs, c.featureErrors()...)
 
-	// Validate ingestion config
+	return errs
+}
+
+// isLanguageCode reports whether code looks like an ISO 639-1 language code
+func isLanguageCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyDefaults fills in defaults for optional ingestion settings
+func (c *ConnectorConfig) ApplyDefaults() {
 	if c.Ingestion.QueryLimit <= 0 {
 		c.Ingestion.QueryLimit = 100 // Default
 	}
 	if c.Ingestion.MaxConcurrency <= 0 {
 		c.Ingestion.MaxConcurrency = 5 // Default from user's answer: configurable
 	}
+	if c.Ingestion.MaxCatchUpRange == "" {
+		c.Ingestion.MaxCatchUpRange = "month"
+	}
+	if c.Ingestion.Pipeline.TransformWorkers <= 0 {
+		c.Ingestion.Pipeline.TransformWorkers = c.Ingestion.MaxConcurrency
+	}
+	if c.Ingestion.Pipeline.EnrichWorkers <= 0 {
+		c.Ingestion.Pipeline.EnrichWorkers = c.Ingestion.MaxConcurrency
+	}
+	if c.Ingestion.Pipeline.QueueSize <= 0 {
+		c.Ingestion.Pipeline.QueueSize = c.Ingestion.MaxConcurrency
+	}
+	if dedup := &c.Ingestion.Deduplication; dedup.Enabled {
+		if dedup.Threshold == 0 {
+			dedup.Threshold = 0.9
+		}
+		if dedup.Action == "" {
+			dedup.Action = DuplicateActionSkip
+		}
+	}
+	if episodes := &c.Transform.Episodes; episodes.Enabled {
+		if episodes.WindowMinutes == 0 {
+			episodes.WindowMinutes = 60
+		}
+		if episodes.MinSharedEntities == 0 {
+			episodes.MinSharedEntities = 1
+		}
+	}
+	if trips := &c.Transform.Trips; trips.Enabled {
+		if trips.MinDistanceKm == 0 {
+			trips.MinDistanceKm = 50
+		}
+		if trips.MaxGapHours == 0 {
+			trips.MaxGapHours = 72
+		}
+		if trips.MinVisits == 0 {
+			trips.MinVisits = 5
+		}
+	}
+	if places := &c.Transform.Places; places.Enabled {
+		if places.RadiusM == 0 {
+			places.RadiusM = 150
+		}
+		if places.MinMemories == 0 {
+			places.MinMemories = 5
+		}
+	}
+	if export := &c.Export; export.Enabled() {
+		if export.Mode == "" {
+			export.Mode = ExportModeAlso
+		}
+		if export.URL != "" && export.Timeout == 0 {
+			export.Timeout = 30
+		}
+	}
+	if c.Simulation.Enabled {
+		c.Simulation.applyDefaults()
+	}
+	if threshold := &c.Ingestion.FailureThreshold; threshold.Enabled() && threshold.MinMemories == 0 {
+		threshold.MinMemories = 20
+	}
+	if tune := &c.Ingestion.AutoTune; tune.Enabled {
+		if tune.MinQueryLimit == 0 {
+			tune.MinQueryLimit = 10
+		}
+		if tune.MaxQueryLimit == 0 {
+			tune.MaxQueryLimit = 1000
+		}
+		if tune.MinConcurrency == 0 {
+			tune.MinConcurrency = 1
+		}
+		if tune.MaxConcurrency == 0 {
+			tune.MaxConcurrency = 50
+		}
+		if tune.TargetFetchSeconds == 0 {
+			tune.TargetFetchSeconds = 10
+		}
+	}
+}
 
-	return nil
+// fieldErrors checks the stage sizes; zero means the default
+func (p *PipelineConfig) fieldErrors() []*FieldError {
+	var errs []*FieldError
+	limits := []struct {
+		field string
+		value int
+		upper int
+	}{
+		{"transform_workers", p.TransformWorkers, 50},
+		{"enrich_workers", p.EnrichWorkers, 50},
+		{"queue_size", p.QueueSize, 1000},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 || limit.value > limit.upper {
+			errs = append(errs, &FieldError{
+				Field:   "ingestion.pipeline." + limit.field,
+				Message: fmt.Sprintf("must be between 1 and %d", limit.upper),
+			})
+		}
+	}
+	return errs
+}
+
+// fieldErrors checks the auto-tuning bounds
+func (t *AutoTuneConfig) fieldErrors() []*FieldError {
+	if !t.Enabled {
+		return nil
+	}
+
+	var errs []*FieldError
+	bounds := []struct {
+		field         string
+		min, max      int
+		lowest, upper int
+	}{
+		{"query_limit", t.MinQueryLimit, t.MaxQueryLimit, 1, 1000},
+		{"concurrency", t.MinConcurrency, t.MaxConcurrency, 1, 50},
+	}
+	for _, b := range bounds {
+		if b.min < b.lowest || b.min > b.upper {
+			errs = append(errs, &FieldError{
+				Field:   "ingestion.auto_tune.min_" + b.field,
+				Message: fmt.Sprintf("must be between %d and %d", b.lowest, b.upper),
+			})
+		}
+		if b.max < b.min || b.max > b.upper {
+			errs = append(errs, &FieldError{
+				Field:   "ingestion.auto_tune.max_" + b.field,
+				Message: fmt.Sprintf("must be between min_%s and %d", b.field, b.upper),
+			})
+		}
+	}
+	if t.TargetFetchSeconds < 1 {
+		errs = append(errs, &FieldError{Field: "ingestion.auto_tune.target_fetch_seconds", Message: "must be positive"})
+	}
+
+	return errs
+}
+
+// QueryRanges are the Memory API query ranges, narrowest first
+var QueryRanges = []string{"day", "week", "month"}
+
+// QueryRangeDuration returns the time span a Memory API query range covers
+func QueryRangeDuration(queryRange string) (time.Duration, bool) {
+	switch queryRange {
+	case "day":
+		return 24 * time.Hour, true
+	case "week":
+		return 7 * 24 * time.Hour, true
+	case "month":
+		return 30 * 24 * time.Hour, true
+	default:
+		return 0, false
+	}
 }
 
 // GetScheduleDescription returns a human-readable schedule description
 func (c *ConnectorConfig) GetScheduleDescription() string {
+	description := c.scheduleTypeDescription()
+	if len(c.Schedule.Windows) == 0 || c.Schedule.Type == "manual" {
+		return description
+	}
+
+	windows := make([]string, len(c.Schedule.Windows))
+	for i, window := range c.Schedule.Windows {
+		windows[i] = window.String()
+	}
+	description += ", within " + strings.Join(windows, " or ")
+	if c.Schedule.Timezone != "" {
+		description += " " + c.Schedule.Timezone
+	}
+	return description
+}
+
+// scheduleTypeDescription describes when the schedule type fires
+func (c *ConnectorConfig) scheduleTypeDescription() string {
 	switch c.Schedule.Type {
 	case "interval":
 		return fmt.Sprintf("Every %d hour(s)", c.Schedule.IntervalHours)
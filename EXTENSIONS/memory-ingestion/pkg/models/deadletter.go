@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// DeadLetterPolicy decides when the memories in a connector's Dead Letter Queue are retried
+type DeadLetterPolicy struct {
+	Backoff     []time.Duration // delay before each retry of a memory, the last one repeating
+	MaxAttempts int             // retries of a memory before the queue gives up on it
+}
+
+// DefaultDeadLetterPolicy retries a memory after 5 minutes, an hour, then every 6 hours, 5 times in all
+var DefaultDeadLetterPolicy = DeadLetterPolicy{
+	Backoff:     []time.Duration{5 * time.Minute, time.Hour, 6 * time.Hour},
+	MaxAttempts: 5,
+}
+
+// NextRetry returns when a memory that failed at failedAt, after retries earlier retries, is
+// retried next; nil once the policy gives up on it
+func (p DeadLetterPolicy) NextRetry(retries int, failedAt time.Time) *time.Time {
+	if retries >= p.MaxAttempts || len(p.Backoff) == 0 {
+		return nil
+	}
+	delay := p.Backoff[len(p.Backoff)-1]
+	if retries < len(p.Backoff) {
+		delay = p.Backoff[retries]
+	}
+	next := failedAt.Add(delay)
+	return &next
+}
+
+// DeadLetterRetry is the outcome of retrying the due memories of a connector's Dead Letter Queue
+type DeadLetterRetry struct {
+	ConnectorID string `json:"connector_id"`
+	Due         int    `json:"due"`
+	Ingested    int    `json:"ingested"`
+	Failed      int    `json:"failed"`             // failed again, retried later unless the queue gave up on them
+	GaveUp      int    `json:"gave_up"`            // of the failed ones, reached the policy's maximum attempts
+	Dropped     int    `json:"dropped"`            // left the queue: a sync ingested them meanwhile, or the Memory API doesn't return them anymore
+	Deferred    int    `json:"deferred,omitempty"` // held back by the daily quota or LightRAG's queue, still due
+	Blocked     int    `json:"blocked,omitempty"`  // blocked by a content policy, left the queue
+}
+
+// AddFailedItem adds a failed memory to the DLQ, or records another failed attempt of a memory
+// already in it, and schedules its next retry by policy
+func (s *SyncState) AddFailedItem(item FailedItem, policy DeadLetterPolicy) {
+	kept := s.FailedItems[:0]
+	for _, queued := range s.FailedItems {
+		if queued.MemoryID != item.MemoryID {
+			kept = append(kept, queued)
+		} else if queued.RetryCount+1 > item.RetryCount {
+			item.RetryCount = queued.RetryCount + 1
+		}
+	}
+	item.NextRetryAt = policy.NextRetry(item.RetryCount, item.FailedAt)
+	item.GaveUp = item.NextRetryAt == nil
+	s.FailedItems = append(kept, item)
+	s.UpdatedAt = time.Now()
+}
+
+// RemoveFailedItem removes a memory from the DLQ. It returns false if the memory wasn't in it.
+func (s *SyncState) RemoveFailedItem(memoryID string) bool {
+	kept := s.FailedItems[:0]
+	for _, queued := range s.FailedItems {
+		if queued.MemoryID != memoryID {
+			kept = append(kept, queued)
+		}
+	}
+	removed := len(kept) < len(s.FailedItems)
+	s.FailedItems = kept
+	if removed {
+		s.UpdatedAt = time.Now()
+	}
+	return removed
+}
+
+// Pending returns true if the memory awaits a retry: it failed retryably and the queue didn't give up on it
+func (i FailedItem) Pending() bool {
+	return i.Retryable && !i.GaveUp
+}
+
+// DueFailedItems returns the memories in the DLQ whose next retry is due at now. Memories queued
+// before retries were scheduled are due right away.
+func (s *SyncState) DueFailedItems(now time.Time) []FailedItem {
+	var due []FailedItem
+	for _, item := range s.FailedItems {
+		if item.Pending() && (item.NextRetryAt == nil || !item.NextRetryAt.After(now)) {
+			due = append(due, item)
+		}
+	}
+	return due
+}
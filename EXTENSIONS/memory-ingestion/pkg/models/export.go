@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Export modes: export the documents inserted into LightRAG, or export them instead of inserting
+const (
+	ExportModeAlso = "also"
+	ExportModeOnly = "only"
+)
+
+// ExportConfig writes a connector's transformed documents (text and metadata) as JSON Lines, to load
+// the same corpus into other RAG systems. Path and URL may contain {connector_id}, {context_id}, and
+// {date} (UTC, YYYY-MM-DD).
+type ExportConfig struct {
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty" mapstructure:"mode" validate:"oneof=also only"` // also (default) or only
+
+	// Path is the file the documents are appended to, one per line
+	Path string `json:"path,omitempty" yaml:"path,omitempty" mapstructure:"path"`
+
+	// URL is the object store URL each document is PUT to, as a one-line JSONL object. It must contain
+	// {document_id}. It may contain a presigned signature, so it's never served by the API.
+	URL     string            `json:"-" yaml:"url,omitempty" mapstructure:"url"`
+	Headers map[string]string `json:"-" yaml:"headers,omitempty" mapstructure:"headers"`                                  // e.g. Authorization, sent with every PUT
+	Timeout int               `json:"timeout,omitempty" yaml:"timeout,omitempty" mapstructure:"timeout" validate:"min=0"` // seconds per PUT, defaults to 30
+}
+
+// Enabled returns true if documents are exported
+func (e ExportConfig) Enabled() bool {
+	return e.Path != "" || e.URL != ""
+}
+
+// ExportOnly returns true if documents are exported instead of inserted into LightRAG
+func (e ExportConfig) ExportOnly() bool {
+	return e.Enabled() && e.Mode == ExportModeOnly
+}
+
+// fieldErrors checks the export settings
+func (e *ExportConfig) fieldErrors() []*FieldError {
+	var errs []*FieldError
+
+	switch e.Mode {
+	case "", ExportModeAlso:
+	case ExportModeOnly:
+		if !e.Enabled() {
+			errs = append(errs, &FieldError{Field: "export.mode", Message: "only requires path or url"})
+		}
+	default:
+		errs = append(errs, &FieldError{
+			Field:   "export.mode",
+			Message: fmt.Sprintf("must be also or only, got '%s'", e.Mode),
+		})
+	}
+	if e.Path != "" && e.URL != "" {
+		errs = append(errs, &FieldError{Field: "export", Message: "must set path or url, not both"})
+	}
+	if e.URL != "" {
+		template := strings.NewReplacer("{document_id}", "x", "{connector_id}", "x", "{context_id}", "x", "{date}", "x").Replace(e.URL)
+		if !strings.Contains(e.URL, "{document_id}") {
+			errs = append(errs, &FieldError{Field: "export.url", Message: "must contain {document_id}"})
+		} else if parsed, err := url.Parse(template); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs = append(errs, &FieldError{Field: "export.url", Message: "must be an http or https URL template"})
+		}
+	}
+	if e.Timeout < 0 {
+		errs = append(errs, &FieldError{Field: "export.timeout", Message: "must not be negative"})
+	}
+
+	return errs
+}
+
+// ImportResult is the outcome of an imported JSON Lines export
+type ImportResult struct {
+	Documents  int      `json:"documents"`  // read from the export
+	Imported   int      `json:"imported"`   // inserted into LightRAG
+	Duplicates int      `json:"duplicates"` // skipped, their memories were already processed
+	Connectors []string `json:"connectors"` // whose sync state records the imported memories
+}
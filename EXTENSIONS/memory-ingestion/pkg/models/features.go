@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Feature flags of connectors, gating experimental behaviors
+const (
+	FeatureAdaptiveConcurrency  = "adaptive_concurrency"  // ingestion.adaptive_concurrency adjusts parallel inserts
+	FeatureAutoTune             = "auto_tune"             // ingestion.auto_tune adapts query limits and parallel inserts
+	FeatureExperimentalStrategy = "experimental_strategy" // transform.experimental_strategy replaces transform.strategy
+)
+
+// FeatureFlag describes a feature flag of connectors
+type FeatureFlag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"` // of connectors that don't set the flag
+}
+
+// FeatureFlags are the feature flags of connectors, sorted by name. Flags of behaviors a connector
+// already configures default to on, so they act as kill switches; new behaviors default to off.
+var FeatureFlags = []FeatureFlag{
+	{
+		Name:        FeatureAdaptiveConcurrency,
+		Description: "adjust the number of parallel inserts to LightRAG's latency, if ingestion.adaptive_concurrency is set",
+		Default:     true,
+	},
+	{
+		Name:        FeatureAutoTune,
+		Description: "adapt query limits and parallel inserts to response times, if ingestion.auto_tune is enabled",
+		Default:     true,
+	},
+	{
+		Name:        FeatureExperimentalStrategy,
+		Description: "transform memories with transform.experimental_strategy instead of transform.strategy",
+		Default:     false,
+	},
+}
+
+// LookupFeatureFlag returns the feature flag of a name
+func LookupFeatureFlag(name string) (FeatureFlag, bool) {
+	for _, flag := range FeatureFlags {
+		if flag.Name == name {
+			return flag, true
+		}
+	}
+	return FeatureFlag{}, false
+}
+
+// FeatureEnabled returns true if a feature flag is on for the connector: its runtime override if
+// overrides has one (flag -> enabled), the connector's features setting otherwise, and the flag's
+// default if neither sets it
+func (c *ConnectorConfig) FeatureEnabled(name string, overrides map[string]bool) bool {
+	if enabled, ok := overrides[name]; ok {
+		return enabled
+	}
+	if enabled, ok := c.Features[name]; ok {
+		return enabled
+	}
+	flag, _ := LookupFeatureFlag(name)
+	return flag.Default
+}
+
+// EnabledFeatures returns the feature flags on for the connector, sorted
+func (c *ConnectorConfig) EnabledFeatures(overrides map[string]bool) []string {
+	var enabled []string
+	for _, flag := range FeatureFlags {
+		if c.FeatureEnabled(flag.Name, overrides) {
+			enabled = append(enabled, flag.Name)
+		}
+	}
+	return enabled
+}
+
+// WithFeatures returns a copy of the connector with the behaviors its feature flags turn off
+// removed from its settings, and those they turn on applied. overrides are the runtime overrides
+// of its flags, flag -> enabled.
+func (c *ConnectorConfig) WithFeatures(overrides map[string]bool) *ConnectorConfig {
+	gated := *c
+	if !c.FeatureEnabled(FeatureAdaptiveConcurrency, overrides) {
+		gated.Ingestion.AdaptiveConcurrency = false
+	}
+	if !c.FeatureEnabled(FeatureAutoTune, overrides) {
+		gated.Ingestion.AutoTune.Enabled = false
+	}
+	if c.FeatureEnabled(FeatureExperimentalStrategy, overrides) && c.Transform.ExperimentalStrategy != "" {
+		gated.Transform.Strategy = c.Transform.ExperimentalStrategy
+	}
+	return &gated
+}
+
+// CheckFeature returns an error if a feature flag doesn't exist, or can't be turned on for the
+// connector
+func (c *ConnectorConfig) CheckFeature(name string, enabled bool) error {
+	if _, ok := LookupFeatureFlag(name); !ok {
+		return fmt.Errorf("unknown feature flag: %s", name)
+	}
+	if name == FeatureExperimentalStrategy && enabled && c.Transform.ExperimentalStrategy == "" {
+		return fmt.Errorf("%s requires transform.experimental_strategy", name)
+	}
+	return nil
+}
+
+// featureErrors validates the connector's feature flags
+func (c *ConnectorConfig) featureErrors() []*FieldError {
+	names := make([]string, 0, len(c.Features))
+	for name := range c.Features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []*FieldError
+	for _, name := range names {
+		if err := c.CheckFeature(name, c.Features[name]); err != nil {
+			errs = append(errs, &FieldError{Field: "features." + name, Message: err.Error()})
+		}
+	}
+
+	if c.Transform.ExperimentalStrategy != "" && c.Transform.Mode == TransformModeDailyDigest {
+		errs = append(errs, &FieldError{Field: "transform.experimental_strategy", Message: "is not supported in daily_digest mode"})
+	}
+
+	return errs
+}
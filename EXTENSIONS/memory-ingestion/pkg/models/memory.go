@@ -1,22 +1,146 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
 	"time"
 )
 
 // Memory represents a memory item from the Memory API
 type Memory struct {
-	ID          string    `json:"id" yaml:"id"`
-	Type        string    `json:"type" yaml:"type"`
-	Audio       bool      `json:"audio" yaml:"audio"`
-	Image       bool      `json:"image" yaml:"image"`
-	GcsUri      string    `json:"gcs_uri,omitempty" yaml:"gcs_uri,omitempty"`
-	GcsUriImg   string    `json:"gcs_uri_img,omitempty" yaml:"gcs_uri_img,omitempty"`
-	Transcript  string    `json:"transcript" yaml:"transcript"`
-	LocationLat *float64  `json:"location_lat,omitempty" yaml:"location_lat,omitempty"`
-	LocationLon *float64  `json:"location_lon,omitempty" yaml:"location_lon,omitempty"`
-	CreatedAt   string    `json:"created_at" yaml:"created_at"`
-	UpdatedAt   *string   `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
+	ID          string   `json:"id" yaml:"id"`
+	Type        string   `json:"type" yaml:"type"`
+	Audio       bool     `json:"audio" yaml:"audio"`
+	Image       bool     `json:"image" yaml:"image"`
+	GcsUri      string   `json:"gcs_uri,omitempty" yaml:"gcs_uri,omitempty"`
+	GcsUriImg   string   `json:"gcs_uri_img,omitempty" yaml:"gcs_uri_img,omitempty"`
+	Transcript  string   `json:"transcript" yaml:"transcript"`
+	LocationLat *float64 `json:"location_lat,omitempty" yaml:"location_lat,omitempty"`
+	LocationLon *float64 `json:"location_lon,omitempty" yaml:"location_lon,omitempty"`
+	CreatedAt   string   `json:"created_at" yaml:"created_at"`
+	UpdatedAt   *string  `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
+
+	// Movement at the location, if the source records it
+	LocationAltitude *float64 `json:"location_altitude,omitempty" yaml:"location_altitude,omitempty"` // meters above sea level
+	LocationSpeed    *float64 `json:"location_speed,omitempty" yaml:"location_speed,omitempty"`       // meters per second, negative if unknown
+	LocationHeading  *float64 `json:"location_heading,omitempty" yaml:"location_heading,omitempty"`   // degrees clockwise from true north, negative if unknown
+
+	// Segments time the sentences of the transcript, if the Memory API provides them
+	Segments []TranscriptSegment `json:"segments,omitempty" yaml:"segments,omitempty"`
+
+	// TimeZone of CreatedAt values without an offset, the connector's ingestion.timezone (UTC if nil)
+	TimeZone *time.Location `json:"-" yaml:"-"`
+
+	// LocalTimeZone is the time zone at the memory's location, resolved for connectors with
+	// transform.local_time (nil if it isn't known)
+	LocalTimeZone *time.Location `json:"-" yaml:"-"`
+
+	// RawFields are the JSON fields of the memory the model doesn't know (nil if there are none),
+	// so fields the Memory API adds can be used through transform.raw_fields before the model
+	// catches up. They're encoded back with the memory.
+	RawFields map[string]any `json:"-" yaml:"-"`
+}
+
+// memoryJSON has the fields of Memory without its methods, so they're decoded and encoded the
+// default way
+type memoryJSON Memory
+
+// memoryFields are the JSON names of the fields of Memory
+var memoryFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(Memory{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// IsMemoryField returns true if name is the JSON name of a field of Memory, so it never is a raw
+// field
+func IsMemoryField(name string) bool {
+	return memoryFields[name]
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping the fields the model doesn't know in RawFields
+func (m *Memory) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*memoryJSON)(m)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	m.RawFields = nil
+	for name, value := range fields {
+		if memoryFields[name] {
+			continue
+		}
+		var decoded any
+		if err := json.Unmarshal(value, &decoded); err != nil {
+			return err
+		}
+		if m.RawFields == nil {
+			m.RawFields = make(map[string]any)
+		}
+		m.RawFields[name] = decoded
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding RawFields next to the fields of the model
+func (m Memory) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(memoryJSON(m))
+	if err != nil || len(m.RawFields) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range m.RawFields {
+		if memoryFields[name] {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = encoded
+	}
+	return json.Marshal(fields)
+}
+
+// RawField returns a raw field as a metadata value: strings as they are, other values as JSON.
+// False if the memory doesn't have the field or it's null.
+func (m *Memory) RawField(name string) (string, bool) {
+	value, ok := m.RawFields[name]
+	if !ok || value == nil {
+		return "", false
+	}
+	if s, ok := value.(string); ok {
+		return s, true
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(encoded), true
+}
+
+// TranscriptSegment is a sentence of a transcript with its position in the recording
+type TranscriptSegment struct {
+	Start float64 `json:"start" yaml:"start"` // seconds from the start of the recording
+	End   float64 `json:"end" yaml:"end"`
+	Text  string  `json:"text" yaml:"text"`
 }
 
 // MemoryList represents a list of memories from the API
@@ -25,21 +149,35 @@ type MemoryList struct {
 	Count    int      `json:"count" yaml:"count"`
 }
 
-// ParseCreatedAt parses the CreatedAt timestamp into a time.Time object
+// ParseCreatedAt parses the CreatedAt timestamp into a time.Time object, reading timestamps
+// without an offset in TimeZone (see ParseTimestamp for the formats)
 func (m *Memory) ParseCreatedAt() (time.Time, error) {
-	// Try RFC3339 format first
-	t, err := time.Parse(time.RFC3339, m.CreatedAt)
-	if err == nil {
-		return t, nil
-	}
+	return ParseTimestamp(m.CreatedAt, m.TimeZone)
+}
 
-	// Try ISO8601 without timezone
-	t, err = time.Parse("2006-01-02T15:04:05", m.CreatedAt)
-	if err == nil {
-		return t, nil
+// LocalCreatedAt returns the CreatedAt timestamp in the time zone at the memory's location, false
+// if the time zone isn't known or the timestamp can't be parsed
+func (m *Memory) LocalCreatedAt() (time.Time, bool) {
+	if m.LocalTimeZone == nil {
+		return time.Time{}, false
 	}
+	createdAt, err := m.ParseCreatedAt()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt.In(m.LocalTimeZone), true
+}
 
-	return time.Time{}, err
+// ContentHash returns the SHA-256 of the memory's content as served by the Memory API, hex
+// encoded. UpdatedAt is left out, so a memory touched without changes keeps its hash, and so are
+// RawFields, so memories don't change when the Memory API adds a field.
+func (m *Memory) ContentHash() string {
+	content := *m
+	content.UpdatedAt = nil
+	content.RawFields = nil
+	data, _ := json.Marshal(content) // a Memory always encodes
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
 
 // HasLocation returns true if the memory has location data
@@ -47,11 +185,32 @@ func (m *Memory) HasLocation() bool {
 	return m.LocationLat != nil && m.LocationLon != nil
 }
 
+// Speed returns the speed in meters per second, false if it's unknown
+func (m *Memory) Speed() (float64, bool) {
+	if m.LocationSpeed == nil || *m.LocationSpeed < 0 {
+		return 0, false
+	}
+	return *m.LocationSpeed, true
+}
+
+// Heading returns the heading in degrees clockwise from true north, false if it's unknown
+func (m *Memory) Heading() (float64, bool) {
+	if m.LocationHeading == nil || *m.LocationHeading < 0 {
+		return 0, false
+	}
+	return math.Mod(*m.LocationHeading, 360), true
+}
+
 // HasAudio returns true if the memory has audio data
 func (m *Memory) HasAudio() bool {
 	return m.Audio
 }
 
+// HasSegments returns true if the memory has timed transcript segments
+func (m *Memory) HasSegments() bool {
+	return len(m.Segments) > 0
+}
+
 // HasImage returns true if the memory has image data
 func (m *Memory) HasImage() bool {
 	return m.Image
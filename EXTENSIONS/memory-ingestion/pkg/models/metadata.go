@@ -0,0 +1,178 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidMetadata is wrapped by the errors of documents whose metadata doesn't match their
+// connector's metadata schema
+var ErrInvalidMetadata = errors.New("metadata doesn't match the schema")
+
+// Metadata field types, and the form their values take
+const (
+	MetadataTypeString   = "string"   // any text
+	MetadataTypeInteger  = "integer"  // decimal, e.g. 42
+	MetadataTypeNumber   = "number"   // decimal, e.g. 0.42
+	MetadataTypeBoolean  = "boolean"  // true or false
+	MetadataTypeDatetime = "datetime" // RFC 3339, e.g. 2025-01-15T18:30:00Z
+)
+
+// What to do with metadata values that don't match their field
+const (
+	MetadataCoerce = "coerce" // convert them to the field's type, drop those that can't be
+	MetadataReject = "reject" // fail the document
+)
+
+// MetadataSchemaConfig types the metadata of a connector's documents, so LightRAG's metadata
+// filters can rely on it. Keys the schema doesn't list are left as they are.
+type MetadataSchemaConfig struct {
+	OnInvalid string          `json:"on_invalid,omitempty" yaml:"on_invalid,omitempty" mapstructure:"on_invalid"` // coerce (default) or reject
+	Fields    []MetadataField `json:"fields,omitempty" yaml:"fields,omitempty" mapstructure:"fields"`
+}
+
+// MetadataField is a metadata key of a schema
+type MetadataField struct {
+	Key       string `json:"key" yaml:"key" mapstructure:"key"`
+	Type      string `json:"type,omitempty" yaml:"type,omitempty" mapstructure:"type"`                   // string (default), integer, number, boolean, or datetime
+	Required  bool   `json:"required,omitempty" yaml:"required,omitempty" mapstructure:"required"`       // fail documents without the key
+	MaxLength int    `json:"max_length,omitempty" yaml:"max_length,omitempty" mapstructure:"max_length"` // in characters, 0 for no limit; strings only
+}
+
+// Enabled returns true if the schema has fields
+func (s *MetadataSchemaConfig) Enabled() bool {
+	return len(s.Fields) > 0
+}
+
+// Apply checks the metadata of a document against the schema. Values that don't match their
+// field are converted to its type, or dropped if they can't be, unless the schema rejects them.
+// Returns an error wrapping ErrInvalidMetadata for the first field that fails, in schema order.
+func (s *MetadataSchemaConfig) Apply(metadata map[string]string) error {
+	for _, field := range s.Fields {
+		value, ok := metadata[field.Key]
+		if !ok {
+			if field.Required {
+				return fmt.Errorf("%w: %s is required", ErrInvalidMetadata, field.Key)
+			}
+			continue
+		}
+
+		normalized, err := field.normalize(value)
+		if err == nil && normalized == value {
+			continue
+		}
+		if s.OnInvalid == MetadataReject {
+			if err == nil {
+				err = field.mismatch(value, normalized)
+			}
+			return fmt.Errorf("%w: %s: %w", ErrInvalidMetadata, field.Key, err)
+		}
+		if err != nil {
+			if field.Required {
+				return fmt.Errorf("%w: %s: %w", ErrInvalidMetadata, field.Key, err)
+			}
+			delete(metadata, field.Key)
+			continue
+		}
+		metadata[field.Key] = normalized
+	}
+	return nil
+}
+
+// normalize returns a value in the form of the field's type, cut to its maximum length, or an
+// error if it isn't of the type
+func (f *MetadataField) normalize(value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	switch f.Type {
+	case MetadataTypeInteger:
+		if n, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return strconv.FormatInt(n, 10), nil
+		}
+		// Whole numbers written as decimals, e.g. 42.0
+		if n, err := strconv.ParseFloat(trimmed, 64); err == nil && n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+			return strconv.FormatInt(int64(n), 10), nil
+		}
+		return "", fmt.Errorf("'%s' is not an integer", value)
+	case MetadataTypeNumber:
+		n, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return "", fmt.Errorf("'%s' is not a number", value)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case MetadataTypeBoolean:
+		switch strings.ToLower(trimmed) {
+		case "true", "t", "1", "yes", "y", "on":
+			return "true", nil
+		case "false", "f", "0", "no", "n", "off":
+			return "false", nil
+		}
+		return "", fmt.Errorf("'%s' is not a boolean", value)
+	case MetadataTypeDatetime:
+		t, err := ParseTimestamp(trimmed, time.UTC)
+		if err != nil {
+			return "", fmt.Errorf("'%s' is not a datetime", value)
+		}
+		return t.Format(time.RFC3339Nano), nil
+	default:
+		if f.MaxLength > 0 && utf8.RuneCountInString(value) > f.MaxLength {
+			return string([]rune(value)[:f.MaxLength]), nil
+		}
+		return value, nil
+	}
+}
+
+// mismatch describes how a value of the field's type differs from its normalized form
+func (f *MetadataField) mismatch(value, normalized string) error {
+	if f.Type == "" || f.Type == MetadataTypeString {
+		return fmt.Errorf("is longer than %d characters", f.MaxLength)
+	}
+	return fmt.Errorf("'%s' must be written '%s'", value, normalized)
+}
+
+// fieldErrors validates the schema
+func (s *MetadataSchemaConfig) fieldErrors() []*FieldError {
+	var errs []*FieldError
+
+	switch s.OnInvalid {
+	case "", MetadataCoerce, MetadataReject:
+	default:
+		errs = append(errs, &FieldError{
+			Field:   "transform.metadata_schema.on_invalid",
+			Message: fmt.Sprintf("must be coerce or reject, got '%s'", s.OnInvalid),
+		})
+	}
+
+	seen := make(map[string]bool, len(s.Fields))
+	for i, field := range s.Fields {
+		path := fmt.Sprintf("transform.metadata_schema.fields[%d]", i)
+		if field.Key == "" {
+			errs = append(errs, &FieldError{Field: path + ".key", Message: "is required"})
+		} else if seen[field.Key] {
+			errs = append(errs, &FieldError{Field: path + ".key", Message: fmt.Sprintf("duplicate key: %s", field.Key)})
+		}
+		seen[field.Key] = true
+
+		switch field.Type {
+		case "", MetadataTypeString:
+		case MetadataTypeInteger, MetadataTypeNumber, MetadataTypeBoolean, MetadataTypeDatetime:
+			if field.MaxLength != 0 {
+				errs = append(errs, &FieldError{Field: path + ".max_length", Message: "only applies to strings"})
+			}
+		default:
+			errs = append(errs, &FieldError{
+				Field:   path + ".type",
+				Message: fmt.Sprintf("must be string, integer, number, boolean, or datetime, got '%s'", field.Type),
+			})
+		}
+		if field.MaxLength < 0 {
+			errs = append(errs, &FieldError{Field: path + ".max_length", Message: "must not be negative"})
+		}
+	}
+
+	return errs
+}
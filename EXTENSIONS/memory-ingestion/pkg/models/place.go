@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// PlaceConfig defines how frequent places are clustered from the locations of memories
+type PlaceConfig struct {
+	Enabled     bool    `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	RadiusM     float64 `json:"radius_m,omitempty" yaml:"radius_m,omitempty" mapstructure:"radius_m" validate:"min=0"`             // neighborhood of a location (DBSCAN eps), defaults to 150
+	MinMemories int     `json:"min_memories,omitempty" yaml:"min_memories,omitempty" mapstructure:"min_memories" validate:"min=0"` // memories within the radius that make a place (DBSCAN minPts), defaults to 5
+}
+
+// PlaceState is the location history frequent places are clustered from, and the clusters, kept across syncs
+type PlaceState struct {
+	History     map[string]PlacePoint `json:"history,omitempty"`      // memory ID -> location, recorded by syncs
+	Clusters    []PlaceCluster        `json:"clusters,omitempty"`     // frequent places, largest first
+	ClusteredAt *time.Time            `json:"clustered_at,omitempty"` // last run of the clustering job
+}
+
+// PlacePoint is the location of a memory
+type PlacePoint struct {
+	Lat float64   `json:"lat"`
+	Lon float64   `json:"lon"`
+	At  time.Time `json:"at"`
+}
+
+// PlaceCluster is a frequent place
+type PlaceCluster struct {
+	ID       string  `json:"id"`
+	Label    string  `json:"label"` // inferred: home, work, or frequent place N
+	Lat      float64 `json:"lat"`   // centroid
+	Lon      float64 `json:"lon"`
+	RadiusM  float64 `json:"radius_m"` // memories this close to the centroid are at the place
+	Memories int     `json:"memories"`
+}
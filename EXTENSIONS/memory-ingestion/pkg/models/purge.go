@@ -0,0 +1,22 @@
+package models
+
+// PurgePlan lists what purging a context removes from LightRAG and the connector's state
+type PurgePlan struct {
+	ContextID    string   `json:"context_id"`
+	ConnectorIDs []string `json:"connector_ids"` // connectors of the context, their sync state is cleared
+	Memories     int      `json:"memories"`      // memories they ingested
+	Documents    []string `json:"documents"`     // IDs of the LightRAG documents of those memories
+
+	// SharedMemories were ingested by connectors of other contexts too, their documents are kept
+	SharedMemories int `json:"shared_memories"`
+
+	// DigestConnectors ingest daily digests, whose documents can't be told apart by memory and are kept
+	DigestConnectors []string `json:"digest_connectors,omitempty"`
+}
+
+// PurgeResult is the outcome of a purged context
+type PurgeResult struct {
+	PurgePlan
+	DeletedDocuments  int      `json:"deleted_documents"`
+	ClearedConnectors []string `json:"cleared_connectors"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// charsPerToken is the rough number of characters per LLM token used to estimate token usage
+const charsPerToken = 4
+
+// QuotaConfig limits how much a connector may ingest per day (UTC). Zero means unlimited.
+type QuotaConfig struct {
+	MaxDocumentsPerDay int `json:"max_documents_per_day,omitempty" yaml:"max_documents_per_day,omitempty" mapstructure:"max_documents_per_day,omitempty"`
+	MaxTokensPerDay    int `json:"max_tokens_per_day,omitempty" yaml:"max_tokens_per_day,omitempty" mapstructure:"max_tokens_per_day,omitempty"` // LLM tokens LightRAG processes, estimated from the inserted text
+}
+
+// Enabled returns true if any limit is set
+func (q QuotaConfig) Enabled() bool {
+	return q.MaxDocumentsPerDay > 0 || q.MaxTokensPerDay > 0
+}
+
+// QuotaUsage is what a connector ingested on one day (UTC)
+type QuotaUsage struct {
+	Day       string `json:"day"` // YYYY-MM-DD
+	Documents int    `json:"documents"`
+	Tokens    int    `json:"tokens"`
+}
+
+// Exhausted returns true if the usage leaves no budget for another document
+func (u *QuotaUsage) Exhausted(quota QuotaConfig) bool {
+	return (quota.MaxDocumentsPerDay > 0 && u.Documents >= quota.MaxDocumentsPerDay) ||
+		(quota.MaxTokensPerDay > 0 && u.Tokens >= quota.MaxTokensPerDay)
+}
+
+// Allows returns true if a document of the given estimated tokens fits in the remaining budget
+func (u *QuotaUsage) Allows(quota QuotaConfig, tokens int) bool {
+	return (quota.MaxDocumentsPerDay <= 0 || u.Documents+1 <= quota.MaxDocumentsPerDay) &&
+		(quota.MaxTokensPerDay <= 0 || u.Tokens+tokens <= quota.MaxTokensPerDay)
+}
+
+// QuotaStatus reports a connector's budget for the current day
+type QuotaStatus struct {
+	QuotaConfig
+	QuotaUsage
+	Exhausted bool      `json:"exhausted"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// Usage describes the used budget and when it resets, e.g. "100/100 documents used, resumes at ..."
+func (q *QuotaStatus) Usage() string {
+	usage := fmt.Sprintf("%d/%d documents", q.Documents, q.MaxDocumentsPerDay)
+	if q.MaxTokensPerDay > 0 && (q.MaxDocumentsPerDay <= 0 || q.Tokens >= q.MaxTokensPerDay) {
+		usage = fmt.Sprintf("%d/%d estimated tokens", q.Tokens, q.MaxTokensPerDay)
+	}
+	return fmt.Sprintf("%s used, resumes at %s", usage, q.ResetsAt.Format(time.RFC3339))
+}
+
+// QuotaDay returns the quota day (UTC) of t
+func QuotaDay(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// NextQuotaDay returns the start of the quota day after t
+func NextQuotaDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+}
+
+// EstimateTokens estimates the LLM tokens of a text from its length
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// QuotaUsageOn returns the connector's usage on day, starting a new day's usage if needed
+func (s *SyncState) QuotaUsageOn(day string) *QuotaUsage {
+	if s.QuotaUsage == nil || s.QuotaUsage.Day != day {
+		s.QuotaUsage = &QuotaUsage{Day: day}
+	}
+	return s.QuotaUsage
+}
+
+// QuotaStatus returns the connector's budget for the day of now
+func (s *SyncState) QuotaStatus(quota QuotaConfig, now time.Time) *QuotaStatus {
+	status := &QuotaStatus{
+		QuotaConfig: quota,
+		QuotaUsage:  QuotaUsage{Day: QuotaDay(now)},
+		ResetsAt:    NextQuotaDay(now),
+	}
+	if s.QuotaUsage != nil && s.QuotaUsage.Day == status.Day {
+		status.QuotaUsage = *s.QuotaUsage
+	}
+	status.Exhausted = status.QuotaUsage.Exhausted(quota)
+	return status
+}
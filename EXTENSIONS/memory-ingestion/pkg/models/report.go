@@ -6,39 +6,104 @@ import (
 
 // SyncReport represents the result of a sync operation
 type SyncReport struct {
-	ConnectorID      string        `json:"connector_id"`
-	ContextID        string        `json:"context_id"`
-	StartTime        time.Time     `json:"start_time"`
-	EndTime          time.Time     `json:"end_time"`
-	Duration         time.Duration `json:"duration"`
-	Status           string        `json:"status"` // success, partial, failed
-	TotalFetched     int           `json:"total_fetched"`
-	TotalProcessed   int           `json:"total_processed"`
-	TotalSkipped     int           `json:"total_skipped"`
-	TotalFailed      int           `json:"total_failed"`
-	MemoriesIngested []string      `json:"memories_ingested,omitempty"`
-	MemoriesSkipped  []string      `json:"memories_skipped,omitempty"`
-	MemoriesFailed   []FailedItem  `json:"memories_failed,omitempty"`
-	ErrorMessage     string        `json:"error_message,omitempty"`
-	Metrics          SyncMetrics   `json:"metrics"`
+	ConnectorID         string          `json:"connector_id"`
+	ContextID           string          `json:"context_id"`
+	StartTime           time.Time       `json:"start_time"`
+	EndTime             time.Time       `json:"end_time"`
+	Duration            time.Duration   `json:"duration"`
+	Status              string          `json:"status"` // success, partial, failed, interrupted, quota_exhausted, upstream_unavailable
+	TotalFetched        int             `json:"total_fetched"`
+	TotalProcessed      int             `json:"total_processed"`
+	TotalSkipped        int             `json:"total_skipped"`
+	TotalFailed         int             `json:"total_failed"`
+	TotalDeferred       int             `json:"total_deferred,omitempty"`        // not started because the sync was interrupted, aborted, or the quota ran out, picked up by a later run
+	TotalAwaitingDigest int             `json:"total_awaiting_digest,omitempty"` // memories of the current day, ingested in its daily digest once it's over
+	TotalDuplicates     int             `json:"total_duplicates,omitempty"`      // near-duplicates of earlier memories, skipped or merged
+	TotalBlocked        int             `json:"total_blocked,omitempty"`         // violated a blocking content policy, not ingested
+	MemoriesIngested    []string        `json:"memories_ingested,omitempty"`
+	MemoriesSkipped     []string        `json:"memories_skipped,omitempty"`
+	MemoriesFailed      []FailedItem    `json:"memories_failed,omitempty"`
+	MemoriesDuplicate   []DuplicateItem `json:"memories_duplicate,omitempty"`
+	MemoriesBlocked     []BlockedItem   `json:"memories_blocked,omitempty"`
+	FailuresByCategory  map[string]int  `json:"failures_by_category,omitempty"` // failed memories per failure category
+	ErrorMessage        string          `json:"error_message,omitempty"`
+	Aborted             bool            `json:"aborted,omitempty"` // failed because more memories failed than ingestion.failure_threshold allows
+	Metrics             SyncMetrics     `json:"metrics"`
+	CatchUp             *CatchUp        `json:"catch_up,omitempty"` // set if the run widened its query window to cover downtime
+	Features            []string        `json:"features,omitempty"` // feature flags on for the run
+}
+
+// CatchUp describes the widened query window of a run after downtime
+type CatchUp struct {
+	LastSyncTime time.Time     `json:"last_sync_time"` // previous sync the gap is measured from
+	Gap          time.Duration `json:"gap"`
+	QueryRange   string        `json:"query_range"` // range queried instead of the configured one
+	QueryLimit   int           `json:"query_limit"` // limit queried instead of the configured one
+	Truncated    bool          `json:"truncated"`   // the gap exceeds max_catch_up_range, older memories may be missed
+}
+
+// SyncProgress is a snapshot of a running sync, reported once memories are fetched and after each processed memory
+type SyncProgress struct {
+	ConnectorID  string `json:"connector_id"`
+	TotalFetched int    `json:"total_fetched"`
+	TotalNew     int    `json:"total_new"` // fetched memories not ingested before
+	Processed    int    `json:"processed"`
+	Skipped      int    `json:"skipped"`
+	Failed       int    `json:"failed"`
+	MemoryID     string `json:"memory_id,omitempty"` // memory whose processing produced this update
 }
 
 // FailedItem represents a memory that failed to process
 // As per user's answer: "Process what we got and track what was lost and what went wrong, capture the errors like in a DLQ"
 type FailedItem struct {
-	MemoryID     string    `json:"memory_id"`
-	ErrorMessage string    `json:"error_message"`
-	FailedAt     time.Time `json:"failed_at"`
-	Retryable    bool      `json:"retryable"`
-	RetryCount   int       `json:"retry_count"`
+	MemoryID     string     `json:"memory_id"`
+	ErrorMessage string     `json:"error_message"`
+	Category     string     `json:"category,omitempty"` // timestamp_error, transform_error, translation_error, enrichment_error, export_error, upstream_4xx, upstream_5xx, timeout, or network_error
+	FailedAt     time.Time  `json:"failed_at"`
+	Retryable    bool       `json:"retryable"`
+	RetryCount   int        `json:"retry_count"`
+	NextRetryAt  *time.Time `json:"next_retry_at,omitempty"` // when the Dead Letter Queue retries the memory
+	GaveUp       bool       `json:"gave_up,omitempty"`       // true once the memory failed its last retry
+}
+
+// DuplicateItem represents a memory suppressed as a near-duplicate of an earlier one
+type DuplicateItem struct {
+	MemoryID    string  `json:"memory_id"`
+	DuplicateOf string  `json:"duplicate_of"`
+	Similarity  float64 `json:"similarity"` // 0 to 1
+	Action      string  `json:"action"`     // skip or merge
 }
 
+// BlockedItem represents a memory that wasn't ingested because it violates content policies
+type BlockedItem struct {
+	MemoryID string   `json:"memory_id"`
+	Policies []string `json:"policies"` // violated blocking policies
+}
+
+// Failure categories of memories that failed to process
+const (
+	FailureTimestamp   = "timestamp_error"   // the memory's created_at is in none of the supported formats
+	FailureTransform   = "transform_error"   // the memory couldn't be transformed into a document
+	FailureTranslation = "translation_error" // the translation API failed to translate the transcript
+	FailureEnrichment  = "enrichment_error"  // a required enricher failed to look up the memory's metadata
+	FailureExport      = "export_error"      // the document couldn't be written to the connector's export
+	FailureMetadata    = "metadata_error"    // the document's metadata doesn't match the connector's metadata schema
+	FailureUpstream4xx = "upstream_4xx"      // LightRAG rejected the document
+	FailureUpstream5xx = "upstream_5xx"      // LightRAG failed to ingest the document
+	FailureTimeout     = "timeout"           // LightRAG didn't answer in time
+	FailureNetwork     = "network_error"     // LightRAG couldn't be reached
+)
+
 // SyncMetrics contains performance metrics for a sync operation
 type SyncMetrics struct {
-	AvgFetchTimeMs    int64 `json:"avg_fetch_time_ms"`
-	AvgTransformTimeMs int64 `json:"avg_transform_time_ms"`
-	AvgInsertTimeMs   int64 `json:"avg_insert_time_ms"`
+	AvgFetchTimeMs      int64 `json:"avg_fetch_time_ms"`
+	AvgTransformTimeMs  int64 `json:"avg_transform_time_ms"`
+	AvgEnrichTimeMs     int64 `json:"avg_enrich_time_ms"`
+	AvgInsertTimeMs     int64 `json:"avg_insert_time_ms"`
 	TotalBytesProcessed int64 `json:"total_bytes_processed"`
+	Concurrency         int   `json:"concurrency,omitempty"`          // insert concurrency an adaptive sync ended with
+	QueryLimit          int   `json:"query_limit,omitempty"`          // query limit an auto-tuned sync fetched with
+	BackpressureWaitMs  int64 `json:"backpressure_wait_ms,omitempty"` // time inserts waited for LightRAG's pipeline queue
 }
 
 // SyncHistory represents historical sync records
@@ -46,16 +111,89 @@ type SyncHistory struct {
 	Reports []SyncReport `json:"reports"`
 }
 
+// ReportQuery selects recorded sync reports of a connector, newest first
+type ReportQuery struct {
+	Since  time.Time // reports started at or after Since, zero for no lower bound
+	Until  time.Time // reports started before Until, zero for no upper bound
+	Status string    // only reports with this status, empty for all
+	Limit  int       // maximum number of reports, 0 for all
+	Offset int       // number of matching reports to skip
+}
+
+// Matches returns true if the report passes the query's filters
+func (q ReportQuery) Matches(report *SyncReport) bool {
+	if !q.Since.IsZero() && report.StartTime.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !report.StartTime.Before(q.Until) {
+		return false
+	}
+	return q.Status == "" || report.Status == q.Status
+}
+
+// ReportPage is a page of recorded sync reports
+type ReportPage struct {
+	Reports []SyncReport `json:"reports"`
+	Total   int          `json:"total"` // matching reports across all pages
+	Limit   int          `json:"limit"`
+	Offset  int          `json:"offset"`
+}
+
 // SyncState tracks the state of a connector for idempotency
 type SyncState struct {
-	ConnectorID     string             `json:"connector_id"`
-	ContextID       string             `json:"context_id"`
-	LastSyncTime    time.Time          `json:"last_sync_time"`
-	ProcessedIDs    map[string]bool    `json:"processed_ids"` // Set of memory IDs already processed
-	LastSyncReport  *SyncReport        `json:"last_sync_report,omitempty"`
-	FailedItems     []FailedItem       `json:"failed_items,omitempty"` // Dead Letter Queue
-	TotalSyncCount  int                `json:"total_sync_count"`
-	UpdatedAt       time.Time          `json:"updated_at"`
+	ConnectorID    string                   `json:"connector_id"`
+	ContextID      string                   `json:"context_id"`
+	LastSyncTime   time.Time                `json:"last_sync_time"`
+	ProcessedIDs   map[string]bool          `json:"processed_ids"` // Set of memory IDs already processed
+	LastSyncReport *SyncReport              `json:"last_sync_report,omitempty"`
+	FailedItems    []FailedItem             `json:"failed_items,omitempty"` // Dead Letter Queue
+	TotalSyncCount int                      `json:"total_sync_count"`
+	QuotaUsage     *QuotaUsage              `json:"quota_usage,omitempty"` // ingestion on the current quota day
+	PausedAt       *time.Time               `json:"paused_at,omitempty"`   // set while an operator paused the connector
+	Trips          *TripState               `json:"trips,omitempty"`       // location history of connectors with transform.trips
+	Places         *PlaceState              `json:"places,omitempty"`      // frequent places of connectors with transform.places
+	Citations      map[string]string        `json:"citations,omitempty"`   // short citation -> memory ID, of connectors with transform.short_citations
+	Versions       map[string]MemoryVersion `json:"versions,omitempty"`    // memory ID -> version of the memory that was ingested
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+// ReingestResult is the outcome of re-ingesting a single memory
+type ReingestResult struct {
+	ConnectorID      string         `json:"connector_id"`
+	MemoryID         string         `json:"memory_id"`
+	URI              string         `json:"uri"`
+	Status           string         `json:"status"`             // reingested, or blocked by a content policy
+	DeletedDocuments []string       `json:"deleted_documents"`  // LightRAG documents of the memory that were replaced
+	Policies         []string       `json:"policies,omitempty"` // content policies that blocked the memory
+	Version          *MemoryVersion `json:"version,omitempty"`  // the re-ingested version
+}
+
+// MemoryVersion identifies the version of a memory that was ingested, to tell whether the memory
+// changed upstream since. It is the memory's ingestion receipt too.
+type MemoryVersion struct {
+	ContentHash  string    `json:"content_hash"`         // Memory.ContentHash at ingestion
+	UpdatedAt    *string   `json:"updated_at,omitempty"` // the memory's updated_at at ingestion, if the Memory API set it
+	IngestedAt   time.Time `json:"ingested_at"`
+	DocID        string    `json:"doc_id,omitempty"`        // the LightRAG document the memory was ingested in
+	TrackID      string    `json:"track_id,omitempty"`      // LightRAG's track ID of the insert, if it returned one
+	RequestHash  string    `json:"request_hash,omitempty"`  // SHA-256 of the insert's JSON body, the document's text and metadata
+	InsertStatus string    `json:"insert_status,omitempty"` // LightRAG's status of the insert, empty if the document was only exported
+}
+
+// IngestedDocument identifies the LightRAG document a memory was ingested in
+type IngestedDocument struct {
+	DocID       string
+	TrackID     string // empty if LightRAG returned none, or the document was only exported
+	RequestHash string // SHA-256 of the insert's JSON body
+	Status      string // LightRAG's status of the insert, empty if the document was only exported
+}
+
+// IngestionReceipt records what a connector sent LightRAG for a memory, to audit its ingestion
+type IngestionReceipt struct {
+	ConnectorID string `json:"connector_id"`
+	ContextID   string `json:"context_id"`
+	MemoryID    string `json:"memory_id"`
+	MemoryVersion
 }
 
 // IsProcessed checks if a memory ID has already been processed
@@ -75,10 +213,37 @@ func (s *SyncState) MarkProcessed(memoryID string) {
 	s.UpdatedAt = time.Now()
 }
 
-// AddFailedItem adds a failed item to the DLQ
-func (s *SyncState) AddFailedItem(item FailedItem) {
-	s.FailedItems = append(s.FailedItems, item)
-	s.UpdatedAt = time.Now()
+// RecordVersion records the version of a memory that was ingested at a time, and the document it
+// was ingested in
+func (s *SyncState) RecordVersion(memory *Memory, document IngestedDocument, ingestedAt time.Time) {
+	if s.Versions == nil {
+		s.Versions = make(map[string]MemoryVersion)
+	}
+	s.Versions[memory.ID] = MemoryVersion{
+		ContentHash:  memory.ContentHash(),
+		UpdatedAt:    memory.UpdatedAt,
+		IngestedAt:   ingestedAt,
+		DocID:        document.DocID,
+		TrackID:      document.TrackID,
+		RequestHash:  document.RequestHash,
+		InsertStatus: document.Status,
+	}
+	s.UpdatedAt = ingestedAt
+}
+
+// Receipt returns the receipt of a memory's latest ingestion, nil if none was recorded: the memory
+// wasn't ingested, or by a release that didn't record versions
+func (s *SyncState) Receipt(memoryID string) *IngestionReceipt {
+	version, ok := s.Versions[memoryID]
+	if !ok {
+		return nil
+	}
+	return &IngestionReceipt{
+		ConnectorID:   s.ConnectorID,
+		ContextID:     s.ContextID,
+		MemoryID:      memoryID,
+		MemoryVersion: version,
+	}
 }
 
 // GetRetryableFailedItems returns items that can be retried
@@ -110,6 +275,21 @@ func (r *SyncReport) IsPartial() bool {
 	return r.Status == "partial"
 }
 
+// IsInterrupted returns true if the sync was stopped early (e.g. by shutdown) after checkpointing its progress
+func (r *SyncReport) IsInterrupted() bool {
+	return r.Status == "interrupted"
+}
+
+// IsQuotaExhausted returns true if the sync stopped early because the connector's daily quota ran out
+func (r *SyncReport) IsQuotaExhausted() bool {
+	return r.Status == "quota_exhausted"
+}
+
+// IsUpstreamUnavailable returns true if the sync was skipped because its Memory API or LightRAG didn't answer
+func (r *SyncReport) IsUpstreamUnavailable() bool {
+	return r.Status == "upstream_unavailable"
+}
+
 // IsFailed returns true if the sync completely failed
 func (r *SyncReport) IsFailed() bool {
 	return r.Status == "failed"
@@ -0,0 +1,83 @@
+package models
+
+import "fmt"
+
+// Time distributions of simulated memories over a day
+const (
+	SimulationDistributionUniform = "uniform" // any time of day
+	SimulationDistributionDiurnal = "diurnal" // waking hours, peaking in the morning and evening
+	SimulationDistributionBursty  = "bursty"  // a few sessions of memories minutes apart
+)
+
+// SimulationConfig makes a connector ingest synthetic memories instead of fetching them from the
+// Memory API, to load-test a LightRAG deployment or try transform settings without real data.
+// Memories are generated per day of the query range from the seed, so repeated syncs see the same
+// memories and new ones arrive as time passes.
+type SimulationConfig struct {
+	Enabled        bool   `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	MemoriesPerDay int    `json:"memories_per_day,omitempty" yaml:"memories_per_day,omitempty" mapstructure:"memories_per_day" validate:"min=0,max=10000"`  // defaults to 20
+	Seed           int64  `json:"seed,omitempty" yaml:"seed,omitempty" mapstructure:"seed"`                                                                 // varies the generated memories, combined with the context ID
+	Distribution   string `json:"distribution,omitempty" yaml:"distribution,omitempty" mapstructure:"distribution" validate:"oneof=uniform diurnal bursty"` // diurnal (default), uniform, or bursty
+
+	// Locations are the places memories are recorded at, with a few meters of jitter; defaults to places in Berlin
+	Locations     []SimulatedLocation `json:"locations,omitempty" yaml:"locations,omitempty" mapstructure:"locations"`
+	LocationRatio float64             `json:"location_ratio,omitempty" yaml:"location_ratio,omitempty" mapstructure:"location_ratio" validate:"min=0,max=1"` // share of memories with coordinates, defaults to 0.8
+
+	// Entities the transcripts mention; each list defaults to built-in names
+	People        []string `json:"people,omitempty" yaml:"people,omitempty" mapstructure:"people"`
+	Organizations []string `json:"organizations,omitempty" yaml:"organizations,omitempty" mapstructure:"organizations"`
+	Topics        []string `json:"topics,omitempty" yaml:"topics,omitempty" mapstructure:"topics"`
+}
+
+// SimulatedLocation is a named place simulated memories are recorded at
+type SimulatedLocation struct {
+	Name string  `json:"name" yaml:"name" mapstructure:"name"`
+	Lat  float64 `json:"lat" yaml:"lat" mapstructure:"lat"`
+	Lon  float64 `json:"lon" yaml:"lon" mapstructure:"lon"`
+}
+
+// applyDefaults fills in the unset simulation settings
+func (s *SimulationConfig) applyDefaults() {
+	if s.MemoriesPerDay == 0 {
+		s.MemoriesPerDay = 20
+	}
+	if s.Distribution == "" {
+		s.Distribution = SimulationDistributionDiurnal
+	}
+	if s.LocationRatio == 0 {
+		s.LocationRatio = 0.8
+	}
+}
+
+// fieldErrors checks the simulation settings
+func (s *SimulationConfig) fieldErrors() []*FieldError {
+	var errs []*FieldError
+
+	if s.MemoriesPerDay < 0 || s.MemoriesPerDay > 10000 {
+		errs = append(errs, &FieldError{
+			Field:   "simulation.memories_per_day",
+			Message: fmt.Sprintf("must be between 1 and 10000, got %d", s.MemoriesPerDay),
+		})
+	}
+	switch s.Distribution {
+	case "", SimulationDistributionUniform, SimulationDistributionDiurnal, SimulationDistributionBursty:
+	default:
+		errs = append(errs, &FieldError{
+			Field:   "simulation.distribution",
+			Message: fmt.Sprintf("must be uniform, diurnal, or bursty, got '%s'", s.Distribution),
+		})
+	}
+	if s.LocationRatio < 0 || s.LocationRatio > 1 {
+		errs = append(errs, &FieldError{Field: "simulation.location_ratio", Message: "must be between 0 and 1"})
+	}
+	for i, location := range s.Locations {
+		if location.Lat < -90 || location.Lat > 90 || location.Lon < -180 || location.Lon > 180 {
+			errs = append(errs, &FieldError{
+				Field:   fmt.Sprintf("simulation.locations[%d]", i),
+				Message: "must have a latitude between -90 and 90 and a longitude between -180 and 180",
+			})
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// SnapshotManifest describes a context snapshot, the first entry of its archive
+type SnapshotManifest struct {
+	FormatVersion int       `json:"format_version"`
+	ContextID     string    `json:"context_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	ConnectorIDs  []string  `json:"connector_ids"` // connectors of the context, their sync state is included
+	Documents     int       `json:"documents"`
+
+	// Missing are memories with documents in LightRAG that the Memory API no longer serves (or not
+	// among the latest memories searched), or whose current version a content policy blocks. Neither
+	// their documents nor their sync state is included, so a restored connector ingests them again.
+	Missing []string `json:"missing"`
+
+	// DigestConnectors ingest daily digests, which can't be rebuilt by memory and aren't included
+	DigestConnectors []string `json:"digest_connectors,omitempty"`
+}
+
+// SnapshotDocument is a LightRAG document of a context snapshot
+type SnapshotDocument struct {
+	ConnectorID string            `json:"connector_id"`
+	MemoryID    string            `json:"memory_id"`
+	DocumentID  string            `json:"document_id"` // in the LightRAG instance it was taken from
+	Status      string            `json:"status"`
+	CreatedAt   string            `json:"created_at"`
+	Text        string            `json:"text"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
+// RestoreResult is the outcome of a restored context snapshot
+type RestoreResult struct {
+	ContextID         string   `json:"context_id"`
+	RestoredDocuments int      `json:"restored_documents"`
+	RestoredStates    []string `json:"restored_states"` // connectors whose sync state was restored
+}
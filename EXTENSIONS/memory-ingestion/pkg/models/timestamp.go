@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTimestamp is returned for timestamps in none of the formats ParseTimestamp reads
+var ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+// epochMillisThreshold separates epoch seconds from milliseconds: as seconds it lies past the
+// year 30000, as milliseconds in 2001
+const epochMillisThreshold = 1e12
+
+// offsetLayouts are timestamps that carry their UTC offset
+var offsetLayouts = []string{
+	time.RFC3339, // fractional seconds are accepted by every layout
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05Z0700",
+	"2006-01-02 15:04:05 -0700",
+	time.RFC1123Z,
+}
+
+// localLayouts are timestamps of local time, without an offset
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseTimestamp parses a timestamp in RFC 3339 or another format with a UTC offset, as epoch
+// seconds or milliseconds, or as a local date and time (e.g. 2006-01-02 15:04:05) of location.
+// Epoch timestamps are returned in location. A nil location is UTC.
+func ParseTimestamp(value string, location *time.Location) (time.Time, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("%w: empty", ErrInvalidTimestamp)
+	}
+
+	if strings.Trim(value, "0123456789.") == "" {
+		epoch, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: '%s' is not an epoch timestamp", ErrInvalidTimestamp, value)
+		}
+		if epoch >= epochMillisThreshold {
+			return time.UnixMilli(int64(epoch)).In(location), nil
+		}
+		seconds := int64(epoch)
+		return time.Unix(seconds, int64((epoch-float64(seconds))*1e9)).Round(time.Millisecond).In(location), nil
+	}
+
+	for _, layout := range offsetLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: '%s' is neither RFC 3339, epoch seconds or milliseconds, nor a local date and time",
+		ErrInvalidTimestamp, value)
+}
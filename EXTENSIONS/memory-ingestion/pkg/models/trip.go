@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// TripConfig defines how trips (memories recorded far from the user's frequent locations) are detected
+type TripConfig struct {
+	Enabled       bool    `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	MinDistanceKm float64 `json:"min_distance_km,omitempty" yaml:"min_distance_km,omitempty" mapstructure:"min_distance_km" validate:"min=0"` // distance from every frequent location, defaults to 50
+	MaxGapHours   int     `json:"max_gap_hours,omitempty" yaml:"max_gap_hours,omitempty" mapstructure:"max_gap_hours" validate:"min=0"`       // maximum gap between consecutive memories of a trip, defaults to 72
+	MinVisits     int     `json:"min_visits,omitempty" yaml:"min_visits,omitempty" mapstructure:"min_visits" validate:"min=0"`                // memories that make a place frequent, defaults to 5
+}
+
+// TripState is the location history trips are detected against, kept across syncs
+type TripState struct {
+	Visits  map[string]int `json:"visits,omitempty"`  // located memories per ~10 km grid cell ("lat,lon" of its corner)
+	Current *CurrentTrip   `json:"current,omitempty"` // trip of the latest located memory, nil if it was at a frequent location
+}
+
+// CurrentTrip is the trip the latest located memory belongs to, continued by the next sync
+type CurrentTrip struct {
+	ID     string    `json:"id"`
+	Place  string    `json:"place"`
+	LastAt time.Time `json:"last_at"` // creation time of its latest memory
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExecutionWindow is a time of day range (and optionally days of the week) in which scheduled syncs
+// may start. End before Start spans midnight; the days then refer to the day the window opens.
+type ExecutionWindow struct {
+	Start string   `json:"start" yaml:"start" mapstructure:"start"`                            // HH:MM
+	End   string   `json:"end" yaml:"end" mapstructure:"end"`                                  // HH:MM, exclusive
+	Days  []string `json:"days,omitempty" yaml:"days,omitempty" mapstructure:"days,omitempty"` // mon, tue, ... (empty means every day)
+}
+
+// weekdays maps day names to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Contains returns true if t (in the window's time zone) falls inside the window
+func (w ExecutionWindow) Contains(t time.Time) bool {
+	start, err := parseTimeOfDay(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseTimeOfDay(w.End)
+	if err != nil {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	switch {
+	case start < end:
+		return minute >= start && minute < end && w.onDay(t.Weekday())
+	case minute >= start:
+		return w.onDay(t.Weekday())
+	case minute < end:
+		// After midnight in a window opened the day before
+		return w.onDay((t.Weekday() + 6) % 7)
+	default:
+		return false
+	}
+}
+
+// onDay returns true if the window opens on day
+func (w ExecutionWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, name := range w.Days {
+		if weekday, ok := weekdays[strings.ToLower(name)]; ok && weekday == day {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the window, e.g. "01:00-05:00 mon,tue"
+func (w ExecutionWindow) String() string {
+	if len(w.Days) == 0 {
+		return fmt.Sprintf("%s-%s", w.Start, w.End)
+	}
+	return fmt.Sprintf("%s-%s %s", w.Start, w.End, strings.Join(w.Days, ","))
+}
+
+// fieldErrors checks the window, reporting fields under path
+func (w ExecutionWindow) fieldErrors(path string) []*FieldError {
+	var errs []*FieldError
+
+	start, startErr := parseTimeOfDay(w.Start)
+	if startErr != nil {
+		errs = append(errs, &FieldError{Field: path + ".start", Message: startErr.Error()})
+	}
+	end, endErr := parseTimeOfDay(w.End)
+	if endErr != nil {
+		errs = append(errs, &FieldError{Field: path + ".end", Message: endErr.Error()})
+	}
+	if startErr == nil && endErr == nil && start == end {
+		errs = append(errs, &FieldError{Field: path + ".end", Message: "must differ from start"})
+	}
+
+	for i, name := range w.Days {
+		if _, ok := weekdays[strings.ToLower(name)]; !ok {
+			errs = append(errs, &FieldError{
+				Field:   fmt.Sprintf("%s.days[%d]", path, i),
+				Message: fmt.Sprintf("must be a day of the week (mon, tue, ...), got '%s'", name),
+			})
+		}
+	}
+
+	return errs
+}
+
+// parseTimeOfDay parses HH:MM into minutes since midnight
+func parseTimeOfDay(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("must be a time of day as HH:MM, got '%s'", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// Location returns the time zone of the schedule's execution windows (local time if unset or invalid)
+func (s *ScheduleConfig) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.Local
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
+// InWindow returns true if scheduled syncs may start at t: always without execution windows,
+// otherwise if t falls inside any of them
+func (s *ScheduleConfig) InWindow(t time.Time) bool {
+	if len(s.Windows) == 0 {
+		return true
+	}
+
+	t = t.In(s.Location())
+	for _, window := range s.Windows {
+		if window.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// windowErrors checks the schedule's execution windows and time zone
+func (s *ScheduleConfig) windowErrors() []*FieldError {
+	var errs []*FieldError
+
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			errs = append(errs, &FieldError{
+				Field:   "schedule.timezone",
+				Message: fmt.Sprintf("must be an IANA time zone name, got '%s'", s.Timezone),
+			})
+		}
+	}
+	for i, window := range s.Windows {
+		errs = append(errs, window.fieldErrors(fmt.Sprintf("schedule.windows[%d]", i))...)
+	}
+
+	return errs
+}
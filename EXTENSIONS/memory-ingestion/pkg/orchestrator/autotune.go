@@ -0,0 +1,80 @@
+package orchestrator
+
+import (
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// Tuning of the auto-tuned query limit
+const (
+	queryLimitGrowth  = 1.5  // after a full batch fetched within the target time, more memories are likely waiting
+	queryLimitBackoff = 0.75 // after a fetch slower than the target time
+	queryLimitFailure = 0.5  // after a fetch failed with 429/5xx or a timeout
+)
+
+// queryLimitFor returns the query limit of a connector's next fetch: the configured one, or with
+// auto-tuning the one its previous fetch arrived at, starting at query_limit
+func (o *Orchestrator) queryLimitFor(config *models.ConnectorConfig) int {
+	tune := config.Ingestion.AutoTune
+	if !tune.Enabled {
+		return config.Ingestion.QueryLimit
+	}
+
+	o.tuneMu.Lock()
+	defer o.tuneMu.Unlock()
+
+	if limit, ok := o.queryLimits[config.ID]; ok {
+		return limit
+	}
+	return clampInt(config.Ingestion.QueryLimit, tune.MinQueryLimit, tune.MaxQueryLimit)
+}
+
+// tuneQueryLimit adapts a connector's query limit to the outcome of a fetch with limit: overload
+// errors and fetches slower than the target shrink it, full batches fetched in time grow it
+func (o *Orchestrator) tuneQueryLimit(
+	config *models.ConnectorConfig,
+	limit int,
+	memoryList *models.MemoryList,
+	duration time.Duration,
+	err error,
+) {
+	tune := config.Ingestion.AutoTune
+	next := float64(limit)
+	switch {
+	case err != nil && isOverload(err):
+		next *= queryLimitFailure
+	case err != nil:
+		// Not a sign of load (e.g. a rejected API key), keep the limit
+	case duration > time.Duration(tune.TargetFetchSeconds)*time.Second:
+		next *= queryLimitBackoff
+	case len(memoryList.Memories) >= limit:
+		next *= queryLimitGrowth
+	}
+	tuned := clampInt(int(next), tune.MinQueryLimit, tune.MaxQueryLimit)
+
+	o.tuneMu.Lock()
+	o.queryLimits[config.ID] = tuned
+	o.tuneMu.Unlock()
+
+	if tuned != limit {
+		o.logger.Info("Tuned query limit",
+			zap.String("connector_id", config.ID),
+			zap.Int("previous", limit),
+			zap.Int("query_limit", tuned),
+			zap.Duration("fetch_time", duration),
+		)
+	}
+}
+
+// clampInt limits value to [min, max]
+func clampInt(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,201 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"go.uber.org/zap"
+)
+
+// errPipelineBusy is returned for memories deferred because LightRAG's pipeline stayed full too long
+var errPipelineBusy = errors.New("LightRAG pipeline busy")
+
+// BackpressureConfig holds the limits on LightRAG's processing queue
+type BackpressureConfig struct {
+	MaxPending   int           // queued documents at which inserts pause
+	PollInterval time.Duration // how often the queue is checked
+	MaxWait      time.Duration // longest a sync waits for the queue before deferring its remaining memories
+}
+
+// pipelineGate holds inserts back while LightRAG's processing queue is full. It's shared by all
+// syncs, since they feed the same LightRAG instance.
+type pipelineGate struct {
+	config BackpressureConfig
+	client client.LightRAGAPI
+	logger *zap.Logger
+
+	mu        sync.Mutex
+	pending   int // queue length at the last check plus documents admitted since
+	checkedAt time.Time
+	checkErr  error
+	busy      bool
+}
+
+// SetBackpressure pauses inserts while LightRAG's processing queue holds config.MaxPending or more
+// documents. Must be called before syncs start.
+func (o *Orchestrator) SetBackpressure(config BackpressureConfig) {
+	o.pipeline = &pipelineGate{
+		config: config,
+		client: o.lightragClient,
+		logger: o.logger,
+	}
+}
+
+// admit reserves room for one document in LightRAG's queue, refreshing the queue length once per
+// poll interval. It returns false if the queue is full. If the queue can't be checked (e.g. an older
+// LightRAG without /documents/status_counts) documents are admitted.
+func (g *pipelineGate) admit(ctx context.Context) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if time.Since(g.checkedAt) >= g.config.PollInterval {
+		counts, err := g.client.GetDocumentStatusCounts(ctx)
+		if ctx.Err() != nil {
+			return false
+		}
+		g.checkedAt = time.Now()
+
+		if err != nil {
+			if g.checkErr == nil {
+				g.logger.Warn("Failed to check LightRAG pipeline, inserting without backpressure", zap.Error(err))
+			}
+			g.pending = 0
+		} else {
+			g.pending = 0
+			for _, status := range client.QueuedDocumentStatuses {
+				g.pending += counts[status]
+			}
+		}
+		g.checkErr = err
+	}
+
+	if g.pending >= g.config.MaxPending {
+		if !g.busy {
+			g.logger.Info("LightRAG pipeline busy, pausing inserts",
+				zap.Int("pending", g.pending),
+				zap.Int("max_pending", g.config.MaxPending),
+			)
+		}
+		g.busy = true
+		return false
+	}
+
+	if g.busy {
+		g.logger.Info("LightRAG pipeline has room again, resuming inserts", zap.Int("pending", g.pending))
+	}
+	g.busy = false
+	g.pending++
+	return true
+}
+
+// syncBackpressure tracks how long one sync waited at the pipeline gate. A nil value never waits.
+type syncBackpressure struct {
+	gate *pipelineGate
+
+	mu           sync.Mutex
+	blockedSince time.Time // zero while the sync isn't waiting
+	waited       time.Duration
+	gaveUp       bool
+	deferred     int
+}
+
+// newSync starts tracking a sync, nil if backpressure is disabled
+func (g *pipelineGate) newSync() *syncBackpressure {
+	if g == nil {
+		return nil
+	}
+	return &syncBackpressure{gate: g}
+}
+
+// wait blocks until LightRAG's queue has room for another document. Once the sync has waited MaxWait
+// without the queue draining, it and all further calls return errPipelineBusy.
+func (b *syncBackpressure) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	for {
+		if b.stopped() {
+			return errPipelineBusy
+		}
+		if b.gate.admit(ctx) {
+			b.unblock()
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !b.block() {
+			return errPipelineBusy
+		}
+
+		timer := time.NewTimer(b.gate.config.PollInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// stopped returns true after the sync gave up waiting, counting the memory as deferred
+func (b *syncBackpressure) stopped() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.gaveUp {
+		b.deferred++
+	}
+	return b.gaveUp
+}
+
+// block marks the sync as waiting. It returns false (and gives up) once the sync waited MaxWait.
+func (b *syncBackpressure) block() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.blockedSince.IsZero() {
+		b.blockedSince = now
+	}
+	if now.Sub(b.blockedSince) < b.gate.config.MaxWait {
+		return true
+	}
+
+	b.waited += now.Sub(b.blockedSince)
+	b.blockedSince = time.Time{}
+	b.gaveUp = true
+	b.deferred++
+	return false
+}
+
+// unblock ends a wait of the sync
+func (b *syncBackpressure) unblock() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.blockedSince.IsZero() {
+		b.waited += time.Since(b.blockedSince)
+		b.blockedSince = time.Time{}
+	}
+}
+
+// result returns how long the sync waited for the queue and how many memories it deferred
+func (b *syncBackpressure) result() (time.Duration, int) {
+	if b == nil {
+		return 0, 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	waited := b.waited
+	if !b.blockedSince.IsZero() {
+		waited += time.Since(b.blockedSince)
+	}
+	return waited, b.deferred
+}
@@ -0,0 +1,200 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/export"
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// benchmarkPollInterval is how often a benchmark checks whether LightRAG's pipeline has drained
+const benchmarkPollInterval = time.Second
+
+// failureBlocked is the benchmark error category of memories a content policy blocks
+const failureBlocked = "blocked"
+
+// Benchmark pushes memories through a connector's pipeline (its content, language, and enrichment
+// stages, transformation, and LightRAG insert) with up to concurrency documents in flight, and
+// measures throughput, latencies, and errors. Sync state, quotas, backpressure, and exports aren't
+// involved. If waitProcessed is set, it also waits for LightRAG's queue to drain and measures the
+// processing rate; documents others queued meanwhile slow it down. The IDs of the inserted documents
+// are returned even if the benchmark fails, so they can be deleted.
+func (o *Orchestrator) Benchmark(
+	ctx context.Context,
+	config *models.ConnectorConfig,
+	memories []models.Memory,
+	concurrency int,
+	waitProcessed bool,
+) (*models.BenchmarkRun, []string, error) {
+	config = config.WithFeatures(o.featureOverridesFor(config.ID))
+	trans, err := o.transformerFor(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	transformConfig, err := o.transformConfigFor(config, memories, &models.SyncState{ConnectorID: config.ID})
+	if err != nil {
+		return nil, nil, err
+	}
+	stages, err := o.memoryStagesFor(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	concurrency = max(concurrency, 1)
+
+	type outcome struct {
+		done    bool // false for memories left over when ctx was canceled
+		latency time.Duration
+		docID   string
+		err     error
+	}
+	outcomes := make([]outcome, len(memories))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	stages.prefetch(ctx, memories)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				begin := time.Now()
+				text, metadata, err := o.renderMemory(ctx, trans, &memories[i], transformConfig, stages, nil)
+				if err == nil {
+					_, err = o.lightragClient.InsertDocument(ctx, text, metadata)
+				}
+				outcomes[i] = outcome{done: true, latency: time.Since(begin), docID: export.DocumentID(text), err: err}
+			}
+		}()
+	}
+feed:
+	for i := range memories {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	duration := time.Since(start)
+
+	run := &models.BenchmarkRun{
+		Concurrency: concurrency,
+		Documents:   len(memories),
+		DurationMs:  duration.Milliseconds(),
+	}
+	var docIDs []string
+	var latencies []time.Duration
+	for _, result := range outcomes {
+		var blocked *blockedError
+		switch {
+		case !result.done:
+		case result.err == nil:
+			docIDs = append(docIDs, result.docID)
+			latencies = append(latencies, result.latency)
+		case errors.As(result.err, &blocked):
+			run.Failed++
+			run.Errors = incrementCount(run.Errors, failureBlocked)
+		case result.err != nil:
+			category, _ := classifyFailure(result.err)
+			run.Failed++
+			run.Errors = incrementCount(run.Errors, category)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, docIDs, err
+	}
+
+	if run.Documents > 0 {
+		run.ErrorRate = float64(run.Failed) / float64(run.Documents)
+	}
+	if duration > 0 {
+		run.DocsPerSecond = float64(len(docIDs)) / duration.Seconds()
+	}
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		run.P50LatencyMs = percentile(latencies, 0.50).Milliseconds()
+		run.P95LatencyMs = percentile(latencies, 0.95).Milliseconds()
+		run.MaxLatencyMs = latencies[len(latencies)-1].Milliseconds()
+	}
+
+	if waitProcessed && len(docIDs) > 0 {
+		if err := o.waitPipelineDrained(ctx); err != nil {
+			return run, docIDs, fmt.Errorf("failed to wait for LightRAG's pipeline: %w", err)
+		}
+		run.ProcessedPerSecond = float64(len(docIDs)) / time.Since(start).Seconds()
+	}
+
+	o.logger.Info("Benchmark run completed",
+		zap.String("connector_id", config.ID),
+		zap.Int("concurrency", run.Concurrency),
+		zap.Int("documents", run.Documents),
+		zap.Int("failed", run.Failed),
+		zap.Float64("docs_per_second", run.DocsPerSecond),
+	)
+
+	return run, docIDs, nil
+}
+
+// DeleteBenchmarkDocuments deletes the documents a benchmark inserted, returning how many were deleted
+func (o *Orchestrator) DeleteBenchmarkDocuments(ctx context.Context, docIDs []string) (int, error) {
+	deleted := 0
+	for start := 0; start < len(docIDs); start += documentPageSize {
+		batch := docIDs[start:min(start+documentPageSize, len(docIDs))]
+		if err := o.lightragClient.DeleteDocuments(ctx, batch); err != nil {
+			return deleted, fmt.Errorf("%d of %d documents deleted: %w", deleted, len(docIDs), err)
+		}
+		deleted += len(batch)
+	}
+	return deleted, nil
+}
+
+// waitPipelineDrained waits until LightRAG's processing queue is empty
+func (o *Orchestrator) waitPipelineDrained(ctx context.Context) error {
+	ticker := time.NewTicker(benchmarkPollInterval)
+	defer ticker.Stop()
+
+	for {
+		counts, err := o.lightragClient.GetDocumentStatusCounts(ctx)
+		if err != nil {
+			return err
+		}
+		queued := 0
+		for _, status := range client.QueuedDocumentStatuses {
+			queued += counts[status]
+		}
+		if queued == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// percentile returns the nearest-rank percentile p (0 to 1) of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// incrementCount adds one to a count, creating the map if needed
+func incrementCount(counts map[string]int, key string) map[string]int {
+	if counts == nil {
+		counts = make(map[string]int)
+	}
+	counts[key]++
+	return counts
+}
@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// maxQueryLimit is the largest query_limit the Memory API accepts
+const maxQueryLimit = 1000
+
+// catchUpWindow returns the query range and limit of a sync. If the time since the last sync exceeds
+// the configured query range (e.g. the process was down for several scheduled runs), the window is
+// widened to the narrowest range covering the gap, up to max_catch_up_range, and baseLimit grows in
+// proportion. catchUp is nil if no catch-up is needed.
+func catchUpWindow(config *models.ConnectorConfig, baseLimit int, lastSync, now time.Time) (queryRange string, limit int, catchUp *models.CatchUp) {
+	queryRange, limit = config.Ingestion.QueryRange, baseLimit
+
+	configured, ok := models.QueryRangeDuration(queryRange)
+	if !ok || lastSync.IsZero() {
+		return queryRange, limit, nil
+	}
+	gap := now.Sub(lastSync)
+	if gap <= configured {
+		return queryRange, limit, nil
+	}
+
+	maxRange, ok := models.QueryRangeDuration(config.Ingestion.MaxCatchUpRange)
+	if !ok || maxRange <= configured {
+		return queryRange, limit, nil
+	}
+
+	catchUp = &models.CatchUp{LastSyncTime: lastSync, Gap: gap, Truncated: true}
+	widened := configured
+	for _, candidate := range models.QueryRanges {
+		span, _ := models.QueryRangeDuration(candidate)
+		if span <= configured || span > maxRange {
+			continue
+		}
+		catchUp.QueryRange, widened = candidate, span
+		if span >= gap {
+			catchUp.Truncated = false
+			break
+		}
+	}
+
+	catchUp.QueryLimit = int(int64(limit) * int64(widened/configured))
+	if catchUp.QueryLimit > maxQueryLimit {
+		catchUp.QueryLimit = maxQueryLimit
+	}
+	if catchUp.QueryLimit < limit {
+		catchUp.QueryLimit = limit
+	}
+
+	return catchUp.QueryRange, catchUp.QueryLimit, catchUp
+}
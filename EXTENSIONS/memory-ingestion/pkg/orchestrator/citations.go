@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// assignCitations returns the short citation of each new memory, nil if the connector cites memories
+// by URI. The citations are kept in the sync state, so lookups can expand them back to the memory.
+// A memory whose citation is taken by another memory of the connector is cited by its URI.
+func (o *Orchestrator) assignCitations(config *models.ConnectorConfig, memories []models.Memory, syncState *models.SyncState) map[string]string {
+	if !config.Transform.ShortCitations {
+		return nil
+	}
+	if syncState.Citations == nil {
+		syncState.Citations = make(map[string]string)
+	}
+
+	citations := make(map[string]string, len(memories))
+	for i := range memories {
+		memoryID := memories[i].ID
+		citation := utils.ShortCitation(memoryID)
+		if cited, ok := syncState.Citations[citation]; ok && cited != memoryID {
+			o.logger.Warn("Short citation already taken, citing memory by its URI",
+				zap.String("connector_id", config.ID),
+				zap.String("memory_id", memoryID),
+				zap.String("citation", citation),
+				zap.String("cited_memory_id", cited),
+			)
+			continue
+		}
+		syncState.Citations[citation] = memoryID
+		citations[memoryID] = citation
+	}
+	return citations
+}
@@ -0,0 +1,177 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/client"
+)
+
+// AIMD tuning of adaptive insert concurrency
+const (
+	latencyTolerance = 2.0 // latency above this multiple of the baseline counts as congestion
+	latencyBackoff   = 0.75
+	overloadBackoff  = 0.5
+	latencySmoothing = 0.2  // weight of a new sample in the smoothed latency
+	baselineDrift    = 0.01 // how fast the baseline follows a persistently higher latency
+)
+
+// insertLimiter limits the number of memories inserted at once. A static limiter keeps the
+// connector's max_concurrency; an adaptive one adjusts the limit to LightRAG's insert latency
+// (additive increase while latency stays near the baseline, multiplicative decrease on 429/5xx,
+// timeouts, or rising latency).
+type insertLimiter struct {
+	mu       sync.Mutex
+	adaptive bool
+	limit    float64
+	min, max float64 // bounds of the adaptive limit
+	inFlight int
+	changed  chan struct{} // closed and replaced when a slot frees up or the limit grows
+
+	smoothed     time.Duration // smoothed insert latency
+	baseline     time.Duration // latency of an unloaded LightRAG, 0 until the first sample
+	lastDecrease time.Time
+}
+
+// newInsertLimiter creates a limiter starting at limit concurrent memories. An adaptive limiter
+// keeps the limit between minLimit and maxLimit; a static one ignores them.
+func newInsertLimiter(limit, minLimit, maxLimit int, adaptive bool) *insertLimiter {
+	if minLimit < 1 {
+		minLimit = 1
+	}
+	if maxLimit < minLimit {
+		maxLimit = minLimit
+	}
+	if adaptive {
+		limit = clampInt(limit, minLimit, maxLimit)
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	return &insertLimiter{
+		adaptive: adaptive,
+		limit:    float64(limit),
+		min:      float64(minLimit),
+		max:      float64(maxLimit),
+		changed:  make(chan struct{}),
+	}
+}
+
+// acquire blocks until a slot is free or ctx is done
+func (l *insertLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.current() {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release frees a slot
+func (l *insertLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	l.notify()
+}
+
+// observe feeds the outcome of an insert into the adaptive limit
+func (l *insertLimiter) observe(latency time.Duration, err error) {
+	if !l.adaptive {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err != nil {
+		if isOverload(err) {
+			l.decrease(overloadBackoff, latency)
+		}
+		return
+	}
+
+	if l.smoothed == 0 {
+		l.smoothed = latency
+	} else {
+		l.smoothed += time.Duration(latencySmoothing * float64(latency-l.smoothed))
+	}
+	switch {
+	case l.baseline == 0 || l.smoothed < l.baseline:
+		l.baseline = l.smoothed
+	default:
+		l.baseline += time.Duration(baselineDrift * float64(l.smoothed-l.baseline))
+	}
+
+	if float64(l.smoothed) > latencyTolerance*float64(l.baseline) {
+		l.decrease(latencyBackoff, latency)
+		return
+	}
+
+	// Additive increase: about one more slot per round of limit inserts
+	previous := l.current()
+	l.limit += 1 / l.limit
+	if l.limit > l.max {
+		l.limit = l.max
+	}
+	if l.current() > previous {
+		l.notify()
+	}
+}
+
+// size returns the current number of memories processed at once
+func (l *insertLimiter) size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.current()
+}
+
+// decrease shrinks the limit by factor, at most once per insert latency (the smoothed one, or that
+// of the observed insert if higher) so that a burst of slow or failing in-flight inserts counts as
+// one congestion signal. Must be called with l.mu held.
+func (l *insertLimiter) decrease(factor float64, latency time.Duration) {
+	window := l.smoothed
+	if latency > window {
+		window = latency
+	}
+
+	now := time.Now()
+	if now.Sub(l.lastDecrease) < window {
+		return
+	}
+	l.lastDecrease = now
+
+	l.limit *= factor
+	if l.limit < l.min {
+		l.limit = l.min
+	}
+}
+
+// current returns the limit as a slot count. Must be called with l.mu held.
+func (l *insertLimiter) current() int {
+	return int(l.limit)
+}
+
+// notify wakes up waiting acquires. Must be called with l.mu held.
+func (l *insertLimiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// isOverload returns true if an insert error signals that LightRAG is overloaded
+func isOverload(err error) bool {
+	return client.IsOverloaded(err) || isTimeout(err)
+}
@@ -0,0 +1,82 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kamir/memory-connector/pkg/contentfilter"
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// blockedError is returned for memories that violate a blocking content policy
+type blockedError struct {
+	policies []string
+}
+
+// Error implements the error interface
+func (e *blockedError) Error() string {
+	return fmt.Sprintf("blocked by content policy: %s", strings.Join(e.policies, ", "))
+}
+
+// contentStage checks memories against the content policies before they're transformed
+type contentStage struct {
+	filter contentfilter.Filter
+}
+
+// SetContentFilter sets the content filter of connectors with transform.content_filter. Without
+// it, their syncs fail rather than ingest unchecked memories.
+func (o *Orchestrator) SetContentFilter(filter contentfilter.Filter) {
+	o.contentFilter = filter
+}
+
+// contentStageFor returns the content stage of a connector, nil if it doesn't filter content
+func (o *Orchestrator) contentStageFor(config *models.ConnectorConfig) (*contentStage, error) {
+	if !config.Transform.ContentFilter {
+		return nil, nil
+	}
+	if o.contentFilter == nil {
+		return nil, fmt.Errorf("connector %s filters content but no content policies are configured", config.ID)
+	}
+	return &contentStage{filter: o.contentFilter}, nil
+}
+
+// apply returns the memory to transform, with violations of redacting policies redacted, and the
+// metadata naming the violated policies. It returns a blockedError if a blocking policy is violated.
+func (s *contentStage) apply(memory *models.Memory) (*models.Memory, map[string]string, error) {
+	transcriptViolations := s.filter.Scan(memory.Transcript)
+	violations := transcriptViolations
+	segmentViolations := make([][]contentfilter.Violation, len(memory.Segments))
+	for i, segment := range memory.Segments {
+		segmentViolations[i] = s.filter.Scan(segment.Text)
+		violations = append(violations, segmentViolations[i]...)
+	}
+	if len(violations) == 0 {
+		return memory, nil, nil
+	}
+
+	if blocked := contentfilter.Policies(violations, contentfilter.ActionBlock); len(blocked) > 0 {
+		return nil, nil, &blockedError{policies: blocked}
+	}
+
+	metadata := make(map[string]string)
+	if tagged := contentfilter.Policies(violations, contentfilter.ActionTag); len(tagged) > 0 {
+		metadata["content_flags"] = strings.Join(tagged, ",")
+	}
+
+	redacted := contentfilter.Policies(violations, contentfilter.ActionRedact)
+	if len(redacted) == 0 {
+		return memory, metadata, nil
+	}
+	metadata["content_redacted"] = strings.Join(redacted, ",")
+
+	filtered := *memory
+	filtered.Transcript = contentfilter.Redact(memory.Transcript, transcriptViolations)
+	if len(memory.Segments) > 0 {
+		filtered.Segments = make([]models.TranscriptSegment, len(memory.Segments))
+		for i, segment := range memory.Segments {
+			segment.Text = contentfilter.Redact(segment.Text, segmentViolations[i])
+			filtered.Segments[i] = segment
+		}
+	}
+	return &filtered, metadata, nil
+}
@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// SetDeadLetterPolicy sets when failed memories in the Dead Letter Queue are retried, by default
+// models.DefaultDeadLetterPolicy. Must be called before syncs start.
+func (o *Orchestrator) SetDeadLetterPolicy(policy models.DeadLetterPolicy) {
+	o.deadLetters = policy
+}
+
+// DueDeadLetters returns the number of memories in a connector's Dead Letter Queue whose retry is due
+func (o *Orchestrator) DueDeadLetters(ctx context.Context, connectorID string) (int, error) {
+	syncState, err := o.stateManager.GetState(ctx, connectorID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sync state: %w", err)
+	}
+	return len(syncState.DueFailedItems(o.clock.Now())), nil
+}
+
+// RetryDeadLetters retries the memories in a connector's Dead Letter Queue whose retry is due. They
+// are fetched again from the Memory API, searching its latest limit memories, and pass through the
+// connector's stages like those of a sync. Memories that fail again are scheduled for their next
+// retry; those a sync ingested meanwhile, or the Memory API doesn't return anymore, leave the queue.
+// Daily digest connectors aren't retried memory by memory, their next sync retries the digests.
+func (o *Orchestrator) RetryDeadLetters(ctx context.Context, config *models.ConnectorConfig, limit int) (*models.DeadLetterRetry, error) {
+	if config.Transform.Mode == models.TransformModeDailyDigest {
+		return nil, fmt.Errorf("connector %s ingests daily digests, which aren't retried by memory", config.ID)
+	}
+	config = config.WithFeatures(o.featureOverridesFor(config.ID))
+
+	syncState, err := o.stateManager.GetState(ctx, config.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync state: %w", err)
+	}
+	if syncState.ContextID == "" {
+		syncState.ContextID = config.ContextID
+	}
+
+	due := syncState.DueFailedItems(o.clock.Now())
+	result := &models.DeadLetterRetry{ConnectorID: config.ID, Due: len(due)}
+	if len(due) == 0 {
+		return result, nil
+	}
+
+	memoryIDs := make([]string, 0, len(due))
+	for _, item := range due {
+		memoryIDs = append(memoryIDs, item.MemoryID)
+	}
+	memories, err := o.FetchMemories(ctx, config, memoryIDs, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpstreamFailed, err)
+	}
+
+	retry := make([]models.Memory, 0, len(due))
+	for _, item := range due {
+		memory := memories[item.MemoryID]
+		if memory == nil || syncState.IsProcessed(item.MemoryID) {
+			syncState.RemoveFailedItem(item.MemoryID)
+			result.Dropped++
+			continue
+		}
+		retry = append(retry, *memory)
+	}
+
+	if len(retry) > 0 {
+		report := &models.SyncReport{ConnectorID: config.ID, ContextID: config.ContextID, StartTime: o.clock.Now()}
+		var backpressure *syncBackpressure
+		if !config.Export.ExportOnly() {
+			backpressure = o.pipeline.newSync()
+		}
+		err := o.processMemoriesConcurrent(ctx, retry, config, syncState, report, backpressure, func(models.SyncProgress) {}, nil)
+		if err != nil && !errors.Is(err, errFailureThreshold) {
+			return nil, err
+		}
+
+		result.Ingested = report.TotalProcessed
+		result.Failed = report.TotalFailed
+		result.Deferred = report.TotalDeferred
+		result.Blocked = report.TotalBlocked
+		for _, item := range syncState.FailedItems {
+			if item.GaveUp && memories[item.MemoryID] != nil {
+				result.GaveUp++
+			}
+		}
+	}
+
+	if err := o.stateManager.SaveState(context.WithoutCancel(ctx), syncState); err != nil {
+		return nil, fmt.Errorf("failed to save state: %w", err)
+	}
+
+	o.logger.Info("Retried dead letters",
+		zap.String("connector_id", config.ID),
+		zap.Int("due", result.Due),
+		zap.Int("ingested", result.Ingested),
+		zap.Int("failed", result.Failed),
+		zap.Int("gave_up", result.GaveUp),
+		zap.Int("dropped", result.Dropped),
+	)
+
+	return result, nil
+}
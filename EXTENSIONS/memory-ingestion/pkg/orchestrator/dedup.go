@@ -0,0 +1,100 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kamir/memory-connector/pkg/dedup"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// suppressDuplicates removes the new memories whose transcript nearly equals that of an earlier
+// fetched memory, marks them processed, and records them in the report. It returns the remaining
+// memories and, if the connector merges duplicates, the duplicates of each remaining memory. A
+// duplicate of a memory ingested by an earlier run is skipped even when merging, since that
+// memory's document already exists.
+func (o *Orchestrator) suppressDuplicates(
+	config *models.ConnectorConfig,
+	fetched []models.Memory,
+	newMemories []models.Memory,
+	syncState *models.SyncState,
+	report *models.SyncReport,
+) ([]models.Memory, map[string][]string) {
+	settings := config.Ingestion.Deduplication
+	if !settings.Enabled || len(newMemories) == 0 {
+		return newMemories, nil
+	}
+
+	duplicates := dedup.Find(fetched, newMemories, settings.Threshold)
+	if len(duplicates) == 0 {
+		return newMemories, nil
+	}
+
+	remaining := make([]models.Memory, 0, len(newMemories)-len(duplicates))
+	isNew := make(map[string]bool, len(newMemories))
+	for _, memory := range newMemories {
+		if _, ok := duplicates[memory.ID]; !ok {
+			remaining = append(remaining, memory)
+			isNew[memory.ID] = true
+		}
+	}
+
+	var merged map[string][]string
+	for _, memory := range newMemories {
+		duplicate, ok := duplicates[memory.ID]
+		if !ok {
+			continue
+		}
+
+		action := models.DuplicateActionSkip
+		if settings.Action == models.DuplicateActionMerge && isNew[duplicate.DuplicateOf] {
+			action = models.DuplicateActionMerge
+			if merged == nil {
+				merged = make(map[string][]string)
+			}
+			merged[duplicate.DuplicateOf] = append(merged[duplicate.DuplicateOf], memory.ID)
+		}
+
+		syncState.MarkProcessed(memory.ID)
+		report.TotalDuplicates++
+		report.MemoriesDuplicate = append(report.MemoriesDuplicate, models.DuplicateItem{
+			MemoryID:    memory.ID,
+			DuplicateOf: duplicate.DuplicateOf,
+			Similarity:  duplicate.Similarity,
+			Action:      action,
+		})
+
+		o.logger.Debug("Suppressed near-duplicate memory",
+			zap.String("connector_id", config.ID),
+			zap.String("memory_id", memory.ID),
+			zap.String("duplicate_of", duplicate.DuplicateOf),
+			zap.Float64("similarity", duplicate.Similarity),
+			zap.String("action", action),
+		)
+	}
+
+	return remaining, merged
+}
+
+// duplicateReference returns the note listing the duplicates merged into a memory's document, and
+// the metadata naming them
+func duplicateReference(duplicates []string) (string, map[string]string) {
+	sorted := append([]string(nil), duplicates...)
+	sort.Strings(sorted)
+
+	uris := make([]string, len(sorted))
+	for i, memoryID := range sorted {
+		uris[i] = utils.MemoryURI(memoryID)
+	}
+
+	text := fmt.Sprintf("\n\n[Also recorded as: %s]", strings.Join(uris, "; "))
+	metadata := map[string]string{
+		"duplicate_ids":   strings.Join(sorted, ","),
+		"duplicate_count": strconv.Itoa(len(sorted)),
+	}
+	return text, metadata
+}
@@ -0,0 +1,204 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/transformer"
+	"go.uber.org/zap"
+)
+
+// digestDayLayout formats the calendar day of a daily digest
+const digestDayLayout = "2006-01-02"
+
+// datedMemory is a memory with its creation time in the connector's time zone
+type datedMemory struct {
+	memory    models.Memory
+	createdAt time.Time
+}
+
+// digestOutcome is the result of a memory of a daily digest, err is nil if it was ingested
+type digestOutcome struct {
+	memory   *models.Memory          // as fetched from the Memory API
+	document models.IngestedDocument // the digest the memory was ingested in
+	err      error
+}
+
+// processDigests ingests memories as one document per calendar day (in the schedule's time zone).
+// Memories of the current day are deferred until it's over, so each day is digested once.
+func (o *Orchestrator) processDigests(
+	ctx context.Context,
+	processCtx context.Context,
+	memories []models.Memory,
+	config *models.ConnectorConfig,
+	syncState *models.SyncState,
+	report *models.SyncReport,
+	backpressure *syncBackpressure,
+	progress ProgressFunc,
+	trans *transformer.Transformer,
+	transformConfig transformer.TransformConfig,
+	stages memoryStages,
+	budget *quotaBudget,
+	limiter *insertLimiter,
+) {
+	location := config.Schedule.Location()
+	today := o.clock.Now().In(location).Format(digestDayLayout)
+
+	days := make(map[string][]datedMemory)
+	for _, memory := range memories {
+		createdAt, err := memory.ParseCreatedAt()
+		if err != nil {
+			err = fmt.Errorf("%w: memory %s has no valid created_at: %w", transformer.ErrTransformFailed, memory.ID, err)
+			o.recordMemory(config, syncState, report, &memory, models.IngestedDocument{}, err)
+			progress(syncProgress(report, len(memories), memory.ID))
+			continue
+		}
+		createdAt = createdAt.In(location)
+		day := createdAt.Format(digestDayLayout)
+		if day >= today {
+			report.TotalAwaitingDigest++
+			continue
+		}
+		days[day] = append(days[day], datedMemory{memory: memory, createdAt: createdAt})
+	}
+
+	o.logger.Info("Grouped memories into daily digests",
+		zap.String("connector_id", config.ID),
+		zap.Int("days", len(days)),
+		zap.Int("awaiting_digest", report.TotalAwaitingDigest),
+	)
+
+	dayKeys := make([]string, 0, len(days))
+	for day := range days {
+		dayKeys = append(dayKeys, day)
+	}
+	sort.Strings(dayKeys)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, day := range dayKeys {
+		wg.Add(1)
+		go func(day string, group []datedMemory) {
+			defer wg.Done()
+
+			// Acquire a slot, unless the sync is being stopped
+			if limiter.acquire(ctx) == nil {
+				defer limiter.release()
+			}
+			if ctx.Err() != nil {
+				mu.Lock()
+				report.TotalDeferred += len(group)
+				mu.Unlock()
+				return
+			}
+
+			// Hold back while LightRAG's pipeline is full
+			if err := backpressure.wait(ctx); err != nil {
+				mu.Lock()
+				report.TotalDeferred += len(group)
+				mu.Unlock()
+				return
+			}
+
+			outcomes, err := o.processDigest(processCtx, config, trans, day, group, transformConfig, stages, budget, limiter)
+			if errors.Is(err, errQuotaExhausted) {
+				mu.Lock()
+				report.TotalDeferred += len(group)
+				mu.Unlock()
+				return
+			}
+
+			// Update report (thread-safe)
+			mu.Lock()
+			defer mu.Unlock()
+
+			for _, outcome := range outcomes {
+				o.recordMemory(config, syncState, report, outcome.memory, outcome.document, outcome.err)
+				progress(syncProgress(report, len(memories), outcome.memory.ID))
+			}
+		}(day, days[day])
+	}
+
+	wg.Wait()
+}
+
+// processDigest transforms the memories of a day and inserts them as one document. Memories that
+// fail to transform are left out of the digest; if the insert fails, all others fail with it.
+func (o *Orchestrator) processDigest(
+	ctx context.Context,
+	config *models.ConnectorConfig,
+	trans *transformer.Transformer,
+	day string,
+	group []datedMemory,
+	transformConfig transformer.TransformConfig,
+	stages memoryStages,
+	budget *quotaBudget,
+	limiter *insertLimiter,
+) ([]digestOutcome, error) {
+	if budget.spent() {
+		return nil, errQuotaExhausted
+	}
+
+	// Transform each memory, filtered and translated to the connector's target language first
+	transformStart := time.Now()
+	outcomes := make([]digestOutcome, 0, len(group))
+	entries := make([]transformer.DigestEntry, 0, len(group))
+	digested := make([]*models.Memory, 0, len(group)) // the memories of entries, as fetched
+	for i := range group {
+		memory, _, err := stages.apply(ctx, &group[i].memory)
+		var text string
+		if err == nil {
+			text, _, err = trans.Transform(memory, transformConfig)
+		}
+		if err != nil {
+			outcomes = append(outcomes, digestOutcome{memory: &group[i].memory, err: err})
+			continue
+		}
+		entries = append(entries, transformer.DigestEntry{Memory: memory, CreatedAt: group[i].createdAt, Text: text})
+		digested = append(digested, &group[i].memory)
+	}
+	if len(entries) == 0 {
+		return outcomes, nil
+	}
+	text, metadata := transformer.ComposeDigest(day, entries, transformConfig)
+	if err := stages.checkMetadata(metadata); err != nil {
+		// The digest's metadata fails the same way for each of its memories
+		for _, memory := range digested {
+			outcomes = append(outcomes, digestOutcome{memory: memory, err: err})
+		}
+		return outcomes, nil
+	}
+	transformDuration := time.Since(transformStart)
+
+	// The LLM work LightRAG does for a document grows with its text
+	tokens := models.EstimateTokens(text)
+	if !budget.reserve(tokens) {
+		return nil, errQuotaExhausted
+	}
+
+	// Insert the digest into LightRAG
+	insertStart := time.Now()
+	document, err := o.insertDocument(ctx, config, text, metadata)
+	limiter.observe(time.Since(insertStart), err)
+	if err != nil {
+		budget.release(tokens)
+		err = fmt.Errorf("insertion failed: %w", err)
+	}
+	for _, memory := range digested {
+		outcomes = append(outcomes, digestOutcome{memory: memory, document: document, err: err})
+	}
+
+	o.logger.Debug("Daily digest processed",
+		zap.String("day", day),
+		zap.Int("memories", len(entries)),
+		zap.Duration("transform_time", transformDuration),
+		zap.Duration("insert_time", time.Since(insertStart)),
+	)
+
+	return outcomes, nil
+}
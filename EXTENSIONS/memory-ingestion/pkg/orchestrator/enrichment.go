@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/kamir/memory-connector/pkg/enrichment"
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// errEnrichmentFailed is wrapped by errors of required enrichers
+var errEnrichmentFailed = errors.New("enrichment failed")
+
+// enrichmentStage runs a connector's chain of enrichers on the metadata of its documents
+type enrichmentStage struct {
+	settings  []models.EnricherConfig
+	enrichers []enrichment.Enricher // per settings entry
+	logger    *zap.Logger
+}
+
+// enrichmentStageFor returns the enrichment stage of a connector, nil if it has no enrichers.
+// Stages are reused until the connector's enrichers change, so enrichers can cache lookups.
+func (o *Orchestrator) enrichmentStageFor(config *models.ConnectorConfig) (*enrichmentStage, error) {
+	if len(config.Transform.Enrichers) == 0 {
+		return nil, nil
+	}
+
+	o.enrichMu.Lock()
+	defer o.enrichMu.Unlock()
+
+	if stage, ok := o.enrichers[config.ID]; ok && reflect.DeepEqual(stage.settings, config.Transform.Enrichers) {
+		return stage, nil
+	}
+
+	stage := &enrichmentStage{
+		settings:  append([]models.EnricherConfig(nil), config.Transform.Enrichers...),
+		enrichers: make([]enrichment.Enricher, len(config.Transform.Enrichers)),
+		logger:    o.logger,
+	}
+	for i, settings := range stage.settings {
+		enricher, err := enrichment.New(settings.Type, settings.Options)
+		if err != nil {
+			return nil, fmt.Errorf("connector %s: enricher %s: %w", config.ID, settings.Type, err)
+		}
+		stage.enrichers[i] = enricher
+	}
+	o.enrichers[config.ID] = stage
+	return stage, nil
+}
+
+// apply runs the enrichers in order, each seeing the metadata of the previous ones, and returns the
+// metadata and the mentions of the enrichers for rich documents. The metadata of a failing enricher
+// is discarded; if it's required, the memory fails.
+func (s *enrichmentStage) apply(ctx context.Context, memory *models.Memory, metadata map[string]string) (map[string]string, []string, error) {
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+
+	var mentions []string
+	for i, enricher := range s.enrichers {
+		enriched := make(map[string]string, len(metadata))
+		for key, value := range metadata {
+			enriched[key] = value
+		}
+
+		if err := enricher.Enrich(ctx, memory, enriched); err != nil {
+			if s.settings[i].Required {
+				return nil, nil, fmt.Errorf("%w: %s: %w", errEnrichmentFailed, s.settings[i].Type, err)
+			}
+			s.logger.Warn("Enricher failed, ingesting memory without its metadata",
+				zap.String("memory_id", memory.ID),
+				zap.String("enricher", s.settings[i].Type),
+				zap.Error(err),
+			)
+			continue
+		}
+		metadata = enriched
+
+		if mentioner, ok := enricher.(enrichment.Mentioner); ok {
+			if mention := mentioner.Mention(enriched); mention != "" {
+				mentions = append(mentions, mention)
+			}
+		}
+	}
+	return metadata, mentions, nil
+}
+
+// prefetch lets the batch enrichers look up the memories at once, before apply enriches them one by
+// one. Failures are logged; the memories concerned are looked up again by apply.
+func (s *enrichmentStage) prefetch(ctx context.Context, memories []models.Memory) {
+	for i, enricher := range s.enrichers {
+		batch, ok := enricher.(enrichment.BatchEnricher)
+		if !ok {
+			continue
+		}
+		if err := batch.Prefetch(ctx, memories); err != nil {
+			s.logger.Warn("Enricher failed to look up memories in a batch, looking them up one by one",
+				zap.String("enricher", s.settings[i].Type),
+				zap.Error(err),
+			)
+		}
+		if reporter, ok := enricher.(enrichment.CacheReporter); ok {
+			stats := reporter.CacheStats()
+			s.logger.Debug("Enricher cache",
+				zap.String("enricher", s.settings[i].Type),
+				zap.Int64("hits", stats.Hits),
+				zap.Int64("misses", stats.Misses),
+				zap.Int("entries", stats.Entries),
+			)
+		}
+	}
+}
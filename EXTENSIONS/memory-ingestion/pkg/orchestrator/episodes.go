@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/episodes"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// detectEpisodes returns the episode of each fetched memory that belongs to one, nil if the connector
+// doesn't detect episodes. All fetched memories are clustered, including those ingested by earlier
+// runs, so new memories continue their episodes.
+func (o *Orchestrator) detectEpisodes(config *models.ConnectorConfig, memories []models.Memory) map[string]*episodes.Episode {
+	if !config.Transform.Episodes.Enabled {
+		return nil
+	}
+
+	detected := episodes.Detect(config.ContextID, memories, episodes.Config{
+		Window:            time.Duration(config.Transform.Episodes.WindowMinutes) * time.Minute,
+		MinSharedEntities: config.Transform.Episodes.MinSharedEntities,
+		Location:          config.Schedule.Location(),
+	})
+
+	o.logger.Debug("Detected episodes",
+		zap.String("connector_id", config.ID),
+		zap.Int("memories_in_episodes", len(detected)),
+	)
+
+	return detected
+}
+
+// episodeReference returns the cross-reference to the other memories of an episode appended to a
+// memory's document, and the metadata linking it to the episode
+func episodeReference(episode *episodes.Episode, memoryID string) (string, map[string]string) {
+	related := make([]string, 0, len(episode.Members)-1)
+	for _, member := range episode.Members {
+		if member.MemoryID != memoryID {
+			related = append(related, fmt.Sprintf("%s at %s",
+				utils.MemoryURI(member.MemoryID), member.CreatedAt.Format("2006-01-02 15:04")))
+		}
+	}
+
+	position := episode.Position(memoryID)
+	text := fmt.Sprintf("\n\n[Episode %s, memory %d of %d. Related memories: %s]",
+		episode.ID, position, len(episode.Members), strings.Join(related, "; "))
+	metadata := map[string]string{
+		"episode_id":       episode.ID,
+		"episode_size":     strconv.Itoa(len(episode.Members)),
+		"episode_position": strconv.Itoa(position),
+	}
+	return text, metadata
+}
@@ -0,0 +1,79 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/export"
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// errExportFailed is wrapped by errors of a connector's export sink
+var errExportFailed = errors.New("export failed")
+
+// connectorExport is the export sink created for one connector's export settings
+type connectorExport struct {
+	settings models.ExportConfig
+	sink     export.Sink
+}
+
+// exportSinkFor returns the export sink of a connector, created on first use and again when its
+// export settings change, nil if it doesn't export
+func (o *Orchestrator) exportSinkFor(config *models.ConnectorConfig) export.Sink {
+	if !config.Export.Enabled() {
+		return nil
+	}
+
+	o.exportMu.Lock()
+	defer o.exportMu.Unlock()
+
+	if cached, ok := o.exports[config.ID]; ok && reflect.DeepEqual(cached.settings, config.Export) {
+		return cached.sink
+	}
+
+	sink := export.New(config.Export)
+	o.exports[config.ID] = connectorExport{settings: config.Export, sink: sink}
+
+	o.logger.Info("Created export sink",
+		zap.String("connector_id", config.ID),
+		zap.String("mode", config.Export.Mode),
+		zap.String("path", config.Export.Path),
+	)
+
+	return sink
+}
+
+// insertDocument inserts a document into LightRAG and writes it to the connector's export, if it
+// exports its documents. Connectors in export-only mode don't insert. The document's ID is the one
+// LightRAG returned, or derived from the text as LightRAG does if it returned none.
+func (o *Orchestrator) insertDocument(ctx context.Context, config *models.ConnectorConfig, text string, metadata map[string]string) (models.IngestedDocument, error) {
+	document := models.IngestedDocument{RequestHash: client.DocumentRequestHash(text, metadata)}
+	if !config.Export.ExportOnly() {
+		resp, err := o.lightragClient.InsertDocument(ctx, text, metadata)
+		if err != nil {
+			return document, err
+		}
+		document.DocID, document.TrackID, document.Status = resp.DocID, resp.TrackID, resp.Status
+		if resp.Status == client.InsertStatusDuplicated {
+			o.logger.Warn("LightRAG already holds a document of the same text",
+				zap.String("connector_id", config.ID),
+				zap.String("file_path", metadata["file_path"]),
+				zap.String("message", resp.Message),
+			)
+		}
+	}
+	if document.DocID == "" {
+		document.DocID = export.DocumentID(text)
+	}
+
+	if sink := o.exportSinkFor(config); sink != nil {
+		if err := sink.Write(ctx, export.NewDocument(config, text, metadata, o.clock.Now())); err != nil {
+			return document, fmt.Errorf("%w: %w", errExportFailed, err)
+		}
+	}
+	return document, nil
+}
@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/transformer"
+)
+
+// classifyFailure returns the failure category of a memory and whether a later retry may succeed.
+// Unreadable timestamps, transform errors, metadata out of schema, and rejected documents fail again; 429 Too Many Requests is the retryable 4xx.
+// Translation errors may be transient, except for requests the translation API rejects; enrichment
+// lookups and exports are retried.
+func classifyFailure(err error) (string, bool) {
+	var statusErr *client.StatusError
+	switch {
+	case errors.Is(err, models.ErrInvalidTimestamp):
+		return models.FailureTimestamp, false
+	case errors.Is(err, transformer.ErrTransformFailed):
+		return models.FailureTransform, false
+	case errors.Is(err, models.ErrInvalidMetadata):
+		return models.FailureMetadata, false
+	case errors.Is(err, errEnrichmentFailed):
+		return models.FailureEnrichment, true
+	case errors.Is(err, errExportFailed):
+		return models.FailureExport, true
+	case errors.Is(err, errTranslationFailed):
+		return models.FailureTranslation, !errors.As(err, &statusErr) || client.IsOverloaded(err)
+	case errors.Is(err, client.ErrUnavailable):
+		return models.FailureUpstream5xx, true
+	case errors.As(err, &statusErr), errors.Is(err, client.ErrUnprocessable):
+		return models.FailureUpstream4xx, errors.Is(err, client.ErrRateLimited)
+	case isTimeout(err):
+		return models.FailureTimeout, true
+	default:
+		return models.FailureNetwork, true
+	}
+}
+
+// isTimeout returns true if err is a deadline or network timeout
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,22 @@
+package orchestrator
+
+// FeatureOverrides holds the runtime overrides of connectors' feature flags
+type FeatureOverrides interface {
+	// For returns the overrides of a connector, flag -> enabled
+	For(connectorID string) map[string]bool
+}
+
+// SetFeatureOverrides makes syncs, re-ingests, benchmarks, and snapshots apply the runtime
+// overrides of connectors' feature flags on top of their features settings
+func (o *Orchestrator) SetFeatureOverrides(overrides FeatureOverrides) {
+	o.features = overrides
+}
+
+// featureOverridesFor returns the runtime overrides of a connector's feature flags, nil if there
+// are none
+func (o *Orchestrator) featureOverridesFor(connectorID string) map[string]bool {
+	if o.features == nil {
+		return nil
+	}
+	return o.features.For(connectorID)
+}
@@ -0,0 +1,48 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// FetchMemory returns the current version of a memory from the connector's Memory API, searching
+// the memories of the widest query range (up to limit), nil if it isn't among them. The Memory API
+// has no lookup of single memories, so a memory beyond limit can't be told from a deleted one.
+func (o *Orchestrator) FetchMemory(ctx context.Context, config *models.ConnectorConfig, memoryID string, limit int) (*models.Memory, error) {
+	memories, err := o.FetchMemories(ctx, config, []string{memoryID}, limit)
+	if err != nil {
+		return nil, err
+	}
+	return memories[memoryID], nil
+}
+
+// FetchMemories is FetchMemory for several memories, fetched at once. Memories that aren't among
+// the latest limit are missing from the result.
+func (o *Orchestrator) FetchMemories(ctx context.Context, config *models.ConnectorConfig, memoryIDs []string, limit int) (map[string]*models.Memory, error) {
+	queryRange := models.QueryRanges[len(models.QueryRanges)-1]
+	memoryList, err := o.memorySourceFor(config).GetMemories(ctx, config.ContextID, limit, queryRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch memories: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(memoryIDs))
+	for _, memoryID := range memoryIDs {
+		wanted[memoryID] = true
+	}
+	memories := make(map[string]*models.Memory, len(memoryIDs))
+	for i := range memoryList.Memories {
+		if wanted[memoryList.Memories[i].ID] {
+			memories[memoryList.Memories[i].ID] = &memoryList.Memories[i]
+		}
+	}
+	return memories, nil
+}
+
+// CheckMemoryAPI checks that the Memory API of a connector answers, requesting its latest memory
+// over the narrowest range
+func (o *Orchestrator) CheckMemoryAPI(ctx context.Context, config *models.ConnectorConfig) error {
+	_, err := o.memorySourceFor(config).GetMemories(ctx, config.ContextID, 1, models.QueryRanges[0])
+	return err
+}
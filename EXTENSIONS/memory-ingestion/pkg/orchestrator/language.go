@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/language"
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// errTranslationFailed is wrapped by errors of the translation API
+var errTranslationFailed = errors.New("translation failed")
+
+// languageStage detects the language of transcripts and translates them to a target language
+// before they're transformed
+type languageStage struct {
+	target     string // ISO 639-1 code, empty to only detect
+	translator client.Translator
+}
+
+// SetTranslator sets the translation API of connectors with transform.target_language. Without
+// it, their syncs fail rather than ingest untranslated memories.
+func (o *Orchestrator) SetTranslator(translator client.Translator) {
+	o.translator = translator
+}
+
+// languageStageFor returns the language stage of a connector, nil if it neither detects nor translates
+func (o *Orchestrator) languageStageFor(config *models.ConnectorConfig) (*languageStage, error) {
+	transform := config.Transform
+	if !transform.DetectLanguage && transform.TargetLanguage == "" {
+		return nil, nil
+	}
+	if transform.TargetLanguage != "" && o.translator == nil {
+		return nil, fmt.Errorf("connector %s translates but no translation API is configured", config.ID)
+	}
+	return &languageStage{target: transform.TargetLanguage, translator: o.translator}, nil
+}
+
+// apply returns the memory to transform, translated if its language differs from the target,
+// and the metadata describing its original language
+func (s *languageStage) apply(ctx context.Context, memory *models.Memory) (*models.Memory, map[string]string, error) {
+	metadata := make(map[string]string)
+	detected := language.Detect(memory.Transcript)
+	if detected != "" {
+		metadata["original_language"] = detected
+	}
+
+	if s.target == "" || detected == s.target || memory.Transcript == "" {
+		return memory, metadata, nil
+	}
+
+	// Undetected languages are left to the translation API
+	translated, err := s.translator.Translate(ctx, memory.Transcript, detected, s.target)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", errTranslationFailed, err)
+	}
+
+	// The segments time the original sentences, which don't match the translation
+	translatedMemory := *memory
+	translatedMemory.Transcript = translated
+	translatedMemory.Segments = nil
+	metadata["translated"] = "true"
+	return &translatedMemory, metadata, nil
+}
@@ -0,0 +1,84 @@
+package orchestrator
+
+import (
+	"github.com/kamir/memory-connector/pkg/episodes"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/trips"
+)
+
+// memoryLinks relate a new memory to other memories, its trip, and its place, referenced in its document
+type memoryLinks struct {
+	episode    *episodes.Episode    // nil if the memory belongs to no episode
+	duplicates []string             // near-duplicates merged into the memory's document
+	trip       *trips.Membership    // nil if the memory was recorded on no trip
+	place      *models.PlaceCluster // frequent place the memory was recorded at, nil if none
+}
+
+// linkMemories combines the episodes, merged duplicates, trips, and places of the fetched memories by memory ID
+func linkMemories(
+	episodeMap map[string]*episodes.Episode,
+	merged map[string][]string,
+	tripMap map[string]trips.Membership,
+	placeMap map[string]*models.PlaceCluster,
+) map[string]*memoryLinks {
+	links := make(map[string]*memoryLinks, len(episodeMap)+len(merged)+len(tripMap)+len(placeMap))
+	linksOf := func(memoryID string) *memoryLinks {
+		if links[memoryID] == nil {
+			links[memoryID] = &memoryLinks{}
+		}
+		return links[memoryID]
+	}
+
+	for memoryID, episode := range episodeMap {
+		linksOf(memoryID).episode = episode
+	}
+	for memoryID, duplicates := range merged {
+		linksOf(memoryID).duplicates = duplicates
+	}
+	for memoryID, trip := range tripMap {
+		trip := trip
+		linksOf(memoryID).trip = &trip
+	}
+	for memoryID, place := range placeMap {
+		linksOf(memoryID).place = place
+	}
+	return links
+}
+
+// reference returns the text appended to a memory's document and the metadata describing its links
+func (l *memoryLinks) reference(memoryID string) (string, map[string]string) {
+	var text string
+	var metadata map[string]string
+	if l.episode != nil {
+		reference, episodeMetadata := episodeReference(l.episode, memoryID)
+		text += reference
+		metadata = mergeMetadata(metadata, episodeMetadata)
+	}
+	if len(l.duplicates) > 0 {
+		reference, duplicateMetadata := duplicateReference(l.duplicates)
+		text += reference
+		metadata = mergeMetadata(metadata, duplicateMetadata)
+	}
+	if l.trip != nil {
+		reference, tripMetadata := tripReference(l.trip)
+		text += reference
+		metadata = mergeMetadata(metadata, tripMetadata)
+	}
+	if l.place != nil {
+		reference, placeMetadata := placeReference(l.place)
+		text += reference
+		metadata = mergeMetadata(metadata, placeMetadata)
+	}
+	return text, metadata
+}
+
+// mergeMetadata adds extra to metadata, which is created if nil and extra isn't empty
+func mergeMetadata(metadata, extra map[string]string) map[string]string {
+	if metadata == nil && len(extra) > 0 {
+		metadata = make(map[string]string, len(extra))
+	}
+	for key, value := range extra {
+		metadata[key] = value
+	}
+	return metadata
+}
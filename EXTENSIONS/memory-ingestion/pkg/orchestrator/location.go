@@ -0,0 +1,42 @@
+package orchestrator
+
+import (
+	"math"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// locationStage coarsens the coordinates of memories before they're transformed, so documents,
+// enricher lookups, and trip names never see more than the connector's location precision. The
+// state keeps full precision for trips and places.
+type locationStage struct {
+	precision string
+	decimals  int
+}
+
+// locationStageFor returns the location stage of a connector, nil if it keeps exact coordinates
+func locationStageFor(config *models.ConnectorConfig) *locationStage {
+	decimals, _, ok := models.LocationPrecisionLimits(config.Transform.LocationPrecision)
+	if !ok {
+		return nil
+	}
+	return &locationStage{precision: config.Transform.LocationPrecision, decimals: decimals}
+}
+
+// apply returns the memory with its coordinates truncated, and the metadata naming the precision
+func (s *locationStage) apply(memory *models.Memory) (*models.Memory, map[string]string) {
+	if !memory.HasLocation() {
+		return memory, nil
+	}
+
+	lat, lon := s.coarsen(*memory.LocationLat, *memory.LocationLon)
+	coarse := *memory
+	coarse.LocationLat, coarse.LocationLon = &lat, &lon
+	return &coarse, map[string]string{"location_precision": s.precision}
+}
+
+// coarsen truncates coordinates to the stage's decimals
+func (s *locationStage) coarsen(lat, lon float64) (float64, float64) {
+	scale := math.Pow(10, float64(s.decimals))
+	return math.Trunc(lat*scale) / scale, math.Trunc(lon*scale) / scale
+}
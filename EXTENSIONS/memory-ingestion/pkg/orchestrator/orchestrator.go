@@ -2,56 +2,287 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/clock"
+	"github.com/kamir/memory-connector/pkg/contentfilter"
+	"github.com/kamir/memory-connector/pkg/events"
 	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/simulation"
 	"github.com/kamir/memory-connector/pkg/state"
 	"github.com/kamir/memory-connector/pkg/transformer"
+	"github.com/kamir/memory-connector/pkg/utils"
 	"go.uber.org/zap"
 )
 
+// MemorySourceFactory creates the Memory API client for a connector that sets its own memory_api settings
+type MemorySourceFactory func(config *models.ConnectorConfig) client.MemorySource
+
+// ProgressFunc receives progress updates of a sync. Calls are serialized and must not block.
+type ProgressFunc func(progress models.SyncProgress)
+
+// connectorSource is a Memory API client created for one connector's memory_api settings
+type connectorSource struct {
+	settings models.MemoryAPIOverride
+	source   client.MemorySource
+}
+
+// zonedSource reads the created_at values without an offset of the memories it fetches in a
+// connector's time zone
+type zonedSource struct {
+	client.MemorySource
+	location *time.Location
+}
+
+// GetMemories fetches memories for a context and sets their time zone
+func (s zonedSource) GetMemories(ctx context.Context, ctxID string, limit int, rangeParam string) (*models.MemoryList, error) {
+	memoryList, err := s.MemorySource.GetMemories(ctx, ctxID, limit, rangeParam)
+	if memoryList != nil {
+		for i := range memoryList.Memories {
+			memoryList.Memories[i].TimeZone = s.location
+		}
+	}
+	return memoryList, err
+}
+
 // Orchestrator coordinates the memory ingestion process
 type Orchestrator struct {
-	memoryClient  *client.MemoryClient
-	lightragClient *client.LightRAGClient
-	transformer   *transformer.Transformer
-	stateManager  state.StateManager
-	logger        *zap.Logger
+	memoryClient     client.MemorySource
+	sourceFactory    MemorySourceFactory
+	sources          map[string]connectorSource // connector ID -> client for connector-specific memory_api settings
+	sourceMu         sync.Mutex
+	lightragClient   client.LightRAGAPI
+	transformer      *transformer.Transformer
+	transformers     map[string]*transformer.Transformer // strategy name -> transformer
+	transformMu      sync.Mutex
+	stateManager     state.StateManager
+	events           events.Publisher            // optional, receives per-memory ingestion events
+	insertLimits     map[string]int              // connector ID -> adaptive insert concurrency learned by its last sync
+	queryLimits      map[string]int              // connector ID -> auto-tuned query limit learned by its last fetch
+	tuneMu           sync.Mutex                  // guards insertLimits and queryLimits
+	pipeline         *pipelineGate               // optional, holds inserts back while LightRAG's queue is full
+	pseudonymizer    transformer.Pseudonymizer   // optional, anonymizes connectors with transform.anonymize
+	aliases          transformer.AliasResolver   // optional, names canonical entities of aliases in documents
+	features         FeatureOverrides            // optional, overrides the feature flags of connectors at runtime
+	translator       client.Translator           // optional, translates connectors with transform.target_language
+	contentFilter    contentfilter.Filter        // optional, checks connectors with transform.content_filter
+	enrichers        map[string]*enrichmentStage // connector ID -> enrichers of its transform.enrichers
+	enrichMu         sync.Mutex
+	geocoder         client.Geocoder   // optional, names the places of connectors with transform.trips
+	places           map[string]string // coordinates -> geocoded place name
+	placeMu          sync.Mutex
+	timeZoneResolver client.TimeZoneResolver   // optional, looks up the time zones of connectors with transform.local_time
+	timeZones        map[string]*time.Location // coordinates -> time zone, nil if there's none
+	timeZoneMu       sync.Mutex
+	exports          map[string]connectorExport // connector ID -> sink of its export settings
+	exportMu         sync.Mutex
+	clock            clock.Clock             // dates reports, sync state, events, and exports
+	deadLetters      models.DeadLetterPolicy // schedules the retries of failed memories
+	logger           *zap.Logger
 }
 
 // NewOrchestrator creates a new orchestrator
 func NewOrchestrator(
-	memoryClient *client.MemoryClient,
-	lightragClient *client.LightRAGClient,
-	transformer *transformer.Transformer,
+	memoryClient client.MemorySource,
+	lightragClient client.LightRAGAPI,
+	defaultTransformer *transformer.Transformer,
 	stateManager state.StateManager,
 	logger *zap.Logger,
 ) *Orchestrator {
-	return &Orchestrator{
+	o := &Orchestrator{
 		memoryClient:   memoryClient,
 		lightragClient: lightragClient,
-		transformer:    transformer,
+		transformer:    defaultTransformer,
+		transformers:   make(map[string]*transformer.Transformer),
+		sources:        make(map[string]connectorSource),
+		insertLimits:   make(map[string]int),
+		queryLimits:    make(map[string]int),
+		enrichers:      make(map[string]*enrichmentStage),
+		places:         make(map[string]string),
+		timeZones:      make(map[string]*time.Location),
+		exports:        make(map[string]connectorExport),
 		stateManager:   stateManager,
+		clock:          clock.System,
+		deadLetters:    models.DefaultDeadLetterPolicy,
 		logger:         logger,
 	}
+	if defaultTransformer != nil {
+		o.transformers[defaultTransformer.StrategyName()] = defaultTransformer
+	}
+
+	return o
+}
+
+// SetClock sets the clock dating reports, sync state, events, exports, and snapshots, and deciding
+// which memories simulated connectors have and which days digests hold back. A fixed clock makes
+// repeated syncs of the same memories produce identical output. Latencies and rate limits keep
+// measuring real time.
+func (o *Orchestrator) SetClock(c clock.Clock) {
+	o.clock = c
+}
+
+// SetMemorySourceFactory enables per-connector Memory API settings. Connectors without
+// memory_api settings keep using the default memory client.
+func (o *Orchestrator) SetMemorySourceFactory(factory MemorySourceFactory) {
+	o.sourceMu.Lock()
+	defer o.sourceMu.Unlock()
+
+	o.sourceFactory = factory
+	o.sources = make(map[string]connectorSource)
+}
+
+// RotateMemoryAPICredentials applies rotated credentials of the global memory_api section. The
+// default client switches to them in place, so running syncs continue with them; the clients of
+// connector-specific settings are recreated by factory for their next sync.
+func (o *Orchestrator) RotateMemoryAPICredentials(apiKey string, oauth2 *client.OAuth2Config, factory MemorySourceFactory) {
+	if memoryClient, ok := o.memoryClient.(*client.MemoryClient); ok {
+		memoryClient.SetCredentials(apiKey, oauth2)
+	}
+	o.SetMemorySourceFactory(factory)
+}
+
+// SetEventPublisher makes the orchestrator publish an event for every ingested, failed, or blocked memory
+func (o *Orchestrator) SetEventPublisher(publisher events.Publisher) {
+	o.events = publisher
+}
+
+// SetPseudonymizer sets the pseudonymizer of connectors with transform.anonymize. Without it,
+// their syncs fail rather than ingest real names.
+func (o *Orchestrator) SetPseudonymizer(pseudonymizer transformer.Pseudonymizer) {
+	o.pseudonymizer = pseudonymizer
+}
+
+// SetAliasResolver makes documents name the canonical entities of the aliases their transcripts
+// use, as registered for the connector's context
+func (o *Orchestrator) SetAliasResolver(aliases transformer.AliasResolver) {
+	o.aliases = aliases
+}
+
+// publish publishes an event if an event publisher is set
+func (o *Orchestrator) publish(eventType, connectorID string, data map[string]interface{}) {
+	if o.events != nil {
+		o.events.Publish(events.Event{Type: eventType, ConnectorID: connectorID, Data: data, Timestamp: o.clock.Now().UTC()})
+	}
+}
+
+// memorySourceFor returns the Memory API client for a connector, or the generator of a simulated
+// connector. The memories it fetches read created_at values without an offset in the connector's
+// ingestion.timezone.
+func (o *Orchestrator) memorySourceFor(config *models.ConnectorConfig) client.MemorySource {
+	source := o.upstreamSourceFor(config)
+	if location := config.Ingestion.Location(); location != nil {
+		return zonedSource{MemorySource: source, location: location}
+	}
+	return source
+}
+
+// upstreamSourceFor returns the Memory API client or generator of a connector. Clients for
+// connector-specific settings are reused until the settings change, so OAuth2 tokens are cached
+// across syncs.
+func (o *Orchestrator) upstreamSourceFor(config *models.ConnectorConfig) client.MemorySource {
+	if config.Simulation.Enabled {
+		// Generators hold no state worth reusing
+		return simulation.New(config.Simulation, o.clock)
+	}
+	if config.MemoryAPI == nil {
+		return o.memoryClient
+	}
+
+	o.sourceMu.Lock()
+	defer o.sourceMu.Unlock()
+
+	if o.sourceFactory == nil {
+		return o.memoryClient
+	}
+
+	if cached, ok := o.sources[config.ID]; ok && reflect.DeepEqual(cached.settings, *config.MemoryAPI) {
+		return cached.source
+	}
+
+	source := o.sourceFactory(config)
+	o.sources[config.ID] = connectorSource{settings: *config.MemoryAPI, source: source}
+
+	o.logger.Info("Created connector-specific Memory API client", zap.String("connector_id", config.ID))
+
+	return source
+}
+
+// transformerFor returns the transformer for a connector's strategy, creating it on first use.
+// The default transformer is used if the connector doesn't set a strategy.
+func (o *Orchestrator) transformerFor(config *models.ConnectorConfig) (*transformer.Transformer, error) {
+	strategy := config.Transform.Strategy
+	if strategy == "" {
+		if o.transformer == nil {
+			return nil, fmt.Errorf("connector %s has no transform strategy", config.ID)
+		}
+		return o.transformer, nil
+	}
+
+	o.transformMu.Lock()
+	defer o.transformMu.Unlock()
+
+	if t, ok := o.transformers[strategy]; ok {
+		return t, nil
+	}
+
+	t, err := transformer.NewTransformer(strategy, o.logger)
+	if err != nil {
+		return nil, err
+	}
+	o.transformers[strategy] = t
+
+	return t, nil
+}
+
+// TransformerMetrics returns the metrics of every transformation strategy used so far, by strategy name
+func (o *Orchestrator) TransformerMetrics() []transformer.StrategyMetrics {
+	o.transformMu.Lock()
+	defer o.transformMu.Unlock()
+
+	metrics := make([]transformer.StrategyMetrics, 0, len(o.transformers))
+	for _, t := range o.transformers {
+		metrics = append(metrics, t.Metrics())
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Strategy < metrics[j].Strategy
+	})
+	return metrics
 }
 
 // SyncConnector performs a full sync for a connector
 func (o *Orchestrator) SyncConnector(ctx context.Context, config *models.ConnectorConfig) (*models.SyncReport, error) {
+	return o.SyncConnectorWithProgress(ctx, config, nil)
+}
+
+// SyncConnectorWithProgress performs a full sync for a connector, reporting progress to progress (may be nil)
+func (o *Orchestrator) SyncConnectorWithProgress(
+	ctx context.Context,
+	config *models.ConnectorConfig,
+	progress ProgressFunc,
+) (*models.SyncReport, error) {
+	overrides := o.featureOverridesFor(config.ID)
+	features := config.EnabledFeatures(overrides)
+	config = config.WithFeatures(overrides)
+
 	o.logger.Info("Starting sync",
 		zap.String("connector_id", config.ID),
 		zap.String("context_id", config.ContextID),
+		zap.Strings("features", features),
 	)
 
 	report := &models.SyncReport{
 		ConnectorID: config.ID,
 		ContextID:   config.ContextID,
-		StartTime:   time.Now(),
+		StartTime:   o.clock.Now(),
 		Status:      "success",
 		Metrics:     models.SyncMetrics{},
+		Features:    features,
 	}
 
 	// Get current state
@@ -65,22 +296,51 @@ func (o *Orchestrator) SyncConnector(ctx context.Context, config *models.Connect
 		syncState.ContextID = config.ContextID
 	}
 
+	// After downtime, widen the query window to cover the runs that were missed
+	baseLimit := o.queryLimitFor(config)
+	queryRange, queryLimit, catchUp := catchUpWindow(config, baseLimit, syncState.LastSyncTime, report.StartTime)
+	if catchUp != nil {
+		report.CatchUp = catchUp
+		o.logger.Info("Catching up after downtime",
+			zap.String("connector_id", config.ID),
+			zap.Time("last_sync_time", catchUp.LastSyncTime),
+			zap.String("query_range", catchUp.QueryRange),
+			zap.Int("query_limit", catchUp.QueryLimit),
+			zap.Bool("truncated", catchUp.Truncated),
+		)
+	}
+
 	// Fetch memories from Memory API
 	fetchStart := time.Now()
-	memoryList, err := o.memoryClient.GetMemories(
+	memoryList, err := o.memorySourceFor(config).GetMemories(
 		ctx,
 		config.ContextID,
-		config.Ingestion.QueryLimit,
-		config.Ingestion.QueryRange,
+		queryLimit,
+		queryRange,
 	)
+	if err != nil && ctx.Err() != nil {
+		// Stopped (paused or shut down) before anything was fetched, the next run starts over
+		report.Status = "interrupted"
+		report.ErrorMessage = interruptedMessage(ctx, "before fetching memories")
+		report.EndTime = o.clock.Now()
+		report.Duration = report.EndTime.Sub(report.StartTime)
+		o.recordReport(ctx, report)
+		return report, nil
+	}
+	fetchDuration := time.Since(fetchStart)
+	if config.Ingestion.AutoTune.Enabled && catchUp == nil {
+		// Catch-up fetches are oversized on purpose and say nothing about the right batch size
+		report.Metrics.QueryLimit = queryLimit
+		o.tuneQueryLimit(config, queryLimit, memoryList, fetchDuration, err)
+	}
 	if err != nil {
 		report.Status = "failed"
 		report.ErrorMessage = fmt.Sprintf("Failed to fetch memories: %v", err)
-		report.EndTime = time.Now()
+		report.EndTime = o.clock.Now()
 		report.Duration = report.EndTime.Sub(report.StartTime)
+		o.recordReport(ctx, report)
 		return report, fmt.Errorf("failed to fetch memories: %w", err)
 	}
-	fetchDuration := time.Since(fetchStart)
 
 	report.TotalFetched = len(memoryList.Memories)
 	o.logger.Info("Fetched memories",
@@ -92,25 +352,48 @@ func (o *Orchestrator) SyncConnector(ctx context.Context, config *models.Connect
 		report.Metrics.AvgFetchTimeMs = fetchDuration.Milliseconds() / int64(report.TotalFetched)
 	}
 
-	// Filter out already-processed memories
+	// Filter out already-processed memories, and fail those whose created_at can't be read, as
+	// the time of a memory is part of its document
 	newMemories := make([]models.Memory, 0)
 	for _, memory := range memoryList.Memories {
-		if !syncState.IsProcessed(memory.ID) {
-			newMemories = append(newMemories, memory)
-		} else {
+		if syncState.IsProcessed(memory.ID) {
 			report.TotalSkipped++
 			report.MemoriesSkipped = append(report.MemoriesSkipped, memory.ID)
+		} else if _, err := memory.ParseCreatedAt(); err != nil {
+			o.recordMemory(config, syncState, report, &memory, models.IngestedDocument{}, fmt.Errorf("created_at: %w", err))
+		} else {
+			newMemories = append(newMemories, memory)
 		}
 	}
+	newMemories, merged := o.suppressDuplicates(config, memoryList.Memories, newMemories, syncState, report)
 
 	o.logger.Info("Filtered memories",
 		zap.Int("new", len(newMemories)),
 		zap.Int("skipped", report.TotalSkipped),
+		zap.Int("duplicates", report.TotalDuplicates),
 	)
 
+	if progress == nil {
+		progress = func(models.SyncProgress) {}
+	}
+	progress(syncProgress(report, len(newMemories), ""))
+
 	// Process new memories with concurrency control (as per user's answer: configurable)
 	if len(newMemories) > 0 {
-		err = o.processMemoriesConcurrent(ctx, newMemories, config, syncState, report)
+		var backpressure *syncBackpressure
+		if !config.Export.ExportOnly() {
+			// Export-only connectors don't feed LightRAG's queue
+			backpressure = o.pipeline.newSync()
+		}
+		links := linkMemories(
+			o.detectEpisodes(config, memoryList.Memories),
+			merged,
+			o.detectTrips(ctx, config, newMemories, syncState),
+			o.labelPlaces(config, newMemories, syncState),
+		)
+		err = o.processMemoriesConcurrent(ctx, newMemories, config, syncState, report, backpressure, progress, links)
+		waited, busyDeferred := backpressure.result()
+		report.Metrics.BackpressureWaitMs = waited.Milliseconds()
 		if err != nil && report.TotalProcessed == 0 {
 			// Complete failure
 			report.Status = "failed"
@@ -119,21 +402,57 @@ func (o *Orchestrator) SyncConnector(ctx context.Context, config *models.Connect
 			// Partial success (as per user's answer: "Process what we got and track what was lost")
 			report.Status = "partial"
 		}
+
+		switch {
+		case errors.Is(err, errFailureThreshold):
+			report.Status = "failed"
+			report.Aborted = true
+			report.ErrorMessage = fmt.Sprintf("Sync aborted, %v; %d memories deferred to the next run", err, report.TotalDeferred)
+		case report.TotalDeferred > 0 && ctx.Err() != nil:
+			report.Status = "interrupted"
+			report.ErrorMessage = interruptedMessage(ctx, fmt.Sprintf("%d memories deferred to the next run", report.TotalDeferred))
+		case busyDeferred > 0 && busyDeferred == report.TotalDeferred:
+			report.Status = "partial"
+			report.ErrorMessage = fmt.Sprintf("LightRAG pipeline busy, %d memories deferred to the next run", report.TotalDeferred)
+		case report.TotalDeferred > 0:
+			report.Status = "quota_exhausted"
+			report.ErrorMessage = fmt.Sprintf("Daily quota exhausted, %d memories deferred to the next day", report.TotalDeferred)
+		}
 	}
 
+	if report.Status == "success" && report.TotalFailed > 0 {
+		// Memories with unreadable timestamps failed before processing
+		report.Status = "partial"
+	}
+
+	o.publish(events.TypeBatchCompleted, config.ID, map[string]interface{}{
+		"query_range":      queryRange,
+		"query_limit":      queryLimit,
+		"fetch_ms":         fetchDuration.Milliseconds(),
+		"total_fetched":    report.TotalFetched,
+		"total_skipped":    report.TotalSkipped,
+		"total_processed":  report.TotalProcessed,
+		"total_failed":     report.TotalFailed,
+		"total_deferred":   report.TotalDeferred,
+		"total_duplicates": report.TotalDuplicates,
+		"total_blocked":    report.TotalBlocked,
+	})
+
+	report.EndTime = o.clock.Now()
+	report.Duration = report.EndTime.Sub(report.StartTime)
+
 	// Update state
-	syncState.LastSyncTime = time.Now()
+	syncState.LastSyncTime = o.clock.Now()
 	syncState.LastSyncReport = report
 	syncState.TotalSyncCount++
-	syncState.UpdatedAt = time.Now()
+	syncState.UpdatedAt = o.clock.Now()
 
-	if err := o.stateManager.SaveState(ctx, syncState); err != nil {
+	// Checkpoint even if the sync was cancelled, so the next run neither repeats nor skips memories
+	if err := o.stateManager.SaveState(context.WithoutCancel(ctx), syncState); err != nil {
 		o.logger.Error("Failed to save state", zap.Error(err))
 		// Don't fail the entire sync just because we couldn't save state
 	}
-
-	report.EndTime = time.Now()
-	report.Duration = report.EndTime.Sub(report.StartTime)
+	o.recordReport(ctx, report)
 
 	o.logger.Info("Sync completed",
 		zap.String("connector_id", config.ID),
@@ -146,6 +465,24 @@ func (o *Orchestrator) SyncConnector(ctx context.Context, config *models.Connect
 	return report, nil
 }
 
+// interruptedMessage describes a sync stopped by ctx, naming the reason if one was given (e.g. a pause)
+func interruptedMessage(ctx context.Context, detail string) string {
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, context.Canceled) {
+		return fmt.Sprintf("Sync interrupted (%v), %s", cause, detail)
+	}
+	return fmt.Sprintf("Sync interrupted, %s", detail)
+}
+
+// recordReport adds a finished report to the connector's report history
+func (o *Orchestrator) recordReport(ctx context.Context, report *models.SyncReport) {
+	if err := o.stateManager.SaveReport(context.WithoutCancel(ctx), report); err != nil {
+		o.logger.Error("Failed to save sync report",
+			zap.String("connector_id", report.ConnectorID),
+			zap.Error(err),
+		)
+	}
+}
+
 // processMemoriesConcurrent processes memories with concurrency control
 func (o *Orchestrator) processMemoriesConcurrent(
 	ctx context.Context,
@@ -153,91 +490,397 @@ func (o *Orchestrator) processMemoriesConcurrent(
 	config *models.ConnectorConfig,
 	syncState *models.SyncState,
 	report *models.SyncReport,
+	backpressure *syncBackpressure,
+	progress ProgressFunc,
+	links map[string]*memoryLinks,
 ) error {
-	// Create semaphore for concurrency control (as per user's answer: configurable)
-	semaphore := make(chan struct{}, config.Ingestion.MaxConcurrency)
-	var wg sync.WaitGroup
-	var mu sync.Mutex
+	trans, err := o.transformerFor(config)
+	if err != nil {
+		return err
+	}
+
+	// Limit concurrency (as per user's answer: configurable), adapted to LightRAG's latency if enabled
+	limiter := o.insertLimiterFor(config)
+
+	transformConfig, err := o.transformConfigFor(config, memories, syncState)
+	if err != nil {
+		return err
+	}
+	stages, err := o.memoryStagesFor(config)
+	if err != nil {
+		return err
+	}
+
+	// Memories already being inserted finish even if ctx is cancelled (e.g. on shutdown),
+	// only memories that haven't started are deferred to the next run
+	processCtx := context.WithoutCancel(ctx)
+
+	// Memories beyond the connector's daily quota are deferred to the next day
+	budget := newQuotaBudget(config.Quota, syncState, o.clock.Now())
+
+	if config.Transform.Mode == models.TransformModeDailyDigest {
+		o.processDigests(ctx, processCtx, memories, config, syncState, report, backpressure, progress,
+			trans, transformConfig, stages, budget, limiter)
+		o.saveInsertLimit(config, report, limiter)
+		return nil
+	}
+
+	// Enrichers that look up memories in a batch do so before the memories are processed
+	stages.prefetch(ctx, memories)
+
+	// A sync whose memories mostly fail stops, deferring the memories it hasn't started
+	pipelineCtx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
+	threshold := config.Ingestion.FailureThreshold
+	var processed, failed int
+
+	pipeline := &syncPipeline{
+		o:               o,
+		ctx:             pipelineCtx,
+		processCtx:      processCtx,
+		config:          config,
+		trans:           trans,
+		transformConfig: transformConfig,
+		stages:          stages,
+		links:           links,
+		budget:          budget,
+		limiter:         limiter,
+		backpressure:    backpressure,
+	}
+	pipeline.run(memories, func(item *pipelineItem) {
+		if errors.Is(item.err, errQuotaExhausted) || errors.Is(item.err, errDeferred) {
+			report.TotalDeferred++
+			return
+		}
+		o.recordMemory(config, syncState, report, item.memory, item.document, item.err)
+		progress(syncProgress(report, len(memories), item.memory.ID))
+
+		var blocked *blockedError
+		processed++
+		if item.err != nil && !errors.As(item.err, &blocked) {
+			failed++
+		}
+		if threshold.Exceeded(failed, processed) && pipelineCtx.Err() == nil {
+			abort(fmt.Errorf("%w: %d of %d memories failed, more than %d%%", errFailureThreshold, failed, processed, threshold.Percent))
+			o.logger.Error("Aborting sync, too many memories failed",
+				zap.String("connector_id", config.ID),
+				zap.Int("failed", failed),
+				zap.Int("processed", processed),
+				zap.Int("threshold_percent", threshold.Percent),
+			)
+		}
+	}, &report.Metrics)
+
+	o.saveInsertLimit(config, report, limiter)
+
+	if cause := context.Cause(pipelineCtx); errors.Is(cause, errFailureThreshold) {
+		return cause
+	}
+	return nil
+}
 
+// transformConfigFor returns the transform settings of a connector for documents of memories,
+// assigning their short citations if the connector uses them
+func (o *Orchestrator) transformConfigFor(
+	config *models.ConnectorConfig,
+	memories []models.Memory,
+	syncState *models.SyncState,
+) (transformer.TransformConfig, error) {
 	transformConfig := transformer.TransformConfig{
 		IncludeMetadata: config.Transform.IncludeMetadata,
 		EnrichLocation:  config.Transform.EnrichLocation,
 		ContextID:       config.ContextID,
+		Timestamps:      config.Transform.Timestamps,
+		S2Level:         config.Transform.S2Level,
+		Citations:       o.assignCitations(config, memories, syncState),
+		Aliases:         o.aliases,
+		RawFields:       config.Transform.RawFields,
+	}
+	if _, s2Level, ok := models.LocationPrecisionLimits(config.Transform.LocationPrecision); ok {
+		transformConfig.S2Level = min(transformConfig.S2Level, s2Level)
 	}
+	if config.Transform.Anonymize {
+		if o.pseudonymizer == nil {
+			return transformConfig, fmt.Errorf("connector %s anonymizes but no anonymization key is configured", config.ID)
+		}
+		transformConfig.Pseudonymizer = o.pseudonymizer
+	}
+	return transformConfig, nil
+}
 
-	for i := range memories {
-		wg.Add(1)
-		go func(memory models.Memory) {
-			defer wg.Done()
-
-			// Acquire semaphore
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			// Process individual memory
-			err := o.processMemory(ctx, &memory, transformConfig)
-
-			// Update report (thread-safe)
-			mu.Lock()
-			defer mu.Unlock()
-
-			if err != nil {
-				report.TotalFailed++
-				failedItem := models.FailedItem{
-					MemoryID:     memory.ID,
-					ErrorMessage: err.Error(),
-					FailedAt:     time.Now(),
-					Retryable:    true,
-					RetryCount:   0,
-				}
-				report.MemoriesFailed = append(report.MemoriesFailed, failedItem)
-				syncState.AddFailedItem(failedItem)
-
-				o.logger.Warn("Failed to process memory",
-					zap.String("memory_id", memory.ID),
-					zap.Error(err),
-				)
-			} else {
-				report.TotalProcessed++
-				report.MemoriesIngested = append(report.MemoriesIngested, memory.ID)
-				syncState.MarkProcessed(memory.ID)
-
-				o.logger.Debug("Processed memory", zap.String("memory_id", memory.ID))
-			}
-		}(memories[i])
-	}
-
-	wg.Wait()
-	return nil
+// saveInsertLimit keeps the insert concurrency an adaptive sync ended with for the connector's next sync
+func (o *Orchestrator) saveInsertLimit(config *models.ConnectorConfig, report *models.SyncReport, limiter *insertLimiter) {
+	if !config.Ingestion.AdaptsConcurrency() {
+		return
+	}
+
+	report.Metrics.Concurrency = limiter.size()
+	o.tuneMu.Lock()
+	o.insertLimits[config.ID] = report.Metrics.Concurrency
+	o.tuneMu.Unlock()
+
+	o.logger.Info("Adapted insert concurrency",
+		zap.String("connector_id", config.ID),
+		zap.Int("concurrency", report.Metrics.Concurrency),
+	)
+}
+
+// recordMemory records the outcome of a memory, as fetched from the Memory API, in the report and
+// sync state: ingested memories with the document they were ingested in. Failures a retry may fix
+// go to the dead letter queue, scheduled for a retry; other outcomes take a memory out of it.
+// Memories blocked by a content policy are marked processed. Callers serialize calls of a sync.
+func (o *Orchestrator) recordMemory(
+	config *models.ConnectorConfig,
+	syncState *models.SyncState,
+	report *models.SyncReport,
+	memory *models.Memory,
+	document models.IngestedDocument,
+	err error,
+) {
+	memoryID := memory.ID
+	var blocked *blockedError
+	if errors.As(err, &blocked) {
+		// Blocked memories aren't ingested by later runs either
+		report.TotalBlocked++
+		report.MemoriesBlocked = append(report.MemoriesBlocked, models.BlockedItem{MemoryID: memoryID, Policies: blocked.policies})
+		syncState.MarkProcessed(memoryID)
+		syncState.RemoveFailedItem(memoryID)
+
+		o.logger.Info("Blocked memory by content policy",
+			zap.String("memory_id", memoryID),
+			zap.Strings("policies", blocked.policies),
+		)
+		o.publish(events.TypeMemoryBlocked, config.ID, map[string]interface{}{
+			"memory_id": memoryID,
+			"policies":  blocked.policies,
+		})
+	} else if err != nil {
+		category, retryable := classifyFailure(err)
+		report.TotalFailed++
+		failedItem := models.FailedItem{
+			MemoryID:     memoryID,
+			ErrorMessage: err.Error(),
+			Category:     category,
+			FailedAt:     o.clock.Now(),
+			Retryable:    retryable,
+			RetryCount:   0,
+		}
+		report.MemoriesFailed = append(report.MemoriesFailed, failedItem)
+		if report.FailuresByCategory == nil {
+			report.FailuresByCategory = make(map[string]int)
+		}
+		report.FailuresByCategory[category]++
+
+		// Only failures a retry may fix go to the dead letter queue
+		if retryable {
+			syncState.AddFailedItem(failedItem, o.deadLetters)
+		} else {
+			syncState.RemoveFailedItem(memoryID)
+		}
+
+		o.logger.Warn("Failed to process memory",
+			zap.String("memory_id", memoryID),
+			zap.String("category", category),
+			zap.Bool("retryable", retryable),
+			zap.Error(err),
+		)
+		o.publish(events.TypeMemoryFailed, config.ID, map[string]interface{}{
+			"memory_id": memoryID,
+			"error":     err.Error(),
+			"category":  category,
+			"retryable": retryable,
+		})
+	} else {
+		report.TotalProcessed++
+		report.MemoriesIngested = append(report.MemoriesIngested, memoryID)
+		syncState.MarkProcessed(memoryID)
+		syncState.RecordVersion(memory, document, o.clock.Now())
+		syncState.RemoveFailedItem(memoryID)
+
+		o.logger.Debug("Processed memory", zap.String("memory_id", memoryID))
+		o.publish(events.TypeMemoryIngested, config.ID, map[string]interface{}{
+			"memory_id": memoryID,
+			"uri":       utils.MemoryURI(memoryID),
+			"receipt":   syncState.Receipt(memoryID),
+		})
+	}
+}
+
+// insertLimiterFor returns the concurrency limiter for a sync of a connector. Adaptive syncs continue
+// from the limit the connector's previous sync arrived at, starting at max_concurrency.
+func (o *Orchestrator) insertLimiterFor(config *models.ConnectorConfig) *insertLimiter {
+	if !config.Ingestion.AdaptsConcurrency() {
+		return newInsertLimiter(config.Ingestion.MaxConcurrency, 1, 1, false)
+	}
+	minLimit, maxLimit := config.Ingestion.ConcurrencyBounds()
+
+	o.tuneMu.Lock()
+	defer o.tuneMu.Unlock()
+
+	limit, ok := o.insertLimits[config.ID]
+	if !ok {
+		limit = config.Ingestion.MaxConcurrency
+	}
+	return newInsertLimiter(limit, minLimit, maxLimit, true)
+}
+
+// syncProgress takes a progress snapshot of a sync report
+func syncProgress(report *models.SyncReport, totalNew int, memoryID string) models.SyncProgress {
+	return models.SyncProgress{
+		ConnectorID:  report.ConnectorID,
+		TotalFetched: report.TotalFetched,
+		TotalNew:     totalNew,
+		Processed:    report.TotalProcessed,
+		Skipped:      report.TotalSkipped,
+		Failed:       report.TotalFailed,
+		MemoryID:     memoryID,
+	}
 }
 
-// processMemory processes a single memory
+// processMemory processes a single memory and returns the document it was ingested in
 func (o *Orchestrator) processMemory(
 	ctx context.Context,
+	config *models.ConnectorConfig,
+	trans *transformer.Transformer,
 	memory *models.Memory,
 	transformConfig transformer.TransformConfig,
-) error {
-	// Transform memory to LightRAG document format
+	stages memoryStages,
+	links *memoryLinks,
+	budget *quotaBudget,
+	limiter *insertLimiter,
+) (models.IngestedDocument, error) {
+	if budget.spent() {
+		return models.IngestedDocument{}, errQuotaExhausted
+	}
+
 	transformStart := time.Now()
-	text, metadata, err := o.transformer.Transform(memory, transformConfig)
+	text, metadata, err := o.renderMemory(ctx, trans, memory, transformConfig, stages, links)
 	if err != nil {
-		return fmt.Errorf("transformation failed: %w", err)
+		return models.IngestedDocument{}, err
 	}
 	transformDuration := time.Since(transformStart)
 
-	// Insert document into LightRAG
 	insertStart := time.Now()
-	_, err = o.lightragClient.InsertDocument(ctx, text, metadata)
+	document, err := o.insertMemory(ctx, config, text, metadata, budget, limiter)
 	if err != nil {
-		return fmt.Errorf("insertion failed: %w", err)
+		return document, err
 	}
 	insertDuration := time.Since(insertStart)
 
 	o.logger.Debug("Memory processed",
 		zap.String("memory_id", memory.ID),
+		zap.String("doc_id", document.DocID),
 		zap.Duration("transform_time", transformDuration),
 		zap.Duration("insert_time", insertDuration),
 	)
 
-	return nil
+	return document, nil
+}
+
+// renderMemory builds the LightRAG document of a memory: its text and metadata, after the
+// connector's content, language, and enrichment stages
+func (o *Orchestrator) renderMemory(
+	ctx context.Context,
+	trans *transformer.Transformer,
+	memory *models.Memory,
+	transformConfig transformer.TransformConfig,
+	stages memoryStages,
+	links *memoryLinks,
+) (string, map[string]string, error) {
+	staged, text, metadata, err := o.transformMemory(ctx, trans, memory, transformConfig, stages)
+	if err != nil {
+		return "", nil, err
+	}
+	return o.enrichDocument(ctx, trans, staged, text, metadata, stages, links)
+}
+
+// transformMemory runs a memory through the connector's content and language stages and transforms
+// it, returning the memory as the stages left it with its document
+func (o *Orchestrator) transformMemory(
+	ctx context.Context,
+	trans *transformer.Transformer,
+	memory *models.Memory,
+	transformConfig transformer.TransformConfig,
+	stages memoryStages,
+) (*models.Memory, string, map[string]string, error) {
+	// Filter the transcript's content, detect its language and translate it to the connector's target language
+	memory, stageMetadata, err := stages.apply(ctx, memory)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// Transform memory to LightRAG document format
+	text, metadata, err := trans.Transform(memory, transformConfig)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return memory, text, mergeMetadata(metadata, stageMetadata), nil
+}
+
+// enrichDocument adds the connector's enrichments and the memory's cross-references to its document
+func (o *Orchestrator) enrichDocument(
+	ctx context.Context,
+	trans *transformer.Transformer,
+	memory *models.Memory,
+	text string,
+	metadata map[string]string,
+	stages memoryStages,
+	links *memoryLinks,
+) (string, map[string]string, error) {
+	// Attach the domain metadata of the connector's enrichers, mentioned in rich documents
+	metadata, mentions, err := stages.enrich(ctx, memory, metadata)
+	if err != nil {
+		return "", nil, err
+	}
+	if trans.StrategyName() == "rich" {
+		for _, mention := range mentions {
+			text = transformer.AddRichContext(text, "["+mention+"]")
+		}
+	}
+
+	// Cross-reference the other memories of the memory's episode, its merged duplicates, its trip, and its place
+	if links != nil {
+		reference, linkMetadata := links.reference(memory.ID)
+		text += reference
+		metadata = mergeMetadata(metadata, linkMetadata)
+	}
+
+	// Name the document after its enrichments, e.g. its place
+	text, metadata, err = stages.entitle(memory, text, metadata)
+	if err != nil {
+		return "", nil, err
+	}
+
+	metadata = stages.profile(memory, metadata)
+	if err := stages.checkMetadata(metadata); err != nil {
+		return "", nil, err
+	}
+	return text, metadata, nil
+}
+
+// insertMemory inserts a memory's document within the connector's quota, reporting the insert's
+// latency to the concurrency limiter
+func (o *Orchestrator) insertMemory(
+	ctx context.Context,
+	config *models.ConnectorConfig,
+	text string,
+	metadata map[string]string,
+	budget *quotaBudget,
+	limiter *insertLimiter,
+) (models.IngestedDocument, error) {
+	// The LLM work LightRAG does for a document grows with its text
+	tokens := models.EstimateTokens(text)
+	if !budget.reserve(tokens) {
+		return models.IngestedDocument{}, errQuotaExhausted
+	}
+
+	// Insert document into LightRAG
+	insertStart := time.Now()
+	document, err := o.insertDocument(ctx, config, text, metadata)
+	limiter.observe(time.Since(insertStart), err)
+	if err != nil {
+		budget.release(tokens)
+		return document, fmt.Errorf("insertion failed: %w", err)
+	}
+	return document, nil
 }
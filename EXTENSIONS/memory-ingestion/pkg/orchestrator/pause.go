@@ -0,0 +1,28 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+)
+
+// PausedAt returns when an operator paused a connector, nil if it isn't paused
+func (o *Orchestrator) PausedAt(ctx context.Context, connectorID string) (*time.Time, error) {
+	syncState, err := o.stateManager.GetState(ctx, connectorID)
+	if err != nil {
+		return nil, err
+	}
+	return syncState.PausedAt, nil
+}
+
+// SetPausedAt records that a connector was paused at pausedAt, or resumed if pausedAt is nil.
+// Must not be called while the connector syncs, since the sync saves its own copy of the state.
+func (o *Orchestrator) SetPausedAt(ctx context.Context, connectorID string, pausedAt *time.Time) error {
+	syncState, err := o.stateManager.GetState(ctx, connectorID)
+	if err != nil {
+		return err
+	}
+
+	syncState.PausedAt = pausedAt
+	syncState.UpdatedAt = o.clock.Now()
+	return o.stateManager.SaveState(ctx, syncState)
+}
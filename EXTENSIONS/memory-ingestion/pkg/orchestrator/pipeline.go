@@ -0,0 +1,174 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/transformer"
+)
+
+// errDeferred marks memories a stopped sync (or one LightRAG's queue held back for too long) left
+// for the next run
+var errDeferred = errors.New("deferred to the next run")
+
+// errFailureThreshold is wrapped by the cause of a sync aborted because more of its memories failed
+// than ingestion.failure_threshold allows
+var errFailureThreshold = errors.New("failure threshold exceeded")
+
+// pipelineItem is a memory passing through the stages of a sync
+type pipelineItem struct {
+	memory   *models.Memory // as fetched
+	staged   *models.Memory // as the content and language stages left it
+	text     string
+	metadata map[string]string
+	document models.IngestedDocument // the document the memory was inserted as
+	err      error                   // failure of a stage; the later stages pass the item on untouched
+
+	transformTime, enrichTime, insertTime time.Duration
+}
+
+// syncPipeline runs the fetched memories of a sync through its stages: transform workers feed
+// enrich workers, which feed the inserts, over bounded queues. A stage that falls behind fills its
+// queue, which blocks the stage before it, so no stage runs ahead of the inserts by more than a
+// queue. Fetching precedes the pipeline: the Memory API serves the batch in one response, and
+// duplicates and cross-references are detected on the whole batch.
+type syncPipeline struct {
+	o               *Orchestrator
+	ctx             context.Context // canceled when the sync is stopped
+	processCtx      context.Context // for work already started, which finishes even if ctx is canceled
+	config          *models.ConnectorConfig
+	trans           *transformer.Transformer
+	transformConfig transformer.TransformConfig
+	stages          memoryStages
+	links           map[string]*memoryLinks
+	budget          *quotaBudget
+	limiter         *insertLimiter
+	backpressure    *syncBackpressure
+}
+
+// run passes memories through the stages and calls record with each of them once it's done, from
+// the calling goroutine. The average stage times of ingested memories are added to metrics.
+func (p *syncPipeline) run(memories []models.Memory, record func(*pipelineItem), metrics *models.SyncMetrics) {
+	pipeline := p.config.Ingestion.Pipeline
+	queueSize := max(pipeline.QueueSize, 1)
+
+	queued := make(chan *pipelineItem, queueSize)
+	transformed := make(chan *pipelineItem, queueSize)
+	enriched := make(chan *pipelineItem, queueSize)
+	done := make(chan *pipelineItem, queueSize)
+
+	go func() {
+		defer close(queued)
+		for i := range memories {
+			queued <- &pipelineItem{memory: &memories[i]}
+		}
+	}()
+	runStage(max(pipeline.TransformWorkers, 1), queued, transformed, p.transform)
+	runStage(max(pipeline.EnrichWorkers, 1), transformed, enriched, p.enrich)
+	runStage(p.insertWorkers(), enriched, done, p.insert)
+
+	var ingested int64
+	var transformTime, enrichTime, insertTime time.Duration
+	for item := range done {
+		if item.err == nil {
+			ingested++
+			transformTime += item.transformTime
+			enrichTime += item.enrichTime
+			insertTime += item.insertTime
+		}
+		record(item)
+	}
+
+	if ingested > 0 {
+		metrics.AvgTransformTimeMs = transformTime.Milliseconds() / ingested
+		metrics.AvgEnrichTimeMs = enrichTime.Milliseconds() / ingested
+		metrics.AvgInsertTimeMs = insertTime.Milliseconds() / ingested
+	}
+}
+
+// runStage starts workers that process the items of in and pass them on to out, and closes out
+// once in is closed and drained. Items that failed in an earlier stage are passed on unprocessed.
+func runStage(workers int, in <-chan *pipelineItem, out chan<- *pipelineItem, process func(*pipelineItem)) {
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range in {
+				if item.err == nil {
+					process(item)
+				}
+				out <- item
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+}
+
+// insertWorkers returns the number of insert workers, enough for the most inserts the concurrency
+// limiter may allow at once
+func (p *syncPipeline) insertWorkers() int {
+	workers := p.config.Ingestion.MaxConcurrency
+	if p.config.Ingestion.AdaptsConcurrency() {
+		_, maxLimit := p.config.Ingestion.ConcurrencyBounds()
+		workers = max(workers, maxLimit)
+	}
+	return max(workers, 1)
+}
+
+// transform runs the content and language stages and the transformation strategy
+func (p *syncPipeline) transform(item *pipelineItem) {
+	// Memories still queued when the sync is stopped are deferred, those in a stage finish
+	if p.ctx.Err() != nil {
+		item.err = errDeferred
+		return
+	}
+	if p.budget.spent() {
+		item.err = errQuotaExhausted
+		return
+	}
+
+	start := time.Now()
+	item.staged, item.text, item.metadata, item.err = p.o.transformMemory(p.processCtx, p.trans, item.memory, p.transformConfig, p.stages)
+	item.transformTime = time.Since(start)
+}
+
+// enrich runs the enrichers and adds the memory's cross-references
+func (p *syncPipeline) enrich(item *pipelineItem) {
+	if p.ctx.Err() != nil {
+		item.err = errDeferred
+		return
+	}
+
+	start := time.Now()
+	item.text, item.metadata, item.err = p.o.enrichDocument(p.processCtx, p.trans, item.staged, item.text, item.metadata, p.stages, p.links[item.memory.ID])
+	item.enrichTime = time.Since(start)
+}
+
+// insert inserts the document once the concurrency limiter and LightRAG's queue admit it
+func (p *syncPipeline) insert(item *pipelineItem) {
+	// Acquire a slot, unless the sync is being stopped
+	if p.limiter.acquire(p.ctx) == nil {
+		defer p.limiter.release()
+	}
+	if p.ctx.Err() != nil {
+		item.err = errDeferred
+		return
+	}
+
+	// Hold back while LightRAG's pipeline is full
+	if err := p.backpressure.wait(p.ctx); err != nil {
+		item.err = errDeferred
+		return
+	}
+
+	start := time.Now()
+	item.document, item.err = p.o.insertMemory(p.processCtx, p.config, item.text, item.metadata, p.budget, p.limiter)
+	item.insertTime = time.Since(start)
+}
@@ -0,0 +1,99 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/places"
+	"go.uber.org/zap"
+)
+
+// ClusterPlaces runs the clustering job of a connector with transform.places. It adds the located
+// memories of the Memory API's widest query range (up to limit) to the location history recorded by
+// syncs, clusters the history into frequent places, and saves them for labelling new memories.
+// The job should run while the connector isn't syncing, since a running sync saves its own state.
+func (o *Orchestrator) ClusterPlaces(ctx context.Context, config *models.ConnectorConfig, limit int) (*models.PlaceState, error) {
+	if !config.Transform.Places.Enabled {
+		return nil, fmt.Errorf("connector %s doesn't cluster places (transform.places.enabled)", config.ID)
+	}
+
+	syncState, err := o.stateManager.GetState(ctx, config.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync state: %w", err)
+	}
+	if syncState.ContextID == "" {
+		syncState.ContextID = config.ContextID
+	}
+	if syncState.Places == nil {
+		syncState.Places = &models.PlaceState{}
+	}
+
+	queryRange := models.QueryRanges[len(models.QueryRanges)-1]
+	memoryList, err := o.memorySourceFor(config).GetMemories(ctx, config.ContextID, limit, queryRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch memories: %w", err)
+	}
+	places.Record(syncState.Places, memoryList.Memories)
+
+	syncState.Places.Clusters = places.Cluster(config.ContextID, syncState.Places.History, syncState.Places.Clusters, places.Config{
+		RadiusM:     config.Transform.Places.RadiusM,
+		MinMemories: config.Transform.Places.MinMemories,
+		Location:    config.Schedule.Location(),
+	})
+	clusteredAt := o.clock.Now()
+	syncState.Places.ClusteredAt = &clusteredAt
+	syncState.UpdatedAt = clusteredAt
+
+	if err := o.stateManager.SaveState(ctx, syncState); err != nil {
+		return nil, fmt.Errorf("failed to save places: %w", err)
+	}
+
+	o.logger.Info("Clustered frequent places",
+		zap.String("connector_id", config.ID),
+		zap.Int("locations", len(syncState.Places.History)),
+		zap.Int("places", len(syncState.Places.Clusters)),
+	)
+
+	return syncState.Places, nil
+}
+
+// labelPlaces records the locations of the new memories for the clustering job and returns the
+// frequent place of each one recorded at one, nil if the connector doesn't cluster places
+func (o *Orchestrator) labelPlaces(config *models.ConnectorConfig, memories []models.Memory, syncState *models.SyncState) map[string]*models.PlaceCluster {
+	if !config.Transform.Places.Enabled {
+		return nil
+	}
+	if syncState.Places == nil {
+		syncState.Places = &models.PlaceState{}
+	}
+	places.Record(syncState.Places, memories)
+
+	labelled := make(map[string]*models.PlaceCluster)
+	for i := range memories {
+		if !memories[i].HasLocation() {
+			continue
+		}
+		if place := places.Match(syncState.Places.Clusters, *memories[i].LocationLat, *memories[i].LocationLon); place != nil {
+			labelled[memories[i].ID] = place
+		}
+	}
+
+	o.logger.Debug("Labelled places",
+		zap.String("connector_id", config.ID),
+		zap.Int("memories_at_places", len(labelled)),
+	)
+
+	return labelled
+}
+
+// placeReference returns the note on a memory's frequent place appended to its document, and the
+// metadata linking it to the place
+func placeReference(place *models.PlaceCluster) (string, map[string]string) {
+	text := fmt.Sprintf("\n\n[Recorded at %s (%s)]", place.Label, place.ID)
+	metadata := map[string]string{
+		"place_cluster_id": place.ID,
+		"inferred_label":   place.Label,
+	}
+	return text, metadata
+}
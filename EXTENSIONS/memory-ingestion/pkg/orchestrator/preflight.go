@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// ErrUpstreamUnavailable is wrapped by the errors of Preflight
+var ErrUpstreamUnavailable = errors.New("upstream unavailable")
+
+// Preflight checks that the upstream services a connector syncs between answer: its Memory API
+// (unless the connector is simulated) and LightRAG (unless it only exports). The error wraps
+// ErrUpstreamUnavailable and names the first that doesn't answer.
+func (o *Orchestrator) Preflight(ctx context.Context, config *models.ConnectorConfig) error {
+	if !config.Simulation.Enabled {
+		if err := o.CheckMemoryAPI(ctx, config); err != nil {
+			return fmt.Errorf("%w: Memory API: %v", ErrUpstreamUnavailable, err)
+		}
+	}
+	if !config.Export.ExportOnly() {
+		if err := o.lightragClient.HealthCheck(ctx); err != nil {
+			return fmt.Errorf("%w: LightRAG: %v", ErrUpstreamUnavailable, err)
+		}
+	}
+	return nil
+}
+
+// RecordUpstreamUnavailable records the report of a sync skipped because its preflight failed
+// with err, as the connector's last report and in its report history. The connector's last sync
+// time is kept, so the next run covers the memories this one would have.
+func (o *Orchestrator) RecordUpstreamUnavailable(ctx context.Context, config *models.ConnectorConfig, err error) *models.SyncReport {
+	now := o.clock.Now()
+	report := &models.SyncReport{
+		ConnectorID:  config.ID,
+		ContextID:    config.ContextID,
+		StartTime:    now,
+		EndTime:      now,
+		Status:       "upstream_unavailable",
+		ErrorMessage: fmt.Sprintf("Sync skipped, %v", err),
+	}
+
+	syncState, stateErr := o.stateManager.GetState(ctx, config.ID)
+	if stateErr == nil {
+		if syncState.ContextID == "" {
+			syncState.ContextID = config.ContextID
+		}
+		syncState.LastSyncReport = report
+		syncState.UpdatedAt = now
+		stateErr = o.stateManager.SaveState(context.WithoutCancel(ctx), syncState)
+	}
+	if stateErr != nil {
+		o.logger.Error("Failed to save state", zap.String("connector_id", config.ID), zap.Error(stateErr))
+	}
+	o.recordReport(ctx, report)
+
+	return report
+}
@@ -0,0 +1,20 @@
+package orchestrator
+
+import (
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// minimalMetadata returns the metadata of a document of the minimal profile: the keys that
+// identify the memory and the date it was created, the day created_at names
+func minimalMetadata(memory *models.Memory, metadata map[string]string) map[string]string {
+	minimal := make(map[string]string, len(models.MinimalMetadataKeys))
+	for _, key := range models.MinimalMetadataKeys {
+		if value, ok := metadata[key]; ok {
+			minimal[key] = value
+		}
+	}
+	if createdAt, err := memory.ParseCreatedAt(); err == nil {
+		minimal["created_date"] = createdAt.Format("2006-01-02")
+	}
+	return minimal
+}
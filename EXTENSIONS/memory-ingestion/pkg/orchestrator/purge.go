@@ -0,0 +1,149 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// ErrPurgePlanChanged is returned by PurgeContext if purging the context would no longer remove
+// what was planned, e.g. after a sync ingested more memories
+var ErrPurgePlanChanged = errors.New("context changed since the purge was planned")
+
+// PlanPurge lists what purging a context removes: the documents of the memories its connectors
+// ingested, found by their file_path (memory URI or short citation) or the document IDs recorded at
+// ingestion, and the connectors' sync state.
+// Memories that connectors of other contexts ingested too keep their documents.
+func (o *Orchestrator) PlanPurge(ctx context.Context, contextID string, connectors []models.ConnectorConfig) (*models.PurgePlan, error) {
+	plan := &models.PurgePlan{ContextID: contextID, ConnectorIDs: []string{}, Documents: []string{}}
+
+	memories := make(map[string]bool) // memory ID -> ingested for other contexts too
+	filePaths := make(map[string]string)
+	docIDs := make(map[string]string)
+	var others []*models.SyncState
+	for i := range connectors {
+		connector := &connectors[i]
+		syncState, err := o.stateManager.GetState(ctx, connector.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sync state: %w", err)
+		}
+		if connector.ContextID != contextID {
+			others = append(others, syncState)
+			continue
+		}
+
+		plan.ConnectorIDs = append(plan.ConnectorIDs, connector.ID)
+		digests := connector.Transform.Mode == models.TransformModeDailyDigest
+		if digests {
+			plan.DigestConnectors = append(plan.DigestConnectors, connector.ID)
+		}
+		for memoryID, processed := range syncState.ProcessedIDs {
+			if processed {
+				memories[memoryID] = false
+				filePaths[utils.MemoryURI(memoryID)] = memoryID
+				if docID := syncState.Versions[memoryID].DocID; docID != "" && !digests {
+					docIDs[docID] = memoryID
+				}
+			}
+		}
+		for citation, memoryID := range syncState.Citations {
+			filePaths[citation] = memoryID
+		}
+	}
+
+	for _, syncState := range others {
+		for memoryID := range memories {
+			if syncState.IsProcessed(memoryID) {
+				memories[memoryID] = true
+			}
+		}
+	}
+	plan.Memories = len(memories)
+	for _, shared := range memories {
+		if shared {
+			plan.SharedMemories++
+		}
+	}
+
+	for page := 1; ; page++ {
+		documents, err := o.lightragClient.ListDocuments(ctx, page, documentPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUpstreamFailed, err)
+		}
+		for _, document := range documents.Documents {
+			memoryID, ok := filePaths[document.FilePath]
+			if !ok {
+				memoryID, ok = docIDs[document.ID]
+			}
+			if ok && !memories[memoryID] {
+				plan.Documents = append(plan.Documents, document.ID)
+			}
+		}
+		if !documents.Pagination.HasNext {
+			return plan, nil
+		}
+	}
+}
+
+// PurgeContext removes the documents of a planned purge from LightRAG and clears the sync state of
+// its connectors, exactly as planned by PlanPurge. It fails with ErrPurgePlanChanged if planning
+// again lists other documents or connectors. The state is kept if a deletion fails, so the purge
+// can be repeated. The connectors must not be syncing.
+func (o *Orchestrator) PurgeContext(ctx context.Context, plan *models.PurgePlan, connectors []models.ConnectorConfig) (*models.PurgeResult, error) {
+	current, err := o.PlanPurge(ctx, plan.ContextID, connectors)
+	if err != nil {
+		return nil, err
+	}
+	if !sameIDs(current.ConnectorIDs, plan.ConnectorIDs) || !sameIDs(current.Documents, plan.Documents) {
+		return nil, fmt.Errorf("%w: %d documents of %d connectors planned, now %d documents of %d connectors",
+			ErrPurgePlanChanged, len(plan.Documents), len(plan.ConnectorIDs), len(current.Documents), len(current.ConnectorIDs))
+	}
+	result := &models.PurgeResult{PurgePlan: *plan, ClearedConnectors: []string{}}
+
+	for start := 0; start < len(plan.Documents); start += documentPageSize {
+		batch := plan.Documents[start:min(start+documentPageSize, len(plan.Documents))]
+		if err := o.lightragClient.DeleteDocuments(ctx, batch); err != nil {
+			return nil, fmt.Errorf("%w: %d of %d documents deleted: %w",
+				ErrUpstreamFailed, result.DeletedDocuments, len(plan.Documents), err)
+		}
+		result.DeletedDocuments += len(batch)
+	}
+
+	for _, connectorID := range plan.ConnectorIDs {
+		if err := o.stateManager.DeleteState(ctx, connectorID); err != nil {
+			return nil, fmt.Errorf("failed to clear the state of connector %s: %w", connectorID, err)
+		}
+		result.ClearedConnectors = append(result.ClearedConnectors, connectorID)
+	}
+
+	o.logger.Warn("Purged context",
+		zap.String("context_id", plan.ContextID),
+		zap.Strings("connectors", result.ClearedConnectors),
+		zap.Int("deleted_documents", result.DeletedDocuments),
+		zap.Int("shared_memories", plan.SharedMemories),
+	)
+
+	return result, nil
+}
+
+// sameIDs returns true if a and b hold the same IDs, in any order
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ids := make(map[string]int, len(a))
+	for _, id := range a {
+		ids[id]++
+	}
+	for _, id := range b {
+		if ids[id] == 0 {
+			return false
+		}
+		ids[id]--
+	}
+	return true
+}
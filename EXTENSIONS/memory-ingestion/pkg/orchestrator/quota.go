@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// errQuotaExhausted is returned by processMemory for memories that don't fit in the daily budget
+var errQuotaExhausted = errors.New("daily quota exhausted")
+
+// quotaBudget hands out a connector's daily budget to the memories of a sync. A nil budget is unlimited.
+type quotaBudget struct {
+	quota models.QuotaConfig
+	usage *models.QuotaUsage // part of the sync state, saved with it
+	mu    sync.Mutex
+}
+
+// newQuotaBudget returns the budget of a connector for the current day, nil if it has no quota
+func newQuotaBudget(quota models.QuotaConfig, syncState *models.SyncState, now time.Time) *quotaBudget {
+	if !quota.Enabled() {
+		return nil
+	}
+	return &quotaBudget{
+		quota: quota,
+		usage: syncState.QuotaUsageOn(models.QuotaDay(now)),
+	}
+}
+
+// spent returns true if no budget is left for any document
+func (b *quotaBudget) spent() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.usage.Exhausted(b.quota)
+}
+
+// reserve takes the budget for a document of the estimated tokens, false if it doesn't fit
+func (b *quotaBudget) reserve(tokens int) bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.usage.Allows(b.quota, tokens) {
+		return false
+	}
+	b.usage.Documents++
+	b.usage.Tokens += tokens
+	return true
+}
+
+// release returns the budget of a document that wasn't ingested
+func (b *quotaBudget) release(tokens int) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.usage.Documents--
+	b.usage.Tokens -= tokens
+}
+
+// QuotaStatus returns a connector's budget for the current day, nil if it has no quota
+func (o *Orchestrator) QuotaStatus(ctx context.Context, config *models.ConnectorConfig) (*models.QuotaStatus, error) {
+	if !config.Quota.Enabled() {
+		return nil, nil
+	}
+
+	syncState, err := o.stateManager.GetState(ctx, config.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return syncState.QuotaStatus(config.Quota, o.clock.Now()), nil
+}
@@ -0,0 +1,173 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kamir/memory-connector/pkg/events"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// Statuses of a re-ingested memory
+const (
+	ReingestStatusReingested = "reingested"
+	ReingestStatusBlocked    = "blocked" // by a content policy, its documents are deleted
+)
+
+// documentPageSize is the page size of document searches, the largest LightRAG allows
+const documentPageSize = 200
+
+var (
+	// ErrMemoryNotFound is returned by ReingestMemory for memories the Memory API doesn't serve
+	ErrMemoryNotFound = errors.New("memory not found")
+
+	// ErrQuotaExhausted is returned by ReingestMemory if the connector's daily quota is used up
+	ErrQuotaExhausted = errQuotaExhausted
+
+	// ErrUpstreamFailed wraps the errors of ReingestMemory, PlanPurge, PurgeContext, Snapshot, and
+	// RestoreSnapshot if the Memory API or LightRAG fail (for ReingestMemory, before the memory's
+	// documents are deleted)
+	ErrUpstreamFailed = errors.New("upstream request failed")
+)
+
+// ReingestMemory replaces the documents of a memory in LightRAG with its latest version: it fetches
+// the memory (among the latest limit memories, see FetchMemory), deletes the documents citing it by
+// URI or short citation, and inserts it transformed with the connector's current settings. Cross-
+// references to its episode, trip, or place aren't added again. If the insert fails, the memory is
+// no longer marked processed, so the next sync ingests it. The connector must not be syncing.
+func (o *Orchestrator) ReingestMemory(ctx context.Context, config *models.ConnectorConfig, memoryID string, limit int) (*models.ReingestResult, error) {
+	if config.Transform.Mode == models.TransformModeDailyDigest {
+		return nil, fmt.Errorf("connector %s ingests daily digests, which can't be re-ingested by memory", config.ID)
+	}
+	config = config.WithFeatures(o.featureOverridesFor(config.ID))
+
+	syncState, err := o.stateManager.GetState(ctx, config.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync state: %w", err)
+	}
+	if syncState.ContextID == "" {
+		syncState.ContextID = config.ContextID
+	}
+
+	memory, err := o.FetchMemory(ctx, config, memoryID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpstreamFailed, err)
+	}
+	if memory == nil {
+		return nil, fmt.Errorf("%w: %s isn't among the latest %d memories of context %s",
+			ErrMemoryNotFound, memoryID, limit, config.ContextID)
+	}
+
+	trans, err := o.transformerFor(config)
+	if err != nil {
+		return nil, err
+	}
+	transformConfig, err := o.transformConfigFor(config, []models.Memory{*memory}, syncState)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := o.memoryStagesFor(config)
+	if err != nil {
+		return nil, err
+	}
+	budget := newQuotaBudget(config.Quota, syncState, o.clock.Now())
+	if budget.spent() {
+		return nil, ErrQuotaExhausted
+	}
+
+	docIDs, err := o.memoryDocuments(ctx, memoryID, syncState)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpstreamFailed, err)
+	}
+	if len(docIDs) > 0 {
+		if err := o.lightragClient.DeleteDocuments(ctx, docIDs); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUpstreamFailed, err)
+		}
+	}
+
+	result := &models.ReingestResult{
+		ConnectorID:      config.ID,
+		MemoryID:         memoryID,
+		URI:              utils.MemoryURI(memoryID),
+		DeletedDocuments: docIDs,
+	}
+
+	stages.prefetch(ctx, []models.Memory{*memory})
+	document, err := o.processMemory(ctx, config, trans, memory, transformConfig, stages, nil, budget, newInsertLimiter(1, 1, 1, false))
+	var blocked *blockedError
+	switch {
+	case errors.As(err, &blocked):
+		result.Status = ReingestStatusBlocked
+		result.Policies = blocked.policies
+		syncState.MarkProcessed(memoryID)
+		delete(syncState.Versions, memoryID)
+	case err != nil:
+		// The memory's documents are gone, so the next sync ingests it
+		delete(syncState.ProcessedIDs, memoryID)
+		delete(syncState.Versions, memoryID)
+		if saveErr := o.stateManager.SaveState(context.WithoutCancel(ctx), syncState); saveErr != nil {
+			o.logger.Error("Failed to save state", zap.Error(saveErr))
+		}
+		return nil, err
+	default:
+		result.Status = ReingestStatusReingested
+		syncState.MarkProcessed(memoryID)
+		syncState.RecordVersion(memory, document, o.clock.Now())
+		version := syncState.Versions[memoryID]
+		result.Version = &version
+	}
+
+	if err := o.stateManager.SaveState(context.WithoutCancel(ctx), syncState); err != nil {
+		return nil, fmt.Errorf("failed to save state: %w", err)
+	}
+
+	o.logger.Info("Re-ingested memory",
+		zap.String("connector_id", config.ID),
+		zap.String("memory_id", memoryID),
+		zap.String("status", result.Status),
+		zap.Strings("deleted_documents", docIDs),
+	)
+	data := map[string]interface{}{
+		"memory_id":         memoryID,
+		"uri":               result.URI,
+		"status":            result.Status,
+		"deleted_documents": docIDs,
+	}
+	if receipt := syncState.Receipt(memoryID); receipt != nil {
+		data["receipt"] = receipt
+	}
+	o.publish(events.TypeMemoryReingested, config.ID, data)
+
+	return result, nil
+}
+
+// memoryDocuments returns the IDs of LightRAG's documents of a memory: the document the connector
+// recorded it was ingested in, and those whose file_path is its URI or a short citation of it
+func (o *Orchestrator) memoryDocuments(ctx context.Context, memoryID string, syncState *models.SyncState) ([]string, error) {
+	filePaths := map[string]bool{utils.MemoryURI(memoryID): true}
+	for citation, cited := range syncState.Citations {
+		if cited == memoryID {
+			filePaths[citation] = true
+		}
+	}
+	recorded := syncState.Versions[memoryID].DocID
+
+	docIDs := []string{}
+	for page := 1; ; page++ {
+		documents, err := o.lightragClient.ListDocuments(ctx, page, documentPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, document := range documents.Documents {
+			if filePaths[document.FilePath] || (recorded != "" && document.ID == recorded) {
+				docIDs = append(docIDs, document.ID)
+			}
+		}
+		if !documents.Pagination.HasNext {
+			return docIDs, nil
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kamir/memory-connector/pkg/export"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// ErrConnectorNotConfigured is returned by ImportDocuments for documents of connectors this
+// deployment doesn't configure
+var ErrConnectorNotConfigured = errors.New("connector not configured")
+
+// ImportConnectorIDs returns the connectors whose sync state an import of documents records:
+// connectorID if set, otherwise the connectors the documents were exported by
+func ImportConnectorIDs(documents []export.Document, connectorID string) []string {
+	if connectorID != "" {
+		return []string{connectorID}
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for i := range documents {
+		if id := documents[i].ConnectorID; !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// ImportDocuments inserts documents of a JSON Lines export (see ExportConfig) into LightRAG as they
+// are, without fetching or transforming memories, and marks their memories processed in the sync
+// state of the connector that exported them, or of connectorID if set. Documents whose memories the
+// connector already processed are skipped, so an import can be repeated; documents without memory
+// IDs (exported without include_metadata) are always inserted and left to LightRAG's deduplication.
+// The connectors must not be syncing.
+func (o *Orchestrator) ImportDocuments(ctx context.Context, documents []export.Document, connectors []models.ConnectorConfig, connectorID string) (*models.ImportResult, error) {
+	configured := make(map[string]bool, len(connectors))
+	for _, connector := range connectors {
+		configured[connector.ID] = true
+	}
+
+	result := &models.ImportResult{Documents: len(documents), Connectors: []string{}}
+	states := make(map[string]*models.SyncState)
+	for _, id := range ImportConnectorIDs(documents, connectorID) {
+		switch {
+		case id == "":
+			return nil, fmt.Errorf("%w: documents without connector_id must be imported into a connector", ErrConnectorNotConfigured)
+		case !configured[id] && connectorID == "":
+			return nil, fmt.Errorf("%w: %q exported the documents, import them into another connector", ErrConnectorNotConfigured, id)
+		case !configured[id]:
+			return nil, fmt.Errorf("%w: %q", ErrConnectorNotConfigured, id)
+		}
+		syncState, err := o.stateManager.GetState(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sync state: %w", err)
+		}
+		states[id] = syncState
+		result.Connectors = append(result.Connectors, id)
+	}
+	for _, connector := range connectors {
+		if syncState := states[connector.ID]; syncState != nil && syncState.ContextID == "" {
+			syncState.ContextID = connector.ContextID
+		}
+	}
+
+	backpressure := o.pipeline.newSync()
+	for i := range documents {
+		document := &documents[i]
+		target := connectorID
+		if target == "" {
+			target = document.ConnectorID
+		}
+		syncState := states[target]
+
+		memoryIDs := document.MemoryIDs()
+		if len(memoryIDs) > 0 && allProcessed(syncState, memoryIDs) {
+			result.Duplicates++
+			continue
+		}
+
+		err := backpressure.wait(ctx)
+		if err == nil {
+			_, err = o.lightragClient.InsertDocument(ctx, document.Text, document.Metadata)
+		}
+		if err != nil {
+			// The documents imported so far are recorded, so a repeated import skips them
+			if saveErr := o.saveImportStates(ctx, states); saveErr != nil {
+				o.logger.Error("Failed to save state", zap.Error(saveErr))
+			}
+			return nil, fmt.Errorf("%w: %d of %d documents imported: %w", ErrUpstreamFailed, result.Imported, len(documents), err)
+		}
+
+		for _, memoryID := range memoryIDs {
+			syncState.MarkProcessed(memoryID)
+		}
+		// Short citations stay resolvable by memory lookups
+		if filePath := document.Metadata["file_path"]; len(memoryIDs) == 1 && filePath != "" && filePath != utils.MemoryURI(memoryIDs[0]) {
+			if syncState.Citations == nil {
+				syncState.Citations = make(map[string]string)
+			}
+			syncState.Citations[filePath] = memoryIDs[0]
+		}
+		result.Imported++
+	}
+
+	if err := o.saveImportStates(ctx, states); err != nil {
+		return nil, err
+	}
+
+	o.logger.Info("Imported documents",
+		zap.Strings("connectors", result.Connectors),
+		zap.Int("documents", result.Documents),
+		zap.Int("imported", result.Imported),
+		zap.Int("duplicates", result.Duplicates),
+	)
+
+	return result, nil
+}
+
+// allProcessed returns true if the sync state marks every memory processed
+func allProcessed(syncState *models.SyncState, memoryIDs []string) bool {
+	for _, memoryID := range memoryIDs {
+		if !syncState.IsProcessed(memoryID) {
+			return false
+		}
+	}
+	return true
+}
+
+// saveImportStates saves the sync states an import recorded its memories in
+func (o *Orchestrator) saveImportStates(ctx context.Context, states map[string]*models.SyncState) error {
+	for connectorID, syncState := range states {
+		if err := o.stateManager.SaveState(context.WithoutCancel(ctx), syncState); err != nil {
+			return fmt.Errorf("failed to save the state of connector %s: %w", connectorID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,433 @@
+package orchestrator
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/export"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// SnapshotFormatVersion is the format of the snapshot archives written by Snapshot
+const SnapshotFormatVersion = 1
+
+// Entries of a snapshot archive, a gzip-compressed tar. The manifest comes first.
+const (
+	snapshotManifestEntry  = "manifest.json"
+	snapshotDocumentsEntry = "documents.jsonl" // one models.SnapshotDocument per line
+	snapshotStatesEntry    = "states.jsonl"    // one models.SyncState per line
+)
+
+var (
+	// ErrInvalidSnapshot is returned by ReadSnapshot and RestoreSnapshot for archives that can't be
+	// restored: malformed, of another format version, or of connectors not configured for its context
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+	// ErrStateExists is returned by RestoreSnapshot if a connector of the snapshot already has sync state
+	ErrStateExists = errors.New("connector already has sync state")
+)
+
+// Snapshot is a read snapshot archive
+type Snapshot struct {
+	Manifest  models.SnapshotManifest
+	Documents []models.SnapshotDocument
+	States    []*models.SyncState
+}
+
+// snapshotSource is a connector whose documents are taken into a snapshot
+type snapshotSource struct {
+	config    *models.ConnectorConfig
+	syncState *models.SyncState
+	documents []client.DocumentStatus // its documents in LightRAG
+	memoryIDs []string                // of documents, by index
+}
+
+// Snapshot writes the documents and sync state of a context to w, as a gzip-compressed tar (see
+// SnapshotFormatVersion). LightRAG doesn't serve the text of documents, so each document is rebuilt
+// from its memory's current version in the Memory API (among the latest limit memories of the widest
+// query range, see FetchMemory), transformed with the connector's current settings, without
+// cross-references to its episode, trip, or place. The recorded versions of the state follow.
+// Memories whose documents can't be rebuilt are listed in the manifest's Missing and dropped from
+// the state, so a restored connector ingests them again.
+func (o *Orchestrator) Snapshot(ctx context.Context, contextID string, connectors []models.ConnectorConfig, limit int, w io.Writer) (*models.SnapshotManifest, error) {
+	manifest := &models.SnapshotManifest{
+		FormatVersion: SnapshotFormatVersion,
+		ContextID:     contextID,
+		CreatedAt:     o.clock.Now().UTC(),
+		ConnectorIDs:  []string{},
+		Missing:       []string{},
+	}
+
+	var sources []*snapshotSource
+	owners := make(map[string]*snapshotSource) // file_path -> connector that ingested it
+	filePaths := make(map[string]string)       // file_path -> memory ID
+	var states []*models.SyncState
+	for i := range connectors {
+		connector := &connectors[i]
+		if connector.ContextID != contextID {
+			continue
+		}
+		syncState, err := o.stateManager.GetState(ctx, connector.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sync state: %w", err)
+		}
+		if syncState.ContextID == "" {
+			syncState.ContextID = contextID
+		}
+		manifest.ConnectorIDs = append(manifest.ConnectorIDs, connector.ID)
+		states = append(states, syncState)
+		if connector.Transform.Mode == models.TransformModeDailyDigest {
+			manifest.DigestConnectors = append(manifest.DigestConnectors, connector.ID)
+			continue
+		}
+
+		source := &snapshotSource{config: connector, syncState: syncState}
+		sources = append(sources, source)
+		for memoryID, processed := range syncState.ProcessedIDs {
+			if uri := utils.MemoryURI(memoryID); processed && owners[uri] == nil {
+				owners[uri] = source
+				filePaths[uri] = memoryID
+			}
+		}
+		for citation, memoryID := range syncState.Citations {
+			if owners[citation] == nil {
+				owners[citation] = source
+				filePaths[citation] = memoryID
+			}
+		}
+	}
+
+	for page := 1; ; page++ {
+		documents, err := o.lightragClient.ListDocuments(ctx, page, documentPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrUpstreamFailed, err)
+		}
+		for _, document := range documents.Documents {
+			if source := owners[document.FilePath]; source != nil {
+				source.documents = append(source.documents, document)
+				source.memoryIDs = append(source.memoryIDs, filePaths[document.FilePath])
+			}
+		}
+		if !documents.Pagination.HasNext {
+			break
+		}
+	}
+
+	var documents []models.SnapshotDocument
+	for _, source := range sources {
+		rebuilt, missing, err := o.rebuildDocuments(ctx, source, limit)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, rebuilt...)
+		manifest.Missing = append(manifest.Missing, missing...)
+	}
+	manifest.Documents = len(documents)
+	if len(manifest.Missing) > 0 {
+		o.logger.Warn("Snapshot misses memories, they are dropped from the sync state",
+			zap.String("context_id", contextID),
+			zap.Int("missing", len(manifest.Missing)),
+			zap.Strings("memory_ids", manifest.Missing),
+			zap.Int("limit", limit),
+		)
+	}
+
+	if err := writeSnapshot(w, manifest, documents, states); err != nil {
+		return nil, err
+	}
+
+	o.logger.Info("Took context snapshot",
+		zap.String("context_id", contextID),
+		zap.Strings("connectors", manifest.ConnectorIDs),
+		zap.Int("documents", manifest.Documents),
+		zap.Int("missing", len(manifest.Missing)),
+	)
+
+	return manifest, nil
+}
+
+// rebuildDocuments rebuilds the documents of a snapshot source from the current versions of their
+// memories, returning the memories the Memory API no longer serves (or beyond limit) or a content
+// policy now blocks. Those are dropped from the source's sync state.
+func (o *Orchestrator) rebuildDocuments(ctx context.Context, source *snapshotSource, limit int) ([]models.SnapshotDocument, []string, error) {
+	if len(source.documents) == 0 {
+		return nil, nil, nil
+	}
+	config := source.config.WithFeatures(o.featureOverridesFor(source.config.ID))
+
+	queryRange := models.QueryRanges[len(models.QueryRanges)-1]
+	memoryList, err := o.memorySourceFor(config).GetMemories(ctx, config.ContextID, limit, queryRange)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to fetch memories: %w", ErrUpstreamFailed, err)
+	}
+	current := make(map[string]*models.Memory, len(memoryList.Memories))
+	for i := range memoryList.Memories {
+		current[memoryList.Memories[i].ID] = &memoryList.Memories[i]
+	}
+
+	var memories []models.Memory
+	for _, memoryID := range source.memoryIDs {
+		if memory := current[memoryID]; memory != nil {
+			memories = append(memories, *memory)
+		}
+	}
+
+	trans, err := o.transformerFor(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	transformConfig, err := o.transformConfigFor(config, memories, source.syncState)
+	if err != nil {
+		return nil, nil, err
+	}
+	stages, err := o.memoryStagesFor(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	stages.prefetch(ctx, memories)
+
+	var documents []models.SnapshotDocument
+	var missing []string
+	for i, document := range source.documents {
+		memory := current[source.memoryIDs[i]]
+		if memory == nil {
+			missing = append(missing, source.memoryIDs[i])
+			continue
+		}
+
+		text, metadata, err := o.renderMemory(ctx, trans, memory, transformConfig, stages, nil)
+		var blocked *blockedError
+		if errors.As(err, &blocked) {
+			missing = append(missing, memory.ID)
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to rebuild the document of memory %s: %w", memory.ID, err)
+		}
+
+		documents = append(documents, models.SnapshotDocument{
+			ConnectorID: config.ID,
+			MemoryID:    memory.ID,
+			DocumentID:  document.ID,
+			Status:      document.Status,
+			CreatedAt:   document.CreatedAt,
+			Text:        text,
+			Metadata:    metadata,
+		})
+		// A restore inserts the rebuilt text, whose ID LightRAG derives from it
+		source.syncState.RecordVersion(memory, models.IngestedDocument{
+			DocID:       export.DocumentID(text),
+			RequestHash: client.DocumentRequestHash(text, metadata),
+		}, o.clock.Now())
+	}
+
+	// The restored state mustn't claim memories whose documents aren't restored
+	for _, memoryID := range missing {
+		forgetMemory(source.syncState, memoryID)
+	}
+
+	return documents, missing, nil
+}
+
+// forgetMemory drops a memory from a sync state, so the connector ingests it again
+func forgetMemory(syncState *models.SyncState, memoryID string) {
+	delete(syncState.ProcessedIDs, memoryID)
+	delete(syncState.Versions, memoryID)
+	for citation, citedID := range syncState.Citations {
+		if citedID == memoryID {
+			delete(syncState.Citations, citation)
+		}
+	}
+}
+
+// writeSnapshot writes a snapshot archive
+func writeSnapshot(w io.Writer, manifest *models.SnapshotManifest, documents []models.SnapshotDocument, states []*models.SyncState) error {
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot manifest: %w", err)
+	}
+	documentData, err := jsonLines(documents)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot documents: %w", err)
+	}
+	stateData, err := jsonLines(states)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot states: %w", err)
+	}
+
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{
+		{snapshotManifestEntry, manifestData},
+		{snapshotDocumentsEntry, documentData},
+		{snapshotStatesEntry, stateData},
+	} {
+		header := &tar.Header{
+			Name:    entry.name,
+			Mode:    0600,
+			Size:    int64(len(entry.data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		if _, err := archive.Write(entry.data); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// jsonLines encodes values as JSON Lines
+func jsonLines[T any](values []T) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, value := range values {
+		if err := encoder.Encode(value); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadSnapshot reads a snapshot archive written by Snapshot
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+	}
+	defer gz.Close()
+
+	snapshot := &Snapshot{}
+	var hasManifest bool
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+		}
+
+		switch header.Name {
+		case snapshotManifestEntry:
+			err = json.NewDecoder(archive).Decode(&snapshot.Manifest)
+			hasManifest = true
+		case snapshotDocumentsEntry:
+			snapshot.Documents, err = readJSONLines[models.SnapshotDocument](archive)
+		case snapshotStatesEntry:
+			snapshot.States, err = readJSONLines[*models.SyncState](archive)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSnapshot, header.Name, err)
+		}
+	}
+
+	if !hasManifest {
+		return nil, fmt.Errorf("%w: no %s", ErrInvalidSnapshot, snapshotManifestEntry)
+	}
+	if snapshot.Manifest.FormatVersion != SnapshotFormatVersion {
+		return nil, fmt.Errorf("%w: format version %d, expected %d",
+			ErrInvalidSnapshot, snapshot.Manifest.FormatVersion, SnapshotFormatVersion)
+	}
+	return snapshot, nil
+}
+
+// readJSONLines decodes JSON Lines
+func readJSONLines[T any](r io.Reader) ([]T, error) {
+	var values []T
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20) // a document's text is on one line
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var value T
+		if err := json.Unmarshal(scanner.Bytes(), &value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, scanner.Err()
+}
+
+// RestoreSnapshot inserts the documents of a snapshot into LightRAG and saves the sync state of its
+// connectors, which must be configured for the snapshot's context and have no sync state yet (e.g. a
+// fresh deployment, or after PurgeContext). The state is saved once all documents are inserted, so a
+// failed restore can be repeated; LightRAG skips the documents it already has. The connectors must
+// not be syncing.
+func (o *Orchestrator) RestoreSnapshot(ctx context.Context, snapshot *Snapshot, connectors []models.ConnectorConfig) (*models.RestoreResult, error) {
+	contextID := snapshot.Manifest.ContextID
+	configured := make(map[string]bool)
+	for _, connector := range connectors {
+		if connector.ContextID == contextID {
+			configured[connector.ID] = true
+		}
+	}
+
+	for _, syncState := range snapshot.States {
+		if !configured[syncState.ConnectorID] {
+			return nil, fmt.Errorf("%w: connector %s isn't configured for context %s",
+				ErrInvalidSnapshot, syncState.ConnectorID, contextID)
+		}
+		existing, err := o.stateManager.GetState(ctx, syncState.ConnectorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sync state: %w", err)
+		}
+		if len(existing.ProcessedIDs) > 0 || !existing.LastSyncTime.IsZero() {
+			return nil, fmt.Errorf("%w: %s", ErrStateExists, syncState.ConnectorID)
+		}
+	}
+
+	result := &models.RestoreResult{ContextID: contextID, RestoredStates: []string{}}
+	backpressure := o.pipeline.newSync()
+	for _, document := range snapshot.Documents {
+		if err := backpressure.wait(ctx); err != nil {
+			return nil, fmt.Errorf("%w: %d of %d documents restored: %w",
+				ErrUpstreamFailed, result.RestoredDocuments, len(snapshot.Documents), err)
+		}
+		if _, err := o.lightragClient.InsertDocument(ctx, document.Text, document.Metadata); err != nil {
+			return nil, fmt.Errorf("%w: %d of %d documents restored: %w",
+				ErrUpstreamFailed, result.RestoredDocuments, len(snapshot.Documents), err)
+		}
+		result.RestoredDocuments++
+	}
+
+	for _, syncState := range snapshot.States {
+		if err := o.stateManager.SaveState(context.WithoutCancel(ctx), syncState); err != nil {
+			return nil, fmt.Errorf("failed to restore the state of connector %s: %w", syncState.ConnectorID, err)
+		}
+		result.RestoredStates = append(result.RestoredStates, syncState.ConnectorID)
+	}
+
+	o.logger.Info("Restored context snapshot",
+		zap.String("context_id", contextID),
+		zap.Time("snapshot_created_at", snapshot.Manifest.CreatedAt),
+		zap.Strings("connectors", result.RestoredStates),
+		zap.Int("restored_documents", result.RestoredDocuments),
+	)
+
+	return result, nil
+}
@@ -0,0 +1,140 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// memoryStages prepare a memory for transformation and enrich its document. Coordinates are
+// coarsened first (also before their time zone is looked up), and content is filtered before
+// language detection, so blocked and redacted text never reaches the translation API.
+type memoryStages struct {
+	location   *locationStage               // nil if the connector keeps exact coordinates
+	timeZones  *timeZoneStage               // nil if the connector doesn't use local time
+	content    *contentStage                // nil if the connector doesn't filter content
+	languages  *languageStage               // nil if the connector neither detects nor translates
+	enrichment *enrichmentStage             // nil if the connector has no enrichers
+	title      *titleStage                  // nil if the connector doesn't title documents
+	minimal    bool                         // keep only the metadata of the minimal profile
+	schema     *models.MetadataSchemaConfig // nil if the connector's metadata is untyped
+}
+
+// memoryStagesFor returns the stages of a connector
+func (o *Orchestrator) memoryStagesFor(config *models.ConnectorConfig) (memoryStages, error) {
+	content, err := o.contentStageFor(config)
+	if err != nil {
+		return memoryStages{}, err
+	}
+	languages, err := o.languageStageFor(config)
+	if err != nil {
+		return memoryStages{}, err
+	}
+	enrichment, err := o.enrichmentStageFor(config)
+	if err != nil {
+		return memoryStages{}, err
+	}
+	timeZones, err := o.timeZoneStageFor(config)
+	if err != nil {
+		return memoryStages{}, err
+	}
+	title, err := o.titleStageFor(config)
+	if err != nil {
+		return memoryStages{}, err
+	}
+	stages := memoryStages{
+		location:   locationStageFor(config),
+		timeZones:  timeZones,
+		content:    content,
+		languages:  languages,
+		enrichment: enrichment,
+		title:      title,
+		minimal:    config.Transform.MetadataProfile == models.MetadataProfileMinimal,
+	}
+	if config.Transform.MetadataSchema.Enabled() {
+		stages.schema = &config.Transform.MetadataSchema
+	}
+	return stages, nil
+}
+
+// apply returns the memory to transform and the metadata the stages add to its document
+func (s memoryStages) apply(ctx context.Context, memory *models.Memory) (*models.Memory, map[string]string, error) {
+	var metadata map[string]string
+	if s.location != nil {
+		var locationMetadata map[string]string
+		memory, locationMetadata = s.location.apply(memory)
+		metadata = mergeMetadata(metadata, locationMetadata)
+	}
+	if s.timeZones != nil {
+		memory = s.timeZones.apply(ctx, memory)
+	}
+	if s.content != nil {
+		var contentMetadata map[string]string
+		var err error
+		memory, contentMetadata, err = s.content.apply(memory)
+		if err != nil {
+			return nil, nil, err
+		}
+		metadata = mergeMetadata(metadata, contentMetadata)
+	}
+	if s.languages != nil {
+		var languageMetadata map[string]string
+		var err error
+		memory, languageMetadata, err = s.languages.apply(ctx, memory)
+		if err != nil {
+			return nil, nil, err
+		}
+		metadata = mergeMetadata(metadata, languageMetadata)
+	}
+	return memory, metadata, nil
+}
+
+// enrich returns the metadata of a memory's document with the connector's enrichers applied, and
+// the lines they contribute to rich documents
+func (s memoryStages) enrich(ctx context.Context, memory *models.Memory, metadata map[string]string) (map[string]string, []string, error) {
+	if s.enrichment == nil {
+		return metadata, nil, nil
+	}
+	return s.enrichment.apply(ctx, memory, metadata)
+}
+
+// entitle returns a document with its title, if the connector titles documents
+func (s memoryStages) entitle(memory *models.Memory, text string, metadata map[string]string) (string, map[string]string, error) {
+	if s.title == nil {
+		return text, metadata, nil
+	}
+	return s.title.apply(memory, text, metadata)
+}
+
+// profile returns the metadata of a document as the connector's metadata profile keeps it
+func (s memoryStages) profile(memory *models.Memory, metadata map[string]string) map[string]string {
+	if !s.minimal {
+		return metadata
+	}
+	return minimalMetadata(memory, metadata)
+}
+
+// checkMetadata types the metadata of a document with the connector's metadata schema
+func (s memoryStages) checkMetadata(metadata map[string]string) error {
+	if s.schema == nil {
+		return nil
+	}
+	return s.schema.Apply(metadata)
+}
+
+// prefetch lets the enrichers look up the memories in a batch, as the memories apply returns, so
+// their lookups match those of enrich
+func (s memoryStages) prefetch(ctx context.Context, memories []models.Memory) {
+	if s.enrichment == nil {
+		return
+	}
+	if s.location != nil {
+		coarse := make([]models.Memory, len(memories))
+		for i := range memories {
+			memory, _ := s.location.apply(&memories[i])
+			coarse[i] = *memory
+		}
+		memories = coarse
+	}
+	s.enrichment.prefetch(ctx, memories)
+}
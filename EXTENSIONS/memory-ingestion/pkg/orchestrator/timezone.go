@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// maxTimeZoneCacheEntries bounds the cached time zones, the cache is cleared when it's full
+const maxTimeZoneCacheEntries = 4096
+
+// timeZoneStage sets the time zone at the location of memories before they're transformed, so
+// their documents are dated in local time
+type timeZoneStage struct {
+	timeZoneAt func(ctx context.Context, lat, lon float64) *time.Location
+}
+
+// SetTimeZoneResolver sets the time zone API of connectors with transform.local_time. Without it,
+// their syncs fail rather than ingest memories dated in UTC.
+func (o *Orchestrator) SetTimeZoneResolver(resolver client.TimeZoneResolver) {
+	o.timeZoneResolver = resolver
+}
+
+// timeZoneStageFor returns the time zone stage of a connector, nil if it doesn't use local time
+func (o *Orchestrator) timeZoneStageFor(config *models.ConnectorConfig) (*timeZoneStage, error) {
+	if !config.Transform.LocalTime {
+		return nil, nil
+	}
+	if o.timeZoneResolver == nil {
+		return nil, fmt.Errorf("connector %s uses local time but no time zone API is configured", config.ID)
+	}
+	return &timeZoneStage{timeZoneAt: o.timeZoneAt}, nil
+}
+
+// apply returns the memory with the time zone at its location, as it is if it has no location or
+// the time zone isn't known
+func (s *timeZoneStage) apply(ctx context.Context, memory *models.Memory) *models.Memory {
+	if !memory.HasLocation() {
+		return memory
+	}
+	location := s.timeZoneAt(ctx, *memory.LocationLat, *memory.LocationLon)
+	if location == nil {
+		return memory
+	}
+
+	localized := *memory
+	localized.LocalTimeZone = location
+	return &localized
+}
+
+// timeZoneAt returns the time zone at coordinates, looked up with the time zone API and cached
+// per ~1 km. It's nil if the API knows none, or if the lookup failed, which is logged and retried
+// for the next memory there.
+func (o *Orchestrator) timeZoneAt(ctx context.Context, lat, lon float64) *time.Location {
+	key := fmt.Sprintf("%.2f,%.2f", lat, lon)
+
+	o.timeZoneMu.Lock()
+	location, ok := o.timeZones[key]
+	o.timeZoneMu.Unlock()
+	if ok {
+		return location
+	}
+
+	name, err := o.timeZoneResolver.TimeZone(ctx, lat, lon)
+	if err != nil {
+		o.logger.Warn("Failed to look up time zone, dating memory in UTC",
+			zap.String("location", key),
+			zap.Error(err),
+		)
+		return nil
+	}
+	if name != "" {
+		if location, err = time.LoadLocation(name); err != nil {
+			o.logger.Warn("Unknown time zone, dating memory in UTC",
+				zap.String("location", key),
+				zap.String("timezone", name),
+				zap.Error(err),
+			)
+		}
+	}
+
+	o.timeZoneMu.Lock()
+	if len(o.timeZones) >= maxTimeZoneCacheEntries {
+		o.timeZones = make(map[string]*time.Location)
+	}
+	o.timeZones[key] = location
+	o.timeZoneMu.Unlock()
+	return location
+}
@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/transformer"
+)
+
+// titleStage names the documents of a connector's memories with its title template, once their
+// enrichers ran so the title can name their place
+type titleStage struct {
+	template      *transformer.TitleTemplate
+	contextID     string
+	pseudonymizer transformer.Pseudonymizer // nil if the connector keeps person names
+}
+
+// titleStageFor returns the title stage of a connector, nil if it has no title template
+func (o *Orchestrator) titleStageFor(config *models.ConnectorConfig) (*titleStage, error) {
+	if config.Transform.Title == "" {
+		return nil, nil
+	}
+	template, err := transformer.ParseTitleTemplate(config.Transform.Title)
+	if err != nil {
+		return nil, fmt.Errorf("connector %s has an invalid title template: %w", config.ID, err)
+	}
+	stage := &titleStage{template: template, contextID: config.ContextID}
+	if config.Transform.Anonymize {
+		// The title quotes the transcript, which must not leak the names the document replaces
+		if o.pseudonymizer == nil {
+			return nil, fmt.Errorf("connector %s anonymizes but no anonymization key is configured", config.ID)
+		}
+		stage.pseudonymizer = o.pseudonymizer
+	}
+	return stage, nil
+}
+
+// apply returns a document with its title as the first line, where LightRAG's document lists
+// show it, and in its title metadata
+func (s *titleStage) apply(memory *models.Memory, text string, metadata map[string]string) (string, map[string]string, error) {
+	title := s.template.Render(memory, metadata, s.contextID)
+	if title == "" {
+		return text, metadata, nil
+	}
+	if s.pseudonymizer != nil {
+		var err error
+		if title, err = s.pseudonymizer.Pseudonymize(s.contextID, title); err != nil {
+			return "", nil, fmt.Errorf("%w: failed to pseudonymize title: %w", transformer.ErrTransformFailed, err)
+		}
+	}
+	return title + "\n\n" + text, mergeMetadata(metadata, map[string]string{"title": title}), nil
+}
@@ -0,0 +1,113 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/trips"
+	"github.com/kamir/memory-connector/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// maxPlaceCacheEntries bounds the cached place names, the cache is cleared when it's full
+const maxPlaceCacheEntries = 1024
+
+// SetGeocoder sets the reverse geocoding API naming the places of trips. Without it, trips are
+// named after their coordinates.
+func (o *Orchestrator) SetGeocoder(geocoder client.Geocoder) {
+	o.geocoder = geocoder
+}
+
+// detectTrips returns the trip of each new memory that belongs to one, nil if the connector doesn't
+// detect trips. The location history the trips are detected against is kept in the sync state.
+func (o *Orchestrator) detectTrips(ctx context.Context, config *models.ConnectorConfig, memories []models.Memory, syncState *models.SyncState) map[string]trips.Membership {
+	if !config.Transform.Trips.Enabled {
+		return nil
+	}
+	if syncState.Trips == nil {
+		syncState.Trips = &models.TripState{}
+	}
+
+	detected := trips.Detect(config.ContextID, memories, syncState.Trips, trips.Config{
+		MinDistanceKm: config.Transform.Trips.MinDistanceKm,
+		MaxGap:        time.Duration(config.Transform.Trips.MaxGapHours) * time.Hour,
+		MinVisits:     config.Transform.Trips.MinVisits,
+	}, func(lat, lon float64) string {
+		// Trip names enter documents, so they're no more precise than the connector's locations
+		if location := locationStageFor(config); location != nil {
+			lat, lon = location.coarsen(lat, lon)
+		}
+		return o.PlaceName(ctx, lat, lon)
+	})
+
+	o.logger.Debug("Detected trips",
+		zap.String("connector_id", config.ID),
+		zap.Int("memories_on_trips", len(detected)),
+	)
+
+	return detected
+}
+
+// PlaceName names the place at coordinates with the geocoder, falling back to the coordinates
+func (o *Orchestrator) PlaceName(ctx context.Context, lat, lon float64) string {
+	fallback := trips.CoordinatesPlace(lat, lon)
+	if o.geocoder == nil {
+		return fallback
+	}
+
+	o.placeMu.Lock()
+	place, ok := o.places[fallback]
+	o.placeMu.Unlock()
+	if ok {
+		return place
+	}
+
+	place, err := o.geocoder.ReverseGeocode(ctx, lat, lon)
+	if err != nil {
+		o.logger.Warn("Failed to name location, using its coordinates",
+			zap.String("location", fallback),
+			zap.Error(err),
+		)
+		return fallback
+	}
+	if place == "" {
+		place = fallback
+	}
+
+	o.placeMu.Lock()
+	if len(o.places) >= maxPlaceCacheEntries {
+		o.places = make(map[string]string)
+	}
+	o.places[fallback] = place
+	o.placeMu.Unlock()
+	return place
+}
+
+// tripReference returns the note on a memory's trip appended to its document, and the metadata
+// linking it to the trip
+func tripReference(trip *trips.Membership) (string, map[string]string) {
+	text := fmt.Sprintf("\n\n[Recorded on trip %s to %s", trip.TripID, trip.Place)
+	if len(trip.Nearby) > 0 {
+		nearby := make([]string, len(trip.Nearby))
+		for i, memoryID := range trip.Nearby {
+			nearby[i] = utils.MemoryURI(memoryID)
+		}
+		text += ". Nearby memories of the trip: " + strings.Join(nearby, "; ")
+	}
+	text += "]"
+
+	metadata := map[string]string{
+		"trip_id":    trip.TripID,
+		"trip_place": trip.Place,
+	}
+	if trip.DistanceKm > 0 {
+		metadata["trip_distance_km"] = strconv.FormatFloat(trip.DistanceKm, 'f', 1, 64)
+		metadata["trip_bearing_deg"] = strconv.FormatFloat(trip.BearingDeg, 'f', 0, 64)
+	}
+	return text, metadata
+}
@@ -0,0 +1,296 @@
+// Package places clusters the locations of memories into frequent places (DBSCAN) and infers what
+// they are, e.g. home or work
+package places
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+)
+
+// Inferred labels of places; the others are labelled "frequent place N" by size
+const (
+	LabelHome = "home" // the place with the most memories recorded at night
+	LabelWork = "work" // the place, other than home, with mostly memories recorded during working hours
+)
+
+const (
+	// maxHistory bounds the recorded locations, the oldest are dropped first
+	maxHistory = 10000
+
+	// metersPerDegree is the length of a degree of latitude
+	metersPerDegree = 111320.0
+
+	// minLabelMemories is the number of memories at night (working hours) a place needs to be home (work)
+	minLabelMemories = 3
+)
+
+// Config holds the clustering settings
+type Config struct {
+	RadiusM     float64        // neighborhood of a location (DBSCAN eps)
+	MinMemories int            // memories within the radius that make a place (DBSCAN minPts)
+	Location    *time.Location // of the hours labels are inferred from, defaults to UTC
+}
+
+// Record adds the located memories to the location history of state
+func Record(state *models.PlaceState, memories []models.Memory) {
+	for i := range memories {
+		memory := &memories[i]
+		if !memory.HasLocation() {
+			continue
+		}
+		createdAt, err := memory.ParseCreatedAt()
+		if err != nil {
+			continue
+		}
+		if state.History == nil {
+			state.History = make(map[string]models.PlacePoint)
+		}
+		state.History[memory.ID] = models.PlacePoint{Lat: *memory.LocationLat, Lon: *memory.LocationLon, At: createdAt}
+	}
+
+	if len(state.History) <= maxHistory {
+		return
+	}
+	ids := make([]string, 0, len(state.History))
+	for id := range state.History {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return state.History[ids[i]].At.Before(state.History[ids[j]].At) })
+	for _, id := range ids[:len(ids)-maxHistory] {
+		delete(state.History, id)
+	}
+}
+
+// Cluster clusters the location history with DBSCAN and returns the frequent places, largest
+// first. Places whose centroid lies within a previous place keep its ID, so the IDs of documents
+// already ingested stay valid; new places get IDs derived from the context and their centroid.
+func Cluster(contextID string, history map[string]models.PlacePoint, previous []models.PlaceCluster, config Config) []models.PlaceCluster {
+	location := config.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	// Sort for deterministic clusters
+	ids := make([]string, 0, len(history))
+	for id := range history {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	points := make([]models.PlacePoint, len(ids))
+	for i, id := range ids {
+		points[i] = history[id]
+	}
+
+	labels := dbscan(points, config.RadiusM, config.MinMemories)
+
+	type summary struct {
+		cluster     models.PlaceCluster
+		members     []int
+		x, y, z     float64 // sum of the members' unit vectors
+		night, work int
+	}
+	summaries := make(map[int]*summary)
+	for i, label := range labels {
+		if label <= 0 {
+			continue
+		}
+		s := summaries[label]
+		if s == nil {
+			s = &summary{}
+			summaries[label] = s
+		}
+		s.members = append(s.members, i)
+		x, y, z := unitVector(points[i].Lat, points[i].Lon)
+		s.x, s.y, s.z = s.x+x, s.y+y, s.z+z
+
+		at := points[i].At.In(location)
+		hour := at.Hour()
+		if hour >= 22 || hour < 6 {
+			s.night++
+		} else if at.Weekday() != time.Saturday && at.Weekday() != time.Sunday && hour >= 9 && hour < 17 {
+			s.work++
+		}
+	}
+
+	ordered := make([]*summary, 0, len(summaries))
+	for _, s := range summaries {
+		s.cluster.Memories = len(s.members)
+		// The centroid is the direction of the members' mean vector, which averages longitudes
+		// correctly across the antimeridian
+		s.cluster.Lat = math.Atan2(s.z, math.Hypot(s.x, s.y)) * 180 / math.Pi
+		s.cluster.Lon = math.Atan2(s.y, s.x) * 180 / math.Pi
+		s.cluster.RadiusM = config.RadiusM
+		for _, i := range s.members {
+			if distance := distanceM(s.cluster.Lat, s.cluster.Lon, points[i].Lat, points[i].Lon); distance > s.cluster.RadiusM {
+				s.cluster.RadiusM = distance
+			}
+		}
+		s.cluster.RadiusM = math.Round(s.cluster.RadiusM)
+		ordered = append(ordered, s)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].cluster.Memories != ordered[j].cluster.Memories {
+			return ordered[i].cluster.Memories > ordered[j].cluster.Memories
+		}
+		return ordered[i].members[0] < ordered[j].members[0]
+	})
+
+	// Infer home and work from when the memories were recorded
+	home, work := -1, -1
+	for i, s := range ordered {
+		if s.night >= minLabelMemories && (home < 0 || s.night > ordered[home].night) {
+			home = i
+		}
+	}
+	for i, s := range ordered {
+		if i != home && s.work >= minLabelMemories && 2*s.work >= len(s.members) && (work < 0 || s.work > ordered[work].work) {
+			work = i
+		}
+	}
+
+	clusters := make([]models.PlaceCluster, len(ordered))
+	used := make(map[string]bool)
+	for i, s := range ordered {
+		cluster := s.cluster
+		switch i {
+		case home:
+			cluster.Label = LabelHome
+		case work:
+			cluster.Label = LabelWork
+		default:
+			cluster.Label = fmt.Sprintf("frequent place %d", i+1)
+		}
+		cluster.ID = placeID(contextID, cluster.Lat, cluster.Lon)
+		for _, prev := range previous {
+			if !used[prev.ID] && distanceM(prev.Lat, prev.Lon, cluster.Lat, cluster.Lon) <= math.Max(prev.RadiusM, cluster.RadiusM) {
+				cluster.ID = prev.ID
+				break
+			}
+		}
+		used[cluster.ID] = true
+		clusters[i] = cluster
+	}
+	return clusters
+}
+
+// Match returns the place at coordinates, the nearest cluster within its radius; nil if there's none
+func Match(clusters []models.PlaceCluster, lat, lon float64) *models.PlaceCluster {
+	var nearest *models.PlaceCluster
+	nearestDistance := math.Inf(1)
+	for i := range clusters {
+		distance := distanceM(clusters[i].Lat, clusters[i].Lon, lat, lon)
+		if distance <= clusters[i].RadiusM && distance < nearestDistance {
+			nearest, nearestDistance = &clusters[i], distance
+		}
+	}
+	return nearest
+}
+
+// dbscan returns the cluster of each point, numbered from 1; noise is -1
+func dbscan(points []models.PlacePoint, radiusM float64, minPoints int) []int {
+	// Index the points in a grid of radius-sized cells, so neighbors are found in adjacent cells.
+	// Longitude cells divide the circle evenly, so the cells at -180° and 180° are adjacent.
+	latCellDegrees := radiusM / metersPerDegree
+	lonCellCount := int(math.Ceil(360 / latCellDegrees))
+	lonCellDegrees := 360 / float64(lonCellCount)
+	type cell struct{ lat, lon int }
+	cellOf := func(p models.PlacePoint) cell {
+		lon := math.Mod(p.Lon+180, 360)
+		if lon < 0 {
+			lon += 360
+		}
+		return cell{int(math.Floor(p.Lat / latCellDegrees)), int(lon/lonCellDegrees) % lonCellCount}
+	}
+	grid := make(map[cell][]int)
+	for i, p := range points {
+		grid[cellOf(p)] = append(grid[cellOf(p)], i)
+	}
+
+	neighbors := func(i int) []int {
+		var found []int
+		add := func(j int) {
+			if distanceM(points[i].Lat, points[i].Lon, points[j].Lat, points[j].Lon) <= radiusM {
+				found = append(found, j)
+			}
+		}
+
+		// Degrees of longitude shrink towards the poles, the most at the latitude of the
+		// neighborhood nearest to one. A neighborhood containing a pole, or spanning all
+		// longitude cells, may hold points of any longitude.
+		nearestPole := math.Abs(points[i].Lat) + latCellDegrees
+		lonCells := lonCellCount
+		if nearestPole < 90 {
+			lonCells = int(math.Ceil(latCellDegrees / math.Cos(nearestPole*math.Pi/180) / lonCellDegrees))
+		}
+		if 2*lonCells+1 >= lonCellCount {
+			for j := range points {
+				add(j)
+			}
+			return found
+		}
+
+		center := cellOf(points[i])
+		for dLat := -1; dLat <= 1; dLat++ {
+			for dLon := -lonCells; dLon <= lonCells; dLon++ {
+				lon := ((center.lon+dLon)%lonCellCount + lonCellCount) % lonCellCount
+				for _, j := range grid[cell{center.lat + dLat, lon}] {
+					add(j)
+				}
+			}
+		}
+		return found
+	}
+
+	labels := make([]int, len(points))
+	cluster := 0
+	for i := range points {
+		if labels[i] != 0 {
+			continue
+		}
+		queue := neighbors(i)
+		if len(queue) < minPoints {
+			labels[i] = -1
+			continue
+		}
+		cluster++
+		labels[i] = cluster
+		for k := 0; k < len(queue); k++ {
+			j := queue[k]
+			if labels[j] == -1 {
+				labels[j] = cluster // border point
+			}
+			if labels[j] != 0 {
+				continue
+			}
+			labels[j] = cluster
+			if more := neighbors(j); len(more) >= minPoints {
+				queue = append(queue, more...)
+			}
+		}
+	}
+	return labels
+}
+
+// unitVector returns the point of coordinates on the unit sphere
+func unitVector(lat, lon float64) (float64, float64, float64) {
+	phi, theta := lat*math.Pi/180, lon*math.Pi/180
+	return math.Cos(phi) * math.Cos(theta), math.Cos(phi) * math.Sin(theta), math.Sin(phi)
+}
+
+// placeID derives the ID of a new place from its context and centroid
+func placeID(contextID string, lat, lon float64) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%.4f,%.4f", contextID, lat, lon)))
+	return "place-" + hex.EncodeToString(sum[:6])
+}
+
+// distanceM returns the distance between two coordinates in meters
+func distanceM(lat1, lon1, lat2, lon2 float64) float64 {
+	return utils.DistanceKm(lat1, lon1, lat2, lon2) * 1000
+}
@@ -0,0 +1,187 @@
+// Package reporting sends panics recovered in API handlers and scheduler goroutines to an error
+// tracker, with tags such as the connector they occurred for. Events are posted to Sentry's store
+// endpoint, so any Sentry-compatible service (Sentry, GlitchTip, ...) can receive them.
+package reporting
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// sendTimeout bounds the delivery of one event
+const sendTimeout = 10 * time.Second
+
+// Reporter receives recovered panics
+type Reporter interface {
+	// CapturePanic reports a panic recovered with the given value. stack is the program counters of
+	// the panicking goroutine (see Callers); tags describe what was running, e.g. connector_id.
+	CapturePanic(recovered interface{}, stack []uintptr, tags map[string]string)
+}
+
+// Nop is a Reporter that discards panics, used when error reporting is disabled
+type Nop struct{}
+
+// CapturePanic discards the panic
+func (Nop) CapturePanic(interface{}, []uintptr, map[string]string) {}
+
+// Callers returns the program counters of the calling goroutine's stack. Called in a deferred
+// recover, the stack still includes the frames that panicked.
+func Callers() []uintptr {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	return pcs[:n]
+}
+
+// Config holds error reporting settings
+type Config struct {
+	DSN         string // Sentry DSN, e.g. https://<key>@o0.ingest.sentry.io/<project>
+	Environment string // e.g. production, staging (optional)
+	Release     string // e.g. memory-connector@0.1.0 (optional)
+}
+
+// SentryReporter posts panics to a Sentry-compatible store endpoint in the background
+type SentryReporter struct {
+	dsn        *DSN
+	config     Config
+	serverName string
+	sender     *sender
+	logger     *zap.Logger
+	pending    sync.WaitGroup
+}
+
+// NewSentryReporter creates a reporter sending to the project of config.DSN
+func NewSentryReporter(config Config, logger *zap.Logger) (*SentryReporter, error) {
+	dsn, err := ParseDSN(config.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	serverName, _ := os.Hostname()
+	return &SentryReporter{
+		dsn:        dsn,
+		config:     config,
+		serverName: serverName,
+		sender:     newSender(dsn),
+		logger:     logger,
+	}, nil
+}
+
+// CapturePanic sends the panic in the background
+func (r *SentryReporter) CapturePanic(recovered interface{}, stack []uintptr, tags map[string]string) {
+	event := r.newEvent(recovered, stack, tags)
+
+	r.pending.Add(1)
+	go func() {
+		defer r.pending.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+
+		if err := r.sender.send(ctx, event); err != nil {
+			r.logger.Error("Failed to report panic",
+				zap.String("event_id", event.EventID),
+				zap.Error(err),
+			)
+			return
+		}
+		r.logger.Info("Reported panic", zap.String("event_id", event.EventID))
+	}()
+}
+
+// Wait blocks until events in delivery are sent or ctx is done
+func (r *SentryReporter) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newEvent builds the Sentry event of a panic
+func (r *SentryReporter) newEvent(recovered interface{}, stack []uintptr, tags map[string]string) *event {
+	value := fmt.Sprint(recovered)
+	if err, ok := recovered.(error); ok {
+		value = err.Error()
+	}
+
+	return &event{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Level:       "fatal",
+		Platform:    "go",
+		Logger:      "memory-connector",
+		ServerName:  r.serverName,
+		Environment: r.config.Environment,
+		Release:     r.config.Release,
+		Tags:        tags,
+		Exception: &exceptions{Values: []exception{{
+			Type:       fmt.Sprintf("panic (%T)", recovered),
+			Value:      value,
+			Stacktrace: &stacktrace{Frames: frames(stack)},
+		}}},
+	}
+}
+
+// frames converts program counters to Sentry frames, outermost first. Frames of the recovering
+// function (up to runtime.gopanic) are dropped, so the last frame is the one that panicked.
+func frames(stack []uintptr) []frame {
+	var result []frame
+	callers := runtime.CallersFrames(stack)
+	for {
+		caller, more := callers.Next()
+		if caller.Function == "runtime.gopanic" {
+			result = result[:0]
+		} else if caller.Function != "" {
+			result = append(result, frame{
+				Function: caller.Function,
+				Module:   packageName(caller.Function),
+				Filename: caller.File,
+				AbsPath:  caller.File,
+				Lineno:   caller.Line,
+				InApp:    strings.HasPrefix(caller.Function, "github.com/kamir/memory-connector/"),
+			})
+		}
+		if !more {
+			break
+		}
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// packageName returns the package path of a fully qualified function name
+func packageName(function string) string {
+	lastSlash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[lastSlash+1:], "."); dot >= 0 {
+		return function[:lastSlash+1+dot]
+	}
+	return function
+}
+
+// newEventID returns a random 32 character hex event ID
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
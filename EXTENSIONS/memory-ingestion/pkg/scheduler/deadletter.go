@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// DeadLetterRetryConfig holds the automatic retries of connectors' Dead Letter Queues
+type DeadLetterRetryConfig struct {
+	Interval    time.Duration // how often the queues are checked for memories whose retry is due
+	MaxMemories int           // latest memories of a connector searched for its due ones
+}
+
+// SetDeadLetterRetry retries the due memories of connectors' Dead Letter Queues, checking every
+// interval independent of the connectors' schedules. Must be called before Start.
+func (s *Scheduler) SetDeadLetterRetry(config DeadLetterRetryConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deadLetters = config
+}
+
+// retryDeadLettersLoop retries the due memories of enabled connectors every interval until the
+// scheduler stops
+func (s *Scheduler) retryDeadLettersLoop(config DeadLetterRetryConfig) {
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.RLock()
+		connectors := make([]models.ConnectorConfig, 0, len(s.configs))
+		for _, connector := range s.configs {
+			if connector.Enabled && connector.Transform.Mode != models.TransformModeDailyDigest {
+				connectors = append(connectors, connector)
+			}
+		}
+		s.mu.RUnlock()
+		sort.Slice(connectors, func(i, j int) bool {
+			return connectors[i].ID < connectors[j].ID
+		})
+
+		for i := range connectors {
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.retryDeadLetters(&connectors[i], config.MaxMemories)
+		}
+	}
+}
+
+// retryDeadLetters retries the due memories of a connector's Dead Letter Queue. Connectors that are
+// syncing, paused, out of quota, or whose upstream services are unavailable are retried in a later
+// round, without using up attempts.
+func (s *Scheduler) retryDeadLetters(config *models.ConnectorConfig, limit int) {
+	if err := s.markRunning(config.ID); err != nil {
+		return
+	}
+	defer s.markDone(config.ID)
+
+	ctx, done, err := s.syncContext(config.ID)
+	if err != nil {
+		return
+	}
+	defer done()
+
+	due, err := s.orchestrator.DueDeadLetters(ctx, config.ID)
+	if err != nil {
+		s.logger.Error("Failed to check dead letters",
+			zap.String("connector_id", config.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	if due == 0 || s.CheckQuota(config) != nil {
+		return
+	}
+
+	if err := s.waitForSlot(ctx, config, "dead_letter"); err != nil {
+		return
+	}
+	defer s.queue.release()
+
+	if err := s.checkUpstreams(ctx, config); err != nil {
+		s.logger.Info("Postponing dead letter retries, upstream unavailable",
+			zap.String("connector_id", config.ID),
+			zap.Int("due", due),
+			zap.Error(err),
+		)
+		return
+	}
+
+	if _, err := s.orchestrator.RetryDeadLetters(ctx, config, limit); err != nil {
+		s.logger.Error("Failed to retry dead letters",
+			zap.String("connector_id", config.ID),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,164 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/events"
+	"go.uber.org/zap"
+)
+
+// ErrPaused is returned when a sync is requested for a connector paused by an operator. It's also
+// the cause of the context of a sync stopped by a pause.
+var ErrPaused = errors.New("connector is paused")
+
+// Pause pauses a connector: scheduled and triggered syncs are refused until Resume, and a running
+// sync stops after its in-flight memories and checkpoints its state, so that the resumed connector
+// continues without duplicates or gaps. The pause is persisted and survives restarts. It returns
+// true if a running (or queued) sync is stopping.
+func (s *Scheduler) Pause(connectorID string) bool {
+	s.mu.Lock()
+	_, alreadyPaused := s.paused[connectorID]
+	if !alreadyPaused {
+		s.paused[connectorID] = time.Now()
+	}
+	cancel, stopping := s.cancels[connectorID]
+	s.mu.Unlock()
+
+	if stopping {
+		cancel(ErrPaused)
+	}
+	if alreadyPaused {
+		return stopping
+	}
+
+	s.logger.Info("Paused connector",
+		zap.String("connector_id", connectorID),
+		zap.Bool("stopping_sync", stopping),
+	)
+	s.persistPause(connectorID)
+	s.publish(events.TypeConnectorPaused, connectorID, map[string]interface{}{
+		"stopping_sync": stopping,
+	})
+
+	return stopping
+}
+
+// Resume lifts the pause of a connector. It returns false if the connector wasn't paused.
+func (s *Scheduler) Resume(connectorID string) bool {
+	s.mu.Lock()
+	_, paused := s.paused[connectorID]
+	delete(s.paused, connectorID)
+	s.mu.Unlock()
+
+	if !paused {
+		return false
+	}
+
+	s.logger.Info("Resumed connector", zap.String("connector_id", connectorID))
+	s.persistPause(connectorID)
+	s.publish(events.TypeConnectorResumed, connectorID, nil)
+
+	return true
+}
+
+// PausedAt returns when a connector was paused, nil if it isn't paused
+func (s *Scheduler) PausedAt(connectorID string) *time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pausedAt, ok := s.paused[connectorID]
+	if !ok {
+		return nil
+	}
+	return &pausedAt
+}
+
+// loadPause restores the pause of a connector from its state
+func (s *Scheduler) loadPause(connectorID string) {
+	pausedAt, err := s.orchestrator.PausedAt(s.ctx, connectorID)
+	if err != nil {
+		s.logger.Warn("Failed to load pause state",
+			zap.String("connector_id", connectorID),
+			zap.Error(err),
+		)
+		return
+	}
+	if pausedAt == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.paused[connectorID] = *pausedAt
+	s.mu.Unlock()
+
+	s.logger.Info("Connector is paused",
+		zap.String("connector_id", connectorID),
+		zap.Time("paused_at", *pausedAt),
+	)
+}
+
+// syncContext returns the context of a new sync of a connector, which Pause cancels, and a function
+// releasing it. It fails with ErrPaused if the connector is paused.
+func (s *Scheduler) syncContext(connectorID string) (context.Context, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pausedAt, paused := s.paused[connectorID]; paused {
+		return nil, nil, fmt.Errorf("%w since %s", ErrPaused, pausedAt.Format(time.RFC3339))
+	}
+
+	ctx, cancel := context.WithCancelCause(s.ctx)
+	s.cancels[connectorID] = cancel
+
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.cancels, connectorID)
+		s.mu.Unlock()
+		cancel(nil)
+	}, nil
+}
+
+// persistPause saves the pause state of a connector. A running sync saves its own copy of the state
+// when it ends, so then the pause is saved once the sync finished (see release).
+func (s *Scheduler) persistPause(connectorID string) {
+	s.mu.Lock()
+	s.pauseDirty[connectorID] = true
+	if s.running[connectorID] {
+		s.mu.Unlock()
+		return
+	}
+	// Hold the connector while saving, so no sync starts from a stale state meanwhile
+	s.running[connectorID] = true
+	s.mu.Unlock()
+
+	s.release(connectorID)
+}
+
+// release clears the syncing mark of a connector, first saving any pause state changed while it was held
+func (s *Scheduler) release(connectorID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.pauseDirty[connectorID] {
+		delete(s.pauseDirty, connectorID)
+		var pausedAt *time.Time
+		if at, paused := s.paused[connectorID]; paused {
+			pausedAt = &at
+		}
+		s.mu.Unlock()
+
+		if err := s.orchestrator.SetPausedAt(context.Background(), connectorID, pausedAt); err != nil {
+			s.logger.Error("Failed to save pause state",
+				zap.String("connector_id", connectorID),
+				zap.Error(err),
+			)
+		}
+
+		s.mu.Lock()
+	}
+
+	delete(s.running, connectorID)
+}
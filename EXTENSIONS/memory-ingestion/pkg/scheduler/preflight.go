@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// PreflightConfig holds the checks of a connector's upstream services before each sync
+type PreflightConfig struct {
+	Timeout       time.Duration // longest the checks may take, 0 disables them
+	RetryAfter    time.Duration // delay of the early retry of a scheduled sync skipped by its checks, 0 for none
+	MaxRetryAfter time.Duration // the delay doubles with each consecutive skip up to this, 0 keeps it fixed
+}
+
+// upstreamRetry tracks the syncs of a connector skipped because an upstream service didn't answer
+type upstreamRetry struct {
+	skips int         // consecutive scheduled syncs skipped
+	timer *time.Timer // runs the pending early retry, nil if there's none
+	at    time.Time   // when the pending early retry runs
+}
+
+// SetPreflight checks that a connector's Memory API and LightRAG answer before each sync. Syncs
+// whose checks fail are skipped with status upstream_unavailable, instead of spending the retries
+// of every memory on a service that is down; scheduled ones are retried before their next run.
+func (s *Scheduler) SetPreflight(config PreflightConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.preflight = config
+}
+
+// checkUpstreams runs the preflight checks of a connector
+func (s *Scheduler) checkUpstreams(ctx context.Context, config *models.ConnectorConfig) error {
+	s.mu.RLock()
+	timeout := s.preflight.Timeout
+	s.mu.RUnlock()
+
+	if timeout <= 0 {
+		return nil
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return s.orchestrator.Preflight(checkCtx, config)
+}
+
+// retryEarly schedules an early retry of a scheduled sync skipped by its preflight checks, after a
+// delay doubling with each consecutive skip. It returns when the retry runs, zero if there's none:
+// for manual syncs, and if the connector's next scheduled run comes first.
+func (s *Scheduler) retryEarly(connectorID, trigger string) time.Time {
+	if trigger == "manual" {
+		return time.Time{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entryID, scheduled := s.jobs[connectorID]
+	if !scheduled || s.preflight.RetryAfter <= 0 || s.ctx.Err() != nil {
+		return time.Time{}
+	}
+
+	retry := s.retries[connectorID]
+	if retry == nil {
+		retry = &upstreamRetry{}
+		s.retries[connectorID] = retry
+	}
+	retry.skips++
+
+	delay := s.preflight.RetryAfter
+	for i := 1; i < retry.skips && delay < s.preflight.MaxRetryAfter; i++ {
+		delay *= 2
+	}
+	if s.preflight.MaxRetryAfter > 0 && delay > s.preflight.MaxRetryAfter {
+		delay = s.preflight.MaxRetryAfter
+	}
+
+	at := time.Now().Add(delay)
+	config := s.configs[connectorID]
+	if next := nextRunInWindow(s.cron.Entry(entryID), &config.Schedule); !next.IsZero() && !next.After(at) {
+		return time.Time{}
+	}
+
+	if retry.timer != nil {
+		retry.timer.Stop()
+	}
+	retry.at = at
+	retry.timer = time.AfterFunc(delay, func() {
+		s.runRetry(connectorID, at)
+	})
+	return at
+}
+
+// runRetry runs the early retry due at at, unless it was dropped or replaced meanwhile
+func (s *Scheduler) runRetry(connectorID string, at time.Time) {
+	s.mu.Lock()
+	retry := s.retries[connectorID]
+	if retry == nil || !retry.at.Equal(at) {
+		s.mu.Unlock()
+		return
+	}
+	retry.timer = nil
+	retry.at = time.Time{}
+	config, ok := s.configs[connectorID]
+	_, scheduled := s.jobs[connectorID]
+	s.mu.Unlock()
+
+	if !ok || !scheduled {
+		return
+	}
+	if !config.Schedule.InWindow(time.Now()) {
+		// The next scheduled run inside the windows covers it
+		s.logger.Info("Skipping early retry outside execution windows",
+			zap.String("connector_id", connectorID),
+		)
+		return
+	}
+	s.runSync(&config, "retry")
+}
+
+// pendingRetry returns when the early retry of a connector runs, zero if none is pending. Called
+// with s.mu held.
+func (s *Scheduler) pendingRetry(connectorID string) time.Time {
+	if retry := s.retries[connectorID]; retry != nil && retry.timer != nil {
+		return retry.at
+	}
+	return time.Time{}
+}
+
+// dropRetry cancels the pending early retry of a connector and resets its delay. Called with s.mu held.
+func (s *Scheduler) dropRetry(connectorID string) {
+	if retry := s.retries[connectorID]; retry != nil && retry.timer != nil {
+		retry.timer.Stop()
+	}
+	delete(s.retries, connectorID)
+}
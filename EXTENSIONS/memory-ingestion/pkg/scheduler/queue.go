@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// syncQueue limits how many syncs run at once. Syncs over the limit wait in a queue and are
+// admitted by connector priority (highest first), then in arrival order.
+type syncQueue struct {
+	mu      sync.Mutex
+	limit   int // 0 means unlimited
+	active  int
+	waiting []*queuedSync // sorted in admission order
+	seq     uint64
+}
+
+// queuedSync is a sync waiting for a slot
+type queuedSync struct {
+	connectorID string
+	priority    int
+	seq         uint64
+	admitted    chan struct{}
+}
+
+// setLimit changes the number of syncs that may run at once and admits waiting syncs that now fit
+func (q *syncQueue) setLimit(limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.limit = limit
+	for len(q.waiting) > 0 && q.fits() {
+		q.admitNext()
+	}
+}
+
+// acquire blocks until the sync may run or ctx is done. queued is called (outside the lock) with
+// the queue position if the sync has to wait. Every successful acquire must be paired with release.
+func (q *syncQueue) acquire(ctx context.Context, connectorID string, priority int, queued func(position int)) error {
+	q.mu.Lock()
+	if q.fits() {
+		q.active++
+		q.mu.Unlock()
+		return nil
+	}
+
+	q.seq++
+	waiter := &queuedSync{
+		connectorID: connectorID,
+		priority:    priority,
+		seq:         q.seq,
+		admitted:    make(chan struct{}),
+	}
+	q.waiting = append(q.waiting, waiter)
+	sort.SliceStable(q.waiting, func(i, j int) bool {
+		if q.waiting[i].priority != q.waiting[j].priority {
+			return q.waiting[i].priority > q.waiting[j].priority
+		}
+		return q.waiting[i].seq < q.waiting[j].seq
+	})
+	position := q.positionLocked(connectorID)
+	q.mu.Unlock()
+
+	if queued != nil {
+		queued(position)
+	}
+
+	select {
+	case <-waiter.admitted:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	for i, w := range q.waiting {
+		if w == waiter {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			q.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+	q.mu.Unlock()
+
+	// Admitted while ctx was being cancelled: hand the slot on
+	q.release()
+	return ctx.Err()
+}
+
+// release frees the slot of a finished sync, handing it to the next waiting sync if any
+func (q *syncQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.active--
+	if len(q.waiting) > 0 && q.fits() {
+		q.admitNext()
+	}
+}
+
+// position returns the 1-based queue position of a connector's waiting sync, 0 if it isn't waiting
+func (q *syncQueue) position(connectorID string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.positionLocked(connectorID)
+}
+
+// positionLocked is position with q.mu held
+func (q *syncQueue) positionLocked(connectorID string) int {
+	for i, w := range q.waiting {
+		if w.connectorID == connectorID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// fits returns true if another sync may start. Must be called with q.mu held.
+func (q *syncQueue) fits() bool {
+	return q.limit <= 0 || q.active < q.limit
+}
+
+// admitNext starts the first waiting sync. Must be called with q.mu held.
+func (q *syncQueue) admitNext() {
+	next := q.waiting[0]
+	q.waiting = q.waiting[1:]
+	q.active++
+	close(next.admitted)
+}
@@ -2,22 +2,57 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"reflect"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/events"
+	"github.com/kamir/memory-connector/pkg/export"
 	"github.com/kamir/memory-connector/pkg/models"
 	"github.com/kamir/memory-connector/pkg/orchestrator"
+	"github.com/kamir/memory-connector/pkg/reporting"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrSyncRunning is returned when a sync is requested for a connector that is already syncing
+	ErrSyncRunning = errors.New("sync already running")
+
+	// ErrShuttingDown is returned when a sync is requested after Shutdown was called
+	ErrShuttingDown = errors.New("scheduler is shutting down")
+
+	// ErrQuotaExhausted is returned when a sync is requested for a connector whose daily quota is used up
+	ErrQuotaExhausted = errors.New("daily quota exhausted")
+)
+
 // Scheduler manages scheduled sync jobs
 type Scheduler struct {
 	cron         *cron.Cron
 	orchestrator *orchestrator.Orchestrator
 	logger       *zap.Logger
-	jobs         map[string]cron.EntryID // connector ID -> cron entry ID
+	jobs         map[string]cron.EntryID            // connector ID -> cron entry ID
+	configs      map[string]models.ConnectorConfig  // connector ID -> config last applied
+	running      map[string]bool                    // connector IDs with a sync in progress
+	cancels      map[string]context.CancelCauseFunc // connector ID -> cancels its running sync
+	paused       map[string]time.Time               // connector ID -> when an operator paused it
+	pauseDirty   map[string]bool                    // connector IDs whose pause state awaits saving
+	syncs        sync.WaitGroup                     // scheduled and manually triggered syncs in progress
+	events       events.Publisher                   // optional, receives connector and sync events
+	jitter       time.Duration                      // maximum random delay before a scheduled sync
+	stagger      bool                               // spread interval connectors' start times by connector ID
+	queue        *syncQueue                         // limits how many syncs run at once
+	reporter     reporting.Reporter                 // receives panics of syncs
+	preflight    PreflightConfig                    // checks of upstream services before each sync
+	retries      map[string]*upstreamRetry          // connector ID -> syncs skipped by their preflight checks
+	deadLetters  DeadLetterRetryConfig              // retries of Dead Letter Queues, off if Interval is 0
 	mu           sync.RWMutex
 	ctx          context.Context
 	cancel       context.CancelFunc
@@ -32,37 +67,145 @@ func NewScheduler(orchestrator *orchestrator.Orchestrator, logger *zap.Logger) *
 		orchestrator: orchestrator,
 		logger:       logger,
 		jobs:         make(map[string]cron.EntryID),
+		configs:      make(map[string]models.ConnectorConfig),
+		running:      make(map[string]bool),
+		cancels:      make(map[string]context.CancelCauseFunc),
+		paused:       make(map[string]time.Time),
+		pauseDirty:   make(map[string]bool),
+		queue:        &syncQueue{},
+		reporter:     reporting.Nop{},
+		retries:      make(map[string]*upstreamRetry),
 		ctx:          ctx,
 		cancel:       cancel,
 	}
 }
 
+// SetEventPublisher makes the scheduler publish connector lifecycle and sync events
+func (s *Scheduler) SetEventPublisher(publisher events.Publisher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = publisher
+}
+
+// SetJitter delays every scheduled sync by a random duration up to max, so connectors sharing
+// a schedule don't hit the Memory API and LightRAG at the same instant. Applies to connectors added afterwards.
+func (s *Scheduler) SetJitter(max time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jitter = max
+}
+
+// SetStagger spreads the start times of interval connectors over their interval, using a fixed
+// offset derived from the connector ID. Applies to connectors added afterwards.
+func (s *Scheduler) SetStagger(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stagger = enabled
+}
+
+// SetMaxConcurrentSyncs limits how many syncs (scheduled or triggered) run at once; 0 means unlimited.
+// Syncs over the limit wait in a queue ordered by connector priority.
+func (s *Scheduler) SetMaxConcurrentSyncs(limit int) {
+	s.queue.setLimit(limit)
+}
+
+// SetErrorReporter makes the scheduler report panics of syncs, tagged with the connector, to reporter
+func (s *Scheduler) SetErrorReporter(reporter reporting.Reporter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reporter = reporter
+}
+
+// publish publishes an event if an event publisher is set. Must not be called with s.mu held.
+func (s *Scheduler) publish(eventType, connectorID string, data interface{}) {
+	s.mu.RLock()
+	publisher := s.events
+	s.mu.RUnlock()
+
+	if publisher != nil {
+		publisher.Publish(events.Event{Type: eventType, ConnectorID: connectorID, Data: data})
+	}
+}
+
 // Start starts the scheduler
 func (s *Scheduler) Start() {
 	s.cron.Start()
+
+	s.mu.RLock()
+	deadLetters := s.deadLetters
+	s.mu.RUnlock()
+	if deadLetters.Interval > 0 {
+		go s.retryDeadLettersLoop(deadLetters)
+	}
+
 	s.logger.Info("Scheduler started")
 }
 
 // Stop stops the scheduler and waits for running jobs to complete
 func (s *Scheduler) Stop() {
+	s.Shutdown(context.Background())
+}
+
+// Shutdown stops scheduling new runs and signals running syncs to stop after their in-flight
+// memories. It waits until all syncs have checkpointed their state or ctx expires.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
 	s.logger.Info("Stopping scheduler...")
 	s.cancel()
+	s.cron.Stop()
+	s.mu.Lock()
+	for connectorID := range s.retries {
+		s.dropRetry(connectorID)
+	}
+	s.mu.Unlock()
 
-	ctx := s.cron.Stop()
-	<-ctx.Done()
+	done := make(chan struct{})
+	go func() {
+		s.syncs.Wait()
+		close(done)
+	}()
 
-	s.logger.Info("Scheduler stopped")
+	select {
+	case <-done:
+		s.logger.Info("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		s.logger.Warn("Scheduler stopped before all syncs checkpointed", zap.Error(ctx.Err()))
+		return fmt.Errorf("waiting for running syncs: %w", ctx.Err())
+	}
 }
 
-// AddConnector adds a connector to the schedule
+// AddConnector adds a connector to the schedule, replacing any existing job for it
 func (s *Scheduler) AddConnector(config *models.ConnectorConfig) error {
-	if !config.Enabled {
-		s.logger.Info("Connector is disabled, skipping scheduling",
-			zap.String("connector_id", config.ID),
-		)
-		return nil
+	s.mu.RLock()
+	_, known := s.configs[config.ID]
+	s.mu.RUnlock()
+
+	if !known {
+		s.loadPause(config.ID)
+	}
+
+	if err := s.addConnector(config); err != nil {
+		return err
 	}
 
+	eventType := events.TypeConnectorAdded
+	if known {
+		eventType = events.TypeConnectorUpdated
+	}
+	s.publish(eventType, config.ID, map[string]interface{}{
+		"enabled":  config.Enabled,
+		"schedule": config.GetScheduleDescription(),
+	})
+
+	return nil
+}
+
+// addConnector (re)schedules a connector
+func (s *Scheduler) addConnector(config *models.ConnectorConfig) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -75,27 +218,46 @@ func (s *Scheduler) AddConnector(config *models.ConnectorConfig) error {
 		)
 	}
 
+	// Keep a private copy so later config reloads can't mutate a scheduled job
+	connector := *config
+	s.configs[connector.ID] = connector
+
+	if !connector.Enabled {
+		s.logger.Info("Connector is disabled, skipping scheduling",
+			zap.String("connector_id", connector.ID),
+		)
+		return nil
+	}
+
 	// Determine schedule based on type
-	var schedule string
-	switch config.Schedule.Type {
-	case "interval":
-		// Convert interval to cron expression
-		// For hourly intervals, we use: 0 0 */N * * * (every N hours)
-		schedule = fmt.Sprintf("0 0 */%d * * *", config.Schedule.IntervalHours)
-	case "cron":
-		schedule = config.Schedule.CronExpr
-	case "manual":
+	schedule, err := cronSpec(&connector, s.stagger)
+	if err != nil {
+		return err
+	}
+	if schedule == "" {
 		s.logger.Info("Connector is manual, not scheduling",
-			zap.String("connector_id", config.ID),
+			zap.String("connector_id", connector.ID),
 		)
 		return nil
-	default:
-		return fmt.Errorf("unsupported schedule type: %s", config.Schedule.Type)
 	}
 
 	// Create job function
+	jitter := s.jitter
 	jobFunc := func() {
-		s.runSync(config)
+		if !connector.Schedule.InWindow(time.Now()) {
+			s.logger.Info("Skipping scheduled sync outside execution windows",
+				zap.String("connector_id", connector.ID),
+			)
+			s.publish(events.TypeSyncSkipped, connector.ID, map[string]interface{}{
+				"trigger": "scheduled",
+				"reason":  "outside execution windows",
+			})
+			return
+		}
+		if !s.waitJitter(connector.ID, jitter) {
+			return
+		}
+		s.runSync(&connector, "scheduled")
 	}
 
 	// Add job to cron
@@ -104,54 +266,554 @@ func (s *Scheduler) AddConnector(config *models.ConnectorConfig) error {
 		return fmt.Errorf("failed to add cron job: %w", err)
 	}
 
-	s.jobs[config.ID] = entryID
+	s.jobs[connector.ID] = entryID
 
 	s.logger.Info("Scheduled connector",
-		zap.String("connector_id", config.ID),
+		zap.String("connector_id", connector.ID),
 		zap.String("schedule", schedule),
-		zap.String("description", config.GetScheduleDescription()),
+		zap.String("description", connector.GetScheduleDescription()),
+	)
+
+	return nil
+}
+
+// waitJitter sleeps for a random duration up to max. It returns false if the scheduler stopped meanwhile.
+func (s *Scheduler) waitJitter(connectorID string, max time.Duration) bool {
+	if max <= 0 {
+		return true
+	}
+
+	delay := time.Duration(rand.Int63n(int64(max)))
+	s.logger.Debug("Delaying scheduled sync",
+		zap.String("connector_id", connectorID),
+		zap.Duration("jitter", delay),
 	)
 
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// cronSpec returns the cron expression for a connector (empty for manual connectors). With stagger,
+// interval connectors start at a fixed offset within their interval instead of on the hour.
+func cronSpec(config *models.ConnectorConfig, stagger bool) (string, error) {
+	switch config.Schedule.Type {
+	case "interval":
+		// Convert interval to cron expression
+		// For hourly intervals, we use: 0 0 */N * * * (every N hours)
+		if !stagger || config.Schedule.IntervalHours <= 0 {
+			return fmt.Sprintf("0 0 */%d * * *", config.Schedule.IntervalHours), nil
+		}
+
+		// Staggered: S M H/N * * * with the offset H:M:S < N hours derived from the connector ID.
+		// Cron hours restart every day, so intervals of a day or more are offset within the first day.
+		offsetHours := config.Schedule.IntervalHours
+		if offsetHours > 24 {
+			offsetHours = 24
+		}
+		offset := staggerOffset(config.ID, offsetHours*3600)
+		return fmt.Sprintf("%d %d %d/%d * * *", offset%60, offset/60%60, offset/3600, config.Schedule.IntervalHours), nil
+	case "cron":
+		return config.Schedule.CronExpr, nil
+	case "manual":
+		return "", nil
+	default:
+		return "", fmt.Errorf("unsupported schedule type: %s", config.Schedule.Type)
+	}
+}
+
+// staggerOffset returns a stable offset in [0, period) seconds for a connector
+func staggerOffset(connectorID string, period int) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(connectorID))
+	return int(hash.Sum32() % uint32(period))
+}
+
+// ValidateConnectors checks that every enabled connector can be scheduled, without changing the schedule
+func (s *Scheduler) ValidateConnectors(connectors []models.ConnectorConfig) error {
+	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+	s.mu.RLock()
+	stagger := s.stagger
+	s.mu.RUnlock()
+
+	for i := range connectors {
+		if !connectors[i].Enabled {
+			continue
+		}
+
+		spec, err := cronSpec(&connectors[i], stagger)
+		if err != nil {
+			return fmt.Errorf("connector %s: %w", connectors[i].ID, err)
+		}
+		if spec == "" {
+			continue
+		}
+
+		if _, err := parser.Parse(spec); err != nil {
+			return fmt.Errorf("connector %s: invalid schedule %q: %w", connectors[i].ID, spec, err)
+		}
+	}
+
 	return nil
 }
 
+// ReconcileConnectors updates the schedule to match the given connectors: removed connectors
+// are unscheduled and new or changed ones are (re)scheduled. Unchanged jobs keep running untouched.
+func (s *Scheduler) ReconcileConnectors(connectors []models.ConnectorConfig) error {
+	if err := s.ValidateConnectors(connectors); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	previous := make(map[string]models.ConnectorConfig, len(s.configs))
+	for id, config := range s.configs {
+		previous[id] = config
+	}
+	s.mu.RUnlock()
+
+	for i := range connectors {
+		old, exists := previous[connectors[i].ID]
+		delete(previous, connectors[i].ID)
+
+		if exists && reflect.DeepEqual(old, connectors[i]) {
+			continue
+		}
+
+		if err := s.AddConnector(&connectors[i]); err != nil {
+			return fmt.Errorf("failed to schedule connector %s: %w", connectors[i].ID, err)
+		}
+	}
+
+	// Whatever is left was removed from the configuration
+	for id := range previous {
+		s.forgetConnector(id)
+	}
+
+	return nil
+}
+
+// forgetConnector unschedules a connector and drops its config
+func (s *Scheduler) forgetConnector(connectorID string) {
+	s.mu.Lock()
+	if entryID, exists := s.jobs[connectorID]; exists {
+		s.cron.Remove(entryID)
+		delete(s.jobs, connectorID)
+	}
+	delete(s.configs, connectorID)
+	delete(s.paused, connectorID)
+	s.dropRetry(connectorID)
+	s.mu.Unlock()
+
+	s.logger.Info("Removed connector from schedule",
+		zap.String("connector_id", connectorID),
+	)
+	s.publish(events.TypeConnectorRemoved, connectorID, nil)
+}
+
 // RemoveConnector removes a connector from the schedule
 func (s *Scheduler) RemoveConnector(connectorID string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	entryID, exists := s.jobs[connectorID]
 	if !exists {
+		s.mu.Unlock()
 		return fmt.Errorf("connector not found in schedule: %s", connectorID)
 	}
 
 	s.cron.Remove(entryID)
 	delete(s.jobs, connectorID)
+	s.dropRetry(connectorID)
+	s.mu.Unlock()
 
 	s.logger.Info("Removed connector from schedule",
 		zap.String("connector_id", connectorID),
 	)
+	s.publish(events.TypeConnectorRemoved, connectorID, nil)
 
 	return nil
 }
 
 // TriggerSync manually triggers a sync for a connector
 func (s *Scheduler) TriggerSync(config *models.ConnectorConfig) (*models.SyncReport, error) {
+	return s.TriggerSyncWithProgress(config, nil)
+}
+
+// TriggerSyncWithProgress manually triggers a sync for a connector, reporting progress to progress (may be nil)
+func (s *Scheduler) TriggerSyncWithProgress(config *models.ConnectorConfig, progress orchestrator.ProgressFunc) (*models.SyncReport, error) {
+	if err := s.markRunning(config.ID); err != nil {
+		return nil, err
+	}
+	defer s.markDone(config.ID)
+
 	s.logger.Info("Manually triggering sync",
 		zap.String("connector_id", config.ID),
 	)
 
-	return s.orchestrator.SyncConnector(s.ctx, config)
+	return s.syncConnector(config, "manual", progress)
+}
+
+// ReingestMemory replaces the documents of a memory with its latest version (see
+// Orchestrator.ReingestMemory). It fails with ErrSyncRunning while the connector syncs, ErrPaused
+// while it's paused, and ErrQuotaExhausted if its daily quota is used up.
+func (s *Scheduler) ReingestMemory(config *models.ConnectorConfig, memoryID string, limit int) (*models.ReingestResult, error) {
+	if err := s.markRunning(config.ID); err != nil {
+		return nil, err
+	}
+	defer s.markDone(config.ID)
+
+	ctx, done, err := s.syncContext(config.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if err := s.CheckQuota(config); err != nil {
+		return nil, err
+	}
+
+	result, err := s.orchestrator.ReingestMemory(ctx, config, memoryID, limit)
+	if errors.Is(err, orchestrator.ErrQuotaExhausted) {
+		return nil, fmt.Errorf("%w: the memory doesn't fit in the rest of today's quota", ErrQuotaExhausted)
+	}
+	return result, err
+}
+
+// PlanPurge lists what PurgeContext removes (see Orchestrator.PlanPurge)
+func (s *Scheduler) PlanPurge(ctx context.Context, contextID string, connectors []models.ConnectorConfig) (*models.PurgePlan, error) {
+	return s.orchestrator.PlanPurge(ctx, contextID, connectors)
+}
+
+// PurgeContext removes the documents of a planned purge from LightRAG and clears the state of its
+// connectors (see Orchestrator.PurgeContext). It fails with ErrSyncRunning while one of them syncs.
+func (s *Scheduler) PurgeContext(plan *models.PurgePlan, connectors []models.ConnectorConfig) (*models.PurgeResult, error) {
+	release, err := s.holdContext(plan.ContextID, connectors)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.orchestrator.PurgeContext(s.ctx, plan, connectors)
+}
+
+// Snapshot writes the documents and sync state of a context to w (see Orchestrator.Snapshot)
+func (s *Scheduler) Snapshot(ctx context.Context, contextID string, connectors []models.ConnectorConfig, limit int, w io.Writer) (*models.SnapshotManifest, error) {
+	return s.orchestrator.Snapshot(ctx, contextID, connectors, limit, w)
+}
+
+// RestoreSnapshot restores a context snapshot (see Orchestrator.RestoreSnapshot). It fails with
+// ErrSyncRunning while one of the context's connectors syncs.
+func (s *Scheduler) RestoreSnapshot(snapshot *orchestrator.Snapshot, connectors []models.ConnectorConfig) (*models.RestoreResult, error) {
+	release, err := s.holdContext(snapshot.Manifest.ContextID, connectors)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.orchestrator.RestoreSnapshot(s.ctx, snapshot, connectors)
+}
+
+// ImportDocuments inserts the documents of a JSON Lines export into LightRAG (see
+// Orchestrator.ImportDocuments). It fails with ErrSyncRunning while one of their connectors syncs.
+func (s *Scheduler) ImportDocuments(documents []export.Document, connectors []models.ConnectorConfig, connectorID string) (*models.ImportResult, error) {
+	release, err := s.holdConnectors(orchestrator.ImportConnectorIDs(documents, connectorID))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.orchestrator.ImportDocuments(s.ctx, documents, connectors, connectorID)
+}
+
+// holdContext marks the connectors of a context running, so none of them syncs until release is called
+func (s *Scheduler) holdContext(contextID string, connectors []models.ConnectorConfig) (release func(), err error) {
+	var connectorIDs []string
+	for _, connector := range connectors {
+		if connector.ContextID == contextID {
+			connectorIDs = append(connectorIDs, connector.ID)
+		}
+	}
+	return s.holdConnectors(connectorIDs)
+}
+
+// holdConnectors marks connectors running, so none of them syncs until release is called
+func (s *Scheduler) holdConnectors(connectorIDs []string) (release func(), err error) {
+	var marked []string
+	release = func() {
+		for _, connectorID := range marked {
+			s.markDone(connectorID)
+		}
+	}
+	for _, connectorID := range connectorIDs {
+		if err := s.markRunning(connectorID); err != nil {
+			release()
+			return nil, fmt.Errorf("connector %s: %w", connectorID, err)
+		}
+		marked = append(marked, connectorID)
+	}
+	return release, nil
+}
+
+// CheckQuota returns an error wrapping ErrQuotaExhausted if the connector is paused by its daily quota
+func (s *Scheduler) CheckQuota(config *models.ConnectorConfig) error {
+	quota, err := s.orchestrator.QuotaStatus(s.ctx, config)
+	if err != nil {
+		return fmt.Errorf("failed to check quota: %w", err)
+	}
+	if quota != nil && quota.Exhausted {
+		return fmt.Errorf("%w: %s", ErrQuotaExhausted, quota.Usage())
+	}
+	return nil
 }
 
-// runSync executes a sync job (called by cron)
-func (s *Scheduler) runSync(config *models.ConnectorConfig) {
+// syncConnector runs a sync and publishes its start and outcome. Connectors paused by an operator
+// or by their quota are skipped, and so are syncs whose upstream services don't pass the preflight
+// checks: they return a report with status upstream_unavailable.
+func (s *Scheduler) syncConnector(config *models.ConnectorConfig, trigger string, progress orchestrator.ProgressFunc) (*models.SyncReport, error) {
+	ctx, done, err := s.syncContext(config.ID)
+	if err != nil {
+		s.publish(events.TypeSyncSkipped, config.ID, map[string]interface{}{
+			"trigger": trigger,
+			"reason":  err.Error(),
+		})
+		return nil, err
+	}
+	defer done()
+
+	if err := s.CheckQuota(config); err != nil {
+		if errors.Is(err, ErrQuotaExhausted) {
+			s.publish(events.TypeSyncSkipped, config.ID, map[string]interface{}{
+				"trigger": trigger,
+				"reason":  err.Error(),
+			})
+		}
+		return nil, err
+	}
+
+	if err := s.waitForSlot(ctx, config, trigger); err != nil {
+		return nil, err
+	}
+	defer s.queue.release()
+
+	if err := s.checkUpstreams(ctx, config); err != nil {
+		if ctx.Err() != nil {
+			if s.ctx.Err() != nil {
+				return nil, ErrShuttingDown
+			}
+			return nil, context.Cause(ctx)
+		}
+		return s.skipUnavailable(ctx, config, trigger, err), nil
+	}
+
+	// The sync does the work of a pending early retry
+	s.mu.Lock()
+	s.dropRetry(config.ID)
+	s.mu.Unlock()
+
+	s.publish(events.TypeSyncStarted, config.ID, map[string]interface{}{
+		"context_id": config.ContextID,
+		"trigger":    trigger,
+	})
+
+	report, err := s.sync(ctx, config, trigger, progress)
+
+	switch {
+	case err != nil:
+		s.publish(events.TypeSyncFailed, config.ID, map[string]interface{}{
+			"trigger": trigger,
+			"error":   err.Error(),
+		})
+	case report.IsFailed():
+		data := map[string]interface{}{
+			"trigger": trigger,
+			"error":   report.ErrorMessage,
+		}
+		if report.Aborted {
+			data["aborted"] = true
+		}
+		s.publish(events.TypeSyncFailed, config.ID, data)
+	default:
+		s.publish(events.TypeSyncCompleted, config.ID, map[string]interface{}{
+			"trigger":         trigger,
+			"status":          report.Status,
+			"total_fetched":   report.TotalFetched,
+			"total_processed": report.TotalProcessed,
+			"total_skipped":   report.TotalSkipped,
+			"total_failed":    report.TotalFailed,
+			"total_deferred":  report.TotalDeferred,
+			"duration_ms":     report.Duration.Milliseconds(),
+		})
+	}
+
+	return report, err
+}
+
+// skipUnavailable records a sync skipped because an upstream service failed the preflight checks
+// with err, publishes it as failed, and schedules its early retry
+func (s *Scheduler) skipUnavailable(ctx context.Context, config *models.ConnectorConfig, trigger string, err error) *models.SyncReport {
+	report := s.orchestrator.RecordUpstreamUnavailable(ctx, config, err)
+	retryAt := s.retryEarly(config.ID, trigger)
+
+	fields := []zap.Field{
+		zap.String("connector_id", config.ID),
+		zap.String("trigger", trigger),
+		zap.Error(err),
+	}
+	data := map[string]interface{}{
+		"trigger": trigger,
+		"status":  report.Status,
+		"error":   report.ErrorMessage,
+	}
+	if !retryAt.IsZero() {
+		fields = append(fields, zap.Time("retry_at", retryAt))
+		data["retry_at"] = retryAt.UTC()
+	}
+	s.logger.Warn("Skipping sync, upstream unavailable", fields...)
+	s.publish(events.TypeSyncFailed, config.ID, data)
+
+	return report
+}
+
+// sync runs a sync, turning a panic into an error that is logged and reported with the connector's context
+func (s *Scheduler) sync(ctx context.Context, config *models.ConnectorConfig, trigger string, progress orchestrator.ProgressFunc) (report *models.SyncReport, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		s.mu.RLock()
+		reporter := s.reporter
+		s.mu.RUnlock()
+		reporter.CapturePanic(recovered, reporting.Callers(), map[string]string{
+			"component":    "scheduler",
+			"connector_id": config.ID,
+			"context_id":   config.ContextID,
+			"trigger":      trigger,
+		})
+
+		s.logger.Error("Sync panicked",
+			zap.String("connector_id", config.ID),
+			zap.String("trigger", trigger),
+			zap.Any("panic", recovered),
+			zap.Stack("stack"),
+		)
+		report, err = nil, fmt.Errorf("sync panicked: %v", recovered)
+	}()
+
+	return s.orchestrator.SyncConnectorWithProgress(ctx, config, progress)
+}
+
+// waitForSlot blocks until the sync may run under the concurrency limit, publishing when it has to queue
+func (s *Scheduler) waitForSlot(ctx context.Context, config *models.ConnectorConfig, trigger string) error {
+	err := s.queue.acquire(ctx, config.ID, config.Priority, func(position int) {
+		s.logger.Info("Sync queued, concurrency limit reached",
+			zap.String("connector_id", config.ID),
+			zap.Int("priority", config.Priority),
+			zap.Int("queue_position", position),
+		)
+		s.publish(events.TypeSyncQueued, config.ID, map[string]interface{}{
+			"trigger":        trigger,
+			"priority":       config.Priority,
+			"queue_position": position,
+		})
+	})
+	if err != nil {
+		if s.ctx.Err() != nil {
+			return ErrShuttingDown
+		}
+		return context.Cause(ctx)
+	}
+	return nil
+}
+
+// QueuePosition returns the 1-based position of a connector's sync waiting for a slot, 0 if none is waiting
+func (s *Scheduler) QueuePosition(connectorID string) int {
+	return s.queue.position(connectorID)
+}
+
+// IsRunning returns true if a sync for the connector is in progress (including waiting in the queue)
+func (s *Scheduler) IsRunning(connectorID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.running[connectorID]
+}
+
+// RunningConnectors returns the IDs of connectors with a sync in progress (including waiting in the queue), sorted
+func (s *Scheduler) RunningConnectors() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.running))
+	for id := range s.running {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// markRunning marks a connector as syncing
+func (s *Scheduler) markRunning(connectorID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return ErrShuttingDown
+	}
+	if s.running[connectorID] {
+		return ErrSyncRunning
+	}
+	s.running[connectorID] = true
+	s.syncs.Add(1)
+	return nil
+}
+
+// markDone clears the syncing mark of a connector
+func (s *Scheduler) markDone(connectorID string) {
+	s.release(connectorID)
+	s.syncs.Done()
+}
+
+// runSync executes a sync job, trigger is scheduled (called by cron) or retry (an early retry of one
+// skipped because an upstream service was unavailable)
+func (s *Scheduler) runSync(config *models.ConnectorConfig, trigger string) {
+	if err := s.markRunning(config.ID); err != nil {
+		s.logger.Warn("Skipping scheduled sync",
+			zap.String("connector_id", config.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	defer s.markDone(config.ID)
+
 	s.logger.Info("Starting scheduled sync",
 		zap.String("connector_id", config.ID),
 		zap.String("context_id", config.ContextID),
+		zap.String("trigger", trigger),
 	)
 
-	report, err := s.orchestrator.SyncConnector(s.ctx, config)
+	report, err := s.syncConnector(config, trigger, nil)
+	if errors.Is(err, ErrQuotaExhausted) || errors.Is(err, ErrPaused) {
+		s.logger.Info("Skipping scheduled sync, connector is paused",
+			zap.String("connector_id", config.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	if errors.Is(err, client.ErrUnauthorized) {
+		// Later runs fail alike until the credentials are fixed
+		s.logger.Error("Scheduled sync failed, the upstream API rejected the connector's credentials",
+			zap.String("connector_id", config.ID),
+			zap.Error(err),
+		)
+		return
+	}
 	if err != nil {
 		s.logger.Error("Scheduled sync failed",
 			zap.String("connector_id", config.ID),
@@ -159,6 +821,9 @@ func (s *Scheduler) runSync(config *models.ConnectorConfig) {
 		)
 		return
 	}
+	if report.IsUpstreamUnavailable() {
+		return
+	}
 
 	s.logger.Info("Scheduled sync completed",
 		zap.String("connector_id", config.ID),
@@ -170,7 +835,17 @@ func (s *Scheduler) runSync(config *models.ConnectorConfig) {
 	)
 }
 
-// GetScheduledJobs returns information about all scheduled jobs
+// ConnectorConfig returns the configuration of a connector as last applied to the scheduler
+func (s *Scheduler) ConnectorConfig(connectorID string) (models.ConnectorConfig, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	config, ok := s.configs[connectorID]
+	return config, ok
+}
+
+// GetScheduledJobs returns information about all scheduled jobs. The next run of a job is its
+// early retry if one is pending.
 func (s *Scheduler) GetScheduledJobs() map[string]JobInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -179,10 +854,15 @@ func (s *Scheduler) GetScheduledJobs() map[string]JobInfo {
 
 	for connectorID, entryID := range s.jobs {
 		entry := s.cron.Entry(entryID)
+		config := s.configs[connectorID]
+		nextRun := nextRunInWindow(entry, &config.Schedule)
+		if retryAt := s.pendingRetry(connectorID); !retryAt.IsZero() && (nextRun.IsZero() || retryAt.Before(nextRun)) {
+			nextRun = retryAt
+		}
 		result[connectorID] = JobInfo{
 			ConnectorID: connectorID,
 			EntryID:     int(entryID),
-			NextRun:     entry.Next,
+			NextRun:     nextRun,
 			PrevRun:     entry.Prev,
 		}
 	}
@@ -190,6 +870,22 @@ func (s *Scheduler) GetScheduledJobs() map[string]JobInfo {
 	return result
 }
 
+// maxWindowLookahead bounds the scheduled runs searched for one inside the execution windows
+const maxWindowLookahead = 10000
+
+// nextRunInWindow returns the next run of a job that falls inside the schedule's execution windows,
+// zero if none is found within maxWindowLookahead runs
+func nextRunInWindow(entry cron.Entry, schedule *models.ScheduleConfig) time.Time {
+	next := entry.Next
+	for i := 0; i < maxWindowLookahead && !next.IsZero(); i++ {
+		if schedule.InWindow(next) {
+			return next
+		}
+		next = entry.Schedule.Next(next)
+	}
+	return time.Time{}
+}
+
 // JobInfo contains information about a scheduled job
 type JobInfo struct {
 	ConnectorID string    `json:"connector_id"`
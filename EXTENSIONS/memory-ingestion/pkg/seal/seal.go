@@ -0,0 +1,65 @@
+// Package seal encrypts data at rest with AES-256-GCM, e.g. sync states, reports, and pseudonym
+// mappings. Sealed data is a random nonce followed by the ciphertext; its users add their own
+// framing around it.
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of keys in bytes
+const KeySize = 32
+
+var (
+	// ErrTruncated is returned when opening data shorter than a nonce
+	ErrTruncated = errors.New("sealed data is truncated")
+	// ErrWrongKey is returned when opening data another key sealed, or that was tampered with
+	ErrWrongKey = errors.New("key doesn't open the sealed data")
+)
+
+// Key seals and opens data with AES-256-GCM
+type Key struct {
+	aead cipher.AEAD
+}
+
+// NewKey creates a key from KeySize bytes
+func NewKey(key []byte) (*Key, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{aead: aead}, nil
+}
+
+// Seal encrypts plaintext with a random nonce, returning the nonce followed by the ciphertext
+func (k *Key) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize(), k.aead.NonceSize()+len(plaintext)+k.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return k.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Open decrypts data sealed by Seal. The error is ErrTruncated or ErrWrongKey.
+func (k *Key) Open(sealed []byte) ([]byte, error) {
+	if len(sealed) < k.aead.NonceSize() {
+		return nil, ErrTruncated
+	}
+	nonce, ciphertext := sealed[:k.aead.NonceSize()], sealed[k.aead.NonceSize():]
+	plaintext, err := k.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plaintext, nil
+}
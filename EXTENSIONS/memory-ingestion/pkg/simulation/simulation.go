@@ -0,0 +1,283 @@
+// Package simulation generates realistic synthetic memories, to load-test a LightRAG deployment
+// or try transform settings without real data
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kamir/memory-connector/pkg/client"
+	"github.com/kamir/memory-connector/pkg/clock"
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// jitterDegrees spreads the coordinates of a location over about 30 meters
+const jitterDegrees = 0.0003
+
+// Entities mentioned by simulated memories of connectors that don't configure their own
+var (
+	defaultLocations = []models.SimulatedLocation{
+		{Name: "home", Lat: 52.5301, Lon: 13.4017},
+		{Name: "the office", Lat: 52.5076, Lon: 13.3904},
+		{Name: "Café Liebling", Lat: 52.5385, Lon: 13.4163},
+		{Name: "Tempelhofer Feld", Lat: 52.4731, Lon: 13.4039},
+		{Name: "the gym", Lat: 52.5163, Lon: 13.4541},
+		{Name: "Berlin Hauptbahnhof", Lat: 52.5251, Lon: 13.3694},
+	}
+	defaultPeople        = []string{"Anna Schmidt", "Ben Carter", "Chen Wei", "Daniela Rossi", "Emeka Obi", "Farah Haddad", "Greta Lind", "Hiro Tanaka"}
+	defaultOrganizations = []string{"Northwind", "Falcon Labs", "Acme Logistics", "Bluebird Health", "the city library"}
+	defaultTopics        = []string{"the quarterly budget", "Project Falcon", "the office move", "the hiring plan", "the product launch", "marathon training", "the kitchen renovation", "the conference talk"}
+)
+
+// template is a transcript with placeholders for the entities a memory mentions
+type template struct {
+	memoryType string
+	text       string
+}
+
+// templates are the transcripts of simulated memories. {person} and {other} are different people,
+// {other} is "a colleague" if only one person is configured.
+var templates = []template{
+	{"conversation", "Met {person} at {place} to talk about {topic}. {other} from {organization} joins us next week."},
+	{"conversation", "Call with {person} from {organization} about {topic}. I promised to send the numbers by {weekday}."},
+	{"conversation", "Lunch with {person} and {other} at {place}. We mostly talked about {topic}."},
+	{"conversation", "{person} thinks {organization} should drop {topic} for now. {other} disagrees, we decide on {weekday}."},
+	{"note", "Note to self: read up on {topic} before the {organization} review on {weekday}."},
+	{"note", "Idea for {topic}: ask {person} whether {organization} has tried something similar."},
+	{"note", "At {place}. Need to remind {person} about {topic}, the deadline moved to {weekday}."},
+	{"note", "{organization} sent the proposal for {topic}. {person} wants feedback, {other} already signed off."},
+	{"note", "Spent a few hours at {place} working on {topic}. Good progress, next step is a review with {person}."},
+	{"note", "Reminder: {other} is out until {weekday}, so {person} covers {topic} at {organization}."},
+}
+
+// diurnalWeights weigh the hours of a day for the diurnal distribution: quiet nights, a morning
+// peak, a lunch dip, and an evening peak
+var diurnalWeights = [24]float64{
+	0.2, 0.1, 0.1, 0.1, 0.1, 0.3, 1, 3, 5, 6, 5, 4,
+	3, 3, 4, 4, 4, 5, 6, 6, 5, 3, 2, 1,
+}
+
+// Generator is a client.MemorySource of synthetic memories (see models.SimulationConfig). Memories
+// are derived from the seed, the context ID, and their day, so every call sees the same memories.
+type Generator struct {
+	config        models.SimulationConfig
+	locations     []models.SimulatedLocation
+	people        []string
+	organizations []string
+	topics        []string
+	clock         clock.Clock
+}
+
+var _ client.MemorySource = (*Generator)(nil)
+
+// New creates the generator of a connector's simulation settings, whose memories arrive as the
+// clock's time passes
+func New(config models.SimulationConfig, c clock.Clock) *Generator {
+	return &Generator{
+		config:        config,
+		locations:     orDefault(config.Locations, defaultLocations),
+		people:        orDefault(config.People, defaultPeople),
+		organizations: orDefault(config.Organizations, defaultOrganizations),
+		topics:        orDefault(config.Topics, defaultTopics),
+		clock:         c,
+	}
+}
+
+// GetMemories returns up to limit of the memories generated for the query range, newest first.
+// Memories of the current day arrive as their time passes.
+func (g *Generator) GetMemories(ctx context.Context, ctxID string, limit int, rangeParam string) (*models.MemoryList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	span, ok := models.QueryRangeDuration(rangeParam)
+	if !ok {
+		return nil, fmt.Errorf("unsupported query range %q", rangeParam)
+	}
+
+	now := g.clock.Now().UTC()
+	from := now.Add(-span)
+	var memories []models.Memory
+	for day := now.Truncate(24 * time.Hour); day.After(from.Add(-24 * time.Hour)); day = day.AddDate(0, 0, -1) {
+		for _, memory := range g.day(ctxID, day) {
+			createdAt, _ := memory.ParseCreatedAt()
+			if createdAt.After(from) && !createdAt.After(now) {
+				memories = append(memories, memory)
+			}
+		}
+	}
+
+	sort.SliceStable(memories, func(i, j int) bool {
+		return memories[i].CreatedAt > memories[j].CreatedAt
+	})
+	if limit > 0 && len(memories) > limit {
+		memories = memories[:limit]
+	}
+
+	return &models.MemoryList{Memories: memories, Count: len(memories)}, nil
+}
+
+// Generate returns count memories of a context created up to until, newest first, going back as
+// many days as they take
+func (g *Generator) Generate(ctxID string, count int, until time.Time) []models.Memory {
+	if g.config.MemoriesPerDay <= 0 {
+		return nil
+	}
+	until = until.UTC()
+	memories := make([]models.Memory, 0, count)
+	for day := until.Truncate(24 * time.Hour); len(memories) < count; day = day.AddDate(0, 0, -1) {
+		generated := g.day(ctxID, day)
+		for i := len(generated) - 1; i >= 0 && len(memories) < count; i-- {
+			if createdAt, _ := generated[i].ParseCreatedAt(); !createdAt.After(until) {
+				memories = append(memories, generated[i])
+			}
+		}
+	}
+	return memories
+}
+
+// GetMemoryAudio fails, simulated memories have no audio
+func (g *Generator) GetMemoryAudio(ctx context.Context, ctxID, memoryID string) ([]byte, error) {
+	return nil, fmt.Errorf("simulated memory %s has no audio", memoryID)
+}
+
+// GetMemoryImage fails, simulated memories have no image
+func (g *Generator) GetMemoryImage(ctx context.Context, ctxID, memoryID string) ([]byte, error) {
+	return nil, fmt.Errorf("simulated memory %s has no image", memoryID)
+}
+
+// day generates the memories of a context's day (UTC midnight), in chronological order
+func (g *Generator) day(ctxID string, day time.Time) []models.Memory {
+	rng := rand.New(rand.NewSource(g.seed(ctxID, day)))
+
+	times := g.times(rng, day)
+	memories := make([]models.Memory, len(times))
+	for i, createdAt := range times {
+		memories[i] = g.memory(rng, fmt.Sprintf("sim-%s-%04d", day.Format("20060102"), i+1), createdAt)
+	}
+	return memories
+}
+
+// seed derives the random source of a context's day from the configured seed
+func (g *Generator) seed(ctxID string, day time.Time) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(ctxID))
+	hash.Write([]byte(day.Format("2006-01-02")))
+	return int64(hash.Sum64()) ^ g.config.Seed
+}
+
+// times returns the sorted creation times of a day's memories, as the distribution spreads them
+func (g *Generator) times(rng *rand.Rand, day time.Time) []time.Time {
+	count := g.config.MemoriesPerDay
+	offsets := make([]time.Duration, count)
+
+	switch g.config.Distribution {
+	case models.SimulationDistributionUniform:
+		for i := range offsets {
+			offsets[i] = time.Duration(rng.Int63n(int64(24 * time.Hour)))
+		}
+	case models.SimulationDistributionBursty:
+		// A session per 8 memories, each memory within 45 minutes of its session's start
+		sessions := make([]time.Duration, 1+count/8)
+		for i := range sessions {
+			sessions[i] = diurnalOffset(rng)
+		}
+		for i := range offsets {
+			offsets[i] = sessions[rng.Intn(len(sessions))] + time.Duration(rng.Int63n(int64(45*time.Minute)))
+			offsets[i] = min(offsets[i], 24*time.Hour-time.Second)
+		}
+	default:
+		for i := range offsets {
+			offsets[i] = diurnalOffset(rng)
+		}
+	}
+
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	times := make([]time.Time, count)
+	for i, offset := range offsets {
+		times[i] = day.Add(offset.Truncate(time.Second))
+	}
+	return times
+}
+
+// diurnalOffset returns a time of day drawn from the diurnal weights
+func diurnalOffset(rng *rand.Rand) time.Duration {
+	var total float64
+	for _, weight := range diurnalWeights {
+		total += weight
+	}
+	pick := rng.Float64() * total
+	hour := 0
+	for ; hour < len(diurnalWeights)-1; hour++ {
+		if pick < diurnalWeights[hour] {
+			break
+		}
+		pick -= diurnalWeights[hour]
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(rng.Int63n(int64(time.Hour)))
+}
+
+// memory generates a memory recorded at createdAt
+func (g *Generator) memory(rng *rand.Rand, id string, createdAt time.Time) models.Memory {
+	tmpl := templates[rng.Intn(len(templates))]
+	location := g.location(rng, createdAt)
+
+	person := rng.Intn(len(g.people))
+	other := "a colleague"
+	if len(g.people) > 1 {
+		other = g.people[(person+1+rng.Intn(len(g.people)-1))%len(g.people)]
+	}
+	transcript := strings.NewReplacer(
+		"{person}", g.people[person],
+		"{other}", other,
+		"{organization}", g.organizations[rng.Intn(len(g.organizations))],
+		"{topic}", g.topics[rng.Intn(len(g.topics))],
+		"{place}", location.Name,
+		"{weekday}", createdAt.AddDate(0, 0, 1+rng.Intn(6)).Weekday().String(),
+	).Replace(tmpl.text)
+	first, size := utf8.DecodeRuneInString(transcript)
+	transcript = string(unicode.ToUpper(first)) + transcript[size:]
+
+	memory := models.Memory{
+		ID:         id,
+		Type:       tmpl.memoryType,
+		Transcript: transcript,
+		CreatedAt:  createdAt.Format(time.RFC3339),
+	}
+	if rng.Float64() < g.config.LocationRatio {
+		lat := location.Lat + (rng.Float64()-0.5)*jitterDegrees
+		lon := location.Lon + (rng.Float64()-0.5)*jitterDegrees
+		memory.LocationLat = &lat
+		memory.LocationLon = &lon
+	}
+	return memory
+}
+
+// location picks where a memory is recorded: mostly the first location at night and the second
+// during working hours on weekdays, so frequent places can be inferred; any location otherwise
+func (g *Generator) location(rng *rand.Rand, createdAt time.Time) models.SimulatedLocation {
+	hour := createdAt.Hour()
+	weekday := createdAt.Weekday() != time.Saturday && createdAt.Weekday() != time.Sunday
+	switch {
+	case (hour >= 22 || hour < 7) && rng.Float64() < 0.9:
+		return g.locations[0]
+	case weekday && hour >= 9 && hour < 17 && len(g.locations) > 1 && rng.Float64() < 0.7:
+		return g.locations[1]
+	default:
+		return g.locations[rng.Intn(len(g.locations))]
+	}
+}
+
+// orDefault returns values, or defaults if there are none
+func orDefault[T any](values, defaults []T) []T {
+	if len(values) == 0 {
+		return defaults
+	}
+	return values
+}
@@ -0,0 +1,55 @@
+package state
+
+import (
+	"hash/maphash"
+	"math"
+)
+
+// bloomFilter is a set of strings that may report strings it doesn't hold (at about the false
+// positive rate it was sized for) but never misses one it holds
+type bloomFilter struct {
+	bits   []uint64
+	size   uint64 // number of bits
+	hashes uint64 // bits set per string
+	seed   maphash.Seed
+}
+
+// newBloomFilter creates a filter for up to capacity strings
+func newBloomFilter(capacity int, falsePositiveRate float64) *bloomFilter {
+	n := float64(max(capacity, 1))
+	size := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	size = max(size, 64)
+	return &bloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: uint64(max(1, math.Round(float64(size)/n*math.Ln2))),
+		seed:   maphash.MakeSeed(),
+	}
+}
+
+// add adds a string to the set
+func (b *bloomFilter) add(s string) {
+	h1, h2 := b.hash(s)
+	for i := uint64(0); i < b.hashes; i++ {
+		bit := (h1 + i*h2) % b.size
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// mayContain returns false if the string was never added, true if it probably was
+func (b *bloomFilter) mayContain(s string) bool {
+	h1, h2 := b.hash(s)
+	for i := uint64(0); i < b.hashes; i++ {
+		bit := (h1 + i*h2) % b.size
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// hash returns the two hashes the bit positions of a string are derived from (double hashing)
+func (b *bloomFilter) hash(s string) (uint64, uint64) {
+	h := maphash.String(b.seed, s)
+	return h, h>>33 | 1
+}
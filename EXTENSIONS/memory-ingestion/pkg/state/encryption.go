@@ -0,0 +1,85 @@
+package state
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/kamir/memory-connector/pkg/seal"
+)
+
+// encryptedPrefix marks a value sealed by a Cipher, so values written before encryption was
+// enabled are still read
+var encryptedPrefix = []byte("enc:v1:")
+
+// ErrNoDecryptionKey is returned when reading an encrypted value without storage encryption, or
+// with keys none of which sealed it (or if it was tampered with)
+var ErrNoDecryptionKey = errors.New("no key decrypts the value")
+
+// Cipher encrypts the values of a state store with AES-256-GCM. A nil Cipher leaves values as
+// they are.
+type Cipher struct {
+	keys []*seal.Key // the first seals, all open
+}
+
+// NewCipher creates a cipher that seals with key and opens values sealed with key or any of the
+// previous keys. Keys must be 32 bytes.
+func NewCipher(key []byte, previousKeys ...[]byte) (*Cipher, error) {
+	c := &Cipher{}
+	for _, k := range append([][]byte{key}, previousKeys...) {
+		sealKey, err := seal.NewKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key: %w", err)
+		}
+		c.keys = append(c.keys, sealKey)
+	}
+	return c, nil
+}
+
+// seal encrypts plaintext with a random nonce, encoded as the prefix and base64 of the nonce and
+// ciphertext. Empty values stay empty.
+func (c *Cipher) seal(plaintext []byte) ([]byte, error) {
+	if c == nil || len(plaintext) == 0 {
+		return plaintext, nil
+	}
+
+	sealed, err := c.keys[0].Seal(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := make([]byte, len(encryptedPrefix)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(encoded, encryptedPrefix)
+	base64.StdEncoding.Encode(encoded[len(encryptedPrefix):], sealed)
+	return encoded, nil
+}
+
+// open decrypts a value sealed by seal. Values without the prefix were written unencrypted and
+// are returned as they are.
+func (c *Cipher) open(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, encryptedPrefix) {
+		return data, nil
+	}
+	if c == nil {
+		return nil, fmt.Errorf("%w: storage.encryption isn't enabled", ErrNoDecryptionKey)
+	}
+
+	sealed := make([]byte, base64.StdEncoding.DecodedLen(len(data)-len(encryptedPrefix)))
+	n, err := base64.StdEncoding.Decode(sealed, data[len(encryptedPrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encrypted value: %w", err)
+	}
+	sealed = sealed[:n]
+
+	for _, key := range c.keys {
+		plaintext, err := key.Open(sealed)
+		if err == nil {
+			return plaintext, nil
+		}
+		if errors.Is(err, seal.ErrTruncated) {
+			return nil, fmt.Errorf("failed to decrypt value: %w", err)
+		}
+	}
+	return nil, ErrNoDecryptionKey
+}
@@ -0,0 +1,114 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// minFilterCapacity sizes the filters of connectors with few processed memories
+const minFilterCapacity = 1024
+
+// ProcessedFilter is implemented by state managers that can rule out that a connector processed a
+// memory without loading its state
+type ProcessedFilter interface {
+	// MayBeProcessed returns false if the connector certainly didn't process the memory; true means
+	// it probably did, which only its state can confirm
+	MayBeProcessed(connectorID, memoryID string) bool
+}
+
+// FilteredStore wraps a state manager with a Bloom filter of each connector's processed memory
+// IDs, warmed from the store when it's created and rebuilt whenever a state is saved. Checking
+// whether a memory was ingested then only loads the states of connectors that probably did.
+// States written by other processes on the same storage are only seen after a restart.
+type FilteredStore struct {
+	StateManager
+	falsePositiveRate float64
+	logger            *zap.Logger
+	mu                sync.RWMutex
+	filters           map[string]*bloomFilter // connector ID -> processed memory IDs
+}
+
+var _ ProcessedFilter = (*FilteredStore)(nil)
+
+// NewFilteredStore wraps store, building the filters of the states it holds
+func NewFilteredStore(store StateManager, falsePositiveRate float64, logger *zap.Logger) (*FilteredStore, error) {
+	start := time.Now()
+	states, err := store.ListStates(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to warm processed-memory filters: %w", err)
+	}
+
+	f := &FilteredStore{
+		StateManager:      store,
+		falsePositiveRate: falsePositiveRate,
+		logger:            logger,
+		filters:           make(map[string]*bloomFilter, len(states)),
+	}
+	processed := 0
+	for i := range states {
+		f.filters[states[i].ConnectorID] = f.buildFilter(&states[i])
+		processed += len(states[i].ProcessedIDs)
+	}
+
+	logger.Info("Warmed processed-memory filters",
+		zap.Int("connectors", len(states)),
+		zap.Int("processed_ids", processed),
+		zap.Duration("duration", time.Since(start)),
+	)
+
+	return f, nil
+}
+
+// SaveState saves the sync state and rebuilds the connector's filter from it
+func (f *FilteredStore) SaveState(ctx context.Context, state *models.SyncState) error {
+	if err := f.StateManager.SaveState(ctx, state); err != nil {
+		return err
+	}
+
+	// Rebuilt rather than extended, so memory IDs dropped from the state (purges, re-ingestion)
+	// leave the filter too
+	filter := f.buildFilter(state)
+	f.mu.Lock()
+	f.filters[state.ConnectorID] = filter
+	f.mu.Unlock()
+	return nil
+}
+
+// DeleteState removes the sync state and the connector's filter
+func (f *FilteredStore) DeleteState(ctx context.Context, connectorID string) error {
+	if err := f.StateManager.DeleteState(ctx, connectorID); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	delete(f.filters, connectorID)
+	f.mu.Unlock()
+	return nil
+}
+
+// MayBeProcessed implements ProcessedFilter
+func (f *FilteredStore) MayBeProcessed(connectorID, memoryID string) bool {
+	f.mu.RLock()
+	filter := f.filters[connectorID]
+	f.mu.RUnlock()
+
+	// Connectors without a filter have no state, or one that couldn't be read when the filters
+	// were warmed; only loading it tells
+	return filter == nil || filter.mayContain(memoryID)
+}
+
+// buildFilter returns a filter of the memory IDs a state marks processed
+func (f *FilteredStore) buildFilter(state *models.SyncState) *bloomFilter {
+	filter := newBloomFilter(max(len(state.ProcessedIDs), minFilterCapacity), f.falsePositiveRate)
+	for memoryID, processed := range state.ProcessedIDs {
+		if processed {
+			filter.add(memoryID)
+		}
+	}
+	return filter
+}
@@ -1,9 +1,13 @@
 package state
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -15,21 +19,23 @@ import (
 // JSONStore implements StateManager using JSON files
 type JSONStore struct {
 	dirPath string
+	cipher  *Cipher
 	logger  *zap.Logger
 	mu      sync.RWMutex
 }
 
-// NewJSONStore creates a new JSON-based state store
-func NewJSONStore(dirPath string, logger *zap.Logger) (*JSONStore, error) {
+// NewJSONStore creates a new JSON-based state store; cipher encrypts its files if not nil
+func NewJSONStore(dirPath string, cipher *Cipher, logger *zap.Logger) (*JSONStore, error) {
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(dirPath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create state directory: %w", err)
 	}
 
-	logger.Info("Initialized JSON state store", zap.String("path", dirPath))
+	logger.Info("Initialized JSON state store", zap.String("path", dirPath), zap.Bool("encrypted", cipher != nil))
 
 	return &JSONStore{
 		dirPath: dirPath,
+		cipher:  cipher,
 		logger:  logger,
 	}, nil
 }
@@ -55,6 +61,9 @@ func (s *JSONStore) GetState(ctx context.Context, connectorID string) (*models.S
 	if err != nil {
 		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
+	if data, err = s.cipher.open(data); err != nil {
+		return nil, fmt.Errorf("failed to decrypt state: %w", err)
+	}
 
 	// Unmarshal JSON
 	var state models.SyncState
@@ -82,6 +91,9 @@ func (s *JSONStore) SaveState(ctx context.Context, state *models.SyncState) erro
 	if err != nil {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
+	if data, err = s.cipher.seal(data); err != nil {
+		return fmt.Errorf("failed to encrypt state: %w", err)
+	}
 
 	// Write to temporary file first
 	tmpPath := filePath + ".tmp"
@@ -141,6 +153,10 @@ func (s *JSONStore) ListStates(ctx context.Context) ([]models.SyncState, error)
 			s.logger.Warn("Failed to read state file", zap.String("file", entry.Name()), zap.Error(err))
 			continue
 		}
+		if data, err = s.cipher.open(data); err != nil {
+			s.logger.Warn("Failed to decrypt state", zap.String("file", entry.Name()), zap.Error(err))
+			continue
+		}
 
 		var state models.SyncState
 		if err := json.Unmarshal(data, &state); err != nil {
@@ -156,6 +172,92 @@ func (s *JSONStore) ListStates(ctx context.Context) ([]models.SyncState, error)
 	return states, nil
 }
 
+// SaveReport appends a sync report to the connector's report history (JSON Lines)
+func (s *JSONStore) SaveReport(ctx context.Context, report *models.SyncReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if data, err = s.cipher.seal(data); err != nil {
+		return fmt.Errorf("failed to encrypt report: %w", err)
+	}
+
+	if err := os.MkdirAll(s.reportsDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	file, err := os.OpenFile(s.getReportsPath(report.ConnectorID), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open reports file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	s.logger.Debug("Saved report to JSON",
+		zap.String("connector_id", report.ConnectorID),
+		zap.String("status", report.Status),
+	)
+
+	return nil
+}
+
+// ListReports returns a page of a connector's recorded sync reports, newest first
+func (s *JSONStore) ListReports(ctx context.Context, connectorID string, query models.ReportQuery) (*models.ReportPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	file, err := os.Open(s.getReportsPath(connectorID))
+	if os.IsNotExist(err) {
+		return pageReports(nil, query), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reports file: %w", err)
+	}
+	defer file.Close()
+
+	var reports []models.SyncReport
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			report, decodeErr := s.decodeReport(line)
+			if decodeErr != nil {
+				// A torn last line (e.g. after a crash) loses that report only
+				s.logger.Warn("Failed to decode report", zap.String("connector_id", connectorID), zap.Error(decodeErr))
+			} else {
+				reports = append(reports, *report)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read reports file: %w", err)
+		}
+	}
+
+	return pageReports(reports, query), nil
+}
+
+// decodeReport decodes a line of a report history, decrypting it if it's encrypted. Lines recorded
+// before encryption was enabled stay readable.
+func (s *JSONStore) decodeReport(line []byte) (*models.SyncReport, error) {
+	data, err := s.cipher.open(bytes.TrimSpace(line))
+	if err != nil {
+		return nil, err
+	}
+	var report models.SyncReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
 // Close closes the JSON store (no-op for JSON)
 func (s *JSONStore) Close() error {
 	return nil
@@ -165,3 +267,13 @@ func (s *JSONStore) Close() error {
 func (s *JSONStore) getFilePath(connectorID string) string {
 	return filepath.Join(s.dirPath, fmt.Sprintf("%s.json", connectorID))
 }
+
+// reportsDir returns the directory of the report histories
+func (s *JSONStore) reportsDir() string {
+	return filepath.Join(s.dirPath, "reports")
+}
+
+// getReportsPath returns the file path for a connector's report history
+func (s *JSONStore) getReportsPath(connectorID string) string {
+	return filepath.Join(s.reportsDir(), fmt.Sprintf("%s.jsonl", connectorID))
+}
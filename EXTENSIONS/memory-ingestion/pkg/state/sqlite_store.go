@@ -15,11 +15,12 @@ import (
 // SQLiteStore implements StateManager using SQLite
 type SQLiteStore struct {
 	db     *sql.DB
+	cipher *Cipher // encrypts the JSON columns, nil stores them unencrypted
 	logger *zap.Logger
 }
 
-// NewSQLiteStore creates a new SQLite-based state store
-func NewSQLiteStore(dbPath string, logger *zap.Logger) (*SQLiteStore, error) {
+// NewSQLiteStore creates a new SQLite-based state store; cipher encrypts its JSON columns if not nil
+func NewSQLiteStore(dbPath string, cipher *Cipher, logger *zap.Logger) (*SQLiteStore, error) {
 	// Open database using pure Go driver (modernc.org/sqlite)
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
@@ -33,6 +34,7 @@ func NewSQLiteStore(dbPath string, logger *zap.Logger) (*SQLiteStore, error) {
 
 	store := &SQLiteStore{
 		db:     db,
+		cipher: cipher,
 		logger: logger,
 	}
 
@@ -42,7 +44,7 @@ func NewSQLiteStore(dbPath string, logger *zap.Logger) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
 
-	logger.Info("Initialized SQLite state store", zap.String("path", dbPath))
+	logger.Info("Initialized SQLite state store", zap.String("path", dbPath), zap.Bool("encrypted", cipher != nil))
 
 	return store, nil
 }
@@ -58,11 +60,27 @@ func (s *SQLiteStore) initSchema() error {
 		last_sync_report TEXT, -- JSON serialized SyncReport
 		failed_items TEXT, -- JSON array of FailedItem
 		total_sync_count INTEGER DEFAULT 0,
+		quota_usage TEXT, -- JSON serialized QuotaUsage
+		paused_at TIMESTAMP,
+		trips TEXT, -- JSON serialized TripState
+		places TEXT, -- JSON serialized PlaceState
+		citations TEXT, -- JSON object of short citation -> memory ID
+		versions TEXT, -- JSON object of memory ID -> ingested MemoryVersion
 		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_context_id ON sync_states(context_id);
 	CREATE INDEX IF NOT EXISTS idx_updated_at ON sync_states(updated_at);
+
+	CREATE TABLE IF NOT EXISTS sync_reports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		connector_id TEXT NOT NULL,
+		start_time INTEGER NOT NULL, -- Unix nanoseconds, for range queries
+		status TEXT NOT NULL,
+		report TEXT NOT NULL -- JSON serialized SyncReport
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_reports_connector_start ON sync_reports(connector_id, start_time);
 	`
 
 	_, err := s.db.Exec(schema)
@@ -70,6 +88,40 @@ func (s *SQLiteStore) initSchema() error {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
 
+	if err := s.addColumn("sync_states", "quota_usage", "TEXT"); err != nil {
+		return err
+	}
+	if err := s.addColumn("sync_states", "paused_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+	if err := s.addColumn("sync_states", "trips", "TEXT"); err != nil {
+		return err
+	}
+	if err := s.addColumn("sync_states", "places", "TEXT"); err != nil {
+		return err
+	}
+	if err := s.addColumn("sync_states", "citations", "TEXT"); err != nil {
+		return err
+	}
+	return s.addColumn("sync_states", "versions", "TEXT")
+}
+
+// addColumn adds a column to a table created by an older version, if it's missing
+func (s *SQLiteStore) addColumn(table, column, columnType string) error {
+	var count int
+	query := `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`
+	if err := s.db.QueryRow(query, table, column).Scan(&count); err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, columnType)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+
+	s.logger.Info("Migrated state schema", zap.String("table", table), zap.String("column", column))
 	return nil
 }
 
@@ -77,14 +129,14 @@ func (s *SQLiteStore) initSchema() error {
 func (s *SQLiteStore) GetState(ctx context.Context, connectorID string) (*models.SyncState, error) {
 	query := `
 		SELECT connector_id, context_id, last_sync_time, processed_ids,
-		       last_sync_report, failed_items, total_sync_count, updated_at
+		       last_sync_report, failed_items, total_sync_count, quota_usage, paused_at, trips, places, citations, versions, updated_at
 		FROM sync_states
 		WHERE connector_id = ?
 	`
 
 	var state models.SyncState
-	var lastSyncTime sql.NullTime
-	var processedIDsJSON, lastSyncReportJSON, failedItemsJSON sql.NullString
+	var lastSyncTime, pausedAt sql.NullTime
+	var processedIDsJSON, lastSyncReportJSON, failedItemsJSON, quotaUsageJSON, tripsJSON, placesJSON, citationsJSON, versionsJSON sql.NullString
 	var updatedAt time.Time
 
 	err := s.db.QueryRowContext(ctx, query, connectorID).Scan(
@@ -95,6 +147,12 @@ func (s *SQLiteStore) GetState(ctx context.Context, connectorID string) (*models
 		&lastSyncReportJSON,
 		&failedItemsJSON,
 		&state.TotalSyncCount,
+		&quotaUsageJSON,
+		&pausedAt,
+		&tripsJSON,
+		&placesJSON,
+		&citationsJSON,
+		&versionsJSON,
 		&updatedAt,
 	)
 
@@ -109,11 +167,17 @@ func (s *SQLiteStore) GetState(ctx context.Context, connectorID string) (*models
 	if err != nil {
 		return nil, fmt.Errorf("failed to query state: %w", err)
 	}
+	if err := s.openColumns(&processedIDsJSON, &lastSyncReportJSON, &failedItemsJSON, &quotaUsageJSON, &tripsJSON, &placesJSON, &citationsJSON, &versionsJSON); err != nil {
+		return nil, fmt.Errorf("failed to decrypt state: %w", err)
+	}
 
 	// Parse nullable fields
 	if lastSyncTime.Valid {
 		state.LastSyncTime = lastSyncTime.Time
 	}
+	if pausedAt.Valid {
+		state.PausedAt = &pausedAt.Time
+	}
 	state.UpdatedAt = updatedAt
 
 	// Unmarshal JSON fields
@@ -147,6 +211,45 @@ func (s *SQLiteStore) GetState(ctx context.Context, connectorID string) (*models
 		}
 	}
 
+	if quotaUsageJSON.Valid && quotaUsageJSON.String != "" {
+		var usage models.QuotaUsage
+		if err := json.Unmarshal([]byte(quotaUsageJSON.String), &usage); err != nil {
+			s.logger.Warn("Failed to unmarshal quota_usage", zap.Error(err))
+		} else {
+			state.QuotaUsage = &usage
+		}
+	}
+
+	if tripsJSON.Valid && tripsJSON.String != "" {
+		var trips models.TripState
+		if err := json.Unmarshal([]byte(tripsJSON.String), &trips); err != nil {
+			s.logger.Warn("Failed to unmarshal trips", zap.Error(err))
+		} else {
+			state.Trips = &trips
+		}
+	}
+
+	if placesJSON.Valid && placesJSON.String != "" {
+		var places models.PlaceState
+		if err := json.Unmarshal([]byte(placesJSON.String), &places); err != nil {
+			s.logger.Warn("Failed to unmarshal places", zap.Error(err))
+		} else {
+			state.Places = &places
+		}
+	}
+
+	if citationsJSON.Valid && citationsJSON.String != "" {
+		if err := json.Unmarshal([]byte(citationsJSON.String), &state.Citations); err != nil {
+			s.logger.Warn("Failed to unmarshal citations", zap.Error(err))
+		}
+	}
+
+	if versionsJSON.Valid && versionsJSON.String != "" {
+		if err := json.Unmarshal([]byte(versionsJSON.String), &state.Versions); err != nil {
+			s.logger.Warn("Failed to unmarshal versions", zap.Error(err))
+		}
+	}
+
 	s.logger.Debug("Retrieved state from SQLite",
 		zap.String("connector_id", connectorID),
 		zap.Int("processed_count", len(state.ProcessedIDs)),
@@ -179,11 +282,63 @@ func (s *SQLiteStore) SaveState(ctx context.Context, state *models.SyncState) er
 		}
 	}
 
+	var quotaUsageJSON []byte
+	if state.QuotaUsage != nil {
+		quotaUsageJSON, err = json.Marshal(state.QuotaUsage)
+		if err != nil {
+			return fmt.Errorf("failed to marshal quota_usage: %w", err)
+		}
+	}
+
+	var tripsJSON []byte
+	if state.Trips != nil {
+		tripsJSON, err = json.Marshal(state.Trips)
+		if err != nil {
+			return fmt.Errorf("failed to marshal trips: %w", err)
+		}
+	}
+
+	var placesJSON []byte
+	if state.Places != nil {
+		placesJSON, err = json.Marshal(state.Places)
+		if err != nil {
+			return fmt.Errorf("failed to marshal places: %w", err)
+		}
+	}
+
+	var citationsJSON []byte
+	if state.Citations != nil {
+		citationsJSON, err = json.Marshal(state.Citations)
+		if err != nil {
+			return fmt.Errorf("failed to marshal citations: %w", err)
+		}
+	}
+
+	var versionsJSON []byte
+	if state.Versions != nil {
+		versionsJSON, err = json.Marshal(state.Versions)
+		if err != nil {
+			return fmt.Errorf("failed to marshal versions: %w", err)
+		}
+	}
+
+	columns := []*[]byte{&processedIDsJSON, &lastSyncReportJSON, &failedItemsJSON, &quotaUsageJSON, &tripsJSON, &placesJSON, &citationsJSON, &versionsJSON}
+	for _, column := range columns {
+		if *column, err = s.cipher.seal(*column); err != nil {
+			return fmt.Errorf("failed to encrypt state: %w", err)
+		}
+	}
+
+	var pausedAt sql.NullTime
+	if state.PausedAt != nil {
+		pausedAt = sql.NullTime{Time: *state.PausedAt, Valid: true}
+	}
+
 	query := `
 		INSERT INTO sync_states
 			(connector_id, context_id, last_sync_time, processed_ids,
-			 last_sync_report, failed_items, total_sync_count, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			 last_sync_report, failed_items, total_sync_count, quota_usage, paused_at, trips, places, citations, versions, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(connector_id) DO UPDATE SET
 			context_id = excluded.context_id,
 			last_sync_time = excluded.last_sync_time,
@@ -191,6 +346,12 @@ func (s *SQLiteStore) SaveState(ctx context.Context, state *models.SyncState) er
 			last_sync_report = excluded.last_sync_report,
 			failed_items = excluded.failed_items,
 			total_sync_count = excluded.total_sync_count,
+			quota_usage = excluded.quota_usage,
+			paused_at = excluded.paused_at,
+			trips = excluded.trips,
+			places = excluded.places,
+			citations = excluded.citations,
+			versions = excluded.versions,
 			updated_at = excluded.updated_at
 	`
 
@@ -202,6 +363,12 @@ func (s *SQLiteStore) SaveState(ctx context.Context, state *models.SyncState) er
 		string(lastSyncReportJSON),
 		string(failedItemsJSON),
 		state.TotalSyncCount,
+		string(quotaUsageJSON),
+		pausedAt,
+		string(tripsJSON),
+		string(placesJSON),
+		string(citationsJSON),
+		string(versionsJSON),
 		time.Now(),
 	)
 
@@ -235,7 +402,7 @@ func (s *SQLiteStore) DeleteState(ctx context.Context, connectorID string) error
 func (s *SQLiteStore) ListStates(ctx context.Context) ([]models.SyncState, error) {
 	query := `
 		SELECT connector_id, context_id, last_sync_time, processed_ids,
-		       last_sync_report, failed_items, total_sync_count, updated_at
+		       last_sync_report, failed_items, total_sync_count, quota_usage, paused_at, trips, places, citations, versions, updated_at
 		FROM sync_states
 		ORDER BY updated_at DESC
 	`
@@ -250,8 +417,8 @@ func (s *SQLiteStore) ListStates(ctx context.Context) ([]models.SyncState, error
 
 	for rows.Next() {
 		var state models.SyncState
-		var lastSyncTime sql.NullTime
-		var processedIDsJSON, lastSyncReportJSON, failedItemsJSON sql.NullString
+		var lastSyncTime, pausedAt sql.NullTime
+		var processedIDsJSON, lastSyncReportJSON, failedItemsJSON, quotaUsageJSON, tripsJSON, placesJSON, citationsJSON, versionsJSON sql.NullString
 		var updatedAt time.Time
 
 		err := rows.Scan(
@@ -262,6 +429,12 @@ func (s *SQLiteStore) ListStates(ctx context.Context) ([]models.SyncState, error
 			&lastSyncReportJSON,
 			&failedItemsJSON,
 			&state.TotalSyncCount,
+			&quotaUsageJSON,
+			&pausedAt,
+			&tripsJSON,
+			&placesJSON,
+			&citationsJSON,
+			&versionsJSON,
 			&updatedAt,
 		)
 
@@ -269,10 +442,17 @@ func (s *SQLiteStore) ListStates(ctx context.Context) ([]models.SyncState, error
 			s.logger.Warn("Failed to scan state row", zap.Error(err))
 			continue
 		}
+		if err := s.openColumns(&processedIDsJSON, &lastSyncReportJSON, &failedItemsJSON, &quotaUsageJSON, &tripsJSON, &placesJSON, &citationsJSON, &versionsJSON); err != nil {
+			s.logger.Warn("Failed to decrypt state", zap.String("connector_id", state.ConnectorID), zap.Error(err))
+			continue
+		}
 
 		if lastSyncTime.Valid {
 			state.LastSyncTime = lastSyncTime.Time
 		}
+		if pausedAt.Valid {
+			state.PausedAt = &pausedAt.Time
+		}
 		state.UpdatedAt = updatedAt
 
 		// Unmarshal JSON fields
@@ -294,6 +474,35 @@ func (s *SQLiteStore) ListStates(ctx context.Context) ([]models.SyncState, error
 			json.Unmarshal([]byte(failedItemsJSON.String), &state.FailedItems)
 		}
 
+		if quotaUsageJSON.Valid && quotaUsageJSON.String != "" {
+			var usage models.QuotaUsage
+			if err := json.Unmarshal([]byte(quotaUsageJSON.String), &usage); err == nil {
+				state.QuotaUsage = &usage
+			}
+		}
+
+		if tripsJSON.Valid && tripsJSON.String != "" {
+			var trips models.TripState
+			if err := json.Unmarshal([]byte(tripsJSON.String), &trips); err == nil {
+				state.Trips = &trips
+			}
+		}
+
+		if placesJSON.Valid && placesJSON.String != "" {
+			var places models.PlaceState
+			if err := json.Unmarshal([]byte(placesJSON.String), &places); err == nil {
+				state.Places = &places
+			}
+		}
+
+		if citationsJSON.Valid && citationsJSON.String != "" {
+			json.Unmarshal([]byte(citationsJSON.String), &state.Citations)
+		}
+
+		if versionsJSON.Valid && versionsJSON.String != "" {
+			json.Unmarshal([]byte(versionsJSON.String), &state.Versions)
+		}
+
 		states = append(states, state)
 	}
 
@@ -306,6 +515,120 @@ func (s *SQLiteStore) ListStates(ctx context.Context) ([]models.SyncState, error
 	return states, nil
 }
 
+// SaveReport records a sync report in the connector's report history
+func (s *SQLiteStore) SaveReport(ctx context.Context, report *models.SyncReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if data, err = s.cipher.seal(data); err != nil {
+		return fmt.Errorf("failed to encrypt report: %w", err)
+	}
+
+	query := `
+		INSERT INTO sync_reports (connector_id, start_time, status, report)
+		VALUES (?, ?, ?, ?)
+	`
+
+	_, err = s.db.ExecContext(ctx, query,
+		report.ConnectorID,
+		report.StartTime.UnixNano(),
+		report.Status,
+		string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+
+	s.logger.Debug("Saved report to SQLite",
+		zap.String("connector_id", report.ConnectorID),
+		zap.String("status", report.Status),
+	)
+
+	return nil
+}
+
+// ListReports returns a page of a connector's recorded sync reports, newest first
+func (s *SQLiteStore) ListReports(ctx context.Context, connectorID string, query models.ReportQuery) (*models.ReportPage, error) {
+	where := "connector_id = ?"
+	args := []interface{}{connectorID}
+	if !query.Since.IsZero() {
+		where += " AND start_time >= ?"
+		args = append(args, query.Since.UnixNano())
+	}
+	if !query.Until.IsZero() {
+		where += " AND start_time < ?"
+		args = append(args, query.Until.UnixNano())
+	}
+	if query.Status != "" {
+		where += " AND status = ?"
+		args = append(args, query.Status)
+	}
+
+	page := &models.ReportPage{
+		Reports: []models.SyncReport{},
+		Limit:   query.Limit,
+		Offset:  query.Offset,
+	}
+
+	countQuery := "SELECT COUNT(*) FROM sync_reports WHERE " + where
+	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count reports: %w", err)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	listQuery := "SELECT report FROM sync_reports WHERE " + where + " ORDER BY start_time DESC, id DESC LIMIT ? OFFSET ?"
+	rows, err := s.db.QueryContext(ctx, listQuery, append(args, limit, query.Offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reports: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			s.logger.Warn("Failed to scan report row", zap.Error(err))
+			continue
+		}
+		if data, err = s.cipher.open(data); err != nil {
+			s.logger.Warn("Failed to decrypt report", zap.Error(err))
+			continue
+		}
+
+		var report models.SyncReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			s.logger.Warn("Failed to unmarshal report", zap.Error(err))
+			continue
+		}
+		page.Reports = append(page.Reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reports: %w", err)
+	}
+
+	return page, nil
+}
+
+// openColumns decrypts JSON columns in place. Values written before encryption was enabled are
+// kept as they are.
+func (s *SQLiteStore) openColumns(columns ...*sql.NullString) error {
+	for _, column := range columns {
+		if !column.Valid || column.String == "" {
+			continue
+		}
+		plaintext, err := s.cipher.open([]byte(column.String))
+		if err != nil {
+			return err
+		}
+		column.String = string(plaintext)
+	}
+	return nil
+}
+
 // Close closes the database connection
 func (s *SQLiteStore) Close() error {
 	return s.db.Close()
@@ -22,6 +22,12 @@ type StateManager interface {
 	// ListStates lists all connector states
 	ListStates(ctx context.Context) ([]models.SyncState, error)
 
+	// SaveReport records a finished sync report in the connector's report history
+	SaveReport(ctx context.Context, report *models.SyncReport) error
+
+	// ListReports returns a page of a connector's recorded sync reports, newest first
+	ListReports(ctx context.Context, connectorID string, query models.ReportQuery) (*models.ReportPage, error)
+
 	// Close closes the state manager
 	Close() error
 }
@@ -30,16 +36,47 @@ type StateManager interface {
 type Config struct {
 	Type string // json or sqlite (as per user's answer: both in parallel)
 	Path string // directory for json files or sqlite db path
+
+	// Cipher encrypts states and reports at rest, nil stores them unencrypted
+	Cipher *Cipher
 }
 
 // NewStateManager creates a new state manager based on configuration
 func NewStateManager(config Config, logger *zap.Logger) (StateManager, error) {
 	switch config.Type {
 	case "json":
-		return NewJSONStore(config.Path, logger)
+		return NewJSONStore(config.Path, config.Cipher, logger)
 	case "sqlite":
-		return NewSQLiteStore(config.Path, logger)
+		return NewSQLiteStore(config.Path, config.Cipher, logger)
 	default:
 		return nil, fmt.Errorf("unsupported state manager type: %s (must be 'json' or 'sqlite')", config.Type)
 	}
 }
+
+// pageReports filters reports (oldest first, as recorded) and returns the requested page, newest first
+func pageReports(reports []models.SyncReport, query models.ReportQuery) *models.ReportPage {
+	matching := make([]models.SyncReport, 0, len(reports))
+	for i := len(reports) - 1; i >= 0; i-- {
+		if query.Matches(&reports[i]) {
+			matching = append(matching, reports[i])
+		}
+	}
+
+	page := &models.ReportPage{
+		Reports: []models.SyncReport{},
+		Total:   len(matching),
+		Limit:   query.Limit,
+		Offset:  query.Offset,
+	}
+	if query.Offset >= len(matching) {
+		return page
+	}
+
+	matching = matching[query.Offset:]
+	if query.Limit > 0 && query.Limit < len(matching) {
+		matching = matching[:query.Limit]
+	}
+	page.Reports = matching
+
+	return page
+}
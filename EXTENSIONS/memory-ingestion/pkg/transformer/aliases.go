@@ -0,0 +1,32 @@
+package transformer
+
+import (
+	"sort"
+	"strings"
+)
+
+// AliasResolver names the canonical entities of the aliases a context's text uses
+type AliasResolver interface {
+	// CanonicalNames returns the aliases used in text, alias -> canonical name
+	CanonicalNames(contextID, text string) map[string]string
+}
+
+// aliasContext returns the context line naming the canonical entities of aliases, e.g.
+// "[Aliases: Mike refers to Michael Schmidt]", or "" if there are none
+func aliasContext(aliases map[string]string) string {
+	if len(aliases) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+
+	hints := make([]string, len(names))
+	for i, alias := range names {
+		hints[i] = alias + " refers to " + aliases[alias]
+	}
+	return "[Aliases: " + strings.Join(hints, "; ") + "]"
+}
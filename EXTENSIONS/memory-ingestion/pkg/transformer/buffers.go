@@ -0,0 +1,42 @@
+package transformer
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+)
+
+// maxPooledBufferBytes bounds the buffers kept for reuse, so a huge transcript doesn't pin its
+// buffer for the rest of the process
+const maxPooledBufferBytes = 64 << 10
+
+// bufferPool reuses the buffers documents are built in across transformations. A strings.Builder
+// gives up its buffer with the string it builds, so documents are built in a bytes.Buffer and
+// copied out once.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns a buffer to the pool; its contents must no longer be used
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBufferBytes {
+		bufferPool.Put(buf)
+	}
+}
+
+// writeFloat writes a float with prec decimals, as fmt's %.<prec>f does
+func writeFloat(buf *bytes.Buffer, value float64, prec int) {
+	buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), value, 'f', prec, 64))
+}
+
+// formatCoordinate formats a coordinate for metadata, as fmt's %f does
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(value, 'f', 6, 64)
+}
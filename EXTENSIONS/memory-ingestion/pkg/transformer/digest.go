@@ -0,0 +1,101 @@
+package transformer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+)
+
+// DigestEntry is a transformed memory of a daily digest
+type DigestEntry struct {
+	Memory    *models.Memory
+	CreatedAt time.Time // in the digest's time zone
+	Text      string    // the memory as transformed by the strategy
+}
+
+// ComposeDigest combines the transformed memories of one calendar day into a single document with a
+// chronological section per memory. Its metadata combines the memory types as tags and the
+// locations of all memories.
+func ComposeDigest(day string, entries []DigestEntry, config TransformConfig) (string, map[string]string) {
+	sorted := make([]DigestEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	tags := make(map[string]bool)
+	var locations []string
+	seenLocations := make(map[string]bool)
+	ids := make([]string, 0, len(sorted))
+	hasAudio, hasImage := false, false
+	for _, entry := range sorted {
+		memory := entry.Memory
+		ids = append(ids, memory.ID)
+		if memory.Type != "" {
+			tags[memory.Type] = true
+		}
+		if memory.HasLocation() && config.EnrichLocation {
+			location := fmt.Sprintf("%.6f,%.6f", *memory.LocationLat, *memory.LocationLon)
+			if !seenLocations[location] {
+				seenLocations[location] = true
+				locations = append(locations, location)
+			}
+		}
+		hasAudio = hasAudio || memory.HasAudio()
+		hasImage = hasImage || memory.HasImage()
+	}
+	tagList := make([]string, 0, len(tags))
+	for tag := range tags {
+		tagList = append(tagList, tag)
+	}
+	sort.Strings(tagList)
+
+	// Build text content: a header describing the day, then one section per memory
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("[Daily digest for %s: %d memories]\n", day, len(sorted)))
+	if len(tagList) > 0 {
+		builder.WriteString(fmt.Sprintf("[Tags: %s]\n", strings.Join(tagList, ", ")))
+	}
+	if len(locations) > 0 {
+		builder.WriteString(fmt.Sprintf("[Locations: %s]\n", strings.Join(locations, "; ")))
+	}
+	for _, entry := range sorted {
+		builder.WriteString(fmt.Sprintf("\n## %s", entry.CreatedAt.Format("15:04")))
+		if entry.Memory.Type != "" {
+			builder.WriteString(" · " + entry.Memory.Type)
+		}
+		builder.WriteString(" · " + utils.MemoryURI(entry.Memory.ID) + "\n")
+		builder.WriteString(strings.TrimSpace(entry.Text))
+		builder.WriteString("\n")
+	}
+
+	// Build metadata
+	metadata := make(map[string]string)
+
+	if config.IncludeMetadata {
+		metadata["digest_date"] = day
+		metadata["context_id"] = config.ContextID
+		metadata["transformation_mode"] = models.TransformModeDailyDigest
+		metadata["memory_ids"] = strings.Join(ids, ",")
+		metadata["memory_count"] = strconv.Itoa(len(ids))
+		if len(tagList) > 0 {
+			metadata["tags"] = strings.Join(tagList, ",")
+		}
+		if len(locations) > 0 {
+			metadata["locations"] = strings.Join(locations, ";")
+		}
+		if hasAudio {
+			metadata["has_audio"] = "true"
+		}
+		if hasImage {
+			metadata["has_image"] = "true"
+		}
+	}
+
+	return builder.String(), metadata
+}
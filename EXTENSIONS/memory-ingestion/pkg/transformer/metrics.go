@@ -0,0 +1,127 @@
+package transformer
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// outputLengthBuckets are the upper bounds (in bytes) of the output length histogram
+var outputLengthBuckets = []int{256, 1024, 4096, 16384, 65536}
+
+// strategyMetrics accumulates the transformations of a transformer since it was created
+type strategyMetrics struct {
+	mu             sync.Mutex
+	transforms     int64
+	errors         int64
+	duration       time.Duration
+	maxDuration    time.Duration
+	outputBytes    int64
+	lengthCounts   []int64          // per outputLengthBuckets entry, plus one for longer outputs
+	metadataFields int64            // metadata fields of all documents
+	fieldCounts    map[string]int64 // metadata field -> documents carrying it
+	enriched       int64            // documents enriched with the memory's location
+}
+
+// newStrategyMetrics creates empty metrics
+func newStrategyMetrics() *strategyMetrics {
+	return &strategyMetrics{
+		lengthCounts: make([]int64, len(outputLengthBuckets)+1),
+		fieldCounts:  make(map[string]int64),
+	}
+}
+
+// record adds a transformation
+func (m *strategyMetrics) record(
+	memory *models.Memory,
+	config TransformConfig,
+	text string,
+	metadata map[string]string,
+	duration time.Duration,
+	err error,
+) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.transforms++
+	m.duration += duration
+	if duration > m.maxDuration {
+		m.maxDuration = duration
+	}
+	if err != nil {
+		m.errors++
+		return
+	}
+
+	m.outputBytes += int64(len(text))
+	bucket := sort.SearchInts(outputLengthBuckets, len(text))
+	m.lengthCounts[bucket]++
+
+	m.metadataFields += int64(len(metadata))
+	for field := range metadata {
+		m.fieldCounts[field]++
+	}
+
+	if config.EnrichLocation && memory.HasLocation() {
+		m.enriched++
+	}
+}
+
+// StrategyMetrics is a snapshot of the transformations of a strategy since the process started
+type StrategyMetrics struct {
+	Strategy          string           `json:"strategy"`
+	Transforms        int64            `json:"transforms"`
+	Errors            int64            `json:"errors"`
+	ErrorRate         float64          `json:"error_rate"` // errors per transform, 0 to 1
+	AvgDurationUs     int64            `json:"avg_duration_us"`
+	MaxDurationUs     int64            `json:"max_duration_us"`
+	AvgOutputBytes    int64            `json:"avg_output_bytes"`
+	OutputLength      []LengthBucket   `json:"output_length"`       // distribution of successful outputs
+	AvgMetadataFields float64          `json:"avg_metadata_fields"` // per successful document
+	MetadataFields    map[string]int64 `json:"metadata_fields"`     // documents carrying each metadata field
+	LocationEnriched  int64            `json:"location_enriched"`   // documents enriched with the memory's location
+}
+
+// LengthBucket counts outputs of at most MaxBytes bytes (and longer than the previous bucket).
+// The last bucket has no MaxBytes.
+type LengthBucket struct {
+	MaxBytes int   `json:"max_bytes,omitempty"`
+	Count    int64 `json:"count"`
+}
+
+// snapshot returns the metrics of strategy
+func (m *strategyMetrics) snapshot(strategy string) StrategyMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := StrategyMetrics{
+		Strategy:         strategy,
+		Transforms:       m.transforms,
+		Errors:           m.errors,
+		MaxDurationUs:    m.maxDuration.Microseconds(),
+		OutputLength:     make([]LengthBucket, len(m.lengthCounts)),
+		MetadataFields:   make(map[string]int64, len(m.fieldCounts)),
+		LocationEnriched: m.enriched,
+	}
+	if m.transforms > 0 {
+		snapshot.ErrorRate = float64(m.errors) / float64(m.transforms)
+		snapshot.AvgDurationUs = m.duration.Microseconds() / m.transforms
+	}
+	if succeeded := m.transforms - m.errors; succeeded > 0 {
+		snapshot.AvgOutputBytes = m.outputBytes / succeeded
+		snapshot.AvgMetadataFields = float64(m.metadataFields) / float64(succeeded)
+	}
+	for i, count := range m.lengthCounts {
+		snapshot.OutputLength[i].Count = count
+		if i < len(outputLengthBuckets) {
+			snapshot.OutputLength[i].MaxBytes = outputLengthBuckets[i]
+		}
+	}
+	for field, count := range m.fieldCounts {
+		snapshot.MetadataFields[field] = count
+	}
+
+	return snapshot
+}
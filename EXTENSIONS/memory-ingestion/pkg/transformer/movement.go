@@ -0,0 +1,66 @@
+package transformer
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// compassPoints name headings in 45° steps, clockwise from north
+var compassPoints = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// compassPoint returns the compass point nearest to a heading, e.g. "NE" for 40°
+func compassPoint(heading float64) string {
+	return compassPoints[int(math.Round(heading/45))%len(compassPoints)]
+}
+
+// writeMovementContext writes the context line describing the altitude, speed, and heading of a
+// memory, e.g. "[Movement: 42.5 km/h heading NE (45°), altitude 520 m]"; nothing if the source
+// recorded none of them
+func writeMovementContext(buf *bytes.Buffer, memory *models.Memory) {
+	speed, hasSpeed := memory.Speed()
+	heading, hasHeading := memory.Heading()
+	if !hasSpeed && !hasHeading && memory.LocationAltitude == nil {
+		return
+	}
+
+	buf.WriteString("[Movement: ")
+	if hasSpeed {
+		writeFloat(buf, speed*3.6, 1)
+		buf.WriteString(" km/h")
+	}
+	if hasHeading && (!hasSpeed || speed > 0) {
+		if hasSpeed {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString("heading ")
+		buf.WriteString(compassPoint(heading))
+		buf.WriteString(" (")
+		writeFloat(buf, heading, 0)
+		buf.WriteString("°)")
+	}
+	if memory.LocationAltitude != nil {
+		if hasSpeed || hasHeading {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("altitude ")
+		writeFloat(buf, *memory.LocationAltitude, 0)
+		buf.WriteString(" m")
+	}
+	buf.WriteString("]\n\n")
+}
+
+// addMovementMetadata adds the known altitude (m), speed (m/s), and heading (degrees) of a memory
+func addMovementMetadata(memory *models.Memory, metadata map[string]string) {
+	if memory.LocationAltitude != nil {
+		metadata["location_altitude_m"] = strconv.FormatFloat(*memory.LocationAltitude, 'f', 1, 64)
+	}
+	if speed, ok := memory.Speed(); ok {
+		metadata["location_speed_mps"] = strconv.FormatFloat(speed, 'f', 2, 64)
+	}
+	if heading, ok := memory.Heading(); ok {
+		metadata["location_heading_deg"] = strconv.FormatFloat(heading, 'f', 1, 64)
+	}
+}
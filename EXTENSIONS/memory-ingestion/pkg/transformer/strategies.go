@@ -2,11 +2,29 @@ package transformer
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
 )
 
+// Metadata fields the strategies set at most (with timestamps and all of the memory's data), so
+// their maps are allocated once
+const (
+	timestampMetadataFields = 3
+	standardMetadataFields  = 18
+	richMetadataFields      = 30
+)
+
+// newMetadata returns a metadata map with room for the fields of a strategy setting at most fields
+func newMetadata(config TransformConfig, fields int) map[string]string {
+	if !config.IncludeMetadata {
+		return make(map[string]string, timestampMetadataFields)
+	}
+	return make(map[string]string, fields+len(config.RawFields))
+}
+
 // StandardStrategy provides basic transformation of memory to text
 type StandardStrategy struct{}
 
@@ -21,23 +39,25 @@ func (s *StandardStrategy) Transform(memory *models.Memory, config TransformConf
 		return "", nil, fmt.Errorf("memory %s has no transcript", memory.ID)
 	}
 
-	// Build text content
-	var builder strings.Builder
-	builder.WriteString(memory.Transcript)
-
 	// Build metadata
-	metadata := make(map[string]string)
+	metadata := newMetadata(config, standardMetadataFields)
+
+	// Build text content
+	buf := getBuffer()
+	defer putBuffer(buf)
+	writeTranscript(buf, memory, config, metadata)
 
 	if config.IncludeMetadata {
 		metadata["memory_id"] = memory.ID
 		metadata["memory_type"] = memory.Type
 		metadata["created_at"] = memory.CreatedAt
 		metadata["context_id"] = config.ContextID
-		metadata["file_path"] = fmt.Sprintf("api://memory-connector/%s", memory.ID)
+		metadata["file_path"] = config.filePath(memory.ID)
 
 		if memory.HasLocation() && config.EnrichLocation {
-			metadata["location_lat"] = fmt.Sprintf("%f", *memory.LocationLat)
-			metadata["location_lon"] = fmt.Sprintf("%f", *memory.LocationLon)
+			metadata["location_lat"] = formatCoordinate(*memory.LocationLat)
+			metadata["location_lon"] = formatCoordinate(*memory.LocationLon)
+			addS2Cell(memory, config, metadata)
 		}
 
 		if memory.HasAudio() {
@@ -47,9 +67,58 @@ func (s *StandardStrategy) Transform(memory *models.Memory, config TransformConf
 		if memory.HasImage() {
 			metadata["has_image"] = "true"
 		}
+
+		addLocalTime(memory, metadata)
+		addRawFields(memory, config, metadata)
 	}
 
-	return builder.String(), metadata, nil
+	return buf.String(), metadata, nil
+}
+
+// addLocalTime adds the time zone at a memory's location and the date, hour, and weekday it was
+// created there, if the time zone is known
+func addLocalTime(memory *models.Memory, metadata map[string]string) {
+	local, ok := memory.LocalCreatedAt()
+	if !ok {
+		return
+	}
+	metadata["timezone"] = memory.LocalTimeZone.String()
+	metadata["local_created_date"] = local.Format("2006-01-02")
+	metadata["local_created_hour"] = strconv.Itoa(local.Hour())
+	metadata["local_created_weekday"] = local.Weekday().String()
+}
+
+// addRawFields adds the raw fields of a memory mapped by config.RawFields, without replacing the
+// metadata the strategy set
+func addRawFields(memory *models.Memory, config TransformConfig, metadata map[string]string) {
+	for field, key := range config.RawFields {
+		if _, ok := metadata[key]; ok {
+			continue
+		}
+		if value, ok := memory.RawField(field); ok {
+			metadata[key] = value
+		}
+	}
+}
+
+// addS2Cell adds the token of the S2 cell containing a located memory at config.S2Level, for
+// S2-based spatial tooling
+func addS2Cell(memory *models.Memory, config TransformConfig, metadata map[string]string) {
+	if config.S2Level > 0 {
+		metadata["location_s2_cell"] = utils.S2CellToken(utils.S2CellID(*memory.LocationLat, *memory.LocationLon, config.S2Level))
+	}
+}
+
+// richTranscriptHeading introduces the transcript of rich documents, after their context lines
+const richTranscriptHeading = "Transcript:\n"
+
+// AddRichContext adds a context line (e.g. "[Weather: light rain, 14.5 °C]") to a document of the
+// rich strategy, after the context lines the strategy wrote
+func AddRichContext(text, line string) string {
+	if i := strings.Index(text, richTranscriptHeading); i >= 0 {
+		return text[:i] + line + "\n\n" + text[i:]
+	}
+	return line + "\n\n" + text
 }
 
 // RichStrategy provides enriched transformation with contextual information
@@ -67,55 +136,70 @@ func (s *RichStrategy) Transform(memory *models.Memory, config TransformConfig)
 	}
 
 	// Build rich text content with contextual information
-	var builder strings.Builder
-
-	// Add temporal context
-	parsedTime, err := memory.ParseCreatedAt()
-	if err == nil {
-		builder.WriteString(fmt.Sprintf("[Memory from %s]\n\n", parsedTime.Format("2006-01-02 15:04:05")))
+	buf := getBuffer()
+	defer putBuffer(buf)
+	metadata := newMetadata(config, richMetadataFields)
+
+	// Add temporal context, in local time where the memory was recorded if its time zone is known
+	createdAt, timeErr := memory.ParseCreatedAt()
+	if local, ok := memory.LocalCreatedAt(); ok {
+		buf.WriteString("[Memory from ")
+		buf.Write(local.AppendFormat(buf.AvailableBuffer(), "2006-01-02 15:04:05"))
+		buf.WriteString(" (")
+		buf.WriteString(memory.LocalTimeZone.String())
+		buf.WriteString(")]\n\n")
+	} else if timeErr == nil {
+		buf.WriteString("[Memory from ")
+		buf.Write(createdAt.AppendFormat(buf.AvailableBuffer(), "2006-01-02 15:04:05"))
+		buf.WriteString("]\n\n")
 	}
 
 	// Add location context if available
 	if memory.HasLocation() && config.EnrichLocation {
-		builder.WriteString(fmt.Sprintf("[Location: %.6f, %.6f]\n\n", *memory.LocationLat, *memory.LocationLon))
+		buf.WriteString("[Location: ")
+		writeFloat(buf, *memory.LocationLat, 6)
+		buf.WriteString(", ")
+		writeFloat(buf, *memory.LocationLon, 6)
+		buf.WriteString("]\n\n")
+		writeMovementContext(buf, memory)
 	}
 
 	// Add media availability context
-	mediaInfo := []string{}
-	if memory.HasAudio() {
-		mediaInfo = append(mediaInfo, "audio recording available")
-	}
-	if memory.HasImage() {
-		mediaInfo = append(mediaInfo, "image available")
-	}
-	if len(mediaInfo) > 0 {
-		builder.WriteString(fmt.Sprintf("[Media: %s]\n\n", strings.Join(mediaInfo, ", ")))
+	switch {
+	case memory.HasAudio() && memory.HasImage():
+		buf.WriteString("[Media: audio recording available, image available]\n\n")
+	case memory.HasAudio():
+		buf.WriteString("[Media: audio recording available]\n\n")
+	case memory.HasImage():
+		buf.WriteString("[Media: image available]\n\n")
 	}
 
 	// Add the main transcript
-	builder.WriteString("Transcript:\n")
-	builder.WriteString(memory.Transcript)
-	builder.WriteString("\n")
+	buf.WriteString(richTranscriptHeading)
+	writeTranscript(buf, memory, config, metadata)
+	buf.WriteString("\n")
 
 	// Add memory type context
 	if memory.Type != "" {
-		builder.WriteString(fmt.Sprintf("\n[Type: %s]", memory.Type))
+		buf.WriteString("\n[Type: ")
+		buf.WriteString(memory.Type)
+		buf.WriteString("]")
 	}
 
 	// Build metadata (similar to standard but with additional enrichments)
-	metadata := make(map[string]string)
-
 	if config.IncludeMetadata {
 		metadata["memory_id"] = memory.ID
 		metadata["memory_type"] = memory.Type
 		metadata["created_at"] = memory.CreatedAt
 		metadata["context_id"] = config.ContextID
 		metadata["transformation_strategy"] = "rich"
-		metadata["file_path"] = fmt.Sprintf("api://memory-connector/%s", memory.ID)
+		metadata["file_path"] = config.filePath(memory.ID)
 
 		if memory.HasLocation() {
-			metadata["location_lat"] = fmt.Sprintf("%f", *memory.LocationLat)
-			metadata["location_lon"] = fmt.Sprintf("%f", *memory.LocationLon)
+			metadata["location_lat"] = formatCoordinate(*memory.LocationLat)
+			metadata["location_lon"] = formatCoordinate(*memory.LocationLon)
+			addS2Cell(memory, config, metadata)
+			addMovementMetadata(memory, metadata)
 
 			// Add enrichment flag
 			if config.EnrichLocation {
@@ -138,14 +222,16 @@ func (s *RichStrategy) Transform(memory *models.Memory, config TransformConfig)
 		}
 
 		// Add temporal metadata
-		if parsedTime, err := memory.ParseCreatedAt(); err == nil {
-			metadata["year"] = fmt.Sprintf("%d", parsedTime.Year())
-			metadata["month"] = fmt.Sprintf("%d", parsedTime.Month())
-			metadata["day"] = fmt.Sprintf("%d", parsedTime.Day())
-			metadata["hour"] = fmt.Sprintf("%d", parsedTime.Hour())
-			metadata["weekday"] = parsedTime.Weekday().String()
+		if timeErr == nil {
+			metadata["year"] = strconv.Itoa(createdAt.Year())
+			metadata["month"] = strconv.Itoa(int(createdAt.Month()))
+			metadata["day"] = strconv.Itoa(createdAt.Day())
+			metadata["hour"] = strconv.Itoa(createdAt.Hour())
+			metadata["weekday"] = createdAt.Weekday().String()
 		}
+		addLocalTime(memory, metadata)
+		addRawFields(memory, config, metadata)
 	}
 
-	return builder.String(), metadata, nil
+	return buf.String(), metadata, nil
 }
@@ -0,0 +1,81 @@
+package transformer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"go.uber.org/zap"
+)
+
+// benchBatchSize is the number of memories transformed per benchmark iteration
+const benchBatchSize = 1000
+
+// benchMemories returns a batch of located audio memories with timed transcripts
+func benchMemories() []models.Memory {
+	start := time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)
+	memories := make([]models.Memory, benchBatchSize)
+	for i := range memories {
+		lat, lon := 52.52+float64(i%100)*0.001, 13.405+float64(i%50)*0.001
+		altitude, speed, heading := 34.0, 1.4, float64(i%360)
+		memories[i] = models.Memory{
+			ID:               fmt.Sprintf("mem-%04d", i),
+			Type:             "voice",
+			Audio:            true,
+			GcsUri:           fmt.Sprintf("gs://memories/audio/mem-%04d.m4a", i),
+			Transcript:       "Met Anna at the market. We talked about the trip to Lisbon next month. She recommended the bakery near the station.",
+			LocationLat:      &lat,
+			LocationLon:      &lon,
+			LocationAltitude: &altitude,
+			LocationSpeed:    &speed,
+			LocationHeading:  &heading,
+			CreatedAt:        start.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+			Segments: []models.TranscriptSegment{
+				{Start: 0, End: 2.4, Text: "Met Anna at the market."},
+				{Start: 2.4, End: 6.1, Text: "We talked about the trip to Lisbon next month."},
+				{Start: 6.1, End: 9.8, Text: "She recommended the bakery near the station."},
+			},
+		}
+	}
+	return memories
+}
+
+// benchmarkStrategy transforms batches of memories with a strategy, with metadata, with timestamps,
+// and without metadata
+func benchmarkStrategy(b *testing.B, strategy string) {
+	variants := []struct {
+		name   string
+		config TransformConfig
+	}{
+		{"metadata", TransformConfig{IncludeMetadata: true, EnrichLocation: true, ContextID: "bench", S2Level: 13}},
+		{"timestamps", TransformConfig{IncludeMetadata: true, EnrichLocation: true, ContextID: "bench", S2Level: 13, Timestamps: true}},
+		{"no_metadata", TransformConfig{ContextID: "bench"}},
+	}
+
+	memories := benchMemories()
+	for _, variant := range variants {
+		b.Run(variant.name, func(b *testing.B) {
+			trans, err := NewTransformer(strategy, zap.NewNop())
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := trans.TransformBatch(memories, variant.config); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStandard(b *testing.B) {
+	benchmarkStrategy(b, "standard")
+}
+
+func BenchmarkRich(b *testing.B) {
+	benchmarkStrategy(b, "rich")
+}
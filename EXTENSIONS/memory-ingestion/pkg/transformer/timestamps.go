@@ -0,0 +1,63 @@
+package transformer
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// writeTranscript writes the transcript to ingest. With timestamps, timed transcripts are rebuilt
+// from their segments, each starting with a [t=mm:ss] marker, and the segment offsets are added to
+// metadata for deep-linking into the recording.
+func writeTranscript(buf *bytes.Buffer, memory *models.Memory, config TransformConfig, metadata map[string]string) {
+	if !config.Timestamps || !memory.HasSegments() {
+		buf.WriteString(memory.Transcript)
+		return
+	}
+
+	// Two offsets of up to 8 bytes each per segment
+	offsets := make([]byte, 0, len(memory.Segments)*18)
+	for i, segment := range memory.Segments {
+		if i > 0 {
+			buf.WriteByte('\n')
+			offsets = append(offsets, ',')
+		}
+		buf.WriteString("[t=")
+		buf.Write(appendTimestamp(buf.AvailableBuffer(), segment.Start))
+		buf.WriteString("] ")
+		buf.WriteString(strings.TrimSpace(segment.Text))
+
+		offsets = strconv.AppendFloat(offsets, segment.Start, 'f', 2, 64)
+		offsets = append(offsets, '-')
+		offsets = strconv.AppendFloat(offsets, segment.End, 'f', 2, 64)
+	}
+
+	metadata["start_offset"] = strconv.FormatFloat(memory.Segments[0].Start, 'f', 2, 64)
+	metadata["end_offset"] = strconv.FormatFloat(memory.Segments[len(memory.Segments)-1].End, 'f', 2, 64)
+	metadata["segment_offsets"] = string(offsets) // seconds, one start-end pair per marker
+}
+
+// appendTimestamp appends seconds as mm:ss, or h:mm:ss for recordings of an hour or longer
+func appendTimestamp(dst []byte, seconds float64) []byte {
+	total := int(seconds)
+	if total < 0 {
+		total = 0
+	}
+	if total >= 3600 {
+		dst = strconv.AppendInt(dst, int64(total/3600), 10)
+		dst = append(dst, ':')
+	}
+	dst = appendTwoDigits(dst, total%3600/60)
+	dst = append(dst, ':')
+	return appendTwoDigits(dst, total%60)
+}
+
+// appendTwoDigits appends a number zero-padded to at least two digits, as fmt's %02d does
+func appendTwoDigits(dst []byte, n int) []byte {
+	if n < 10 {
+		dst = append(dst, '0')
+	}
+	return strconv.AppendInt(dst, int64(n), 10)
+}
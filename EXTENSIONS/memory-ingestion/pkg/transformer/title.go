@@ -0,0 +1,142 @@
+package transformer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// defaultTitleWords is the number of transcript words {words} takes without a count
+const defaultTitleWords = 8
+
+// titlePlaceholder matches the placeholders of title templates, e.g. {date} or {words:5}
+var titlePlaceholder = regexp.MustCompile(`\{([a-z_]+)(?::(\d+))?\}`)
+
+// titleFields are the placeholders of title templates, and whether they take a count
+var titleFields = map[string]bool{
+	"date":        false,
+	"time":        false,
+	"weekday":     false,
+	"place":       false,
+	"words":       true,
+	"memory_id":   false,
+	"memory_type": false,
+	"context_id":  false,
+}
+
+// TitleTemplate composes the title of a memory's document, e.g. "{date} {place}: {words:6}"
+type TitleTemplate struct {
+	template string
+}
+
+// ParseTitleTemplate parses a title template. Placeholders: {date}, {time} (HH:MM), and
+// {weekday} the memory was created (where it was recorded if its time zone is known), {place}
+// (named by the location enricher or a frequent place), {words} or {words:N} (the first words of
+// the transcript, 8 by default), {memory_id}, {memory_type}, and {context_id}.
+func ParseTitleTemplate(template string) (*TitleTemplate, error) {
+	if strings.TrimSpace(template) == "" {
+		return nil, fmt.Errorf("title template is empty")
+	}
+	for _, match := range titlePlaceholder.FindAllStringSubmatch(template, -1) {
+		counted, ok := titleFields[match[1]]
+		if !ok {
+			return nil, fmt.Errorf("unknown placeholder %s", match[0])
+		}
+		if match[2] != "" && !counted {
+			return nil, fmt.Errorf("placeholder {%s} doesn't take a count", match[1])
+		}
+		if match[2] != "" {
+			if n, err := strconv.Atoi(match[2]); err != nil || n < 1 {
+				return nil, fmt.Errorf("placeholder %s must count at least 1 word", match[0])
+			}
+		}
+	}
+	return &TitleTemplate{template: template}, nil
+}
+
+// Render returns the title of a memory's document with the metadata its enrichers added, "" if
+// all of its placeholders are empty and it has no other text
+func (t *TitleTemplate) Render(memory *models.Memory, metadata map[string]string, contextID string) string {
+	createdAt, dated := memory.LocalCreatedAt()
+	if !dated {
+		var err error
+		createdAt, err = memory.ParseCreatedAt()
+		dated = err == nil
+	}
+
+	title := titlePlaceholder.ReplaceAllStringFunc(t.template, func(placeholder string) string {
+		match := titlePlaceholder.FindStringSubmatch(placeholder)
+		switch {
+		case match[1] == "date" && dated:
+			return createdAt.Format("2006-01-02")
+		case match[1] == "time" && dated:
+			return createdAt.Format("15:04")
+		case match[1] == "weekday" && dated:
+			return createdAt.Weekday().String()
+		}
+		switch match[1] {
+		case "date", "time", "weekday":
+			return ""
+		case "place":
+			if name := metadata["location_name"]; name != "" {
+				return name
+			}
+			return metadata["inferred_label"]
+		case "words":
+			n := defaultTitleWords
+			if match[2] != "" {
+				n, _ = strconv.Atoi(match[2])
+			}
+			return firstWords(memory.Transcript, n)
+		case "memory_id":
+			return memory.ID
+		case "memory_type":
+			return memory.Type
+		case "context_id":
+			return contextID
+		}
+		return placeholder
+	})
+
+	return tidyTitle(title)
+}
+
+// titleSeparators are the characters of separators between the parts of titles
+const titleSeparators = "-–—:,;|·/"
+
+// tidyTitle collapses the whitespace of a rendered title and drops the separators and empty
+// brackets empty placeholders left, e.g. "2025-01-15 · – Lunch ()" becomes "2025-01-15 · Lunch"
+func tidyTitle(title string) string {
+	var kept []string
+	separated := true // at the start, as after a separator
+	for _, word := range strings.Fields(title) {
+		switch {
+		case word == "()" || word == "[]":
+			continue
+		case strings.Trim(word, titleSeparators) == "":
+			if separated {
+				continue
+			}
+			separated = true
+		default:
+			separated = false
+		}
+		kept = append(kept, word)
+	}
+	for len(kept) > 0 && strings.Trim(kept[len(kept)-1], titleSeparators) == "" {
+		kept = kept[:len(kept)-1]
+	}
+	return strings.Join(kept, " ")
+}
+
+// firstWords returns the first n words of text, followed by an ellipsis if there are more
+func firstWords(text string, n int) string {
+	words := strings.Fields(text)
+	if len(words) <= n {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(words[:n], " ") + "…"
+}
@@ -1,41 +1,106 @@
 package transformer
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
 	"go.uber.org/zap"
 )
 
+// ErrTransformFailed is wrapped by all errors of Transform
+var ErrTransformFailed = errors.New("transformation failed")
+
 // Transformer converts Memory API data to LightRAG document format
 type Transformer struct {
 	strategy Strategy
+	metrics  *strategyMetrics
 	logger   *zap.Logger
 }
 
-// Strategy defines the interface for transformation strategies
+// Strategy defines the interface for transformation strategies. A registered strategy is shared
+// by the transformers using it, so it must be safe for concurrent use.
 type Strategy interface {
 	Transform(memory *models.Memory, config TransformConfig) (string, map[string]string, error)
 	Name() string
 }
 
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[string]Strategy{
+		"standard": &StandardStrategy{},
+		"rich":     &RichStrategy{},
+	}
+)
+
+// Register registers (or replaces) a transformation strategy under its name
+func Register(strategy Strategy) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+
+	strategies[strategy.Name()] = strategy
+}
+
+// Registered returns true if a strategy is registered under the name
+func Registered(name string) bool {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	_, ok := strategies[name]
+	return ok
+}
+
+// Strategies returns the names of the registered strategies, sorted
+func Strategies() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // TransformConfig holds configuration for transformation
 type TransformConfig struct {
 	IncludeMetadata bool
 	EnrichLocation  bool
 	ContextID       string
+	Timestamps      bool              // annotate timed transcripts with [t=mm:ss] markers and their offsets
+	S2Level         int               // add the S2 cell of located memories at this level (1 to 30) to their location metadata, 0 to leave it out
+	Pseudonymizer   Pseudonymizer     // replaces person names in the transcript, nil to keep them
+	Aliases         AliasResolver     // names the canonical entities of aliases in the transcript, nil to leave them out
+	RawFields       map[string]string // metadata keys of the memories' raw fields (see models.Memory.RawFields), by field
+
+	// Citations are the short citations stored as file_path instead of the memory URI, by memory ID
+	Citations map[string]string
+}
+
+// filePath returns the file_path of a memory's document: its short citation, or its URI
+func (c TransformConfig) filePath(memoryID string) string {
+	if citation, ok := c.Citations[memoryID]; ok {
+		return citation
+	}
+	return utils.MemoryURI(memoryID)
+}
+
+// Pseudonymizer replaces the person names in a context's text with stable pseudonyms
+type Pseudonymizer interface {
+	Pseudonymize(contextID, text string) (string, error)
 }
 
-// NewTransformer creates a new transformer with the specified strategy
+// NewTransformer creates a new transformer with the specified registered strategy
 func NewTransformer(strategyName string, logger *zap.Logger) (*Transformer, error) {
-	var strategy Strategy
-
-	switch strategyName {
-	case "standard":
-		strategy = &StandardStrategy{}
-	case "rich":
-		strategy = &RichStrategy{}
-	default:
+	strategiesMu.RLock()
+	strategy, ok := strategies[strategyName]
+	strategiesMu.RUnlock()
+	if !ok {
 		return nil, fmt.Errorf("unknown transformation strategy: %s", strategyName)
 	}
 
@@ -43,27 +108,84 @@ func NewTransformer(strategyName string, logger *zap.Logger) (*Transformer, erro
 
 	return &Transformer{
 		strategy: strategy,
+		metrics:  newStrategyMetrics(),
 		logger:   logger,
 	}, nil
 }
 
-// Transform converts a memory to LightRAG document format
-func (t *Transformer) Transform(memory *models.Memory, config TransformConfig) (string, map[string]string, error) {
-	t.logger.Debug("Transforming memory",
-		zap.String("memory_id", memory.ID),
-		zap.String("strategy", t.strategy.Name()),
-	)
+// StrategyName returns the name of the transformer's strategy
+func (t *Transformer) StrategyName() string {
+	return t.strategy.Name()
+}
+
+// Metrics returns the metrics of the transformer's strategy
+func (t *Transformer) Metrics() StrategyMetrics {
+	return t.metrics.snapshot(t.strategy.Name())
+}
+
+// Transform converts a memory to LightRAG document format. A strategy panicking on malformed
+// memory data fails the memory instead of the caller.
+func (t *Transformer) Transform(memory *models.Memory, config TransformConfig) (text string, metadata map[string]string, err error) {
+	// Checked first, since the fields of a disabled log entry still allocate
+	if entry := t.logger.Check(zap.DebugLevel, "Transforming memory"); entry != nil {
+		entry.Write(
+			zap.String("memory_id", memory.ID),
+			zap.String("strategy", t.strategy.Name()),
+		)
+	}
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			text, metadata, err = "", nil, fmt.Errorf("%w: strategy %s panicked: %v", ErrTransformFailed, t.strategy.Name(), r)
+		}
+		t.metrics.record(memory, config, text, metadata, time.Since(start), err)
+	}()
+
+	// Computed from the real names, pseudonymized with the transcript
+	aliasLine := ""
+	if config.Aliases != nil {
+		aliasLine = aliasContext(config.Aliases.CanonicalNames(config.ContextID, memory.Transcript))
+	}
 
-	text, metadata, err := t.strategy.Transform(memory, config)
+	if config.Pseudonymizer != nil {
+		if aliasLine != "" {
+			aliasLine, err = config.Pseudonymizer.Pseudonymize(config.ContextID, aliasLine)
+			if err != nil {
+				return "", nil, fmt.Errorf("%w: failed to pseudonymize aliases: %w", ErrTransformFailed, err)
+			}
+		}
+		anonymized := *memory
+		anonymized.Transcript, err = config.Pseudonymizer.Pseudonymize(config.ContextID, memory.Transcript)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: failed to pseudonymize transcript: %w", ErrTransformFailed, err)
+		}
+		anonymized.Segments = make([]models.TranscriptSegment, len(memory.Segments))
+		for i, segment := range memory.Segments {
+			segment.Text, err = config.Pseudonymizer.Pseudonymize(config.ContextID, segment.Text)
+			if err != nil {
+				return "", nil, fmt.Errorf("%w: failed to pseudonymize transcript: %w", ErrTransformFailed, err)
+			}
+			anonymized.Segments[i] = segment
+		}
+		memory = &anonymized
+	}
+
+	text, metadata, err = t.strategy.Transform(memory, config)
 	if err != nil {
-		return "", nil, fmt.Errorf("transformation failed: %w", err)
+		return "", nil, fmt.Errorf("%w: %w", ErrTransformFailed, err)
+	}
+	if aliasLine != "" {
+		text = AddRichContext(text, aliasLine)
 	}
 
-	t.logger.Debug("Transformation complete",
-		zap.String("memory_id", memory.ID),
-		zap.Int("text_length", len(text)),
-		zap.Int("metadata_count", len(metadata)),
-	)
+	if entry := t.logger.Check(zap.DebugLevel, "Transformation complete"); entry != nil {
+		entry.Write(
+			zap.String("memory_id", memory.ID),
+			zap.Int("text_length", len(text)),
+			zap.Int("metadata_count", len(metadata)),
+		)
+	}
 
 	return text, metadata, nil
 }
@@ -0,0 +1,169 @@
+// Package transformertest runs transformation strategies over fixture memories and compares their
+// documents with golden files, for regression tests of custom strategies built on this module.
+package transformertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/transformer"
+	"go.uber.org/zap"
+)
+
+// updateEnv rewrites the golden files with the documents of the run instead of comparing them if
+// set to true, e.g. UPDATE_GOLDEN=1 go test ./...
+const updateEnv = "UPDATE_GOLDEN"
+
+// metadataSeparator separates the text of a golden document from its metadata
+const metadataSeparator = "--- metadata ---"
+
+// updating returns true if the golden files are to be rewritten: UPDATE_GOLDEN is true, or the
+// test binary defines an -update flag of its own and it's set. The flag is looked up, not defined,
+// so test packages remain free to define it.
+func updating() bool {
+	if update, _ := strconv.ParseBool(os.Getenv(updateEnv)); update {
+		return true
+	}
+	update := flag.Lookup("update")
+	return update != nil && update.Value.String() == "true"
+}
+
+// Run transforms each fixture memory in dir (one JSON memory per *.json file, as the Memory API
+// returns it) with a registered strategy, and compares the document with the golden file next to
+// it, <fixture>.<strategy>.golden. While updating (see updating), the golden files are written instead. Each fixture
+// runs as a subtest named after its file; a dir without fixtures fails the test.
+func Run(t *testing.T, strategy, dir string, config transformer.TransformConfig) {
+	t.Helper()
+
+	trans, err := transformer.NewTransformer(strategy, zap.NewNop())
+	if err != nil {
+		t.Fatalf("%v (registered: %s)", err, strings.Join(transformer.Strategies(), ", "))
+	}
+
+	fixtures, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list fixtures: %v", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatalf("no fixture memories (*.json) in %s", dir)
+	}
+
+	update := updating()
+	for _, fixture := range fixtures {
+		fixture := fixture
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
+		t.Run(name, func(t *testing.T) {
+			memory, err := loadMemory(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			text, metadata, err := trans.Transform(memory, config)
+			got := Render(text, metadata, err)
+
+			golden := filepath.Join(dir, name+"."+strategy+".golden")
+			if update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatalf("failed to write golden file: %v", err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if os.IsNotExist(err) {
+				t.Fatalf("no golden file %s, run the test with %s=1 to create it", golden, updateEnv)
+			}
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("document differs from %s (run the test with %s=1 to accept it):\n%s", golden, updateEnv, firstDifference(want, got))
+			}
+		})
+	}
+}
+
+// RunAll runs Run with every registered strategy, each as a subtest named after it
+func RunAll(t *testing.T, dir string, config transformer.TransformConfig) {
+	t.Helper()
+
+	for _, strategy := range transformer.Strategies() {
+		strategy := strategy
+		t.Run(strategy, func(t *testing.T) {
+			Run(t, strategy, dir, config)
+		})
+	}
+}
+
+// loadMemory reads a fixture memory
+func loadMemory(path string) (*models.Memory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var memory models.Memory
+	if err := json.Unmarshal(data, &memory); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	return &memory, nil
+}
+
+// Render returns the golden form of a transformation: the document's text, then its metadata one
+// key = "value" line per key, sorted; or the error of a failed transformation
+func Render(text string, metadata map[string]string, err error) []byte {
+	var buf bytes.Buffer
+	if err != nil {
+		fmt.Fprintf(&buf, "error: %v\n", err)
+		return buf.Bytes()
+	}
+
+	buf.WriteString(text)
+	if !strings.HasSuffix(text, "\n") {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(metadataSeparator + "\n")
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "%s = %s\n", key, strconv.Quote(metadata[key]))
+	}
+	return buf.Bytes()
+}
+
+// firstDifference describes the first line where got differs from want
+func firstDifference(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i >= len(wantLines) || i >= len(gotLines) || w != g {
+			return fmt.Sprintf("line %d:\n  want: %s\n  got:  %s", i+1, lineOrEnd(w, i < len(wantLines)), lineOrEnd(g, i < len(gotLines)))
+		}
+	}
+	return "(no difference)"
+}
+
+// lineOrEnd quotes a line, or names the end of the file if there's no such line
+func lineOrEnd(line string, ok bool) string {
+	if !ok {
+		return "(end of file)"
+	}
+	return strconv.Quote(line)
+}
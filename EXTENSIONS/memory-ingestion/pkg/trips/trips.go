@@ -0,0 +1,229 @@
+// Package trips detects trips: sequences of memories recorded far from the user's frequent locations
+package trips
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kamir/memory-connector/pkg/models"
+	"github.com/kamir/memory-connector/pkg/utils"
+)
+
+const (
+	// cellDegrees is the size of the grid cells visits are counted in (~11 km north-south)
+	cellDegrees = 0.1
+
+	// frequentShare is the share of the busiest cell's visits a cell needs to be frequent, so a
+	// long stay somewhere doesn't turn it into a frequent location
+	frequentShare = 0.1
+
+	// maxCells bounds the visited cells kept in the state, the least visited are dropped first
+	maxCells = 1000
+
+	// maxNearby is the number of nearby memories of the trip referenced by a memory
+	maxNearby = 3
+)
+
+// Config holds the trip detection settings
+type Config struct {
+	MinDistanceKm float64       // distance from every frequent location that makes a memory part of a trip
+	MaxGap        time.Duration // maximum gap between consecutive memories of a trip
+	MinVisits     int           // located memories that make a cell a frequent location
+}
+
+// Membership is the trip of a memory
+type Membership struct {
+	TripID     string
+	Place      string
+	DistanceKm float64  // from the nearest frequent location, 0 for memories without a location
+	BearingDeg float64  // direction from the nearest frequent location, clockwise from north
+	Nearby     []string // IDs of the nearest memories of the trip recorded before, nearest first
+}
+
+// PlaceFunc names the place at coordinates, e.g. by reverse geocoding
+type PlaceFunc func(lat, lon float64) string
+
+// Detect returns the trip of each memory that belongs to one and updates the location history of
+// state with the located memories. Memories are taken in order of creation; a located memory far
+// from every frequent location continues the current trip if it follows its last memory within
+// MaxGap, or starts a new one named after its place. A memory near a frequent location ends the
+// current trip. Memories without a location belong to a trip of this batch if they were recorded
+// during it. Located memories reference the nearest located memories of the trip in this batch
+// recorded before them. Trip IDs derive from the context and the trip's first memory.
+func Detect(contextID string, memories []models.Memory, state *models.TripState, config Config, place PlaceFunc) map[string]Membership {
+	type dated struct {
+		memory    *models.Memory
+		createdAt time.Time
+	}
+
+	sorted := make([]dated, 0, len(memories))
+	for i := range memories {
+		createdAt, err := memories[i].ParseCreatedAt()
+		if err != nil {
+			continue
+		}
+		sorted = append(sorted, dated{memory: &memories[i], createdAt: createdAt})
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].createdAt.Before(sorted[j].createdAt) })
+
+	if state.Visits == nil {
+		state.Visits = make(map[string]int)
+	}
+
+	// Time spans of the trips of this batch, for the memories without a location
+	type span struct {
+		trip       *models.CurrentTrip
+		start, end time.Time
+		located    []models.Memory // of the trip so far
+	}
+	var spans []*span
+
+	memberships := make(map[string]Membership)
+	current := state.Current
+	var currentSpan *span
+	for _, memory := range sorted {
+		if !memory.memory.HasLocation() {
+			continue
+		}
+		lat, lon := *memory.memory.LocationLat, *memory.memory.LocationLon
+
+		distance, fromLat, fromLon, known := nearestFrequent(state.Visits, config.MinVisits, lat, lon)
+		state.Visits[cellKey(lat, lon)]++
+		if !known || distance <= config.MinDistanceKm {
+			current, currentSpan = nil, nil
+			continue
+		}
+
+		if current == nil || memory.createdAt.Sub(current.LastAt).Abs() > config.MaxGap {
+			current = &models.CurrentTrip{ID: tripID(contextID, memory.memory.ID), Place: place(lat, lon)}
+			currentSpan = nil
+		}
+		if currentSpan == nil {
+			start := memory.createdAt
+			if !current.LastAt.IsZero() && current.LastAt.Before(start) {
+				start = current.LastAt // continued from an earlier sync
+			}
+			currentSpan = &span{trip: current, start: start}
+			spans = append(spans, currentSpan)
+		}
+		if memory.createdAt.After(current.LastAt) {
+			current.LastAt = memory.createdAt
+		}
+		currentSpan.end = current.LastAt
+
+		var nearby []string
+		for _, near := range utils.NearestMemories(memory.memory, currentSpan.located, maxNearby) {
+			nearby = append(nearby, near.Memory.ID)
+		}
+		currentSpan.located = append(currentSpan.located, *memory.memory)
+
+		memberships[memory.memory.ID] = Membership{
+			TripID:     current.ID,
+			Place:      current.Place,
+			DistanceKm: math.Round(distance*10) / 10,
+			BearingDeg: math.Mod(math.Round(utils.InitialBearing(fromLat, fromLon, lat, lon)), 360),
+			Nearby:     nearby,
+		}
+	}
+	state.Current = current
+	pruneVisits(state.Visits)
+
+	for _, memory := range sorted {
+		if memory.memory.HasLocation() {
+			continue
+		}
+		for _, s := range spans {
+			if !memory.createdAt.Before(s.start) && !memory.createdAt.After(s.end) {
+				memberships[memory.memory.ID] = Membership{TripID: s.trip.ID, Place: s.trip.Place}
+				break
+			}
+		}
+	}
+
+	return memberships
+}
+
+// tripID derives the ID of a trip from its context and first memory
+func tripID(contextID, memoryID string) string {
+	sum := sha256.Sum256([]byte(contextID + "\x00" + memoryID))
+	return "trip-" + hex.EncodeToString(sum[:6])
+}
+
+// cellKey returns the grid cell of coordinates, the coordinates of its south-west corner
+func cellKey(lat, lon float64) string {
+	return fmt.Sprintf("%.1f,%.1f", math.Floor(lat/cellDegrees)*cellDegrees, math.Floor(lon/cellDegrees)*cellDegrees)
+}
+
+// cellCenter returns the center of a grid cell
+func cellCenter(key string) (float64, float64, bool) {
+	latText, lonText, ok := strings.Cut(key, ",")
+	if !ok {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(latText, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lon, err := strconv.ParseFloat(lonText, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat + cellDegrees/2, lon + cellDegrees/2, true
+}
+
+// nearestFrequent returns the distance to the nearest frequent location and its coordinates, false
+// if there is none yet
+func nearestFrequent(visits map[string]int, minVisits int, lat, lon float64) (float64, float64, float64, bool) {
+	busiest := 0
+	for _, count := range visits {
+		if count > busiest {
+			busiest = count
+		}
+	}
+
+	nearest, nearestLat, nearestLon, known := math.Inf(1), 0.0, 0.0, false
+	for key, count := range visits {
+		if count < minVisits || float64(count) < frequentShare*float64(busiest) {
+			continue
+		}
+		cellLat, cellLon, ok := cellCenter(key)
+		if !ok {
+			continue
+		}
+		if distance := utils.DistanceKm(lat, lon, cellLat, cellLon); distance < nearest {
+			nearest, nearestLat, nearestLon, known = distance, cellLat, cellLon, true
+		}
+	}
+	return nearest, nearestLat, nearestLon, known
+}
+
+// pruneVisits drops the least visited cells beyond maxCells
+func pruneVisits(visits map[string]int) {
+	if len(visits) <= maxCells {
+		return
+	}
+	keys := make([]string, 0, len(visits))
+	for key := range visits {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if visits[keys[i]] != visits[keys[j]] {
+			return visits[keys[i]] < visits[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys[:len(keys)-maxCells] {
+		delete(visits, key)
+	}
+}
+
+// CoordinatesPlace names a place by its rounded coordinates, e.g. "48.14, 11.58"
+func CoordinatesPlace(lat, lon float64) string {
+	return fmt.Sprintf("%.2f, %.2f", lat, lon)
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kamir/memory-connector/pkg/models"
+)
+
+// earthRadiusKm is the mean radius of the earth
+const earthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between two coordinates (haversine)
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := math.Pi / 180
+	dLat := (lat2 - lat1) * toRadians
+	dLon := (lon2 - lon1) * toRadians
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRadians)*math.Cos(lat2*toRadians)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// InitialBearing returns the direction from the first coordinates to the second at the start of
+// the great circle between them, in degrees clockwise from north (0 to 360)
+func InitialBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := math.Pi / 180
+	phi1, phi2 := lat1*toRadians, lat2*toRadians
+	dLon := (lon2 - lon1) * toRadians
+	y := math.Sin(dLon) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLon)
+	return math.Mod(math.Atan2(y, x)/toRadians+360, 360)
+}
+
+// DistanceBetweenMemories returns the great-circle distance between the locations of two memories
+// in km, false if either has no location
+func DistanceBetweenMemories(a, b *models.Memory) (float64, bool) {
+	if !a.HasLocation() || !b.HasLocation() {
+		return 0, false
+	}
+	return DistanceKm(*a.LocationLat, *a.LocationLon, *b.LocationLat, *b.LocationLon), true
+}
+
+// Bearing returns the direction from the location of a memory to that of another, in degrees
+// clockwise from north (0 to 360), false if either has no location
+func Bearing(from, to *models.Memory) (float64, bool) {
+	if !from.HasLocation() || !to.HasLocation() {
+		return 0, false
+	}
+	return InitialBearing(*from.LocationLat, *from.LocationLon, *to.LocationLat, *to.LocationLon), true
+}
+
+// NearbyMemory is a memory and its distance from another
+type NearbyMemory struct {
+	Memory     *models.Memory
+	DistanceKm float64
+}
+
+// NearestMemories returns up to n of the candidates nearest to a memory, nearest first. Candidates
+// without a location and the memory itself are left out; none are returned if the memory has no
+// location.
+func NearestMemories(memory *models.Memory, candidates []models.Memory, n int) []NearbyMemory {
+	if !memory.HasLocation() || n <= 0 {
+		return nil
+	}
+
+	var nearby []NearbyMemory
+	for i := range candidates {
+		if candidates[i].ID == memory.ID {
+			continue
+		}
+		if distance, ok := DistanceBetweenMemories(memory, &candidates[i]); ok {
+			nearby = append(nearby, NearbyMemory{Memory: &candidates[i], DistanceKm: distance})
+		}
+	}
+	sort.SliceStable(nearby, func(i, j int) bool { return nearby[i].DistanceKm < nearby[j].DistanceKm })
+	if len(nearby) > n {
+		nearby = nearby[:n]
+	}
+	return nearby
+}
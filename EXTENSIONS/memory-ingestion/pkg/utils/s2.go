@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// S2MaxLevel is the finest S2 cell level (~1 cm cells)
+const S2MaxLevel = 30
+
+const (
+	// s2PosBits is the number of bits of a leaf cell's face and Hilbert curve position
+	s2PosBits = 2*S2MaxLevel + 1
+
+	// s2MaxSize is the number of leaf cells along a face's edge
+	s2MaxSize = 1 << S2MaxLevel
+
+	// s2LookupBits is the number of i and j bits converted per table lookup
+	s2LookupBits = 4
+
+	// Orientations of the Hilbert curve within a cell
+	s2SwapMask   = 0x01
+	s2InvertMask = 0x02
+)
+
+var (
+	// s2PosToIJ is the (i, j) quadrant of each Hilbert curve position, per orientation
+	s2PosToIJ = [4][4]int{
+		{0, 1, 3, 2}, // canonical order
+		{0, 2, 3, 1}, // axes swapped
+		{3, 2, 0, 1}, // bits inverted
+		{3, 1, 0, 2}, // swapped & inverted
+	}
+
+	// s2PosToOrientation is the change of orientation of each Hilbert curve position
+	s2PosToOrientation = [4]int{s2SwapMask, 0, 0, s2InvertMask | s2SwapMask}
+
+	// s2LookupPos maps s2LookupBits of i and j and an orientation to their curve position and
+	// the orientation of the next bits
+	s2LookupPos [1 << (2*s2LookupBits + 2)]int
+)
+
+func init() {
+	initS2Lookup(0, 0, 0, 0, 0, 0)
+	initS2Lookup(0, 0, 0, s2SwapMask, 0, s2SwapMask)
+	initS2Lookup(0, 0, 0, s2InvertMask, 0, s2InvertMask)
+	initS2Lookup(0, 0, 0, s2SwapMask|s2InvertMask, 0, s2SwapMask|s2InvertMask)
+}
+
+// initS2Lookup fills s2LookupPos by walking the Hilbert curve s2LookupBits levels deep
+func initS2Lookup(level, i, j, origOrientation, pos, orientation int) {
+	if level == s2LookupBits {
+		ij := (i << s2LookupBits) + j
+		s2LookupPos[(ij<<2)+origOrientation] = (pos << 2) + orientation
+		return
+	}
+
+	level++
+	i <<= 1
+	j <<= 1
+	pos <<= 2
+	quadrants := s2PosToIJ[orientation]
+	for k, quadrant := range quadrants {
+		initS2Lookup(level, i+(quadrant>>1), j+(quadrant&1), origOrientation, pos+k, orientation^s2PosToOrientation[k])
+	}
+}
+
+// S2CellID returns the ID of the S2 cell at level (0 to 30) containing coordinates
+func S2CellID(lat, lon float64, level int) uint64 {
+	level = max(0, min(level, S2MaxLevel))
+
+	// Point on the unit sphere
+	phi, theta := lat*math.Pi/180, lon*math.Pi/180
+	x, y, z := math.Cos(phi)*math.Cos(theta), math.Cos(phi)*math.Sin(theta), math.Sin(phi)
+
+	// Face of the cube the point projects onto, and its coordinates on the face
+	var face int
+	var u, v float64
+	ax, ay, az := math.Abs(x), math.Abs(y), math.Abs(z)
+	switch {
+	case ax >= ay && ax >= az:
+		face = 0
+		if x < 0 {
+			face = 3
+		}
+	case ay >= az:
+		face = 1
+		if y < 0 {
+			face = 4
+		}
+	default:
+		face = 2
+		if z < 0 {
+			face = 5
+		}
+	}
+	switch face {
+	case 0:
+		u, v = y/x, z/x
+	case 1:
+		u, v = -x/y, z/y
+	case 2:
+		u, v = -x/z, -y/z
+	case 3:
+		u, v = z/x, y/x
+	case 4:
+		u, v = z/y, -x/y
+	default:
+		u, v = -y/z, -x/z
+	}
+
+	i := s2LeafIndex(s2UVToST(u))
+	j := s2LeafIndex(s2UVToST(v))
+
+	// Position along the face's Hilbert curve, s2LookupBits of i and j at a time
+	id := uint64(face) << (s2PosBits - 1)
+	bits := face & s2SwapMask
+	mask := (1 << s2LookupBits) - 1
+	for k := 7; k >= 0; k-- {
+		bits += ((i >> (k * s2LookupBits)) & mask) << (s2LookupBits + 2)
+		bits += ((j >> (k * s2LookupBits)) & mask) << 2
+		bits = s2LookupPos[bits]
+		id |= uint64(bits>>2) << (k * 2 * s2LookupBits)
+		bits &= s2SwapMask | s2InvertMask
+	}
+	leaf := id<<1 | 1
+
+	// Parent at level: the bits below its lowest set bit are cleared
+	lsb := uint64(1) << (2 * (S2MaxLevel - level))
+	return leaf&-lsb | lsb
+}
+
+// S2CellToken returns the token of an S2 cell ID: its hex digits without trailing zeros
+func S2CellToken(id uint64) string {
+	if id == 0 {
+		return "X"
+	}
+	return strings.TrimRight(fmt.Sprintf("%016x", id), "0")
+}
+
+// s2UVToST converts a face coordinate to the quadratic cell-space projection S2 uses
+func s2UVToST(u float64) float64 {
+	if u >= 0 {
+		return 0.5 * math.Sqrt(1+3*u)
+	}
+	return 1 - 0.5*math.Sqrt(1-3*u)
+}
+
+// s2LeafIndex returns the index of the leaf cell row (or column) at cell-space coordinate s
+func s2LeafIndex(s float64) int {
+	return max(0, min(s2MaxSize-1, int(math.Floor(s2MaxSize*s))))
+}
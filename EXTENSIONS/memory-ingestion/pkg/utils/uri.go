@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MemoryURIPrefix is the prefix of the memory URIs stored as file_path in LightRAG documents
+const MemoryURIPrefix = "api://memory-connector/"
+
+// ErrInvalidMemoryURI is wrapped by the errors of ParseMemoryURI
+var ErrInvalidMemoryURI = errors.New("invalid memory URI")
+
+// MemoryURI returns the URI that references a memory in LightRAG citations
+func MemoryURI(memoryID string) string {
+	return MemoryURIPrefix + url.PathEscape(memoryID)
+}
+
+// ShortCitationLength is the length of short citations
+const ShortCitationLength = 8
+
+// ShortCitation returns the short citation stored as file_path instead of a memory's URI by
+// connectors with transform.short_citations: the first 8 hex digits of the SHA-256 of its ID
+func ShortCitation(memoryID string) string {
+	sum := sha256.Sum256([]byte(memoryID))
+	return hex.EncodeToString(sum[:ShortCitationLength/2])
+}
+
+// IsShortCitation returns true if s has the form of a short citation
+func IsShortCitation(s string) bool {
+	if len(s) != ShortCitationLength {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// DeepLink fills a deep link template with a memory's ID and context, e.g.
+// https://app.example.com/memories/{memory_id} or https://app.example.com/{context_id}/{memory_id}.
+// The values are escaped for use in a URL path.
+func DeepLink(template, memoryID, contextID string) string {
+	return strings.NewReplacer(
+		"{memory_id}", url.PathEscape(memoryID),
+		"{context_id}", url.PathEscape(contextID),
+	).Replace(template)
+}
+
+// ResolveDeepLink converts a memory URI into the deep link of the memory in the source system
+func ResolveDeepLink(template, uri, contextID string) (string, error) {
+	memoryID, err := ParseMemoryURI(uri)
+	if err != nil {
+		return "", err
+	}
+	return DeepLink(template, memoryID, contextID), nil
+}
+
+// ParseMemoryURI extracts the memory ID from a memory URI (api://memory-connector/<memory_id>)
+func ParseMemoryURI(uri string) (string, error) {
+	uri = strings.TrimSpace(uri)
+	if !strings.HasPrefix(uri, MemoryURIPrefix) {
+		return "", fmt.Errorf("%w %q: expected prefix %s", ErrInvalidMemoryURI, uri, MemoryURIPrefix)
+	}
+
+	escapedID := strings.TrimPrefix(uri, MemoryURIPrefix)
+	if escapedID == "" || strings.Contains(escapedID, "/") {
+		return "", fmt.Errorf("%w %q: expected %s<memory_id>", ErrInvalidMemoryURI, uri, MemoryURIPrefix)
+	}
+
+	memoryID, err := url.PathUnescape(escapedID)
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %w", ErrInvalidMemoryURI, uri, err)
+	}
+
+	return memoryID, nil
+}